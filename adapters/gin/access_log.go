@@ -0,0 +1,37 @@
+package gin
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/httpmw"
+	"mora/pkg/logger"
+)
+
+// AccessLogConfig holds the configuration for the access log middleware
+type AccessLogConfig = httpmw.AccessLogConfig
+
+// AccessLog creates a middleware that writes one structured entry per
+// request with pkg/httpmw, carrying its method, path, status, latency, user
+// ID and trace ID. Server errors and slow requests are logged at warn level.
+func AccessLog(config AccessLogConfig) gin.HandlerFunc {
+	accessLog := httpmw.NewAccessLogger(config)
+	return func(c *gin.Context) {
+		r := c.Request
+		if accessLog.Skip(r) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		ctx := c.Request.Context()
+		if userID := GetUserID(c); userID != "" {
+			ctx = logger.WithUserID(ctx, userID)
+		}
+		accessLog.Log(ctx, r, c.Writer.Status(), latency)
+	}
+}
@@ -0,0 +1,79 @@
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/auth/apikey"
+	"mora/pkg/errors"
+	"mora/pkg/httpmw"
+	"mora/pkg/logger"
+)
+
+// ContextKeyAPIKey is the key used to store the API key in gin context
+const ContextKeyAPIKey = "api_key"
+
+// APIKeyMiddlewareConfig holds the configuration for API key middleware
+type APIKeyMiddlewareConfig struct {
+	Validator apikey.KeyValidator
+	// Header carries the key, "X-API-Key" when empty
+	Header string
+	// SkipPaths contains paths that should skip authentication, in the
+	// AuthMiddlewareConfig.SkipPaths syntax
+	SkipPaths []string
+	// Skipper, when set, skips requests SkipPaths cannot express
+	Skipper Skipper
+}
+
+// APIKeyMiddleware creates a middleware that authenticates requests by API
+// key. The key's claims are stored like AuthMiddleware's, so GetUserID,
+// GetClaims and RequirePermission work unchanged.
+func APIKeyMiddleware(config APIKeyMiddlewareConfig) gin.HandlerFunc {
+	header := config.Header
+	if header == "" {
+		header = "X-API-Key"
+	}
+	skip := httpmw.NewSkipper(config.SkipPaths, config.Skipper)
+	return func(c *gin.Context) {
+		if skip(c.Request) {
+			c.Next()
+			return
+		}
+
+		raw := c.GetHeader(header)
+		if raw == "" {
+			Error(c, errors.ErrUnauthorized.WithMessage("missing api key"))
+			return
+		}
+
+		key, err := config.Validator.ValidateKey(c.Request.Context(), raw)
+		switch {
+		case errors.Is(err, apikey.ErrExpiredKey):
+			Error(c, errors.ErrUnauthorized.WithMessage("api key expired"))
+			return
+		case errors.Is(err, apikey.ErrInvalidKey):
+			Error(c, errors.ErrUnauthorized.WithMessage("invalid api key"))
+			return
+		case err != nil:
+			Error(c, errors.ErrUnavailable.WithMessage("unable to verify api key"))
+			return
+		}
+
+		claims := key.Claims()
+		c.Set(ContextKeyAPIKey, key)
+		c.Set(ContextKeyClaims, claims)
+		c.Set(ContextKeyUserID, claims.UserID)
+		c.Request = c.Request.WithContext(logger.WithUserID(c.Request.Context(), claims.UserID))
+
+		c.Next()
+	}
+}
+
+// GetAPIKey extracts the API key from gin context
+func GetAPIKey(c *gin.Context) *apikey.Key {
+	if key, exists := c.Get(ContextKeyAPIKey); exists {
+		if k, ok := key.(*apikey.Key); ok {
+			return k
+		}
+	}
+	return nil
+}
@@ -1,12 +1,15 @@
 package gin
 
 import (
-	"net/http"
-	"strings"
+	"crypto"
 
 	"github.com/gin-gonic/gin"
 
 	"mora/pkg/auth"
+	"mora/pkg/auth/revocation"
+	"mora/pkg/errors"
+	"mora/pkg/httpmw"
+	"mora/pkg/logger"
 )
 
 const (
@@ -18,82 +21,81 @@ const (
 
 // AuthMiddlewareConfig holds the configuration for auth middleware
 type AuthMiddlewareConfig struct {
+	// Secret verifies HMAC-signed tokens
 	Secret string
-	// SkipPaths contains paths that should skip authentication
+	// PublicKey verifies RS256, ES256 or EdDSA tokens instead of Secret
+	PublicKey crypto.PublicKey
+	// Keyfunc resolves verification keys, e.g. by key ID; it takes precedence
+	// over PublicKey and Secret
+	Keyfunc auth.Keyfunc
+	// Validate adds issuer, audience and leeway checks
+	Validate auth.ValidateOptions
+	// SkipPaths contains paths that should skip authentication: exact
+	// paths, path/* prefixes, globs or ~regexps, optionally preceded by a
+	// method as in "GET /public/*"
 	SkipPaths []string
+	// Skipper, when set, skips requests SkipPaths cannot express
+	Skipper Skipper
+	// TokenLookup lists where to read the token from, in order, e.g.
+	// "header:Authorization:Bearer,cookie:access_token,query:token";
+	// httpmw.DefaultTokenLookup when empty
+	TokenLookup string
+	// Revocation, when set, rejects tokens whose ID has been revoked
+	Revocation revocation.Checker
+	// ErrorHandler, when set, writes the response for rejected requests
+	// instead of the default error envelope
+	ErrorHandler AuthErrorHandler
+}
+
+// AuthErrorHandler writes the response for a request AuthMiddleware
+// rejects. err carries the status and user-safe message; the auth error
+// behind it, such as auth.ErrExpiredToken, is in its chain.
+type AuthErrorHandler func(c *gin.Context, err *errors.Error)
+
+// keyfunc returns the key function for the configured verification key
+func (config AuthMiddlewareConfig) keyfunc() auth.Keyfunc {
+	switch {
+	case config.Keyfunc != nil:
+		return config.Keyfunc
+	case config.PublicKey != nil:
+		return auth.PublicKeyfunc(config.PublicKey)
+	}
+	return auth.HMACKeyfunc([]byte(config.Secret))
+}
+
+// defaultAuthErrorHandler writes the error envelope
+func defaultAuthErrorHandler(c *gin.Context, err *errors.Error) {
+	Error(c, err)
 }
 
 // AuthMiddleware creates a new authentication middleware for Gin
 func AuthMiddleware(config AuthMiddlewareConfig) gin.HandlerFunc {
+	keyfunc := config.keyfunc()
+	skip := httpmw.NewSkipper(config.SkipPaths, config.Skipper)
+	tokens := httpmw.NewTokenExtractor(config.TokenLookup)
+	onError := config.ErrorHandler
+	if onError == nil {
+		onError = defaultAuthErrorHandler
+	}
 	return func(c *gin.Context) {
 		// Check if current path should skip authentication
-		currentPath := c.Request.URL.Path
-		for _, path := range config.SkipPaths {
-			// Support wildcard pattern matching
-			if path == currentPath {
-				c.Next()
-				return
-			}
-			// Support path/* patterns
-			if strings.HasSuffix(path, "/*") {
-				prefix := strings.TrimSuffix(path, "/*")
-				if strings.HasPrefix(currentPath, prefix) {
-					c.Next()
-					return
-				}
-			}
-		}
-
-		// Extract token from Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "missing authorization header",
-			})
-			c.Abort()
+		if skip(c.Request) {
+			c.Next()
 			return
 		}
 
-		// Check Bearer token format
-		const bearerPrefix = "Bearer "
-		if !strings.HasPrefix(authHeader, bearerPrefix) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "invalid authorization header format",
-			})
-			c.Abort()
-			return
-		}
-
-		// Extract token
-		token := strings.TrimPrefix(authHeader, bearerPrefix)
+		// Extract token from the configured sources
+		token, message := tokens.Extract(c.Request)
 		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "missing token",
-			})
+			onError(c, errors.ErrUnauthorized.WithMessage(message))
 			c.Abort()
 			return
 		}
 
-		// Validate token
-		claims, err := auth.ValidateToken(token, config.Secret)
-		if err != nil {
-			var message string
-			switch err {
-			case auth.ErrExpiredToken:
-				message = "token expired"
-			case auth.ErrMalformedToken:
-				message = "malformed token"
-			default:
-				message = "invalid token"
-			}
-
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": message,
-			})
+		// Validate the token and reject revoked ones
+		claims, authErr := auth.Authenticate(c.Request.Context(), token, keyfunc, config.Validate, config.Revocation)
+		if authErr != nil {
+			onError(c, authErr)
 			c.Abort()
 			return
 		}
@@ -101,6 +103,7 @@ func AuthMiddleware(config AuthMiddlewareConfig) gin.HandlerFunc {
 		// Store claims and user ID in context
 		c.Set(ContextKeyClaims, claims)
 		c.Set(ContextKeyUserID, claims.UserID)
+		c.Request = c.Request.WithContext(logger.WithUserID(c.Request.Context(), claims.UserID))
 
 		c.Next()
 	}
@@ -0,0 +1,88 @@
+package gin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/captcha"
+)
+
+// CaptchaConfig configures CaptchaMiddleware
+type CaptchaConfig struct {
+	// KeyFunc identifies who failures are counted for; the client IP by default
+	KeyFunc func(c *gin.Context) string
+	// IDHeader and AnswerHeader carry the solved challenge
+	IDHeader     string
+	AnswerHeader string
+	// IsFailure reports whether the handled request failed; by default a
+	// 401 response does
+	IsFailure func(c *gin.Context) bool
+}
+
+// DefaultCaptchaConfig returns default captcha middleware configuration
+func DefaultCaptchaConfig() CaptchaConfig {
+	return CaptchaConfig{
+		KeyFunc:      func(c *gin.Context) string { return c.ClientIP() },
+		IDHeader:     "X-Captcha-Id",
+		AnswerHeader: "X-Captcha-Answer",
+		IsFailure:    func(c *gin.Context) bool { return c.Writer.Status() == http.StatusUnauthorized },
+	}
+}
+
+// CaptchaMiddleware requires a solved captcha once a client has failed too
+// often, e.g. on /login. Failures and successes are learned from the
+// response, so handlers need no captcha code.
+func CaptchaMiddleware(g *captcha.Guard, config CaptchaConfig) gin.HandlerFunc {
+	defaults := DefaultCaptchaConfig()
+	if config.KeyFunc == nil {
+		config.KeyFunc = defaults.KeyFunc
+	}
+	if config.IDHeader == "" {
+		config.IDHeader = defaults.IDHeader
+	}
+	if config.AnswerHeader == "" {
+		config.AnswerHeader = defaults.AnswerHeader
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = defaults.IsFailure
+	}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		key := config.KeyFunc(c)
+		if err := g.Check(ctx, key, c.GetHeader(config.IDHeader), c.GetHeader(config.AnswerHeader)); err != nil {
+			Error(c, err)
+			return
+		}
+
+		c.Next()
+
+		switch {
+		case config.IsFailure(c):
+			g.Fail(ctx, key)
+		case c.Writer.Status() < http.StatusBadRequest:
+			g.Reset(ctx, key)
+		}
+	}
+}
+
+// CaptchaResponse is a generated captcha challenge
+type CaptchaResponse struct {
+	ID string `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	// Image is a PNG data URL
+	Image string `json:"image" example:"data:image/png;base64,iVBORw0KGgo..."`
+}
+
+// CaptchaHandler issues a new captcha challenge
+func CaptchaHandler(cp *captcha.Captcha) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ch, err := cp.Generate(c.Request.Context())
+		if err != nil {
+			Error(c, err)
+			return
+		}
+		c.Header("Cache-Control", "no-store")
+		OK(c, CaptchaResponse{ID: ch.ID, Image: ch.DataURL()})
+	}
+}
@@ -0,0 +1,20 @@
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/httpmw"
+)
+
+// CORS creates a middleware applying pkg/httpmw CORS; register it with
+// engine.Use so preflights for unknown routes are answered too
+func CORS(config httpmw.CORSConfig) gin.HandlerFunc {
+	cors := httpmw.NewCORS(config)
+	return func(c *gin.Context) {
+		if cors.Handle(c.Writer, c.Request) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
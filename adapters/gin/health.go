@@ -0,0 +1,24 @@
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/health"
+)
+
+// HealthHandler serves the health report of a kind, with status 503 when it is down
+func HealthHandler(a *health.Aggregator, kind health.Kind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := a.Run(c.Request.Context(), kind)
+		c.Header("Cache-Control", "no-store")
+		c.JSON(report.HTTPStatus(), report)
+	}
+}
+
+// RegisterHealthRoutes serves readiness on /health and /health/ready and
+// liveness on /health/live
+func RegisterHealthRoutes(r gin.IRoutes, a *health.Aggregator) {
+	r.GET("/health", HealthHandler(a, health.Readiness))
+	r.GET("/health/ready", HealthHandler(a, health.Readiness))
+	r.GET("/health/live", HealthHandler(a, health.Liveness))
+}
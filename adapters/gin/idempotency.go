@@ -0,0 +1,56 @@
+package gin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/httpmw"
+)
+
+// Idempotency creates a middleware replaying the first response to each
+// Idempotency-Key with pkg/httpmw, so that retried requests such as order
+// creation run once. Keys are scoped to the user; register it after
+// AuthMiddleware.
+func Idempotency(store httpmw.IdempotencyStore, config httpmw.IdempotencyConfig) gin.HandlerFunc {
+	idem := httpmw.NewIdempotency(store, config)
+	return func(c *gin.Context) {
+		ran := false
+		err := idem.Serve(c.Writer, c.Request, GetUserID(c), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ran = true
+			writer := c.Writer
+			c.Writer = &idempotencyWriter{ResponseWriter: writer, capture: w}
+			c.Next()
+			c.Writer = writer
+		}))
+		if err != nil {
+			Error(c, err)
+			return
+		}
+		if !ran {
+			c.Abort()
+		}
+	}
+}
+
+// idempotencyWriter routes writes through the pkg/httpmw capture writer so
+// that the response can be stored
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	capture http.ResponseWriter
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *idempotencyWriter) WriteHeader(status int) {
+	w.capture.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (w *idempotencyWriter) Write(p []byte) (int, error) {
+	return w.capture.Write(p)
+}
+
+// WriteString implements gin.ResponseWriter
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	return w.capture.Write([]byte(s))
+}
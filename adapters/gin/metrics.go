@@ -0,0 +1,27 @@
+package gin
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/metrics"
+)
+
+// Metrics creates a middleware recording pkg/metrics HTTP metrics by route
+// pattern, e.g. "/orders/:id"; requests matching no route are reported as
+// metrics.RouteUnmatched
+func Metrics(m *metrics.HTTPMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer m.Start()()
+		start := time.Now()
+		c.Next()
+		m.Observe(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// MetricsHandler serves the metrics of a registry, e.g.
+// r.GET(reg.Path(), ginauth.MetricsHandler(reg))
+func MetricsHandler(r *metrics.Registry) gin.HandlerFunc {
+	return gin.WrapH(r.Handler())
+}
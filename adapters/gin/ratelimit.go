@@ -0,0 +1,54 @@
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/httpmw"
+	"mora/pkg/ratelimit"
+)
+
+// RateLimitConfig configures RateLimitMiddleware
+type RateLimitConfig struct {
+	Limiter ratelimit.Limiter
+	// KeyFunc identifies whose budget a request takes from; by default the
+	// user ID of authenticated requests and the client IP otherwise
+	KeyFunc func(c *gin.Context) string
+	// SkipPaths contains paths that are not limited, in the
+	// AuthMiddlewareConfig.SkipPaths syntax
+	SkipPaths []string
+	// Skipper, when set, skips requests SkipPaths cannot express
+	Skipper Skipper
+}
+
+// RateLimitKey is the default RateLimitConfig.KeyFunc
+func RateLimitKey(c *gin.Context) string {
+	if userID := GetUserID(c); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitMiddleware limits requests per key with pkg/httpmw, setting the
+// X-RateLimit-* headers and rejecting requests over budget with
+// ErrTooManyRequests; register it after AuthMiddleware to limit by user
+func RateLimitMiddleware(config RateLimitConfig) gin.HandlerFunc {
+	if config.KeyFunc == nil {
+		config.KeyFunc = RateLimitKey
+	}
+	limiter := httpmw.NewRateLimiter(httpmw.RateLimitConfig{
+		Limiter:   config.Limiter,
+		SkipPaths: config.SkipPaths,
+		Skipper:   config.Skipper,
+	})
+	return func(c *gin.Context) {
+		if limiter.Skip(c.Request) {
+			c.Next()
+			return
+		}
+		if err := limiter.Take(c.Writer, c.Request, config.KeyFunc(c)); err != nil {
+			Error(c, err)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/errors"
+)
+
+// RequireRole creates a middleware that allows users with any of the roles;
+// use it after AuthMiddleware
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := GetClaims(c)
+		if claims == nil {
+			Error(c, errors.ErrUnauthorized.WithMessage("missing claims"))
+			return
+		}
+		for _, role := range roles {
+			if claims.HasRole(role) {
+				c.Next()
+				return
+			}
+		}
+		Error(c, errors.ErrForbidden.WithMessage("insufficient role"))
+	}
+}
+
+// RequirePermission creates a middleware that allows users granted all of
+// the permissions; use it after AuthMiddleware
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := GetClaims(c)
+		if claims == nil {
+			Error(c, errors.ErrUnauthorized.WithMessage("missing claims"))
+			return
+		}
+		for _, permission := range permissions {
+			if !claims.HasPermission(permission) {
+				Error(c, errors.ErrForbidden.WithMessage("insufficient permission"))
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/httpmw"
+)
+
+// PanicNotifier reports a recovered panic, e.g. to Sentry
+type PanicNotifier = httpmw.PanicNotifier
+
+// RecoveryConfig holds the configuration for the recovery middleware
+type RecoveryConfig = httpmw.RecoveryConfig
+
+// RecoveryMiddleware creates a middleware that recovers from panics in
+// later handlers with pkg/httpmw, logs them with their stack and trace ID
+// and responds with the pkg/errors ErrInternal envelope
+func RecoveryMiddleware(config RecoveryConfig) gin.HandlerFunc {
+	recoverer := httpmw.NewRecoverer(config)
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				recoverer.Handle(c.Writer, c.Request, recovered, c.Writer.Written())
+				c.Abort()
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,19 @@
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/logger"
+)
+
+// RequestID creates a middleware that takes the request ID from the
+// X-Request-ID header, or generates one, echoes it in the response and puts
+// it in the request context, so AccessLog and logger.Ctx log it
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := logger.RequestID(c.Request)
+		c.Header(logger.RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
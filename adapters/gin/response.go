@@ -0,0 +1,73 @@
+package gin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"mora/pkg/errors"
+	"mora/pkg/response"
+	"mora/pkg/validator"
+)
+
+// JSON writes a response envelope, filling in the request's trace ID
+func JSON(c *gin.Context, status int, resp response.Response) {
+	c.JSON(status, resp.WithContext(c.Request.Context()))
+}
+
+// OK writes a 200 envelope carrying data
+func OK(c *gin.Context, data any) {
+	JSON(c, http.StatusOK, response.Success(data))
+}
+
+// Created writes a 201 envelope carrying the created resource
+func Created(c *gin.Context, data any) {
+	JSON(c, http.StatusCreated, response.Success(data))
+}
+
+// Paged writes a 200 envelope carrying one page of items
+func Paged(c *gin.Context, items any, total int64, page, pageSize int) {
+	JSON(c, http.StatusOK, response.PageOf(items, total, page, pageSize))
+}
+
+// Fail writes an error envelope and aborts the handler chain
+func Fail(c *gin.Context, status, code int, message string) {
+	c.AbortWithStatusJSON(status, response.Error(code, message).WithContext(c.Request.Context()))
+}
+
+// Error writes the envelope for err and aborts the handler chain; coded
+// errors from pkg/errors keep their status, code and user-safe message
+func Error(c *gin.Context, err error) {
+	status, resp := response.FromError(err)
+	c.AbortWithStatusJSON(status, resp.WithContext(c.Request.Context()))
+}
+
+// Bind decodes the request into obj by its content type and validates it
+// with v, translating messages to the Accept-Language of the request. Decode
+// failures are bad requests; validation failures render their field errors.
+func Bind(c *gin.Context, v *validator.Validator, obj any) error {
+	if err := c.ShouldBindWith(obj, binding.Default(c.Request.Method, c.ContentType())); err != nil {
+		return errors.ErrBadRequest.Wrap(err)
+	}
+	ctx := c.Request.Context()
+	if locale := validator.MatchLocale(c.GetHeader("Accept-Language")); locale != "" {
+		ctx = validator.WithLocale(ctx, locale)
+	}
+	return v.Struct(ctx, obj)
+}
+
+// BindAndValidate binds and validates obj with the default validator,
+// writing the error envelope, with field errors for validation failures,
+// and aborting when it fails. It reports whether the handler may continue:
+//
+//	if !ginauth.BindAndValidate(c, &req) {
+//		return
+//	}
+func BindAndValidate(c *gin.Context, obj any) bool {
+	if err := Bind(c, validator.Default(), obj); err != nil {
+		Error(c, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,20 @@
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/tracing"
+)
+
+// Tracing creates a middleware that continues the caller's trace and starts
+// a server span per request, named after the route pattern, with the
+// global tracer provider, see tracing.Init. The trace ID reaches pkg/logger
+// through the request context, so AccessLog and handlers log it.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r, span := tracing.StartHTTP(c.Request)
+		c.Request = r
+		defer func() { tracing.EndHTTP(span, r.Method, c.FullPath(), c.Writer.Status()) }()
+		c.Next()
+	}
+}
@@ -0,0 +1,137 @@
+package gin
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"mora/pkg/errors"
+	"mora/pkg/storage"
+	"mora/pkg/utils"
+)
+
+// UploadConfig configures file uploads
+type UploadConfig struct {
+	// Field is the multipart form field holding the file
+	Field string
+	// MaxSize is the largest accepted file in bytes
+	MaxSize int64
+	// AllowedTypes lists accepted content types, e.g. "image/png" or
+	// "image/*"; any type is accepted when empty. Types are sniffed from
+	// the content, not taken from the client.
+	AllowedTypes []string
+	// Prefix is prepended to generated keys
+	Prefix string
+	// KeyFunc names the stored object; by default Prefix, a ULID and the
+	// file's extension
+	KeyFunc func(c *gin.Context, filename string) (string, error)
+}
+
+// DefaultUploadConfig returns default upload configuration
+func DefaultUploadConfig() UploadConfig {
+	return UploadConfig{
+		Field:   "file",
+		MaxSize: 10 << 20,
+		Prefix:  "uploads/",
+	}
+}
+
+// Upload validates the uploaded file and stores it. Errors are coded errors
+// from pkg/errors, ready for Error.
+func Upload(c *gin.Context, s storage.Storage, cfg UploadConfig) (*storage.Object, error) {
+	if cfg.Field == "" {
+		cfg.Field = "file"
+	}
+	if cfg.MaxSize > 0 {
+		// Leave room for the multipart framing around the file
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxSize+1<<20)
+	}
+
+	header, err := c.FormFile(cfg.Field)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, errors.ErrPayloadTooLarge.WithDetail("file exceeds %d bytes", cfg.MaxSize)
+		}
+		return nil, errors.ErrBadRequest.WithDetail("missing file field %q", cfg.Field)
+	}
+	if cfg.MaxSize > 0 && header.Size > cfg.MaxSize {
+		return nil, errors.ErrPayloadTooLarge.WithDetail("file exceeds %d bytes", cfg.MaxSize)
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		return nil, errors.ErrBadRequest.Wrap(err)
+	}
+	defer f.Close()
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(f, sniff)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, errors.ErrBadRequest.Wrap(err)
+	}
+	sniff = sniff[:n]
+	contentType := http.DetectContentType(sniff)
+	if !allowedType(contentType, cfg.AllowedTypes) {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		return nil, errors.ErrUnsupportedType.WithDetail("file type %s is not allowed", mediaType)
+	}
+
+	keyFunc := cfg.KeyFunc
+	if keyFunc == nil {
+		keyFunc = func(c *gin.Context, filename string) (string, error) {
+			id, err := utils.GenerateULID()
+			if err != nil {
+				return "", err
+			}
+			return cfg.Prefix + id + strings.ToLower(path.Ext(filename)), nil
+		}
+	}
+	key, err := keyFunc(c, header.Filename)
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	obj, err := s.Put(c.Request.Context(), key, io.MultiReader(bytes.NewReader(sniff), f), &storage.PutOptions{
+		ContentType: contentType,
+		Size:        header.Size,
+	})
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+	return obj, nil
+}
+
+// UploadHandler stores the uploaded file and responds with the object
+func UploadHandler(s storage.Storage, cfg UploadConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		obj, err := Upload(c, s, cfg)
+		if err != nil {
+			Error(c, err)
+			return
+		}
+		Created(c, obj)
+	}
+}
+
+// allowedType reports whether contentType matches one of allowed
+func allowedType(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, a := range allowed {
+		if a == mediaType || strings.HasSuffix(a, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(a, "*")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package gozero
+
+import (
+	"net/http"
+
+	"mora/pkg/httpmw"
+)
+
+// AccessLogConfig holds the configuration for the access log middleware
+type AccessLogConfig = httpmw.AccessLogConfig
+
+// AccessLog creates a middleware that writes one structured entry per
+// request with pkg/httpmw, carrying its method, path, status, latency, user
+// ID and trace ID. Server errors and slow requests are logged at warn level.
+func AccessLog(config AccessLogConfig) func(next http.HandlerFunc) http.HandlerFunc {
+	accessLog := httpmw.AccessLog(config)
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return accessLog(next).ServeHTTP
+	}
+}
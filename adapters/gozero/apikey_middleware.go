@@ -0,0 +1,83 @@
+package gozero
+
+import (
+	"context"
+	"net/http"
+
+	"mora/pkg/auth/apikey"
+	"mora/pkg/errors"
+	"mora/pkg/httpmw"
+)
+
+// ContextKeyAPIKey is the key used to store the API key in go-zero context
+const ContextKeyAPIKey = "api_key"
+
+// APIKeyMiddlewareConfig holds the configuration for API key middleware
+type APIKeyMiddlewareConfig struct {
+	Validator apikey.KeyValidator
+	// Header carries the key, "X-API-Key" when empty
+	Header string
+	// SkipPaths contains paths that should skip authentication, in the
+	// AuthMiddlewareConfig.SkipPaths syntax
+	SkipPaths []string
+	// Skipper, when set, skips requests SkipPaths cannot express
+	Skipper Skipper
+}
+
+// APIKeyMiddleware creates a middleware that authenticates requests by API
+// key. The key's claims are stored like AuthMiddleware's, so GetUserID,
+// GetClaims and RequirePermission work unchanged.
+func APIKeyMiddleware(config APIKeyMiddlewareConfig) func(next http.HandlerFunc) http.HandlerFunc {
+	header := config.Header
+	if header == "" {
+		header = "X-API-Key"
+	}
+	skip := httpmw.NewSkipper(config.SkipPaths, config.Skipper)
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if skip(r) {
+				next(w, r)
+				return
+			}
+
+			raw := r.Header.Get(header)
+			if raw == "" {
+				Error(w, r, errors.ErrUnauthorized.WithMessage("missing api key"))
+				return
+			}
+
+			key, err := config.Validator.ValidateKey(r.Context(), raw)
+			switch {
+			case errors.Is(err, apikey.ErrExpiredKey):
+				Error(w, r, errors.ErrUnauthorized.WithMessage("api key expired"))
+				return
+			case errors.Is(err, apikey.ErrInvalidKey):
+				Error(w, r, errors.ErrUnauthorized.WithMessage("invalid api key"))
+				return
+			case err != nil:
+				Error(w, r, errors.ErrUnavailable.WithMessage("unable to verify api key"))
+				return
+			}
+
+			claims := key.Claims()
+			ctx := WithAPIKey(r.Context(), key)
+			ctx = WithClaims(ctx, claims)
+			ctx = WithUserID(ctx, claims.UserID)
+
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// WithAPIKey adds the API key to context
+func WithAPIKey(ctx context.Context, key *apikey.Key) context.Context {
+	return context.WithValue(ctx, ContextKeyAPIKey, key)
+}
+
+// GetAPIKey extracts the API key from context
+func GetAPIKey(ctx context.Context) *apikey.Key {
+	if key, ok := ctx.Value(ContextKeyAPIKey).(*apikey.Key); ok {
+		return key
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package gozero
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/proc"
+	"github.com/zeromicro/go-zero/rest"
+
+	"mora/pkg/app"
+)
+
+// serverComponent runs a go-zero server under pkg/app
+type serverComponent struct {
+	name   string
+	server *rest.Server
+	errs   chan error
+	done   chan struct{}
+}
+
+// Server wraps a go-zero server as a pkg/app component. go-zero traps
+// SIGINT/SIGTERM itself and force-quits after its shutdown wait time, so
+// keep proc's WaitTime at least as long as the app's ShutdownTimeout.
+func Server(name string, server *rest.Server) app.Component {
+	return &serverComponent{name: name, server: server, errs: make(chan error, 1)}
+}
+
+// Name implements app.Component
+func (s *serverComponent) Name() string { return s.name }
+
+// Start implements app.Component
+func (s *serverComponent) Start(ctx context.Context) error {
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		// go-zero panics when the server fails, e.g. the port is taken
+		defer func() {
+			if r := recover(); r != nil {
+				s.errs <- fmt.Errorf("%v", r)
+			}
+		}()
+		s.server.Start()
+	}()
+	return nil
+}
+
+// Stop implements app.Component
+func (s *serverComponent) Stop(ctx context.Context) error {
+	// go-zero shuts its servers down through proc's shutdown listeners
+	go proc.Shutdown()
+	select {
+	case <-s.done:
+		s.server.Stop()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Err implements app.Runner
+func (s *serverComponent) Err() <-chan error { return s.errs }
@@ -1,103 +1,101 @@
 package gozero
 
 import (
-	"encoding/json"
+	"crypto"
 	"net/http"
-	"strings"
 
 	"mora/pkg/auth"
+	"mora/pkg/auth/revocation"
+	"mora/pkg/errors"
+	"mora/pkg/httpmw"
+	"mora/pkg/logger"
 )
 
 const (
-	// ContextKeyUserID is the key used to store user ID in go-zero context
-	ContextKeyUserID = "user_id"
+	// ContextKeyUserID is the key used to store user ID in go-zero context,
+	// shared with pkg/logger so that logged entries carry it
+	ContextKeyUserID = logger.UserIDKey
 	// ContextKeyClaims is the key used to store claims in go-zero context
 	ContextKeyClaims = "claims"
 )
 
 // AuthMiddlewareConfig holds the configuration for auth middleware
 type AuthMiddlewareConfig struct {
+	// Secret verifies HMAC-signed tokens
 	Secret string
-	// SkipPaths contains paths that should skip authentication
+	// PublicKey verifies RS256, ES256 or EdDSA tokens instead of Secret
+	PublicKey crypto.PublicKey
+	// Keyfunc resolves verification keys, e.g. by key ID; it takes precedence
+	// over PublicKey and Secret
+	Keyfunc auth.Keyfunc
+	// Validate adds issuer, audience and leeway checks
+	Validate auth.ValidateOptions
+	// SkipPaths contains paths that should skip authentication: exact
+	// paths, path/* prefixes, globs or ~regexps, optionally preceded by a
+	// method as in "GET /public/*"
 	SkipPaths []string
+	// Skipper, when set, skips requests SkipPaths cannot express
+	Skipper Skipper
+	// TokenLookup lists where to read the token from, in order, e.g.
+	// "header:Authorization:Bearer,cookie:access_token,query:token";
+	// httpmw.DefaultTokenLookup when empty
+	TokenLookup string
+	// Revocation, when set, rejects tokens whose ID has been revoked
+	Revocation revocation.Checker
+	// ErrorHandler, when set, writes the response for rejected requests
+	// instead of the default error envelope
+	ErrorHandler AuthErrorHandler
 }
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-}
-
-// writeErrorResponse writes an error response
-func writeErrorResponse(w http.ResponseWriter, code int, err, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-
-	response := ErrorResponse{
-		Error:   err,
-		Message: message,
+// AuthErrorHandler writes the response for a request AuthMiddleware
+// rejects. err carries the status and user-safe message; the auth error
+// behind it, such as auth.ErrExpiredToken, is in its chain.
+type AuthErrorHandler func(w http.ResponseWriter, r *http.Request, err *errors.Error)
+
+// keyfunc returns the key function for the configured verification key
+func (config AuthMiddlewareConfig) keyfunc() auth.Keyfunc {
+	switch {
+	case config.Keyfunc != nil:
+		return config.Keyfunc
+	case config.PublicKey != nil:
+		return auth.PublicKeyfunc(config.PublicKey)
 	}
+	return auth.HMACKeyfunc([]byte(config.Secret))
+}
 
-	json.NewEncoder(w).Encode(response)
+// defaultAuthErrorHandler writes the error envelope
+func defaultAuthErrorHandler(w http.ResponseWriter, r *http.Request, err *errors.Error) {
+	Error(w, r, err)
 }
 
 // AuthMiddleware creates a new authentication middleware for go-zero
 func AuthMiddleware(config AuthMiddlewareConfig) func(next http.HandlerFunc) http.HandlerFunc {
+	keyfunc := config.keyfunc()
+	skip := httpmw.NewSkipper(config.SkipPaths, config.Skipper)
+	tokens := httpmw.NewTokenExtractor(config.TokenLookup)
+	onError := config.ErrorHandler
+	if onError == nil {
+		onError = defaultAuthErrorHandler
+	}
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			// Check if current path should skip authentication
-			currentPath := r.URL.Path
-			for _, path := range config.SkipPaths {
-				// Support exact matching
-				if path == currentPath {
-					next(w, r)
-					return
-				}
-				// Support path/* patterns
-				if strings.HasSuffix(path, "/*") {
-					prefix := strings.TrimSuffix(path, "/*")
-					if strings.HasPrefix(currentPath, prefix) {
-						next(w, r)
-						return
-					}
-				}
-			}
-
-			// Extract token from Authorization header
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", "missing authorization header")
+			if skip(r) {
+				next(w, r)
 				return
 			}
 
-			// Check Bearer token format
-			const bearerPrefix = "Bearer "
-			if !strings.HasPrefix(authHeader, bearerPrefix) {
-				writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", "invalid authorization header format")
-				return
-			}
-
-			// Extract token
-			token := strings.TrimPrefix(authHeader, bearerPrefix)
+			// Extract token from the configured sources
+			token, message := tokens.Extract(r)
 			if token == "" {
-				writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", "missing token")
+				onError(w, r, errors.ErrUnauthorized.WithMessage(message))
 				return
 			}
 
-			// Validate token
-			claims, err := auth.ValidateToken(token, config.Secret)
-			if err != nil {
-				var message string
-				switch err {
-				case auth.ErrExpiredToken:
-					message = "token expired"
-				case auth.ErrMalformedToken:
-					message = "malformed token"
-				default:
-					message = "invalid token"
-				}
-
-				writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", message)
+			// Validate the token and reject revoked ones
+			claims, authErr := auth.Authenticate(r.Context(), token, keyfunc, config.Validate, config.Revocation)
+			if authErr != nil {
+				onError(w, r, authErr)
 				return
 			}
 
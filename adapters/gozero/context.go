@@ -4,10 +4,13 @@ import (
 	"context"
 
 	"mora/pkg/auth"
+	"mora/pkg/httpmw"
 )
 
-// WithUserID adds user ID to context
+// WithUserID adds user ID to context, under the key pkg/logger reads, and
+// reports it to an enclosing AccessLog
 func WithUserID(ctx context.Context, userID string) context.Context {
+	httpmw.ReportUserID(ctx, userID)
 	return context.WithValue(ctx, ContextKeyUserID, userID)
 }
 
@@ -0,0 +1,46 @@
+package gozero
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"github.com/zeromicro/go-zero/rest/router"
+
+	"mora/pkg/httpmw"
+)
+
+// CORS creates a route middleware applying pkg/httpmw CORS. Preflights only
+// reach it for routes registered with OPTIONS; use WithCORS to cover all.
+func CORS(config httpmw.CORSConfig) func(next http.HandlerFunc) http.HandlerFunc {
+	cors := httpmw.NewCORS(config)
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if cors.Handle(w, r) {
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// WithCORS returns a server option applying pkg/httpmw CORS to every
+// request before routing, e.g. rest.MustNewServer(c, gozero.WithCORS(cfg)).
+// It installs its own router, so do not combine it with rest.WithRouter.
+func WithCORS(config httpmw.CORSConfig) rest.RunOption {
+	return rest.WithRouter(&corsRouter{Router: router.NewRouter(), cors: httpmw.NewCORS(config)})
+}
+
+// corsRouter applies CORS ahead of a router
+type corsRouter struct {
+	httpx.Router
+	cors *httpmw.CORS
+}
+
+// ServeHTTP implements http.Handler
+func (r *corsRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.cors.Handle(w, req) {
+		return
+	}
+	r.Router.ServeHTTP(w, req)
+}
@@ -0,0 +1,29 @@
+package gozero
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"mora/pkg/health"
+)
+
+// HealthHandler serves the health report of a kind, with status 503 when it is down
+func HealthHandler(a *health.Aggregator, kind health.Kind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := a.Run(r.Context(), kind)
+		w.Header().Set("Cache-Control", "no-store")
+		httpx.WriteJsonCtx(r.Context(), w, report.HTTPStatus(), report)
+	}
+}
+
+// HealthRoutes serves readiness on /health and /health/ready and liveness
+// on /health/live; add them with server.AddRoutes
+func HealthRoutes(a *health.Aggregator) []rest.Route {
+	return []rest.Route{
+		{Method: http.MethodGet, Path: "/health", Handler: HealthHandler(a, health.Readiness)},
+		{Method: http.MethodGet, Path: "/health/ready", Handler: HealthHandler(a, health.Readiness)},
+		{Method: http.MethodGet, Path: "/health/live", Handler: HealthHandler(a, health.Liveness)},
+	}
+}
@@ -0,0 +1,22 @@
+package gozero
+
+import (
+	"net/http"
+
+	"mora/pkg/httpmw"
+)
+
+// Idempotency creates a middleware replaying the first response to each
+// Idempotency-Key with pkg/httpmw, so that retried requests such as order
+// creation run once. Keys are scoped to the user; apply it inside
+// AuthMiddleware.
+func Idempotency(store httpmw.IdempotencyStore, config httpmw.IdempotencyConfig) func(next http.HandlerFunc) http.HandlerFunc {
+	idem := httpmw.NewIdempotency(store, config)
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if err := idem.Serve(w, r, GetUserID(r.Context()), next); err != nil {
+				Error(w, r, err)
+			}
+		}
+	}
+}
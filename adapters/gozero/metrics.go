@@ -0,0 +1,28 @@
+package gozero
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest"
+
+	"mora/pkg/metrics"
+)
+
+// InstrumentRoutes wraps the handlers of routes so they record pkg/metrics
+// HTTP metrics labelled with the route path, e.g.
+// server.AddRoutes(gozero.InstrumentRoutes(m, routes...))
+func InstrumentRoutes(m *metrics.HTTPMetrics, routes ...rest.Route) []rest.Route {
+	instrumented := make([]rest.Route, len(routes))
+	for i, route := range routes {
+		path := route.Path
+		route.Handler = m.Instrument(route.Handler, func(*http.Request) string { return path }).ServeHTTP
+		instrumented[i] = route
+	}
+	return instrumented
+}
+
+// MetricsRoute serves the metrics of a registry on its path; add it with
+// server.AddRoute
+func MetricsRoute(r *metrics.Registry) rest.Route {
+	return rest.Route{Method: http.MethodGet, Path: r.Path(), Handler: r.Handler().ServeHTTP}
+}
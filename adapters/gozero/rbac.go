@@ -0,0 +1,49 @@
+package gozero
+
+import (
+	"net/http"
+
+	"mora/pkg/errors"
+)
+
+// RequireRole creates a middleware that allows users with any of the roles;
+// use it after AuthMiddleware
+func RequireRole(roles ...string) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			claims := GetClaims(r.Context())
+			if claims == nil {
+				Error(w, r, errors.ErrUnauthorized.WithMessage("missing claims"))
+				return
+			}
+			for _, role := range roles {
+				if claims.HasRole(role) {
+					next(w, r)
+					return
+				}
+			}
+			Error(w, r, errors.ErrForbidden.WithMessage("insufficient role"))
+		}
+	}
+}
+
+// RequirePermission creates a middleware that allows users granted all of
+// the permissions; use it after AuthMiddleware
+func RequirePermission(permissions ...string) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			claims := GetClaims(r.Context())
+			if claims == nil {
+				Error(w, r, errors.ErrUnauthorized.WithMessage("missing claims"))
+				return
+			}
+			for _, permission := range permissions {
+				if !claims.HasPermission(permission) {
+					Error(w, r, errors.ErrForbidden.WithMessage("insufficient permission"))
+					return
+				}
+			}
+			next(w, r)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package gozero
+
+import (
+	"net/http"
+
+	"mora/pkg/httpmw"
+)
+
+// PanicNotifier reports a recovered panic, e.g. to Sentry
+type PanicNotifier = httpmw.PanicNotifier
+
+// RecoveryConfig holds the configuration for the recovery middleware
+type RecoveryConfig = httpmw.RecoveryConfig
+
+// RecoveryMiddleware creates a middleware that recovers from panics in
+// later handlers with pkg/httpmw, logs them with their stack and trace ID
+// and responds with the pkg/errors ErrInternal envelope
+func RecoveryMiddleware(config RecoveryConfig) func(next http.HandlerFunc) http.HandlerFunc {
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = Error
+	}
+	recovery := httpmw.Recovery(config)
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return recovery(next).ServeHTTP
+	}
+}
@@ -0,0 +1,21 @@
+package gozero
+
+import (
+	"net/http"
+
+	"mora/pkg/logger"
+)
+
+// RequestID creates a middleware that takes the request ID from the
+// X-Request-ID header, or generates one, echoes it in the response and puts
+// it in the request context, so logger.Ctx logs it. Add it with server.Use
+// before AccessLog so that the access entries carry it too.
+func RequestID() func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			id := logger.RequestID(r)
+			w.Header().Set(logger.RequestIDHeader, id)
+			next(w, r.WithContext(logger.WithRequestID(r.Context(), id)))
+		}
+	}
+}
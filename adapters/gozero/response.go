@@ -0,0 +1,76 @@
+package gozero
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"mora/pkg/errors"
+	"mora/pkg/response"
+	"mora/pkg/validator"
+)
+
+// JSON writes a response envelope through httpx, filling in the request's trace ID
+func JSON(w http.ResponseWriter, r *http.Request, status int, resp response.Response) {
+	httpx.WriteJsonCtx(r.Context(), w, status, resp.WithContext(r.Context()))
+}
+
+// OK writes a 200 envelope carrying data
+func OK(w http.ResponseWriter, r *http.Request, data any) {
+	JSON(w, r, http.StatusOK, response.Success(data))
+}
+
+// Created writes a 201 envelope carrying the created resource
+func Created(w http.ResponseWriter, r *http.Request, data any) {
+	JSON(w, r, http.StatusCreated, response.Success(data))
+}
+
+// Paged writes a 200 envelope carrying one page of items
+func Paged(w http.ResponseWriter, r *http.Request, items any, total int64, page, pageSize int) {
+	JSON(w, r, http.StatusOK, response.PageOf(items, total, page, pageSize))
+}
+
+// Fail writes an error envelope
+func Fail(w http.ResponseWriter, r *http.Request, status, code int, message string) {
+	JSON(w, r, status, response.Error(code, message))
+}
+
+// Error writes the envelope for err; coded errors from pkg/errors keep their
+// status, code and user-safe message
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	status, resp := response.FromError(err)
+	JSON(w, r, status, resp)
+}
+
+// ErrorHandler renders errors passed to httpx.Error and httpx.ErrorCtx as
+// envelopes; install it with httpx.SetErrorHandlerCtx(gozero.ErrorHandler)
+func ErrorHandler(ctx context.Context, err error) (int, any) {
+	status, resp := response.FromError(err)
+	return status, resp.WithContext(ctx)
+}
+
+// Bind parses the request into obj with httpx.Parse and validates it with
+// v, translating messages to the Accept-Language of the request. Parse
+// failures are bad requests; validation failures render their field errors.
+func Bind(r *http.Request, v *validator.Validator, obj any) error {
+	if err := httpx.Parse(r, obj); err != nil {
+		return errors.ErrBadRequest.WithMessage(err.Error())
+	}
+	ctx := r.Context()
+	if locale := validator.MatchLocale(r.Header.Get("Accept-Language")); locale != "" {
+		ctx = validator.WithLocale(ctx, locale)
+	}
+	return v.Struct(ctx, obj)
+}
+
+// BindAndValidate binds and validates obj with the default validator,
+// writing the error envelope when it fails, and reports whether the
+// handler may continue
+func BindAndValidate(w http.ResponseWriter, r *http.Request, obj any) bool {
+	if err := Bind(r, validator.Default(), obj); err != nil {
+		Error(w, r, err)
+		return false
+	}
+	return true
+}
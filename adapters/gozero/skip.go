@@ -0,0 +1,7 @@
+package gozero
+
+import "mora/pkg/httpmw"
+
+// Skipper reports whether a request should bypass a middleware; SkipPaths
+// patterns are compiled with httpmw.NewSkipper
+type Skipper = httpmw.Skipper
@@ -0,0 +1,22 @@
+package gozero
+
+import (
+	"net/http"
+
+	"mora/pkg/httpmw"
+	"mora/pkg/tracing"
+)
+
+// Tracing creates a middleware that puts the trace ID of the span go-zero
+// starts for each request, when RestConf.Middlewares.Trace is on as by
+// default, in the context for pkg/logger and reports it to an enclosing
+// AccessLog; add it with server.Use
+func Tracing() func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := tracing.WithLogTraceID(r.Context())
+			httpmw.ReportTraceID(ctx, tracing.TraceID(ctx))
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
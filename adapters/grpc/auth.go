@@ -0,0 +1,130 @@
+// Package grpc binds pkg/auth to gRPC: server interceptors that validate
+// Bearer tokens from metadata and a client interceptor that attaches them.
+// Plug the server side into pkg/grpcserver with grpcserver.WithAuth.
+package grpc
+
+import (
+	"context"
+	"crypto"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"mora/pkg/auth"
+	"mora/pkg/auth/revocation"
+	"mora/pkg/errors"
+)
+
+// AuthConfig holds the configuration for the auth interceptors
+type AuthConfig struct {
+	// Secret verifies HMAC-signed tokens
+	Secret string
+	// PublicKey verifies RS256, ES256 or EdDSA tokens instead of Secret
+	PublicKey crypto.PublicKey
+	// Keyfunc resolves verification keys, e.g. by key ID; it takes precedence
+	// over PublicKey and Secret
+	Keyfunc auth.Keyfunc
+	// Validate adds issuer, audience and leeway checks
+	Validate auth.ValidateOptions
+	// SkipMethods contains full method names that skip authentication, e.g.
+	// "/grpc.health.v1.Health/Check", or "/pkg.Service/*" for a whole service
+	SkipMethods []string
+	// Revocation, when set, rejects tokens whose ID has been revoked
+	Revocation revocation.Checker
+}
+
+// keyfunc returns the key function for the configured verification key
+func (config AuthConfig) keyfunc() auth.Keyfunc {
+	switch {
+	case config.Keyfunc != nil:
+		return config.Keyfunc
+	case config.PublicKey != nil:
+		return auth.PublicKeyfunc(config.PublicKey)
+	}
+	return auth.HMACKeyfunc([]byte(config.Secret))
+}
+
+// skipMethod reports whether method matches one of the methods exactly or
+// a /service/* pattern by prefix
+func skipMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(m, "*"); ok && strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticator validates the token of incoming calls
+type authenticator struct {
+	config  AuthConfig
+	keyfunc auth.Keyfunc
+}
+
+// authenticate returns ctx carrying the caller's claims, or a coded error
+// that pkg/errors reports as Unauthenticated
+func (a *authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if skipMethod(a.config.SkipMethods, method) {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, errors.ErrUnauthorized.WithMessage("missing authorization metadata")
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, errors.ErrUnauthorized.WithMessage("invalid authorization metadata format")
+	}
+	if token == "" {
+		return nil, errors.ErrUnauthorized.WithMessage("missing token")
+	}
+
+	claims, err := auth.Authenticate(ctx, token, a.keyfunc, a.config.Validate, a.config.Revocation)
+	if err != nil {
+		return nil, err
+	}
+	return WithClaims(ctx, claims), nil
+}
+
+// UnaryServerInterceptor authenticates unary calls and stores the claims
+// in the handler's context
+func UnaryServerInterceptor(config AuthConfig) grpc.UnaryServerInterceptor {
+	a := &authenticator{config: config, keyfunc: config.keyfunc()}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates streams and stores the claims in
+// the stream's context
+func StreamServerInterceptor(config AuthConfig) grpc.StreamServerInterceptor {
+	a := &authenticator{config: config, keyfunc: config.keyfunc()}
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// contextStream overrides a stream's context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the authenticated context
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,184 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"mora/pkg/auth"
+	"mora/pkg/auth/revocation"
+)
+
+const testSecret = "test-secret"
+
+func TestSkipMethod(t *testing.T) {
+	methods := []string{"/grpc.health.v1.Health/Check", "/public.Service/*"}
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{"/grpc.health.v1.Health/Check", true},
+		{"/grpc.health.v1.Health/Watch", false},
+		{"/public.Service/Get", true},
+		{"/private.Service/Get", false},
+	}
+	for _, tt := range tests {
+		if got := skipMethod(methods, tt.method); got != tt.want {
+			t.Errorf("skipMethod(%q) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	pair, _ := auth.GenerateTokenPair("user123", "testuser", auth.TokenConfig{Secret: testSecret, AccessTTL: time.Minute, RefreshTTL: time.Hour})
+	revokedPair, _ := auth.GenerateTokenPair("user123", "testuser", auth.TokenConfig{Secret: testSecret, AccessTTL: time.Minute, RefreshTTL: time.Hour})
+	list := revocation.New(revocation.NewMemoryStore())
+	if err := list.Revoke(context.Background(), revokedPair.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+	interceptor := UnaryServerInterceptor(AuthConfig{
+		Secret:      testSecret,
+		SkipMethods: []string{"/grpc.health.v1.Health/*"},
+		Revocation:  list,
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		auth     string
+		wantCode codes.Code
+		wantUser string
+	}{
+		{name: "valid token", method: "/orders.Service/Get", auth: "Bearer " + pair.AccessToken, wantCode: codes.OK, wantUser: "user123"},
+		{name: "skipped method", method: "/grpc.health.v1.Health/Check", wantCode: codes.OK},
+		{name: "missing metadata", method: "/orders.Service/Get", wantCode: codes.Unauthenticated},
+		{name: "missing scheme", method: "/orders.Service/Get", auth: pair.AccessToken, wantCode: codes.Unauthenticated},
+		{name: "invalid token", method: "/orders.Service/Get", auth: "Bearer not-a-token", wantCode: codes.Unauthenticated},
+		{name: "refresh token", method: "/orders.Service/Get", auth: "Bearer " + pair.RefreshToken, wantCode: codes.Unauthenticated},
+		{name: "revoked token", method: "/orders.Service/Get", auth: "Bearer " + revokedPair.AccessToken, wantCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.auth != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.auth))
+			}
+			var user string
+			handler := func(ctx context.Context, req any) (any, error) {
+				user = GetUserID(ctx)
+				return "ok", nil
+			}
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v, want %v (err = %v)", code, tt.wantCode, err)
+			}
+			if user != tt.wantUser {
+				t.Errorf("user = %q, want %q", user, tt.wantUser)
+			}
+		})
+	}
+}
+
+// revocationDown is a revocation.Checker whose blacklist is unreachable
+type revocationDown struct{}
+
+func (revocationDown) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestUnaryServerInterceptorFailsClosed(t *testing.T) {
+	token, _ := auth.GenerateToken("user123", "testuser", testSecret, time.Minute)
+	interceptor := UnaryServerInterceptor(AuthConfig{Secret: testSecret, Revocation: revocationDown{}})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/orders.Service/Get"}, func(ctx context.Context, req any) (any, error) {
+		t.Error("handler called")
+		return nil, nil
+	})
+	if code := status.Code(err); code != codes.Unavailable {
+		t.Errorf("code = %v, want %v", code, codes.Unavailable)
+	}
+}
+
+// serverStream is a grpc.ServerStream carrying a context
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	token, _ := auth.GenerateToken("user123", "testuser", testSecret, time.Minute)
+	interceptor := StreamServerInterceptor(AuthConfig{Secret: testSecret})
+	info := &grpc.StreamServerInfo{FullMethod: "/orders.Service/Watch"}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	var user string
+	err := interceptor(nil, &serverStream{ctx: ctx}, info, func(srv any, ss grpc.ServerStream) error {
+		user = GetUserID(ss.Context())
+		return nil
+	})
+	if err != nil || user != "user123" {
+		t.Errorf("err = %v, user = %q, want user123", err, user)
+	}
+
+	err = interceptor(nil, &serverStream{ctx: context.Background()}, info, func(srv any, ss grpc.ServerStream) error {
+		t.Error("handler called without a token")
+		return nil
+	})
+	if code := status.Code(err); code != codes.Unauthenticated {
+		t.Errorf("code = %v, want %v", code, codes.Unauthenticated)
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		source  TokenSource
+		want    string
+		wantErr bool
+	}{
+		{name: "attaches token", ctx: context.Background(), source: StaticToken("abc"), want: "Bearer abc"},
+		{
+			name:   "keeps caller's token",
+			ctx:    metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer mine"),
+			source: StaticToken("abc"),
+			want:   "Bearer mine",
+		},
+		{
+			name:    "source error",
+			ctx:     context.Background(),
+			source:  func(context.Context) (string, error) { return "", errors.New("no token") },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				md, _ := metadata.FromOutgoingContext(ctx)
+				got = md.Get("authorization")[0]
+				return nil
+			}
+
+			err := UnaryClientInterceptor(tt.source)(tt.ctx, "/orders.Service/Get", nil, nil, nil, invoker)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
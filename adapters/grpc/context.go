@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"context"
+
+	"mora/pkg/auth"
+)
+
+// claimsKey keys the caller's claims in call contexts
+type claimsKey struct{}
+
+// WithClaims adds claims to context
+func WithClaims(ctx context.Context, claims *auth.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// GetClaims extracts claims from context
+func GetClaims(ctx context.Context) *auth.Claims {
+	if claims, ok := ctx.Value(claimsKey{}).(*auth.Claims); ok {
+		return claims
+	}
+	return nil
+}
+
+// GetUserID extracts the caller's user ID from context
+func GetUserID(ctx context.Context) string {
+	if claims := GetClaims(ctx); claims != nil {
+		return claims.UserID
+	}
+	return ""
+}
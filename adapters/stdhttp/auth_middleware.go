@@ -0,0 +1,101 @@
+package stdhttp
+
+import (
+	"crypto"
+	"net/http"
+
+	"mora/pkg/auth"
+	"mora/pkg/auth/revocation"
+	"mora/pkg/errors"
+	"mora/pkg/httpmw"
+	"mora/pkg/response"
+)
+
+// AuthMiddlewareConfig holds the configuration for auth middleware
+type AuthMiddlewareConfig struct {
+	// Secret verifies HMAC-signed tokens
+	Secret string
+	// PublicKey verifies RS256, ES256 or EdDSA tokens instead of Secret
+	PublicKey crypto.PublicKey
+	// Keyfunc resolves verification keys, e.g. by key ID; it takes precedence
+	// over PublicKey and Secret
+	Keyfunc auth.Keyfunc
+	// Validate adds issuer, audience and leeway checks
+	Validate auth.ValidateOptions
+	// SkipPaths contains paths that should skip authentication: exact
+	// paths, path/* prefixes, globs or ~regexps, optionally preceded by a
+	// method as in "GET /public/*"
+	SkipPaths []string
+	// Skipper, when set, skips requests SkipPaths cannot express
+	Skipper Skipper
+	// TokenLookup lists where to read the token from, in order, e.g.
+	// "header:Authorization:Bearer,cookie:access_token,query:token";
+	// httpmw.DefaultTokenLookup when empty
+	TokenLookup string
+	// Revocation, when set, rejects tokens whose ID has been revoked
+	Revocation revocation.Checker
+	// ErrorHandler, when set, writes the response for rejected requests
+	// instead of the default error envelope
+	ErrorHandler AuthErrorHandler
+}
+
+// AuthErrorHandler writes the response for a request AuthMiddleware
+// rejects. err carries the status and user-safe message; the auth error
+// behind it, such as auth.ErrExpiredToken, is in its chain.
+type AuthErrorHandler func(w http.ResponseWriter, r *http.Request, err *errors.Error)
+
+// keyfunc returns the key function for the configured verification key
+func (config AuthMiddlewareConfig) keyfunc() auth.Keyfunc {
+	switch {
+	case config.Keyfunc != nil:
+		return config.Keyfunc
+	case config.PublicKey != nil:
+		return auth.PublicKeyfunc(config.PublicKey)
+	}
+	return auth.HMACKeyfunc([]byte(config.Secret))
+}
+
+// defaultAuthErrorHandler writes the error envelope
+func defaultAuthErrorHandler(w http.ResponseWriter, r *http.Request, err *errors.Error) {
+	response.Err(w, r, err)
+}
+
+// AuthMiddleware creates a new authentication middleware for net/http
+func AuthMiddleware(config AuthMiddlewareConfig) Middleware {
+	keyfunc := config.keyfunc()
+	skip := httpmw.NewSkipper(config.SkipPaths, config.Skipper)
+	tokens := httpmw.NewTokenExtractor(config.TokenLookup)
+	onError := config.ErrorHandler
+	if onError == nil {
+		onError = defaultAuthErrorHandler
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Check if current path should skip authentication
+			if skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Extract token from the configured sources
+			token, message := tokens.Extract(r)
+			if token == "" {
+				onError(w, r, errors.ErrUnauthorized.WithMessage(message))
+				return
+			}
+
+			// Validate the token and reject revoked ones
+			claims, authErr := auth.Authenticate(r.Context(), token, keyfunc, config.Validate, config.Revocation)
+			if authErr != nil {
+				onError(w, r, authErr)
+				return
+			}
+
+			// Store claims and user ID in context
+			ctx := WithClaims(r.Context(), claims)
+			ctx = WithUserID(ctx, claims.UserID)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package stdhttp
+
+import (
+	"context"
+
+	"mora/pkg/auth"
+	"mora/pkg/httpmw"
+	"mora/pkg/logger"
+)
+
+// contextKey keys values stored in request contexts
+type contextKey int
+
+const (
+	claimsKey contextKey = iota
+)
+
+// WithUserID adds user ID to context, where pkg/logger finds it, and reports
+// it to an enclosing AccessLog
+func WithUserID(ctx context.Context, userID string) context.Context {
+	httpmw.ReportUserID(ctx, userID)
+	return logger.WithUserID(ctx, userID)
+}
+
+// GetUserID extracts user ID from context
+func GetUserID(ctx context.Context) string {
+	return logger.GetUserIDFromContext(ctx)
+}
+
+// WithClaims adds claims to context
+func WithClaims(ctx context.Context, claims *auth.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// GetClaims extracts claims from context
+func GetClaims(ctx context.Context) *auth.Claims {
+	if claims, ok := ctx.Value(claimsKey).(*auth.Claims); ok {
+		return claims
+	}
+	return nil
+}
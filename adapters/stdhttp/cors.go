@@ -0,0 +1,9 @@
+package stdhttp
+
+import "mora/pkg/httpmw"
+
+// CORS creates a middleware applying pkg/httpmw CORS; wrap the whole mux
+// with it so preflights for any route are answered
+func CORS(config httpmw.CORSConfig) Middleware {
+	return httpmw.NewCORS(config).Middleware
+}
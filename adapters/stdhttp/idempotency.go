@@ -0,0 +1,23 @@
+package stdhttp
+
+import (
+	"net/http"
+
+	"mora/pkg/httpmw"
+	"mora/pkg/response"
+)
+
+// Idempotency creates a middleware replaying the first response to each
+// Idempotency-Key with pkg/httpmw, so that retried requests such as order
+// creation run once. Keys are scoped to the user; apply it inside
+// AuthMiddleware.
+func Idempotency(store httpmw.IdempotencyStore, config httpmw.IdempotencyConfig) Middleware {
+	idem := httpmw.NewIdempotency(store, config)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := idem.Serve(w, r, GetUserID(r.Context()), next); err != nil {
+				response.Err(w, r, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package stdhttp
+
+import (
+	"net/http"
+
+	"mora/pkg/metrics"
+)
+
+// Metrics creates a middleware recording pkg/metrics HTTP metrics by the
+// http.ServeMux pattern, e.g. "GET /orders/{id}"; wrap the whole mux with
+// it, requests matching no pattern are reported as metrics.RouteUnmatched
+func Metrics(m *metrics.HTTPMetrics) Middleware {
+	return func(next http.Handler) http.Handler {
+		return m.Instrument(next, func(r *http.Request) string { return r.Pattern })
+	}
+}
@@ -0,0 +1,27 @@
+// Package stdhttp binds Mora to net/http, so it works with http.ServeMux,
+// chi, gorilla/mux or any router built on http.Handler without pulling in
+// gin or go-zero. Responses are written with pkg/response.
+package stdhttp
+
+import (
+	"net/http"
+)
+
+// Middleware wraps a handler
+type Middleware func(http.Handler) http.Handler
+
+// Chain composes middleware so the first one is outermost:
+// Chain(a, b)(h) runs a, then b, then h
+func Chain(middleware ...Middleware) Middleware {
+	return func(h http.Handler) http.Handler {
+		for i := len(middleware) - 1; i >= 0; i-- {
+			h = middleware[i](h)
+		}
+		return h
+	}
+}
+
+// Wrap applies middleware to h, e.g. mux.Handle("/orders", stdhttp.Wrap(orders, auth, admin))
+func Wrap(h http.Handler, middleware ...Middleware) http.Handler {
+	return Chain(middleware...)(h)
+}
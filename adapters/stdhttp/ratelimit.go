@@ -0,0 +1,23 @@
+package stdhttp
+
+import (
+	"net/http"
+
+	"mora/pkg/httpmw"
+)
+
+// RateLimitConfig configures RateLimitMiddleware
+type RateLimitConfig = httpmw.RateLimitConfig
+
+// RateLimitKey is the default RateLimitConfig.KeyFunc, keying by user ID
+// for authenticated requests and by client IP otherwise
+func RateLimitKey(r *http.Request) string {
+	return httpmw.RateLimitKey(r)
+}
+
+// RateLimitMiddleware limits requests per key with pkg/httpmw, setting the
+// X-RateLimit-* headers and rejecting requests over budget with
+// ErrTooManyRequests; apply it inside AuthMiddleware to limit by user
+func RateLimitMiddleware(config RateLimitConfig) Middleware {
+	return httpmw.RateLimit(config)
+}
@@ -0,0 +1,50 @@
+package stdhttp
+
+import (
+	"net/http"
+
+	"mora/pkg/errors"
+	"mora/pkg/response"
+)
+
+// RequireRole creates a middleware that allows users with any of the roles;
+// use it after AuthMiddleware
+func RequireRole(roles ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := GetClaims(r.Context())
+			if claims == nil {
+				response.Err(w, r, errors.ErrUnauthorized.WithMessage("missing claims"))
+				return
+			}
+			for _, role := range roles {
+				if claims.HasRole(role) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			response.Err(w, r, errors.ErrForbidden.WithMessage("insufficient role"))
+		})
+	}
+}
+
+// RequirePermission creates a middleware that allows users granted all of
+// the permissions; use it after AuthMiddleware
+func RequirePermission(permissions ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := GetClaims(r.Context())
+			if claims == nil {
+				response.Err(w, r, errors.ErrUnauthorized.WithMessage("missing claims"))
+				return
+			}
+			for _, permission := range permissions {
+				if !claims.HasPermission(permission) {
+					response.Err(w, r, errors.ErrForbidden.WithMessage("insufficient permission"))
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
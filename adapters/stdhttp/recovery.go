@@ -0,0 +1,18 @@
+package stdhttp
+
+import (
+	"mora/pkg/httpmw"
+)
+
+// PanicNotifier reports a recovered panic, e.g. to Sentry
+type PanicNotifier = httpmw.PanicNotifier
+
+// RecoveryConfig holds the configuration for the recovery middleware
+type RecoveryConfig = httpmw.RecoveryConfig
+
+// RecoveryMiddleware creates a middleware that recovers from panics in
+// later handlers with pkg/httpmw, logs them with their stack and trace ID
+// and responds with the pkg/errors ErrInternal envelope
+func RecoveryMiddleware(config RecoveryConfig) Middleware {
+	return httpmw.Recovery(config)
+}
@@ -0,0 +1,25 @@
+package stdhttp
+
+import (
+	"net/http"
+
+	"mora/pkg/httpmw"
+	"mora/pkg/tracing"
+)
+
+// Tracing creates a middleware that continues the caller's trace and starts
+// a server span per request with the global tracer provider, see
+// tracing.Init. Spans are named after the http.ServeMux pattern, so wrap
+// the mux directly; the trace ID reaches pkg/logger through the request
+// context and is reported to an enclosing AccessLog.
+func Tracing() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, span := tracing.StartHTTP(r)
+			httpmw.ReportTraceID(r.Context(), tracing.TraceID(r.Context()))
+			rec := httpmw.NewStatusRecorder(w)
+			defer func() { tracing.EndHTTP(span, r.Method, r.Pattern, rec.Status()) }()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package scaffold
+
+import (
+	"bufio"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MiddlewareOptions configures AddMiddleware
+type MiddlewareOptions struct {
+	// Name is the middleware name, e.g. request-id
+	Name string
+	// Dir is the service root, defaulting to the working directory
+	Dir string
+	// Framework is gin or gozero, detected from go.mod when empty
+	Framework string
+	Force     bool
+}
+
+// AddMiddleware writes internal/middleware/<name>.go and returns its path
+func AddMiddleware(opts MiddlewareOptions) (string, error) {
+	if !token.IsIdentifier(Pascal(opts.Name)) {
+		return "", fmt.Errorf("invalid middleware name %q", opts.Name)
+	}
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	framework := opts.Framework
+	if framework == "" {
+		detected, err := DetectFramework(dir)
+		if err != nil {
+			return "", err
+		}
+		framework = detected
+	}
+	if framework != FrameworkGin && framework != FrameworkGoZero {
+		return "", fmt.Errorf("unsupported framework %q, want %s or %s", framework, FrameworkGin, FrameworkGoZero)
+	}
+
+	content, err := renderTemplate("add/middleware_"+framework+".go.tmpl", opts)
+	if err != nil {
+		return "", err
+	}
+	f := file{path: "internal/middleware/" + Snake(opts.Name) + ".go", content: content}
+	if err := writeFiles(dir, []file{f}, opts.Force); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.FromSlash(f.path)), nil
+}
+
+// ModelOptions configures AddModel
+type ModelOptions struct {
+	// Name is the model name, e.g. Order or order_item
+	Name string
+	// Fields are "name:type" pairs, e.g. "amount:float64"
+	Fields []string
+	// Table overrides the table name, which defaults to the plural snake case name
+	Table string
+	// Dir is the service root, defaulting to the working directory
+	Dir        string
+	MoraModule string
+	Force      bool
+}
+
+// field is a model field passed to the template
+type field struct {
+	Name   string
+	Type   string
+	Column string
+}
+
+// model is the data passed to the model template
+type model struct {
+	Type       string
+	Table      string
+	Fields     []field
+	MoraModule string
+}
+
+// reservedFields are generated for every model
+var reservedFields = map[string]bool{"ID": true, "CreatedAt": true, "UpdatedAt": true}
+
+// AddModel writes internal/model/<name>.go with a gorm model and a CRUD
+// repository on pkg/db, and returns its path
+func AddModel(opts ModelOptions) (string, error) {
+	m := model{Type: Pascal(opts.Name), Table: opts.Table, MoraModule: opts.MoraModule}
+	if !token.IsIdentifier(m.Type) {
+		return "", fmt.Errorf("invalid model name %q", opts.Name)
+	}
+	if m.Table == "" {
+		m.Table = plural(Snake(opts.Name))
+	}
+	if m.MoraModule == "" {
+		m.MoraModule = DefaultMoraModule
+	}
+
+	fields, err := parseFields(opts.Fields)
+	if err != nil {
+		return "", err
+	}
+	m.Fields = fields
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	content, err := renderTemplate("add/model.go.tmpl", m)
+	if err != nil {
+		return "", err
+	}
+	f := file{path: "internal/model/" + Snake(opts.Name) + ".go", content: content}
+	if err := writeFiles(dir, []file{f}, opts.Force); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.FromSlash(f.path)), nil
+}
+
+// parseFields parses "name:type" pairs, which may also be comma separated
+func parseFields(values []string) ([]field, error) {
+	var fields []field
+	seen := make(map[string]bool)
+	for _, v := range values {
+		for _, spec := range strings.Split(v, ",") {
+			spec = strings.TrimSpace(spec)
+			if spec == "" {
+				continue
+			}
+			name, typ, ok := strings.Cut(spec, ":")
+			if !ok || name == "" || typ == "" {
+				return nil, fmt.Errorf("invalid field %q, want name:type", spec)
+			}
+			f := field{Name: Pascal(name), Type: fieldType(typ), Column: Snake(name)}
+			if !token.IsIdentifier(f.Name) {
+				return nil, fmt.Errorf("invalid field name %q", name)
+			}
+			if reservedFields[f.Name] {
+				return nil, fmt.Errorf("field %s is generated for every model", f.Name)
+			}
+			if seen[f.Name] {
+				return nil, fmt.Errorf("duplicate field %s", f.Name)
+			}
+			seen[f.Name] = true
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// fieldType maps shorthand types to Go types
+func fieldType(t string) string {
+	switch strings.ToLower(t) {
+	case "text":
+		return "string"
+	case "time", "datetime", "timestamp":
+		return "time.Time"
+	case "decimal", "float":
+		return "float64"
+	case "integer":
+		return "int"
+	case "boolean":
+		return "bool"
+	default:
+		return t
+	}
+}
+
+// plural forms a table name from a snake case noun
+func plural(s string) string {
+	switch {
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsAny(s[len(s)-2:len(s)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "z"),
+		strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	default:
+		return s + "s"
+	}
+}
+
+// DetectFramework reports the framework a service uses from its go.mod
+func DetectFramework(dir string) (string, error) {
+	f, err := os.Open(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("failed to detect framework, pass -framework: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.Contains(line, "github.com/zeromicro/go-zero"):
+			return FrameworkGoZero, nil
+		case strings.Contains(line, "github.com/gin-gonic/gin"):
+			return FrameworkGin, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	return "", fmt.Errorf("no gin or go-zero dependency in %s, pass -framework", filepath.Join(dir, "go.mod"))
+}
@@ -0,0 +1,199 @@
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// ErrExists is returned when a generated file would overwrite an existing one
+var ErrExists = errors.New("scaffold: file already exists")
+
+// funcs are available to every template
+var funcs = template.FuncMap{
+	"snake":  Snake,
+	"pascal": Pascal,
+	"camel":  Camel,
+	"upper":  strings.ToUpper,
+	"lower":  strings.ToLower,
+}
+
+// file is one rendered output file
+type file struct {
+	path    string
+	content []byte
+}
+
+// renderDir renders every template under templates/<dir>. Output paths drop
+// the .tmpl suffix and may themselves contain template actions; templates
+// rendering to whitespace only are skipped, which is how optional
+// components leave their files out.
+func renderDir(dir string, data any) ([]file, error) {
+	root := path.Join("templates", dir)
+	var files []file
+	err := fs.WalkDir(templates, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel := strings.TrimSuffix(strings.TrimPrefix(p, root+"/"), ".tmpl")
+		out, err := renderString(rel, rel, data)
+		if err != nil {
+			return err
+		}
+
+		src, err := templates.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		content, err := renderString(p, string(src), data)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(content) == "" {
+			return nil
+		}
+		files = append(files, file{path: out, content: []byte(content)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// renderTemplate renders the single template templates/<name>
+func renderTemplate(name string, data any) ([]byte, error) {
+	src, err := templates.ReadFile(path.Join("templates", name))
+	if err != nil {
+		return nil, err
+	}
+	content, err := renderString(name, string(src), data)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+// renderString executes text as a template named name
+func renderString(name, text string, data any) (string, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// writeFiles writes files under dir, gofmt-ing Go sources. Existing files
+// are only replaced when force is set.
+func writeFiles(dir string, files []file, force bool) error {
+	if !force {
+		for _, f := range files {
+			target := filepath.Join(dir, filepath.FromSlash(f.path))
+			if _, err := os.Stat(target); err == nil {
+				return fmt.Errorf("%w: %s", ErrExists, target)
+			}
+		}
+	}
+
+	for _, f := range files {
+		content := f.content
+		if strings.HasSuffix(f.path, ".go") {
+			formatted, err := format.Source(content)
+			if err != nil {
+				return fmt.Errorf("failed to format %s: %w", f.path, err)
+			}
+			content = formatted
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(f.path))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", target, err)
+		}
+		if err := os.WriteFile(target, content, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// words splits an identifier such as "requestID", "RequestId" or
+// "request-id" into lower-case words, keeping acronyms together
+func words(s string) []string {
+	var result []string
+	var current []rune
+	runes := []rune(s)
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ' || r == '.':
+			flush()
+		case unicode.IsUpper(r):
+			// A capital starts a word unless it continues an acronym
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			prevUpper := i > 0 && unicode.IsUpper(runes[i-1])
+			if prevLower || (prevUpper && nextLower) {
+				flush()
+			}
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	return result
+}
+
+// initialisms are written in upper case by Pascal and Camel, as golint expects
+var initialisms = map[string]bool{
+	"api": true, "http": true, "id": true, "ip": true, "json": true,
+	"jwt": true, "sql": true, "url": true, "uuid": true,
+}
+
+// Snake converts an identifier to snake_case
+func Snake(s string) string {
+	return strings.Join(words(s), "_")
+}
+
+// Pascal converts an identifier to PascalCase
+func Pascal(s string) string {
+	var b strings.Builder
+	for _, w := range words(s) {
+		if initialisms[w] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+// Camel converts an identifier to camelCase
+func Camel(s string) string {
+	ws := words(s)
+	if len(ws) == 0 {
+		return ""
+	}
+	return ws[0] + Pascal(strings.Join(ws[1:], "_"))
+}
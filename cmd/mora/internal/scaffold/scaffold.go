@@ -0,0 +1,220 @@
+// Package scaffold generates Mora services from the gin and go-zero
+// starters, and adds middleware and model files to existing ones.
+package scaffold
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Frameworks supported by New
+const (
+	FrameworkGin    = "gin"
+	FrameworkGoZero = "gozero"
+)
+
+// Components that can be wired into a new service
+const (
+	ComponentAuth    = "auth"
+	ComponentConfig  = "config"
+	ComponentLogger  = "logger"
+	ComponentDB      = "db"
+	ComponentCache   = "cache"
+	ComponentHealth  = "health"
+	ComponentMetrics = "metrics"
+)
+
+// AllComponents lists every component, in the order they are wired
+var AllComponents = []string{
+	ComponentConfig, ComponentLogger, ComponentDB, ComponentCache,
+	ComponentAuth, ComponentHealth, ComponentMetrics,
+}
+
+// DefaultComponents are wired when no components are selected
+var DefaultComponents = []string{ComponentConfig, ComponentLogger, ComponentAuth, ComponentHealth}
+
+// DefaultMoraModule is the module path of this library
+const DefaultMoraModule = "mora"
+
+var nameRe = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// Options configures a new service
+type Options struct {
+	// Name is the service name, e.g. order-service
+	Name string
+	// Module is the Go module path, defaulting to Name
+	Module string
+	// Framework is gin or gozero
+	Framework string
+	// Components selects what is wired, defaulting to DefaultComponents
+	Components []string
+	// Dir is the output directory, defaulting to Name
+	Dir string
+	// MoraModule and MoraVersion pin the library in go.mod
+	MoraModule  string
+	MoraVersion string
+	// MoraReplace, when set, adds a replace directive pointing at a local
+	// checkout of the library
+	MoraReplace string
+	// Force allows writing into a non-empty directory
+	Force bool
+}
+
+// project is the data passed to the project templates
+type project struct {
+	Name        string
+	Module      string
+	Package     string
+	EnvPrefix   string
+	MoraModule  string
+	MoraVersion string
+	MoraReplace string
+
+	Auth    bool
+	Config  bool
+	Logger  bool
+	DB      bool
+	Cache   bool
+	Health  bool
+	Metrics bool
+}
+
+// MoraSections reports whether any component has its own config section
+func (p *project) MoraSections() bool {
+	return p.Logger || p.DB || p.Cache
+}
+
+// New generates a service and returns the directory it was written to
+func New(opts Options) (string, error) {
+	p, err := newProject(opts)
+	if err != nil {
+		return "", err
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = opts.Name
+	}
+	if !opts.Force {
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+			return "", fmt.Errorf("%w: directory %s is not empty", ErrExists, dir)
+		}
+	}
+
+	framework := opts.Framework
+	if framework == "" {
+		framework = FrameworkGin
+	}
+	files, err := renderDir(framework, p)
+	if err != nil {
+		return "", err
+	}
+	if err := writeFiles(dir, files, opts.Force); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// newProject validates opts and builds the template data
+func newProject(opts Options) (*project, error) {
+	if !nameRe.MatchString(opts.Name) {
+		return nil, fmt.Errorf("invalid service name %q: use lower-case letters, digits and dashes", opts.Name)
+	}
+	switch opts.Framework {
+	case "", FrameworkGin, FrameworkGoZero:
+	default:
+		return nil, fmt.Errorf("unsupported framework %q, want %s or %s", opts.Framework, FrameworkGin, FrameworkGoZero)
+	}
+
+	components, err := ParseComponents(opts.Components)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &project{
+		Name:        opts.Name,
+		Module:      opts.Module,
+		Package:     strings.ReplaceAll(opts.Name, "-", ""),
+		EnvPrefix:   strings.ToUpper(Snake(opts.Name)),
+		MoraModule:  opts.MoraModule,
+		MoraVersion: opts.MoraVersion,
+		MoraReplace: opts.MoraReplace,
+	}
+	if p.Module == "" {
+		p.Module = opts.Name
+	}
+	if p.MoraModule == "" {
+		p.MoraModule = DefaultMoraModule
+	}
+	if p.MoraVersion == "" {
+		p.MoraVersion = "v0.0.0"
+	}
+	if !token.IsIdentifier(p.Package) {
+		return nil, fmt.Errorf("invalid service name %q: %q is not a Go identifier", opts.Name, p.Package)
+	}
+
+	for _, c := range components {
+		switch c {
+		case ComponentAuth:
+			p.Auth = true
+		case ComponentConfig:
+			p.Config = true
+		case ComponentLogger:
+			p.Logger = true
+		case ComponentDB:
+			p.DB = true
+		case ComponentCache:
+			p.Cache = true
+		case ComponentHealth:
+			p.Health = true
+		case ComponentMetrics:
+			p.Metrics = true
+		}
+	}
+	return p, nil
+}
+
+// ParseComponents validates component names, accepting comma separated
+// lists and "all"; an empty selection yields DefaultComponents
+func ParseComponents(values []string) ([]string, error) {
+	known := make(map[string]bool, len(AllComponents))
+	for _, c := range AllComponents {
+		known[c] = true
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range values {
+		for _, c := range strings.Split(v, ",") {
+			c = strings.ToLower(strings.TrimSpace(c))
+			switch {
+			case c == "":
+			case c == "all":
+				for _, c := range AllComponents {
+					seen[c] = true
+				}
+			case known[c]:
+				seen[c] = true
+			default:
+				return nil, fmt.Errorf("unknown component %q, want one of %s", c, strings.Join(AllComponents, ", "))
+			}
+		}
+	}
+	if len(seen) == 0 {
+		return append([]string(nil), DefaultComponents...), nil
+	}
+
+	result := make([]string, 0, len(seen))
+	for c := range seen {
+		result = append(result, c)
+	}
+	order := make(map[string]int, len(AllComponents))
+	for i, c := range AllComponents {
+		order[c] = i
+	}
+	sort.Slice(result, func(i, j int) bool { return order[result[i]] < order[result[j]] })
+	return result, nil
+}
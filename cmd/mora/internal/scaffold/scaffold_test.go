@@ -0,0 +1,276 @@
+package scaffold
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// imports returns the import paths of every Go file under dir, by file
+func imports(t *testing.T, dir string) map[string][]string {
+	t.Helper()
+	result := make(map[string][]string)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") {
+			return err
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		for _, imp := range f.Imports {
+			p, _ := strconv.Unquote(imp.Path.Value)
+			result[filepath.ToSlash(rel)] = append(result[filepath.ToSlash(rel)], p)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// importsAny reports whether any file imports path
+func importsAny(all map[string][]string, path string) bool {
+	for _, paths := range all {
+		for _, p := range paths {
+			if p == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		framework  string
+		components []string
+		files      []string
+		absent     []string
+	}{
+		{FrameworkGin, []string{"all"}, []string{"main.go", "go.mod", "config.yaml", "internal/config/config.go", "internal/handler/handler.go"}, nil},
+		{FrameworkGin, []string{"health"}, []string{"main.go", "internal/config/config.go"}, []string{"config.yaml"}},
+		{FrameworkGoZero, []string{"all"}, []string{"main.go", "etc/svc.yaml", "internal/svc/servicecontext.go", "internal/handler/loginhandler.go"}, nil},
+		{FrameworkGoZero, []string{"metrics"}, []string{"main.go", "internal/handler/routes.go"}, []string{"internal/handler/loginhandler.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.framework+"/"+strings.Join(tt.components, ","), func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "svc")
+			got, err := New(Options{Name: "svc", Module: "example.com/svc", Framework: tt.framework, Components: tt.components, Dir: dir})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got != dir {
+				t.Errorf("New() = %s, want %s", got, dir)
+			}
+			for _, f := range tt.files {
+				if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+					t.Errorf("missing %s", f)
+				}
+			}
+			for _, f := range tt.absent {
+				if _, err := os.Stat(filepath.Join(dir, f)); err == nil {
+					t.Errorf("unexpected %s", f)
+				}
+			}
+			if _, err := parser.ParseDir(token.NewFileSet(), dir, nil, 0); err != nil {
+				t.Errorf("main package does not parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewWiresSelectedComponents(t *testing.T) {
+	for _, framework := range []string{FrameworkGin, FrameworkGoZero} {
+		t.Run(framework, func(t *testing.T) {
+			dir := t.TempDir()
+			if _, err := New(Options{Name: "svc", Framework: framework, Components: []string{"db,health"}, Dir: dir}); err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			all := imports(t, dir)
+			for _, p := range []string{"mora/pkg/db", "mora/pkg/health", "mora/pkg/app", "svc/internal/config"} {
+				if !importsAny(all, p) {
+					t.Errorf("%s is not imported", p)
+				}
+			}
+			for _, p := range []string{"mora/pkg/cache", "mora/pkg/auth", "mora/pkg/logger", "github.com/prometheus/client_golang/prometheus/promhttp"} {
+				if importsAny(all, p) {
+					t.Errorf("%s should not be imported", p)
+				}
+			}
+		})
+	}
+}
+
+func TestNewGoMod(t *testing.T) {
+	dir := t.TempDir()
+	_, err := New(Options{Name: "svc", Module: "example.com/svc", MoraVersion: "v1.2.0", MoraReplace: "../mora", Dir: dir})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"module example.com/svc", "mora v1.2.0", "replace mora => ../mora"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("go.mod missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"bad name", Options{Name: "My Service"}},
+		{"bad framework", Options{Name: "svc", Framework: "echo"}},
+		{"bad component", Options{Name: "svc", Components: []string{"kafka"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Dir = t.TempDir()
+			if _, err := New(tt.opts); err == nil {
+				t.Error("New() should fail")
+			}
+		})
+	}
+
+	t.Run("non-empty dir", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "keep"), nil, 0o644)
+		if _, err := New(Options{Name: "svc", Dir: dir}); !errors.Is(err, ErrExists) {
+			t.Errorf("New() error = %v, want ErrExists", err)
+		}
+		if _, err := New(Options{Name: "svc", Dir: dir, Force: true}); err != nil {
+			t.Errorf("New() with Force error = %v", err)
+		}
+	})
+}
+
+func TestParseComponents(t *testing.T) {
+	tests := []struct {
+		input []string
+		want  []string
+	}{
+		{nil, DefaultComponents},
+		{[]string{"health, db", "DB"}, []string{"db", "health"}},
+		{[]string{"all"}, AllComponents},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.input, ";"), func(t *testing.T) {
+			got, err := ParseComponents(tt.input)
+			if err != nil {
+				t.Fatalf("ParseComponents() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseComponents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddMiddleware(t *testing.T) {
+	tests := []struct {
+		gomod string
+		want  string
+	}{
+		{"require github.com/gin-gonic/gin v1.11.0", "gin.HandlerFunc"},
+		{"require github.com/zeromicro/go-zero v1.9.0", "func(next http.HandlerFunc) http.HandlerFunc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			dir := t.TempDir()
+			os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module svc\n\n"+tt.gomod+"\n"), 0o644)
+
+			path, err := AddMiddleware(MiddlewareOptions{Name: "request-id", Dir: dir})
+			if err != nil {
+				t.Fatalf("AddMiddleware() error = %v", err)
+			}
+			if want := filepath.Join(dir, "internal", "middleware", "request_id.go"); path != want {
+				t.Errorf("path = %s, want %s", path, want)
+			}
+			data, _ := os.ReadFile(path)
+			if !strings.Contains(string(data), "func RequestID() "+tt.want) {
+				t.Errorf("unexpected middleware:\n%s", data)
+			}
+
+			if _, err := AddMiddleware(MiddlewareOptions{Name: "request-id", Dir: dir}); !errors.Is(err, ErrExists) {
+				t.Errorf("second AddMiddleware() error = %v, want ErrExists", err)
+			}
+		})
+	}
+
+	if _, err := AddMiddleware(MiddlewareOptions{Name: "x", Dir: t.TempDir()}); err == nil {
+		t.Error("AddMiddleware() without go.mod or framework should fail")
+	}
+}
+
+func TestAddModel(t *testing.T) {
+	dir := t.TempDir()
+	path, err := AddModel(ModelOptions{Name: "OrderItem", Fields: []string{"sku:string,unit_price:decimal", "paid_at:time"}, Dir: dir})
+	if err != nil {
+		t.Fatalf("AddModel() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"type OrderItem struct",
+		"UnitPrice float64   `gorm:\"column:unit_price\" json:\"unit_price\"`",
+		"PaidAt    time.Time",
+		`return "order_items"`,
+		"func NewOrderItemRepo(client *db.Client) *OrderItemRepo",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("model missing %q:\n%s", want, data)
+		}
+	}
+
+	for _, fields := range []string{"name", "id:int", "a:int,a:string"} {
+		if _, err := AddModel(ModelOptions{Name: "thing", Fields: []string{fields}, Dir: t.TempDir()}); err == nil {
+			t.Errorf("AddModel() with fields %q should fail", fields)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		input, snake, pascal, camel string
+	}{
+		{"request-id", "request_id", "RequestID", "requestID"},
+		{"RequestID", "request_id", "RequestID", "requestID"},
+		{"HTTPServer", "http_server", "HTTPServer", "httpServer"},
+		{"order_item", "order_item", "OrderItem", "orderItem"},
+		{"userId2", "user_id2", "UserId2", "userId2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Snake(tt.input); got != tt.snake {
+				t.Errorf("Snake() = %s, want %s", got, tt.snake)
+			}
+			if got := Pascal(tt.input); got != tt.pascal {
+				t.Errorf("Pascal() = %s, want %s", got, tt.pascal)
+			}
+			if got := Camel(tt.input); got != tt.camel {
+				t.Errorf("Camel() = %s, want %s", got, tt.camel)
+			}
+		})
+	}
+
+	for noun, want := range map[string]string{"order": "orders", "category": "categories", "box": "boxes", "day": "days"} {
+		if got := plural(noun); got != want {
+			t.Errorf("plural(%s) = %s, want %s", noun, got, want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Command mora scaffolds services built on the Mora library.
+//
+//	mora new <name> [-framework gin|gozero] [-module path] [-with components]
+//	mora add middleware <name> [-framework gin|gozero]
+//	mora add model <Name> [-fields name:type,...]
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"mora/cmd/mora/internal/scaffold"
+)
+
+const usage = `Usage:
+  mora new <name> [flags]            create a service from a starter
+  mora add middleware <name> [flags] add internal/middleware/<name>.go
+  mora add model <Name> [flags]      add internal/model/<name>.go
+
+Run "mora <command> -h" for the flags of a command.
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "mora:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// run dispatches a command line
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return flag.ErrHelp
+	}
+	switch args[0] {
+	case "new":
+		return runNew(args[1:], stdout, stderr)
+	case "add":
+		if len(args) < 2 {
+			fmt.Fprint(stderr, usage)
+			return flag.ErrHelp
+		}
+		switch args[1] {
+		case "middleware":
+			return runAddMiddleware(args[2:], stdout, stderr)
+		case "model":
+			return runAddModel(args[2:], stdout, stderr)
+		}
+		return fmt.Errorf("unknown generator %q, want middleware or model", args[1])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+	return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+}
+
+// runNew implements mora new
+func runNew(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts := scaffold.Options{}
+	var with string
+	fs.StringVar(&opts.Framework, "framework", scaffold.FrameworkGin, "starter to use: gin or gozero")
+	fs.StringVar(&opts.Module, "module", "", "Go module path (default the service name)")
+	fs.StringVar(&with, "with", strings.Join(scaffold.DefaultComponents, ","),
+		"components to wire, comma separated or \"all\": "+strings.Join(scaffold.AllComponents, ", "))
+	fs.StringVar(&opts.Dir, "dir", "", "output directory (default the service name)")
+	fs.StringVar(&opts.MoraVersion, "mora-version", "", "version of the Mora module to require")
+	fs.StringVar(&opts.MoraReplace, "mora-replace", "", "local Mora checkout to use through a replace directive")
+	fs.BoolVar(&opts.Force, "force", false, "write into a non-empty directory, overwriting files")
+	name, err := parseWithName(fs, args, "service name")
+	if err != nil {
+		return err
+	}
+	opts.Name = name
+	opts.Components = []string{with}
+
+	dir, err := scaffold.New(opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "created %s in %s\n\n  cd %s\n  go mod tidy\n  go run .\n", opts.Name, dir, dir)
+	return nil
+}
+
+// runAddMiddleware implements mora add middleware
+func runAddMiddleware(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("add middleware", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts := scaffold.MiddlewareOptions{}
+	fs.StringVar(&opts.Framework, "framework", "", "gin or gozero (default detected from go.mod)")
+	fs.StringVar(&opts.Dir, "dir", ".", "service root")
+	fs.BoolVar(&opts.Force, "force", false, "overwrite an existing file")
+	name, err := parseWithName(fs, args, "middleware name")
+	if err != nil {
+		return err
+	}
+	opts.Name = name
+
+	path, err := scaffold.AddMiddleware(opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "created", path)
+	return nil
+}
+
+// runAddModel implements mora add model
+func runAddModel(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("add model", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts := scaffold.ModelOptions{}
+	var fields string
+	fs.StringVar(&fields, "fields", "", "fields as name:type pairs, comma separated")
+	fs.StringVar(&opts.Table, "table", "", "table name (default the plural snake case name)")
+	fs.StringVar(&opts.Dir, "dir", ".", "service root")
+	fs.BoolVar(&opts.Force, "force", false, "overwrite an existing file")
+	name, err := parseWithName(fs, args, "model name")
+	if err != nil {
+		return err
+	}
+	opts.Name = name
+	opts.Fields = []string{fields}
+
+	path, err := scaffold.AddModel(opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "created", path)
+	return nil
+}
+
+// parseWithName parses flags given before or after the single positional
+// argument, which is returned
+func parseWithName(fs *flag.FlagSet, args []string, what string) (string, error) {
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return "", err
+		}
+	}
+	if name == "" || fs.NArg() > 0 {
+		return "", fmt.Errorf("expected one %s", what)
+	}
+	return name, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "svc")
+	var stdout, stderr bytes.Buffer
+
+	// Flags may follow the name
+	if err := run([]string{"new", "svc", "-dir", dir, "-framework", "gozero", "-with", "health"}, &stdout, &stderr); err != nil {
+		t.Fatalf("new: %v\n%s", err, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "etc", "svc.yaml")); err != nil {
+		t.Errorf("go-zero config not generated: %v", err)
+	}
+
+	if err := run([]string{"add", "model", "-dir", dir, "order", "-fields", "amount:float64"}, &stdout, &stderr); err != nil {
+		t.Fatalf("add model: %v", err)
+	}
+	if err := run([]string{"add", "middleware", "audit", "-dir", dir}, &stdout, &stderr); err != nil {
+		t.Fatalf("add middleware: %v", err)
+	}
+	if !strings.Contains(stdout.String(), filepath.Join("internal", "middleware", "audit.go")) {
+		t.Errorf("stdout = %s", stdout.String())
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no command", nil},
+		{"unknown command", []string{"build"}},
+		{"unknown generator", []string{"add", "handler", "x"}},
+		{"missing name", []string{"new"}},
+		{"two names", []string{"new", "a", "b"}},
+		{"unknown flag", []string{"new", "svc", "-nope"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := run(tt.args, &out, &out)
+			if err == nil {
+				t.Error("run() should fail")
+			}
+			if tt.args == nil && err != flag.ErrHelp {
+				t.Errorf("run() error = %v, want flag.ErrHelp", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+// Package app runs a service's components under one lifecycle: components
+// start in the order they were added, the app waits for a signal, a
+// component failure or Stop, then stops them in reverse order, each under
+// its own timeout.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"mora/pkg/logger"
+)
+
+// ErrRunning is returned when Run is called on an app that is already running
+var ErrRunning = errors.New("app: already running")
+
+// Component is a part of the service with a start and stop phase
+type Component interface {
+	// Name identifies the component in logs and errors
+	Name() string
+	// Start brings the component up and returns once it is ready; long
+	// running work belongs in goroutines. ctx stays valid until the app
+	// has stopped every component.
+	Start(ctx context.Context) error
+	// Stop releases the component, returning by the time ctx expires
+	Stop(ctx context.Context) error
+}
+
+// Runner is implemented by components that can fail after Start, such as
+// servers; an error received from Err shuts the app down
+type Runner interface {
+	Err() <-chan error
+}
+
+// Hook runs at a lifecycle stage
+type Hook func(ctx context.Context) error
+
+// Config configures an App
+type Config struct {
+	Name string `json:"name" yaml:"name" env:"APP_NAME"`
+	// StartTimeout bounds each component's Start
+	StartTimeout time.Duration `json:"start_timeout" yaml:"start_timeout" env:"APP_START_TIMEOUT"`
+	// StopTimeout bounds each component's Stop
+	StopTimeout time.Duration `json:"stop_timeout" yaml:"stop_timeout" env:"APP_STOP_TIMEOUT"`
+	// ShutdownTimeout bounds the whole shutdown, hooks included
+	ShutdownTimeout time.Duration `json:"shutdown_timeout" yaml:"shutdown_timeout" env:"APP_SHUTDOWN_TIMEOUT"`
+	// Signals trigger shutdown; a second signal abandons it
+	Signals []os.Signal    `json:"-" yaml:"-"`
+	Logger  *logger.Logger `json:"-" yaml:"-"`
+}
+
+// DefaultConfig returns default app configuration
+func DefaultConfig() Config {
+	return Config{
+		Name:            "mora-service",
+		StartTimeout:    30 * time.Second,
+		StopTimeout:     10 * time.Second,
+		ShutdownTimeout: 30 * time.Second,
+		Signals:         []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+	}
+}
+
+// Option customizes a component added to the app
+type Option func(*entry)
+
+// WithStartTimeout overrides Config.StartTimeout for one component
+func WithStartTimeout(d time.Duration) Option {
+	return func(e *entry) { e.startTimeout = d }
+}
+
+// WithStopTimeout overrides Config.StopTimeout for one component
+func WithStopTimeout(d time.Duration) Option {
+	return func(e *entry) { e.stopTimeout = d }
+}
+
+// entry is a registered component with its timeouts
+type entry struct {
+	Component
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+}
+
+// App composes components under one lifecycle
+type App struct {
+	cfg Config
+	log *logger.Logger
+
+	mu          sync.Mutex
+	entries     []*entry
+	beforeStart []Hook
+	afterStart  []Hook
+	beforeStop  []Hook
+	afterStop   []Hook
+	running     bool
+	stop        chan struct{}
+	stopOnce    sync.Once
+}
+
+// New creates an app; zero timeouts fall back to DefaultConfig
+func New(cfg Config) *App {
+	defaults := DefaultConfig()
+	if cfg.Name == "" {
+		cfg.Name = defaults.Name
+	}
+	if cfg.StartTimeout <= 0 {
+		cfg.StartTimeout = defaults.StartTimeout
+	}
+	if cfg.StopTimeout <= 0 {
+		cfg.StopTimeout = defaults.StopTimeout
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = defaults.ShutdownTimeout
+	}
+	log := cfg.Logger
+	if log == nil {
+		log = logger.NewDefault()
+	}
+	return &App{cfg: cfg, log: log, stop: make(chan struct{})}
+}
+
+// Add registers a component; components start in the order they are added
+// and stop in reverse, so add dependencies such as databases first
+func (a *App) Add(c Component, opts ...Option) *App {
+	e := &entry{Component: c, startTimeout: a.cfg.StartTimeout, stopTimeout: a.cfg.StopTimeout}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, e)
+	return a
+}
+
+// BeforeStart registers a hook run before any component starts; an error
+// aborts Run
+func (a *App) BeforeStart(h Hook) *App {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.beforeStart = append(a.beforeStart, h)
+	return a
+}
+
+// AfterStart registers a hook run once every component has started; an
+// error shuts the app down
+func (a *App) AfterStart(h Hook) *App {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.afterStart = append(a.afterStart, h)
+	return a
+}
+
+// BeforeStop registers a hook run before components stop, e.g. to fail
+// readiness probes so load balancers drain the instance
+func (a *App) BeforeStop(h Hook) *App {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.beforeStop = append(a.beforeStop, h)
+	return a
+}
+
+// AfterStop registers a hook run after every component has stopped
+func (a *App) AfterStop(h Hook) *App {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.afterStop = append(a.afterStop, h)
+	return a
+}
+
+// Stop asks a running app to shut down; Run returns once it has
+func (a *App) Stop() {
+	a.stopOnce.Do(func() { close(a.stop) })
+}
+
+// Run starts the components, blocks until ctx is cancelled, a signal
+// arrives, a component fails or Stop is called, then shuts down. The
+// returned error joins startup, runtime and shutdown failures.
+func (a *App) Run(ctx context.Context) error {
+	a.mu.Lock()
+	if a.running {
+		a.mu.Unlock()
+		return ErrRunning
+	}
+	a.running = true
+	entries := append([]*entry(nil), a.entries...)
+	beforeStart := append([]Hook(nil), a.beforeStart...)
+	afterStart := append([]Hook(nil), a.afterStart...)
+	a.mu.Unlock()
+
+	signals := make(chan os.Signal, 2)
+	if len(a.cfg.Signals) > 0 {
+		signal.Notify(signals, a.cfg.Signals...)
+		defer signal.Stop(signals)
+	}
+
+	if err := runHooks(ctx, beforeStart); err != nil {
+		return fmt.Errorf("before start hook: %w", err)
+	}
+
+	// Components keep the lifetime context until shutdown has finished
+	lifetime, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	failures := make(chan error, len(entries))
+	watching := make(chan struct{})
+	var started []*entry
+	var runErr error
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			runErr = err
+			break
+		}
+		if err := a.startComponent(lifetime, e); err != nil {
+			runErr = fmt.Errorf("failed to start %s: %w", e.Name(), err)
+			break
+		}
+		started = append(started, e)
+		if r, ok := e.Component.(Runner); ok {
+			go watch(e.Name(), r, failures, watching)
+		}
+		a.log.Debugf("%s: started %s", a.cfg.Name, e.Name())
+	}
+
+	if runErr == nil {
+		if err := runHooks(ctx, afterStart); err != nil {
+			runErr = fmt.Errorf("after start hook: %w", err)
+		}
+	}
+	if runErr == nil {
+		a.log.Infof("%s started with %d components", a.cfg.Name, len(started))
+		select {
+		case <-ctx.Done():
+			a.log.Infof("%s: context done, shutting down", a.cfg.Name)
+		case sig := <-signals:
+			a.log.Infof("%s: received %s, shutting down", a.cfg.Name, sig)
+		case err := <-failures:
+			a.log.Errorf("%s: %v, shutting down", a.cfg.Name, err)
+			runErr = err
+		case <-a.stop:
+			a.log.Infof("%s: stop requested, shutting down", a.cfg.Name)
+		}
+	}
+	close(watching)
+
+	stopErr := a.shutdown(lifetime, started, signals)
+	return errors.Join(runErr, stopErr)
+}
+
+// startComponent runs e.Start, giving up after its start timeout
+func (a *App) startComponent(ctx context.Context, e *entry) error {
+	done := make(chan error, 1)
+	go func() { done <- e.Start(ctx) }()
+
+	timer := time.NewTimer(e.startTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("timed out after %s", e.startTimeout)
+	}
+}
+
+// shutdown runs the stop hooks and stops started components in reverse
+// order; a second signal cancels whatever is still waiting
+func (a *App) shutdown(lifetime context.Context, started []*entry, signals <-chan os.Signal) error {
+	a.mu.Lock()
+	beforeStop := append([]Hook(nil), a.beforeStop...)
+	afterStop := append([]Hook(nil), a.afterStop...)
+	a.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(lifetime), a.cfg.ShutdownTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case sig := <-signals:
+			a.log.Warnf("%s: received %s again, abandoning shutdown", a.cfg.Name, sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var errs []error
+	if err := runHooks(ctx, beforeStop); err != nil {
+		errs = append(errs, fmt.Errorf("before stop hook: %w", err))
+	}
+	for i := len(started) - 1; i >= 0; i-- {
+		e := started[i]
+		stopCtx, stopCancel := context.WithTimeout(ctx, e.stopTimeout)
+		err := e.Stop(stopCtx)
+		stopCancel()
+		if err != nil {
+			a.log.Errorf("%s: failed to stop %s: %v", a.cfg.Name, e.Name(), err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", e.Name(), err))
+			continue
+		}
+		a.log.Debugf("%s: stopped %s", a.cfg.Name, e.Name())
+	}
+	if err := runHooks(ctx, afterStop); err != nil {
+		errs = append(errs, fmt.Errorf("after stop hook: %w", err))
+	}
+
+	a.log.Infof("%s stopped", a.cfg.Name)
+	return errors.Join(errs...)
+}
+
+// runHooks runs hooks in order, stopping at the first error
+func runHooks(ctx context.Context, hooks []Hook) error {
+	for _, h := range hooks {
+		if err := h(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watch forwards the first runtime error of a component until done closes
+func watch(name string, r Runner, failures chan<- error, done <-chan struct{}) {
+	select {
+	case err, ok := <-r.Err():
+		if ok && err != nil {
+			failures <- fmt.Errorf("%s failed: %w", name, err)
+		}
+	case <-done:
+	}
+}
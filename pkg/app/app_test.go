@@ -0,0 +1,210 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder collects lifecycle events in order
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.events, ",")
+}
+
+func (r *recorder) component(name string, startErr error) Component {
+	return Func(name,
+		func(context.Context) error { r.add("start " + name); return startErr },
+		func(context.Context) error { r.add("stop " + name); return nil },
+	)
+}
+
+func (r *recorder) hook(name string) Hook {
+	return func(context.Context) error { r.add(name); return nil }
+}
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.Signals = nil
+	return cfg
+}
+
+func TestRunOrder(t *testing.T) {
+	rec := &recorder{}
+	a := New(testConfig())
+	a.Add(rec.component("db", nil)).
+		Add(rec.component("cache", nil)).
+		Add(rec.component("http", nil))
+	a.BeforeStart(rec.hook("before start")).
+		AfterStart(func(context.Context) error { rec.add("after start"); a.Stop(); return nil }).
+		BeforeStop(rec.hook("before stop")).
+		AfterStop(rec.hook("after stop"))
+
+	if err := a.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := "before start,start db,start cache,start http,after start,before stop,stop http,stop cache,stop db,after stop"
+	if got := rec.String(); got != want {
+		t.Errorf("events = %s\nwant %s", got, want)
+	}
+
+	if err := a.Run(context.Background()); !errors.Is(err, ErrRunning) {
+		t.Errorf("second Run() error = %v, want ErrRunning", err)
+	}
+}
+
+func TestStartFailureStopsStarted(t *testing.T) {
+	rec := &recorder{}
+	a := New(testConfig())
+	a.Add(rec.component("db", nil)).
+		Add(rec.component("cache", errors.New("refused"))).
+		Add(rec.component("http", nil))
+
+	err := a.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "failed to start cache: refused") {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got, want := rec.String(), "start db,start cache,stop db"; got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	t.Run("start", func(t *testing.T) {
+		a := New(testConfig())
+		a.Add(Func("slow", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}, nil), WithStartTimeout(20*time.Millisecond))
+
+		if err := a.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Errorf("Run() error = %v, want start timeout", err)
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		rec := &recorder{}
+		a := New(testConfig())
+		a.Add(rec.component("db", nil))
+		a.Add(Func("stuck", nil, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}), WithStopTimeout(20*time.Millisecond))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		a.AfterStart(func(context.Context) error { cancel(); return nil })
+		err := a.Run(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Run() error = %v, want deadline exceeded", err)
+		}
+		if !strings.Contains(rec.String(), "stop db") {
+			t.Errorf("a stuck component should not block the others: %s", rec)
+		}
+	})
+}
+
+func TestBackgroundFailure(t *testing.T) {
+	rec := &recorder{}
+	a := New(testConfig())
+	a.Add(rec.component("db", nil))
+	a.Add(Background("consumer", func(ctx context.Context) error {
+		return errors.New("broker gone")
+	}))
+
+	done := make(chan error, 1)
+	go func() { done <- a.Run(context.Background()) }()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "consumer failed: broker gone") {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("app did not stop after a component failed")
+	}
+	if !strings.Contains(rec.String(), "stop db") {
+		t.Errorf("events = %s, want db stopped", rec)
+	}
+}
+
+func TestBackgroundStop(t *testing.T) {
+	var stopped bool
+	a := New(testConfig())
+	a.Add(Background("consumer", func(ctx context.Context) error {
+		<-ctx.Done()
+		stopped = true
+		return ctx.Err()
+	}))
+	a.AfterStart(func(context.Context) error { a.Stop(); return nil })
+
+	if err := a.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !stopped {
+		t.Error("background function should see its context cancelled")
+	}
+}
+
+func TestHTTPServer(t *testing.T) {
+	srv := HTTPServer("http", &http.Server{
+		Addr: "127.0.0.1:0",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "pong")
+		}),
+	})
+	a := New(testConfig())
+	a.Add(srv)
+
+	var body string
+	a.AfterStart(func(context.Context) error {
+		defer a.Stop()
+		addr := srv.(interface{ Addr() net.Addr }).Addr()
+		resp, err := http.Get("http://" + addr.String())
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		body = string(b)
+		return err
+	})
+
+	if err := a.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if body != "pong" {
+		t.Errorf("body = %q, want pong", body)
+	}
+}
+
+func TestHTTPServerPortInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	a := New(testConfig())
+	a.Add(HTTPServer("http", &http.Server{Addr: ln.Addr().String()}))
+	if err := a.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "failed to listen") {
+		t.Errorf("Run() error = %v, want listen failure", err)
+	}
+}
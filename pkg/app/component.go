@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// funcComponent adapts a pair of functions to Component
+type funcComponent struct {
+	name  string
+	start func(ctx context.Context) error
+	stop  func(ctx context.Context) error
+}
+
+// Func builds a component from start and stop functions, either may be nil.
+// A scheduler, for example, is app.Func("scheduler", startFn, s.Stop).
+func Func(name string, start, stop func(ctx context.Context) error) Component {
+	return &funcComponent{name: name, start: start, stop: stop}
+}
+
+// Name implements Component
+func (c *funcComponent) Name() string { return c.name }
+
+// Start implements Component
+func (c *funcComponent) Start(ctx context.Context) error {
+	if c.start == nil {
+		return nil
+	}
+	return c.start(ctx)
+}
+
+// Stop implements Component
+func (c *funcComponent) Stop(ctx context.Context) error {
+	if c.stop == nil {
+		return nil
+	}
+	return c.stop(ctx)
+}
+
+// Closer wraps a client that only needs closing at shutdown, such as the
+// pkg/db and pkg/cache clients
+func Closer(name string, close func() error) Component {
+	return Func(name, nil, func(context.Context) error { return close() })
+}
+
+// background runs a blocking function until stopped
+type background struct {
+	name   string
+	run    func(ctx context.Context) error
+	errs   chan error
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Background wraps a blocking function, such as an mq consumer's Consume,
+// as a component. Stop cancels its context and waits for it to return;
+// returning before that, with or without an error, shuts the app down.
+func Background(name string, run func(ctx context.Context) error) Component {
+	return &background{name: name, run: run, errs: make(chan error, 1)}
+}
+
+// Name implements Component
+func (b *background) Name() string { return b.name }
+
+// Start implements Component
+func (b *background) Start(ctx context.Context) error {
+	ctx, b.cancel = context.WithCancel(ctx)
+	b.done = make(chan struct{})
+	go func() {
+		defer close(b.done)
+		err := b.run(ctx)
+		if err != nil && ctx.Err() == nil {
+			b.errs <- err
+		} else if err == nil && ctx.Err() == nil {
+			b.errs <- errors.New("exited unexpectedly")
+		}
+	}()
+	return nil
+}
+
+// Stop implements Component
+func (b *background) Stop(ctx context.Context) error {
+	if b.cancel == nil {
+		return nil
+	}
+	b.cancel()
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Err implements Runner
+func (b *background) Err() <-chan error { return b.errs }
+
+// httpServer runs a net/http server
+type httpServer struct {
+	name string
+	srv  *http.Server
+	errs chan error
+	mu   sync.Mutex
+	addr net.Addr
+}
+
+// HTTPServer wraps srv, e.g. one whose Handler is a gin engine, as a
+// component. Start binds srv.Addr so port conflicts fail startup, and Stop
+// shuts the server down gracefully.
+func HTTPServer(name string, srv *http.Server) Component {
+	return &httpServer{name: name, srv: srv, errs: make(chan error, 1)}
+}
+
+// Name implements Component
+func (s *httpServer) Name() string { return s.name }
+
+// Start implements Component
+func (s *httpServer) Start(ctx context.Context) error {
+	addr := s.srv.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	var lc net.ListenConfig
+	ln, err := lc.Listen(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.mu.Lock()
+	s.addr = ln.Addr()
+	s.mu.Unlock()
+
+	go func() {
+		var err error
+		if s.srv.TLSConfig != nil {
+			err = s.srv.ServeTLS(ln, "", "")
+		} else {
+			err = s.srv.Serve(ln)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.errs <- err
+		}
+	}()
+	return nil
+}
+
+// Stop implements Component
+func (s *httpServer) Stop(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+// Err implements Runner
+func (s *httpServer) Err() <-chan error { return s.errs }
+
+// Addr returns the address the server listens on, nil before Start
+func (s *httpServer) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
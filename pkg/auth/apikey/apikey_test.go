@@ -0,0 +1,103 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mora/pkg/db"
+)
+
+func TestGenerate(t *testing.T) {
+	key, hash, err := Generate("mk_")
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.HasPrefix(key, "mk_") || len(key) != 43 {
+		t.Errorf("key = %q", key)
+	}
+	if hash != Hash(key) || len(hash) != 64 {
+		t.Errorf("hash = %q, want Hash(key)", hash)
+	}
+	if other, _, _ := Generate("mk_"); other == key {
+		t.Error("Generate() returned the same key twice")
+	}
+}
+
+func TestKeyClaims(t *testing.T) {
+	k := &Key{ID: "k1", Name: "billing", UserID: "svc-1", Scopes: []string{"orders:*"}}
+	claims := k.Claims()
+	if claims.UserID != "svc-1" || claims.ID != "k1" || !claims.HasPermission("orders:read") {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+// testValidator checks a validator holding active and expired keys
+func testValidator(t *testing.T, v KeyValidator, active, expired string) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr error
+	}{
+		{name: "active", key: active},
+		{name: "expired", key: expired, wantErr: ErrExpiredKey},
+		{name: "unknown", key: "mk_unknown", wantErr: ErrInvalidKey},
+		{name: "hash is not a key", key: Hash(active), wantErr: ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := v.ValidateKey(context.Background(), tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (k.ID != "k1" || k.Metadata["team"] != "billing" || len(k.Scopes) != 2) {
+				t.Errorf("key = %+v", k)
+			}
+		})
+	}
+}
+
+func testKeys() (active, expired *Key) {
+	active = &Key{ID: "k1", Name: "billing", UserID: "svc-1", Scopes: []string{"orders:read", "users:read"},
+		Metadata: map[string]string{"team": "billing"}, ExpiresAt: time.Now().Add(time.Hour)}
+	expired = &Key{ID: "k2", Name: "old", UserID: "svc-1", ExpiresAt: time.Now().Add(-time.Hour)}
+	return active, expired
+}
+
+func TestStaticValidator(t *testing.T) {
+	active, expired := testKeys()
+	testValidator(t, NewStaticValidator(map[string]*Key{Hash("mk_active"): active, Hash("mk_expired"): expired}), "mk_active", "mk_expired")
+}
+
+func TestSQLValidator(t *testing.T) {
+	cfg := db.DefaultConfig()
+	cfg.Driver = "sqlite3"
+	cfg.DSN = filepath.Join(t.TempDir(), "keys.db")
+	client, err := db.NewSQLX(cfg)
+	if err != nil {
+		t.Fatalf("NewSQLX() error = %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	v := NewSQLValidator(client, "")
+	if err := v.CreateTable(ctx); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	active, expired := testKeys()
+	if err := v.Save(ctx, Hash("mk_active"), active); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	v.Save(ctx, Hash("mk_expired"), expired)
+	testValidator(t, v, "mk_active", "mk_expired")
+
+	if err := v.Delete(ctx, "k1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := v.ValidateKey(ctx, "mk_active"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("ValidateKey() after Delete error = %v, want ErrInvalidKey", err)
+	}
+}
@@ -0,0 +1,75 @@
+package apikey
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mora/pkg/cache"
+)
+
+// RedisValidator validates keys stored in Redis through pkg/cache as JSON
+// under their hash; keys with an expiry are dropped by Redis when they expire
+type RedisValidator struct {
+	client *cache.Client
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisValidator creates a validator whose keys start with prefix,
+// "apikey:" when empty
+func NewRedisValidator(client *cache.Client, prefix string) *RedisValidator {
+	if prefix == "" {
+		prefix = "apikey:"
+	}
+	return &RedisValidator{client: client, prefix: prefix, now: time.Now}
+}
+
+// Save stores a key under its hash
+func (v *RedisValidator) Save(ctx context.Context, hash string, k *Key) error {
+	data, err := json.Marshal(k)
+	if err != nil {
+		return fmt.Errorf("apikey: failed to encode key: %w", err)
+	}
+	var ttl time.Duration
+	if !k.ExpiresAt.IsZero() {
+		if ttl = k.ExpiresAt.Sub(v.now()); ttl <= 0 {
+			return ErrExpiredKey
+		}
+	}
+	if err := v.client.Set(ctx, v.prefix+hash, data, ttl); err != nil {
+		return fmt.Errorf("apikey: failed to save key: %w", err)
+	}
+	return nil
+}
+
+// Delete revokes the key with the given hash
+func (v *RedisValidator) Delete(ctx context.Context, hash string) error {
+	if err := v.client.Delete(ctx, v.prefix+hash); err != nil {
+		return fmt.Errorf("apikey: failed to delete key: %w", err)
+	}
+	return nil
+}
+
+// ValidateKey implements KeyValidator
+func (v *RedisValidator) ValidateKey(ctx context.Context, key string) (*Key, error) {
+	data, err := v.client.GetBytes(ctx, v.prefix+Hash(key))
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("apikey: failed to load key: %w", err)
+	}
+	var k Key
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, fmt.Errorf("apikey: failed to decode key: %w", err)
+	}
+	if k.expired(v.now()) {
+		return nil, ErrExpiredKey
+	}
+	return &k, nil
+}
@@ -0,0 +1,116 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mora/pkg/db"
+)
+
+// SQLValidator validates keys stored in a table accessed through pkg/db
+type SQLValidator struct {
+	client *db.SQLXClient
+	table  string
+	now    func() time.Time
+}
+
+// NewSQLValidator creates a validator on table, "api_keys" when empty; call
+// CreateTable or create it with an equivalent migration
+func NewSQLValidator(client *db.SQLXClient, table string) *SQLValidator {
+	if table == "" {
+		table = "api_keys"
+	}
+	return &SQLValidator{client: client, table: table, now: time.Now}
+}
+
+// CreateTable creates the table if it does not exist
+func (v *SQLValidator) CreateTable(ctx context.Context) error {
+	_, err := v.client.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		key_hash CHAR(64) PRIMARY KEY,
+		id VARCHAR(64) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		user_id VARCHAR(64) NOT NULL,
+		scopes TEXT NOT NULL,
+		metadata TEXT NOT NULL,
+		expires_at BIGINT NOT NULL
+	)`, v.table))
+	if err != nil {
+		return fmt.Errorf("apikey: failed to create table: %w", err)
+	}
+	return nil
+}
+
+// keyRow is the table representation of a Key; scopes are space separated,
+// metadata is JSON and expires_at is Unix milliseconds, 0 for never
+type keyRow struct {
+	KeyHash   string `db:"key_hash"`
+	ID        string `db:"id"`
+	Name      string `db:"name"`
+	UserID    string `db:"user_id"`
+	Scopes    string `db:"scopes"`
+	Metadata  string `db:"metadata"`
+	ExpiresAt int64  `db:"expires_at"`
+}
+
+// Save stores a key under its hash
+func (v *SQLValidator) Save(ctx context.Context, hash string, k *Key) error {
+	metadata, err := json.Marshal(k.Metadata)
+	if err != nil {
+		return fmt.Errorf("apikey: failed to encode metadata: %w", err)
+	}
+	var expiresAt int64
+	if !k.ExpiresAt.IsZero() {
+		expiresAt = k.ExpiresAt.UnixMilli()
+	}
+	_, err = v.client.NamedExec(ctx, fmt.Sprintf(`INSERT INTO %s (key_hash, id, name, user_id, scopes, metadata, expires_at)
+		VALUES (:key_hash, :id, :name, :user_id, :scopes, :metadata, :expires_at)`, v.table), keyRow{
+		KeyHash:   hash,
+		ID:        k.ID,
+		Name:      k.Name,
+		UserID:    k.UserID,
+		Scopes:    strings.Join(k.Scopes, " "),
+		Metadata:  string(metadata),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("apikey: failed to save key: %w", err)
+	}
+	return nil
+}
+
+// Delete revokes the key with the given ID
+func (v *SQLValidator) Delete(ctx context.Context, id string) error {
+	query := v.client.DB().Rebind(fmt.Sprintf("DELETE FROM %s WHERE id = ?", v.table))
+	if _, err := v.client.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("apikey: failed to delete key: %w", err)
+	}
+	return nil
+}
+
+// ValidateKey implements KeyValidator
+func (v *SQLValidator) ValidateKey(ctx context.Context, key string) (*Key, error) {
+	var r keyRow
+	query := v.client.DB().Rebind(fmt.Sprintf("SELECT * FROM %s WHERE key_hash = ?", v.table))
+	if err := v.client.Get(ctx, &r, query, Hash(key)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidKey
+		}
+		return nil, fmt.Errorf("apikey: failed to load key: %w", err)
+	}
+	k := &Key{ID: r.ID, Name: r.Name, UserID: r.UserID, Scopes: strings.Fields(r.Scopes)}
+	if err := json.Unmarshal([]byte(r.Metadata), &k.Metadata); err != nil {
+		return nil, fmt.Errorf("apikey: failed to decode metadata: %w", err)
+	}
+	if r.ExpiresAt != 0 {
+		k.ExpiresAt = time.UnixMilli(r.ExpiresAt)
+	}
+	if k.expired(v.now()) {
+		return nil, ErrExpiredKey
+	}
+	return k, nil
+}
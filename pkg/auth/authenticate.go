@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	moraerrors "mora/pkg/errors"
+)
+
+// tokenErrors are the validation failures named to clients; any other
+// failure is reported as ErrInvalidToken
+var tokenErrors = []error{ErrExpiredToken, ErrMalformedToken, ErrInvalidTokenType, ErrInvalidIssuer, ErrInvalidAudience}
+
+// Authenticate validates an access token and, when checker is set, rejects
+// it once revoked. It is the check every auth middleware runs; the coded
+// error is Unauthorized with a message naming the failure, or Unavailable
+// when checker fails, and wraps the cause, such as ErrExpiredToken.
+func Authenticate(ctx context.Context, token string, keyfunc Keyfunc, opts ValidateOptions, checker RevocationChecker) (*Claims, *moraerrors.Error) {
+	claims, err := ParseAccessToken(token, keyfunc, opts)
+	if err != nil {
+		message := ErrInvalidToken.Error()
+		for _, known := range tokenErrors {
+			if errors.Is(err, known) {
+				message = known.Error()
+				break
+			}
+		}
+		return nil, moraerrors.ErrUnauthorized.WithMessage(message).Wrap(err)
+	}
+
+	// Fail closed when the blacklist is unreachable
+	if err := CheckRevoked(ctx, checker, claims); err != nil {
+		if errors.Is(err, ErrTokenRevoked) {
+			return nil, moraerrors.ErrUnauthorized.WithMessage(err.Error()).Wrap(err)
+		}
+		return nil, moraerrors.ErrUnavailable.WithMessage("unable to verify token").Wrap(err)
+	}
+	return claims, nil
+}
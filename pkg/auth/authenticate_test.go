@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	moraerrors "mora/pkg/errors"
+)
+
+// failingChecker is a RevocationChecker whose blacklist is unreachable
+type failingChecker struct{}
+
+func (failingChecker) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestAuthenticate(t *testing.T) {
+	keyfunc := HMACKeyfunc([]byte("test-secret"))
+	pair, _ := GenerateTokenPair("user123", "testuser", TokenConfig{Secret: "test-secret", AccessTTL: time.Minute, RefreshTTL: time.Hour})
+	expired, _ := GenerateToken("user123", "testuser", "test-secret", -time.Minute)
+	claims, _ := ValidateAccessToken(pair.AccessToken, "test-secret")
+
+	tests := []struct {
+		name        string
+		token       string
+		checker     RevocationChecker
+		wantErr     *moraerrors.Error
+		wantMessage string
+		wantCause   error
+	}{
+		{name: "valid", token: pair.AccessToken, checker: revokedIDs{}},
+		{name: "expired", token: expired, wantErr: moraerrors.ErrUnauthorized, wantMessage: "token expired", wantCause: ErrExpiredToken},
+		{name: "refresh token", token: pair.RefreshToken, wantErr: moraerrors.ErrUnauthorized, wantMessage: "invalid token type", wantCause: ErrInvalidTokenType},
+		{name: "malformed", token: "not-a-token", wantErr: moraerrors.ErrUnauthorized, wantMessage: "malformed token", wantCause: ErrMalformedToken},
+		{name: "revoked", token: pair.AccessToken, checker: revokedIDs{claims.ID: true}, wantErr: moraerrors.ErrUnauthorized, wantMessage: "token revoked", wantCause: ErrTokenRevoked},
+		{name: "checker unavailable", token: pair.AccessToken, checker: failingChecker{}, wantErr: moraerrors.ErrUnavailable, wantMessage: "unable to verify token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Authenticate(context.Background(), tt.token, keyfunc, ValidateOptions{}, tt.checker)
+			if tt.wantErr == nil {
+				if err != nil || got.UserID != "user123" {
+					t.Fatalf("Authenticate() = %+v, %v", got, err)
+				}
+				return
+			}
+			if err == nil || !errors.Is(err, tt.wantErr) || err.Message != tt.wantMessage {
+				t.Fatalf("Authenticate() error = %v, want %v with message %q", err, tt.wantErr, tt.wantMessage)
+			}
+			if tt.wantCause != nil && !errors.Is(err, tt.wantCause) {
+				t.Errorf("Authenticate() error = %v, want cause %v", err, tt.wantCause)
+			}
+		})
+	}
+}
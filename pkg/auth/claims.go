@@ -1,6 +1,10 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -10,16 +14,24 @@ import (
 type Claims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username,omitempty"`
+	// TokenType is TokenTypeAccess or TokenTypeRefresh; empty for tokens from GenerateToken
+	TokenType string `json:"token_type,omitempty"`
+	// Roles and Permissions are checked by the adapters' RequireRole and
+	// RequirePermission middleware
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// NewClaims creates a new Claims with standard fields
+// NewClaims creates a new Claims with standard fields and a random token
+// ID, so every token can be revoked
 func NewClaims(userID, username string, ttl time.Duration) *Claims {
 	now := time.Now()
 	return &Claims{
 		UserID:   userID,
 		Username: username,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newTokenID(),
 			Subject:   userID,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
@@ -34,3 +46,30 @@ func (c *Claims) IsExpired() bool {
 	}
 	return c.ExpiresAt.Time.Before(time.Now())
 }
+
+// HasRole reports whether the claims include role
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// HasPermission reports whether the claims grant permission, either exactly
+// or through a wildcard: "*" grants everything and "orders:*" grants every
+// permission starting with "orders:"
+func (c *Claims) HasPermission(permission string) bool {
+	for _, p := range c.Permissions {
+		if p == permission || p == "*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(p, "*"); ok && strings.HasSuffix(prefix, ":") && strings.HasPrefix(permission, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// newTokenID returns a random token ID for the jti claim
+func newTokenID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
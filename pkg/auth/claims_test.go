@@ -0,0 +1,30 @@
+package auth
+
+import "testing"
+
+func TestClaimsRolesAndPermissions(t *testing.T) {
+	claims := &Claims{
+		Roles:       []string{"editor", "viewer"},
+		Permissions: []string{"orders:*", "users:read"},
+	}
+	tests := []struct {
+		name  string
+		check func() bool
+		want  bool
+	}{
+		{name: "has role", check: func() bool { return claims.HasRole("editor") }, want: true},
+		{name: "lacks role", check: func() bool { return claims.HasRole("admin") }, want: false},
+		{name: "exact permission", check: func() bool { return claims.HasPermission("users:read") }, want: true},
+		{name: "wildcard permission", check: func() bool { return claims.HasPermission("orders:write") }, want: true},
+		{name: "wildcard needs separator", check: func() bool { return claims.HasPermission("ordersx") }, want: false},
+		{name: "missing permission", check: func() bool { return claims.HasPermission("users:write") }, want: false},
+		{name: "global wildcard", check: func() bool { return (&Claims{Permissions: []string{"*"}}).HasPermission("a:b") }, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.check(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type tenantClaims struct {
+	TenantID string   `json:"tenant_id"`
+	Scopes   []string `json:"scopes"`
+	jwt.RegisteredClaims
+}
+
+func TestCustomClaims(t *testing.T) {
+	secret := "test-secret"
+	valid, err := GenerateTokenWithClaims(&tenantClaims{
+		TenantID:         "t-1",
+		Scopes:           []string{"orders:read"},
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "user123", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}, secret)
+	if err != nil {
+		t.Fatalf("GenerateTokenWithClaims() error = %v", err)
+	}
+	expired, _ := GenerateTokenWithClaims(&tenantClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour))},
+	}, secret)
+
+	tests := []struct {
+		name    string
+		token   string
+		secret  string
+		wantErr error
+	}{
+		{name: "valid", token: valid, secret: secret},
+		{name: "wrong secret", token: valid, secret: "other", wantErr: ErrInvalidToken},
+		{name: "expired", token: expired, secret: secret, wantErr: ErrExpiredToken},
+		{name: "malformed", token: "a.b.c", secret: secret, wantErr: ErrMalformedToken},
+		{name: "empty", token: "", secret: secret, wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ValidateTokenAs[tenantClaims](tt.token, tt.secret)
+			if err != tt.wantErr {
+				t.Fatalf("ValidateTokenAs() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if claims != nil {
+					t.Error("ValidateTokenAs() should return nil claims on error")
+				}
+				return
+			}
+			if claims.TenantID != "t-1" || claims.Subject != "user123" || len(claims.Scopes) != 1 {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestCustomClaimsEmbeddingClaims(t *testing.T) {
+	type orgClaims struct {
+		OrgID string `json:"org_id"`
+		Claims
+	}
+	signer, _ := NewSigner(testKeys(t)["ES256"], "")
+	token, err := signer.Sign(&orgClaims{OrgID: "o-1", Claims: *NewClaims("user123", "testuser", time.Hour)})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	claims, err := ParseTokenAs[orgClaims](token, signer.Keyfunc())
+	if err != nil {
+		t.Fatalf("ParseTokenAs() error = %v", err)
+	}
+	if claims.OrgID != "o-1" || claims.UserID != "user123" {
+		t.Errorf("claims = %+v", claims)
+	}
+	// The standard claims are still readable from the same token
+	if base, err := ParseToken(token, signer.Keyfunc()); err != nil || base.Username != "testuser" {
+		t.Errorf("ParseToken() = %+v, %v", base, err)
+	}
+}
@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWKSConfig holds the settings for fetching a remote JWKS document
+type JWKSConfig struct {
+	// RefreshInterval is how long fetched keys are used before they are fetched again
+	RefreshInterval time.Duration `json:"refresh_interval" yaml:"refresh_interval" env:"JWKS_REFRESH_INTERVAL"`
+	// MinRefreshInterval limits refetches triggered by tokens with an unknown key ID
+	MinRefreshInterval time.Duration `json:"min_refresh_interval" yaml:"min_refresh_interval" env:"JWKS_MIN_REFRESH_INTERVAL"`
+	Timeout            time.Duration `json:"timeout" yaml:"timeout" env:"JWKS_TIMEOUT"`
+	HTTPClient         *http.Client  `json:"-" yaml:"-"`
+}
+
+// DefaultJWKSConfig returns hourly refreshes and at most one refetch a minute
+// for unknown key IDs
+func DefaultJWKSConfig() JWKSConfig {
+	return JWKSConfig{
+		RefreshInterval:    time.Hour,
+		MinRefreshInterval: time.Minute,
+		Timeout:            10 * time.Second,
+	}
+}
+
+// JWKSValidator validates tokens signed by an identity provider such as
+// Keycloak or Auth0, resolving the key by the token's "kid" header from the
+// provider's JWKS document. When a refresh fails the previous keys are kept.
+type JWKSValidator struct {
+	url    string
+	cfg    JWKSConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+	tried   time.Time
+}
+
+// NewJWKSValidator creates a validator for the JWKS document at url; keys
+// are fetched on first use
+func NewJWKSValidator(url string, cfg JWKSConfig) *JWKSValidator {
+	defaults := DefaultJWKSConfig()
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = defaults.RefreshInterval
+	}
+	if cfg.MinRefreshInterval <= 0 {
+		cfg.MinRefreshInterval = defaults.MinRefreshInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &JWKSValidator{url: url, cfg: cfg, client: client, now: time.Now}
+}
+
+// ValidateToken validates a token and returns the claims; providers share
+// keys across clients, so pass opts with the expected issuer and audience
+func (v *JWKSValidator) ValidateToken(tokenString string, opts ...ValidateOptions) (*Claims, error) {
+	return ParseToken(tokenString, v.Keyfunc(), opts...)
+}
+
+// Keyfunc returns a key function for ParseToken or the auth middleware
+func (v *JWKSValidator) Keyfunc() Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := v.key(context.Background(), kid)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMethod(token.Method, key); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+}
+
+// Refresh fetches the JWKS document now
+func (v *JWKSValidator) Refresh(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.refresh(ctx)
+}
+
+// key returns the key for kid, refreshing stale keys and refetching at most
+// once per MinRefreshInterval for unknown IDs. A token without a key ID
+// matches when the document has a single key.
+func (v *JWKSValidator) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := v.now()
+	if now.Sub(v.fetched) >= v.cfg.RefreshInterval && now.Sub(v.tried) >= v.cfg.MinRefreshInterval {
+		if err := v.refresh(ctx); err != nil && v.keys == nil {
+			return nil, err
+		}
+	}
+	if key, ok := v.lookup(kid); ok {
+		return key, nil
+	}
+	if now.Sub(v.tried) >= v.cfg.MinRefreshInterval {
+		if err := v.refresh(ctx); err != nil {
+			return nil, err
+		}
+		if key, ok := v.lookup(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key ID %q", kid)
+}
+
+// lookup finds a key; callers hold the lock
+func (v *JWKSValidator) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// refresh fetches and replaces the keys; callers hold the lock
+func (v *JWKSValidator) refresh(ctx context.Context) error {
+	v.tried = v.now()
+	ctx, cancel := context.WithTimeout(ctx, v.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.url, nil)
+	if err != nil {
+		return fmt.Errorf("auth: failed to fetch JWKS: %w", err)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("auth: failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("auth: failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("auth: failed to decode JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		// Keys of unsupported types are skipped so one odd key does not
+		// break validation with the others
+		if key, err := k.publicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+	v.keys = keys
+	v.fetched = v.tried
+	return nil
+}
+
+// jwk is a JSON Web Key (RFC 7517)
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey decodes an RSA, EC or OKP (Ed25519) key
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("%w: unsupported curve %q", ErrInvalidKey, k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("%w: point not on curve", ErrInvalidKey)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("%w: unsupported curve %q", ErrInvalidKey, k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: invalid Ed25519 key", ErrInvalidKey)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("%w: unsupported key type %q", ErrInvalidKey, k.Kty)
+}
+
+// decodeBigInt decodes a base64url big-endian integer
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("%w: invalid key parameter", ErrInvalidKey)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
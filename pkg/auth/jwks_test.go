@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// jwksServer serves the public keys of signers as a JWKS document
+type jwksServer struct {
+	mu       sync.Mutex
+	keys     map[string]crypto.PublicKey
+	fail     bool
+	requests int
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if s.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	var keys []map[string]string
+	for kid, key := range s.keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, map[string]string{"kty": "RSA", "kid": kid, "use": "sig", "n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes())})
+		case *ecdsa.PublicKey:
+			keys = append(keys, map[string]string{"kty": "EC", "kid": kid, "crv": k.Curve.Params().Name, "x": b64(k.X.Bytes()), "y": b64(k.Y.Bytes())})
+		case ed25519.PublicKey:
+			keys = append(keys, map[string]string{"kty": "OKP", "kid": kid, "crv": "Ed25519", "x": b64(k)})
+		}
+	}
+	keys = append(keys, map[string]string{"kty": "RSA", "kid": "enc", "use": "enc"}, map[string]string{"kty": "oct", "kid": "hmac"})
+	json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+}
+
+func (s *jwksServer) set(keys map[string]crypto.PublicKey, fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys, s.fail = keys, fail
+}
+
+func TestJWKSValidator(t *testing.T) {
+	keys := testKeys(t)
+	signers := map[string]Signer{}
+	public := map[string]crypto.PublicKey{}
+	for alg, key := range keys {
+		signers[alg], _ = NewSigner(key, alg)
+		public[alg] = key.Public()
+	}
+	server := &jwksServer{keys: public}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	now := time.Unix(1700000000, 0)
+	v := NewJWKSValidator(ts.URL, JWKSConfig{RefreshInterval: time.Hour, MinRefreshInterval: time.Minute})
+	v.now = func() time.Time { return now }
+
+	for alg, signer := range signers {
+		t.Run(alg, func(t *testing.T) {
+			token, _ := signer.Sign(NewClaims("user123", "", time.Hour))
+			claims, err := v.ValidateToken(token)
+			if err != nil || claims.UserID != "user123" {
+				t.Fatalf("ValidateToken() = %+v, %v", claims, err)
+			}
+		})
+	}
+	if server.requests != 1 {
+		t.Errorf("requests = %d, want keys cached after one fetch", server.requests)
+	}
+
+	t.Run("unknown key refetches once", func(t *testing.T) {
+		rotated, _ := NewSigner(keys["EdDSA"], "rotated")
+		token, _ := rotated.Sign(NewClaims("user123", "", time.Hour))
+		now = now.Add(2 * time.Minute)
+		if _, err := v.ValidateToken(token); err != ErrInvalidToken {
+			t.Fatalf("ValidateToken() error = %v, want ErrInvalidToken", err)
+		}
+		if _, err := v.ValidateToken(token); err != ErrInvalidToken {
+			t.Fatalf("ValidateToken() error = %v, want ErrInvalidToken", err)
+		}
+		if server.requests != 2 {
+			t.Errorf("requests = %d, want one refetch", server.requests)
+		}
+
+		server.set(map[string]crypto.PublicKey{"rotated": keys["EdDSA"].Public()}, false)
+		now = now.Add(2 * time.Minute)
+		if _, err := v.ValidateToken(token); err != nil {
+			t.Errorf("ValidateToken() after rotation error = %v", err)
+		}
+	})
+
+	t.Run("failed refresh keeps keys", func(t *testing.T) {
+		server.set(nil, true)
+		now = now.Add(2 * time.Hour)
+		rotated, _ := NewSigner(keys["EdDSA"], "rotated")
+		token, _ := rotated.Sign(NewClaims("user123", "", time.Hour))
+		if _, err := v.ValidateToken(token); err != nil {
+			t.Errorf("ValidateToken() error = %v", err)
+		}
+	})
+
+	t.Run("single key without kid", func(t *testing.T) {
+		anonymous, _ := NewSigner(keys["EdDSA"], "")
+		token, _ := anonymous.Sign(NewClaims("user123", "", time.Hour))
+		if _, err := v.ValidateToken(token); err != nil {
+			t.Errorf("ValidateToken() error = %v", err)
+		}
+	})
+}
+
+func TestJWKSValidatorUnavailable(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	v := NewJWKSValidator(ts.URL, DefaultJWKSConfig())
+	if err := v.Refresh(t.Context()); err == nil {
+		t.Error("Refresh() succeeded against a missing document")
+	}
+	signer, _ := NewSigner(testKeys(t)["ES256"], "k")
+	token, _ := signer.Sign(NewClaims("user123", "", time.Hour))
+	if _, err := v.ValidateToken(token); err != ErrInvalidToken {
+		t.Errorf("ValidateToken() error = %v, want ErrInvalidToken", err)
+	}
+}
@@ -2,7 +2,6 @@ package auth
 
 import (
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -15,46 +14,121 @@ var (
 	ErrExpiredToken = errors.New("token expired")
 	// ErrMalformedToken represents a malformed token error
 	ErrMalformedToken = errors.New("malformed token")
+	// ErrInvalidIssuer is returned when the token's issuer is not the expected one
+	ErrInvalidIssuer = errors.New("invalid token issuer")
+	// ErrInvalidAudience is returned when the token is not meant for any expected audience
+	ErrInvalidAudience = errors.New("invalid token audience")
 )
 
+// ValidateOptions holds the checks applied beyond the signature and expiry
+type ValidateOptions struct {
+	// Issuer, when set, must equal the token's iss claim
+	Issuer string `json:"issuer" yaml:"issuer" env:"JWT_ISSUER"`
+	// Audiences, when set, must include one of the token's aud values
+	Audiences []string `json:"audiences" yaml:"audiences" env:"JWT_AUDIENCES"`
+	// Leeway tolerates clock skew between issuer and validator
+	Leeway time.Duration `json:"leeway" yaml:"leeway" env:"JWT_LEEWAY"`
+}
+
+// parserOptions converts the first of opts to parser options
+func parserOptions(opts []ValidateOptions) []jwt.ParserOption {
+	if len(opts) == 0 {
+		return nil
+	}
+	o := opts[0]
+	var options []jwt.ParserOption
+	if o.Issuer != "" {
+		options = append(options, jwt.WithIssuer(o.Issuer))
+	}
+	if len(o.Audiences) > 0 {
+		options = append(options, jwt.WithAudience(o.Audiences...))
+	}
+	if o.Leeway > 0 {
+		options = append(options, jwt.WithLeeway(o.Leeway))
+	}
+	return options
+}
+
 // GenerateToken generates a new JWT token with the given user information
 func GenerateToken(userID, username, secret string, ttl time.Duration) (string, error) {
-	claims := NewClaims(userID, username, ttl)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return NewHMACSigner([]byte(secret), "").Sign(NewClaims(userID, username, ttl))
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func ValidateToken(tokenString, secret string) (*Claims, error) {
+// missingClaimError reports which expected claim a token lacks
+func missingClaimError(claims jwt.Claims, opts []ValidateOptions) error {
+	if len(opts) > 0 {
+		if aud, _ := claims.GetAudience(); len(opts[0].Audiences) > 0 && len(aud) == 0 {
+			return ErrInvalidAudience
+		}
+		if iss, _ := claims.GetIssuer(); opts[0].Issuer != "" && iss == "" {
+			return ErrInvalidIssuer
+		}
+	}
+	return ErrInvalidToken
+}
+
+// ValidateToken validates an HMAC-signed JWT token of any type and returns
+// the claims; opts adds issuer, audience and leeway checks
+func ValidateToken(tokenString, secret string, opts ...ValidateOptions) (*Claims, error) {
+	return ParseToken(tokenString, HMACKeyfunc([]byte(secret)), opts...)
+}
+
+// ParseToken validates a JWT token of any type with the key keyfunc resolves
+// and returns the claims
+func ParseToken(tokenString string, keyfunc Keyfunc, opts ...ValidateOptions) (*Claims, error) {
+	return ParseTokenAs[Claims](tokenString, keyfunc, opts...)
+}
+
+// GenerateTokenWithClaims signs caller-defined claims with HS256, so services
+// can carry fields such as tenant_id or scopes by embedding Claims or
+// jwt.RegisteredClaims in their own struct
+func GenerateTokenWithClaims[T jwt.Claims](claims T, secret string) (string, error) {
+	return NewHMACSigner([]byte(secret), "").Sign(claims)
+}
+
+// ValidateTokenAs validates an HMAC-signed token into caller-defined claims,
+// e.g. ValidateTokenAs[TenantClaims](token, secret) returns *TenantClaims
+func ValidateTokenAs[T any, PT interface {
+	*T
+	jwt.Claims
+}](tokenString, secret string, opts ...ValidateOptions) (PT, error) {
+	return ParseTokenAs[T, PT](tokenString, HMACKeyfunc([]byte(secret)), opts...)
+}
+
+// ParseTokenAs is ValidateTokenAs with the key keyfunc resolves
+func ParseTokenAs[T any, PT interface {
+	*T
+	jwt.Claims
+}](tokenString string, keyfunc Keyfunc, opts ...ValidateOptions) (PT, error) {
 	if tokenString == "" {
 		return nil, ErrInvalidToken
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
-	})
+	claims := PT(new(T))
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyfunc, parserOptions(opts)...)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrExpiredToken
 		}
+		if errors.Is(err, jwt.ErrTokenInvalidIssuer) {
+			return nil, ErrInvalidIssuer
+		}
+		if errors.Is(err, jwt.ErrTokenInvalidAudience) {
+			return nil, ErrInvalidAudience
+		}
+		if errors.Is(err, jwt.ErrTokenRequiredClaimMissing) {
+			return nil, missingClaimError(claims, opts)
+		}
 		if errors.Is(err, jwt.ErrTokenMalformed) {
 			return nil, ErrMalformedToken
 		}
 		return nil, ErrInvalidToken
 	}
 
-	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
+	if !token.Valid {
 		return nil, ErrInvalidToken
 	}
 
-	if claims.IsExpired() {
-		return nil, ErrExpiredToken
-	}
-
 	return claims, nil
 }
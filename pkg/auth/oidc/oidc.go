@@ -0,0 +1,266 @@
+// Package oidc is an OpenID Connect client for the authorization-code flow:
+// provider discovery, authorization URLs with state, nonce and PKCE, code
+// exchange and ID token validation against the provider's JWKS, enough to
+// add "Login with Google/Keycloak" to a service.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"mora/pkg/auth"
+)
+
+var (
+	// ErrInvalidState is returned when the callback's state does not match the login's
+	ErrInvalidState = errors.New("oidc: invalid state")
+	// ErrInvalidNonce is returned when the ID token's nonce does not match the login's
+	ErrInvalidNonce = errors.New("oidc: invalid nonce")
+)
+
+// Config holds the client registration at the provider
+type Config struct {
+	// Issuer is the provider URL, e.g. https://accounts.google.com or
+	// https://keycloak.example.com/realms/main
+	Issuer       string   `json:"issuer" yaml:"issuer" env:"OIDC_ISSUER"`
+	ClientID     string   `json:"client_id" yaml:"client_id" env:"OIDC_CLIENT_ID"`
+	ClientSecret string   `json:"client_secret" yaml:"client_secret" env:"OIDC_CLIENT_SECRET"`
+	RedirectURL  string   `json:"redirect_url" yaml:"redirect_url" env:"OIDC_REDIRECT_URL"`
+	Scopes       []string `json:"scopes" yaml:"scopes" env:"OIDC_SCOPES"`
+	// StateSecret signs the state cookie set by Begin and checked by Callback
+	StateSecret string        `json:"state_secret" yaml:"state_secret" env:"OIDC_STATE_SECRET"`
+	Timeout     time.Duration `json:"timeout" yaml:"timeout" env:"OIDC_TIMEOUT"`
+	// Leeway tolerates clock skew when validating ID tokens
+	Leeway     time.Duration `json:"leeway" yaml:"leeway" env:"OIDC_LEEWAY"`
+	HTTPClient *http.Client  `json:"-" yaml:"-"`
+}
+
+// DefaultConfig returns the standard OpenID scopes
+func DefaultConfig() Config {
+	return Config{
+		Scopes:  []string{"openid", "profile", "email"},
+		Timeout: 10 * time.Second,
+		Leeway:  time.Minute,
+	}
+}
+
+// Metadata is the provider's discovery document
+type Metadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserinfoEndpoint      string   `json:"userinfo_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	EndSessionEndpoint    string   `json:"end_session_endpoint"`
+	ScopesSupported       []string `json:"scopes_supported"`
+}
+
+// Provider is a discovered OpenID provider
+type Provider struct {
+	cfg      Config
+	client   *http.Client
+	metadata Metadata
+	keys     *auth.JWKSValidator
+}
+
+// Discover fetches the provider's discovery document and checks that it
+// belongs to the configured issuer
+func Discover(ctx context.Context, cfg Config) (*Provider, error) {
+	defaults := DefaultConfig()
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = defaults.Scopes
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	issuer := strings.TrimSuffix(cfg.Issuer, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: failed to fetch discovery document: %w", err)
+	}
+	var metadata Metadata
+	if err := doJSON(client, req, &metadata); err != nil {
+		return nil, fmt.Errorf("oidc: failed to fetch discovery document: %w", err)
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc: discovery issuer %q does not match %q", metadata.Issuer, cfg.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is missing endpoints")
+	}
+
+	jwksCfg := auth.DefaultJWKSConfig()
+	jwksCfg.HTTPClient = client
+	return &Provider{
+		cfg:      cfg,
+		client:   client,
+		metadata: metadata,
+		keys:     auth.NewJWKSValidator(metadata.JWKSURI, jwksCfg),
+	}, nil
+}
+
+// Metadata returns the discovery document
+func (p *Provider) Metadata() Metadata {
+	return p.metadata
+}
+
+// AuthRequest is the per-login secret state that must survive the redirect
+// to the provider and back
+type AuthRequest struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	// ReturnTo is where the app sends the user after logging in
+	ReturnTo string `json:"return_to,omitempty"`
+}
+
+// NewAuthRequest creates a login with random state, nonce and PKCE verifier
+func NewAuthRequest() (*AuthRequest, error) {
+	var values [3]string
+	for i := range values {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("oidc: failed to generate state: %w", err)
+		}
+		values[i] = base64.RawURLEncoding.EncodeToString(b)
+	}
+	return &AuthRequest{State: values[0], Nonce: values[1], CodeVerifier: values[2]}, nil
+}
+
+// BuildAuthURL returns the provider URL to redirect the user to; extra adds
+// parameters such as prompt=consent or login_hint
+func (p *Provider) BuildAuthURL(req *AuthRequest, extra url.Values) string {
+	challenge := sha256.Sum256([]byte(req.CodeVerifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {req.State},
+		"nonce":                 {req.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	for k, v := range extra {
+		q[k] = v
+	}
+	sep := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + sep + q.Encode()
+}
+
+// Token is the token endpoint's response
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	IDToken      string    `json:"id_token"`
+	ExpiresIn    int       `json:"expires_in"`
+	Expiry       time.Time `json:"-"`
+}
+
+// ExchangeCode trades the authorization code from the callback for tokens
+func (p *Provider) ExchangeCode(ctx context.Context, code string, req *AuthRequest) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {req.CodeVerifier},
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("oidc: failed to exchange code: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	var token Token
+	if err := doJSON(p.client, httpReq, &token); err != nil {
+		return nil, fmt.Errorf("oidc: failed to exchange code: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return &token, nil
+}
+
+// IDTokenClaims are the standard claims of an ID token
+type IDTokenClaims struct {
+	Nonce         string `json:"nonce"`
+	AuthorizedBy  string `json:"azp,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Picture       string `json:"picture,omitempty"`
+	Username      string `json:"preferred_username,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ValidateIDToken checks the ID token's signature, issuer, audience, expiry
+// and nonce
+func (p *Provider) ValidateIDToken(ctx context.Context, rawIDToken, nonce string) (*IDTokenClaims, error) {
+	claims, err := auth.ParseTokenAs[IDTokenClaims](rawIDToken, p.keys.Keyfunc(), auth.ValidateOptions{
+		Issuer:    p.metadata.Issuer,
+		Audiences: []string{p.cfg.ClientID},
+		Leeway:    p.cfg.Leeway,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid ID token: %w", err)
+	}
+	if claims.Nonce != nonce {
+		return nil, ErrInvalidNonce
+	}
+	// With several audiences the token must name this client as its authorized party
+	if len(claims.Audience) > 1 && claims.AuthorizedBy != p.cfg.ClientID {
+		return nil, fmt.Errorf("oidc: invalid ID token: %w", auth.ErrInvalidAudience)
+	}
+	return claims, nil
+}
+
+// doJSON sends req and decodes a successful JSON response into v, reporting
+// the provider's OAuth error otherwise
+func doJSON(client *http.Client, req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			return fmt.Errorf("status %d: %s: %s", resp.StatusCode, oauthErr.Error, oauthErr.Description)
+		}
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, v)
+}
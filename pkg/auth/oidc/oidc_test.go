@@ -0,0 +1,248 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"mora/pkg/auth"
+)
+
+// fakeProvider is an OpenID provider issuing ID tokens for a single code
+type fakeProvider struct {
+	*httptest.Server
+	key      *rsa.PrivateKey
+	nonce    string
+	verifier string
+	audience []string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &fakeProvider{key: key, audience: []string{"client-1"}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Metadata{
+			Issuer:                p.URL,
+			AuthorizationEndpoint: p.URL + "/authorize",
+			TokenEndpoint:         p.URL + "/token",
+			JWKSURI:               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		b64 := base64.RawURLEncoding.EncodeToString
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1", "n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "client-1" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		if r.FormValue("code") != "good-code" || r.FormValue("code_verifier") != p.verifier {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "bad code"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "at", "token_type": "Bearer", "expires_in": 3600, "id_token": p.idToken(t, p.nonce),
+		})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *fakeProvider) idToken(t *testing.T, nonce string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, &IDTokenClaims{
+		Nonce: nonce,
+		Email: "ada@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.URL,
+			Subject:   "user-1",
+			Audience:  p.audience,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	token.Header["kid"] = "k1"
+	s, err := token.SignedString(p.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func testConfig(issuer string) Config {
+	cfg := DefaultConfig()
+	cfg.Issuer = issuer
+	cfg.ClientID = "client-1"
+	cfg.ClientSecret = "s3cret"
+	cfg.RedirectURL = "http://app.test/callback"
+	cfg.StateSecret = "state-secret"
+	return cfg
+}
+
+func TestDiscover(t *testing.T) {
+	fake := newFakeProvider(t)
+	if _, err := Discover(context.Background(), testConfig(fake.URL+"/")); err != nil {
+		t.Errorf("Discover() with trailing slash error = %v", err)
+	}
+	if _, err := Discover(context.Background(), testConfig(fake.URL+"/other")); err == nil {
+		t.Error("Discover() of a missing document succeeded")
+	}
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Metadata{Issuer: "https://evil.test"})
+	}))
+	defer other.Close()
+	if _, err := Discover(context.Background(), testConfig(other.URL)); err == nil {
+		t.Error("Discover() accepted a document for another issuer")
+	}
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeProvider(t)
+	p, err := Discover(ctx, testConfig(fake.URL))
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	req, _ := NewAuthRequest()
+	fake.nonce, fake.verifier = req.Nonce, req.CodeVerifier
+	u, _ := url.Parse(p.BuildAuthURL(req, url.Values{"prompt": {"consent"}}))
+	q := u.Query()
+	if u.Path != "/authorize" || q.Get("state") != req.State || q.Get("nonce") != req.Nonce ||
+		q.Get("client_id") != "client-1" || q.Get("scope") != "openid profile email" ||
+		q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" || q.Get("prompt") != "consent" {
+		t.Errorf("auth URL = %s", u)
+	}
+
+	token, err := p.ExchangeCode(ctx, "good-code", req)
+	if err != nil {
+		t.Fatalf("ExchangeCode() error = %v", err)
+	}
+	if token.AccessToken != "at" || token.Expiry.IsZero() {
+		t.Errorf("token = %+v", token)
+	}
+	if _, err := p.ExchangeCode(ctx, "bad-code", req); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("ExchangeCode(bad) error = %v, want invalid_grant", err)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		nonce    string
+		audience []string
+		wantErr  error
+	}{
+		{name: "valid", token: token.IDToken, nonce: req.Nonce},
+		{name: "wrong nonce", token: token.IDToken, nonce: "other", wantErr: ErrInvalidNonce},
+		{name: "other audience", nonce: req.Nonce, audience: []string{"client-2"}, wantErr: auth.ErrInvalidAudience},
+		{name: "several audiences without azp", nonce: req.Nonce, audience: []string{"client-1", "client-2"}, wantErr: auth.ErrInvalidAudience},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := tt.token
+			if raw == "" {
+				fake.audience = tt.audience
+				raw = fake.idToken(t, req.Nonce)
+				fake.audience = []string{"client-1"}
+			}
+			claims, err := p.ValidateIDToken(ctx, raw, tt.nonce)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateIDToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (claims.Subject != "user-1" || claims.Email != "ada@example.com") {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestBeginAndCallback(t *testing.T) {
+	fake := newFakeProvider(t)
+	p, err := Discover(context.Background(), testConfig(fake.URL))
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	begin := func() (*http.Cookie, url.Values) {
+		w := httptest.NewRecorder()
+		if err := p.Begin(w, httptest.NewRequest(http.MethodGet, "/login", nil), "/dashboard"); err != nil {
+			t.Fatalf("Begin() error = %v", err)
+		}
+		if w.Code != http.StatusFound {
+			t.Fatalf("Begin() status = %d", w.Code)
+		}
+		loc, _ := url.Parse(w.Header().Get("Location"))
+		return w.Result().Cookies()[0], loc.Query()
+	}
+	callback := func(cookie *http.Cookie, query string) (*Result, error) {
+		r := httptest.NewRequest(http.MethodGet, "/callback?"+query, nil)
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		return p.Callback(httptest.NewRecorder(), r)
+	}
+
+	cookie, q := begin()
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("state cookie = %+v", cookie)
+	}
+	// The provider sees the nonce and the challenge, never the verifier
+	fake.nonce = q.Get("nonce")
+	var state statePayload
+	payload, _, _ := strings.Cut(cookie.Value, ".")
+	data, _ := base64.RawURLEncoding.DecodeString(payload)
+	json.Unmarshal(data, &state)
+	fake.verifier = state.CodeVerifier
+
+	tampered := *cookie
+	tampered.Value = base64.RawURLEncoding.EncodeToString([]byte(`{"state":"x"}`)) + "." + strings.SplitN(cookie.Value, ".", 2)[1]
+
+	tests := []struct {
+		name    string
+		cookie  *http.Cookie
+		query   string
+		wantErr error
+	}{
+		{name: "missing cookie", query: "state=" + q.Get("state") + "&code=good-code", wantErr: ErrInvalidState},
+		{name: "wrong state", cookie: cookie, query: "state=other&code=good-code", wantErr: ErrInvalidState},
+		{name: "tampered cookie", cookie: &tampered, query: "state=x&code=good-code", wantErr: ErrInvalidState},
+		{name: "valid", cookie: cookie, query: "state=" + q.Get("state") + "&code=good-code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := callback(tt.cookie, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Callback() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (res.Claims.Subject != "user-1" || res.ReturnTo != "/dashboard") {
+				t.Errorf("result = %+v", res)
+			}
+		})
+	}
+
+	if _, err := callback(nil, "error=access_denied&error_description=nope"); err == nil || !strings.Contains(err.Error(), "access_denied") {
+		t.Errorf("Callback() with provider error = %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package oidc
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mora/pkg/utils"
+)
+
+// stateCookie holds the signed AuthRequest between Begin and Callback
+const stateCookie = "oidc_auth"
+
+// stateTTL is how long a user has to log in at the provider
+const stateTTL = 10 * time.Minute
+
+// statePayload is the cookie content; Expires bounds replays of a stolen
+// cookie regardless of what the browser keeps
+type statePayload struct {
+	AuthRequest
+	Expires int64 `json:"exp"`
+}
+
+// Result is the outcome of a completed login
+type Result struct {
+	Token    *Token
+	Claims   *IDTokenClaims
+	ReturnTo string
+}
+
+// Begin starts a login: it stores a new AuthRequest in a signed, HTTP-only
+// cookie and redirects to the provider; returnTo is kept for the Callback
+func (p *Provider) Begin(w http.ResponseWriter, r *http.Request, returnTo string) error {
+	if p.cfg.StateSecret == "" {
+		return errors.New("oidc: StateSecret is required for Begin")
+	}
+	req, err := NewAuthRequest()
+	if err != nil {
+		return err
+	}
+	req.ReturnTo = returnTo
+	data, err := json.Marshal(statePayload{AuthRequest: *req, Expires: time.Now().Add(stateTTL).Unix()})
+	if err != nil {
+		return fmt.Errorf("oidc: failed to encode state: %w", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    payload + "." + utils.SignSHA256(p.cfg.StateSecret, payload),
+		Path:     "/",
+		MaxAge:   int(stateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil || strings.HasPrefix(p.cfg.RedirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, p.BuildAuthURL(req, nil), http.StatusFound)
+	return nil
+}
+
+// Callback completes a login started by Begin: it checks the state, clears
+// the cookie, exchanges the code and validates the ID token
+func (p *Provider) Callback(w http.ResponseWriter, r *http.Request) (*Result, error) {
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		return nil, fmt.Errorf("oidc: provider returned %s: %s", e, q.Get("error_description"))
+	}
+	cookie, err := r.Cookie(stateCookie)
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+	http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: "/", MaxAge: -1, HttpOnly: true})
+
+	payload, sig, ok := strings.Cut(cookie.Value, ".")
+	if !ok || !utils.VerifySHA256(p.cfg.StateSecret, payload, sig) {
+		return nil, ErrInvalidState
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+	var state statePayload
+	if err := json.Unmarshal(data, &state); err != nil || time.Now().Unix() > state.Expires {
+		return nil, ErrInvalidState
+	}
+	req := state.AuthRequest
+	if subtle.ConstantTimeCompare([]byte(q.Get("state")), []byte(req.State)) != 1 {
+		return nil, ErrInvalidState
+	}
+
+	token, err := p.ExchangeCode(r.Context(), q.Get("code"), &req)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := p.ValidateIDToken(r.Context(), token.IDToken, req.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Token: token, Claims: claims, ReturnTo: req.ReturnTo}, nil
+}
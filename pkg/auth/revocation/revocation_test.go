@@ -0,0 +1,77 @@
+package revocation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"mora/pkg/auth"
+)
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	list := New(store)
+	list.now = store.now
+
+	token, err := auth.GenerateToken("user123", "testuser", "test-secret", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	claims, err := auth.ValidateToken(token, "test-secret")
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	other := auth.NewClaims("user456", "", time.Hour)
+
+	if err := list.Revoke(ctx, token); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if err := list.RevokeID(ctx, "forever", time.Time{}); err != nil {
+		t.Fatalf("RevokeID() error = %v", err)
+	}
+	if err := list.RevokeID(ctx, "expired", now.Add(-time.Second)); err != nil {
+		t.Fatalf("RevokeID() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		after time.Duration
+		jti   string
+		want  bool
+	}{
+		{name: "revoked token", jti: claims.ID, want: true},
+		{name: "other token", jti: other.ID, want: false},
+		{name: "already expired", jti: "expired", want: false},
+		{name: "after token expiry", after: 2 * time.Hour, jti: claims.ID, want: false},
+		{name: "without expiry", after: 2 * time.Hour, jti: "forever", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.now = func() time.Time { return now.Add(tt.after) }
+			got, err := list.IsRevoked(ctx, tt.jti)
+			if err != nil {
+				t.Fatalf("IsRevoked() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsRevoked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevokeErrors(t *testing.T) {
+	list := New(NewMemoryStore())
+	if err := list.Revoke(context.Background(), "not-a-token"); err == nil {
+		t.Error("Revoke() of garbage succeeded")
+	}
+	claims := &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}}
+	if err := list.RevokeClaims(context.Background(), claims); !errors.Is(err, ErrNoTokenID) {
+		t.Errorf("RevokeClaims() error = %v, want ErrNoTokenID", err)
+	}
+}
@@ -0,0 +1,71 @@
+package revocation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"mora/pkg/cache"
+)
+
+// RedisStore is a Store backed by pkg/cache; each revoked ID is a key that
+// expires with the token
+type RedisStore struct {
+	client *cache.Client
+	prefix string
+}
+
+// NewRedisStore creates a Redis store whose keys start with prefix,
+// "auth:revoked:" when empty
+func NewRedisStore(client *cache.Client, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "auth:revoked:"
+	}
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Add implements Store
+func (s *RedisStore) Add(ctx context.Context, jti string, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+jti, 1, ttl)
+}
+
+// Contains implements Store
+func (s *RedisStore) Contains(ctx context.Context, jti string) (bool, error) {
+	return s.client.Exists(ctx, s.prefix+jti)
+}
+
+// MemoryStore is an in-process Store for tests and single-instance services
+type MemoryStore struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{expires: make(map[string]time.Time), now: time.Now}
+}
+
+// Add implements Store
+func (s *MemoryStore) Add(ctx context.Context, jti string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var expires time.Time
+	if ttl > 0 {
+		expires = s.now().Add(ttl)
+	}
+	s.expires[jti] = expires
+	return nil
+}
+
+// Contains implements Store
+func (s *MemoryStore) Contains(ctx context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires, ok := s.expires[jti]
+	if ok && !expires.IsZero() && !s.now().Before(expires) {
+		delete(s.expires, jti)
+		return false, nil
+	}
+	return ok, nil
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Keyfunc resolves the key that verifies a token, e.g. by its "kid" header
+type Keyfunc = jwt.Keyfunc
+
+// Signer signs tokens and verifies the tokens it signed
+type Signer interface {
+	// Sign returns the signed token, with a "kid" header when the signer has a key ID
+	Sign(claims jwt.Claims) (string, error)
+	// Keyfunc returns the key function that verifies tokens signed by Sign
+	Keyfunc() Keyfunc
+}
+
+// keySigner signs with a fixed method and key
+type keySigner struct {
+	method jwt.SigningMethod
+	key    any
+	verify any
+	kid    string
+}
+
+// NewHMACSigner creates an HS256 signer with a shared secret
+func NewHMACSigner(secret []byte, kid string) Signer {
+	return &keySigner{method: jwt.SigningMethodHS256, key: secret, verify: secret, kid: kid}
+}
+
+// NewSigner creates a signer for a private key: RS256 for RSA keys, ES256,
+// ES384 or ES512 for ECDSA keys depending on the curve, and EdDSA for
+// Ed25519 keys
+func NewSigner(key crypto.PrivateKey, kid string) (Signer, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return &keySigner{method: jwt.SigningMethodRS256, key: k, verify: &k.PublicKey, kid: kid}, nil
+	case *ecdsa.PrivateKey:
+		method, err := ecdsaMethod(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		return &keySigner{method: method, key: k, verify: &k.PublicKey, kid: kid}, nil
+	case ed25519.PrivateKey:
+		return &keySigner{method: jwt.SigningMethodEdDSA, key: k, verify: k.Public(), kid: kid}, nil
+	}
+	return nil, fmt.Errorf("auth: unsupported private key type %T", key)
+}
+
+// ecdsaMethod returns the signing method for an ECDSA curve
+func ecdsaMethod(curve elliptic.Curve) (jwt.SigningMethod, error) {
+	switch curve {
+	case elliptic.P256():
+		return jwt.SigningMethodES256, nil
+	case elliptic.P384():
+		return jwt.SigningMethodES384, nil
+	case elliptic.P521():
+		return jwt.SigningMethodES512, nil
+	}
+	return nil, fmt.Errorf("auth: unsupported ECDSA curve %s", curve.Params().Name)
+}
+
+// Sign implements Signer
+func (s *keySigner) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.method, claims)
+	if s.kid != "" {
+		token.Header["kid"] = s.kid
+	}
+	return token.SignedString(s.key)
+}
+
+// Keyfunc implements Signer
+func (s *keySigner) Keyfunc() Keyfunc {
+	if secret, ok := s.verify.([]byte); ok {
+		return HMACKeyfunc(secret)
+	}
+	return PublicKeyfunc(s.verify)
+}
+
+// HMACKeyfunc verifies HMAC-signed tokens with a shared secret
+func HMACKeyfunc(secret []byte) Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return secret, nil
+	}
+}
+
+// PublicKeyfunc verifies tokens with a public key, accepting only the
+// algorithms of the key's type so a public key is never used as an HMAC secret
+func PublicKeyfunc(key crypto.PublicKey) Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		if err := checkMethod(token.Method, key); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+}
+
+// KeySetKeyfunc verifies tokens with the public key named by their "kid"
+// header, which allows rotating keys without downtime
+func KeySetKeyfunc(keys map[string]crypto.PublicKey) Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key ID %q", kid)
+		}
+		if err := checkMethod(token.Method, key); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+}
+
+// checkMethod rejects signing methods that do not belong to the key's type
+func checkMethod(method jwt.SigningMethod, key crypto.PublicKey) error {
+	var ok bool
+	switch key.(type) {
+	case *rsa.PublicKey:
+		_, ok = method.(*jwt.SigningMethodRSA)
+		if !ok {
+			_, ok = method.(*jwt.SigningMethodRSAPSS)
+		}
+	case *ecdsa.PublicKey:
+		_, ok = method.(*jwt.SigningMethodECDSA)
+	case ed25519.PublicKey:
+		_, ok = method.(*jwt.SigningMethodEd25519)
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	if !ok {
+		return fmt.Errorf("unexpected signing method: %v", method.Alg())
+	}
+	return nil
+}
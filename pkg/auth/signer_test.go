@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKeys generates one private key of every supported type
+func testKeys(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ec384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	return map[string]crypto.Signer{"RS256": rsaKey, "ES256": ecKey, "ES384": ec384, "EdDSA": edKey}
+}
+
+func TestSigners(t *testing.T) {
+	keys := testKeys(t)
+	for alg, key := range keys {
+		t.Run(alg, func(t *testing.T) {
+			signer, err := NewSigner(key, "key-1")
+			if err != nil {
+				t.Fatalf("NewSigner() error = %v", err)
+			}
+			token, err := signer.Sign(NewClaims("user123", "testuser", time.Hour))
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			parsed, _, _ := jwt.NewParser().ParseUnverified(token, &Claims{})
+			if parsed.Method.Alg() != alg || parsed.Header["kid"] != "key-1" {
+				t.Errorf("header = %v, want alg %s and kid key-1", parsed.Header, alg)
+			}
+
+			claims, err := ParseToken(token, signer.Keyfunc())
+			if err != nil || claims.UserID != "user123" {
+				t.Fatalf("ParseToken() = %+v, %v", claims, err)
+			}
+			if _, err := ParseToken(token, PublicKeyfunc(key.Public())); err != nil {
+				t.Errorf("ParseToken() with public key error = %v", err)
+			}
+			if _, err := ValidateToken(token, "secret"); err != ErrInvalidToken {
+				t.Errorf("ValidateToken() with HMAC error = %v, want ErrInvalidToken", err)
+			}
+			for other, otherKey := range keys {
+				if other == alg {
+					continue
+				}
+				if _, err := ParseToken(token, PublicKeyfunc(otherKey.Public())); err != ErrInvalidToken {
+					t.Errorf("ParseToken() with %s key error = %v, want ErrInvalidToken", other, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPublicKeyfuncRejectsHMAC(t *testing.T) {
+	// A token signed with the public key bytes as HMAC secret must not verify
+	pub := testKeys(t)["RS256"].Public()
+	der, _ := x509.MarshalPKIXPublicKey(pub)
+	forged, _ := NewHMACSigner(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), "").
+		Sign(NewClaims("admin", "", time.Hour))
+	if _, err := ParseToken(forged, PublicKeyfunc(pub)); err != ErrInvalidToken {
+		t.Errorf("ParseToken() error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestKeySetKeyfunc(t *testing.T) {
+	keys := testKeys(t)
+	oldSigner, _ := NewSigner(keys["RS256"], "old")
+	newSigner, _ := NewSigner(keys["ES256"], "new")
+	unknown, _ := NewSigner(keys["EdDSA"], "unknown")
+	keyfunc := KeySetKeyfunc(map[string]crypto.PublicKey{
+		"old": keys["RS256"].Public(),
+		"new": keys["ES256"].Public(),
+	})
+
+	tests := []struct {
+		name    string
+		signer  Signer
+		wantErr error
+	}{
+		{name: "old key", signer: oldSigner},
+		{name: "new key", signer: newSigner},
+		{name: "unknown key", signer: unknown, wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := tt.signer.Sign(NewClaims("user123", "", time.Hour))
+			if _, err := ParseToken(token, keyfunc); err != tt.wantErr {
+				t.Errorf("ParseToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenPairWithSigner(t *testing.T) {
+	signer, _ := NewSigner(testKeys(t)["EdDSA"], "")
+	cfg := DefaultTokenConfig()
+	cfg.Signer = signer
+
+	pair, err := GenerateTokenPair("user123", "testuser", cfg)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+	access, err := RefreshAccessToken(pair.RefreshToken, cfg)
+	if err != nil {
+		t.Fatalf("RefreshAccessToken() error = %v", err)
+	}
+	if _, err := ParseAccessToken(access, signer.Keyfunc()); err != nil {
+		t.Errorf("ParseAccessToken() error = %v", err)
+	}
+}
+
+func TestParseKeysPEM(t *testing.T) {
+	keys := testKeys(t)
+	rsaKey := keys["RS256"].(*rsa.PrivateKey)
+	ecKey := keys["ES256"].(*ecdsa.PrivateKey)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(keys["EdDSA"])
+	sec1, _ := x509.MarshalECPrivateKey(ecKey)
+	pkix, _ := x509.MarshalPKIXPublicKey(ecKey.Public())
+	encode := func(typ string, der []byte) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	}
+
+	privateTests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "pkcs8", data: encode("PRIVATE KEY", pkcs8)},
+		{name: "pkcs1", data: encode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))},
+		{name: "sec1", data: encode("EC PRIVATE KEY", sec1)},
+		{name: "not pem", data: []byte("secret"), wantErr: true},
+		{name: "wrong type", data: encode("PUBLIC KEY", pkix), wantErr: true},
+	}
+	for _, tt := range privateTests {
+		t.Run("private "+tt.name, func(t *testing.T) {
+			key, err := ParsePrivateKeyPEM(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePrivateKeyPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidKey) {
+					t.Errorf("error %v is not ErrInvalidKey", err)
+				}
+				return
+			}
+			if _, err := NewSigner(key, ""); err != nil {
+				t.Errorf("NewSigner() error = %v", err)
+			}
+		})
+	}
+
+	publicTests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "pkix", data: encode("PUBLIC KEY", pkix)},
+		{name: "pkcs1", data: encode("RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey))},
+		{name: "garbage", data: encode("PUBLIC KEY", []byte("nope")), wantErr: true},
+	}
+	for _, tt := range publicTests {
+		t.Run("public "+tt.name, func(t *testing.T) {
+			_, err := ParsePublicKeyPEM(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePublicKeyPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	os.WriteFile(path, encode("PRIVATE KEY", pkcs8), 0o600)
+	if _, err := LoadPrivateKey(path); err != nil {
+		t.Errorf("LoadPrivateKey() error = %v", err)
+	}
+	if _, err := LoadPublicKey(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("LoadPublicKey() of a missing file succeeded")
+	}
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// TokenTypeAccess marks tokens that authorize requests
+	TokenTypeAccess = "access"
+	// TokenTypeRefresh marks tokens that can only be exchanged for access tokens
+	TokenTypeRefresh = "refresh"
+)
+
+// ErrInvalidTokenType is returned when a token of the wrong type is presented,
+// such as a refresh token used as an access token
+var ErrInvalidTokenType = errors.New("invalid token type")
+
+// ErrTokenRevoked is returned for tokens whose ID has been revoked
+var ErrTokenRevoked = errors.New("token revoked")
+
+// RevocationChecker reports whether a token ID has been revoked, as
+// revocation.List does
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// CheckRevoked returns ErrTokenRevoked when checker lists the token's ID.
+// Tokens without an ID pass; a checker error is returned as is, so that
+// callers fail closed when the blacklist is unreachable.
+func CheckRevoked(ctx context.Context, checker RevocationChecker, claims *Claims) error {
+	if checker == nil || claims.ID == "" {
+		return nil
+	}
+	revoked, err := checker.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// TokenConfig holds the settings for issuing token pairs
+type TokenConfig struct {
+	Secret     string        `json:"secret" yaml:"secret" env:"JWT_SECRET"`
+	AccessTTL  time.Duration `json:"access_ttl" yaml:"access_ttl" env:"JWT_ACCESS_TTL"`
+	RefreshTTL time.Duration `json:"refresh_ttl" yaml:"refresh_ttl" env:"JWT_REFRESH_TTL"`
+	// Issuer and Audience are set on issued tokens and required of refresh tokens
+	Issuer   string   `json:"issuer" yaml:"issuer" env:"JWT_ISSUER"`
+	Audience []string `json:"audience" yaml:"audience" env:"JWT_AUDIENCE"`
+	// Signer signs and verifies tokens instead of the shared Secret, e.g. with RS256
+	Signer Signer `json:"-" yaml:"-"`
+	// Revocation, when set, rejects refresh tokens whose ID has been revoked
+	Revocation RevocationChecker `json:"-" yaml:"-"`
+}
+
+// newClaims creates claims of tokenType with the configured issuer and audience
+func (c TokenConfig) newClaims(userID, username, tokenType string, ttl time.Duration) *Claims {
+	claims := NewClaims(userID, username, ttl)
+	claims.TokenType = tokenType
+	claims.Issuer = c.Issuer
+	claims.Audience = c.Audience
+	return claims
+}
+
+// validateOptions checks tokens against the configured issuer and audience
+func (c TokenConfig) validateOptions() ValidateOptions {
+	return ValidateOptions{Issuer: c.Issuer, Audiences: c.Audience}
+}
+
+// signer returns the configured Signer, or HS256 with the shared secret
+func (c TokenConfig) signer() Signer {
+	if c.Signer != nil {
+		return c.Signer
+	}
+	return NewHMACSigner([]byte(c.Secret), "")
+}
+
+// DefaultTokenConfig returns short-lived access tokens and week-long refresh tokens
+func DefaultTokenConfig() TokenConfig {
+	return TokenConfig{
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: 7 * 24 * time.Hour,
+	}
+}
+
+// TokenPair is an access token together with the refresh token that renews it
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// GenerateTokenPair issues an access token and a refresh token for the user
+func GenerateTokenPair(userID, username string, cfg TokenConfig) (*TokenPair, error) {
+	signer := cfg.signer()
+	access := cfg.newClaims(userID, username, TokenTypeAccess, cfg.AccessTTL)
+	accessToken, err := signer.Sign(access)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh := cfg.newClaims(userID, username, TokenTypeRefresh, cfg.RefreshTTL)
+	refreshToken, err := signer.Sign(refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		AccessExpiresAt:  access.ExpiresAt.Time,
+		RefreshExpiresAt: refresh.ExpiresAt.Time,
+	}, nil
+}
+
+// RefreshAccessToken validates a refresh token and issues a new access token
+// for the same user, carrying over the refresh token's roles and permissions
+func RefreshAccessToken(refreshToken string, cfg TokenConfig) (string, error) {
+	return RefreshAccessTokenContext(context.Background(), refreshToken, cfg)
+}
+
+// RefreshAccessTokenContext is RefreshAccessToken checking cfg.Revocation
+// with ctx
+func RefreshAccessTokenContext(ctx context.Context, refreshToken string, cfg TokenConfig) (string, error) {
+	signer := cfg.signer()
+	claims, err := ParseRefreshToken(refreshToken, signer.Keyfunc(), cfg.validateOptions())
+	if err != nil {
+		return "", err
+	}
+	if err := CheckRevoked(ctx, cfg.Revocation, claims); err != nil {
+		return "", err
+	}
+	access := cfg.newClaims(claims.UserID, claims.Username, TokenTypeAccess, cfg.AccessTTL)
+	access.Roles = claims.Roles
+	access.Permissions = claims.Permissions
+	return signer.Sign(access)
+}
+
+// ValidateAccessToken validates an HMAC-signed token and rejects refresh
+// tokens; tokens without a type, such as those from GenerateToken, count as
+// access tokens
+func ValidateAccessToken(tokenString, secret string, opts ...ValidateOptions) (*Claims, error) {
+	return ParseAccessToken(tokenString, HMACKeyfunc([]byte(secret)), opts...)
+}
+
+// ParseAccessToken is ValidateAccessToken with the key keyfunc resolves
+func ParseAccessToken(tokenString string, keyfunc Keyfunc, opts ...ValidateOptions) (*Claims, error) {
+	claims, err := ParseToken(tokenString, keyfunc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != "" && claims.TokenType != TokenTypeAccess {
+		return nil, ErrInvalidTokenType
+	}
+	return claims, nil
+}
+
+// ValidateRefreshToken validates an HMAC-signed token and rejects anything
+// but refresh tokens
+func ValidateRefreshToken(tokenString, secret string, opts ...ValidateOptions) (*Claims, error) {
+	return ParseRefreshToken(tokenString, HMACKeyfunc([]byte(secret)), opts...)
+}
+
+// ParseRefreshToken is ValidateRefreshToken with the key keyfunc resolves
+func ParseRefreshToken(tokenString string, keyfunc Keyfunc, opts ...ValidateOptions) (*Claims, error) {
+	claims, err := ParseToken(tokenString, keyfunc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeRefresh {
+		return nil, ErrInvalidTokenType
+	}
+	return claims, nil
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGenerateTokenPair(t *testing.T) {
+	cfg := DefaultTokenConfig()
+	cfg.Secret = "test-secret"
+
+	pair, err := GenerateTokenPair("user123", "testuser", cfg)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+	if !pair.RefreshExpiresAt.After(pair.AccessExpiresAt) {
+		t.Errorf("refresh expires %v, not after access %v", pair.RefreshExpiresAt, pair.AccessExpiresAt)
+	}
+
+	tests := []struct {
+		name     string
+		validate func(string, string, ...ValidateOptions) (*Claims, error)
+		token    string
+		wantErr  error
+		wantType string
+	}{
+		{name: "access as access", validate: ValidateAccessToken, token: pair.AccessToken, wantType: TokenTypeAccess},
+		{name: "refresh as refresh", validate: ValidateRefreshToken, token: pair.RefreshToken, wantType: TokenTypeRefresh},
+		{name: "refresh as access", validate: ValidateAccessToken, token: pair.RefreshToken, wantErr: ErrInvalidTokenType},
+		{name: "access as refresh", validate: ValidateRefreshToken, token: pair.AccessToken, wantErr: ErrInvalidTokenType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.validate(tt.token, cfg.Secret)
+			if err != tt.wantErr {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (claims.TokenType != tt.wantType || claims.UserID != "user123") {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestValidateAccessTokenAcceptsUntyped(t *testing.T) {
+	token, err := GenerateToken("user123", "testuser", "test-secret", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err := ValidateAccessToken(token, "test-secret"); err != nil {
+		t.Errorf("ValidateAccessToken() error = %v", err)
+	}
+	if _, err := ValidateRefreshToken(token, "test-secret"); err != ErrInvalidTokenType {
+		t.Errorf("ValidateRefreshToken() error = %v, want ErrInvalidTokenType", err)
+	}
+}
+
+func TestRefreshAccessToken(t *testing.T) {
+	cfg := TokenConfig{Secret: "test-secret", AccessTTL: time.Minute, RefreshTTL: time.Hour}
+	pair, _ := GenerateTokenPair("user123", "testuser", cfg)
+	expired, _ := GenerateTokenPair("user123", "testuser", TokenConfig{Secret: cfg.Secret, AccessTTL: time.Minute, RefreshTTL: -time.Hour})
+
+	tests := []struct {
+		name    string
+		token   string
+		secret  string
+		wantErr error
+	}{
+		{name: "valid refresh token", token: pair.RefreshToken, secret: cfg.Secret},
+		{name: "access token", token: pair.AccessToken, secret: cfg.Secret, wantErr: ErrInvalidTokenType},
+		{name: "expired refresh token", token: expired.RefreshToken, secret: cfg.Secret, wantErr: ErrExpiredToken},
+		{name: "wrong secret", token: pair.RefreshToken, secret: "other", wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cfg
+			c.Secret = tt.secret
+			token, err := RefreshAccessToken(tt.token, c)
+			if err != tt.wantErr {
+				t.Fatalf("RefreshAccessToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			claims, err := ValidateAccessToken(token, cfg.Secret)
+			if err != nil {
+				t.Fatalf("new access token invalid: %v", err)
+			}
+			if claims.UserID != "user123" || claims.Username != "testuser" || claims.TokenType != TokenTypeAccess {
+				t.Errorf("claims = %+v", claims)
+			}
+			if ttl := time.Until(claims.ExpiresAt.Time); ttl > time.Minute {
+				t.Errorf("access TTL = %v, want at most a minute", ttl)
+			}
+		})
+	}
+}
+
+// revokedIDs is a RevocationChecker listing token IDs
+type revokedIDs map[string]bool
+
+func (r revokedIDs) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return r[jti], nil
+}
+
+func TestRefreshRejectsRevokedToken(t *testing.T) {
+	cfg := TokenConfig{Secret: "test-secret", AccessTTL: time.Minute, RefreshTTL: time.Hour}
+	pair, _ := GenerateTokenPair("user123", "testuser", cfg)
+	claims, _ := ValidateRefreshToken(pair.RefreshToken, cfg.Secret)
+
+	cfg.Revocation = revokedIDs{}
+	if _, err := RefreshAccessTokenContext(context.Background(), pair.RefreshToken, cfg); err != nil {
+		t.Fatalf("RefreshAccessTokenContext() error = %v", err)
+	}
+	cfg.Revocation = revokedIDs{claims.ID: true}
+	if _, err := RefreshAccessTokenContext(context.Background(), pair.RefreshToken, cfg); err != ErrTokenRevoked {
+		t.Errorf("RefreshAccessTokenContext() error = %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestRefreshKeepsRoles(t *testing.T) {
+	signer := NewHMACSigner([]byte("test-secret"), "")
+	refresh := NewClaims("user123", "testuser", time.Hour)
+	refresh.TokenType = TokenTypeRefresh
+	refresh.Roles = []string{"admin"}
+	refresh.Permissions = []string{"orders:*"}
+	token, _ := signer.Sign(refresh)
+
+	access, err := RefreshAccessToken(token, TokenConfig{Signer: signer, AccessTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("RefreshAccessToken() error = %v", err)
+	}
+	claims, _ := ParseAccessToken(access, signer.Keyfunc())
+	if !claims.HasRole("admin") || !claims.HasPermission("orders:write") {
+		t.Errorf("claims = %+v, want roles and permissions carried over", claims)
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	cfg := TokenConfig{Secret: "test-secret", AccessTTL: time.Minute, RefreshTTL: time.Hour, Issuer: "mora", Audience: []string{"orders"}}
+	pair, err := GenerateTokenPair("user123", "testuser", cfg)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+	expired, _ := GenerateToken("user123", "", cfg.Secret, -30*time.Second)
+	untargeted, _ := GenerateToken("user123", "", cfg.Secret, time.Hour)
+
+	tests := []struct {
+		name    string
+		token   string
+		opts    ValidateOptions
+		wantErr error
+	}{
+		{name: "no checks", token: pair.AccessToken},
+		{name: "matching issuer and audience", token: pair.AccessToken, opts: ValidateOptions{Issuer: "mora", Audiences: []string{"billing", "orders"}}},
+		{name: "wrong issuer", token: pair.AccessToken, opts: ValidateOptions{Issuer: "other"}, wantErr: ErrInvalidIssuer},
+		{name: "wrong audience", token: pair.AccessToken, opts: ValidateOptions{Audiences: []string{"billing"}}, wantErr: ErrInvalidAudience},
+		{name: "missing audience", token: untargeted, opts: ValidateOptions{Audiences: []string{"orders"}}, wantErr: ErrInvalidAudience},
+		{name: "expired within leeway", token: expired, opts: ValidateOptions{Leeway: time.Minute}},
+		{name: "expired beyond leeway", token: expired, opts: ValidateOptions{Leeway: time.Second}, wantErr: ErrExpiredToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ValidateToken(tt.token, cfg.Secret, tt.opts); err != tt.wantErr {
+				t.Errorf("ValidateToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("refresh requires issuer", func(t *testing.T) {
+		if _, err := RefreshAccessToken(pair.RefreshToken, cfg); err != nil {
+			t.Fatalf("RefreshAccessToken() error = %v", err)
+		}
+		other := cfg
+		other.Issuer = "other"
+		if _, err := RefreshAccessToken(pair.RefreshToken, other); err != ErrInvalidIssuer {
+			t.Errorf("RefreshAccessToken() error = %v, want ErrInvalidIssuer", err)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+// Package breaker isolates failing dependencies of outbound calls. It builds
+// on utils.CircuitBreaker, adding a context-aware generic Do, per-name
+// breaker groups, an http.RoundTripper and Prometheus hooks.
+package breaker
+
+import (
+	"context"
+	"sync"
+
+	"mora/pkg/utils"
+)
+
+type (
+	// Breaker is a circuit breaker over a rolling window of calls
+	Breaker = utils.CircuitBreaker
+	// Config configures a breaker
+	Config = utils.CircuitBreakerConfig
+	// State is the state of a breaker
+	State = utils.BreakerState
+	// CallResult describes a call recorded by a breaker
+	CallResult = utils.CallResult
+)
+
+// Breaker states
+const (
+	StateClosed   = utils.StateClosed
+	StateOpen     = utils.StateOpen
+	StateHalfOpen = utils.StateHalfOpen
+)
+
+var (
+	// ErrOpen is returned while a breaker rejects calls
+	ErrOpen = utils.ErrCircuitOpen
+	// ErrTooManyTrialCalls is returned when the half-open trial slots are taken
+	ErrTooManyTrialCalls = utils.ErrTooManyTrialCalls
+)
+
+// DefaultConfig returns the default breaker configuration
+func DefaultConfig() Config {
+	return utils.DefaultCircuitBreakerConfig()
+}
+
+// New creates a breaker, filling unset options with defaults
+func New(cfg Config) *Breaker {
+	return utils.NewCircuitBreaker(cfg)
+}
+
+// Do runs fn through b and returns its result. A context that is already
+// done returns its error without taking a call slot, and fn failing only
+// because ctx ended is not held against the dependency.
+func Do[T any](ctx context.Context, b *Breaker, fn func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	done, err := b.Allow()
+	if err != nil {
+		return zero, err
+	}
+	v, err := fn(ctx)
+	if err != nil && ctx.Err() != nil {
+		done(nil)
+		return v, err
+	}
+	done(err)
+	return v, err
+}
+
+// Group lazily creates one breaker per name, e.g. per downstream host
+type Group struct {
+	cfg      Config
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewGroup creates a group whose breakers share cfg, named after their key
+func NewGroup(cfg Config) *Group {
+	return &Group{cfg: cfg, breakers: make(map[string]*Breaker)}
+}
+
+// Get returns the breaker for name, creating it on first use
+func (g *Group) Get(name string) *Breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b, ok := g.breakers[name]
+	if !ok {
+		cfg := g.cfg
+		cfg.Name = name
+		b = New(cfg)
+		g.breakers[name] = b
+	}
+	return b
+}
+
+// States returns the state of every breaker in the group by name
+func (g *Group) States() map[string]State {
+	g.mu.Lock()
+	breakers := make(map[string]*Breaker, len(g.breakers))
+	for name, b := range g.breakers {
+		breakers[name] = b
+	}
+	g.mu.Unlock()
+
+	states := make(map[string]State, len(breakers))
+	for name, b := range breakers {
+		states[name] = b.State()
+	}
+	return states
+}
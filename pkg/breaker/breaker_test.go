@@ -0,0 +1,174 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// tripConfig opens after two calls when both fail
+func tripConfig() Config {
+	return Config{WindowSize: 2, MinCalls: 2, FailureRateThreshold: 1, HalfOpenCalls: 1}
+}
+
+func TestDo(t *testing.T) {
+	b := New(tripConfig())
+	ctx := context.Background()
+	boom := errors.New("boom")
+
+	v, err := Do(ctx, b, func(ctx context.Context) (int, error) { return 42, nil })
+	if v != 42 || err != nil {
+		t.Fatalf("Do() = %d, %v", v, err)
+	}
+	for range 2 {
+		if _, err := Do(ctx, b, func(ctx context.Context) (int, error) { return 0, boom }); !errors.Is(err, boom) {
+			t.Fatalf("Do() error = %v, want boom", err)
+		}
+	}
+	if b.State() != StateOpen {
+		t.Fatalf("state = %v, want open", b.State())
+	}
+
+	called := false
+	_, err = Do(ctx, b, func(ctx context.Context) (int, error) { called = true; return 0, nil })
+	if !errors.Is(err, ErrOpen) || called {
+		t.Errorf("Do() on open breaker = %v, called %v", err, called)
+	}
+}
+
+func TestDoContext(t *testing.T) {
+	b := New(tripConfig())
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	if _, err := Do(cancelled, b, func(ctx context.Context) (string, error) { called = true; return "", nil }); !errors.Is(err, context.Canceled) || called {
+		t.Errorf("Do() with done context = %v, called %v", err, called)
+	}
+
+	for range 3 {
+		ctx, cancel := context.WithCancel(context.Background())
+		Do(ctx, b, func(ctx context.Context) (string, error) {
+			cancel()
+			return "", ctx.Err()
+		})
+	}
+	if b.State() != StateClosed || b.Counts().Failures != 0 {
+		t.Errorf("cancellations counted: state %v, counts %+v", b.State(), b.Counts())
+	}
+}
+
+func TestGroup(t *testing.T) {
+	g := NewGroup(tripConfig())
+	a := g.Get("a")
+	if g.Get("a") != a || g.Get("b") == a {
+		t.Fatal("Get() should return one breaker per name")
+	}
+	if a.Name() != "a" {
+		t.Errorf("Name() = %q, want a", a.Name())
+	}
+
+	for range 2 {
+		a.Execute(func() error { return errors.New("down") })
+	}
+	states := g.States()
+	if states["a"] != StateOpen || states["b"] != StateClosed {
+		t.Errorf("States() = %v", states)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer healthy.Close()
+
+	tests := []struct {
+		name     string
+		perHost  bool
+		wantOpen bool
+	}{
+		{name: "per host isolates healthy hosts", perHost: true, wantOpen: false},
+		{name: "shared breaker cuts off every host", perHost: false, wantOpen: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{Transport: NewTransport(nil, TransportConfig{Breaker: tripConfig(), PerHost: tt.perHost})}
+			for range 2 {
+				resp, err := client.Get(failing.URL)
+				if err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				resp.Body.Close()
+			}
+
+			if _, err := client.Get(failing.URL); !errors.Is(err, ErrOpen) {
+				t.Errorf("Get() on tripped host error = %v, want ErrOpen", err)
+			}
+
+			resp, err := client.Get(healthy.URL)
+			if tt.wantOpen {
+				if !errors.Is(err, ErrOpen) {
+					t.Errorf("Get() on healthy host error = %v, want ErrOpen", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get() on healthy host error = %v", err)
+			}
+			// 4xx responses are not failures
+			resp.Body.Close()
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewMetrics(reg)
+	if err != nil {
+		t.Fatalf("NewMetrics() error = %v", err)
+	}
+	if _, err := NewMetrics(reg); err != nil {
+		t.Fatalf("second NewMetrics() error = %v, want shared collectors", err)
+	}
+
+	changes := 0
+	cfg := tripConfig()
+	cfg.Name = "payments"
+	cfg.OnStateChange = func(string, State, State) { changes++ }
+	b := New(m.Instrument(cfg))
+
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return errors.New("down") })
+	b.Execute(func() error { return errors.New("down") })
+	b.Execute(func() error { return nil })
+
+	want := `
+# HELP circuit_breaker_calls_total Calls recorded by circuit breakers, by outcome.
+# TYPE circuit_breaker_calls_total counter
+circuit_breaker_calls_total{name="payments",outcome="failure"} 2
+circuit_breaker_calls_total{name="payments",outcome="success"} 1
+# HELP circuit_breaker_rejected_total Calls rejected by circuit breakers without running.
+# TYPE circuit_breaker_rejected_total counter
+circuit_breaker_rejected_total{name="payments",state="open"} 1
+# HELP circuit_breaker_state State of each circuit breaker: 0 closed, 1 open, 2 half-open.
+# TYPE circuit_breaker_state gauge
+circuit_breaker_state{name="payments"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+	if changes != 1 {
+		t.Errorf("existing OnStateChange called %d times, want 1", changes)
+	}
+}
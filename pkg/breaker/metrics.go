@@ -0,0 +1,93 @@
+package breaker
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics exports breaker states, calls and rejections to Prometheus
+type Metrics struct {
+	state    *prometheus.GaugeVec
+	calls    *prometheus.CounterVec
+	rejected *prometheus.CounterVec
+}
+
+// NewMetrics registers the circuit_breaker_state, circuit_breaker_calls_total
+// and circuit_breaker_rejected_total collectors with reg, or with
+// prometheus.DefaultRegisterer when nil. Collectors already registered in
+// the process are shared.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	state := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "circuit_breaker_state",
+		Help: "State of each circuit breaker: 0 closed, 1 open, 2 half-open.",
+	}, []string{"name"})
+	calls := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "circuit_breaker_calls_total",
+		Help: "Calls recorded by circuit breakers, by outcome.",
+	}, []string{"name", "outcome"})
+	rejected := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "circuit_breaker_rejected_total",
+		Help: "Calls rejected by circuit breakers without running.",
+	}, []string{"name", "state"})
+
+	if err := register(reg, &state); err != nil {
+		return nil, err
+	}
+	if err := register(reg, &calls); err != nil {
+		return nil, err
+	}
+	if err := register(reg, &rejected); err != nil {
+		return nil, err
+	}
+	return &Metrics{state: state, calls: calls, rejected: rejected}, nil
+}
+
+// register registers *c, replacing it with the existing collector when an
+// identical one is already registered
+func register[C prometheus.Collector](reg prometheus.Registerer, c *C) error {
+	err := reg.Register(*c)
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			*c = existing
+			return nil
+		}
+	}
+	return err
+}
+
+// Instrument returns cfg with hooks feeding the metrics, calling any hooks
+// cfg already has
+func (m *Metrics) Instrument(cfg Config) Config {
+	onStateChange, onCall, onReject := cfg.OnStateChange, cfg.OnCall, cfg.OnReject
+	cfg.OnStateChange = func(name string, from, to State) {
+		m.state.WithLabelValues(name).Set(float64(to))
+		if onStateChange != nil {
+			onStateChange(name, from, to)
+		}
+	}
+	cfg.OnCall = func(name string, result CallResult) {
+		outcome := "success"
+		switch {
+		case result.Failure:
+			outcome = "failure"
+		case result.Slow:
+			outcome = "slow"
+		}
+		m.calls.WithLabelValues(name, outcome).Inc()
+		if onCall != nil {
+			onCall(name, result)
+		}
+	}
+	cfg.OnReject = func(name string, state State) {
+		m.rejected.WithLabelValues(name, state.String()).Inc()
+		if onReject != nil {
+			onReject(name, state)
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,76 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// errServerError marks responses the transport counts as failures
+var errServerError = errors.New("breaker: server error response")
+
+// TransportConfig configures a Transport
+type TransportConfig struct {
+	// Breaker configures the breakers; its IsFailure is replaced by the
+	// transport's own classification
+	Breaker Config
+	// PerHost gives every host its own breaker, so one failing dependency
+	// does not cut off the others behind the same client
+	PerHost bool
+	// IsFailure decides whether a round trip counts as a failure; by
+	// default transport errors and 5xx responses do
+	IsFailure func(resp *http.Response, err error) bool
+}
+
+// Transport is an http.RoundTripper failing fast while a breaker is open.
+// Rejected requests return an error wrapping ErrOpen or ErrTooManyTrialCalls.
+type Transport struct {
+	base      http.RoundTripper
+	group     *Group
+	perHost   bool
+	isFailure func(resp *http.Response, err error) bool
+}
+
+// NewTransport wraps base, http.DefaultTransport when nil
+func NewTransport(base http.RoundTripper, cfg TransportConfig) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= http.StatusInternalServerError
+		}
+	}
+	cfg.Breaker.IsFailure = nil
+	return &Transport{base: base, group: NewGroup(cfg.Breaker), perHost: cfg.PerHost, isFailure: cfg.IsFailure}
+}
+
+// Breaker returns the breaker guarding requests to host; host is ignored
+// unless PerHost is set
+func (t *Transport) Breaker(host string) *Breaker {
+	if !t.perHost {
+		host = ""
+	}
+	return t.group.Get(host)
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b := t.Breaker(req.URL.Host)
+	done, err := b.Allow()
+	if err != nil {
+		return nil, fmt.Errorf("breaker: %s: %w", req.URL.Host, err)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		// The caller gave up; that says nothing about the dependency
+		done(nil)
+	case t.isFailure(resp, err):
+		done(errServerError)
+	default:
+		done(nil)
+	}
+	return resp, err
+}
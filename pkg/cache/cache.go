@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Cache is the key-value subset of Client, so that code needing only plain
+// caching can run on Redis, Memcached or in memory. A missing key returns
+// redis.Nil whatever the backend.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	// Set stores value with optional TTL, 0 meaning no expiry
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	// TTL returns the remaining TTL of key, -1 when it has no expiry and -2
+	// when it does not exist, like Redis
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+var (
+	_ Cache = (*Client)(nil)
+	_ Cache = (*Memory)(nil)
+	_ Cache = (*Memcached)(nil)
+)
+
+const (
+	// ttlNoExpiry and ttlMissing are the TTL results of Redis for a key with
+	// no expiry and for a missing key
+	ttlNoExpiry time.Duration = -1
+	ttlMissing  time.Duration = -2
+)
+
+// formatValue converts a Set value to the string Redis would store
+func formatValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return strconv.FormatInt(v.Nanoseconds(), 10), nil
+	case encoding.BinaryMarshaler:
+		data, err := v.MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("can't marshal %T (implement encoding.BinaryMarshaler)", value)
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// DefaultCompressThreshold is the encoded size from which object values are
+// gzip-compressed
+const DefaultCompressThreshold = 1024
+
+// Codec serializes the values of SetObject and GetObject; implement it to
+// store values as protobuf or any other format
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	// JSONCodec encodes values with encoding/json
+	JSONCodec Codec = jsonCodec{}
+	// MsgPackCodec encodes values with MessagePack, smaller and faster than
+	// JSON; fields are named by msgpack tags, then json tags
+	MsgPackCodec Codec = msgpackCodec{}
+)
+
+// jsonCodec implements Codec with encoding/json
+type jsonCodec struct{}
+
+// Marshal implements Codec
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements Codec
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// msgpackCodec implements Codec with MessagePack
+type msgpackCodec struct{}
+
+// Marshal implements Codec
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal implements Codec
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+// gzipMagic starts every gzip stream; neither JSON, MessagePack nor
+// protobuf values can start with it, so compressed values need no marker
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// encodeValue serializes v, compressing it from threshold bytes when
+// threshold is positive
+func encodeValue(codec Codec, v interface{}, threshold int) ([]byte, error) {
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cache value: %w", err)
+	}
+	if threshold <= 0 || len(data) < threshold {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress cache value: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress cache value: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeValue decompresses data when needed and deserializes it into v
+func decodeValue(codec Codec, data []byte, v interface{}) error {
+	if bytes.HasPrefix(data, gzipMagic) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("failed to decompress cache value: %w", err)
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return fmt.Errorf("failed to decompress cache value: %w", err)
+		}
+	}
+	if err := codec.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode cache value: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type cachedUser struct {
+	ID    int64    `json:"id"`
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+}
+
+func TestEncodeValue(t *testing.T) {
+	small := cachedUser{ID: 42, Name: "ada", Roles: []string{"admin"}}
+	large := cachedUser{ID: 7, Name: strings.Repeat("x", 4096)}
+
+	tests := []struct {
+		name           string
+		codec          Codec
+		value          cachedUser
+		threshold      int
+		wantCompressed bool
+	}{
+		{name: "json", codec: JSONCodec, value: small, threshold: DefaultCompressThreshold},
+		{name: "msgpack", codec: MsgPackCodec, value: small, threshold: DefaultCompressThreshold},
+		{name: "json compressed", codec: JSONCodec, value: large, threshold: DefaultCompressThreshold, wantCompressed: true},
+		{name: "msgpack compressed", codec: MsgPackCodec, value: large, threshold: DefaultCompressThreshold, wantCompressed: true},
+		{name: "compression disabled", codec: JSONCodec, value: large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := encodeValue(tt.codec, tt.value, tt.threshold)
+			if err != nil {
+				t.Fatalf("encodeValue() error = %v", err)
+			}
+			if compressed := bytes.HasPrefix(data, gzipMagic); compressed != tt.wantCompressed {
+				t.Errorf("compressed = %v, want %v", compressed, tt.wantCompressed)
+			}
+			if tt.wantCompressed && len(data) >= 4096 {
+				t.Errorf("compressed size = %d", len(data))
+			}
+
+			var got cachedUser
+			if err := decodeValue(tt.codec, data, &got); err != nil {
+				t.Fatalf("decodeValue() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("decodeValue() = %+v, want %+v", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestDecodeValueErrors(t *testing.T) {
+	var v cachedUser
+	if err := decodeValue(JSONCodec, []byte("not json"), &v); err == nil {
+		t.Error("decodeValue() of invalid JSON should fail")
+	}
+	if err := decodeValue(JSONCodec, append(append([]byte{}, gzipMagic...), 0, 1), &v); err == nil {
+		t.Error("decodeValue() of a truncated gzip stream should fail")
+	}
+	// Values written with Set as plain JSON strings are read by GetJSON
+	if err := decodeValue(JSONCodec, []byte(`{"id":1,"name":"a"}`), &v); err != nil || v.ID != 1 {
+		t.Errorf("decodeValue() = %+v, %v", v, err)
+	}
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCounterDurationValidation(t *testing.T) {
+	client := New(DefaultConfig())
+	defer client.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "IncrWithExpire zero ttl", call: func() error {
+			_, err := client.IncrWithExpire(ctx, "quota", 1, 0)
+			return err
+		}},
+		{name: "SlidingWindowCount negative window", call: func() error {
+			_, err := client.SlidingWindowCount(ctx, "rate", -time.Second)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, errNonPositiveDuration) {
+				t.Errorf("error = %v, want %v", err, errNonPositiveDuration)
+			}
+		})
+	}
+}
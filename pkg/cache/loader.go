@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// DefaultNegativeTTL is how long a missing value is remembered
+	DefaultNegativeTTL = 30 * time.Second
+	// DefaultTTLJitter spreads expirations of keys cached together
+	DefaultTTLJitter = 0.1
+)
+
+// ErrNotFound is returned by a GetOrLoad loader when the value does not
+// exist; the miss is cached for NegativeTTL and returned to callers
+var ErrNotFound = errors.New("cache: value not found")
+
+// negativeValue marks a cached miss; it starts with the first byte of the
+// gzip magic, which no codec output starts with, but is not gzip
+var negativeValue = []byte("\x1fmora:not-found")
+
+// LoadOptions contains options for GetOrLoad
+type LoadOptions struct {
+	NegativeTTL time.Duration // TTL of cached misses, 0 disables negative caching
+	Jitter      float64       // Lengthens each TTL by up to this share of it, 0-1
+}
+
+// DefaultLoadOptions returns default GetOrLoad options
+func DefaultLoadOptions() LoadOptions {
+	return LoadOptions{
+		NegativeTTL: DefaultNegativeTTL,
+		Jitter:      DefaultTTLJitter,
+	}
+}
+
+// GetOrLoad returns the value cached at key, or calls load, caches its
+// result for ttl and returns it. Concurrent calls for a key in the process
+// share one load, so an expired hot key does not stampede the database.
+// When load returns ErrNotFound, the miss is cached for NegativeTTL.
+// Cache errors do not fail the call: the value is loaded and returned.
+//
+//	user, err := cache.GetOrLoad(ctx, client, "user:42", time.Hour, func() (User, error) {
+//		return repo.FindUser(ctx, 42)
+//	})
+func GetOrLoad[T any](ctx context.Context, c *Client, key string, ttl time.Duration, load func() (T, error), opts ...LoadOptions) (T, error) {
+	options := DefaultLoadOptions()
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	if v, ok, err := getCached[T](ctx, c, key); ok {
+		return v, err
+	}
+
+	res, err, _ := c.loads.Do(key, func() (interface{}, error) {
+		v, err := load()
+		switch {
+		case errors.Is(err, ErrNotFound):
+			if options.NegativeTTL > 0 {
+				c.rdb.Set(ctx, key, negativeValue, jitteredTTL(options.NegativeTTL, options.Jitter))
+			}
+		case err == nil:
+			if data, err := encodeValue(c.codec, v, c.compressThreshold); err == nil {
+				c.rdb.Set(ctx, key, data, jitteredTTL(ttl, options.Jitter))
+			}
+		}
+		return v, err
+	})
+	v, _ := res.(T)
+	return v, err
+}
+
+// getCached returns the value cached at key; ok is false on a miss, and on
+// errors so that the value is loaded again
+func getCached[T any](ctx context.Context, c *Client, key string) (v T, ok bool, err error) {
+	data, err := c.rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		return v, false, nil
+	}
+	if bytes.Equal(data, negativeValue) {
+		return v, true, ErrNotFound
+	}
+	// A value that no longer decodes, e.g. after its type changed, is replaced
+	if err := decodeValue(c.codec, data, &v); err != nil {
+		return v, false, nil
+	}
+	return v, true, nil
+}
+
+// jitteredTTL lengthens ttl by up to jitter of it, so keys cached at the
+// same time do not expire together; 0 keeps the key forever
+func jitteredTTL(ttl time.Duration, jitter float64) time.Duration {
+	jitter = min(max(jitter, 0), 1)
+	if ttl <= 0 || jitter == 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Float64()*float64(ttl)*jitter)
+}
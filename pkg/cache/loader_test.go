@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJitteredTTL(t *testing.T) {
+	tests := []struct {
+		name   string
+		ttl    time.Duration
+		jitter float64
+		max    time.Duration
+	}{
+		{name: "no jitter", ttl: time.Minute, max: time.Minute},
+		{name: "jitter", ttl: time.Minute, jitter: 0.5, max: 90 * time.Second},
+		{name: "clamped", ttl: time.Minute, jitter: 3, max: 2 * time.Minute},
+		{name: "no expiration", ttl: 0, jitter: 0.5, max: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := jitteredTTL(tt.ttl, tt.jitter); got < tt.ttl || got > tt.max {
+					t.Fatalf("jitteredTTL(%v, %v) = %v, want within [%v, %v]", tt.ttl, tt.jitter, got, tt.ttl, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestNegativeValue(t *testing.T) {
+	if bytes.HasPrefix(negativeValue, gzipMagic) {
+		t.Error("negativeValue must not look compressed")
+	}
+	var v string
+	if err := decodeValue(JSONCodec, negativeValue, &v); err == nil {
+		t.Error("negativeValue must not decode as JSON")
+	}
+}
+
+func TestGetOrLoadWithoutRedis(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Addr = "127.0.0.1:1"
+	client := New(cfg)
+	defer client.Close()
+	ctx := context.Background()
+
+	// An unreachable cache falls back on the loader
+	got, err := GetOrLoad(ctx, client, "user:42", time.Minute, func() (cachedUser, error) {
+		return cachedUser{ID: 42}, nil
+	})
+	if err != nil || got.ID != 42 {
+		t.Errorf("GetOrLoad() = %+v, %v", got, err)
+	}
+
+	if _, err := GetOrLoad(ctx, client, "user:43", time.Minute, func() (cachedUser, error) {
+		return cachedUser{}, ErrNotFound
+	}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetOrLoad() error = %v, want ErrNotFound", err)
+	}
+}
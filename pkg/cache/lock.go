@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"mora/pkg/retry"
 )
 
 const (
@@ -32,6 +34,8 @@ type DistributedLock struct {
 	key    string
 	value  string
 	ttl    time.Duration
+	// acquiredAt times how long the lock is held, for the lock hook
+	acquiredAt time.Time
 }
 
 // LockOptions contains options for acquiring a lock
@@ -54,6 +58,14 @@ func DefaultLockOptions() LockOptions {
 
 // TryLock attempts to acquire a distributed lock without retries
 func (c *Client) TryLock(ctx context.Context, key string, ttl time.Duration) (*DistributedLock, error) {
+	start := time.Now()
+	lock, err := c.tryLock(ctx, key, ttl)
+	c.observeAcquire(key, start, err)
+	return lock, err
+}
+
+// tryLock makes one acquisition attempt
+func (c *Client) tryLock(ctx context.Context, key string, ttl time.Duration) (*DistributedLock, error) {
 	value := generateLockValue()
 
 	// Use SET with NX (only if not exists) and EX (expiration)
@@ -67,10 +79,11 @@ func (c *Client) TryLock(ctx context.Context, key string, ttl time.Duration) (*D
 	}
 
 	return &DistributedLock{
-		client: c,
-		key:    key,
-		value:  value,
-		ttl:    ttl,
+		client:     c,
+		key:        key,
+		value:      value,
+		ttl:        ttl,
+		acquiredAt: time.Now(),
 	}, nil
 }
 
@@ -81,50 +94,52 @@ func (c *Client) Lock(ctx context.Context, key string, opts ...LockOptions) (*Di
 		options = opts[0]
 	}
 
+	return acquire(ctx, c, key, options, func(ctx context.Context) (*DistributedLock, error) {
+		return c.tryLock(ctx, key, options.TTL)
+	})
+}
+
+// acquire calls try until it stops returning ErrLockNotAcquired, within the
+// retries and timeout of options, and reports the outcome to the lock hook
+func acquire[T any](ctx context.Context, c *Client, key string, options LockOptions, try func(context.Context) (T, error)) (T, error) {
+	start := time.Now()
+	lock, err := acquireWithRetry(ctx, options, try)
+	c.observeAcquire(key, start, err)
+	return lock, err
+}
+
+// acquireWithRetry implements acquire
+func acquireWithRetry[T any](ctx context.Context, options LockOptions, try func(context.Context) (T, error)) (T, error) {
 	// Create a context with timeout for the entire lock acquisition process
 	lockCtx, cancel := context.WithTimeout(ctx, options.LockTimeout)
 	defer cancel()
 
-	var lastErr error
-	retries := 0
-
-	for {
-		select {
-		case <-lockCtx.Done():
-			if lastErr != nil {
-				return nil, fmt.Errorf("lock acquisition timeout: %w", lastErr)
-			}
-			return nil, fmt.Errorf("lock acquisition timeout: %w", lockCtx.Err())
-		default:
-		}
-
-		lock, err := c.TryLock(lockCtx, key, options.TTL)
-		if err == nil {
-			return lock, nil
-		}
-
-		if !errors.Is(err, ErrLockNotAcquired) {
-			return nil, err
-		}
-
-		lastErr = err
-		retries++
-
-		if retries > options.MaxRetries {
-			return nil, fmt.Errorf("max retries exceeded: %w", ErrLockNotAcquired)
-		}
-
-		// Wait before retrying
-		select {
-		case <-lockCtx.Done():
-			return nil, fmt.Errorf("lock acquisition timeout during retry: %w", lockCtx.Err())
-		case <-time.After(options.RetryDelay):
-		}
+	lock, err := retry.DoValue(lockCtx, retry.Config{
+		MaxAttempts: options.MaxRetries + 1,
+		Backoff:     options.RetryDelay,
+		Retryable:   func(err error) bool { return errors.Is(err, ErrLockNotAcquired) },
+	}, try)
+	var zero T
+	switch {
+	case err == nil:
+		return lock, nil
+	case lockCtx.Err() != nil:
+		return zero, fmt.Errorf("lock acquisition timeout: %w", err)
+	case errors.Is(err, ErrLockNotAcquired):
+		return zero, fmt.Errorf("max retries exceeded: %w", ErrLockNotAcquired)
 	}
+	return zero, err
 }
 
 // Unlock releases the distributed lock
 func (lock *DistributedLock) Unlock(ctx context.Context) error {
+	err := lock.unlock(ctx)
+	lock.client.observeRelease(lock.key, lock.acquiredAt, err, false)
+	return err
+}
+
+// unlock implements Unlock
+func (lock *DistributedLock) unlock(ctx context.Context) error {
 	// Lua script to ensure we only delete the lock if we own it
 	script := `
 		if redis.call("get", KEYS[1]) == ARGV[1] then
@@ -148,6 +163,13 @@ func (lock *DistributedLock) Unlock(ctx context.Context) error {
 
 // Extend extends the lock's TTL
 func (lock *DistributedLock) Extend(ctx context.Context, ttl time.Duration) error {
+	err := lock.extend(ctx, ttl)
+	lock.client.observeRelease(lock.key, lock.acquiredAt, err, true)
+	return err
+}
+
+// extend implements Extend
+func (lock *DistributedLock) extend(ctx context.Context, ttl time.Duration) error {
 	// Lua script to extend TTL only if we own the lock
 	script := `
 		if redis.call("get", KEYS[1]) == ARGV[1] then
@@ -205,12 +227,8 @@ func (c *Client) WithLock(ctx context.Context, key string, fn func() error, opts
 		return err
 	}
 
-	defer func() {
-		if unlockErr := lock.Unlock(ctx); unlockErr != nil {
-			// Log the unlock error but don't return it
-			// You might want to integrate with your logger here
-		}
-	}()
+	// Unlock failures are not returned, they reach the lock hook
+	defer func() { _ = lock.Unlock(ctx) }()
 
 	return fn()
 }
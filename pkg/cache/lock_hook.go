@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// LockEventType identifies a lock event
+type LockEventType string
+
+const (
+	// LockAcquired is sent once a lock is taken, Duration being the wait
+	LockAcquired LockEventType = "acquired"
+	// LockContended is sent when an acquisition gives up because the lock is
+	// held elsewhere, Duration being the wait
+	LockContended LockEventType = "contended"
+	// LockReleased is sent by Unlock, Duration being how long the lock was
+	// held; Err is set when the release failed
+	LockReleased LockEventType = "released"
+	// LockLost is sent when Unlock or Extend finds the lock expired or taken
+	// over, Duration being how long it was held
+	LockLost LockEventType = "lost"
+)
+
+// LockEvent describes something that happened to a lock, for metrics and
+// alerting on contention
+type LockEvent struct {
+	Type     LockEventType
+	Key      string
+	Duration time.Duration
+	Err      error
+}
+
+// LockHook is called synchronously with every lock event of a client
+type LockHook func(LockEvent)
+
+// emitLock passes ev to the lock hook
+func (c *Client) emitLock(ev LockEvent) {
+	if c.lockHook != nil {
+		c.lockHook(ev)
+	}
+}
+
+// observeAcquire reports the outcome of a lock acquisition started at start
+func (c *Client) observeAcquire(key string, start time.Time, err error) {
+	switch {
+	case err == nil:
+		c.emitLock(LockEvent{Type: LockAcquired, Key: key, Duration: time.Since(start)})
+	case errors.Is(err, ErrLockNotAcquired):
+		c.emitLock(LockEvent{Type: LockContended, Key: key, Duration: time.Since(start), Err: err})
+	}
+}
+
+// observeRelease reports the outcome of releasing or extending a lock
+// acquired at acquiredAt; extend only reports losses
+func (c *Client) observeRelease(key string, acquiredAt time.Time, err error, extend bool) {
+	held := time.Since(acquiredAt)
+	switch {
+	case errors.Is(err, ErrLockNotOwned):
+		c.emitLock(LockEvent{Type: LockLost, Key: key, Duration: held, Err: err})
+	case !extend:
+		c.emitLock(LockEvent{Type: LockReleased, Key: key, Duration: held, Err: err})
+	}
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	errRedis := errors.New("connection refused")
+	options := LockOptions{RetryDelay: time.Millisecond, MaxRetries: 2, LockTimeout: time.Second}
+
+	tests := []struct {
+		name      string
+		options   LockOptions
+		failures  int
+		err       error
+		wantErr   error
+		wantCalls int
+		wantEvent LockEventType
+	}{
+		{name: "first try", options: options, wantCalls: 1, wantEvent: LockAcquired},
+		{name: "after retries", options: options, failures: 2, err: ErrLockNotAcquired, wantCalls: 3, wantEvent: LockAcquired},
+		{name: "retries exhausted", options: options, failures: 5, err: ErrLockNotAcquired, wantErr: ErrLockNotAcquired, wantCalls: 3, wantEvent: LockContended},
+		{name: "redis error", options: options, failures: 1, err: errRedis, wantErr: errRedis, wantCalls: 1},
+		{name: "timeout", options: LockOptions{RetryDelay: 50 * time.Millisecond, MaxRetries: 10, LockTimeout: 10 * time.Millisecond},
+			failures: 10, err: ErrLockNotAcquired, wantErr: ErrLockNotAcquired, wantCalls: 1, wantEvent: LockContended},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []LockEvent
+			cfg := DefaultConfig()
+			cfg.LockHook = func(ev LockEvent) { events = append(events, ev) }
+			client := New(cfg)
+			defer client.Close()
+
+			calls := 0
+			got, err := acquire(context.Background(), client, "orders", tt.options, func(context.Context) (string, error) {
+				calls++
+				if calls <= tt.failures {
+					return "", tt.err
+				}
+				return "lock", nil
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("acquire() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil || got != "lock" {
+				t.Errorf("acquire() = %q, %v", got, err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			switch {
+			case tt.wantEvent == "" && len(events) > 0:
+				t.Errorf("events = %+v, want none", events)
+			case tt.wantEvent != "" && (len(events) != 1 || events[0].Type != tt.wantEvent || events[0].Key != "orders"):
+				t.Errorf("events = %+v, want one %s event", events, tt.wantEvent)
+			}
+		})
+	}
+}
+
+func TestObserveRelease(t *testing.T) {
+	errRedis := errors.New("connection refused")
+
+	tests := []struct {
+		name    string
+		err     error
+		extend  bool
+		want    LockEventType
+		wantErr error
+	}{
+		{name: "released", want: LockReleased},
+		{name: "unlock failed", err: errRedis, want: LockReleased, wantErr: errRedis},
+		{name: "lost on unlock", err: ErrLockNotOwned, want: LockLost, wantErr: ErrLockNotOwned},
+		{name: "lost on extend", err: ErrLockNotOwned, extend: true, want: LockLost, wantErr: ErrLockNotOwned},
+		{name: "extended", extend: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []LockEvent
+			client := New(Config{LockHook: func(ev LockEvent) { events = append(events, ev) }})
+			defer client.Close()
+
+			client.observeRelease("orders", time.Now().Add(-time.Second), tt.err, tt.extend)
+			if tt.want == "" {
+				if len(events) > 0 {
+					t.Errorf("events = %+v, want none", events)
+				}
+				return
+			}
+			if len(events) != 1 {
+				t.Fatalf("events = %+v, want one", events)
+			}
+			ev := events[0]
+			if ev.Type != tt.want || !errors.Is(ev.Err, tt.wantErr) || ev.Duration < time.Second {
+				t.Errorf("event = %+v, want %s with %v", ev, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
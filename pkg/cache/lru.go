@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru is a size-bounded in-memory cache evicting the least recently used
+// entry, with a TTL per entry; a zero expiry means none
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	items   map[string]*list.Element
+	order   *list.List
+	nowFunc func() time.Time
+}
+
+// lruEntry is an entry of lru
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// newLRU creates an lru holding up to size entries
+func newLRU(size int) *lru {
+	return &lru{
+		size:    size,
+		items:   make(map[string]*list.Element),
+		order:   list.New(),
+		nowFunc: time.Now,
+	}
+}
+
+// get returns the value of key unless it is missing or expired
+func (c *lru) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*lruEntry)
+	if c.expired(entry) {
+		c.removeElement(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.value, true
+}
+
+// ttl returns the time key has left, 0 when it does not expire
+func (c *lru) ttl(key string) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return 0, false
+	}
+	entry := el.Value.(*lruEntry)
+	if c.expired(entry) {
+		c.removeElement(el)
+		return 0, false
+	}
+	if entry.expiresAt.IsZero() {
+		return 0, true
+	}
+	return entry.expiresAt.Sub(c.nowFunc()), true
+}
+
+// set stores value for ttl, or with no expiry when ttl is not positive,
+// evicting the least recently used entry when full
+func (c *lru) set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.nowFunc().Add(ttl)
+	}
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value, entry.expiresAt = value, expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// remove deletes keys
+func (c *lru) remove(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.removeElement(el)
+		}
+	}
+}
+
+// purge deletes every entry
+func (c *lru) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// len returns the number of entries, expired ones included
+func (c *lru) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// expired reports whether entry has expired; c.mu must be held
+func (c *lru) expired(entry *lruEntry) bool {
+	return !entry.expiresAt.IsZero() && c.nowFunc().After(entry.expiresAt)
+}
+
+// removeElement deletes an entry; c.mu must be held
+func (c *lru) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := newLRU(2)
+	c.nowFunc = func() time.Time { return now }
+
+	c.set("a", []byte("1"), time.Minute)
+	c.set("b", []byte("2"), time.Minute)
+	c.get("a") // a is now more recent than b
+	c.set("c", []byte("3"), time.Minute)
+
+	if _, ok := c.get("b"); ok {
+		t.Error("least recently used entry b should be evicted")
+	}
+	if v, ok := c.get("a"); !ok || string(v) != "1" {
+		t.Errorf("get(a) = %q, %v", v, ok)
+	}
+
+	c.set("a", []byte("10"), time.Second)
+	if v, _ := c.get("a"); string(v) != "10" || c.len() != 2 {
+		t.Errorf("get(a) after update = %q, len %d", v, c.len())
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, ok := c.get("a"); ok {
+		t.Error("expired entry a should be missing")
+	}
+	if _, ok := c.get("c"); !ok {
+		t.Error("entry c should still be cached")
+	}
+
+	c.remove("c", "missing")
+	if c.len() != 0 {
+		t.Errorf("len = %d after remove, want 0", c.len())
+	}
+	c.set("d", []byte("4"), time.Minute)
+	c.purge()
+	if _, ok := c.get("d"); ok || c.len() != 0 {
+		t.Error("purge should empty the cache")
+	}
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/redis/go-redis/v9"
+)
+
+// MemcachedConfig holds Memcached configuration
+type MemcachedConfig struct {
+	// Servers are host:port addresses, keys being spread over them
+	Servers      []string      `json:"servers" yaml:"servers"`
+	Timeout      time.Duration `json:"timeout" yaml:"timeout" env:"TIMEOUT"`
+	MaxIdleConns int           `json:"max_idle_conns" yaml:"max_idle_conns" env:"MAX_IDLE_CONNS"`
+}
+
+// DefaultMemcachedConfig returns default Memcached configuration
+func DefaultMemcachedConfig() MemcachedConfig {
+	return MemcachedConfig{
+		Servers:      []string{"localhost:11211"},
+		Timeout:      memcache.DefaultTimeout,
+		MaxIdleConns: memcache.DefaultMaxIdleConns,
+	}
+}
+
+// memcachedRelativeLimit is the largest expiration Memcached reads as
+// seconds from now; larger ones are Unix timestamps
+const memcachedRelativeLimit = 30 * 24 * time.Hour
+
+// memcacheClient is the part of *memcache.Client Memcached uses
+type memcacheClient interface {
+	Get(key string) (*memcache.Item, error)
+	Set(item *memcache.Item) error
+	Delete(key string) error
+	Ping() error
+	Close() error
+}
+
+// Memcached is a Cache on Memcached. Memcached cannot report TTLs, so each
+// item also carries its expiry in its flags, as a Unix time in seconds or 0
+// without one; values are stored as is, readable by any client. TTLs are
+// rounded up to the second.
+type Memcached struct {
+	mc  memcacheClient
+	now func() time.Time
+}
+
+// NewMemcached creates a Memcached cache
+func NewMemcached(cfg MemcachedConfig) *Memcached {
+	mc := memcache.New(cfg.Servers...)
+	mc.Timeout = cfg.Timeout
+	mc.MaxIdleConns = cfg.MaxIdleConns
+	return &Memcached{mc: mc, now: time.Now}
+}
+
+// Ping tests the connection to every server
+func (m *Memcached) Ping(ctx context.Context) error {
+	return m.mc.Ping()
+}
+
+// Close closes the idle connections
+func (m *Memcached) Close() error {
+	return m.mc.Close()
+}
+
+// Get gets a value by key
+func (m *Memcached) Get(ctx context.Context, key string) (string, error) {
+	value, _, err := m.get(key)
+	return string(value), err
+}
+
+// Set sets a key-value pair with optional TTL
+func (m *Memcached) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	s, err := formatValue(value)
+	if err != nil {
+		return err
+	}
+
+	item := &memcache.Item{Key: key, Value: []byte(s)}
+	if ttl > 0 {
+		secs := int64((ttl + time.Second - 1) / time.Second)
+		expiresAt := m.now().Unix() + secs
+		item.Flags = uint32(expiresAt)
+		item.Expiration = int32(secs)
+		if ttl > memcachedRelativeLimit {
+			item.Expiration = int32(expiresAt)
+		}
+	}
+	return m.mc.Set(item)
+}
+
+// Delete deletes keys
+func (m *Memcached) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := m.mc.Delete(key); err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exists checks if a key exists
+func (m *Memcached) Exists(ctx context.Context, key string) (bool, error) {
+	_, _, err := m.get(key)
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// TTL gets the TTL of a key
+func (m *Memcached) TTL(ctx context.Context, key string) (time.Duration, error) {
+	_, expiresAt, err := m.get(key)
+	switch {
+	case errors.Is(err, redis.Nil):
+		return ttlMissing, nil
+	case err != nil:
+		return 0, err
+	case expiresAt.IsZero():
+		return ttlNoExpiry, nil
+	}
+	return expiresAt.Sub(m.now()), nil
+}
+
+// get reads a value and its expiry, zero when it has none
+func (m *Memcached) get(key string) ([]byte, time.Time, error) {
+	item, err := m.mc.Get(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil, time.Time{}, redis.Nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var expiresAt time.Time
+	if item.Flags != 0 {
+		expiresAt = time.Unix(int64(item.Flags), 0)
+		// Memcached may still serve an item within its last second
+		if !m.now().Before(expiresAt) {
+			return nil, time.Time{}, redis.Nil
+		}
+	}
+	return item.Value, expiresAt, nil
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeMemcache is an in-memory memcacheClient; like the real client, Get
+// returns the flags but not the expiration
+type fakeMemcache struct {
+	items map[string]memcache.Item
+}
+
+func (f *fakeMemcache) Get(key string) (*memcache.Item, error) {
+	item, ok := f.items[key]
+	if !ok {
+		return nil, memcache.ErrCacheMiss
+	}
+	item.Expiration = 0
+	return &item, nil
+}
+
+func (f *fakeMemcache) Set(item *memcache.Item) error {
+	f.items[item.Key] = *item
+	return nil
+}
+
+func (f *fakeMemcache) Delete(key string) error {
+	if _, ok := f.items[key]; !ok {
+		return memcache.ErrCacheMiss
+	}
+	delete(f.items, key)
+	return nil
+}
+
+func (f *fakeMemcache) Ping() error  { return nil }
+func (f *fakeMemcache) Close() error { return nil }
+
+func TestMemcached(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name           string
+		ttl            time.Duration
+		wantFlags      uint32
+		wantExpiration int32
+		wantTTL        time.Duration
+	}{
+		{name: "no expiry", wantTTL: ttlNoExpiry},
+		{name: "rounded up to the second", ttl: 1500 * time.Millisecond, wantFlags: 1_700_000_002, wantExpiration: 2, wantTTL: 2 * time.Second},
+		{
+			name:           "beyond 30 days is absolute",
+			ttl:            40 * 24 * time.Hour,
+			wantFlags:      1_700_000_000 + 40*24*3600,
+			wantExpiration: 1_700_000_000 + 40*24*3600,
+			wantTTL:        40 * 24 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeMemcache{items: map[string]memcache.Item{}}
+			m := &Memcached{mc: fake, now: func() time.Time { return now }}
+			ctx := context.Background()
+
+			if err := m.Set(ctx, "k", 42, tt.ttl); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			item := fake.items["k"]
+			if string(item.Value) != "42" || item.Flags != tt.wantFlags || item.Expiration != tt.wantExpiration {
+				t.Errorf("stored %q with flags %d, expiration %d, want \"42\", %d, %d",
+					item.Value, item.Flags, item.Expiration, tt.wantFlags, tt.wantExpiration)
+			}
+			if got, err := m.Get(ctx, "k"); err != nil || got != "42" {
+				t.Errorf("Get() = %q, %v, want 42", got, err)
+			}
+			if ttl, err := m.TTL(ctx, "k"); err != nil || ttl != tt.wantTTL {
+				t.Errorf("TTL() = %v, %v, want %v", ttl, err, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestMemcachedMissing(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	fake := &fakeMemcache{items: map[string]memcache.Item{}}
+	m := &Memcached{mc: fake, now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	if err := m.Set(ctx, "k", "v", time.Second); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	// Memcached may serve the item until the end of its last second
+	now = now.Add(time.Second)
+	if _, err := m.Get(ctx, "k"); !errors.Is(err, redis.Nil) {
+		t.Errorf("Get() of an expired item error = %v, want redis.Nil", err)
+	}
+	if ttl, err := m.TTL(ctx, "k"); err != nil || ttl != ttlMissing {
+		t.Errorf("TTL() of an expired item = %v, %v, want %v", ttl, err, ttlMissing)
+	}
+
+	if ok, err := m.Exists(ctx, "other"); err != nil || ok {
+		t.Errorf("Exists() of a missing key = %v, %v", ok, err)
+	}
+	if err := m.Delete(ctx, "k", "other"); err != nil {
+		t.Errorf("Delete() of missing keys error = %v", err)
+	}
+	if len(fake.items) != 0 {
+		t.Errorf("items left after Delete() = %v", fake.items)
+	}
+}
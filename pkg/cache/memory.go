@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MemoryConfig configures a Memory cache
+type MemoryConfig struct {
+	// Size is the maximum number of entries, the least recently used being
+	// evicted first
+	Size int `json:"size" yaml:"size" env:"SIZE"`
+}
+
+// DefaultMemoryConfig returns default in-memory cache configuration
+func DefaultMemoryConfig() MemoryConfig {
+	return MemoryConfig{
+		Size: 10000,
+	}
+}
+
+// Memory is an in-process Cache with LRU eviction and per-key TTL, for unit
+// tests and single-instance deployments
+type Memory struct {
+	local *lru
+}
+
+// NewMemory creates an in-memory cache
+func NewMemory(cfg MemoryConfig) *Memory {
+	if cfg.Size <= 0 {
+		cfg.Size = DefaultMemoryConfig().Size
+	}
+	return &Memory{local: newLRU(cfg.Size)}
+}
+
+// Get gets a value by key
+func (m *Memory) Get(ctx context.Context, key string) (string, error) {
+	data, ok := m.local.get(key)
+	if !ok {
+		return "", redis.Nil
+	}
+	return string(data), nil
+}
+
+// Set sets a key-value pair with optional TTL
+func (m *Memory) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	s, err := formatValue(value)
+	if err != nil {
+		return err
+	}
+	m.local.set(key, []byte(s), ttl)
+	return nil
+}
+
+// Delete deletes keys
+func (m *Memory) Delete(ctx context.Context, keys ...string) error {
+	m.local.remove(keys...)
+	return nil
+}
+
+// Exists checks if a key exists
+func (m *Memory) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok := m.local.get(key)
+	return ok, nil
+}
+
+// TTL gets the TTL of a key
+func (m *Memory) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, ok := m.local.ttl(key)
+	switch {
+	case !ok:
+		return ttlMissing, nil
+	case ttl == 0:
+		return ttlNoExpiry, nil
+	}
+	return ttl, nil
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	m := NewMemory(MemoryConfig{})
+	m.local.nowFunc = func() time.Time { return now }
+
+	if err := m.Set(ctx, "session", "abc", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Set(ctx, "visits", 42, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := m.Get(ctx, "visits"); err != nil || v != "42" {
+		t.Errorf("Get(visits) = %q, %v", v, err)
+	}
+	if _, err := m.Get(ctx, "missing"); !errors.Is(err, redis.Nil) {
+		t.Errorf("Get(missing) error = %v, want redis.Nil", err)
+	}
+
+	ttls := map[string]time.Duration{"session": time.Minute, "visits": ttlNoExpiry, "missing": ttlMissing}
+	for key, want := range ttls {
+		if got, err := m.TTL(ctx, key); err != nil || got != want {
+			t.Errorf("TTL(%s) = %v, %v, want %v", key, got, err, want)
+		}
+	}
+
+	now = now.Add(2 * time.Minute)
+	if ok, _ := m.Exists(ctx, "session"); ok {
+		t.Error("expired session should not exist")
+	}
+	if ok, _ := m.Exists(ctx, "visits"); !ok {
+		t.Error("visits without TTL should exist")
+	}
+
+	if err := m.Delete(ctx, "visits", "missing"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := m.Exists(ctx, "visits"); ok {
+		t.Error("deleted visits should not exist")
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "string", value: "abc", want: "abc"},
+		{name: "bytes", value: []byte("abc"), want: "abc"},
+		{name: "int", value: -7, want: "-7"},
+		{name: "float", value: 1.5, want: "1.5"},
+		{name: "bool", value: true, want: "1"},
+		{name: "nil", value: nil, want: ""},
+		{name: "time", value: time.Unix(0, 0).UTC(), want: "1970-01-01T00:00:00Z"},
+		{name: "struct", value: struct{}{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatValue(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("formatValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
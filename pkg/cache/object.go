@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Object Operations
+
+// WithCodec returns a client sharing the connection of c that serializes
+// objects with codec, e.g. a protobuf codec for one group of keys
+func (c *Client) WithCodec(codec Codec) *Client {
+	clone := *c
+	clone.codec = codec
+	return &clone
+}
+
+// SetObject serializes v with the client codec and stores it with optional TTL
+func (c *Client) SetObject(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+	data, err := encodeValue(c.codec, v, c.compressThreshold)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, key, data, ttl).Err()
+}
+
+// getObject retrieves a value stored by SetObject into v, a pointer
+func (c *Client) getObject(ctx context.Context, key string, v interface{}) error {
+	data, err := c.rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		return err
+	}
+	return decodeValue(c.codec, data, v)
+}
+
+// SetJSON stores v as JSON with optional TTL, whatever the client codec
+func (c *Client) SetJSON(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+	return c.WithCodec(JSONCodec).SetObject(ctx, key, v, ttl)
+}
+
+// GetJSON retrieves a JSON value stored by SetJSON, or any JSON string,
+// into v, a pointer; a missing key returns redis.Nil
+func (c *Client) GetJSON(ctx context.Context, key string, v interface{}) error {
+	return c.WithCodec(JSONCodec).getObject(ctx, key, v)
+}
+
+// GetObject retrieves a value stored by SetObject as a T; a missing key
+// returns redis.Nil, e.g.
+//
+//	user, err := cache.GetObject[User](ctx, client, "user:42")
+func GetObject[T any](ctx context.Context, c *Client, key string) (T, error) {
+	var v T
+	err := c.getObject(ctx, key, &v)
+	return v, err
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Probabilistic Operations
+
+// BFAdd adds item to a RedisBloom filter, creating it with the module
+// defaults when missing, and returns whether it was new. It requires the
+// RedisBloom module; see BloomFilter otherwise.
+func (c *Client) BFAdd(ctx context.Context, key string, item interface{}) (bool, error) {
+	return c.rdb.Do(ctx, "BF.ADD", key, item).Bool()
+}
+
+// BFExists reports whether item may have been added to a RedisBloom filter;
+// false is certain, true holds with the error rate of the filter
+func (c *Client) BFExists(ctx context.Context, key string, item interface{}) (bool, error) {
+	return c.rdb.Do(ctx, "BF.EXISTS", key, item).Bool()
+}
+
+// PFAdd adds elements to a HyperLogLog and returns whether its estimate changed
+func (c *Client) PFAdd(ctx context.Context, key string, elements ...interface{}) (bool, error) {
+	n, err := c.rdb.PFAdd(ctx, key, elements...).Result()
+	return n == 1, err
+}
+
+// PFCount estimates the number of unique elements added to the HyperLogLogs
+// of keys, with a standard error of 0.81%
+func (c *Client) PFCount(ctx context.Context, keys ...string) (int64, error) {
+	return c.rdb.PFCount(ctx, keys...).Result()
+}
+
+// PFMerge merges the HyperLogLogs of sources into dest, e.g. daily unique
+// visitors into a weekly count
+func (c *Client) PFMerge(ctx context.Context, dest string, sources ...string) error {
+	return c.rdb.PFMerge(ctx, dest, sources...).Err()
+}
+
+// maxBloomBits is the size limit of a Redis bitmap
+const maxBloomBits = 1 << 32
+
+// BloomFilter is a Bloom filter stored in a plain Redis bitmap, for servers
+// without the RedisBloom module. Its size is fixed at creation: adding more
+// items than planned raises the false positive rate.
+type BloomFilter struct {
+	client *Client
+	key    string
+	bits   uint64
+	hashes int
+}
+
+// NewBloomFilter sizes a filter at key for expectedItems with the wanted
+// falsePositiveRate, e.g. 0.01; every user of key must agree on both
+func (c *Client) NewBloomFilter(key string, expectedItems uint64, falsePositiveRate float64) (*BloomFilter, error) {
+	if expectedItems == 0 || falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, errors.New("bloom filter: expected items must be positive and false positive rate within (0, 1)")
+	}
+	bits, hashes := bloomParams(expectedItems, falsePositiveRate)
+	return &BloomFilter{client: c, key: key, bits: bits, hashes: hashes}, nil
+}
+
+// bloomParams returns the optimal number of bits and hash functions for n
+// items at false positive rate p
+func bloomParams(n uint64, p float64) (uint64, int) {
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	bits := uint64(math.Min(m, maxBloomBits))
+	hashes := int(math.Max(1, math.Round(float64(bits)/float64(n)*math.Ln2)))
+	return bits, hashes
+}
+
+// Add adds items to the filter
+func (f *BloomFilter) Add(ctx context.Context, items ...string) error {
+	_, err := f.client.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, item := range items {
+			for _, offset := range f.offsets(item) {
+				pipe.SetBit(ctx, f.key, int64(offset), 1)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// Exists reports whether item may have been added; false is certain
+func (f *BloomFilter) Exists(ctx context.Context, item string) (bool, error) {
+	offsets := f.offsets(item)
+	cmds := make([]*redis.IntCmd, len(offsets))
+	_, err := f.client.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, offset := range offsets {
+			cmds[i] = pipe.GetBit(ctx, f.key, int64(offset))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, cmd := range cmds {
+		if cmd.Val() == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// offsets returns the bits of item, derived from two halves of one 64-bit
+// hash (Kirsch-Mitzenmacher double hashing)
+func (f *BloomFilter) offsets(item string) []uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(item))
+	sum := h.Sum64()
+	h1, h2 := sum&math.MaxUint32, sum>>32|1
+
+	offsets := make([]uint64, f.hashes)
+	for i := range offsets {
+		offsets[i] = (h1 + uint64(i)*h2) % f.bits
+	}
+	return offsets
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestBloomParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		n          uint64
+		p          float64
+		wantBits   uint64
+		wantHashes int
+	}{
+		{name: "1% of a million", n: 1000000, p: 0.01, wantBits: 9585059, wantHashes: 7},
+		{name: "0.1% of a thousand", n: 1000, p: 0.001, wantBits: 14378, wantHashes: 10},
+		{name: "capped", n: 1 << 40, p: 0.01, wantBits: maxBloomBits, wantHashes: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bits, hashes := bloomParams(tt.n, tt.p)
+			if bits != tt.wantBits || hashes != tt.wantHashes {
+				t.Errorf("bloomParams() = %d, %d, want %d, %d", bits, hashes, tt.wantBits, tt.wantHashes)
+			}
+		})
+	}
+}
+
+func TestNewBloomFilter(t *testing.T) {
+	client := New(DefaultConfig())
+	defer client.Close()
+
+	if _, err := client.NewBloomFilter("seen", 0, 0.01); err == nil {
+		t.Error("zero expected items should fail")
+	}
+	if _, err := client.NewBloomFilter("seen", 100, 1); err == nil {
+		t.Error("false positive rate of 1 should fail")
+	}
+
+	f, err := client.NewBloomFilter("seen", 1000, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offsets := f.offsets("order-42")
+	if len(offsets) != f.hashes {
+		t.Fatalf("len(offsets) = %d, want %d", len(offsets), f.hashes)
+	}
+	for i, offset := range f.offsets("order-42") {
+		if offset != offsets[i] || offset >= f.bits {
+			t.Errorf("offset %d = %d, want stable and below %d", i, offset, f.bits)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Pub/Sub Operations
+
+// Message is a message received by a subscription
+type Message struct {
+	Channel string
+	// Pattern is the pattern matched by PSubscribe subscriptions
+	Pattern string
+	Payload []byte
+
+	codec Codec
+}
+
+// Decode deserializes a payload published with Publish into v, a pointer,
+// using the codec of the subscribing client
+func (m *Message) Decode(v interface{}) error {
+	return decodeValue(m.codec, m.Payload, v)
+}
+
+// Handler processes the messages of a subscription, one at a time
+type Handler func(ctx context.Context, msg *Message) error
+
+// TypedHandler adapts fn to a Handler that decodes each payload as a T
+func TypedHandler[T any](fn func(ctx context.Context, v T) error) Handler {
+	return func(ctx context.Context, msg *Message) error {
+		var v T
+		if err := msg.Decode(&v); err != nil {
+			return err
+		}
+		return fn(ctx, v)
+	}
+}
+
+// SubscribeOptions contains options for Subscribe and PSubscribe
+type SubscribeOptions struct {
+	OnError     func(error)   // Called with handler and connection errors
+	OnReconnect func()        // Called once subscribed again after a connection loss
+	RetryDelay  time.Duration // Delay before receiving again after an error
+}
+
+// DefaultSubscribeOptions returns default subscribe options
+func DefaultSubscribeOptions() SubscribeOptions {
+	return SubscribeOptions{RetryDelay: DefaultRetryDelay}
+}
+
+// Publish sends message to channel. Strings and byte slices are sent as
+// they are, other values are serialized with the client codec.
+func (c *Client) Publish(ctx context.Context, channel string, message interface{}) error {
+	var payload interface{}
+	switch m := message.(type) {
+	case string, []byte:
+		payload = m
+	default:
+		data, err := c.codec.Marshal(m)
+		if err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+		payload = data
+	}
+	if err := c.rdb.Publish(ctx, channel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Subscription delivers the messages of subscribed channels to a handler
+// until it is closed or its context is done
+type Subscription struct {
+	pubsub    *redis.PubSub
+	handler   Handler
+	codec     Codec
+	opts      SubscribeOptions
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Subscribe calls handler with the messages of channels; it returns once
+// subscribed, so no message published afterwards is missed. The
+// subscription is restored after connection losses; messages published
+// meanwhile are lost, as always with Redis pub/sub.
+func (c *Client) Subscribe(ctx context.Context, handler Handler, channels []string, opts ...SubscribeOptions) (*Subscription, error) {
+	return c.subscribe(ctx, handler, opts, func(ctx context.Context) *redis.PubSub {
+		return c.rdb.Subscribe(ctx, channels...)
+	})
+}
+
+// PSubscribe calls handler with the messages of channels matching patterns,
+// e.g. "orders.*", like Subscribe
+func (c *Client) PSubscribe(ctx context.Context, handler Handler, patterns []string, opts ...SubscribeOptions) (*Subscription, error) {
+	return c.subscribe(ctx, handler, opts, func(ctx context.Context) *redis.PubSub {
+		return c.rdb.PSubscribe(ctx, patterns...)
+	})
+}
+
+// subscribe starts a subscription created by open
+func (c *Client) subscribe(ctx context.Context, handler Handler, opts []SubscribeOptions, open func(context.Context) *redis.PubSub) (*Subscription, error) {
+	options := DefaultSubscribeOptions()
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = DefaultRetryDelay
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Subscription{
+		pubsub:  open(ctx),
+		handler: handler,
+		codec:   c.codec,
+		opts:    options,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	if _, err := s.pubsub.Receive(ctx); err != nil {
+		cancel()
+		s.pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+	go s.run(ctx)
+	return s, nil
+}
+
+// run receives messages until the subscription ends
+func (s *Subscription) run(ctx context.Context) {
+	defer close(s.done)
+	// The initial subscribe confirms each channel; only confirmations after
+	// a receive error, when go-redis has reconnected, are a resubscribe
+	reconnecting := false
+	for {
+		msg, err := s.pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, redis.ErrClosed) {
+				return
+			}
+			reconnecting = true
+			s.report(fmt.Errorf("pubsub receive: %w", err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.opts.RetryDelay):
+			}
+			continue
+		}
+
+		switch msg := msg.(type) {
+		case *redis.Subscription:
+			// go-redis subscribes again after reconnecting, confirming each
+			// channel; the first confirmation reports the reconnect
+			if !reconnecting || (msg.Kind != "subscribe" && msg.Kind != "psubscribe") {
+				continue
+			}
+			reconnecting = false
+			if s.opts.OnReconnect != nil {
+				s.opts.OnReconnect()
+			}
+		case *redis.Message:
+			m := &Message{Channel: msg.Channel, Pattern: msg.Pattern, Payload: []byte(msg.Payload), codec: s.codec}
+			if err := s.handler(ctx, m); err != nil {
+				s.report(fmt.Errorf("pubsub handler for %s: %w", msg.Channel, err))
+			}
+		}
+	}
+}
+
+// report passes err to the error handler
+func (s *Subscription) report(err error) {
+	if s.opts.OnError != nil {
+		s.opts.OnError(err)
+	}
+}
+
+// Close unsubscribes and waits for the message being handled
+func (s *Subscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		s.cancel()
+		err = s.pubsub.Close()
+		<-s.done
+	})
+	return err
+}
+
+// Done is closed once the subscription has stopped
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTypedHandler(t *testing.T) {
+	var got cachedUser
+	handler := TypedHandler(func(_ context.Context, u cachedUser) error {
+		got = u
+		if u.ID == 0 {
+			return errors.New("missing id")
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name    string
+		codec   Codec
+		payload func() []byte
+		wantErr bool
+	}{
+		{
+			name:  "json",
+			codec: JSONCodec,
+			payload: func() []byte {
+				data, _ := JSONCodec.Marshal(cachedUser{ID: 1, Name: "ada"})
+				return data
+			},
+		},
+		{
+			name:  "msgpack",
+			codec: MsgPackCodec,
+			payload: func() []byte {
+				data, _ := MsgPackCodec.Marshal(cachedUser{ID: 1, Name: "ada"})
+				return data
+			},
+		},
+		{name: "undecodable", codec: JSONCodec, payload: func() []byte { return []byte("hello") }, wantErr: true},
+		{name: "handler error", codec: JSONCodec, payload: func() []byte { return []byte(`{"name":"ada"}`) }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = cachedUser{}
+			msg := &Message{Channel: "users", Payload: tt.payload(), codec: tt.codec}
+			err := handler(context.Background(), msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got.ID != 1 || got.Name != "ada") {
+				t.Errorf("handler received %+v", got)
+			}
+		})
+	}
+}
+
+func TestSubscribeReconnect(t *testing.T) {
+	c, srv := newTestClient(t)
+	ctx := context.Background()
+
+	received := make(chan string, 16)
+	var reconnects atomic.Int32
+	sub, err := c.Subscribe(ctx, func(_ context.Context, msg *Message) error {
+		received <- msg.Channel
+		return nil
+	}, []string{"orders", "payments", "refunds"}, SubscribeOptions{
+		OnReconnect: func() { reconnects.Add(1) },
+		RetryDelay:  10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer sub.Close()
+
+	// A message on the last channel is read after every subscribe
+	// confirmation
+	if err := c.Publish(ctx, "refunds", "r1"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	waitMessage(t, received, "refunds")
+	if n := reconnects.Load(); n != 0 {
+		t.Fatalf("OnReconnect called %d times on the initial subscribe", n)
+	}
+
+	srv.Close()
+	if err := srv.Restart(); err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for reconnects.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("OnReconnect not called after the connection was lost")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.Publish(ctx, "refunds", "r2"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	waitMessage(t, received, "refunds")
+	if n := reconnects.Load(); n != 1 {
+		t.Errorf("OnReconnect called %d times for one reconnect", n)
+	}
+}
+
+// waitMessage waits for a message on channel
+func waitMessage(t *testing.T, received <-chan string, channel string) {
+	t.Helper()
+	select {
+	case got := <-received:
+		if got != channel {
+			t.Fatalf("received message on %s, want %s", got, channel)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no message received on %s", channel)
+	}
+}
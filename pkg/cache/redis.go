@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 )
 
 // Config holds Redis configuration
@@ -14,22 +15,38 @@ type Config struct {
 	DB           int    `json:"db" yaml:"db" env:"DB"`
 	PoolSize     int    `json:"pool_size" yaml:"pool_size" env:"POOL_SIZE"`
 	MinIdleConns int    `json:"min_idle_conns" yaml:"min_idle_conns" env:"MIN_IDLE_CONNS"`
+	// Tracing starts a span per command with the global tracer provider
+	Tracing bool `json:"tracing" yaml:"tracing" env:"TRACING"`
+	// CompressThreshold is the size from which SetObject and SetJSON
+	// gzip-compress values; 0 disables compression
+	CompressThreshold int `json:"compress_threshold" yaml:"compress_threshold" env:"COMPRESS_THRESHOLD"`
+	// Codec serializes SetObject and GetObject values, JSONCodec when nil
+	Codec Codec `json:"-" yaml:"-"`
+	// LockHook is called with lock acquisitions, contention, releases and
+	// losses, e.g. to record metrics or log unlock failures
+	LockHook LockHook `json:"-" yaml:"-"`
 }
 
 // DefaultConfig returns default Redis configuration
 func DefaultConfig() Config {
 	return Config{
-		Addr:         "localhost:6379",
-		Password:     "",
-		DB:           0,
-		PoolSize:     10,
-		MinIdleConns: 2,
+		Addr:              "localhost:6379",
+		Password:          "",
+		DB:                0,
+		PoolSize:          10,
+		MinIdleConns:      2,
+		CompressThreshold: DefaultCompressThreshold,
 	}
 }
 
 // Client wraps Redis client with additional functionality
 type Client struct {
-	rdb *redis.Client
+	rdb               *redis.Client
+	codec             Codec
+	compressThreshold int
+	lockHook          LockHook
+	// loads dedupes concurrent GetOrLoad calls, shared by WithCodec clones
+	loads *singleflight.Group
 }
 
 // New creates a new Redis client
@@ -41,8 +58,15 @@ func New(cfg Config) *Client {
 		PoolSize:     cfg.PoolSize,
 		MinIdleConns: cfg.MinIdleConns,
 	})
+	if cfg.Tracing {
+		rdb.AddHook(tracingHook{})
+	}
 
-	return &Client{rdb: rdb}
+	codec := cfg.Codec
+	if codec == nil {
+		codec = JSONCodec
+	}
+	return &Client{rdb: rdb, codec: codec, compressThreshold: cfg.CompressThreshold, lockHook: cfg.LockHook, loads: &singleflight.Group{}}
 }
 
 // Ping tests the connection
@@ -62,6 +86,12 @@ func (c *Client) Set(ctx context.Context, key string, value interface{}, ttl tim
 	return c.rdb.Set(ctx, key, value, ttl).Err()
 }
 
+// SetNX stores a key-value pair with optional TTL unless the key exists,
+// reporting whether it was stored
+func (c *Client) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, key, value, ttl).Result()
+}
+
 // Get retrieves a value by key
 func (c *Client) Get(ctx context.Context, key string) (string, error) {
 	return c.rdb.Get(ctx, key).Result()
@@ -164,6 +194,64 @@ func (c *Client) SRem(ctx context.Context, key string, members ...interface{}) e
 	return c.rdb.SRem(ctx, key, members...).Err()
 }
 
+// Sorted Set Operations
+
+// ZAdd adds members with their scores to a sorted set, updating the scores
+// of existing members
+func (c *Client) ZAdd(ctx context.Context, key string, members ...redis.Z) error {
+	return c.rdb.ZAdd(ctx, key, members...).Err()
+}
+
+// ZRangeByScore gets the members of a sorted set scored between min and max,
+// lowest first; bounds are scores, "-inf"/"+inf" or "(" prefixed to exclude
+// them, and count 0 returns every member from offset
+func (c *Client) ZRangeByScore(ctx context.Context, key, min, max string, offset, count int64) ([]redis.Z, error) {
+	if count == 0 {
+		count = -1
+	}
+	return c.rdb.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Min:    min,
+		Max:    max,
+		Offset: offset,
+		Count:  count,
+	}).Result()
+}
+
+// ZIncrBy increments the score of a sorted set member and returns the new score
+func (c *Client) ZIncrBy(ctx context.Context, key string, increment float64, member string) (float64, error) {
+	return c.rdb.ZIncrBy(ctx, key, increment, member).Result()
+}
+
+// ZRem removes members from a sorted set
+func (c *Client) ZRem(ctx context.Context, key string, members ...interface{}) error {
+	return c.rdb.ZRem(ctx, key, members...).Err()
+}
+
+// Geo Operations
+
+// GeoAdd adds named locations to a geo set
+func (c *Client) GeoAdd(ctx context.Context, key string, locations ...*redis.GeoLocation) error {
+	return c.rdb.GeoAdd(ctx, key, locations...).Err()
+}
+
+// GeoSearch gets up to count locations of a geo set within radius of the
+// given point, nearest first, with their distance in unit ("m", "km", "mi"
+// or "ft") and coordinates; count 0 returns every location
+func (c *Client) GeoSearch(ctx context.Context, key string, longitude, latitude, radius float64, unit string, count int) ([]redis.GeoLocation, error) {
+	return c.rdb.GeoSearchLocation(ctx, key, &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Longitude:  longitude,
+			Latitude:   latitude,
+			Radius:     radius,
+			RadiusUnit: unit,
+			Sort:       "ASC",
+			Count:      count,
+		},
+		WithCoord: true,
+		WithDist:  true,
+	}).Result()
+}
+
 // Advanced Operations
 
 // GetClient returns the underlying Redis client for advanced operations
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestClient returns a client of an in-memory Redis server, closed with
+// the test
+func newTestClient(t *testing.T) (*Client, *miniredis.Miniredis) {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	cfg := DefaultConfig()
+	cfg.Addr = srv.Addr()
+	c := New(cfg)
+	t.Cleanup(func() { c.Close() })
+	return c, srv
+}
+
+func TestZRangeByScore(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+	if err := c.ZAdd(ctx, "scores", redis.Z{Score: 1, Member: "a"}, redis.Z{Score: 2, Member: "b"},
+		redis.Z{Score: 3, Member: "c"}, redis.Z{Score: 4, Member: "d"}); err != nil {
+		t.Fatalf("ZAdd() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		min, max      string
+		offset, count int64
+		want          []string
+	}{
+		{name: "inclusive", min: "2", max: "3", want: []string{"b", "c"}},
+		{name: "exclusive min", min: "(2", max: "4", want: []string{"c", "d"}},
+		{name: "exclusive max", min: "1", max: "(3", want: []string{"a", "b"}},
+		{name: "infinite", min: "-inf", max: "+inf", want: []string{"a", "b", "c", "d"}},
+		{name: "count", min: "-inf", max: "+inf", count: 2, want: []string{"a", "b"}},
+		{name: "offset without count", min: "-inf", max: "+inf", offset: 3, want: []string{"d"}},
+		{name: "offset and count", min: "-inf", max: "+inf", offset: 1, count: 2, want: []string{"b", "c"}},
+		{name: "empty range", min: "(4", max: "+inf", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.ZRangeByScore(ctx, "scores", tt.min, tt.max, tt.offset, tt.count)
+			if err != nil {
+				t.Fatalf("ZRangeByScore() error = %v", err)
+			}
+			var members []string
+			for _, z := range got {
+				members = append(members, z.Member.(string))
+			}
+			if !reflect.DeepEqual(members, tt.want) {
+				t.Errorf("ZRangeByScore(%s, %s, %d, %d) = %v, want %v", tt.min, tt.max, tt.offset, tt.count, members, tt.want)
+			}
+		})
+	}
+}
+
+func TestZIncrBy(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		member    string
+		increment float64
+		want      float64
+	}{
+		{"new member", "a", 1.5, 1.5},
+		{"existing member", "a", 2, 3.5},
+		{"negative increment", "a", -4, -0.5},
+		{"other member", "b", 10, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.ZIncrBy(ctx, "scores", tt.increment, tt.member)
+			if err != nil {
+				t.Fatalf("ZIncrBy() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ZIncrBy(%s, %v) = %v, want %v", tt.member, tt.increment, got, tt.want)
+			}
+		})
+	}
+
+	if err := c.ZRem(ctx, "scores", "a"); err != nil {
+		t.Fatalf("ZRem() error = %v", err)
+	}
+	got, err := c.ZRangeByScore(ctx, "scores", "-inf", "+inf", 0, 0)
+	if err != nil || len(got) != 1 || got[0].Member != "b" {
+		t.Errorf("after ZRem members = %v, %v, want [b]", got, err)
+	}
+}
+
+func TestGeoSearch(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+	if err := c.GeoAdd(ctx, "cities",
+		&redis.GeoLocation{Name: "Palermo", Longitude: 13.361389, Latitude: 38.115556},
+		&redis.GeoLocation{Name: "Catania", Longitude: 15.087269, Latitude: 37.502669},
+	); err != nil {
+		t.Fatalf("GeoAdd() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		radius float64
+		unit   string
+		count  int
+		want   []string
+		dist   []float64
+	}{
+		{name: "nearest only", radius: 100, unit: "km", want: []string{"Catania"}, dist: []float64{56.4413}},
+		{name: "both nearest first", radius: 200, unit: "km", want: []string{"Catania", "Palermo"}, dist: []float64{56.4413, 190.4424}},
+		{name: "count", radius: 200, unit: "km", count: 1, want: []string{"Catania"}, dist: []float64{56.4413}},
+		{name: "meters", radius: 50000, unit: "m", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.GeoSearch(ctx, "cities", 15, 37, tt.radius, tt.unit, tt.count)
+			if err != nil {
+				t.Fatalf("GeoSearch() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("GeoSearch() = %+v, want %v", got, tt.want)
+			}
+			for i, loc := range got {
+				if loc.Name != tt.want[i] || math.Abs(loc.Dist-tt.dist[i]) > 0.01 {
+					t.Errorf("GeoSearch()[%d] = %s at %v, want %s at %v", i, loc.Name, loc.Dist, tt.want[i], tt.dist[i])
+				}
+				if loc.Longitude == 0 || loc.Latitude == 0 {
+					t.Errorf("GeoSearch()[%d] has no coordinates", i)
+				}
+			}
+		})
+	}
+}
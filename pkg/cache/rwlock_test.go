@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRWLock(t *testing.T) {
+	// gone is the wantTTL of a step after which the lock key is deleted
+	const gone = -1
+	type step struct {
+		op      string // rlock, wlock, unlock or extend
+		holder  string
+		ttl     time.Duration
+		wantErr error
+		wantTTL time.Duration
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "readers share, writers are excluded",
+			steps: []step{
+				{op: "rlock", holder: "r1", ttl: time.Second},
+				{op: "rlock", holder: "r2", ttl: time.Second},
+				{op: "wlock", holder: "w", ttl: time.Second, wantErr: ErrLockNotAcquired},
+			},
+		},
+		{
+			name: "writer blocks new readers",
+			steps: []step{
+				{op: "wlock", holder: "w1", ttl: time.Second},
+				{op: "rlock", holder: "r", ttl: time.Second, wantErr: ErrLockNotAcquired},
+				{op: "wlock", holder: "w2", ttl: time.Second, wantErr: ErrLockNotAcquired},
+				{op: "unlock", holder: "w1", wantTTL: gone},
+				{op: "rlock", holder: "r", ttl: time.Second},
+			},
+		},
+		{
+			name: "last reader deletes the key",
+			steps: []step{
+				{op: "rlock", holder: "r1", ttl: time.Second},
+				{op: "rlock", holder: "r2", ttl: 2 * time.Second},
+				{op: "unlock", holder: "r1", wantTTL: 2 * time.Second},
+				{op: "unlock", holder: "r2", wantTTL: gone},
+				{op: "wlock", holder: "w", ttl: time.Second},
+			},
+		},
+		{
+			name: "unlock with another token is refused",
+			steps: []step{
+				{op: "rlock", holder: "r1", ttl: time.Second},
+				{op: "unlock", holder: "forged", wantErr: ErrLockNotOwned, wantTTL: time.Second},
+				{op: "unlock", holder: "r1", wantTTL: gone},
+				{op: "unlock", holder: "r1", wantErr: ErrLockNotOwned},
+			},
+		},
+		{
+			name: "extend",
+			steps: []step{
+				{op: "rlock", holder: "r1", ttl: time.Second},
+				{op: "extend", holder: "r1", ttl: 10 * time.Second, wantTTL: 10 * time.Second},
+				{op: "rlock", holder: "r2", ttl: time.Second, wantTTL: 10 * time.Second},
+				// Readers never shorten the lock of the other readers
+				{op: "extend", holder: "r2", ttl: 2 * time.Second, wantTTL: 10 * time.Second},
+				{op: "extend", holder: "forged", ttl: time.Minute, wantErr: ErrLockNotOwned, wantTTL: 10 * time.Second},
+				{op: "unlock", holder: "r1"},
+				{op: "unlock", holder: "r2", wantTTL: gone},
+				// The writer's TTL is the lock's, shorter or not
+				{op: "wlock", holder: "w", ttl: 10 * time.Second},
+				{op: "extend", holder: "w", ttl: time.Second, wantTTL: time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, srv := newTestClient(t)
+			ctx := context.Background()
+			const key = "lock:orders"
+			locks := map[string]*RWLock{}
+			lockOf := func(holder string) *RWLock {
+				if lock, ok := locks[holder]; ok {
+					return lock
+				}
+				return &RWLock{client: c, key: key, value: "forged-token"}
+			}
+
+			for i, s := range tt.steps {
+				var err error
+				switch s.op {
+				case "rlock", "wlock":
+					try := c.TryRLock
+					if s.op == "wlock" {
+						try = c.TryWLock
+					}
+					var lock *RWLock
+					if lock, err = try(ctx, key, s.ttl); err == nil {
+						locks[s.holder] = lock
+					}
+				case "unlock":
+					err = lockOf(s.holder).Unlock(ctx)
+				case "extend":
+					err = lockOf(s.holder).Extend(ctx, s.ttl)
+				}
+				if !errors.Is(err, s.wantErr) {
+					t.Fatalf("step %d: %s %s error = %v, want %v", i, s.op, s.holder, err, s.wantErr)
+				}
+
+				switch s.wantTTL {
+				case 0:
+				case gone:
+					if srv.Exists(key) {
+						t.Fatalf("step %d: %s still exists", i, key)
+					}
+				default:
+					if ttl := srv.TTL(key); ttl != s.wantTTL {
+						t.Fatalf("step %d: TTL = %v, want %v", i, ttl, s.wantTTL)
+					}
+				}
+			}
+		})
+	}
+}
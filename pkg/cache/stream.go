@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Stream Operations
+
+// XAdd appends an entry to a stream and returns its ID; maxLen
+// approximately caps the stream length, 0 keeps every entry
+func (c *Client) XAdd(ctx context.Context, stream string, values map[string]interface{}, maxLen int64) (string, error) {
+	return c.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: maxLen > 0,
+		Values: values,
+	}).Result()
+}
+
+// XGroupCreate creates a consumer group reading stream from start, "0" for
+// the oldest entry or "$" for new ones only, creating the stream if needed;
+// an existing group is not an error
+func (c *Client) XGroupCreate(ctx context.Context, stream, group, start string) error {
+	err := c.rdb.XGroupCreateMkStream(ctx, stream, group, start).Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// XReadGroup reads up to count entries never delivered to the group from
+// streams, blocking up to block when none is available; no entry returns
+// redis.Nil
+func (c *Client) XReadGroup(ctx context.Context, group, consumer string, streams []string, count int64, block time.Duration) ([]redis.XStream, error) {
+	args := make([]string, 0, 2*len(streams))
+	args = append(args, streams...)
+	for range streams {
+		args = append(args, ">")
+	}
+	return c.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  args,
+		Count:    count,
+		Block:    block,
+	}).Result()
+}
+
+// XAck acknowledges entries processed by a group
+func (c *Client) XAck(ctx context.Context, stream, group string, ids ...string) error {
+	return c.rdb.XAck(ctx, stream, group, ids...).Err()
+}
+
+// XPending lists up to count entries of a group left unacknowledged for at
+// least minIdle, with their delivery counts
+func (c *Client) XPending(ctx context.Context, stream, group string, minIdle time.Duration, count int64) ([]redis.XPendingExt, error) {
+	return c.rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  group,
+		Idle:   minIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  count,
+	}).Result()
+}
+
+// XClaim takes over pending entries idle for at least minIdle, e.g. those
+// of a consumer that died, and returns them
+func (c *Client) XClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, ids ...string) ([]redis.XMessage, error) {
+	return c.rdb.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Messages: ids,
+	}).Result()
+}
+
+// XLen returns the number of entries of a stream
+func (c *Client) XLen(ctx context.Context, stream string) (int64, error) {
+	return c.rdb.XLen(ctx, stream).Result()
+}
@@ -0,0 +1,318 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mora/pkg/retry"
+)
+
+// DeadLetterSuffix names a stream's dead-letter stream by default, as in
+// orders:dlq
+const DeadLetterSuffix = ":dlq"
+
+// Fields added to entries moved to a dead-letter stream
+const (
+	DeadLetterFieldStream   = "dlq:stream"
+	DeadLetterFieldID       = "dlq:id"
+	DeadLetterFieldAttempts = "dlq:attempts"
+	DeadLetterFieldError    = "dlq:error"
+)
+
+// StreamConsumerConfig configures a StreamConsumer
+type StreamConsumerConfig struct {
+	Stream string `json:"stream" yaml:"stream" env:"STREAM"`
+	Group  string `json:"group" yaml:"group" env:"GROUP"`
+	// Consumer names this instance within the group, defaults to host-pid
+	Consumer string `json:"consumer" yaml:"consumer" env:"CONSUMER"`
+	// StartID is where a new group starts reading: 0 (oldest) or $ (new only)
+	StartID string `json:"start_id" yaml:"start_id" env:"START_ID"`
+	// Workers is the number of entries handled concurrently
+	Workers   int           `json:"workers" yaml:"workers" env:"WORKERS"`
+	BatchSize int64         `json:"batch_size" yaml:"batch_size" env:"BATCH_SIZE"`
+	Block     time.Duration `json:"block" yaml:"block" env:"BLOCK"`
+
+	// Retry retries a failed handler in process before the entry is left
+	// pending; a zero MaxAttempts calls the handler once
+	Retry retry.Config `json:"retry" yaml:"retry"`
+	// Entries left pending longer than MinIdle, because their handler failed
+	// or a consumer died, are reclaimed every ClaimInterval and handled again
+	ClaimInterval time.Duration `json:"claim_interval" yaml:"claim_interval" env:"CLAIM_INTERVAL"`
+	MinIdle       time.Duration `json:"min_idle" yaml:"min_idle" env:"MIN_IDLE"`
+	// MaxDeliveries moves an entry to DeadLetterStream, with its last error,
+	// once its handler has failed on this many deliveries; 0 retries forever
+	MaxDeliveries int64 `json:"max_deliveries" yaml:"max_deliveries" env:"MAX_DELIVERIES"`
+	// DeadLetterStream defaults to Stream + DeadLetterSuffix
+	DeadLetterStream string `json:"dead_letter_stream" yaml:"dead_letter_stream" env:"DEAD_LETTER_STREAM"`
+
+	// OnError is called with handler and Redis errors; the consumer goes on
+	OnError func(error) `json:"-" yaml:"-"`
+}
+
+// DefaultStreamConsumerConfig returns default stream consumer configuration
+func DefaultStreamConsumerConfig() StreamConsumerConfig {
+	return StreamConsumerConfig{
+		StartID:       "0",
+		Workers:       1,
+		BatchSize:     10,
+		Block:         2 * time.Second,
+		ClaimInterval: 30 * time.Second,
+		MinIdle:       time.Minute,
+		MaxDeliveries: 5,
+	}
+}
+
+// StreamHandler processes one stream entry; an error leaves the entry
+// pending so that it is delivered again
+type StreamHandler func(ctx context.Context, msg redis.XMessage) error
+
+// delivery is an entry handed to a worker with its delivery count
+type delivery struct {
+	msg   redis.XMessage
+	count int64
+}
+
+// StreamConsumer runs a worker loop reading a stream as a consumer group.
+// Entries are acknowledged once their handler succeeds; failed ones are
+// reclaimed after MinIdle and dead-lettered after MaxDeliveries.
+// mq.RedisConsumer adds headers and middleware on top of it.
+type StreamConsumer struct {
+	client  *Client
+	cfg     StreamConsumerConfig
+	handler StreamHandler
+
+	mu      sync.Mutex
+	stop    context.CancelFunc
+	done    chan struct{}
+	stopped bool
+}
+
+// NewStreamConsumer creates a consumer of cfg.Stream calling handler
+func NewStreamConsumer(client *Client, cfg StreamConsumerConfig, handler StreamHandler) (*StreamConsumer, error) {
+	if cfg.Stream == "" || cfg.Group == "" {
+		return nil, errors.New("stream consumer: stream and group are required")
+	}
+	if handler == nil {
+		return nil, errors.New("stream consumer: handler is required")
+	}
+	defaults := DefaultStreamConsumerConfig()
+	if cfg.Consumer == "" {
+		host, _ := os.Hostname()
+		cfg.Consumer = host + "-" + strconv.Itoa(os.Getpid())
+	}
+	if cfg.StartID == "" {
+		cfg.StartID = defaults.StartID
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaults.Workers
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaults.BatchSize
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = defaults.Block
+	}
+	if cfg.DeadLetterStream == "" {
+		cfg.DeadLetterStream = cfg.Stream + DeadLetterSuffix
+	}
+	return &StreamConsumer{client: client, cfg: cfg, handler: handler}, nil
+}
+
+// Run consumes the stream until ctx is done or Close is called, then waits
+// for the entries being handled and returns nil. Handlers keep running
+// after ctx is done so that they can finish their entry.
+func (s *StreamConsumer) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopped || s.done != nil {
+		s.mu.Unlock()
+		return errors.New("stream consumer: already running or closed")
+	}
+	fetchCtx, stop := context.WithCancel(ctx)
+	s.stop, s.done = stop, make(chan struct{})
+	s.mu.Unlock()
+	defer close(s.done)
+	defer stop()
+
+	if err := s.client.XGroupCreate(ctx, s.cfg.Stream, s.cfg.Group, s.cfg.StartID); err != nil {
+		return fmt.Errorf("stream consumer: failed to create group: %w", err)
+	}
+
+	handleCtx := context.WithoutCancel(ctx)
+	jobs := make(chan delivery)
+	var wg sync.WaitGroup
+	for i := 0; i < s.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range jobs {
+				s.process(handleCtx, d)
+			}
+		}()
+	}
+
+	s.fetch(fetchCtx, jobs)
+	close(jobs)
+	wg.Wait()
+	return nil
+}
+
+// fetch reads entries and hands them to the workers until ctx is done; an
+// entry read but not handed over stays pending and is reclaimed later
+func (s *StreamConsumer) fetch(ctx context.Context, jobs chan<- delivery) {
+	var lastClaim time.Time
+	for ctx.Err() == nil {
+		if s.cfg.ClaimInterval > 0 && time.Since(lastClaim) >= s.cfg.ClaimInterval {
+			lastClaim = time.Now()
+			if !dispatch(ctx, jobs, s.reclaim(ctx)) {
+				return
+			}
+		}
+
+		res, err := s.client.XReadGroup(ctx, s.cfg.Group, s.cfg.Consumer, []string{s.cfg.Stream}, s.cfg.BatchSize, s.cfg.Block)
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			s.report(fmt.Errorf("stream consumer: failed to read %s: %w", s.cfg.Stream, err))
+			select {
+			case <-ctx.Done():
+			case <-time.After(DefaultRetryDelay):
+			}
+			continue
+		}
+		for _, stream := range res {
+			entries := make([]delivery, 0, len(stream.Messages))
+			for _, msg := range stream.Messages {
+				entries = append(entries, delivery{msg: msg, count: 1})
+			}
+			if !dispatch(ctx, jobs, entries) {
+				return
+			}
+		}
+	}
+}
+
+// dispatch hands entries to the workers; false means ctx is done
+func dispatch(ctx context.Context, jobs chan<- delivery, entries []delivery) bool {
+	for _, entry := range entries {
+		select {
+		case jobs <- entry:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// process handles one entry and acknowledges it on success. A failed entry
+// is dead-lettered on its last delivery and otherwise left pending, so that
+// reclaim delivers it again once it has been idle.
+func (s *StreamConsumer) process(ctx context.Context, d delivery) {
+	msg := d.msg
+	var err error
+	if s.cfg.Retry.MaxAttempts > 1 {
+		err = retry.Do(ctx, s.cfg.Retry, func(ctx context.Context) error { return s.handler(ctx, msg) })
+	} else {
+		err = s.handler(ctx, msg)
+	}
+	if err != nil {
+		s.report(fmt.Errorf("stream consumer: handler failed for %s: %w", msg.ID, err))
+		if s.cfg.MaxDeliveries > 0 && d.count >= s.cfg.MaxDeliveries {
+			if err := s.deadLetter(ctx, msg, d.count, err); err != nil {
+				s.report(err)
+			}
+		}
+		return
+	}
+	if err := s.client.XAck(ctx, s.cfg.Stream, s.cfg.Group, msg.ID); err != nil {
+		s.report(fmt.Errorf("stream consumer: failed to ack %s: %w", msg.ID, err))
+	}
+}
+
+// reclaim takes over entries left pending longer than MinIdle and returns
+// those to handle again, dead-lettering the ones already delivered
+// MaxDeliveries times, e.g. to consumers that died handling them
+func (s *StreamConsumer) reclaim(ctx context.Context) []delivery {
+	pending, err := s.client.XPending(ctx, s.cfg.Stream, s.cfg.Group, s.cfg.MinIdle, s.cfg.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.report(fmt.Errorf("stream consumer: failed to list pending of %s: %w", s.cfg.Stream, err))
+		}
+		return nil
+	}
+
+	var retries []delivery
+	for _, p := range pending {
+		entries, err := s.client.XClaim(ctx, s.cfg.Stream, s.cfg.Group, s.cfg.Consumer, s.cfg.MinIdle, p.ID)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.report(fmt.Errorf("stream consumer: failed to claim %s: %w", p.ID, err))
+			}
+			continue
+		}
+		for _, entry := range entries {
+			// RetryCount counts deliveries before this claim
+			if s.cfg.MaxDeliveries > 0 && p.RetryCount >= s.cfg.MaxDeliveries {
+				cause := fmt.Errorf("not acknowledged after %d deliveries", p.RetryCount)
+				if err := s.deadLetter(ctx, entry, p.RetryCount, cause); err != nil {
+					s.report(err)
+				}
+				continue
+			}
+			retries = append(retries, delivery{msg: entry, count: p.RetryCount + 1})
+		}
+	}
+	return retries
+}
+
+// deadLetter moves an exhausted entry to the dead-letter stream with the
+// reason it failed
+func (s *StreamConsumer) deadLetter(ctx context.Context, entry redis.XMessage, deliveries int64, cause error) error {
+	values := make(map[string]interface{}, len(entry.Values)+4)
+	for k, v := range entry.Values {
+		values[k] = v
+	}
+	values[DeadLetterFieldStream] = s.cfg.Stream
+	values[DeadLetterFieldID] = entry.ID
+	values[DeadLetterFieldAttempts] = strconv.FormatInt(deliveries, 10)
+	values[DeadLetterFieldError] = cause.Error()
+
+	_, err := s.client.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: s.cfg.DeadLetterStream, Values: values})
+		pipe.XAck(ctx, s.cfg.Stream, s.cfg.Group, entry.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("stream consumer: failed to dead-letter %s: %w", entry.ID, err)
+	}
+	return nil
+}
+
+// report passes err to the error handler
+func (s *StreamConsumer) report(err error) {
+	if s.cfg.OnError != nil {
+		s.cfg.OnError(err)
+	}
+}
+
+// Close stops reading and waits for Run to return once the entries being
+// handled are done
+func (s *StreamConsumer) Close() error {
+	s.mu.Lock()
+	s.stopped = true
+	stop, done := s.stop, s.done
+	s.mu.Unlock()
+
+	if stop != nil {
+		stop()
+		<-done
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestNewStreamConsumer(t *testing.T) {
+	client := New(DefaultConfig())
+	defer client.Close()
+	handler := func(context.Context, redis.XMessage) error { return nil }
+
+	tests := []struct {
+		name    string
+		cfg     StreamConsumerConfig
+		handler StreamHandler
+		wantErr bool
+	}{
+		{name: "missing stream", cfg: StreamConsumerConfig{Group: "billing"}, handler: handler, wantErr: true},
+		{name: "missing handler", cfg: StreamConsumerConfig{Stream: "orders", Group: "billing"}, wantErr: true},
+		{name: "defaults", cfg: StreamConsumerConfig{Stream: "orders", Group: "billing"}, handler: handler},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewStreamConsumer(client, tt.cfg, tt.handler)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewStreamConsumer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if c.cfg.Consumer == "" || c.cfg.Workers != 1 || c.cfg.DeadLetterStream != "orders:dlq" {
+				t.Errorf("cfg = %+v", c.cfg)
+			}
+		})
+	}
+}
+
+func TestStreamConsumerRunWithoutRedis(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Addr = "127.0.0.1:1"
+	client := New(cfg)
+	defer client.Close()
+
+	c, err := NewStreamConsumer(client, StreamConsumerConfig{Stream: "orders", Group: "billing"}, func(context.Context, redis.XMessage) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "create group") {
+		t.Errorf("Run() error = %v, want a group creation failure", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := c.Run(context.Background()); err == nil {
+		t.Error("Run() after Close should fail")
+	}
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultInvalidationChannel is the Redis channel of TieredCache invalidations
+const DefaultInvalidationChannel = "mora:cache:invalidate"
+
+// TieredConfig configures a TieredCache
+type TieredConfig struct {
+	// Size is the maximum number of entries kept in memory
+	Size int `json:"size" yaml:"size" env:"SIZE"`
+	// LocalTTL bounds how long a value is served from memory, and so how
+	// stale it can get should an invalidation be lost
+	LocalTTL time.Duration `json:"local_ttl" yaml:"local_ttl" env:"LOCAL_TTL"`
+	// Channel is the pub/sub channel shared by every instance
+	Channel string `json:"channel" yaml:"channel" env:"CHANNEL"`
+}
+
+// DefaultTieredConfig returns default tiered cache configuration
+func DefaultTieredConfig() TieredConfig {
+	return TieredConfig{
+		Size:     10000,
+		LocalTTL: time.Minute,
+		Channel:  DefaultInvalidationChannel,
+	}
+}
+
+// TieredCache keeps recently used values in memory in front of Redis.
+// Writes through the cache are broadcast over Redis pub/sub, so every
+// instance evicts its local copy when another one updates or deletes a key.
+type TieredCache struct {
+	client *Client
+	cfg    TieredConfig
+	local  *lru
+	origin string
+	sub    *Subscription
+}
+
+// invalidation is the message broadcast when keys change
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+// NewTieredCache creates a tiered cache on client and subscribes to
+// invalidations; Close stops the subscription
+func NewTieredCache(client *Client, cfg TieredConfig) (*TieredCache, error) {
+	defaults := DefaultTieredConfig()
+	if cfg.Size <= 0 {
+		cfg.Size = defaults.Size
+	}
+	if cfg.LocalTTL <= 0 {
+		cfg.LocalTTL = defaults.LocalTTL
+	}
+	if cfg.Channel == "" {
+		cfg.Channel = defaults.Channel
+	}
+
+	t := &TieredCache{
+		client: client,
+		cfg:    cfg,
+		local:  newLRU(cfg.Size),
+		origin: generateLockValue(),
+	}
+
+	// Invalidations sent while the subscription is down are lost, so the
+	// local cache is emptied on connection errors and reconnects
+	sub, err := client.Subscribe(context.Background(), func(_ context.Context, msg *Message) error {
+		t.handle(msg.Payload)
+		return nil
+	}, []string{cfg.Channel}, SubscribeOptions{
+		OnError:     func(error) { t.local.purge() },
+		OnReconnect: t.local.purge,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", cfg.Channel, err)
+	}
+	t.sub = sub
+	return t, nil
+}
+
+// Get retrieves a value stored by Set into v, a pointer, from memory or
+// else from Redis; a missing key returns redis.Nil
+func (t *TieredCache) Get(ctx context.Context, key string, v interface{}) error {
+	if data, ok := t.local.get(key); ok {
+		return decodeValue(t.client.codec, data, v)
+	}
+	data, err := t.client.rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		return err
+	}
+	if err := decodeValue(t.client.codec, data, v); err != nil {
+		return err
+	}
+	t.local.set(key, data, t.cfg.LocalTTL)
+	return nil
+}
+
+// Set stores v in Redis with optional TTL and in memory, then tells the
+// other instances to drop their copy
+func (t *TieredCache) Set(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+	data, err := encodeValue(t.client.codec, v, t.client.compressThreshold)
+	if err != nil {
+		return err
+	}
+	if err := t.client.rdb.Set(ctx, key, data, ttl).Err(); err != nil {
+		return err
+	}
+	localTTL := t.cfg.LocalTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	t.local.set(key, data, localTTL)
+	return t.publish(ctx, key)
+}
+
+// Delete removes keys from Redis and from the memory of every instance
+func (t *TieredCache) Delete(ctx context.Context, keys ...string) error {
+	t.local.remove(keys...)
+	if err := t.client.rdb.Del(ctx, keys...).Err(); err != nil {
+		return err
+	}
+	return t.publish(ctx, keys...)
+}
+
+// Invalidate drops keys from the memory of every instance, leaving Redis
+// alone, e.g. after keys were written with the Client directly
+func (t *TieredCache) Invalidate(ctx context.Context, keys ...string) error {
+	t.local.remove(keys...)
+	return t.publish(ctx, keys...)
+}
+
+// Close stops listening for invalidations and empties the local cache
+func (t *TieredCache) Close() error {
+	err := t.sub.Close()
+	t.local.purge()
+	return err
+}
+
+// publish broadcasts the invalidation of keys
+func (t *TieredCache) publish(ctx context.Context, keys ...string) error {
+	msg, err := json.Marshal(invalidation{Origin: t.origin, Keys: keys})
+	if err != nil {
+		return err
+	}
+	return t.client.Publish(ctx, t.cfg.Channel, msg)
+}
+
+// handle evicts the keys of an invalidation sent by another instance
+func (t *TieredCache) handle(payload []byte) {
+	var msg invalidation
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.Origin == t.origin {
+		return
+	}
+	t.local.remove(msg.Keys...)
+}
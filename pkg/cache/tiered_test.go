@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTieredCacheHandle(t *testing.T) {
+	tc := &TieredCache{local: newLRU(10), origin: "self"}
+
+	tests := []struct {
+		name    string
+		payload string
+		want    bool // whether user:1 stays cached
+	}{
+		{name: "own message", payload: `{"origin":"self","keys":["user:1"]}`, want: true},
+		{name: "invalid message", payload: `not json`, want: true},
+		{name: "other key", payload: `{"origin":"peer","keys":["user:2"]}`, want: true},
+		{name: "peer update", payload: `{"origin":"peer","keys":["user:2","user:1"]}`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc.local.set("user:1", []byte(`{"id":1}`), time.Minute)
+			tc.handle([]byte(tt.payload))
+			if _, ok := tc.local.get("user:1"); ok != tt.want {
+				t.Errorf("cached = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+
+	data, _ := json.Marshal(invalidation{Origin: "self", Keys: []string{"a"}})
+	if string(data) != `{"origin":"self","keys":["a"]}` {
+		t.Errorf("invalidation = %s", data)
+	}
+}
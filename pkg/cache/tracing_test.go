@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingHook(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+	otel.SetTracerProvider(tp)
+
+	ctx := context.Background()
+	tests := []struct {
+		name      string
+		run       func() error
+		wantName  string
+		wantError bool
+	}{
+		{
+			name: "command",
+			run: func() error {
+				return tracingHook{}.ProcessHook(func(context.Context, redis.Cmder) error { return nil })(ctx, redis.NewStringCmd(ctx, "get", "k"))
+			},
+			wantName: "redis.get",
+		},
+		{
+			name: "missing key",
+			run: func() error {
+				return tracingHook{}.ProcessHook(func(context.Context, redis.Cmder) error { return redis.Nil })(ctx, redis.NewStringCmd(ctx, "get", "k"))
+			},
+			wantName: "redis.get",
+		},
+		{
+			name: "failed pipeline",
+			run: func() error {
+				return tracingHook{}.ProcessPipelineHook(func(context.Context, []redis.Cmder) error { return errors.New("conn reset") })(ctx,
+					[]redis.Cmder{redis.NewStatusCmd(ctx, "set", "k", "v"), redis.NewIntCmd(ctx, "incr", "n")})
+			},
+			wantName:  "redis.pipeline",
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			tt.run()
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("exported %d spans, want 1", len(spans))
+			}
+			if spans[0].Name != tt.wantName {
+				t.Errorf("span name = %q, want %q", spans[0].Name, tt.wantName)
+			}
+			if (spans[0].Status.Code == codes.Error) != tt.wantError {
+				t.Errorf("status = %+v, want error %v", spans[0].Status, tt.wantError)
+			}
+		})
+	}
+}
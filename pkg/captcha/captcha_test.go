@@ -0,0 +1,126 @@
+package captcha
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCaptcha(t *testing.T) {
+	store := NewMemoryStore()
+	c := New(store, DefaultConfig())
+	ctx := context.Background()
+
+	ch, err := c.Generate(ctx)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(ch.Image))
+	if err != nil {
+		t.Fatalf("image is not a PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 160 || b.Dy() != 60 {
+		t.Errorf("image size = %v", b)
+	}
+	if !strings.HasPrefix(ch.DataURL(), "data:image/png;base64,") {
+		t.Errorf("DataURL() = %.40s", ch.DataURL())
+	}
+
+	answer, _ := store.Get(ctx, "captcha:image:"+ch.ID)
+	if len(answer) != 4 {
+		t.Fatalf("answer = %q, want 4 digits", answer)
+	}
+	if err := c.Verify(ctx, ch.ID, " "+answer+" "); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := c.Verify(ctx, ch.ID, answer); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify() reused error = %v, want ErrInvalid", err)
+	}
+
+	ch, _ = c.Generate(ctx)
+	if err := c.Verify(ctx, ch.ID, "wrong"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify() wrong answer error = %v, want ErrInvalid", err)
+	}
+	answer, _ = store.Get(ctx, "captcha:image:"+ch.ID)
+	if answer != "" {
+		t.Error("a wrong answer should consume the challenge")
+	}
+}
+
+func TestGuard(t *testing.T) {
+	store := NewMemoryStore()
+	c := New(store, DefaultConfig())
+	g := NewGuard(store, c, GuardConfig{Threshold: 2})
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		fail    bool
+		wantErr error
+	}{
+		{"no failures", false, nil},
+		{"one failure", true, nil},
+		{"threshold reached", true, ErrRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.fail {
+				g.Fail(ctx, "1.2.3.4")
+			}
+			if err := g.Check(ctx, "1.2.3.4", "", ""); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	ch, _ := c.Generate(ctx)
+	answer, _ := store.Get(ctx, "captcha:image:"+ch.ID)
+	if err := g.Check(ctx, "1.2.3.4", ch.ID, "x"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Check() wrong answer error = %v, want ErrInvalid", err)
+	}
+	ch, _ = c.Generate(ctx)
+	answer, _ = store.Get(ctx, "captcha:image:"+ch.ID)
+	if err := g.Check(ctx, "1.2.3.4", ch.ID, answer); err != nil {
+		t.Errorf("Check() right answer error = %v", err)
+	}
+	if required, _ := g.Required(ctx, "5.6.7.8"); required {
+		t.Error("failures should be counted per key")
+	}
+	g.Reset(ctx, "1.2.3.4")
+	if required, _ := g.Required(ctx, "1.2.3.4"); required {
+		t.Error("Reset() should clear failures")
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	s.Set(ctx, "k", "v", time.Minute)
+	if ok, _ := s.SetNX(ctx, "k", "w", time.Minute); ok {
+		t.Error("SetNX() should not overwrite a live key")
+	}
+	if n, _ := s.Incr(ctx, "n", time.Minute); n != 1 {
+		t.Errorf("Incr() = %d, want 1", n)
+	}
+	if n, _ := s.Incr(ctx, "n", time.Minute); n != 2 {
+		t.Errorf("Incr() = %d, want 2", n)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := s.Get(ctx, "k"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after expiry error = %v, want ErrNotFound", err)
+	}
+	if n, _ := s.Incr(ctx, "n", time.Minute); n != 1 {
+		t.Errorf("Incr() after expiry = %d, want 1", n)
+	}
+	if ok, _ := s.SetNX(ctx, "k", "w", time.Minute); !ok {
+		t.Error("SetNX() should set an expired key")
+	}
+}
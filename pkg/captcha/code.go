@@ -0,0 +1,170 @@
+package captcha
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"mora/pkg/mailer"
+	"mora/pkg/sms"
+)
+
+// Deliverer sends a one-time code to a target such as an email address or
+// phone number
+type Deliverer interface {
+	Deliver(ctx context.Context, purpose, target, code string) error
+}
+
+// DelivererFunc adapts a function to Deliverer
+type DelivererFunc func(ctx context.Context, purpose, target, code string) error
+
+// Deliver calls f
+func (f DelivererFunc) Deliver(ctx context.Context, purpose, target, code string) error {
+	return f(ctx, purpose, target, code)
+}
+
+// MailDeliverer emails codes; build returns the message for a purpose, its
+// To is set to the target
+func MailDeliverer(sender mailer.Sender, build func(purpose, code string) (*mailer.Message, error)) Deliverer {
+	return DelivererFunc(func(ctx context.Context, purpose, target, code string) error {
+		msg, err := build(purpose, code)
+		if err != nil {
+			return err
+		}
+		msg.To = []string{target}
+		return sender.Send(ctx, msg)
+	})
+}
+
+// SMSDeliverer texts codes using the template configured for each purpose;
+// the code is passed as the template parameter named param
+func SMSDeliverer(sender sms.Sender, templates map[string]string, param string) Deliverer {
+	return DelivererFunc(func(ctx context.Context, purpose, target, code string) error {
+		template, ok := templates[purpose]
+		if !ok {
+			return fmt.Errorf("no sms template for purpose %q", purpose)
+		}
+		return sender.Send(ctx, &sms.Message{Phone: target, Template: template, Params: map[string]string{param: code}})
+	})
+}
+
+// CodeConfig configures one-time codes
+type CodeConfig struct {
+	Length int           `json:"length" yaml:"length" env:"LENGTH"`
+	TTL    time.Duration `json:"ttl" yaml:"ttl" env:"TTL"`
+	// Interval is the minimum time between two codes to a target; negative
+	// disables the limit
+	Interval time.Duration `json:"interval" yaml:"interval" env:"INTERVAL"`
+	// MaxAttempts wrong answers invalidate the code
+	MaxAttempts int    `json:"max_attempts" yaml:"max_attempts" env:"MAX_ATTEMPTS"`
+	Prefix      string `json:"prefix" yaml:"prefix" env:"PREFIX"`
+}
+
+// DefaultCodeConfig returns default code configuration
+func DefaultCodeConfig() CodeConfig {
+	return CodeConfig{
+		Length:      6,
+		TTL:         10 * time.Minute,
+		Interval:    time.Minute,
+		MaxAttempts: 5,
+		Prefix:      "captcha:code:",
+	}
+}
+
+// Codes sends and verifies one-time codes. A purpose such as "login" or
+// "reset" scopes each code so it cannot be used for another flow.
+type Codes struct {
+	store     Store
+	deliverer Deliverer
+	cfg       CodeConfig
+}
+
+// NewCodes creates a code sender
+func NewCodes(store Store, deliverer Deliverer, cfg CodeConfig) *Codes {
+	defaults := DefaultCodeConfig()
+	if cfg.Length <= 0 {
+		cfg.Length = defaults.Length
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaults.TTL
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = defaults.Interval
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaults.MaxAttempts
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaults.Prefix
+	}
+	return &Codes{store: store, deliverer: deliverer, cfg: cfg}
+}
+
+// keys returns the code, attempt and resend keys of a target
+func (c *Codes) keys(purpose, target string) (code, attempts, resend string) {
+	base := c.cfg.Prefix + purpose + ":" + target
+	return base, base + ":attempts", base + ":resend"
+}
+
+// Send generates a code and delivers it, replacing any previous one. It
+// returns ErrTooSoon within Interval of the last send.
+func (c *Codes) Send(ctx context.Context, purpose, target string) error {
+	codeKey, attemptsKey, resendKey := c.keys(purpose, target)
+	if c.cfg.Interval > 0 {
+		ok, err := c.store.SetNX(ctx, resendKey, "1", c.cfg.Interval)
+		if err != nil {
+			return fmt.Errorf("failed to check resend interval: %w", err)
+		}
+		if !ok {
+			return ErrTooSoon
+		}
+	}
+
+	code, err := randomDigits(c.cfg.Length)
+	if err != nil {
+		return err
+	}
+	if err := c.store.Set(ctx, codeKey, code, c.cfg.TTL); err != nil {
+		return fmt.Errorf("failed to store code: %w", err)
+	}
+	if err := c.store.Delete(ctx, attemptsKey); err != nil {
+		return fmt.Errorf("failed to reset attempts: %w", err)
+	}
+	if err := c.deliverer.Deliver(ctx, purpose, target, code); err != nil {
+		// Let the user retry right away when delivery failed
+		c.store.Delete(ctx, codeKey, resendKey)
+		return fmt.Errorf("failed to deliver code: %w", err)
+	}
+	return nil
+}
+
+// Verify checks a code and consumes it on success. After MaxAttempts wrong
+// codes it returns ErrTooManyAttempts and the code must be sent again.
+func (c *Codes) Verify(ctx context.Context, purpose, target, code string) error {
+	codeKey, attemptsKey, _ := c.keys(purpose, target)
+	want, err := c.store.Get(ctx, codeKey)
+	if stderrors.Is(err, ErrNotFound) {
+		return ErrInvalidCode
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load code: %w", err)
+	}
+
+	if code != "" && equal(want, code) {
+		if err := c.store.Delete(ctx, codeKey, attemptsKey); err != nil {
+			return fmt.Errorf("failed to consume code: %w", err)
+		}
+		return nil
+	}
+
+	n, err := c.store.Incr(ctx, attemptsKey, c.cfg.TTL)
+	if err != nil {
+		return fmt.Errorf("failed to count attempts: %w", err)
+	}
+	if n >= int64(c.cfg.MaxAttempts) {
+		c.store.Delete(ctx, codeKey, attemptsKey)
+		return ErrTooManyAttempts
+	}
+	return ErrInvalidCode
+}
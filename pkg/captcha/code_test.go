@@ -0,0 +1,96 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mora/pkg/mailer"
+	"mora/pkg/sms"
+)
+
+func TestCodes(t *testing.T) {
+	store := NewMemoryStore()
+	var sent []string
+	deliverer := DelivererFunc(func(ctx context.Context, purpose, target, code string) error {
+		sent = append(sent, code)
+		return nil
+	})
+	codes := NewCodes(store, deliverer, CodeConfig{MaxAttempts: 3})
+	ctx := context.Background()
+
+	if err := codes.Send(ctx, "login", "+8613800000000"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := codes.Send(ctx, "login", "+8613800000000"); !errors.Is(err, ErrTooSoon) {
+		t.Errorf("Send() again error = %v, want ErrTooSoon", err)
+	}
+	if err := codes.Send(ctx, "reset", "+8613800000000"); err != nil {
+		t.Errorf("Send() for another purpose error = %v", err)
+	}
+	if len(sent) != 2 || len(sent[0]) != 6 {
+		t.Fatalf("sent = %v", sent)
+	}
+
+	if err := codes.Verify(ctx, "reset", "+8613800000000", sent[0]); err == nil && sent[0] != sent[1] {
+		t.Error("codes should be scoped to their purpose")
+	}
+	if err := codes.Verify(ctx, "login", "+8613800000000", sent[0]); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := codes.Verify(ctx, "login", "+8613800000000", sent[0]); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Verify() reused error = %v, want ErrInvalidCode", err)
+	}
+}
+
+func TestCodesAttempts(t *testing.T) {
+	store := NewMemoryStore()
+	codes := NewCodes(store, DelivererFunc(func(context.Context, string, string, string) error { return nil }),
+		CodeConfig{MaxAttempts: 3, Interval: -1})
+	ctx := context.Background()
+	codes.Send(ctx, "login", "a@example.com")
+
+	tests := []error{ErrInvalidCode, ErrInvalidCode, ErrTooManyAttempts, ErrInvalidCode}
+	for i, want := range tests {
+		if err := codes.Verify(ctx, "login", "a@example.com", "bad"); !errors.Is(err, want) {
+			t.Errorf("attempt %d error = %v, want %v", i+1, err, want)
+		}
+	}
+}
+
+func TestCodesDeliveryFailure(t *testing.T) {
+	store := NewMemoryStore()
+	mock := sms.NewMockSender()
+	mock.Err = errors.New("provider down")
+	codes := NewCodes(store, SMSDeliverer(mock, map[string]string{"login": "SMS_1"}, "code"), DefaultCodeConfig())
+	ctx := context.Background()
+
+	if err := codes.Send(ctx, "login", "+8613800000000"); err == nil {
+		t.Fatal("Send() should report delivery errors")
+	}
+	mock.Err = nil
+	if err := codes.Send(ctx, "login", "+8613800000000"); err != nil {
+		t.Fatalf("Send() after a failed delivery error = %v, want no resend delay", err)
+	}
+	msg := mock.Last("+8613800000000")
+	if msg == nil || msg.Template != "SMS_1" || len(msg.Params["code"]) != 6 {
+		t.Errorf("sms = %+v", msg)
+	}
+	if err := codes.Send(ctx, "signup", "+8613800000001"); err == nil {
+		t.Error("Send() without a template for the purpose should fail")
+	}
+}
+
+func TestMailDeliverer(t *testing.T) {
+	sender := mailer.NewMemorySender()
+	d := MailDeliverer(sender, func(purpose, code string) (*mailer.Message, error) {
+		return &mailer.Message{From: "noreply@example.com", Subject: "Your " + purpose + " code", Text: code}, nil
+	})
+	if err := d.Deliver(context.Background(), "reset", "alice@example.com", "123456"); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	msgs := sender.Messages()
+	if len(msgs) != 1 || msgs[0].To[0] != "alice@example.com" || msgs[0].Text != "123456" {
+		t.Errorf("messages = %+v", msgs)
+	}
+}
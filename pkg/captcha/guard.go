@@ -0,0 +1,93 @@
+package captcha
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// GuardConfig configures when a captcha becomes required
+type GuardConfig struct {
+	// Threshold failures within Window make a captcha required
+	Threshold int           `json:"threshold" yaml:"threshold" env:"THRESHOLD"`
+	Window    time.Duration `json:"window" yaml:"window" env:"WINDOW"`
+	Prefix    string        `json:"prefix" yaml:"prefix" env:"PREFIX"`
+}
+
+// DefaultGuardConfig returns a captcha after 3 failures in 15 minutes
+func DefaultGuardConfig() GuardConfig {
+	return GuardConfig{
+		Threshold: 3,
+		Window:    15 * time.Minute,
+		Prefix:    "captcha:failures:",
+	}
+}
+
+// Guard counts failures per key, such as a client IP or username, and
+// requires a solved captcha once a key reaches the threshold
+type Guard struct {
+	store   Store
+	captcha *Captcha
+	cfg     GuardConfig
+}
+
+// NewGuard creates a guard verifying answers with captcha
+func NewGuard(store Store, captcha *Captcha, cfg GuardConfig) *Guard {
+	defaults := DefaultGuardConfig()
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaults.Threshold
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaults.Window
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaults.Prefix
+	}
+	return &Guard{store: store, captcha: captcha, cfg: cfg}
+}
+
+// Captcha returns the captcha generator used to issue challenges
+func (g *Guard) Captcha() *Captcha {
+	return g.captcha
+}
+
+// Required reports whether key must solve a captcha
+func (g *Guard) Required(ctx context.Context, key string) (bool, error) {
+	v, err := g.store.Get(ctx, g.cfg.Prefix+key)
+	if stderrors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load failures: %w", err)
+	}
+	n, _ := strconv.Atoi(v)
+	return n >= g.cfg.Threshold, nil
+}
+
+// Check returns nil when key needs no captcha or the answer is correct,
+// ErrRequired when none was given and ErrInvalid when it is wrong
+func (g *Guard) Check(ctx context.Context, key, id, answer string) error {
+	required, err := g.Required(ctx, key)
+	if err != nil || !required {
+		return err
+	}
+	if id == "" || answer == "" {
+		return ErrRequired
+	}
+	return g.captcha.Verify(ctx, id, answer)
+}
+
+// Fail records a failure for key
+func (g *Guard) Fail(ctx context.Context, key string) error {
+	if _, err := g.store.Incr(ctx, g.cfg.Prefix+key, g.cfg.Window); err != nil {
+		return fmt.Errorf("failed to record failure: %w", err)
+	}
+	return nil
+}
+
+// Reset clears the failures of key, e.g. after a successful login
+func (g *Guard) Reset(ctx context.Context, key string) error {
+	return g.store.Delete(ctx, g.cfg.Prefix+key)
+}
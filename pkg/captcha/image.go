@@ -0,0 +1,116 @@
+package captcha
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand/v2"
+)
+
+// digits is a 5x7 bitmap font; each row is the low five bits
+var digits = [10][7]uint8{
+	{0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	{0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	{0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	{0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	{0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	{0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	{0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	{0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	{0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	{0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+}
+
+// render draws text as a PNG with jittered, slanted glyphs and noise
+func render(text string, width, height, noise int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	bg := color.RGBA{uint8(230 + rand.IntN(26)), uint8(230 + rand.IntN(26)), uint8(230 + rand.IntN(26)), 255}
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []uint8{bg.R, bg.G, bg.B, bg.A})
+	}
+
+	cell := width / (len(text) + 1)
+	scale := max(1, min(height*6/10/7, cell*7/10/5))
+	for i, ch := range text {
+		if ch < '0' || ch > '9' {
+			return nil, fmt.Errorf("captcha text must be digits, got %q", ch)
+		}
+		x0 := cell/2 + i*cell + rand.IntN(max(1, cell-5*scale))
+		y0 := rand.IntN(max(1, height-7*scale))
+		slant := rand.IntN(3) - 1
+		c := darkColor()
+		for row, bits := range digits[ch-'0'] {
+			shift := slant * (3 - row) * scale / 3
+			for col := 0; col < 5; col++ {
+				if bits&(1<<(4-col)) == 0 {
+					continue
+				}
+				fillRect(img, x0+col*scale+shift, y0+row*scale, scale, scale, c)
+			}
+		}
+	}
+
+	for i := 0; i < noise; i++ {
+		drawLine(img, rand.IntN(width), rand.IntN(height), rand.IntN(width), rand.IntN(height), darkColor())
+	}
+	for i := 0; i < width*height/30; i++ {
+		img.Set(rand.IntN(width), rand.IntN(height), darkColor())
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode captcha: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// darkColor returns a random color readable on the light background
+func darkColor() color.RGBA {
+	return color.RGBA{uint8(rand.IntN(150)), uint8(rand.IntN(150)), uint8(rand.IntN(150)), 255}
+}
+
+// fillRect fills a w x h rectangle at x, y
+func fillRect(img *image.RGBA, x, y, w, h int, c color.RGBA) {
+	for dy := 0; dy < h; dy++ {
+		for dx := 0; dx < w; dx++ {
+			img.SetRGBA(x+dx, y+dy, c)
+		}
+	}
+}
+
+// drawLine draws a line with Bresenham's algorithm
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	for e := dx + dy; ; {
+		img.SetRGBA(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,167 @@
+package captcha
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mora/pkg/cache"
+)
+
+// ErrNotFound is returned by Store.Get for missing or expired keys
+var ErrNotFound = stderrors.New("captcha: key not found")
+
+// Store keeps challenges, codes and counters with expiry
+type Store interface {
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// SetNX sets key only when it does not exist and reports whether it did
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// Get returns ErrNotFound for missing keys
+	Get(ctx context.Context, key string) (string, error)
+	Delete(ctx context.Context, keys ...string) error
+	// Incr increments a counter, starting its ttl on the first increment
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// RedisStore is a Store backed by pkg/cache
+type RedisStore struct {
+	client *cache.Client
+}
+
+// NewRedisStore creates a Redis store
+func NewRedisStore(client *cache.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Set implements Store
+func (s *RedisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl)
+}
+
+// SetNX implements Store
+func (s *RedisStore) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	return s.client.GetClient().SetNX(ctx, key, value, ttl).Result()
+}
+
+// Get implements Store
+func (s *RedisStore) Get(ctx context.Context, key string) (string, error) {
+	v, err := s.client.Get(ctx, key)
+	if stderrors.Is(err, redis.Nil) {
+		return "", ErrNotFound
+	}
+	return v, err
+}
+
+// Delete implements Store
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	return s.client.Delete(ctx, keys...)
+}
+
+// incrScript increments a counter and sets its expiry on creation
+var incrScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// Incr implements Store
+func (s *RedisStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	n, err := incrScript.Run(ctx, s.client.GetClient(), []string{key}, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// memoryEntry is a value with its expiry
+type memoryEntry struct {
+	value   string
+	expires time.Time
+}
+
+// MemoryStore is an in-process Store for tests and single-instance services
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry), now: time.Now}
+}
+
+// get returns a live entry; callers hold the lock
+func (s *MemoryStore) get(key string) (memoryEntry, bool) {
+	e, ok := s.entries[key]
+	if ok && !s.now().Before(e.expires) {
+		delete(s.entries, key)
+		return memoryEntry{}, false
+	}
+	return e, ok
+}
+
+// Set implements Store
+func (s *MemoryStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryEntry{value: value, expires: s.now().Add(ttl)}
+	return nil
+}
+
+// SetNX implements Store
+func (s *MemoryStore) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.get(key); ok {
+		return false, nil
+	}
+	s.entries[key] = memoryEntry{value: value, expires: s.now().Add(ttl)}
+	return true, nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.get(key)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return e.value, nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range keys {
+		delete(s.entries, k)
+	}
+	return nil
+}
+
+// Incr implements Store
+func (s *MemoryStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.get(key)
+	if !ok {
+		e = memoryEntry{value: "0", expires: s.now().Add(ttl)}
+	}
+	n, err := strconv.ParseInt(e.value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a counter", key)
+	}
+	n++
+	e.value = strconv.FormatInt(n, 10)
+	s.entries[key] = e
+	return n, nil
+}
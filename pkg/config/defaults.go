@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MissingFieldsError lists the required fields left empty after loading
+type MissingFieldsError struct {
+	// Fields are the dotted YAML paths with their environment variable,
+	// e.g. "database.password (DATABASE_PASSWORD)"
+	Fields []string
+}
+
+// Error implements error
+func (e *MissingFieldsError) Error() string {
+	return "missing required config fields: " + strings.Join(e.Fields, ", ")
+}
+
+// applyTags fills fields still at their zero value from `default:"x"` tags,
+// in setFieldValue syntax, then reports every `required:"true"` field that
+// is still empty. Nil pointers to structs are optional sections and are
+// left alone.
+func (l *Loader) applyTags(cfg any) error {
+	missing := &MissingFieldsError{}
+	if err := l.applyStructTags(reflect.ValueOf(cfg).Elem(), "", "", missing); err != nil {
+		return err
+	}
+	if len(missing.Fields) > 0 {
+		return missing
+	}
+	return nil
+}
+
+// applyStructTags applies the tags of a struct found at path and env prefix
+func (l *Loader) applyStructTags(v reflect.Value, path, prefix string, missing *MissingFieldsError) error {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		fieldType := t.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		name, ok := parseFieldName(fieldType)
+		if !ok {
+			continue
+		}
+
+		if isStructField(field) {
+			if field.Kind() == reflect.Pointer {
+				if field.IsNil() {
+					continue
+				}
+				field = field.Elem()
+			}
+			nestedPath, nestedPrefix := path, prefix
+			if !name.inline {
+				nestedPath = joinPath(path, name.yaml)
+				nestedPrefix = name.env
+				if prefix != "" {
+					nestedPrefix = prefix + "_" + name.env
+				}
+			}
+			if err := l.applyStructTags(field, nestedPath, nestedPrefix, missing); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fieldPath := joinPath(path, name.yaml)
+		if def, ok := fieldType.Tag.Lookup("default"); ok && field.IsZero() {
+			if err := l.setFieldValue(field, def); err != nil {
+				return fmt.Errorf("invalid default for field %s: %w", fieldPath, err)
+			}
+		}
+		if fieldType.Tag.Get("required") == "true" && field.IsZero() {
+			missing.Fields = append(missing.Fields, fmt.Sprintf("%s (%s)", fieldPath, l.fieldEnvName(prefix, name)))
+		}
+	}
+	return nil
+}
+
+// joinPath appends a key to a dotted path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
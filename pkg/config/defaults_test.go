@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type tagConfig struct {
+	Server struct {
+		Host    string        `yaml:"host" default:"0.0.0.0"`
+		Port    int           `yaml:"port" default:"8080"`
+		Timeout time.Duration `yaml:"timeout" default:"30s"`
+	} `yaml:"server"`
+	Database struct {
+		DSN      string `yaml:"dsn" required:"true"`
+		Password string `yaml:"password" env:"/DB_PASSWORD" required:"true"`
+	} `yaml:"database"`
+	SkipPaths []string `yaml:"skip_paths" default:"/health,/metrics"`
+	Cache     *struct {
+		Addr string `yaml:"addr" required:"true"`
+	} `yaml:"cache"`
+}
+
+func TestApplyTags(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		env         map[string]string
+		wantMissing []string
+		check       func(t *testing.T, cfg *tagConfig)
+	}{
+		{
+			name:        "missing required",
+			yaml:        "server:\n  port: 9000\n",
+			wantMissing: []string{"database.dsn (DATABASE_DSN)", "database.password (DB_PASSWORD)"},
+		},
+		{
+			name: "defaults fill unset fields",
+			yaml: "server:\n  port: 9000\ndatabase:\n  dsn: postgres://db\n",
+			env:  map[string]string{"DB_PASSWORD": "secret"},
+			check: func(t *testing.T, cfg *tagConfig) {
+				if cfg.Server.Port != 9000 || cfg.Server.Host != "0.0.0.0" || cfg.Server.Timeout != 30*time.Second {
+					t.Errorf("Server = %+v", cfg.Server)
+				}
+				if !reflect.DeepEqual(cfg.SkipPaths, []string{"/health", "/metrics"}) {
+					t.Errorf("SkipPaths = %v", cfg.SkipPaths)
+				}
+				if cfg.Cache != nil {
+					t.Errorf("Cache = %+v, want nil", cfg.Cache)
+				}
+			},
+		},
+		{
+			name:        "required inside a set section",
+			yaml:        "database:\n  dsn: x\n  password: y\ncache: {}\n",
+			wantMissing: []string{"cache.addr (CACHE_ADDR)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			var cfg tagConfig
+			err := NewLoader(WithConfigPaths(path)).Load(&cfg)
+
+			var missing *MissingFieldsError
+			if len(tt.wantMissing) > 0 {
+				if !errors.As(err, &missing) {
+					t.Fatalf("Load() error = %v, want MissingFieldsError", err)
+				}
+				if !reflect.DeepEqual(missing.Fields, tt.wantMissing) {
+					t.Errorf("missing = %v, want %v", missing.Fields, tt.wantMissing)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			tt.check(t, &cfg)
+		})
+	}
+}
+
+func TestInvalidDefault(t *testing.T) {
+	var cfg struct {
+		Port int `yaml:"port" default:"http"`
+	}
+	if err := NewLoader(WithConfigPaths()).Load(&cfg); err == nil {
+		t.Error("Load() with an invalid default should fail")
+	}
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"mora/pkg/utils"
+)
+
+// MaskedValue replaces the value of secret fields in Dump and Diff
+const MaskedValue = "******"
+
+// Dump renders cfg, typically after Load, as YAML with the fields tagged
+// `secret:"true"` masked, showing the value that won after merging files,
+// environment and defaults. Everything below a secret field is masked:
+// strings become MaskedValue and other values their zero value.
+func (l *Loader) Dump(cfg any) ([]byte, error) {
+	data, err := yaml.Marshal(maskSecrets(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump config: %w", err)
+	}
+	return data, nil
+}
+
+// DumpJSON renders cfg like Dump as indented JSON
+func (l *Loader) DumpJSON(cfg any) ([]byte, error) {
+	data, err := json.MarshalIndent(maskSecrets(cfg), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump config: %w", err)
+	}
+	return data, nil
+}
+
+// Diff returns the fields changed between two configurations of the same
+// type like utils.Diff, with the values of secret fields replaced by
+// MaskedValue; a secret set or cleared keeps its empty side. Watch reports
+// its changes with Diff.
+func Diff(old, new any) []utils.Change {
+	changes := utils.Diff(old, new)
+	if len(changes) == 0 {
+		return changes
+	}
+
+	var secrets []string
+	collectSecretPaths(reflect.ValueOf(old), "", &secrets)
+	collectSecretPaths(reflect.ValueOf(new), "", &secrets)
+	for i, change := range changes {
+		for _, path := range secrets {
+			if change.Path == path || strings.HasPrefix(change.Path, path+".") || strings.HasPrefix(change.Path, path+"[") {
+				changes[i].Old = maskChangeValue(change.Old)
+				changes[i].New = maskChangeValue(change.New)
+				break
+			}
+		}
+	}
+	return changes
+}
+
+// isSecretField reports whether a field is tagged `secret:"true"`
+func isSecretField(field reflect.StructField) bool {
+	return field.Tag.Get("secret") == "true"
+}
+
+// maskSecrets returns a copy of cfg with its secret fields masked
+func maskSecrets(cfg any) any {
+	masked := utils.DeepCopy(cfg)
+	v := reflect.ValueOf(&masked).Elem()
+	maskFields(v)
+	return masked
+}
+
+// maskFields masks the secret fields found in v, a settable value
+func maskFields(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			maskFields(v.Elem())
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		maskFields(elem)
+		v.Set(elem)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Field(i).CanSet() {
+				continue
+			}
+			if isSecretField(t.Field(i)) {
+				maskValue(v.Field(i))
+				continue
+			}
+			maskFields(v.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			maskFields(v.Index(i))
+		}
+	case reflect.Map:
+		// Map values are not addressable, each one is masked in a copy
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			maskFields(elem)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+}
+
+// maskValue masks every value in v
+func maskValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() > 0 {
+			v.SetString(MaskedValue)
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			maskValue(v.Elem())
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		maskValue(elem)
+		v.Set(elem)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				maskValue(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		// []byte would render as its content, base64 encoded in JSON
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			maskValue(v.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			maskValue(v.Index(i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			maskValue(elem)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	default:
+		v.Set(reflect.Zero(v.Type()))
+	}
+}
+
+// maskChangeValue masks one side of a change, keeping empty values
+func maskChangeValue(value interface{}) interface{} {
+	if value == nil || reflect.ValueOf(value).IsZero() {
+		return value
+	}
+	return MaskedValue
+}
+
+// collectSecretPaths appends the paths of the secret fields in v, named
+// like utils.Diff names them
+func collectSecretPaths(v reflect.Value, path string, paths *[]string) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			collectSecretPaths(v.Elem(), path, paths)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+				collectSecretPaths(v.Field(i), path, paths)
+				continue
+			}
+			name, ok := diffFieldName(field)
+			if !ok {
+				continue
+			}
+			if isSecretField(field) {
+				*paths = append(*paths, joinPath(path, name))
+				continue
+			}
+			collectSecretPaths(v.Field(i), joinPath(path, name), paths)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectSecretPaths(v.Index(i), fmt.Sprintf("%s[%d]", path, i), paths)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			collectSecretPaths(iter.Value(), joinPath(path, fmt.Sprint(iter.Key().Interface())), paths)
+		}
+	}
+}
+
+// diffFieldName returns the name utils.Diff gives a struct field: its json
+// name, or its Go name without one
+func diffFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type dumpConfig struct {
+	Server struct {
+		Port int `json:"port" yaml:"port"`
+	} `json:"server" yaml:"server"`
+	Database struct {
+		User     string `json:"user" yaml:"user"`
+		Password string `json:"password" yaml:"password" secret:"true"`
+	} `json:"database" yaml:"database"`
+	Keys    []string          `json:"keys" yaml:"keys" secret:"true"`
+	Tokens  map[string]string `json:"tokens" yaml:"tokens" secret:"true"`
+	PIN     int               `json:"pin" yaml:"pin" secret:"true"`
+	APIKey  string            `json:"api_key" yaml:"api_key" secret:"true"`
+	Clients []struct {
+		Name   string `json:"name" yaml:"name"`
+		Secret string `json:"secret" yaml:"secret" secret:"true"`
+	} `json:"clients" yaml:"clients"`
+}
+
+func newDumpConfig() *dumpConfig {
+	cfg := &dumpConfig{}
+	cfg.Server.Port = 8080
+	cfg.Database.User = "app"
+	cfg.Database.Password = "p4ss"
+	cfg.Keys = []string{"k1", "k2"}
+	cfg.Tokens = map[string]string{"ci": "t0k"}
+	cfg.PIN = 1234
+	cfg.Clients = append(cfg.Clients, struct {
+		Name   string `json:"name" yaml:"name"`
+		Secret string `json:"secret" yaml:"secret" secret:"true"`
+	}{Name: "web", Secret: "s3cr3t"})
+	return cfg
+}
+
+func TestDump(t *testing.T) {
+	cfg := newDumpConfig()
+	loader := NewLoader()
+
+	data, err := loader.Dump(cfg)
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	var got dumpConfig
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Dump() is not YAML: %v\n%s", err, data)
+	}
+	if got.Server.Port != 8080 || got.Database.User != "app" || got.Clients[0].Name != "web" {
+		t.Errorf("Dump() lost plain values:\n%s", data)
+	}
+	if got.Database.Password != MaskedValue || got.Keys[1] != MaskedValue || got.Tokens["ci"] != MaskedValue ||
+		got.PIN != 0 || got.Clients[0].Secret != MaskedValue {
+		t.Errorf("Dump() did not mask secrets:\n%s", data)
+	}
+	if got.APIKey != "" {
+		t.Errorf("empty secret dumped as %q, want empty", got.APIKey)
+	}
+
+	data, err = loader.DumpJSON(cfg)
+	if err != nil {
+		t.Fatalf("DumpJSON() error = %v", err)
+	}
+	if !json.Valid(data) || strings.Contains(string(data), "p4ss") || !strings.Contains(string(data), `"user": "app"`) {
+		t.Errorf("DumpJSON() =\n%s", data)
+	}
+
+	if cfg.Database.Password != "p4ss" || cfg.Tokens["ci"] != "t0k" || cfg.Clients[0].Secret != "s3cr3t" {
+		t.Errorf("Dump() modified cfg: %+v", cfg)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := newDumpConfig()
+	new := newDumpConfig()
+	new.Server.Port = 9090
+	new.Database.Password = "rotated"
+	new.Tokens["deploy"] = "t1"
+	new.APIKey = "key"
+	new.Clients[0].Secret = "rotated"
+
+	want := map[string][2]interface{}{
+		"server.port":       {8080, 9090},
+		"database.password": {MaskedValue, MaskedValue},
+		"tokens.deploy":     {nil, MaskedValue},
+		"api_key":           {"", MaskedValue},
+		"clients[0].secret": {MaskedValue, MaskedValue},
+	}
+	changes := Diff(old, new)
+	if len(changes) != len(want) {
+		t.Fatalf("Diff() = %v, want %d changes", changes, len(want))
+	}
+	for _, c := range changes {
+		w, ok := want[c.Path]
+		if !ok || c.Old != w[0] || c.New != w[1] {
+			t.Errorf("change %s: %v -> %v, want %v -> %v", c.Path, c.Old, c.New, w[0], w[1])
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"mora/pkg/cache"
+	"mora/pkg/db"
+	"mora/pkg/ratelimit"
+)
+
+type KafkaConfig struct {
+	Brokers []string `yaml:"brokers"`
+	Topic   string   `yaml:"topic"`
+}
+
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+}
+
+type envConfig struct {
+	KafkaConfig `yaml:",inline"`
+	Common      struct {
+		Name string `yaml:"name"`
+	} `yaml:",inline"`
+	SkipPaths []string          `yaml:"skip_paths"`
+	Ports     []int             `yaml:"ports"`
+	Labels    map[string]string `yaml:"labels"`
+	Weights   map[string]int    `yaml:"weights"`
+	Timeout   time.Duration     `yaml:"timeout"`
+	Retries   *int              `yaml:"retries"`
+	Listen    net.IP            `yaml:"listen"`
+	TLS       *TLSConfig        `yaml:"tls"`
+	Cache     *struct {
+		TTL time.Duration `yaml:"ttl"`
+	} `yaml:"cache"`
+	Ignored string `yaml:"-"`
+}
+
+func TestLoadFromEnvTypes(t *testing.T) {
+	env := map[string]string{
+		"APP_BROKERS":       "kafka-1:9092, kafka-2:9092,",
+		"APP_TOPIC":         "orders",
+		"APP_NAME":          "order-api",
+		"APP_SKIP_PATHS":    "/health,/metrics",
+		"APP_PORTS":         "80,443",
+		"APP_LABELS":        "team=core, tier=1",
+		"APP_WEIGHTS":       "a=1,b=2",
+		"APP_TIMEOUT":       "30s",
+		"APP_RETRIES":       "3",
+		"APP_LISTEN":        "10.0.0.1",
+		"APP_TLS_CERT_FILE": "/etc/tls/cert.pem",
+		"APP_IGNORED":       "set",
+	}
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	var cfg envConfig
+	if err := NewLoader(WithConfigPaths(), WithEnvPrefix("app")).Load(&cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	retries := 3
+	want := envConfig{
+		KafkaConfig: KafkaConfig{Brokers: []string{"kafka-1:9092", "kafka-2:9092"}, Topic: "orders"},
+		SkipPaths:   []string{"/health", "/metrics"},
+		Ports:       []int{80, 443},
+		Labels:      map[string]string{"team": "core", "tier": "1"},
+		Weights:     map[string]int{"a": 1, "b": 2},
+		Timeout:     30 * time.Second,
+		Retries:     &retries,
+		Listen:      net.ParseIP("10.0.0.1"),
+		TLS:         &TLSConfig{CertFile: "/etc/tls/cert.pem"},
+	}
+	want.Common.Name = "order-api"
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Load() = %+v\nwant %+v", cfg, want)
+	}
+	if cfg.Cache != nil {
+		t.Errorf("Cache = %+v, want nil when none of its variables is set", cfg.Cache)
+	}
+}
+
+func TestSetFieldValueErrors(t *testing.T) {
+	loader := NewLoader()
+
+	tests := []struct {
+		name  string
+		field interface{}
+		value string
+	}{
+		{name: "duration", field: new(time.Duration), value: "30"},
+		{name: "slice element", field: new([]int), value: "1,x"},
+		{name: "map entry", field: new(map[string]string), value: "team"},
+		{name: "map value", field: new(map[string]int), value: "a=x"},
+		{name: "int overflow", field: new(int8), value: "300"},
+		{name: "unsupported", field: new(chan int), value: "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := loader.setFieldValue(reflect.ValueOf(tt.field).Elem(), tt.value); err == nil {
+				t.Errorf("setFieldValue(%q) should fail", tt.value)
+			}
+		})
+	}
+}
+
+func TestLoadFromEnvNesting(t *testing.T) {
+	type appConfig struct {
+		Cache     cache.Config     `yaml:"cache"`
+		Database  db.Config        `yaml:"database"`
+		RateLimit ratelimit.Config `yaml:"rate_limit"`
+		Region    struct {
+			Name string `yaml:"name" env:"/AWS_REGION"`
+		} `yaml:"region"`
+	}
+
+	env := map[string]string{
+		"PASSWORD":          "p",
+		"DSN":               "d",
+		"DB":                "3",
+		"LIMIT":             "7",
+		"CACHE_PASSWORD":    "cp",
+		"DATABASE_DSN":      "dd",
+		"AWS_REGION":        "eu-west-1",
+		"REGION_AWS_REGION": "ignored",
+		"REGION_NAME":       "ignored",
+	}
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	var cfg appConfig
+	if err := NewLoader(WithConfigPaths()).Load(&cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"cache password", cfg.Cache.Password, "cp"},
+		{"unprefixed db", cfg.Cache.DB, 0},
+		{"database dsn", cfg.Database.DSN, "dd"},
+		{"unprefixed limit", cfg.RateLimit.Limit, 0},
+		{"absolute env tag", cfg.Region.Name, "eu-west-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
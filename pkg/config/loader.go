@@ -1,19 +1,28 @@
 package config
 
 import (
+	"encoding"
 	"fmt"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
-
-	"gopkg.in/yaml.v3"
+	"sync"
+	"time"
 )
 
 // Loader handles configuration loading from various sources
 type Loader struct {
-	configPaths []string
-	envPrefix   string
+	configPaths   []string
+	envPrefix     string
+	profile       string
+	sources       []Source
+	watchDebounce time.Duration
+	onWatchError  func(error)
+	secrets       map[string]SecretProvider
+
+	fileOnce sync.Once
+	file     *yamlFileSource
 }
 
 // Option represents a configuration option
@@ -47,63 +56,50 @@ func NewLoader(opts ...Option) *Loader {
 	return loader
 }
 
-// Load loads configuration into the provided struct
+// Load loads configuration into the provided struct: by default from the
+// first existing config file and the file of the active profile, overridden
+// by environment variables, or from the sources of WithSources in order.
+// Defaults and required fields are applied last, then secret placeholders
+// are resolved.
 func (l *Loader) Load(cfg any) error {
-	// First, try to load from YAML files
-	if err := l.loadFromFile(cfg); err != nil {
-		return fmt.Errorf("failed to load config from file: %w", err)
+	if err := l.loadSources(cfg); err != nil {
+		return err
 	}
+	return l.finish(cfg)
+}
 
-	// Then, override with environment variables
-	if err := l.loadFromEnv(cfg); err != nil {
-		return fmt.Errorf("failed to load config from env: %w", err)
+// finish fills defaults, checks required fields and resolves secrets
+func (l *Loader) finish(cfg any) error {
+	if err := l.applyTags(cfg); err != nil {
+		return err
 	}
-
-	return nil
+	return l.resolveSecrets(cfg)
 }
 
-// loadFromFile loads configuration from YAML files
-func (l *Loader) loadFromFile(cfg any) error {
-	var configFile string
-	var found bool
-
-	// Find the first existing config file
-	for _, path := range l.configPaths {
-		if _, err := os.Stat(path); err == nil {
-			configFile = path
-			found = true
-			break
+// loadSources merges every source into cfg
+func (l *Loader) loadSources(cfg any) error {
+	for _, source := range l.activeSources() {
+		if err := source.Load(cfg); err != nil {
+			return fmt.Errorf("failed to load config from %s: %w", source.Name(), err)
 		}
 	}
-
-	if !found {
-		// No config file found, that's okay - we'll rely on env vars or defaults
-		return nil
-	}
-
-	data, err := os.ReadFile(configFile)
-	if err != nil {
-		return fmt.Errorf("failed to read config file %s: %w", configFile, err)
-	}
-
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return fmt.Errorf("failed to parse config file %s: %w", configFile, err)
-	}
-
 	return nil
 }
 
 // loadFromEnv loads configuration from environment variables using reflection
 func (l *Loader) loadFromEnv(cfg any) error {
-	return l.loadStructFromEnv(reflect.ValueOf(cfg).Elem(), "")
+	_, err := l.loadStructFromEnv(reflect.ValueOf(cfg).Elem(), "")
+	return err
 }
 
-// loadStructFromEnv recursively loads struct fields from environment variables
-func (l *Loader) loadStructFromEnv(v reflect.Value, prefix string) error {
+// loadStructFromEnv recursively loads struct fields from environment
+// variables and reports whether any was set
+func (l *Loader) loadStructFromEnv(v reflect.Value, prefix string) (bool, error) {
 	if v.Kind() != reflect.Struct {
-		return nil
+		return false, nil
 	}
 
+	set := false
 	t := v.Type()
 	for i := 0; i < v.NumField(); i++ {
 		field := v.Field(i)
@@ -114,27 +110,37 @@ func (l *Loader) loadStructFromEnv(v reflect.Value, prefix string) error {
 			continue
 		}
 
-		// Get field name from tag or use field name
-		fieldName := fieldType.Name
-		if tag := fieldType.Tag.Get("env"); tag != "" {
-			fieldName = tag
-		} else if tag := fieldType.Tag.Get("yaml"); tag != "" {
-			fieldName = tag
+		name, ok := parseFieldName(fieldType)
+		if !ok {
+			continue
+		}
+		fieldName := name.env
+
+		// Embedded and inlined structs share the prefix of their parent
+		if name.inline && isStructField(field) {
+			ok, err := l.loadNestedFromEnv(field, prefix)
+			if err != nil {
+				return false, err
+			}
+			set = set || ok
+			continue
 		}
 
 		// Build environment variable name
-		envName := l.buildEnvName(prefix, fieldName)
+		envName := l.fieldEnvName(prefix, name)
 
-		// Handle nested structs
-		if field.Kind() == reflect.Struct {
+		// Handle nested structs, including pointers to structs
+		if isStructField(field) {
 			// For nested structs, use the field name as prefix
 			nestedPrefix := fieldName
-			if prefix != "" {
+			if prefix != "" && !name.absolute {
 				nestedPrefix = prefix + "_" + fieldName
 			}
-			if err := l.loadStructFromEnv(field, nestedPrefix); err != nil {
-				return err
+			ok, err := l.loadNestedFromEnv(field, nestedPrefix)
+			if err != nil {
+				return false, err
 			}
+			set = set || ok
 			continue
 		}
 
@@ -146,11 +152,92 @@ func (l *Loader) loadStructFromEnv(v reflect.Value, prefix string) error {
 
 		// Set field value based on type
 		if err := l.setFieldValue(field, envValue); err != nil {
-			return fmt.Errorf("failed to set field %s from env %s: %w", fieldName, envName, err)
+			return false, fmt.Errorf("failed to set field %s from env %s: %w", fieldName, envName, err)
 		}
+		set = true
 	}
 
-	return nil
+	return set, nil
+}
+
+// loadNestedFromEnv loads a struct or pointer to struct field; a nil
+// pointer is only allocated when one of its variables is set
+func (l *Loader) loadNestedFromEnv(field reflect.Value, prefix string) (bool, error) {
+	if field.Kind() != reflect.Pointer {
+		return l.loadStructFromEnv(field, prefix)
+	}
+	if !field.IsNil() {
+		return l.loadStructFromEnv(field.Elem(), prefix)
+	}
+
+	nested := reflect.New(field.Type().Elem())
+	set, err := l.loadStructFromEnv(nested.Elem(), prefix)
+	if set && err == nil {
+		field.Set(nested)
+	}
+	return set, err
+}
+
+// fieldName holds the names a struct field is known by
+type fieldName struct {
+	// yaml is the key in config files
+	yaml string
+	// env is the variable name part, the env tag or the yaml key
+	env string
+	// absolute is set by an env tag with a leading AbsoluteEnvMarker: the
+	// name is not nested under the parent's prefix
+	absolute bool
+	// inline fields are embedded or ",inline" structs sharing the parent's
+	// prefix
+	inline bool
+}
+
+// parseFieldName reads the yaml and env tags of a field; false means the
+// field is skipped with "-"
+func parseFieldName(field reflect.StructField) (fieldName, bool) {
+	envTag := field.Tag.Get("env")
+	yamlName, yamlOpts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if envTag == "-" || yamlName == "-" {
+		return fieldName{}, false
+	}
+
+	name := fieldName{yaml: field.Name, env: field.Name}
+	if yamlName != "" {
+		name.yaml, name.env = yamlName, yamlName
+	}
+	if envTag != "" {
+		name.env = strings.TrimPrefix(envTag, AbsoluteEnvMarker)
+		name.absolute = name.env != envTag
+	}
+	name.inline = envTag == "" &&
+		((field.Anonymous && yamlName == "") || strings.Contains(yamlOpts, "inline"))
+	return name, true
+}
+
+// AbsoluteEnvMarker starts an env tag naming a variable outside the parent's
+// prefix, e.g. `env:"/DB_HOST"` reads DB_HOST wherever the field is nested
+const AbsoluteEnvMarker = "/"
+
+// fieldEnvName returns the environment variable of a field, nested under
+// prefix unless its env tag is absolute; the loader's prefix always applies
+func (l *Loader) fieldEnvName(prefix string, name fieldName) string {
+	if name.absolute {
+		return l.buildEnvName("", name.env)
+	}
+	return l.buildEnvName(prefix, name.env)
+}
+
+// isStructField reports whether a field is loaded as a group of variables
+// rather than from a single one
+func isStructField(field reflect.Value) bool {
+	t := field.Type()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(textUnmarshalerType)
 }
 
 // buildEnvName builds environment variable name with prefix
@@ -168,25 +255,54 @@ func (l *Loader) buildEnvName(prefix, fieldName string) string {
 	return envName
 }
 
-// setFieldValue sets field value from string
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setFieldValue sets field value from string. Slices are comma-separated,
+// e.g. "a,b,c", and maps are comma-separated key=value pairs, e.g.
+// "team=core,tier=1"; durations use time.ParseDuration syntax, e.g. "30s".
 func (l *Loader) setFieldValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Pointer {
+		elem := reflect.New(field.Type().Elem())
+		if err := l.setFieldValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
 	switch field.Kind() {
 	case reflect.String:
 		field.SetString(value)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		intVal, err := strconv.ParseInt(value, 10, 64)
+		intVal, err := strconv.ParseInt(value, 10, field.Type().Bits())
 		if err != nil {
 			return err
 		}
 		field.SetInt(intVal)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		uintVal, err := strconv.ParseUint(value, 10, 64)
+		uintVal, err := strconv.ParseUint(value, 10, field.Type().Bits())
 		if err != nil {
 			return err
 		}
 		field.SetUint(uintVal)
 	case reflect.Float32, reflect.Float64:
-		floatVal, err := strconv.ParseFloat(value, 64)
+		floatVal, err := strconv.ParseFloat(value, field.Type().Bits())
 		if err != nil {
 			return err
 		}
@@ -197,12 +313,56 @@ func (l *Loader) setFieldValue(field reflect.Value, value string) error {
 			return err
 		}
 		field.SetBool(boolVal)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.Uint8 {
+			field.SetBytes([]byte(value))
+			return nil
+		}
+		parts := splitList(value)
+		slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := l.setFieldValue(slice.Index(i), part); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		field.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(field.Type())
+		for _, pair := range splitList(value) {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("invalid map entry %q, want key=value", pair)
+			}
+			key := reflect.New(field.Type().Key()).Elem()
+			if err := l.setFieldValue(key, strings.TrimSpace(k)); err != nil {
+				return fmt.Errorf("key %q: %w", k, err)
+			}
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := l.setFieldValue(elem, strings.TrimSpace(v)); err != nil {
+				return fmt.Errorf("value of %q: %w", k, err)
+			}
+			m.SetMapIndex(key, elem)
+		}
+		field.Set(m)
 	default:
-		return fmt.Errorf("unsupported field type: %s", field.Kind())
+		return fmt.Errorf("unsupported field type: %s", field.Type())
 	}
 	return nil
 }
 
+// splitList splits a comma-separated value, trimming spaces and dropping
+// empty items
+func splitList(value string) []string {
+	parts := strings.Split(value, ",")
+	out := parts[:0]
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
 // MustLoad loads configuration and panics if it fails
 func (l *Loader) MustLoad(cfg any) {
 	if err := l.Load(cfg); err != nil {
@@ -8,28 +8,28 @@ import (
 
 type TestConfig struct {
 	Database struct {
-		Host     string `yaml:"host" env:"DB_HOST"`
-		Port     int    `yaml:"port" env:"DB_PORT"`
-		Username string `yaml:"username" env:"DB_USERNAME"`
-		Password string `yaml:"password" env:"DB_PASSWORD"`
+		Host     string `yaml:"host" env:"/DB_HOST"`
+		Port     int    `yaml:"port" env:"/DB_PORT"`
+		Username string `yaml:"username" env:"/DB_USERNAME"`
+		Password string `yaml:"password" env:"/DB_PASSWORD"`
 	} `yaml:"database"`
 	Server struct {
-		Port    int  `yaml:"port" env:"SERVER_PORT"`
-		Debug   bool `yaml:"debug" env:"DEBUG"`
-		Timeout int  `yaml:"timeout" env:"TIMEOUT"`
+		Port    int  `yaml:"port" env:"/SERVER_PORT"`
+		Debug   bool `yaml:"debug" env:"/DEBUG"`
+		Timeout int  `yaml:"timeout" env:"/TIMEOUT"`
 	} `yaml:"server"`
 }
 
 func TestLoadConfig_FromEnv(t *testing.T) {
 	// Set environment variables
 	envVars := map[string]string{
-		"DB_HOST":      "localhost",
-		"DB_PORT":      "5432",
-		"DB_USERNAME":  "testuser",
-		"DB_PASSWORD":  "testpass",
-		"SERVER_PORT":  "8080",
-		"DEBUG":        "true",
-		"TIMEOUT":      "30",
+		"DB_HOST":     "localhost",
+		"DB_PORT":     "5432",
+		"DB_USERNAME": "testuser",
+		"DB_PASSWORD": "testpass",
+		"SERVER_PORT": "8080",
+		"DEBUG":       "true",
+		"TIMEOUT":     "30",
 	}
 
 	// Set env vars
@@ -297,4 +297,4 @@ func TestSetFieldValue(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
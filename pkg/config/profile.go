@@ -0,0 +1,24 @@
+package config
+
+import "os"
+
+// ProfileEnv names the environment variable selecting the profile when
+// WithProfile is not used, e.g. APP_ENV=prod
+const ProfileEnv = "APP_ENV"
+
+// WithProfile sets the profile whose file overlays the config file, e.g.
+// "prod" reads config.yaml then config.prod.yaml next to it
+func WithProfile(profile string) Option {
+	return func(l *Loader) {
+		l.profile = profile
+	}
+}
+
+// Profile returns the active profile: the one of WithProfile, else the
+// value of ProfileEnv, else "" for none
+func (l *Loader) Profile() string {
+	if l.profile != "" {
+		return l.profile
+	}
+	return os.Getenv(ProfileEnv)
+}
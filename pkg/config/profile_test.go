@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	files := map[string]string{
+		base:                                   "log:\n  level: info\nlimits:\n  rps: 10\n  burst: 20\nflags:\n  beta: false\n  audit: true\n",
+		filepath.Join(dir, "config.prod.yaml"): "log:\n  level: warn\nlimits:\n  rps: 100\nflags:\n  beta: true\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		env       string
+		wantLevel string
+		wantRPS   int
+		wantBeta  bool
+	}{
+		{name: "no profile", wantLevel: "info", wantRPS: 10},
+		{name: "option", opts: []Option{WithProfile("prod")}, wantLevel: "warn", wantRPS: 100, wantBeta: true},
+		{name: "env", env: "prod", wantLevel: "warn", wantRPS: 100, wantBeta: true},
+		{name: "option wins over env", opts: []Option{WithProfile("dev")}, env: "prod", wantLevel: "info", wantRPS: 10},
+		{name: "missing profile file", env: "staging", wantLevel: "info", wantRPS: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ProfileEnv, tt.env)
+
+			var cfg watchConfig
+			if err := NewLoader(append([]Option{WithConfigPaths(base)}, tt.opts...)...).Load(&cfg); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Log.Level != tt.wantLevel || cfg.Limits.RPS != tt.wantRPS || cfg.Flags["beta"] != tt.wantBeta {
+				t.Errorf("Load() = %+v", cfg)
+			}
+			// Keys the profile file leaves out keep the values of the base file
+			if cfg.Limits.Burst != 20 || !cfg.Flags["audit"] {
+				t.Errorf("Load() lost base values: %+v", cfg)
+			}
+		})
+	}
+
+	if err := NewLoader(WithConfigPaths(base), WithProfile("../prod")).Load(&watchConfig{}); err == nil {
+		t.Error("Load() with a profile containing a path should fail")
+	}
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SecretProvider resolves the reference of a ${scheme:ref} placeholder,
+// e.g. "secret/db#password" for ${vault:secret/db#password}
+type SecretProvider interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretProviderFunc adapts a function to SecretProvider
+type SecretProviderFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve implements SecretProvider
+func (f SecretProviderFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// ErrSecretNotFound is returned by providers when a secret does not exist
+var ErrSecretNotFound = errors.New("secret not found")
+
+// secretPattern matches ${scheme:ref} placeholders
+var secretPattern = regexp.MustCompile(`\$\{([a-z][a-z0-9_-]*):([^}]+)\}`)
+
+// WithSecretProvider registers the provider of ${scheme:ref} placeholders.
+// "env" and "file" are registered by default; Vault and AWS Secrets Manager
+// providers are registered under the scheme of choice, e.g.
+//
+//	vault, err := config.NewVaultSecretProvider(config.VaultConfig{})
+//	...
+//	config.NewLoader(config.WithSecretProvider("vault", vault))
+func WithSecretProvider(scheme string, provider SecretProvider) Option {
+	return func(l *Loader) {
+		if l.secrets == nil {
+			l.secrets = map[string]SecretProvider{}
+		}
+		l.secrets[scheme] = provider
+	}
+}
+
+// secretProvider returns the provider of scheme
+func (l *Loader) secretProvider(scheme string) SecretProvider {
+	if p, ok := l.secrets[scheme]; ok {
+		return p
+	}
+	switch scheme {
+	case "env":
+		return EnvSecretProvider()
+	case "file":
+		return FileSecretProvider("")
+	}
+	return nil
+}
+
+// resolveSecrets expands the placeholders of every string in cfg, including
+// strings in slices, maps and nested structs. A placeholder may be the whole
+// value or part of it, e.g. "postgres://app:${env:DB_PASSWORD}@db/app".
+func (l *Loader) resolveSecrets(cfg any) error {
+	r := &secretResolver{loader: l, ctx: context.Background(), cache: map[string]string{}}
+	return r.resolve(reflect.ValueOf(cfg).Elem(), "")
+}
+
+// secretResolver resolves the placeholders of one configuration, asking
+// the providers once per placeholder
+type secretResolver struct {
+	loader *Loader
+	ctx    context.Context
+	cache  map[string]string
+}
+
+// resolve expands the placeholders found in v, a settable value at path
+func (r *secretResolver) resolve(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := r.expand(v.String())
+		if err != nil {
+			return fmt.Errorf("failed to resolve secret of field %s: %w", path, err)
+		}
+		v.SetString(s)
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return r.resolve(v.Elem(), path)
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := r.resolve(elem, path); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			name, ok := parseFieldName(t.Field(i))
+			if !ok {
+				continue
+			}
+			fieldPath := path
+			if !name.inline {
+				fieldPath = joinPath(path, name.yaml)
+			}
+			if err := r.resolve(field, fieldPath); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := r.resolve(v.Index(i), path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// Map values are not addressable, each one is resolved in a copy
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := r.resolve(elem, joinPath(path, fmt.Sprint(iter.Key().Interface()))); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
+
+// expand replaces the placeholders of s
+func (r *secretResolver) expand(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var err error
+	out := secretPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		if err != nil {
+			return placeholder
+		}
+		if value, ok := r.cache[placeholder]; ok {
+			return value
+		}
+		m := secretPattern.FindStringSubmatch(placeholder)
+		scheme, ref := m[1], m[2]
+		provider := r.loader.secretProvider(scheme)
+		if provider == nil {
+			err = fmt.Errorf("unknown secret provider %q", scheme)
+			return placeholder
+		}
+		var value string
+		if value, err = provider.Resolve(r.ctx, ref); err != nil {
+			err = fmt.Errorf("%s:%s: %w", scheme, ref, err)
+			return placeholder
+		}
+		r.cache[placeholder] = value
+		return value
+	})
+	return out, err
+}
+
+// splitSecretRef splits "path#key" references
+func splitSecretRef(ref string) (path, key string) {
+	path, key, _ = strings.Cut(ref, "#")
+	return path, key
+}
+
+// secretKey returns key of a secret holding several values; without a
+// key, a secret with a single value returns that value
+func secretKey(data map[string]interface{}, key string) (string, error) {
+	if key == "" {
+		if len(data) != 1 {
+			return "", errors.New("secret holds several values, select one with #key")
+		}
+		for _, v := range data {
+			return fmt.Sprint(v), nil
+		}
+	}
+	v, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %q: %w", key, ErrSecretNotFound)
+	}
+	return fmt.Sprint(v), nil
+}
+
+// EnvSecretProvider resolves ${env:NAME} from the environment; an unset
+// variable is an error, unlike an empty one
+func EnvSecretProvider() SecretProvider {
+	return SecretProviderFunc(func(_ context.Context, name string) (string, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("env %s: %w", name, ErrSecretNotFound)
+		}
+		return value, nil
+	})
+}
+
+// FileSecretProvider resolves ${file:path} to the content of a file,
+// without surrounding whitespace, e.g. a Docker or Kubernetes secret
+// mounted at /run/secrets/db_password. ${file:path#key} reads the file as
+// YAML or JSON and returns one of its top-level keys. Relative paths are
+// relative to dir, or to the working directory when dir is empty.
+func FileSecretProvider(dir string) SecretProvider {
+	return SecretProviderFunc(func(_ context.Context, ref string) (string, error) {
+		path, key := splitSecretRef(ref)
+		if dir != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return "", fmt.Errorf("file %s: %w", path, ErrSecretNotFound)
+			}
+			return "", err
+		}
+		if key == "" {
+			return strings.TrimSpace(string(data)), nil
+		}
+
+		var values map[string]interface{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return "", fmt.Errorf("failed to parse secret file %s: %w", path, err)
+		}
+		return secretKey(values, key)
+	})
+}
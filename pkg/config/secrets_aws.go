@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"mora/pkg/internal/sigv4"
+)
+
+// AWSSecretsConfig configures the AWS Secrets Manager secret provider;
+// empty credentials are read from the standard AWS_* variables
+type AWSSecretsConfig struct {
+	// Region defaults to $AWS_REGION, then $AWS_DEFAULT_REGION
+	Region          string `json:"region" yaml:"region"`
+	AccessKeyID     string `json:"access_key_id" yaml:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key" yaml:"secret_access_key"`
+	SessionToken    string `json:"session_token" yaml:"session_token"`
+	// Endpoint overrides the regional endpoint, e.g. for LocalStack
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	// Timeout bounds each request, defaults to DefaultRemoteTimeout
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+// AWSSecretsProvider reads secrets from AWS Secrets Manager. A reference
+// is the secret name or ARN, followed by "#key" to select a key of a JSON
+// secret, e.g. "prod/order-api/db#password".
+type AWSSecretsProvider struct {
+	cfg    AWSSecretsConfig
+	client *http.Client
+	signer *sigv4.Signer
+}
+
+// NewAWSSecretsProvider creates an AWS Secrets Manager secret provider
+func NewAWSSecretsProvider(cfg AWSSecretsConfig) (*AWSSecretsProvider, error) {
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_REGION")
+	}
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if cfg.AccessKeyID == "" && cfg.SecretAccessKey == "" {
+		cfg.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		cfg.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		cfg.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("aws region is required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("aws access key is required")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://secretsmanager." + cfg.Region + ".amazonaws.com"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultRemoteTimeout
+	}
+	return &AWSSecretsProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		signer: &sigv4.Signer{
+			Region:          cfg.Region,
+			Service:         "secretsmanager",
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			SessionToken:    cfg.SessionToken,
+		},
+	}, nil
+}
+
+// Resolve implements SecretProvider
+func (p *AWSSecretsProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	id, key := splitSecretRef(ref)
+	payload, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	p.signer.Sign(req, sigv4.PayloadHash(payload))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("secrets manager request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if strings.HasSuffix(apiErr.Type, "ResourceNotFoundException") {
+			return "", ErrSecretNotFound
+		}
+		return "", fmt.Errorf("secrets manager request failed: status %d: %s %s", resp.StatusCode, apiErr.Type, apiErr.Message)
+	}
+
+	var out struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary []byte  `json:"SecretBinary"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("invalid secrets manager response: %w", err)
+	}
+	value := string(out.SecretBinary)
+	if out.SecretString != nil {
+		value = *out.SecretString
+	}
+	if key == "" {
+		return value, nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object: %w", id, err)
+	}
+	return secretKey(values, key)
+}
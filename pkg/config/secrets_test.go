@@ -0,0 +1,199 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type secretsConfig struct {
+	Database struct {
+		DSN      string `yaml:"dsn"`
+		Password string `yaml:"password"`
+	} `yaml:"database"`
+	JWT struct {
+		Secret string `yaml:"secret" default:"${file:jwt.key}"`
+	} `yaml:"jwt"`
+	Brokers []string          `yaml:"brokers"`
+	Headers map[string]string `yaml:"headers"`
+	Extra   map[string]any    `yaml:"extra"`
+}
+
+func TestResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "jwt.key"), []byte("jwt-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "db.yaml"), []byte("user: app\npassword: file-pass\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	content := `database:
+  dsn: postgres://${file:db.yaml#user}:${vault:secret/db#password}@db:5432/app
+  password: ${vault:secret/db#password}
+brokers: ["${env:BROKER}"]
+headers:
+  x-api-key: ${env:API_KEY}
+extra:
+  token: ${static:token}
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BROKER", "kafka:9092")
+	t.Setenv("API_KEY", "key-1")
+
+	calls := 0
+	vault := SecretProviderFunc(func(_ context.Context, ref string) (string, error) {
+		calls++
+		if ref != "secret/db#password" {
+			return "", ErrSecretNotFound
+		}
+		return "vault-pass", nil
+	})
+
+	var cfg secretsConfig
+	err := NewLoader(
+		WithConfigPaths(path),
+		WithSecretProvider("vault", vault),
+		WithSecretProvider("file", FileSecretProvider(dir)),
+		WithSecretProvider("static", SecretProviderFunc(func(context.Context, string) (string, error) { return "static", nil })),
+	).Load(&cfg)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Database.DSN != "postgres://app:vault-pass@db:5432/app" {
+		t.Errorf("DSN = %q", cfg.Database.DSN)
+	}
+	if cfg.Database.Password != "vault-pass" || calls != 1 {
+		t.Errorf("Password = %q after %d vault calls, want one call", cfg.Database.Password, calls)
+	}
+	if cfg.JWT.Secret != "jwt-secret" {
+		t.Errorf("JWT secret from default = %q", cfg.JWT.Secret)
+	}
+	if cfg.Brokers[0] != "kafka:9092" || cfg.Headers["x-api-key"] != "key-1" || cfg.Extra["token"] != "static" {
+		t.Errorf("Load() = %+v", cfg)
+	}
+}
+
+func TestResolveSecretsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "unknown provider", value: "${nope:x}", want: `unknown secret provider "nope"`},
+		{name: "unset env", value: "${env:MORA_TEST_UNSET}", want: "secret not found"},
+		{name: "missing file", value: "${file:/nonexistent/secret}", want: "secret not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg secretsConfig
+			cfg.Database.Password = tt.value
+			err := NewLoader(WithSources()).Load(&cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), "database.password") {
+				t.Errorf("Load() error = %v, want %q for database.password", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/db":
+			w.Write([]byte(`{"data":{"data":{"password":"p4ss","user":"app"},"metadata":{"version":3}}}`))
+		case "/v1/kv/single":
+			w.Write([]byte(`{"data":{"value":"only"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	v2, err := NewVaultSecretProvider(VaultConfig{Address: srv.URL, Token: "root"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1, _ := NewVaultSecretProvider(VaultConfig{Address: srv.URL, Token: "root", KVVersion: 1})
+	denied, _ := NewVaultSecretProvider(VaultConfig{Address: srv.URL, Token: "bad"})
+
+	tests := []struct {
+		name     string
+		provider SecretProvider
+		ref      string
+		want     string
+		wantErr  error
+	}{
+		{name: "kv v2", provider: v2, ref: "secret/db#password", want: "p4ss"},
+		{name: "kv v1 single value", provider: v1, ref: "kv/single", want: "only"},
+		{name: "several values without key", provider: v2, ref: "secret/db"},
+		{name: "missing key", provider: v2, ref: "secret/db#token", wantErr: ErrSecretNotFound},
+		{name: "missing secret", provider: v2, ref: "secret/other#password", wantErr: ErrSecretNotFound},
+		{name: "denied", provider: denied, ref: "secret/db#password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.provider.Resolve(context.Background(), tt.ref)
+			if tt.want != "" {
+				if err != nil || got != tt.want {
+					t.Errorf("Resolve(%q) = %q, %v, want %q", tt.ref, got, err, tt.want)
+				}
+				return
+			}
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("Resolve(%q) error = %v, want %v", tt.ref, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAWSSecretsProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/secretsmanager/aws4_request") ||
+			r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var req struct{ SecretId string }
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.SecretId {
+		case "prod/db":
+			w.Write([]byte(`{"Name":"prod/db","SecretString":"{\"password\":\"p4ss\"}"}`))
+		case "prod/plain":
+			w.Write([]byte(`{"Name":"prod/plain","SecretString":"plain"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"not found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	p, err := NewAWSSecretsProvider(AWSSecretsConfig{Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "secret", Endpoint: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := p.Resolve(context.Background(), "prod/db#password"); err != nil || got != "p4ss" {
+		t.Errorf("Resolve(prod/db#password) = %q, %v", got, err)
+	}
+	if got, err := p.Resolve(context.Background(), "prod/plain"); err != nil || got != "plain" {
+		t.Errorf("Resolve(prod/plain) = %q, %v", got, err)
+	}
+	if _, err := p.Resolve(context.Background(), "prod/missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Resolve(prod/missing) error = %v, want ErrSecretNotFound", err)
+	}
+}
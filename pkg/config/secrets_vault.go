@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultConfig configures the HashiCorp Vault secret provider
+type VaultConfig struct {
+	// Address is the Vault URL, defaults to $VAULT_ADDR
+	Address string `json:"address" yaml:"address"`
+	// Token authenticates requests, defaults to $VAULT_TOKEN
+	Token string `json:"token" yaml:"token"`
+	// Namespace is the Vault Enterprise namespace, defaults to $VAULT_NAMESPACE
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// KVVersion is the version of the KV secrets engine, 1 or 2 (default)
+	KVVersion int `json:"kv_version" yaml:"kv_version"`
+	// Timeout bounds each request, defaults to DefaultRemoteTimeout
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+// VaultSecretProvider reads secrets from a Vault KV secrets engine. A
+// reference is "mount/path#key", e.g. "secret/db#password" reads the
+// password key of the db secret in the secret mount.
+type VaultSecretProvider struct {
+	cfg    VaultConfig
+	client *http.Client
+}
+
+// NewVaultSecretProvider creates a Vault secret provider
+func NewVaultSecretProvider(cfg VaultConfig) (*VaultSecretProvider, error) {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if cfg.Address == "" {
+		return nil, errors.New("vault address is required")
+	}
+	if cfg.KVVersion == 0 {
+		cfg.KVVersion = 2
+	}
+	if cfg.KVVersion != 1 && cfg.KVVersion != 2 {
+		return nil, fmt.Errorf("unsupported vault kv version %d", cfg.KVVersion)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultRemoteTimeout
+	}
+	cfg.Address = strings.TrimSuffix(cfg.Address, "/")
+	return &VaultSecretProvider{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+// Resolve implements SecretProvider
+func (p *VaultSecretProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	path, key := splitSecretRef(ref)
+	path = strings.Trim(path, "/")
+	if p.cfg.KVVersion == 2 {
+		// KV v2 serves secrets under mount/data/path
+		mount, rest, ok := strings.Cut(path, "/")
+		if !ok {
+			return "", fmt.Errorf("invalid vault secret path %q, want mount/path", path)
+		}
+		path = mount + "/data/" + rest
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.Address+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.cfg.Token)
+	if p.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.cfg.Namespace)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", ErrSecretNotFound
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("vault request failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+	data := body.Data
+	if p.cfg.KVVersion == 2 {
+		data, _ = body.Data["data"].(map[string]interface{})
+	}
+	return secretKey(data, key)
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+)
+
+// Source provides configuration merged into a struct by Loader.Load, in
+// the order the sources are given to WithSources
+type Source interface {
+	// Name identifies the source in errors, e.g. "file" or "etcd"
+	Name() string
+	// Load merges the source into cfg, a pointer to a struct
+	Load(cfg any) error
+}
+
+// WatchableSource is a Source that reports its changes, used by Loader.Watch
+type WatchableSource interface {
+	Source
+	// Watch starts watching in the background and returns once the watch is
+	// set up. onChange is called after every change and onError with errors
+	// met while watching; both stop when ctx is done.
+	Watch(ctx context.Context, onChange func(), onError func(error)) error
+}
+
+// errFileGone is returned by a file source whose file disappeared, e.g.
+// for a moment while an editor replaces it
+var errFileGone = errors.New("config file is missing")
+
+// WithSources replaces the default sources, the config files of
+// WithConfigPaths then the environment, e.g.
+//
+//	etcd, err := config.NewEtcdSource(config.EtcdConfig{Endpoints: endpoints, Key: "/config/order-api"})
+//	...
+//	config.WithSources(config.FileSource("config.yaml"), etcd, config.EnvSource("APP"))
+func WithSources(sources ...Source) Option {
+	return func(l *Loader) {
+		l.sources = sources
+	}
+}
+
+// activeSources returns the configured sources or the default file and env ones
+func (l *Loader) activeSources() []Source {
+	if l.sources != nil {
+		return l.sources
+	}
+	return []Source{l.fileSource(), EnvSource(l.envPrefix)}
+}
+
+// fileSource returns the file source of the loader, shared between Load
+// and Watch so that both use the same file
+func (l *Loader) fileSource() *yamlFileSource {
+	l.fileOnce.Do(func() {
+		l.file = &yamlFileSource{paths: l.configPaths, profile: l.Profile()}
+	})
+	return l.file
+}
+
+// yamlFileSource reads the first existing YAML file of a list, overlaid
+// with the file of its profile
+type yamlFileSource struct {
+	paths   []string
+	profile string
+
+	mu      sync.Mutex
+	found   string
+	overlay bool
+}
+
+// FileSource reads the first existing file of paths as YAML; no existing
+// file is not an error, so env vars or defaults can provide everything
+func FileSource(paths ...string) WatchableSource {
+	return &yamlFileSource{paths: paths}
+}
+
+// ProfileFileSource reads the first existing file of paths like FileSource,
+// then overlays the file of profile next to it, e.g. config.prod.yaml for
+// config.yaml; a missing profile file is not an error
+func ProfileFileSource(profile string, paths ...string) WatchableSource {
+	return &yamlFileSource{paths: paths, profile: profile}
+}
+
+// Name implements Source
+func (s *yamlFileSource) Name() string {
+	return "file"
+}
+
+// path returns the file to read; once one was found it stays the file of
+// the source
+func (s *yamlFileSource) path() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.found != "" {
+		if _, err := os.Stat(s.found); err != nil {
+			return "", errFileGone
+		}
+		return s.found, nil
+	}
+	for _, path := range s.paths {
+		if _, err := os.Stat(path); err == nil {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve config file %s: %w", path, err)
+			}
+			s.found = abs
+			return abs, nil
+		}
+	}
+	return "", nil
+}
+
+// overlayPath returns the profile file of path, or "" when it is missing
+func (s *yamlFileSource) overlayPath(path string) (string, error) {
+	if s.profile == "" {
+		return "", nil
+	}
+	if strings.ContainsAny(s.profile, `/\`) {
+		return "", fmt.Errorf("invalid config profile %q", s.profile)
+	}
+	ext := filepath.Ext(path)
+	overlay := strings.TrimSuffix(path, ext) + "." + s.profile + ext
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := os.Stat(overlay); err != nil {
+		// Once read, the profile file may only be missing while it is replaced
+		if s.overlay {
+			return "", errFileGone
+		}
+		return "", nil
+	}
+	s.overlay = true
+	return overlay, nil
+}
+
+// Load implements Source
+func (s *yamlFileSource) Load(cfg any) error {
+	path, err := s.path()
+	if err != nil || path == "" {
+		// No config file found, that's okay - we'll rely on env vars or defaults
+		return err
+	}
+	overlay, err := s.overlayPath(path)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range []string{path, overlay} {
+		if file == "" {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read config file %s: %w", file, err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// Watch implements WatchableSource. It watches the directory of the file,
+// which holds its profile file too: editors and Kubernetes ConfigMaps
+// replace the file instead of writing to it, which drops a watch on the
+// file itself.
+func (s *yamlFileSource) Watch(ctx context.Context, onChange func(), onError func(error)) error {
+	path, err := s.path()
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return ErrNoConfigFile
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	if err := fsw.Add(filepath.Dir(path)); err != nil {
+		fsw.Close()
+		return fmt.Errorf("failed to watch config file %s: %w", path, err)
+	}
+
+	go func() {
+		defer fsw.Close()
+		for {
+			select {
+			case _, ok := <-fsw.Events:
+				if !ok {
+					return
+				}
+				// Any event in the directory may replace the file, e.g. the
+				// ..data symlink swap of a ConfigMap
+				onChange()
+			case err, ok := <-fsw.Errors:
+				if !ok {
+					return
+				}
+				onError(fmt.Errorf("config watcher: %w", err))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// envSource reads environment variables
+type envSource struct {
+	loader *Loader
+}
+
+// EnvSource reads environment variables named after the yaml tags, or env
+// tags, of the fields, preceded by prefix when set
+func EnvSource(prefix string) Source {
+	return &envSource{loader: &Loader{envPrefix: prefix}}
+}
+
+// Name implements Source
+func (s *envSource) Name() string {
+	return "env"
+}
+
+// Load implements Source
+func (s *envSource) Load(cfg any) error {
+	return s.loader.loadFromEnv(cfg)
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConsulWaitTime is how long a Consul blocking query waits for a change
+const DefaultConsulWaitTime = 5 * time.Minute
+
+// ConsulConfig configures a Consul KV config source
+type ConsulConfig struct {
+	// Address is the Consul agent URL, e.g. http://127.0.0.1:8500
+	Address string `json:"address" yaml:"address"`
+	// Key holds the YAML (or JSON) document, e.g. config/order-api
+	Key string `json:"key" yaml:"key"`
+	// Token is the ACL token
+	Token string `json:"token" yaml:"token"`
+	// Datacenter defaults to the datacenter of the agent
+	Datacenter string `json:"datacenter" yaml:"datacenter"`
+	// WaitTime bounds each blocking query, defaults to DefaultConsulWaitTime
+	WaitTime time.Duration `json:"wait_time" yaml:"wait_time"`
+	// Timeout bounds each request, defaults to DefaultRemoteTimeout
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// RetryDelay is the wait before a failed query is retried, defaults to
+	// DefaultRemoteRetryDelay
+	RetryDelay time.Duration `json:"retry_delay" yaml:"retry_delay"`
+}
+
+// ConsulSource reads a configuration document from a Consul KV key and
+// watches it with blocking queries for hot reload
+type ConsulSource struct {
+	cfg    ConsulConfig
+	client *http.Client
+
+	mu    sync.Mutex
+	index uint64
+}
+
+// NewConsulSource creates a Consul KV config source
+func NewConsulSource(cfg ConsulConfig) (*ConsulSource, error) {
+	if cfg.Address == "" || cfg.Key == "" {
+		return nil, errors.New("consul source: address and key are required")
+	}
+	if cfg.WaitTime <= 0 {
+		cfg.WaitTime = DefaultConsulWaitTime
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultRemoteTimeout
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = DefaultRemoteRetryDelay
+	}
+	cfg.Address = strings.TrimSuffix(cfg.Address, "/")
+	cfg.Key = strings.TrimPrefix(cfg.Key, "/")
+	return &ConsulSource{cfg: cfg, client: &http.Client{}}, nil
+}
+
+// Name implements Source
+func (s *ConsulSource) Name() string {
+	return "consul"
+}
+
+// Load implements Source; a missing key leaves cfg unchanged
+func (s *ConsulSource) Load(cfg any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+
+	value, index, err := s.get(ctx, 0)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.index = index
+	s.mu.Unlock()
+
+	if value == nil {
+		return nil
+	}
+	if err := yaml.Unmarshal(value, cfg); err != nil {
+		return fmt.Errorf("failed to parse key %s: %w", s.cfg.Key, err)
+	}
+	return nil
+}
+
+// Watch implements WatchableSource. Each blocking query returns when the
+// index of the key moves past the last one seen, or after WaitTime.
+func (s *ConsulSource) Watch(ctx context.Context, onChange func(), onError func(error)) error {
+	go func() {
+		for ctx.Err() == nil {
+			s.mu.Lock()
+			last := s.index
+			s.mu.Unlock()
+
+			reqCtx, cancel := context.WithTimeout(ctx, s.cfg.WaitTime+s.cfg.Timeout)
+			_, index, err := s.get(reqCtx, last)
+			cancel()
+			if err != nil {
+				if ctx.Err() == nil {
+					onError(err)
+				}
+				select {
+				case <-ctx.Done():
+				case <-time.After(s.cfg.RetryDelay):
+				}
+				continue
+			}
+
+			s.mu.Lock()
+			// An index going backwards means the raft state was reset
+			if index < last {
+				index = 0
+			}
+			s.index = index
+			s.mu.Unlock()
+			if index > last {
+				onChange()
+			}
+			if index == 0 {
+				// Nothing to block on, avoid a busy loop
+				select {
+				case <-ctx.Done():
+				case <-time.After(s.cfg.RetryDelay):
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// get reads the key, blocking until its index passes index when non-zero;
+// value is nil when the key does not exist
+func (s *ConsulSource) get(ctx context.Context, index uint64) ([]byte, uint64, error) {
+	query := url.Values{}
+	if s.cfg.Datacenter != "" {
+		query.Set("dc", s.cfg.Datacenter)
+	}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", strconv.FormatInt(s.cfg.WaitTime.Milliseconds(), 10)+"ms")
+	}
+	u := s.cfg.Address + "/v1/kv/" + s.cfg.Key
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if s.cfg.Token != "" {
+		req.Header.Set("X-Consul-Token", s.cfg.Token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("consul request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, next, nil
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, 0, fmt.Errorf("consul request failed: status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	// Value is []byte so encoding/json decodes its base64
+	var pairs []struct {
+		Value []byte `json:"Value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, 0, fmt.Errorf("invalid consul response: %w", err)
+	}
+	if len(pairs) == 0 {
+		return nil, next, nil
+	}
+	return pairs[0].Value, next, nil
+}
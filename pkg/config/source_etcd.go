@@ -0,0 +1,344 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultRemoteTimeout bounds each request to a remote config source
+	DefaultRemoteTimeout = 5 * time.Second
+	// DefaultRemoteRetryDelay is the wait before a failed watch is resumed
+	DefaultRemoteRetryDelay = 2 * time.Second
+)
+
+// EtcdConfig configures an etcd config source
+type EtcdConfig struct {
+	// Endpoints are the etcd client URLs, tried in order, e.g. http://etcd-0:2379
+	Endpoints []string `json:"endpoints" yaml:"endpoints"`
+	// Key holds the YAML (or JSON) document, e.g. /config/order-api
+	Key string `json:"key" yaml:"key"`
+	// Username and Password enable etcd authentication
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	// Timeout bounds each request, defaults to DefaultRemoteTimeout
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// RetryDelay is the wait before a broken watch is resumed, defaults to
+	// DefaultRemoteRetryDelay
+	RetryDelay time.Duration `json:"retry_delay" yaml:"retry_delay"`
+}
+
+// EtcdSource reads a configuration document from an etcd v3 key through
+// the etcd JSON gateway and watches it for hot reload
+type EtcdSource struct {
+	cfg    EtcdConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	token    string
+	revision int64
+}
+
+// NewEtcdSource creates an etcd config source
+func NewEtcdSource(cfg EtcdConfig) (*EtcdSource, error) {
+	if len(cfg.Endpoints) == 0 || cfg.Key == "" {
+		return nil, errors.New("etcd source: endpoints and key are required")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultRemoteTimeout
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = DefaultRemoteRetryDelay
+	}
+	for i, endpoint := range cfg.Endpoints {
+		cfg.Endpoints[i] = strings.TrimSuffix(endpoint, "/")
+	}
+	return &EtcdSource{cfg: cfg, client: &http.Client{}}, nil
+}
+
+// Name implements Source
+func (s *EtcdSource) Name() string {
+	return "etcd"
+}
+
+// etcdKeyValue is a key-value pair of the JSON gateway; bytes are base64
+// encoded and 64-bit integers are strings
+type etcdKeyValue struct {
+	Value       string `json:"value"`
+	ModRevision string `json:"mod_revision"`
+}
+
+// etcdHeader is the response header of the JSON gateway
+type etcdHeader struct {
+	Revision string `json:"revision"`
+}
+
+// Load implements Source; a missing key leaves cfg unchanged
+func (s *EtcdSource) Load(cfg any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+
+	var resp struct {
+		Header etcdHeader     `json:"header"`
+		Kvs    []etcdKeyValue `json:"kvs"`
+	}
+	body := map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(s.cfg.Key))}
+	if err := s.post(ctx, "/v3/kv/range", body, &resp); err != nil {
+		return err
+	}
+
+	revision, _ := strconv.ParseInt(resp.Header.Revision, 10, 64)
+	s.mu.Lock()
+	s.revision = revision
+	s.mu.Unlock()
+
+	if len(resp.Kvs) == 0 {
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Kvs[0].Value)
+	if err != nil {
+		return fmt.Errorf("invalid value of key %s: %w", s.cfg.Key, err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse key %s: %w", s.cfg.Key, err)
+	}
+	return nil
+}
+
+// errCompacted is returned by watch when etcd compacted the revision it
+// was to resume from
+var errCompacted = errors.New("watch revision compacted")
+
+// Watch implements WatchableSource, resuming from the last revision seen
+// after a broken stream so that no change is missed. When that revision was
+// compacted the key is read afresh and the watch resumes from its revision.
+func (s *EtcdSource) Watch(ctx context.Context, onChange func(), onError func(error)) error {
+	go func() {
+		for {
+			err := s.watch(ctx, onChange)
+			if errors.Is(err, errCompacted) {
+				if err = s.resync(ctx, onChange); err == nil {
+					continue
+				}
+			}
+			if err != nil && ctx.Err() == nil {
+				onError(err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.cfg.RetryDelay):
+			}
+		}
+	}()
+	return nil
+}
+
+// watch follows one watch stream until it breaks
+func (s *EtcdSource) watch(ctx context.Context, onChange func()) error {
+	s.mu.Lock()
+	start := s.revision + 1
+	s.mu.Unlock()
+
+	req := map[string]interface{}{
+		"create_request": map[string]string{
+			"key":            base64.StdEncoding.EncodeToString([]byte(s.cfg.Key)),
+			"start_revision": strconv.FormatInt(start, 10),
+		},
+	}
+	resp, err := s.do(ctx, "/v3/watch", req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var msg struct {
+			Result struct {
+				Header          etcdHeader `json:"header"`
+				Canceled        bool       `json:"canceled"`
+				CompactRevision string     `json:"compact_revision"`
+				Events          []struct {
+					Kv etcdKeyValue `json:"kv"`
+				} `json:"events"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return fmt.Errorf("invalid watch response: %w", err)
+		}
+		if msg.Error != nil {
+			return fmt.Errorf("watch failed: %s", msg.Error.Message)
+		}
+		if compacted, _ := strconv.ParseInt(msg.Result.CompactRevision, 10, 64); compacted > 0 {
+			return fmt.Errorf("%w: %d", errCompacted, compacted)
+		}
+		if msg.Result.Canceled {
+			return errors.New("watch canceled by server")
+		}
+		if len(msg.Result.Events) == 0 {
+			continue
+		}
+
+		if revision, err := strconv.ParseInt(msg.Result.Header.Revision, 10, 64); err == nil {
+			s.mu.Lock()
+			s.revision = revision
+			s.mu.Unlock()
+		}
+		onChange()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("watch stream: %w", err)
+	}
+	return errors.New("watch stream closed")
+}
+
+// resync reads the key's current revision after the watch revision was
+// compacted, so watching resumes from there, and reloads the configuration
+// since changes in between are lost
+func (s *EtcdSource) resync(ctx context.Context, onChange func()) error {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	var resp struct {
+		Header etcdHeader `json:"header"`
+	}
+	body := map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(s.cfg.Key))}
+	if err := s.post(ctx, "/v3/kv/range", body, &resp); err != nil {
+		return fmt.Errorf("resync after compaction: %w", err)
+	}
+	revision, err := strconv.ParseInt(resp.Header.Revision, 10, 64)
+	if err != nil {
+		return fmt.Errorf("resync after compaction: invalid revision %q", resp.Header.Revision)
+	}
+
+	s.mu.Lock()
+	s.revision = revision
+	s.mu.Unlock()
+	onChange()
+	return nil
+}
+
+// post sends a JSON request with the request timeout and decodes the response
+func (s *EtcdSource) post(ctx context.Context, path string, body, out interface{}) error {
+	resp, err := s.do(ctx, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", path, err)
+	}
+	return nil
+}
+
+// do sends a request to the first endpoint that answers, authenticating
+// first when credentials are set and again once the token expired
+func (s *EtcdSource) do(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, endpoint := range s.cfg.Endpoints {
+		resp, err := s.send(ctx, endpoint, path, payload)
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && s.cfg.Username != "" {
+			resp.Body.Close()
+			s.setToken("")
+			resp, err = s.send(ctx, endpoint, path, payload)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			resp.Body.Close()
+			errs = append(errs, fmt.Errorf("%s%s: status %d: %s", endpoint, path, resp.StatusCode, bytes.TrimSpace(msg)))
+			continue
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("etcd request failed: %w", errors.Join(errs...))
+}
+
+// send performs one request against an endpoint
+func (s *EtcdSource) send(ctx context.Context, endpoint, path string, payload []byte) (*http.Response, error) {
+	token, err := s.authenticate(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	return s.client.Do(req)
+}
+
+// authenticate returns the auth token, requesting one when none is cached
+func (s *EtcdSource) authenticate(ctx context.Context, endpoint string) (string, error) {
+	if s.cfg.Username == "" {
+		return "", nil
+	}
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+	if token != "" {
+		return token, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	payload, _ := json.Marshal(map[string]string{"name": s.cfg.Username, "password": s.cfg.Password})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/v3/auth/authenticate", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("etcd authentication failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("etcd authentication failed: status %d", resp.StatusCode)
+	}
+
+	var auth struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return "", fmt.Errorf("invalid etcd authentication response: %w", err)
+	}
+	s.setToken(auth.Token)
+	return auth.Token, nil
+}
+
+// setToken caches the auth token
+func (s *EtcdSource) setToken(token string) {
+	s.mu.Lock()
+	s.token = token
+	s.mu.Unlock()
+}
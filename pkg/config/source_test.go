@@ -0,0 +1,282 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"mora/pkg/utils"
+)
+
+// fakeKV is a key holding a document, shared by the etcd and Consul servers
+type fakeKV struct {
+	mu        sync.Mutex
+	value     string
+	revision  int64
+	compacted int64
+	changed   chan struct{}
+}
+
+func newFakeKV(value string) *fakeKV {
+	return &fakeKV{value: value, revision: 1, changed: make(chan struct{})}
+}
+
+func (kv *fakeKV) get() (string, int64, chan struct{}) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	return kv.value, kv.revision, kv.changed
+}
+
+func (kv *fakeKV) set(value string) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	kv.value = value
+	kv.revision++
+	close(kv.changed)
+	kv.changed = make(chan struct{})
+}
+
+// compact discards the history up to the current revision
+func (kv *fakeKV) compact() {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	kv.compacted = kv.revision
+}
+
+// compactedAt returns the revision history was compacted up to
+func (kv *fakeKV) compactedAt() int64 {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	return kv.compacted
+}
+
+// newEtcdServer serves the parts of the etcd JSON gateway used by EtcdSource
+func newEtcdServer(t *testing.T, kv *fakeKV) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/auth/authenticate":
+			json.NewEncoder(w).Encode(map[string]string{"token": "tok"})
+		case "/v3/kv/range":
+			if r.Header.Get("Authorization") != "tok" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			value, revision, _ := kv.get()
+			rev := strconv.FormatInt(revision, 10)
+			fmt.Fprintf(w, `{"header":{"revision":%q},"kvs":[{"value":%q,"mod_revision":%q}]}`,
+				rev, base64.StdEncoding.EncodeToString([]byte(value)), rev)
+		case "/v3/watch":
+			var req struct {
+				CreateRequest struct {
+					StartRevision string `json:"start_revision"`
+				} `json:"create_request"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			start, _ := strconv.ParseInt(req.CreateRequest.StartRevision, 10, 64)
+			_, revision, changed := kv.get()
+			if compacted := kv.compactedAt(); start <= compacted {
+				fmt.Fprintf(w, `{"result":{"header":{"revision":"%d"},"canceled":true,"compact_revision":"%d"}}`+"\n", revision, compacted)
+				return
+			}
+			fmt.Fprintf(w, `{"result":{"header":{"revision":"%d"},"created":true}}`+"\n", revision)
+			w.(http.Flusher).Flush()
+			// Changes since start_revision are replayed like etcd does
+			if start > revision {
+				select {
+				case <-changed:
+				case <-r.Context().Done():
+					return
+				}
+				_, revision, _ = kv.get()
+			}
+			fmt.Fprintf(w, `{"result":{"header":{"revision":"%d"},"events":[{"kv":{}}]}}`+"\n", revision)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newConsulServer serves the KV endpoint of Consul with blocking queries
+func newConsulServer(t *testing.T, kv *fakeKV) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/kv/missing" {
+			w.Header().Set("X-Consul-Index", "1")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Path != "/v1/kv/config/app" || r.Header.Get("X-Consul-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		value, revision, changed := kv.get()
+		if index, _ := strconv.ParseInt(r.URL.Query().Get("index"), 10, 64); index >= revision {
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+				return
+			}
+			value, revision, _ = kv.get()
+		}
+		w.Header().Set("X-Consul-Index", strconv.FormatInt(revision, 10))
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"Key": "config/app", "Value": []byte(value)}})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRemoteSources(t *testing.T) {
+	etcdKV := newFakeKV("log:\n  level: info\n")
+	consulKV := newFakeKV(`{"log": {"level": "info"}}`)
+	etcdSrv := newEtcdServer(t, etcdKV)
+	consulSrv := newConsulServer(t, consulKV)
+
+	etcd, err := NewEtcdSource(EtcdConfig{
+		// The first endpoint is down, the source moves on to the next one
+		Endpoints: []string{"http://127.0.0.1:1", etcdSrv.URL},
+		Key:       "/config/app",
+		Username:  "root",
+		Password:  "pass",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	consul, err := NewConsulSource(ConsulConfig{Address: consulSrv.URL, Key: "config/app", Token: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		source WatchableSource
+		kv     *fakeKV
+		update string
+	}{
+		{name: "etcd", source: etcd, kv: etcdKV, update: "log:\n  level: debug\n"},
+		{name: "consul", source: consul, kv: consulKV, update: `{"log": {"level": "debug"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reloads := make(chan []utils.Change, 4)
+			w, err := NewLoader(WithSources(tt.source), WithWatchDebounce(10*time.Millisecond)).
+				Watch(&watchConfig{}, func(_ any, changes []utils.Change) { reloads <- changes })
+			if err != nil {
+				t.Fatalf("Watch() error = %v", err)
+			}
+			defer w.Close()
+
+			if got := w.Config().(*watchConfig).Log.Level; got != "info" {
+				t.Fatalf("Load() level = %q, want info", got)
+			}
+
+			tt.kv.set(tt.update)
+			select {
+			case changes := <-reloads:
+				if len(changes) != 1 || changes[0].Path != "log.level" || changes[0].New != "debug" {
+					t.Errorf("changes = %v, want log.level -> debug", changes)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("no reload after the key changed")
+			}
+		})
+	}
+}
+
+func TestEtcdSourceCompacted(t *testing.T) {
+	kv := newFakeKV("log:\n  level: info\n")
+	etcd, err := NewEtcdSource(EtcdConfig{Endpoints: []string{newEtcdServer(t, kv).URL}, Key: "/config/app", Username: "root", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := etcd.Load(&watchConfig{}); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// The revision the watch resumes from is gone
+	kv.set("log:\n  level: debug\n")
+	kv.set("log:\n  level: warn\n")
+	kv.compact()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{}, 4)
+	errs := make(chan error, 4)
+	if err := etcd.Watch(ctx, func() { changes <- struct{}{} }, func(err error) { errs <- err }); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	waitChange := func(what string) {
+		t.Helper()
+		select {
+		case <-changes:
+		case err := <-errs:
+			t.Fatalf("%s: watch error = %v", what, err)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: no change reported", what)
+		}
+	}
+
+	waitChange("after compaction")
+	var cfg watchConfig
+	if err := etcd.Load(&cfg); err != nil || cfg.Log.Level != "warn" {
+		t.Fatalf("Load() after compaction = %v, level %q, want warn", err, cfg.Log.Level)
+	}
+
+	kv.set("log:\n  level: error\n")
+	waitChange("after resuming")
+}
+
+func TestRemoteSourceErrors(t *testing.T) {
+	consulSrv := newConsulServer(t, newFakeKV(""))
+
+	if _, err := NewEtcdSource(EtcdConfig{Key: "/config/app"}); err == nil {
+		t.Error("NewEtcdSource() without endpoints should fail")
+	}
+	if _, err := NewConsulSource(ConsulConfig{Address: consulSrv.URL}); err == nil {
+		t.Error("NewConsulSource() without a key should fail")
+	}
+
+	missing, _ := NewConsulSource(ConsulConfig{Address: consulSrv.URL, Key: "missing"})
+	cfg := &watchConfig{}
+	cfg.Log.Level = "warn"
+	if err := NewLoader(WithSources(missing)).Load(cfg); err != nil || cfg.Log.Level != "warn" {
+		t.Errorf("Load() of a missing key = %v, level %q, want no error and defaults", err, cfg.Log.Level)
+	}
+
+	forbidden, _ := NewConsulSource(ConsulConfig{Address: consulSrv.URL, Key: "config/app"})
+	if err := NewLoader(WithSources(forbidden)).Load(&watchConfig{}); err == nil {
+		t.Error("Load() with a rejected token should fail")
+	}
+}
+
+func TestWithSourcesOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("log:\n  level: info\nlimits:\n  rps: 10\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_LOG_LEVEL", "error")
+
+	var cfg watchConfig
+	if err := NewLoader(WithSources(FileSource(path), EnvSource("APP"))).Load(&cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Log.Level != "error" || cfg.Limits.RPS != 10 {
+		t.Errorf("Load() = %+v, want the env to override the file", cfg)
+	}
+
+	if _, err := NewLoader(WithSources(EnvSource("APP"))).Watch(&watchConfig{}, nil); err == nil {
+		t.Error("Watch() without a watchable source should fail")
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mora/pkg/utils"
+)
+
+// DefaultWatchDebounce is how long the config file must stay unchanged
+// before it is reloaded, so editors writing in several steps trigger one reload
+const DefaultWatchDebounce = 100 * time.Millisecond
+
+// ErrNoConfigFile is returned by Watch when none of the config paths exists
+// or no source can be watched
+var ErrNoConfigFile = errors.New("no config file to watch")
+
+// WithWatchDebounce sets how long Watch waits for writes to settle
+func WithWatchDebounce(d time.Duration) Option {
+	return func(l *Loader) {
+		l.watchDebounce = d
+	}
+}
+
+// WithWatchErrorHandler sets the function called when a reload fails; the
+// previous configuration stays in effect
+func WithWatchErrorHandler(fn func(error)) Option {
+	return func(l *Loader) {
+		l.onWatchError = fn
+	}
+}
+
+// fieldCallback is a change callback registered for a path
+type fieldCallback struct {
+	path string
+	fn   func(utils.Change)
+}
+
+// Watcher reloads a configuration when one of its sources changes. Every
+// reload builds a new value, so a configuration returned by Config is never
+// modified and can be read without locking.
+type Watcher struct {
+	loader   *Loader
+	sources  []Source
+	defaults any
+	onChange func(cfg any, changes []utils.Change)
+
+	current atomic.Value
+	// mu serializes reloads and guards fields
+	mu     sync.Mutex
+	fields []fieldCallback
+
+	cancel    context.CancelFunc
+	debouncer *utils.Debouncer[struct{}]
+	closeOnce sync.Once
+}
+
+// Watch loads cfg, a pointer to a struct, like Load and then watches every
+// WatchableSource, by default the config file it was read from. On every
+// change all sources are loaded again into a new value of the same type,
+// starting from the values cfg held before loading, and installed
+// atomically: Config returns it, and onChange, when set, receives it with
+// the changed fields as reported by Diff, secrets masked. cfg itself is
+// never modified after Watch returns.
+// Failed reloads are reported to the WithWatchErrorHandler handler and
+// leave the current configuration in effect.
+func (l *Loader) Watch(cfg any, onChange func(cfg any, changes []utils.Change)) (*Watcher, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a non-nil pointer to a struct, got %T", cfg)
+	}
+
+	defaults := utils.DeepCopy(cfg)
+	if err := l.Load(cfg); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Watcher{
+		loader:   l,
+		sources:  l.activeSources(),
+		defaults: defaults,
+		onChange: onChange,
+		cancel:   cancel,
+	}
+	w.current.Store(cfg)
+
+	wait := l.watchDebounce
+	if wait <= 0 {
+		wait = DefaultWatchDebounce
+	}
+	w.debouncer = utils.NewDebouncer(wait, func(struct{}) { w.Reload() })
+
+	watched := false
+	for _, source := range w.sources {
+		ws, ok := source.(WatchableSource)
+		if !ok {
+			continue
+		}
+		trigger := func() { w.debouncer.Call(struct{}{}) }
+		report := func(err error) { w.report(fmt.Errorf("config %s: %w", source.Name(), err)) }
+		if err := ws.Watch(ctx, trigger, report); err != nil {
+			w.Close()
+			return nil, fmt.Errorf("failed to watch config %s: %w", source.Name(), err)
+		}
+		watched = true
+	}
+	if !watched {
+		w.Close()
+		return nil, ErrNoConfigFile
+	}
+	return w, nil
+}
+
+// Config returns the current configuration, a pointer of the type passed
+// to Watch
+func (w *Watcher) Config() any {
+	return w.current.Load()
+}
+
+// OnFieldChange registers fn for changes of a field, or of any field below
+// it, named by its path in utils.Diff form, e.g. "logger.level" or "limits"
+func (w *Watcher) OnFieldChange(path string, fn func(utils.Change)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.fields = append(w.fields, fieldCallback{path: path, fn: fn})
+}
+
+// Reload loads every source again and installs the result when it differs
+// from the current configuration. It is called by the watcher and can be
+// called directly, e.g. on SIGHUP.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next := utils.DeepCopy(w.defaults)
+	for _, source := range w.sources {
+		if err := source.Load(next); err != nil {
+			// The file may be missing for a moment while it is replaced
+			if errors.Is(err, errFileGone) {
+				return nil
+			}
+			return w.report(fmt.Errorf("failed to load config from %s: %w", source.Name(), err))
+		}
+	}
+	if err := w.loader.finish(next); err != nil {
+		return w.report(err)
+	}
+
+	changes := Diff(w.current.Load(), next)
+	if len(changes) == 0 {
+		return nil
+	}
+	w.current.Store(next)
+
+	if w.onChange != nil {
+		w.onChange(next, changes)
+	}
+	for _, change := range changes {
+		for _, cb := range w.fields {
+			if change.Path == cb.path || strings.HasPrefix(change.Path, cb.path+".") || strings.HasPrefix(change.Path, cb.path+"[") {
+				cb.fn(change)
+			}
+		}
+	}
+	return nil
+}
+
+// report passes err to the error handler and returns it
+func (w *Watcher) report(err error) error {
+	if w.loader.onWatchError != nil {
+		w.loader.onWatchError(err)
+	}
+	return err
+}
+
+// Close stops watching; the current configuration stays available
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		w.cancel()
+		w.debouncer.Stop()
+	})
+	return nil
+}
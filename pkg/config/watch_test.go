@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"mora/pkg/utils"
+)
+
+type watchConfig struct {
+	Log struct {
+		Level string `yaml:"level" json:"level"`
+	} `yaml:"log" json:"log"`
+	Limits struct {
+		RPS   int `yaml:"rps" json:"rps"`
+		Burst int `yaml:"burst" json:"burst"`
+	} `yaml:"limits" json:"limits"`
+	Flags map[string]bool `yaml:"flags" json:"flags"`
+}
+
+// writeConfig replaces the file like an editor does, through a rename
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "log:\n  level: info\nlimits:\n  rps: 10\n")
+
+	cfg := &watchConfig{}
+	cfg.Limits.Burst = 5 // default kept across reloads
+
+	reloads := make(chan []utils.Change, 4)
+	w, err := NewLoader(WithConfigPaths(path), WithWatchDebounce(10*time.Millisecond)).
+		Watch(cfg, func(_ any, changes []utils.Change) { reloads <- changes })
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer w.Close()
+
+	var mu sync.Mutex
+	var levels []utils.Change
+	w.OnFieldChange("log", func(c utils.Change) {
+		mu.Lock()
+		defer mu.Unlock()
+		levels = append(levels, c)
+	})
+
+	writeConfig(t, path, "log:\n  level: debug\nlimits:\n  rps: 10\nflags:\n  beta: true\n")
+
+	select {
+	case changes := <-reloads:
+		if len(changes) != 2 {
+			t.Errorf("changes = %v, want log.level and flags.beta", changes)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no reload after the file changed")
+	}
+
+	got := w.Config().(*watchConfig)
+	if got.Log.Level != "debug" || !got.Flags["beta"] || got.Limits.Burst != 5 {
+		t.Errorf("Config() = %+v", got)
+	}
+	if cfg.Log.Level != "info" {
+		t.Errorf("cfg was modified: level = %q", cfg.Log.Level)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(levels) != 1 || levels[0].Path != "log.level" || levels[0].Old != "info" || levels[0].New != "debug" {
+		t.Errorf("field changes = %v, want log.level: info -> debug", levels)
+	}
+}
+
+func TestWatchReload(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantErr     bool
+		wantChanged bool
+	}{
+		{name: "unchanged", content: "limits:\n  rps: 10\n"},
+		{name: "same values", content: "limits:\n  rps: 10 # comment\n"},
+		{name: "invalid", content: "limits: [", wantErr: true},
+		{name: "changed", content: "limits:\n  rps: 20\n", wantChanged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			writeConfig(t, path, "limits:\n  rps: 10\n")
+
+			var reported error
+			changed := false
+			w, err := NewLoader(WithConfigPaths(path), WithWatchDebounce(time.Hour), WithWatchErrorHandler(func(err error) { reported = err })).
+				Watch(&watchConfig{}, func(any, []utils.Change) { changed = true })
+			if err != nil {
+				t.Fatalf("Watch() error = %v", err)
+			}
+			defer w.Close()
+
+			writeConfig(t, path, tt.content)
+			err = w.Reload()
+			if (err != nil) != tt.wantErr || (reported != nil) != tt.wantErr {
+				t.Errorf("Reload() error = %v, reported %v, wantErr %v", err, reported, tt.wantErr)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !tt.wantChanged && w.Config().(*watchConfig).Limits.RPS != 10 {
+				t.Errorf("config replaced: %+v", w.Config())
+			}
+		})
+	}
+}
+
+func TestWatchErrors(t *testing.T) {
+	if _, err := NewLoader(WithConfigPaths(filepath.Join(t.TempDir(), "missing.yaml"))).Watch(&watchConfig{}, nil); !errors.Is(err, ErrNoConfigFile) {
+		t.Errorf("Watch() without a file error = %v, want ErrNoConfigFile", err)
+	}
+	if _, err := NewLoader().Watch(watchConfig{}, nil); err == nil {
+		t.Error("Watch() with a non-pointer should fail")
+	}
+}
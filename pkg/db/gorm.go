@@ -11,6 +11,9 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+
+	"mora/pkg/retry"
 )
 
 // Config holds database configuration
@@ -21,6 +24,18 @@ type Config struct {
 	MaxIdleConns    int    `json:"max_idle_conns" yaml:"max_idle_conns" env:"MAX_IDLE_CONNS"`
 	ConnMaxLifetime int    `json:"conn_max_lifetime" yaml:"conn_max_lifetime" env:"CONN_MAX_LIFETIME"` // seconds
 	LogLevel        string `json:"log_level" yaml:"log_level" env:"LOG_LEVEL"`                         // silent, error, warn, info
+	// ConnectAttempts includes the first try, so services starting next to
+	// their database wait for it instead of failing; 0 tries once
+	ConnectAttempts int `json:"connect_attempts" yaml:"connect_attempts" env:"CONNECT_ATTEMPTS"`
+	// ConnectBackoff is the first delay between attempts, doubled each time
+	ConnectBackoff time.Duration `json:"connect_backoff" yaml:"connect_backoff" env:"CONNECT_BACKOFF"`
+	// Tracing starts a span per statement with the global tracer provider
+	Tracing bool `json:"tracing" yaml:"tracing" env:"TRACING"`
+	// Replicas are the DSNs of read replicas. Reads outside transactions go
+	// to them and everything else to DSN, the primary.
+	Replicas []string `json:"replicas" yaml:"replicas" env:"REPLICAS"`
+	// ReplicaPolicy picks the replica of each read: random or round_robin
+	ReplicaPolicy string `json:"replica_policy" yaml:"replica_policy" env:"REPLICA_POLICY"`
 }
 
 // DefaultConfig returns default database configuration
@@ -32,6 +47,20 @@ func DefaultConfig() Config {
 		MaxIdleConns:    5,
 		ConnMaxLifetime: 3600, // 1 hour
 		LogLevel:        "warn",
+		ConnectAttempts: 3,
+		ConnectBackoff:  time.Second,
+		ReplicaPolicy:   PolicyRandom,
+	}
+}
+
+// connectRetry returns the retry policy of the initial connection
+func (cfg Config) connectRetry() retry.Config {
+	return retry.Config{
+		MaxAttempts: max(1, cfg.ConnectAttempts),
+		Backoff:     cfg.ConnectBackoff,
+		MaxBackoff:  8 * cfg.ConnectBackoff,
+		Multiplier:  2,
+		Jitter:      0.2,
 	}
 }
 
@@ -42,17 +71,15 @@ type Client struct {
 
 // New creates a new database client using GORM
 func New(cfg Config) (*Client, error) {
-	var dialector gorm.Dialector
-
-	switch cfg.Driver {
-	case "mysql":
-		dialector = mysql.Open(cfg.DSN)
-	case "postgres":
-		dialector = postgres.Open(cfg.DSN)
-	case "sqlite":
-		dialector = sqlite.Open(cfg.DSN)
-	default:
-		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
+	dialector, err := openDialector(cfg.Driver, cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	var resolver *dbresolver.DBResolver
+	if len(cfg.Replicas) > 0 {
+		if resolver, err = cfg.resolver(); err != nil {
+			return nil, err
+		}
 	}
 
 	// Configure GORM logger
@@ -70,12 +97,24 @@ func New(cfg Config) (*Client, error) {
 		logLevel = logger.Warn
 	}
 
-	db, err := gorm.Open(dialector, &gorm.Config{
-		Logger: logger.Default.LogMode(logLevel),
+	db, err := retry.DoValue(context.Background(), cfg.connectRetry(), func(ctx context.Context) (*gorm.DB, error) {
+		return gorm.Open(dialector, &gorm.Config{
+			Logger: logger.Default.LogMode(logLevel),
+		})
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	if resolver != nil {
+		if err := db.Use(resolver); err != nil {
+			return nil, fmt.Errorf("failed to configure replicas: %w", err)
+		}
+	}
+	if cfg.Tracing {
+		if err := db.Use(TracingPlugin()); err != nil {
+			return nil, fmt.Errorf("failed to install tracing plugin: %w", err)
+		}
+	}
 
 	// Get underlying sql.DB for connection pool configuration
 	sqlDB, err := db.DB()
@@ -91,6 +130,19 @@ func New(cfg Config) (*Client, error) {
 	return &Client{db: db}, nil
 }
 
+// openDialector returns the GORM dialector of a driver
+func openDialector(driver, dsn string) (gorm.Dialector, error) {
+	switch driver {
+	case "mysql":
+		return mysql.Open(dsn), nil
+	case "postgres":
+		return postgres.Open(dsn), nil
+	case "sqlite":
+		return sqlite.Open(dsn), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver: %s", driver)
+}
+
 // DB returns the underlying GORM DB instance
 func (c *Client) DB() *gorm.DB {
 	return c.db
@@ -0,0 +1,297 @@
+// Package migrate runs versioned schema migrations. Migrations are SQL files,
+// read from a directory or an embed.FS, or Go functions; applied versions
+// are recorded in a schema_migrations table, and each migration runs in a
+// transaction together with its record.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrUnknownVersion is returned by Force for a version no migration has
+var ErrUnknownVersion = errors.New("migrate: unknown version")
+
+// Conn runs the statements of a migration: its transaction, or the
+// database itself for NoTx migrations
+type Conn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Migration is one schema change. Up applies it and Down reverts it; Down
+// may be nil for changes that cannot be reverted.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(ctx context.Context, conn Conn) error
+	Down    func(ctx context.Context, conn Conn) error
+	// NoTx runs the migration outside a transaction, for statements such as
+	// CREATE INDEX CONCURRENTLY that refuse one; a failure then leaves the
+	// change half done, to be fixed by hand before calling Force
+	NoTx bool
+}
+
+// Status is the state of one migration
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Config configures a Migrator
+type Config struct {
+	// Table records the applied versions
+	Table string `json:"table" yaml:"table" env:"TABLE"`
+	// Driver selects the placeholder syntax: mysql, postgres or sqlite.
+	// MySQL DSNs need parseTime=true, and multiStatements=true for SQL files
+	// holding several statements.
+	Driver string `json:"driver" yaml:"driver" env:"DRIVER"`
+}
+
+// DefaultConfig returns default migration configuration
+func DefaultConfig() Config {
+	return Config{
+		Table:  "schema_migrations",
+		Driver: "mysql",
+	}
+}
+
+// Migrator applies migrations to a database. Run one migrator at a time,
+// e.g. from a deploy job; concurrent runs are not coordinated.
+type Migrator struct {
+	db         *sql.DB
+	cfg        Config
+	migrations []Migration
+}
+
+// New creates a migrator of db running migrations, which may come from
+// several sources; versions must be unique
+func New(db *sql.DB, cfg Config, migrations ...Migration) (*Migrator, error) {
+	if cfg.Table == "" {
+		cfg.Table = DefaultConfig().Table
+	}
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, m := range sorted {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("migrate: %s: version must be positive", m.Name)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("migrate: duplicate version %d: %s and %s", m.Version, sorted[i-1].Name, m.Name)
+		}
+		if m.Up == nil {
+			return nil, fmt.Errorf("migrate: %d %s: no up migration", m.Version, m.Name)
+		}
+	}
+	return &Migrator{db: db, cfg: cfg, migrations: sorted}, nil
+}
+
+// Up applies every pending migration in version order and returns how many
+// ran; it stops at the first failure
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		if err := m.run(ctx, mig, true); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Down reverts the last applied migration; it returns false when none is
+func (m *Migrator) Down(ctx context.Context) (bool, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return false, err
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if mig.Down == nil {
+			return false, fmt.Errorf("migrate: %d %s: no down migration", mig.Version, mig.Name)
+		}
+		return true, m.run(ctx, mig, false)
+	}
+	return false, nil
+}
+
+// Force records the migrations up to version as applied and the later ones
+// as pending, running none of them, e.g. to recover once a failed NoTx
+// migration has been fixed by hand; version 0 marks all as pending
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	known := version == 0
+	for _, mig := range m.migrations {
+		known = known || mig.Version == version
+	}
+	if !known {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migrate: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+m.cfg.Table); err != nil {
+		return fmt.Errorf("migrate: failed to clear versions: %w", err)
+	}
+	for _, mig := range m.migrations {
+		if mig.Version > version {
+			break
+		}
+		if err := m.record(ctx, tx, mig); err != nil {
+			return fmt.Errorf("migrate: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Status returns the state of every migration in version order
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, len(m.migrations))
+	for i, mig := range m.migrations {
+		at, ok := applied[mig.Version]
+		statuses[i] = Status{Version: mig.Version, Name: mig.Name, Applied: ok, AppliedAt: at}
+	}
+	return statuses, nil
+}
+
+// run applies (up) or reverts one migration with its record
+func (m *Migrator) run(ctx context.Context, mig Migration, up bool) error {
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+	wrap := func(err error) error {
+		return fmt.Errorf("migrate: %d %s %s: %w", mig.Version, mig.Name, direction, err)
+	}
+
+	fn := mig.Down
+	if up {
+		fn = mig.Up
+	}
+	if mig.NoTx {
+		if err := fn(ctx, m.db); err != nil {
+			return wrap(err)
+		}
+		if err := m.mark(ctx, m.db, mig, up); err != nil {
+			return wrap(err)
+		}
+		return nil
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrap(err)
+	}
+	defer tx.Rollback()
+	if err := fn(ctx, tx); err != nil {
+		return wrap(err)
+	}
+	if err := m.mark(ctx, tx, mig, up); err != nil {
+		return wrap(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return wrap(err)
+	}
+	return nil
+}
+
+// mark records mig as applied (up) or removes its record
+func (m *Migrator) mark(ctx context.Context, conn Conn, mig Migration, up bool) error {
+	if up {
+		return m.record(ctx, conn, mig)
+	}
+	if _, err := conn.ExecContext(ctx, m.deleteQuery(), mig.Version); err != nil {
+		return fmt.Errorf("failed to remove version %d: %w", mig.Version, err)
+	}
+	return nil
+}
+
+// record marks mig as applied
+func (m *Migrator) record(ctx context.Context, conn Conn, mig Migration) error {
+	if _, err := conn.ExecContext(ctx, m.insertQuery(), mig.Version, mig.Name, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to record version %d: %w", mig.Version, err)
+	}
+	return nil
+}
+
+// applied returns the applied versions with when they were applied
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := m.db.QueryContext(ctx, "SELECT version, applied_at FROM "+m.cfg.Table)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: failed to read versions: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("migrate: failed to read versions: %w", err)
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+// ensureTable creates the version table when missing
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	query := "CREATE TABLE IF NOT EXISTS " + m.cfg.Table + ` (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	applied_at TIMESTAMP NOT NULL
+)`
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("migrate: failed to create %s: %w", m.cfg.Table, err)
+	}
+	return nil
+}
+
+// insertQuery returns the statement recording a version
+func (m *Migrator) insertQuery() string {
+	return "INSERT INTO " + m.cfg.Table + " (version, name, applied_at) VALUES (" +
+		m.placeholder(1) + ", " + m.placeholder(2) + ", " + m.placeholder(3) + ")"
+}
+
+// deleteQuery returns the statement removing a version
+func (m *Migrator) deleteQuery() string {
+	return "DELETE FROM " + m.cfg.Table + " WHERE version = " + m.placeholder(1)
+}
+
+// placeholder returns the n-th bind parameter of the driver
+func (m *Migrator) placeholder(n int) string {
+	if m.cfg.Driver == "postgres" || m.cfg.Driver == "pgx" {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
@@ -0,0 +1,185 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestParseFileName(t *testing.T) {
+	tests := []struct {
+		file        string
+		wantVersion int64
+		wantName    string
+		wantUp      bool
+		wantErr     bool
+	}{
+		{file: "0001_create_users.up.sql", wantVersion: 1, wantName: "create_users", wantUp: true},
+		{file: "20240102150405_add_index.down.sql", wantVersion: 20240102150405, wantName: "add_index"},
+		{file: "0003.up.sql", wantVersion: 3, wantUp: true},
+		{file: "0004_missing_direction.sql", wantErr: true},
+		{file: "users.up.sql", wantErr: true},
+		{file: "0000_zero.up.sql", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			version, name, up, err := parseFileName(tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFileName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (version != tt.wantVersion || name != tt.wantName || up != tt.wantUp) {
+				t.Errorf("parseFileName() = %d, %q, %v", version, name, up)
+			}
+		})
+	}
+}
+
+func TestFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0002_add_email.up.sql":      {Data: []byte("-- migrate:no-transaction\nALTER TABLE users ADD COLUMN email TEXT;")},
+		"migrations/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY);")},
+		"migrations/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"migrations/README.md":                  {Data: []byte("not a migration")},
+	}
+
+	migrations, err := FromFS(fsys, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("len(migrations) = %d, want 2", len(migrations))
+	}
+	byVersion := map[int64]Migration{}
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+	if m := byVersion[1]; m.Name != "create_users" || m.Up == nil || m.Down == nil || m.NoTx {
+		t.Errorf("migration 1 = %+v", m)
+	}
+	if m := byVersion[2]; m.Down != nil || !m.NoTx {
+		t.Errorf("migration 2 = %+v, want no down and NoTx", m)
+	}
+
+	fsys["migrations/0003_orphan.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE x;")}
+	if _, err := FromFS(fsys, "migrations"); err == nil {
+		t.Error("a down file without up file should fail")
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	files, err := FromFS(fstest.MapFS{
+		"0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);")},
+		"0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+	}, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := Migration{
+		Version: 2,
+		Name:    "seed_admin",
+		Up: func(ctx context.Context, conn Conn) error {
+			_, err := conn.ExecContext(ctx, "INSERT INTO users (name) VALUES (?)", "admin")
+			return err
+		},
+		Down: func(ctx context.Context, conn Conn) error {
+			_, err := conn.ExecContext(ctx, "DELETE FROM users WHERE name = ?", "admin")
+			return err
+		},
+	}
+	cfg := Config{Driver: "sqlite"}
+	m, err := New(db, cfg, append(files, seed)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := m.Up(ctx); err != nil || n != 2 {
+		t.Fatalf("Up() = %d, %v, want 2", n, err)
+	}
+	if n, err := m.Up(ctx); err != nil || n != 0 {
+		t.Fatalf("second Up() = %d, %v, want 0", n, err)
+	}
+	assertApplied(t, m, true, true)
+
+	if ok, err := m.Down(ctx); err != nil || !ok {
+		t.Fatalf("Down() = %v, %v", ok, err)
+	}
+	assertApplied(t, m, true, false)
+	var users int
+	db.QueryRow("SELECT COUNT(*) FROM users").Scan(&users)
+	if users != 0 {
+		t.Errorf("users = %d after reverting the seed", users)
+	}
+
+	t.Run("failure rolls back", func(t *testing.T) {
+		broken := Migration{
+			Version: 3,
+			Name:    "broken",
+			Up: func(ctx context.Context, conn Conn) error {
+				if _, err := conn.ExecContext(ctx, "CREATE TABLE audit (id INTEGER)"); err != nil {
+					return err
+				}
+				return errors.New("boom")
+			},
+		}
+		m, err := New(db, cfg, append(files, seed, broken)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := m.Up(ctx); err == nil || n != 1 {
+			t.Fatalf("Up() = %d, %v, want the seed applied then a failure", n, err)
+		}
+		assertApplied(t, m, true, true, false)
+		if _, err := db.Exec("SELECT * FROM audit"); err == nil {
+			t.Error("table of the failed migration should be rolled back")
+		}
+	})
+
+	t.Run("force", func(t *testing.T) {
+		if err := m.Force(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		assertApplied(t, m, true, false)
+		if err := m.Force(ctx, 7); !errors.Is(err, ErrUnknownVersion) {
+			t.Errorf("Force(7) error = %v, want ErrUnknownVersion", err)
+		}
+	})
+
+	t.Run("duplicate versions", func(t *testing.T) {
+		if _, err := New(db, cfg, seed, seed); err == nil {
+			t.Error("duplicate versions should fail")
+		}
+	})
+}
+
+// assertApplied checks the applied state of each migration in order
+func assertApplied(t *testing.T, m *Migrator, want ...bool) {
+	t.Helper()
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("len(Status()) = %d, want %d", len(statuses), len(want))
+	}
+	for i, s := range statuses {
+		if s.Applied != want[i] {
+			t.Errorf("migration %d applied = %v, want %v", s.Version, s.Applied, want[i])
+		}
+		if s.Applied && s.AppliedAt.IsZero() {
+			t.Errorf("migration %d has no applied time", s.Version)
+		}
+	}
+}
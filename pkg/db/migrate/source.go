@@ -0,0 +1,103 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// NoTxDirective, as the first line of a SQL file, runs it outside a
+// transaction
+const NoTxDirective = "-- migrate:no-transaction"
+
+// FromDir reads the SQL migrations of a directory, see FromFS
+func FromDir(dir string) ([]Migration, error) {
+	return FromFS(os.DirFS(dir), ".")
+}
+
+// FromFS reads the SQL migrations of dir in fsys, e.g. an embed.FS. Files
+// are named <version>_<name>.up.sql and <version>_<name>.down.sql, e.g.
+// 0001_create_users.up.sql; the down file is optional. Each file runs as one
+// Exec, so its statements must be accepted together by the driver.
+func FromFS(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: failed to read %s: %w", dir, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	var versions []int64
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		version, name, up, err := parseFileName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("migrate: failed to read %s: %w", entry.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: name}
+			byVersion[version] = mig
+			versions = append(versions, version)
+		} else if mig.Name != name {
+			return nil, fmt.Errorf("migrate: version %d has two names: %s and %s", version, mig.Name, name)
+		}
+
+		query := string(data)
+		run := func(ctx context.Context, conn Conn) error {
+			_, err := conn.ExecContext(ctx, query)
+			return err
+		}
+		noTx := strings.HasPrefix(strings.TrimSpace(query), NoTxDirective)
+		if up {
+			if mig.Up != nil {
+				return nil, fmt.Errorf("migrate: version %d has two up files", version)
+			}
+			mig.Up, mig.NoTx = run, noTx
+		} else {
+			if mig.Down != nil {
+				return nil, fmt.Errorf("migrate: version %d has two down files", version)
+			}
+			mig.Down = run
+		}
+	}
+
+	migrations := make([]Migration, 0, len(versions))
+	for _, version := range versions {
+		mig := byVersion[version]
+		if mig.Up == nil {
+			return nil, fmt.Errorf("migrate: version %d %s has no up file", version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	return migrations, nil
+}
+
+// parseFileName splits <version>_<name>.(up|down).sql
+func parseFileName(file string) (version int64, name string, up bool, err error) {
+	base := strings.TrimSuffix(file, ".sql")
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		base, up = strings.TrimSuffix(base, ".up"), true
+	case strings.HasSuffix(base, ".down"):
+		base = strings.TrimSuffix(base, ".down")
+	default:
+		return 0, "", false, fmt.Errorf("migrate: %s: want a .up.sql or .down.sql suffix", file)
+	}
+	v, name, _ := strings.Cut(base, "_")
+	version, err = strconv.ParseInt(v, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", false, fmt.Errorf("migrate: %s: want a positive version prefix", file)
+	}
+	return version, name, up, nil
+}
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Default List page sizes
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Filter matches records whose columns equal the given values; a slice
+// value matches any of its elements
+type Filter map[string]interface{}
+
+// ListOptions selects a page of records
+type ListOptions struct {
+	Filter Filter
+	// Sort orders by columns, "-" prefixed for descending, e.g. "-created_at"
+	Sort []string
+	// Page starts at 1; PageSize defaults to DefaultPageSize and is capped
+	// at MaxPageSize
+	Page     int
+	PageSize int
+}
+
+// Page is one page of records with the total count
+type Page[T any] struct {
+	Data       []T   `json:"data"`
+	Total      int64 `json:"total"`
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// Repository provides typed CRUD for the model T. Filter and sort columns
+// are checked against the model schema, so they may come from requests.
+// Missing records return gorm.ErrRecordNotFound.
+type Repository[T any] struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a repository of T on client
+func NewRepository[T any](client *Client) *Repository[T] {
+	return &Repository[T]{db: client.db}
+}
+
+// WithTx returns a repository running within tx
+func (r *Repository[T]) WithTx(tx *Transaction) *Repository[T] {
+	return &Repository[T]{db: tx.tx}
+}
+
+// Create inserts entity, filling in its primary key
+func (r *Repository[T]) Create(ctx context.Context, entity *T) error {
+	return r.db.WithContext(ctx).Create(entity).Error
+}
+
+// Find gets the record with primary key id
+func (r *Repository[T]) Find(ctx context.Context, id interface{}) (*T, error) {
+	var entity T
+	if err := r.db.WithContext(ctx).First(&entity, r.idCondition(id)).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// FindOne gets the first record, by primary key, matching filter
+func (r *Repository[T]) FindOne(ctx context.Context, filter Filter) (*T, error) {
+	query, err := r.where(r.db.WithContext(ctx), filter)
+	if err != nil {
+		return nil, err
+	}
+	var entity T
+	if err := query.First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// UpdateFields sets columns of the record with primary key id, e.g.
+// {"status": "paid"}; zero values are written too, unlike Updates with a
+// struct. Updating a missing record is not an error, as drivers such as
+// MySQL do not tell it from an update changing nothing.
+func (r *Repository[T]) UpdateFields(ctx context.Context, id interface{}, fields map[string]interface{}) error {
+	if err := r.checkColumns(mapKeys(fields)); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Model(new(T)).Where(r.idCondition(id)).Updates(fields).Error
+}
+
+// DeleteByID deletes the record with primary key id, softly when T has a
+// gorm.DeletedAt field
+func (r *Repository[T]) DeleteByID(ctx context.Context, id interface{}) error {
+	res := r.db.WithContext(ctx).Where(r.idCondition(id)).Delete(new(T))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// List gets a page of the records matching opts.Filter
+func (r *Repository[T]) List(ctx context.Context, opts ListOptions) (*Page[T], error) {
+	page, pageSize := opts.Page, opts.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	pageSize = min(pageSize, MaxPageSize)
+
+	query, err := r.where(r.db.WithContext(ctx).Model(new(T)), opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count records: %w", err)
+	}
+
+	order, err := r.orderBy(opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]T, 0, pageSize)
+	if err := query.Clauses(order).Offset((page - 1) * pageSize).Limit(pageSize).Find(&data).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch paginated data: %w", err)
+	}
+
+	return &Page[T]{
+		Data:       data,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}, nil
+}
+
+// where adds filter to query
+func (r *Repository[T]) where(query *gorm.DB, filter Filter) (*gorm.DB, error) {
+	if len(filter) == 0 {
+		return query, nil
+	}
+	if err := r.checkColumns(mapKeys(filter)); err != nil {
+		return nil, err
+	}
+	return query.Where(map[string]interface{}(filter)), nil
+}
+
+// orderBy converts sort to an ORDER BY clause, by primary key by default so
+// that pages are stable
+func (r *Repository[T]) orderBy(sorts []string) (clause.OrderBy, error) {
+	var order clause.OrderBy
+	if len(sorts) == 0 {
+		if pk := r.schema().PrioritizedPrimaryField; pk != nil {
+			order.Columns = append(order.Columns, clause.OrderByColumn{Column: clause.Column{Name: pk.DBName}})
+		}
+		return order, nil
+	}
+	for _, s := range sorts {
+		column, desc := strings.TrimPrefix(s, "-"), strings.HasPrefix(s, "-")
+		if err := r.checkColumns([]string{column}); err != nil {
+			return order, err
+		}
+		order.Columns = append(order.Columns, clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc})
+	}
+	return order, nil
+}
+
+// idCondition matches the primary key of T
+func (r *Repository[T]) idCondition(id interface{}) clause.Expression {
+	name := "id"
+	if pk := r.schema().PrioritizedPrimaryField; pk != nil {
+		name = pk.DBName
+	}
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: name}, Value: id}
+}
+
+// checkColumns rejects columns T does not have
+func (r *Repository[T]) checkColumns(columns []string) error {
+	s := r.schema()
+	for _, column := range columns {
+		if _, ok := s.FieldsByDBName[column]; !ok {
+			return fmt.Errorf("unknown column %q of %s", column, s.Name)
+		}
+	}
+	return nil
+}
+
+// schema returns the parsed schema of T, cached by GORM
+func (r *Repository[T]) schema() *schema.Schema {
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		// T is not a valid model; GORM reports it on every query too
+		return &schema.Schema{Name: fmt.Sprintf("%T", *new(T)), FieldsByDBName: map[string]*schema.Field{}}
+	}
+	return stmt.Schema
+}
+
+// mapKeys returns the sorted keys of m
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
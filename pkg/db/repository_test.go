@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// product is a GORM model for the repository test
+type product struct {
+	ID        uint
+	Name      string
+	Category  string
+	Price     int
+	DeletedAt gorm.DeletedAt
+}
+
+func TestRepository(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Driver, cfg.DSN, cfg.LogLevel = "sqlite", filepath.Join(t.TempDir(), "repo.db"), "silent"
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.AutoMigrate(&product{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	repo := NewRepository[product](client)
+	for i, name := range []string{"pen", "pencil", "mug", "plate", "cup"} {
+		category := "stationery"
+		if i >= 2 {
+			category = "kitchen"
+		}
+		if err := repo.Create(ctx, &product{Name: name, Category: category, Price: (i + 1) * 10}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("find", func(t *testing.T) {
+		p, err := repo.Find(ctx, 3)
+		if err != nil || p.Name != "mug" {
+			t.Fatalf("Find(3) = %+v, %v", p, err)
+		}
+		if _, err := repo.Find(ctx, 99); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("Find(99) error = %v, want ErrRecordNotFound", err)
+		}
+		p, err = repo.FindOne(ctx, Filter{"category": "kitchen", "price": 40})
+		if err != nil || p.Name != "plate" {
+			t.Errorf("FindOne() = %+v, %v", p, err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			opts      ListOptions
+			wantNames []string
+			wantTotal int64
+			wantPages int
+			wantErr   bool
+		}{
+			{name: "default", opts: ListOptions{}, wantNames: []string{"pen", "pencil", "mug", "plate", "cup"}, wantTotal: 5, wantPages: 1},
+			{name: "filtered and sorted", opts: ListOptions{Filter: Filter{"category": "kitchen"}, Sort: []string{"-price"}},
+				wantNames: []string{"cup", "plate", "mug"}, wantTotal: 3, wantPages: 1},
+			{name: "in", opts: ListOptions{Filter: Filter{"name": []string{"pen", "cup"}}}, wantNames: []string{"pen", "cup"}, wantTotal: 2, wantPages: 1},
+			{name: "second page", opts: ListOptions{Sort: []string{"name"}, Page: 2, PageSize: 2},
+				wantNames: []string{"pen", "pencil"}, wantTotal: 5, wantPages: 3},
+			{name: "unknown sort column", opts: ListOptions{Sort: []string{"price; DROP TABLE products"}}, wantErr: true},
+			{name: "unknown filter column", opts: ListOptions{Filter: Filter{"secret": 1}}, wantErr: true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page, err := repo.List(ctx, tt.opts)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("List() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+				var names []string
+				for _, p := range page.Data {
+					names = append(names, p.Name)
+				}
+				if len(names) != len(tt.wantNames) || page.Total != tt.wantTotal || page.TotalPages != tt.wantPages {
+					t.Fatalf("List() = %v, total %d, pages %d", names, page.Total, page.TotalPages)
+				}
+				for i := range names {
+					if names[i] != tt.wantNames[i] {
+						t.Errorf("List() = %v, want %v", names, tt.wantNames)
+						break
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		if err := repo.UpdateFields(ctx, 1, map[string]interface{}{"price": 0}); err != nil {
+			t.Fatal(err)
+		}
+		if p, _ := repo.Find(ctx, 1); p.Price != 0 {
+			t.Errorf("price = %d after update, want 0", p.Price)
+		}
+		if err := repo.UpdateFields(ctx, 1, map[string]interface{}{"owner": "x"}); err == nil {
+			t.Error("updating an unknown column should fail")
+		}
+
+		if err := repo.DeleteByID(ctx, 2); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.Find(ctx, 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("Find() after delete error = %v", err)
+		}
+		if err := repo.DeleteByID(ctx, 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("second DeleteByID() error = %v, want ErrRecordNotFound", err)
+		}
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		err := client.WithTransaction(ctx, func(tx *Transaction) error {
+			if err := repo.WithTx(tx).Create(ctx, &product{Name: "bowl", Category: "kitchen"}); err != nil {
+				return err
+			}
+			return errors.New("abort")
+		})
+		if err == nil {
+			t.Fatal("transaction should fail")
+		}
+		if _, err := repo.FindOne(ctx, Filter{"name": "bowl"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("rolled back record found, error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// Replica policies
+const (
+	PolicyRandom     = "random"
+	PolicyRoundRobin = "round_robin"
+)
+
+// resolver returns the GORM plugin sending reads to the replicas
+func (cfg Config) resolver() (*dbresolver.DBResolver, error) {
+	var policy dbresolver.Policy
+	switch cfg.ReplicaPolicy {
+	case "", PolicyRandom:
+		policy = dbresolver.RandomPolicy{}
+	case PolicyRoundRobin:
+		policy = dbresolver.RoundRobinPolicy()
+	default:
+		return nil, fmt.Errorf("unsupported replica policy: %s", cfg.ReplicaPolicy)
+	}
+
+	replicas := make([]gorm.Dialector, len(cfg.Replicas))
+	for i, dsn := range cfg.Replicas {
+		dialector, err := openDialector(cfg.Driver, dsn)
+		if err != nil {
+			return nil, err
+		}
+		replicas[i] = dialector
+	}
+
+	return dbresolver.Register(dbresolver.Config{Replicas: replicas, Policy: policy}).
+		SetMaxOpenConns(cfg.MaxOpenConns).
+		SetMaxIdleConns(cfg.MaxIdleConns).
+		SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second), nil
+}
+
+// ReadDB returns a GORM DB instance whose statements go to a replica, the
+// primary when there is none
+func (c *Client) ReadDB() *gorm.DB {
+	return c.db.Clauses(dbresolver.Read)
+}
+
+// WriteDB returns a GORM DB instance whose statements go to the primary,
+// e.g. to read back a row just written before replicas catch up
+func (c *Client) WriteDB() *gorm.DB {
+	return c.db.Clauses(dbresolver.Write)
+}
+
+// replicaPicker picks the replica of each read
+type replicaPicker func(n int) int
+
+// newReplicaPicker returns the picker of a policy
+func newReplicaPicker(policy string) (replicaPicker, error) {
+	switch policy {
+	case "", PolicyRandom:
+		return rand.IntN, nil
+	case PolicyRoundRobin:
+		var next atomic.Uint64
+		return func(n int) int { return int((next.Add(1) - 1) % uint64(n)) }, nil
+	}
+	return nil, fmt.Errorf("unsupported replica policy: %s", policy)
+}
+
+// ReadDB returns the sqlx DB instance of a replica, the primary when there
+// is none
+func (c *SQLXClient) ReadDB() *sqlx.DB {
+	if len(c.replicas) == 0 {
+		return c.db
+	}
+	return c.replicas[c.pick(len(c.replicas))]
+}
+
+// WriteDB returns the sqlx DB instance of the primary
+func (c *SQLXClient) WriteDB() *sqlx.DB {
+	return c.db
+}
+
+// dbFor returns where query runs: SELECT statements that do not lock rows
+// go to a replica, the rest to the primary, like the GORM resolver
+func (c *SQLXClient) dbFor(query string) *sqlx.DB {
+	if isReadQuery(query) {
+		return c.ReadDB()
+	}
+	return c.db
+}
+
+// isReadQuery reports whether query only reads
+func isReadQuery(query string) bool {
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\n")
+	return len(query) > 6 && strings.EqualFold(query[:6], "select") &&
+		!(len(query) > 10 && strings.EqualFold(query[len(query)-10:], "for update"))
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsReadQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "SELECT * FROM users", want: true},
+		{query: "  select id from users where id = ?;\n", want: true},
+		{query: "SELECT * FROM users WHERE id = ? FOR UPDATE", want: false},
+		{query: "INSERT INTO users (name) VALUES (?) RETURNING id", want: false},
+		{query: "UPDATE users SET name = ?", want: false},
+		{query: "select", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := isReadQuery(tt.query); got != tt.want {
+				t.Errorf("isReadQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplicaPicker(t *testing.T) {
+	pick, err := newReplicaPicker(PolicyRoundRobin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int{0, 1, 2, 0, 1} {
+		if got := pick(3); got != want {
+			t.Errorf("pick %d = %d, want %d", i, got, want)
+		}
+	}
+
+	pick, _ = newReplicaPicker(PolicyRandom)
+	for i := 0; i < 10; i++ {
+		if got := pick(2); got < 0 || got > 1 {
+			t.Fatalf("random pick = %d, out of range", got)
+		}
+	}
+
+	if _, err := newReplicaPicker("nearest"); err == nil {
+		t.Error("unknown policy should fail")
+	}
+}
+
+// seedNames creates a users table holding name in the sqlite file at path
+func seedNames(t *testing.T, path, name string) {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Driver, cfg.DSN, cfg.LogLevel = "sqlite", path, "silent"
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.AutoMigrate(&user{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Create(context.Background(), &user{Name: name}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadWriteSplitting(t *testing.T) {
+	dir := t.TempDir()
+	primary, replica := filepath.Join(dir, "primary.db"), filepath.Join(dir, "replica.db")
+	seedNames(t, primary, "primary")
+	seedNames(t, replica, "replica")
+	ctx := context.Background()
+
+	t.Run("gorm", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Driver, cfg.DSN, cfg.LogLevel, cfg.Replicas = "sqlite", primary, "silent", []string{replica}
+		client, err := New(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		var read, written user
+		if err := client.ReadDB().WithContext(ctx).First(&read).Error; err != nil || read.Name != "replica" {
+			t.Errorf("ReadDB() read %q, %v, want replica", read.Name, err)
+		}
+		if err := client.WriteDB().WithContext(ctx).First(&written).Error; err != nil || written.Name != "primary" {
+			t.Errorf("WriteDB() read %q, %v, want primary", written.Name, err)
+		}
+	})
+
+	t.Run("sqlx", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Driver, cfg.DSN, cfg.Replicas, cfg.ReplicaPolicy = "sqlite3", primary, []string{replica}, PolicyRoundRobin
+		client, err := NewSQLX(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		var name string
+		if err := client.Get(ctx, &name, "SELECT name FROM users LIMIT 1"); err != nil || name != "replica" {
+			t.Errorf("Get() = %q, %v, want replica", name, err)
+		}
+		if _, err := client.Exec(ctx, "UPDATE users SET name = ?", "updated"); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.WriteDB().GetContext(ctx, &name, "SELECT name FROM users LIMIT 1"); err != nil || name != "updated" {
+			t.Errorf("primary name = %q, %v, want updated", name, err)
+		}
+		if err := client.Ping(); err != nil {
+			t.Errorf("Ping() error = %v", err)
+		}
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Driver, cfg.DSN, cfg.LogLevel = "sqlite", primary, "silent"
+		cfg.Replicas, cfg.ReplicaPolicy = []string{replica}, "nearest"
+		if _, err := New(cfg); err == nil {
+			t.Error("New() with an unknown policy should fail")
+		}
+	})
+}
@@ -10,17 +10,49 @@ import (
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
+
+	"mora/pkg/retry"
 )
 
 // SQLXClient wraps sqlx database instance
 type SQLXClient struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	replicas []*sqlx.DB
+	pick     replicaPicker
+	tracing  bool
 }
 
-// NewSQLX creates a new database client using sqlx
+// NewSQLX creates a new database client using sqlx. With Config.Replicas,
+// SELECT queries go to the replicas and other statements to the primary.
 func NewSQLX(cfg Config) (*SQLXClient, error) {
-	db, err := sqlx.Connect(cfg.Driver, cfg.DSN)
+	pick, err := newReplicaPicker(cfg.ReplicaPolicy)
 	if err != nil {
+		return nil, err
+	}
+	db, err := openSQLX(cfg, cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	c := &SQLXClient{db: db, pick: pick, tracing: cfg.Tracing}
+	for _, dsn := range cfg.Replicas {
+		replica, err := openSQLX(cfg, dsn)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("replica: %w", err)
+		}
+		c.replicas = append(c.replicas, replica)
+	}
+	return c, nil
+}
+
+// openSQLX connects to dsn and configures its connection pool
+func openSQLX(cfg Config, dsn string) (*sqlx.DB, error) {
+	db, err := sqlx.Open(cfg.Driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := retry.Do(context.Background(), cfg.connectRetry(), db.PingContext); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
@@ -29,7 +61,7 @@ func NewSQLX(cfg Config) (*SQLXClient, error) {
 	db.SetMaxIdleConns(cfg.MaxIdleConns)
 	db.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
 
-	return &SQLXClient{db: db}, nil
+	return db, nil
 }
 
 // DB returns the underlying sqlx DB instance
@@ -37,17 +69,31 @@ func (c *SQLXClient) DB() *sqlx.DB {
 	return c.db
 }
 
-// Close closes the database connection
+// Close closes the database connections
 func (c *SQLXClient) Close() error {
-	return c.db.Close()
+	err := c.db.Close()
+	for _, replica := range c.replicas {
+		if closeErr := replica.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
 }
 
-// Ping tests the database connection
+// Ping tests the connections to the primary and the replicas
 func (c *SQLXClient) Ping() error {
-	return c.db.Ping()
+	if err := c.db.Ping(); err != nil {
+		return err
+	}
+	for _, replica := range c.replicas {
+		if err := replica.Ping(); err != nil {
+			return fmt.Errorf("replica: %w", err)
+		}
+	}
+	return nil
 }
 
-// Stats returns database connection pool statistics
+// Stats returns primary connection pool statistics
 func (c *SQLXClient) Stats() sql.DBStats {
 	return c.db.Stats()
 }
@@ -118,37 +164,55 @@ func (c *SQLXClient) WithTransaction(ctx context.Context, fn func(*SQLXTransacti
 
 // Get gets a single record into dest
 func (c *SQLXClient) Get(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	return c.db.GetContext(ctx, dest, query, args...)
+	ctx, end := c.span(ctx, "get", query)
+	err := c.dbFor(query).GetContext(ctx, dest, query, args...)
+	end(err)
+	return err
 }
 
 // Select gets multiple records into dest
 func (c *SQLXClient) Select(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	return c.db.SelectContext(ctx, dest, query, args...)
+	ctx, end := c.span(ctx, "select", query)
+	err := c.dbFor(query).SelectContext(ctx, dest, query, args...)
+	end(err)
+	return err
 }
 
 // Exec executes a query without returning any rows
 func (c *SQLXClient) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	return c.db.ExecContext(ctx, query, args...)
+	ctx, end := c.span(ctx, "exec", query)
+	res, err := c.db.ExecContext(ctx, query, args...)
+	end(err)
+	return res, err
 }
 
 // Query executes a query that returns rows
 func (c *SQLXClient) Query(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
-	return c.db.QueryxContext(ctx, query, args...)
+	ctx, end := c.span(ctx, "query", query)
+	rows, err := c.dbFor(query).QueryxContext(ctx, query, args...)
+	end(err)
+	return rows, err
 }
 
 // QueryRow executes a query that is expected to return at most one row
 func (c *SQLXClient) QueryRow(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
-	return c.db.QueryRowxContext(ctx, query, args...)
+	return c.dbFor(query).QueryRowxContext(ctx, query, args...)
 }
 
 // NamedExec executes a named query
 func (c *SQLXClient) NamedExec(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
-	return c.db.NamedExecContext(ctx, query, arg)
+	ctx, end := c.span(ctx, "exec", query)
+	res, err := c.db.NamedExecContext(ctx, query, arg)
+	end(err)
+	return res, err
 }
 
 // NamedQuery executes a named query that returns rows
 func (c *SQLXClient) NamedQuery(ctx context.Context, query string, arg interface{}) (*sqlx.Rows, error) {
-	return c.db.NamedQueryContext(ctx, query, arg)
+	ctx, end := c.span(ctx, "query", query)
+	rows, err := c.dbFor(query).NamedQueryContext(ctx, query, arg)
+	end(err)
+	return rows, err
 }
 
 // Prepared Statements
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"mora/pkg/tracing"
+)
+
+// spanKey stores the span of a GORM statement between its callbacks
+const spanKey = "mora:tracing_span"
+
+// dbSystem returns the db.system attribute of a driver name
+func dbSystem(driver string) attribute.KeyValue {
+	switch driver {
+	case "mysql":
+		return semconv.DBSystemMySQL
+	case "postgres", "pgx":
+		return semconv.DBSystemPostgreSQL
+	case "sqlite", "sqlite3":
+		return semconv.DBSystemSqlite
+	}
+	return semconv.DBSystemKey.String(driver)
+}
+
+// startSpan starts a client span for one database operation
+func startSpan(ctx context.Context, driver, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "db."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(dbSystem(driver), semconv.DBOperation(operation)))
+}
+
+// endSpan ends a database span; a missing row is a result, not a failure
+func endSpan(span trace.Span, err error) {
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	tracing.End(span, err)
+}
+
+// tracingPlugin starts a span around every GORM create, query, update,
+// delete, row and raw operation
+type tracingPlugin struct{}
+
+// TracingPlugin returns a GORM plugin tracing statements with the global
+// tracer provider; New installs it when Config.Tracing is set, or add it
+// to your own instance with db.Use(TracingPlugin())
+func TracingPlugin() gorm.Plugin {
+	return tracingPlugin{}
+}
+
+// Name implements gorm.Plugin
+func (tracingPlugin) Name() string {
+	return "mora:tracing"
+}
+
+// Initialize implements gorm.Plugin
+func (p tracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("mora:before_create", p.before("create")),
+		cb.Create().After("gorm:create").Register("mora:after_create", p.after),
+		cb.Query().Before("gorm:query").Register("mora:before_query", p.before("query")),
+		cb.Query().After("gorm:query").Register("mora:after_query", p.after),
+		cb.Update().Before("gorm:update").Register("mora:before_update", p.before("update")),
+		cb.Update().After("gorm:update").Register("mora:after_update", p.after),
+		cb.Delete().Before("gorm:delete").Register("mora:before_delete", p.before("delete")),
+		cb.Delete().After("gorm:delete").Register("mora:after_delete", p.after),
+		cb.Row().Before("gorm:row").Register("mora:before_row", p.before("row")),
+		cb.Row().After("gorm:row").Register("mora:after_row", p.after),
+		cb.Raw().Before("gorm:raw").Register("mora:before_raw", p.before("raw")),
+		cb.Raw().After("gorm:raw").Register("mora:after_raw", p.after),
+	)
+}
+
+// before starts the span of a statement
+func (tracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := startSpan(db.Statement.Context, db.Dialector.Name(), operation)
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+// after records the statement and ends its span
+func (tracingPlugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := v.(trace.Span)
+	span.SetAttributes(semconv.DBStatement(db.Statement.SQL.String()), attribute.Int64("db.rows_affected", db.RowsAffected))
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBSQLTable(db.Statement.Table))
+	}
+	endSpan(span, db.Error)
+}
+
+// span starts the span of an sqlx operation when tracing is enabled; call
+// the returned function with the operation's error
+func (c *SQLXClient) span(ctx context.Context, operation, query string) (context.Context, func(error)) {
+	if !c.tracing {
+		return ctx, func(error) {}
+	}
+	ctx, span := startSpan(ctx, c.db.DriverName(), operation)
+	span.SetAttributes(semconv.DBStatement(query))
+	return ctx, func(err error) { endSpan(span, err) }
+}
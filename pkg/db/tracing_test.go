@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// user is a GORM model for the tracing test
+type user struct {
+	ID   uint
+	Name string
+}
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+	otel.SetTracerProvider(tp)
+
+	cfg := DefaultConfig()
+	cfg.Driver, cfg.DSN, cfg.Tracing, cfg.LogLevel = "sqlite", ":memory:", true, "silent"
+
+	tests := []struct {
+		name      string
+		run       func(t *testing.T, ctx context.Context)
+		wantSpans []string
+	}{
+		{
+			name: "gorm",
+			run: func(t *testing.T, ctx context.Context) {
+				client, err := New(cfg)
+				if err != nil {
+					t.Fatalf("New() error = %v", err)
+				}
+				defer client.Close()
+				db := client.DB().WithContext(ctx)
+				if err := db.AutoMigrate(&user{}); err != nil {
+					t.Fatalf("AutoMigrate() error = %v", err)
+				}
+				exporter.Reset()
+				db.Create(&user{Name: "ann"})
+				db.First(&user{}, "name = ?", "bob")
+			},
+			wantSpans: []string{"db.create", "db.query"},
+		},
+		{
+			name: "sqlx",
+			run: func(t *testing.T, ctx context.Context) {
+				sqlxCfg := cfg
+				sqlxCfg.Driver = "sqlite3"
+				client, err := NewSQLX(sqlxCfg)
+				if err != nil {
+					t.Fatalf("NewSQLX() error = %v", err)
+				}
+				defer client.Close()
+				client.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+				var name string
+				client.Get(ctx, &name, "SELECT name FROM users WHERE id = ?", 1)
+			},
+			wantSpans: []string{"db.exec", "db.get"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+			tt.run(t, ctx)
+			parent.End()
+
+			var got []string
+			for _, s := range exporter.GetSpans() {
+				if s.Name == "parent" {
+					continue
+				}
+				got = append(got, s.Name)
+				if s.Parent.SpanID() != parent.SpanContext().SpanID() {
+					t.Errorf("span %s is not a child of the caller's span", s.Name)
+				}
+				if s.Status.Code == codes.Error {
+					t.Errorf("span %s status = %+v, a missing row is not an error", s.Name, s.Status)
+				}
+			}
+			if len(got) != len(tt.wantSpans) {
+				t.Fatalf("spans = %v, want %v", got, tt.wantSpans)
+			}
+			for i := range got {
+				if got[i] != tt.wantSpans[i] {
+					t.Errorf("spans = %v, want %v", got, tt.wantSpans)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Package errors provides coded errors that carry a business code, the HTTP
+// status and gRPC code to report them with, a user-safe message and an
+// internal detail that is logged but never sent to clients.
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error is a coded error
+type Error struct {
+	// Code is the business error code returned to clients
+	Code int
+	// HTTPStatus is the HTTP status the error is rendered with
+	HTTPStatus int
+	// GRPCCode is the gRPC status code the error is rendered with
+	GRPCCode codes.Code
+	// Message is the user-safe description
+	Message string
+	// Detail is internal context for logs, never shown to clients
+	Detail string
+
+	cause error
+}
+
+// New creates a coded error; the gRPC code is derived from the HTTP status
+func New(code, httpStatus int, message string) *Error {
+	return &Error{
+		Code:       code,
+		HTTPStatus: httpStatus,
+		GRPCCode:   grpcCodeFromHTTP(httpStatus),
+		Message:    message,
+	}
+}
+
+// Wrap creates a coded error caused by err
+func Wrap(err error, code, httpStatus int, message string) *Error {
+	e := New(code, httpStatus, message)
+	e.cause = err
+	return e
+}
+
+// Error returns the message followed by the internal detail and cause
+func (e *Error) Error() string {
+	msg := e.Message
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.cause != nil {
+		msg += ": " + e.cause.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is matches any coded error with the same business code, so a wrapped or
+// annotated copy of ErrNotFound still satisfies errors.Is(err, ErrNotFound)
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// clone returns a shallow copy so predefined errors are never mutated
+func (e *Error) clone() *Error {
+	c := *e
+	return &c
+}
+
+// Wrap returns a copy of the error caused by err
+func (e *Error) Wrap(err error) *Error {
+	c := e.clone()
+	c.cause = err
+	return c
+}
+
+// WithMessage returns a copy of the error with a different user-safe message
+func (e *Error) WithMessage(message string) *Error {
+	c := e.clone()
+	c.Message = message
+	return c
+}
+
+// WithDetail returns a copy of the error with formatted internal detail
+func (e *Error) WithDetail(format string, args ...any) *Error {
+	c := e.clone()
+	c.Detail = fmt.Sprintf(format, args...)
+	return c
+}
+
+// WithGRPCCode returns a copy of the error reported with a different gRPC code
+func (e *Error) WithGRPCCode(code codes.Code) *Error {
+	c := e.clone()
+	c.GRPCCode = code
+	return c
+}
+
+// GRPCStatus lets grpc-go's status.FromError report the error's code and
+// user-safe message
+func (e *Error) GRPCStatus() *status.Status {
+	return status.New(e.GRPCCode, e.Message)
+}
+
+// Predefined errors using the HTTP status times 100 as business code;
+// services are free to define their own codes with New
+var (
+	ErrBadRequest      = New(40000, http.StatusBadRequest, "bad request")
+	ErrUnauthorized    = New(40100, http.StatusUnauthorized, "unauthorized")
+	ErrForbidden       = New(40300, http.StatusForbidden, "forbidden")
+	ErrNotFound        = New(40400, http.StatusNotFound, "resource not found")
+	ErrConflict        = New(40900, http.StatusConflict, "resource conflict")
+	ErrPayloadTooLarge = New(41300, http.StatusRequestEntityTooLarge, "payload too large")
+	ErrUnsupportedType = New(41500, http.StatusUnsupportedMediaType, "unsupported media type")
+	ErrTooManyRequests = New(42900, http.StatusTooManyRequests, "too many requests")
+	ErrInternal        = New(50000, http.StatusInternalServerError, "internal server error")
+	ErrUnavailable     = New(50300, http.StatusServiceUnavailable, "service unavailable")
+)
+
+// FromError returns the coded error in err's chain, or ErrInternal wrapping
+// err when there is none, so unexpected errors never leak their text to
+// clients. It returns nil for a nil error.
+func FromError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if stderrors.As(err, &e) {
+		return e
+	}
+	return ErrInternal.Wrap(err)
+}
+
+// Code returns the business code of err, 0 for nil
+func Code(err error) int {
+	if e := FromError(err); e != nil {
+		return e.Code
+	}
+	return 0
+}
+
+// HTTPStatus returns the HTTP status err should be rendered with, 200 for nil
+func HTTPStatus(err error) int {
+	if e := FromError(err); e != nil {
+		return e.HTTPStatus
+	}
+	return http.StatusOK
+}
+
+// Is reports whether any error in err's chain matches target
+func Is(err, target error) bool {
+	return stderrors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target
+func As(err error, target any) bool {
+	return stderrors.As(err, target)
+}
+
+// Unwrap returns the result of calling Unwrap on err
+func Unwrap(err error) error {
+	return stderrors.Unwrap(err)
+}
+
+// Join returns an error wrapping the given errors
+func Join(errs ...error) error {
+	return stderrors.Join(errs...)
+}
+
+// grpcCodeFromHTTP maps an HTTP status to the closest gRPC code
+func grpcCodeFromHTTP(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	case 499:
+		return codes.Canceled
+	}
+	if httpStatus >= 400 && httpStatus < 500 {
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
@@ -0,0 +1,100 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestError(t *testing.T) {
+	err := ErrNotFound.WithDetail("order %s", "o-1").Wrap(io.EOF)
+
+	if got := err.Error(); got != "resource not found: order o-1: EOF" {
+		t.Errorf("Error() = %q", got)
+	}
+	if !Is(err, ErrNotFound) {
+		t.Error("Is(err, ErrNotFound) = false")
+	}
+	if Is(err, ErrConflict) {
+		t.Error("Is(err, ErrConflict) = true")
+	}
+	if !Is(err, io.EOF) {
+		t.Error("Is(err, io.EOF) = false, cause should be unwrapped")
+	}
+	if ErrNotFound.Detail != "" {
+		t.Error("WithDetail() mutated the predefined error")
+	}
+
+	wrapped := fmt.Errorf("load order: %w", err)
+	var coded *Error
+	if !As(wrapped, &coded) || coded.Code != 40400 {
+		t.Errorf("As() = %v", coded)
+	}
+}
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStatus int
+	}{
+		{"nil", nil, 0, http.StatusOK},
+		{"coded", ErrForbidden, 40300, http.StatusForbidden},
+		{"wrapped coded", fmt.Errorf("ctx: %w", ErrTooManyRequests), 42900, http.StatusTooManyRequests},
+		{"plain", stderrors.New("db down"), 50000, http.StatusInternalServerError},
+		{"custom", Wrap(io.EOF, 10001, http.StatusUnprocessableEntity, "balance too low"), 10001, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Code(tt.err); got != tt.wantCode {
+				t.Errorf("Code() = %d, want %d", got, tt.wantCode)
+			}
+			if got := HTTPStatus(tt.err); got != tt.wantStatus {
+				t.Errorf("HTTPStatus() = %d, want %d", got, tt.wantStatus)
+			}
+		})
+	}
+
+	plain := stderrors.New("db down")
+	if e := FromError(plain); e.Message != "internal server error" || !Is(e, plain) {
+		t.Errorf("FromError() = %v, should hide the cause behind a safe message", e)
+	}
+}
+
+func TestGRPCStatus(t *testing.T) {
+	tests := []struct {
+		err  *Error
+		want codes.Code
+	}{
+		{ErrBadRequest, codes.InvalidArgument},
+		{ErrUnauthorized, codes.Unauthenticated},
+		{ErrNotFound, codes.NotFound},
+		{ErrUnavailable, codes.Unavailable},
+		{New(1, http.StatusTeapot, "teapot"), codes.FailedPrecondition},
+		{ErrInternal.WithGRPCCode(codes.DataLoss), codes.DataLoss},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Message, func(t *testing.T) {
+			s, ok := status.FromError(fmt.Errorf("rpc: %w", tt.err))
+			if !ok || s.Code() != tt.want {
+				t.Errorf("status.FromError() code = %v, want %v", s.Code(), tt.want)
+			}
+			if s := tt.err.WithDetail("secret").GRPCStatus(); s.Message() != tt.err.Message {
+				t.Errorf("status message = %q, want %q", s.Message(), tt.err.Message)
+			}
+		})
+	}
+
+	if s, _ := status.FromError(ErrInternal.Wrap(context.DeadlineExceeded)); s.Code() != codes.Internal {
+		t.Errorf("code = %v", s.Code())
+	}
+}
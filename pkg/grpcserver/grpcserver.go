@@ -0,0 +1,266 @@
+// Package grpcserver builds gRPC servers with Mora's standard interceptor
+// chain (recovery, tracing, logging, metrics, rate limiting, auth and error
+// mapping), the health and reflection services, and a pkg/app component
+// that stops the server gracefully.
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+
+	"mora/pkg/logger"
+)
+
+// Config configures a Server
+type Config struct {
+	Name string `json:"name" yaml:"name" env:"GRPC_NAME"`
+	Addr string `json:"addr" yaml:"addr" env:"GRPC_ADDR"`
+	// Reflection registers the reflection service used by grpcurl and friends
+	Reflection bool `json:"reflection" yaml:"reflection" env:"GRPC_REFLECTION"`
+	// MaxRecvMsgSize and MaxSendMsgSize bound message sizes in bytes
+	MaxRecvMsgSize int `json:"max_recv_msg_size" yaml:"max_recv_msg_size" env:"GRPC_MAX_RECV_MSG_SIZE"`
+	MaxSendMsgSize int `json:"max_send_msg_size" yaml:"max_send_msg_size" env:"GRPC_MAX_SEND_MSG_SIZE"`
+	// MaxConnectionAge closes connections periodically so clients rebalance
+	MaxConnectionAge  time.Duration `json:"max_connection_age" yaml:"max_connection_age" env:"GRPC_MAX_CONNECTION_AGE"`
+	MaxConnectionIdle time.Duration `json:"max_connection_idle" yaml:"max_connection_idle" env:"GRPC_MAX_CONNECTION_IDLE"`
+	// KeepaliveTime pings idle clients; KeepaliveTimeout closes unresponsive ones
+	KeepaliveTime    time.Duration `json:"keepalive_time" yaml:"keepalive_time" env:"GRPC_KEEPALIVE_TIME"`
+	KeepaliveTimeout time.Duration `json:"keepalive_timeout" yaml:"keepalive_timeout" env:"GRPC_KEEPALIVE_TIMEOUT"`
+	// RateLimit is the requests per second the server accepts, 0 for no limit
+	RateLimit float64 `json:"rate_limit" yaml:"rate_limit" env:"GRPC_RATE_LIMIT"`
+	RateBurst int     `json:"rate_burst" yaml:"rate_burst" env:"GRPC_RATE_BURST"`
+	// Metrics records request counts and latencies with Prometheus
+	Metrics bool `json:"metrics" yaml:"metrics" env:"GRPC_METRICS"`
+	// Tracing starts a span per call, continuing the caller's trace
+	Tracing bool `json:"tracing" yaml:"tracing" env:"GRPC_TRACING"`
+
+	// Logger logs every call, defaulting to logger.NewDefault
+	Logger *logger.Logger `json:"-" yaml:"-"`
+	// Registerer receives the metrics, defaulting to prometheus.DefaultRegisterer
+	Registerer prometheus.Registerer `json:"-" yaml:"-"`
+}
+
+// DefaultConfig returns default server configuration
+func DefaultConfig() Config {
+	return Config{
+		Name:             "grpc",
+		Addr:             ":9090",
+		Reflection:       true,
+		MaxRecvMsgSize:   4 << 20,
+		MaxSendMsgSize:   4 << 20,
+		KeepaliveTime:    2 * time.Hour,
+		KeepaliveTimeout: 20 * time.Second,
+		Metrics:          true,
+		Tracing:          true,
+	}
+}
+
+// options collects the Option values
+type options struct {
+	authUnary  grpc.UnaryServerInterceptor
+	authStream grpc.StreamServerInterceptor
+	unary      []grpc.UnaryServerInterceptor
+	stream     []grpc.StreamServerInterceptor
+	limiter    Limiter
+	serverOpts []grpc.ServerOption
+}
+
+// Option customizes a Server
+type Option func(*options)
+
+// WithAuth sets the authentication interceptors, e.g. those of adapters/grpc;
+// they run after rate limiting and before the interceptors of WithUnary/WithStream
+func WithAuth(unary grpc.UnaryServerInterceptor, stream grpc.StreamServerInterceptor) Option {
+	return func(o *options) {
+		o.authUnary = unary
+		o.authStream = stream
+	}
+}
+
+// WithUnary appends unary interceptors, run innermost, just before the handler
+func WithUnary(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(o *options) { o.unary = append(o.unary, interceptors...) }
+}
+
+// WithStream appends stream interceptors, run innermost, just before the handler
+func WithStream(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(o *options) { o.stream = append(o.stream, interceptors...) }
+}
+
+// WithLimiter replaces the limiter built from Config.RateLimit, e.g. with
+// a per-method or per-client one
+func WithLimiter(l Limiter) Option {
+	return func(o *options) { o.limiter = l }
+}
+
+// WithServerOptions passes extra options, such as grpc.Creds, to grpc.NewServer
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(o *options) { o.serverOpts = append(o.serverOpts, opts...) }
+}
+
+// Server is a gRPC server; register services on it as on a *grpc.Server.
+// It implements app.Component.
+type Server struct {
+	*grpc.Server
+	cfg    Config
+	health *health.Server
+	errs   chan error
+
+	mu   sync.Mutex
+	addr net.Addr
+}
+
+// New builds a server with the interceptor chain
+//
+//	recovery → tracing → logging → metrics → rate limit → auth → custom → error mapping
+//
+// and the health service, and the reflection service when enabled
+func New(cfg Config, opts ...Option) (*Server, error) {
+	defaults := DefaultConfig()
+	if cfg.Name == "" {
+		cfg.Name = defaults.Name
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = defaults.Addr
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logger.NewDefault()
+	}
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.limiter == nil && cfg.RateLimit > 0 {
+		o.limiter = NewTokenBucket(cfg.RateLimit, cfg.RateBurst)
+	}
+
+	unary := []grpc.UnaryServerInterceptor{RecoveryUnary(cfg.Logger)}
+	stream := []grpc.StreamServerInterceptor{RecoveryStream(cfg.Logger)}
+	if cfg.Tracing {
+		unary = append(unary, TracingUnary())
+		stream = append(stream, TracingStream())
+	}
+	unary = append(unary, LoggingUnary(cfg.Logger))
+	stream = append(stream, LoggingStream(cfg.Logger))
+	if cfg.Metrics {
+		m, err := NewMetrics(cfg.Registerer)
+		if err != nil {
+			return nil, err
+		}
+		unary = append(unary, m.Unary())
+		stream = append(stream, m.Stream())
+	}
+	if o.limiter != nil {
+		unary = append(unary, RateLimitUnary(o.limiter))
+		stream = append(stream, RateLimitStream(o.limiter))
+	}
+	if o.authUnary != nil {
+		unary = append(unary, o.authUnary)
+	}
+	if o.authStream != nil {
+		stream = append(stream, o.authStream)
+	}
+	unary = append(append(unary, o.unary...), ErrorsUnary())
+	stream = append(append(stream, o.stream...), ErrorsStream())
+
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle: cfg.MaxConnectionIdle,
+			MaxConnectionAge:  cfg.MaxConnectionAge,
+			Time:              cfg.KeepaliveTime,
+			Timeout:           cfg.KeepaliveTimeout,
+		}),
+	}
+	if cfg.MaxRecvMsgSize > 0 {
+		serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		serverOpts = append(serverOpts, grpc.MaxSendMsgSize(cfg.MaxSendMsgSize))
+	}
+
+	s := &Server{
+		Server: grpc.NewServer(append(serverOpts, o.serverOpts...)...),
+		cfg:    cfg,
+		health: health.NewServer(),
+		errs:   make(chan error, 1),
+	}
+	// Report NOT_SERVING until Start
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(s.Server, s.health)
+	if cfg.Reflection {
+		reflection.Register(s.Server)
+	}
+	return s, nil
+}
+
+// Health returns the health service, to report the status of individual
+// services with SetServingStatus
+func (s *Server) Health() *health.Server {
+	return s.health
+}
+
+// Name implements app.Component
+func (s *Server) Name() string { return s.cfg.Name }
+
+// Start implements app.Component; it binds Addr so port conflicts fail
+// startup, then serves in the background and reports SERVING
+func (s *Server) Start(ctx context.Context) error {
+	var lc net.ListenConfig
+	ln, err := lc.Listen(ctx, "tcp", s.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.cfg.Addr, err)
+	}
+	s.mu.Lock()
+	s.addr = ln.Addr()
+	s.mu.Unlock()
+
+	go func() {
+		if err := s.Serve(ln); err != nil {
+			s.errs <- err
+		}
+	}()
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	return nil
+}
+
+// Stop implements app.Component. It reports NOT_SERVING, waits for running
+// calls to finish and closes remaining connections once ctx expires.
+func (s *Server) Stop(ctx context.Context) error {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.Server.Stop()
+		<-done
+		return fmt.Errorf("grpc: graceful stop: %w", ctx.Err())
+	}
+}
+
+// Err implements app.Runner
+func (s *Server) Err() <-chan error { return s.errs }
+
+// Addr returns the address the server listens on, nil before Start
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
@@ -0,0 +1,225 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	moraerrors "mora/pkg/errors"
+)
+
+// testService is a hand-written service whose methods behave as named
+var testService = grpc.ServiceDesc{
+	ServiceName: "test.Test",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "OK", Handler: testHandler(func() error { return nil })},
+		{MethodName: "Panic", Handler: testHandler(func() error { panic("boom") })},
+		{MethodName: "Plain", Handler: testHandler(func() error { return errors.New("dial tcp 10.0.0.1: refused") })},
+		{MethodName: "Coded", Handler: testHandler(func() error { return moraerrors.ErrNotFound })},
+	},
+}
+
+func testHandler(fn func() error) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		in := new(emptypb.Empty)
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		handler := func(ctx context.Context, req any) (any, error) {
+			if err := fn(); err != nil {
+				return nil, err
+			}
+			return &emptypb.Empty{}, nil
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Test/" + methodName(ctx)}
+		return interceptor(ctx, in, info, handler)
+	}
+}
+
+// methodName returns the method of the call in ctx
+func methodName(ctx context.Context) string {
+	m, _ := grpc.Method(ctx)
+	_, name := splitMethod(m)
+	return name
+}
+
+// startServer starts a server on a free port and returns a client connection
+func startServer(t *testing.T, cfg Config, opts ...Option) (*Server, *grpc.ClientConn) {
+	t.Helper()
+	cfg.Addr = "127.0.0.1:0"
+	s, err := New(cfg, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	var impl any = struct{}{}
+	s.RegisterService(&testService, impl)
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { s.Stop(context.Background()) })
+
+	conn, err := grpc.NewClient(s.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return s, conn
+}
+
+func invoke(conn *grpc.ClientConn, method string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return conn.Invoke(ctx, "/test.Test/"+method, &emptypb.Empty{}, &emptypb.Empty{})
+}
+
+func TestInterceptorChain(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Registerer = prometheus.NewRegistry()
+	_, conn := startServer(t, cfg)
+
+	tests := []struct {
+		method   string
+		wantCode codes.Code
+		wantMsg  string
+	}{
+		{method: "OK", wantCode: codes.OK},
+		{method: "Panic", wantCode: codes.Internal, wantMsg: "internal server error"},
+		{method: "Plain", wantCode: codes.Internal, wantMsg: "internal server error"},
+		{method: "Coded", wantCode: codes.NotFound, wantMsg: "resource not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			err := invoke(conn, tt.method)
+			st := status.Convert(err)
+			if st.Code() != tt.wantCode {
+				t.Fatalf("code = %s, want %s (%v)", st.Code(), tt.wantCode, err)
+			}
+			if tt.wantMsg != "" && st.Message() != tt.wantMsg {
+				t.Errorf("message = %q, want %q", st.Message(), tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cfg := DefaultConfig()
+	cfg.Registerer = reg
+	startServer(t, cfg)
+	// A second server in the process shares the collectors
+	_, conn := startServer(t, cfg)
+
+	invoke(conn, "OK")
+	invoke(conn, "Coded")
+	invoke(conn, "Coded")
+
+	m, err := NewMetrics(reg)
+	if err != nil {
+		t.Fatalf("NewMetrics() error = %v", err)
+	}
+	if got := testutil.ToFloat64(m.handled.WithLabelValues("test.Test", "Coded", "unary", "NotFound")); got != 2 {
+		t.Errorf("handled NotFound = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.handled.WithLabelValues("test.Test", "OK", "unary", "OK")); got != 1 {
+		t.Errorf("handled OK = %v, want 1", got)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Metrics = false
+	cfg.RateLimit = 1
+	cfg.RateBurst = 2
+	_, conn := startServer(t, cfg)
+
+	for i := 0; i < 2; i++ {
+		if err := invoke(conn, "OK"); err != nil {
+			t.Fatalf("call %d error = %v", i, err)
+		}
+	}
+	if code := status.Code(invoke(conn, "OK")); code != codes.ResourceExhausted {
+		t.Errorf("third call code = %s, want ResourceExhausted", code)
+	}
+}
+
+func TestAuthAndCustomInterceptors(t *testing.T) {
+	var order []string
+	record := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			order = append(order, name)
+			return handler(ctx, req)
+		}
+	}
+	cfg := DefaultConfig()
+	cfg.Metrics = false
+	_, conn := startServer(t, cfg,
+		WithUnary(record("custom")),
+		WithAuth(record("auth"), nil),
+		WithLimiter(LimiterFunc(func(ctx context.Context, method string) bool {
+			order = append(order, "limit")
+			return true
+		})))
+
+	if err := invoke(conn, "OK"); err != nil {
+		t.Fatalf("invoke() error = %v", err)
+	}
+	if len(order) != 3 || order[0] != "limit" || order[1] != "auth" || order[2] != "custom" {
+		t.Errorf("order = %v, want limit, auth, custom", order)
+	}
+}
+
+func TestHealthAndLifecycle(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Metrics = false
+	s, conn := startServer(t, cfg)
+
+	client := healthpb.NewHealthClient(conn)
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Check() = %v, %v, want SERVING", resp, err)
+	}
+	if _, ok := s.GetServiceInfo()["grpc.reflection.v1.ServerReflection"]; !ok {
+		t.Error("reflection service is not registered")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if err := invoke(conn, "OK"); status.Code(err) != codes.Unavailable {
+		t.Errorf("call after Stop error = %v, want Unavailable", err)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewTokenBucket(2, 2)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if b.Allow(context.Background(), "") {
+			allowed++
+		}
+	}
+	if allowed != 2 {
+		t.Fatalf("allowed %d of a full bucket, want 2", allowed)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if !b.Allow(context.Background(), "") || b.Allow(context.Background(), "") {
+		t.Error("want exactly one token after half a second at 2/s")
+	}
+}
@@ -0,0 +1,256 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	moraerrors "mora/pkg/errors"
+	"mora/pkg/logger"
+	"mora/pkg/tracing"
+)
+
+// wrappedStream replaces the context of a server stream
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream
+func (s *wrappedStream) Context() context.Context { return s.ctx }
+
+// withContext returns ss carrying ctx
+func withContext(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	if w, ok := ss.(*wrappedStream); ok {
+		return &wrappedStream{ServerStream: w.ServerStream, ctx: ctx}
+	}
+	return &wrappedStream{ServerStream: ss, ctx: ctx}
+}
+
+// recovered logs a panic and converts it to an Internal error
+func recovered(ctx context.Context, log *logger.Logger, method string, r any) error {
+	log.WithContext(ctx).Errorw("grpc handler panic", "method", method, "panic", r, "stack", string(debug.Stack()))
+	return moraerrors.ErrInternal.WithDetail("panic: %v", r)
+}
+
+// RecoveryUnary turns handler panics into Internal errors and logs their stack
+func RecoveryUnary(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, log, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStream turns handler panics into Internal errors and logs their stack
+func RecoveryStream(log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), log, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// Span attributes of the OpenTelemetry RPC conventions
+const (
+	rpcSystem     = attribute.Key("rpc.system")
+	rpcService    = attribute.Key("rpc.service")
+	rpcMethod     = attribute.Key("rpc.method")
+	rpcStatusCode = attribute.Key("rpc.grpc.status_code")
+)
+
+// metadataCarrier adapts incoming metadata to propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) { metadata.MD(c).Set(key, value) }
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// startSpan continues the caller's trace from the metadata and starts a server span
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	}
+	service, name := splitMethod(method)
+	return tracing.Start(ctx, strings.TrimPrefix(method, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			rpcSystem.String("grpc"),
+			rpcService.String(service),
+			rpcMethod.String(name),
+		))
+}
+
+// endSpan records the call's status code on the span and ends it
+func endSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(rpcStatusCode.Int(int(code)))
+	if err != nil && isServerError(code) {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TracingUnary starts a span per call with the global tracer provider, see
+// tracing.Init, and puts the trace ID in the context for pkg/logger
+func TracingUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		ctx, span := startSpan(ctx, info.FullMethod)
+		defer func() { endSpan(span, err) }()
+		return handler(ctx, req)
+	}
+}
+
+// TracingStream starts a span per stream, see TracingUnary
+func TracingStream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx, span := startSpan(ss.Context(), info.FullMethod)
+		defer func() { endSpan(span, err) }()
+		return handler(srv, withContext(ss, ctx))
+	}
+}
+
+// logCall logs a finished call at a level chosen by its status code
+func logCall(ctx context.Context, log *logger.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	keysAndValues := []interface{}{
+		"method", method,
+		"code", code.String(),
+		"duration", time.Since(start),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		keysAndValues = append(keysAndValues, "peer", p.Addr.String())
+	}
+	l := log.WithContext(ctx)
+	switch {
+	case err == nil:
+		l.Infow("grpc call", keysAndValues...)
+	case isServerError(code):
+		l.Errorw("grpc call failed", append(keysAndValues, "error", err)...)
+	default:
+		l.Warnw("grpc call rejected", append(keysAndValues, "error", err)...)
+	}
+}
+
+// LoggingUnary logs every call with its method, code and duration
+func LoggingUnary(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, log, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// LoggingStream logs every stream when it ends
+func LoggingStream(log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), log, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// RateLimitUnary rejects calls the limiter does not allow with ResourceExhausted
+func RateLimitUnary(l Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !l.Allow(ctx, info.FullMethod) {
+			return nil, moraerrors.ErrTooManyRequests
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStream rejects streams the limiter does not allow
+func RateLimitStream(l Limiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !l.Allow(ss.Context(), info.FullMethod) {
+			return moraerrors.ErrTooManyRequests
+		}
+		return handler(srv, ss)
+	}
+}
+
+// mapError keeps gRPC status errors, including coded pkg/errors errors, and
+// reports any other error as Internal so its text never reaches clients
+func mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return moraerrors.FromError(err)
+}
+
+// ErrorsUnary maps handler errors, see mapError
+func ErrorsUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, mapError(err)
+	}
+}
+
+// ErrorsStream maps stream handler errors, see mapError
+func ErrorsStream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return mapError(handler(srv, ss))
+	}
+}
+
+// isServerError reports whether code means the server, not the caller, failed
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.Unimplemented, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// splitMethod splits "/pkg.Service/Method" into service and method
+func splitMethod(fullMethod string) (string, string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return "unknown", fullMethod
+	}
+	return service, method
+}
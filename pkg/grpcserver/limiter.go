@@ -0,0 +1,55 @@
+package grpcserver
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter decides whether a call may proceed
+type Limiter interface {
+	Allow(ctx context.Context, fullMethod string) bool
+}
+
+// LimiterFunc adapts a function to Limiter
+type LimiterFunc func(ctx context.Context, fullMethod string) bool
+
+// Allow calls f
+func (f LimiterFunc) Allow(ctx context.Context, fullMethod string) bool {
+	return f(ctx, fullMethod)
+}
+
+// TokenBucket is an in-process Limiter allowing rate calls per second on
+// average and bursts of up to burst calls
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTokenBucket creates a full bucket; burst defaults to the rate rounded up
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst <= 0 {
+		burst = max(1, int(rate+0.999))
+	}
+	b := &TokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), now: time.Now}
+	b.last = b.now()
+	return b
+}
+
+// Allow implements Limiter, taking one token when available
+func (b *TokenBucket) Allow(ctx context.Context, fullMethod string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,92 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics records per-method call counts and latencies
+type Metrics struct {
+	handled *prometheus.CounterVec
+	latency *prometheus.HistogramVec
+}
+
+// NewMetrics registers the grpc_server_handled_total and
+// grpc_server_handling_seconds collectors with reg, or with
+// prometheus.DefaultRegisterer when nil. Collectors already registered by
+// another server in the process are shared.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	handled := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Total number of RPCs completed on the server, by method and code.",
+	}, []string{"grpc_service", "grpc_method", "grpc_type", "grpc_code"})
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Latency of RPCs handled by the server, by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"grpc_service", "grpc_method", "grpc_type"})
+
+	if err := register(reg, &handled); err != nil {
+		return nil, err
+	}
+	if err := register(reg, &latency); err != nil {
+		return nil, err
+	}
+	return &Metrics{handled: handled, latency: latency}, nil
+}
+
+// register registers *c, replacing it with the existing collector when an
+// identical one is already registered
+func register[C prometheus.Collector](reg prometheus.Registerer, c *C) error {
+	err := reg.Register(*c)
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			*c = existing
+			return nil
+		}
+	}
+	return err
+}
+
+// observe records one finished call
+func (m *Metrics) observe(fullMethod, kind string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+	m.handled.WithLabelValues(service, method, kind, status.Code(err).String()).Inc()
+	m.latency.WithLabelValues(service, method, kind).Observe(time.Since(start).Seconds())
+}
+
+// Unary returns the unary interceptor recording the metrics
+func (m *Metrics) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observe(info.FullMethod, "unary", start, err)
+		return resp, err
+	}
+}
+
+// Stream returns the stream interceptor recording the metrics
+func (m *Metrics) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		kind := "bidi_stream"
+		switch {
+		case info.IsClientStream && !info.IsServerStream:
+			kind = "client_stream"
+		case info.IsServerStream && !info.IsClientStream:
+			kind = "server_stream"
+		}
+		m.observe(info.FullMethod, kind, start, err)
+		return err
+	}
+}
@@ -0,0 +1,21 @@
+package health
+
+import (
+	"context"
+	"fmt"
+)
+
+// Disk checks that the filesystem holding path has at least minFree bytes
+// available to unprivileged users, e.g. Disk("/var/lib/app", 1<<30)
+func Disk(path string, minFree uint64) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		free, err := freeSpace(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		if free < minFree {
+			return fmt.Errorf("%s has %d bytes free, want at least %d", path, free, minFree)
+		}
+		return nil
+	})
+}
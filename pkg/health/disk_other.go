@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd
+
+package health
+
+import "errors"
+
+// freeSpace is not available on this platform
+func freeSpace(path string) (uint64, error) {
+	return 0, errors.New("disk check not supported on this platform")
+}
@@ -0,0 +1,292 @@
+// Package health aggregates liveness and readiness checks of a service's
+// components, such as databases, Redis and free disk space. Results are
+// cached briefly so frequent probes do not hammer databases, and every
+// check runs concurrently under its own timeout.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is the state of a check or a whole report
+type Status string
+
+const (
+	// StatusUp means the check passed
+	StatusUp Status = "up"
+	// StatusDegraded means only non-critical checks failed
+	StatusDegraded Status = "degraded"
+	// StatusDown means a critical check failed
+	StatusDown Status = "down"
+)
+
+// Kind selects the probes a check takes part in
+type Kind int
+
+const (
+	// Readiness checks decide whether the instance should receive traffic
+	Readiness Kind = 1 << iota
+	// Liveness checks decide whether the instance should be restarted; keep
+	// them to the process itself, a failing database must not restart it
+	Liveness
+)
+
+// Checker checks one component
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to Checker
+type CheckerFunc func(ctx context.Context) error
+
+// Check implements Checker
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Pinger is implemented by clients such as *cache.Client
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks a client with a Ping(ctx) method, e.g. the pkg/cache client
+func Ping(p Pinger) Checker {
+	return CheckerFunc(p.Ping)
+}
+
+// SQL checks a database connection pool, e.g. from db.Client.DB().DB()
+func SQL(db *sql.DB) Checker {
+	return CheckerFunc(db.PingContext)
+}
+
+// Config configures an Aggregator
+type Config struct {
+	// Timeout bounds each check unless overridden with WithTimeout
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// CacheTTL reuses a check result for this long, 0 disables caching
+	CacheTTL time.Duration `json:"cache_ttl" yaml:"cache_ttl"`
+}
+
+// DefaultConfig returns default health configuration
+func DefaultConfig() Config {
+	return Config{
+		Timeout:  2 * time.Second,
+		CacheTTL: time.Second,
+	}
+}
+
+// Option configures a registered check
+type Option func(*check)
+
+// WithTimeout overrides the aggregator timeout for a check
+func WithTimeout(d time.Duration) Option {
+	return func(c *check) {
+		c.timeout = d
+	}
+}
+
+// WithKind selects the probes of a check, Readiness by default; combine
+// kinds with |
+func WithKind(kind Kind) Option {
+	return func(c *check) {
+		c.kind = kind
+	}
+}
+
+// NonCritical makes a failing check degrade the report instead of taking
+// it down, e.g. for an optional downstream service
+func NonCritical() Option {
+	return func(c *check) {
+		c.critical = false
+	}
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Status    Status    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+	Critical  bool      `json:"critical"`
+}
+
+// Report is the aggregated outcome of a probe
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+	Time   time.Time         `json:"time"`
+}
+
+// HTTPStatus returns 503 for a down report and 200 otherwise
+func (r Report) HTTPStatus() int {
+	if r.Status == StatusDown {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+// check is a registered checker with its cached result
+type check struct {
+	name     string
+	checker  Checker
+	timeout  time.Duration
+	kind     Kind
+	critical bool
+
+	mu     sync.Mutex
+	result Result
+	valid  bool
+}
+
+// Aggregator runs registered checks
+type Aggregator struct {
+	cfg Config
+
+	mu     sync.RWMutex
+	checks map[string]*check
+}
+
+// New creates an aggregator
+func New(cfg Config) *Aggregator {
+	return &Aggregator{cfg: cfg, checks: make(map[string]*check)}
+}
+
+// Register adds a named check, replacing any check with the same name
+func (a *Aggregator) Register(name string, checker Checker, opts ...Option) {
+	c := &check{name: name, checker: checker, timeout: a.cfg.Timeout, kind: Readiness, critical: true}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.checks[name] = c
+}
+
+// Unregister removes a check
+func (a *Aggregator) Unregister(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.checks, name)
+}
+
+// Liveness runs the liveness checks
+func (a *Aggregator) Liveness(ctx context.Context) Report {
+	return a.Run(ctx, Liveness)
+}
+
+// Readiness runs the readiness checks
+func (a *Aggregator) Readiness(ctx context.Context) Report {
+	return a.Run(ctx, Readiness)
+}
+
+// Run runs the checks of a kind concurrently and aggregates their results
+func (a *Aggregator) Run(ctx context.Context, kind Kind) Report {
+	a.mu.RLock()
+	var checks []*check
+	for _, c := range a.checks {
+		if c.kind&kind != 0 {
+			checks = append(checks, c)
+		}
+	}
+	a.mu.RUnlock()
+	sort.Slice(checks, func(i, j int) bool { return checks[i].name < checks[j].name })
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = a.runCheck(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Time: time.Now()}
+	if len(checks) > 0 {
+		report.Checks = make(map[string]Result, len(checks))
+	}
+	for i, c := range checks {
+		r := results[i]
+		report.Checks[c.name] = r
+		if r.Status == StatusUp {
+			continue
+		}
+		if c.critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// runCheck returns the cached result of a check or runs it; concurrent
+// probes wait for a single run
+func (a *Aggregator) runCheck(ctx context.Context, c *check) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.valid && time.Since(c.result.CheckedAt) < a.cfg.CacheTTL {
+		return c.result
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := safeCheck(ctx, c.checker)
+	r := Result{Status: StatusUp, Duration: time.Since(start).String(), CheckedAt: start, Critical: c.critical}
+	if err != nil {
+		r.Status, r.Error = StatusDown, err.Error()
+	}
+	c.result, c.valid = r, true
+	return r
+}
+
+// safeCheck runs a checker, abandoning it once ctx expires so a hung
+// dependency cannot block the probe, and converting panics into errors
+func safeCheck(ctx context.Context, checker Checker) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("check panic: %v", r)
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return errors.New("check timed out")
+		}
+		return ctx.Err()
+	}
+}
+
+// Handler returns a net/http handler serving the report of a kind as JSON,
+// with status 503 when it is down
+func (a *Aggregator) Handler(kind Kind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := a.Run(r.Context(), kind)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(report.HTTPStatus())
+		json.NewEncoder(w).Encode(report)
+	}
+}
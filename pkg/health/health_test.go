@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAggregatorStatus(t *testing.T) {
+	ok := CheckerFunc(func(ctx context.Context) error { return nil })
+	fail := CheckerFunc(func(ctx context.Context) error { return errors.New("connection refused") })
+
+	tests := []struct {
+		name   string
+		setup  func(a *Aggregator)
+		kind   Kind
+		want   Status
+		checks int
+	}{
+		{"no checks", func(a *Aggregator) {}, Readiness, StatusUp, 0},
+		{"all up", func(a *Aggregator) {
+			a.Register("db", ok)
+			a.Register("cache", ok)
+		}, Readiness, StatusUp, 2},
+		{"critical down", func(a *Aggregator) {
+			a.Register("db", fail)
+			a.Register("cache", ok)
+		}, Readiness, StatusDown, 2},
+		{"non-critical down", func(a *Aggregator) {
+			a.Register("db", ok)
+			a.Register("search", fail, NonCritical())
+		}, Readiness, StatusDegraded, 2},
+		{"liveness ignores readiness checks", func(a *Aggregator) {
+			a.Register("db", fail)
+			a.Register("goroutines", ok, WithKind(Liveness))
+		}, Liveness, StatusUp, 1},
+		{"both kinds", func(a *Aggregator) {
+			a.Register("self", fail, WithKind(Liveness|Readiness))
+		}, Liveness, StatusDown, 1},
+		{"panic", func(a *Aggregator) {
+			a.Register("mq", CheckerFunc(func(ctx context.Context) error { panic("nil client") }))
+		}, Readiness, StatusDown, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := New(DefaultConfig())
+			tt.setup(a)
+			report := a.Run(context.Background(), tt.kind)
+			if report.Status != tt.want {
+				t.Errorf("Status = %v, want %v (%+v)", report.Status, tt.want, report.Checks)
+			}
+			if len(report.Checks) != tt.checks {
+				t.Errorf("checks = %d, want %d", len(report.Checks), tt.checks)
+			}
+		})
+	}
+}
+
+func TestAggregatorTimeoutAndCache(t *testing.T) {
+	a := New(Config{Timeout: time.Second, CacheTTL: time.Hour})
+
+	var calls atomic.Int32
+	a.Register("slow", CheckerFunc(func(ctx context.Context) error {
+		calls.Add(1)
+		time.Sleep(time.Second)
+		return nil
+	}), WithTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	report := a.Readiness(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Readiness() took %v, timeout not applied", elapsed)
+	}
+	if r := report.Checks["slow"]; r.Status != StatusDown || r.Error != "check timed out" {
+		t.Errorf("result = %+v", r)
+	}
+
+	a.Readiness(context.Background())
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want cached result", calls.Load())
+	}
+
+	a.Unregister("slow")
+	if report := a.Readiness(context.Background()); len(report.Checks) != 0 {
+		t.Errorf("checks after Unregister() = %v", report.Checks)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	a := New(DefaultConfig())
+	a.Register("db", CheckerFunc(func(ctx context.Context) error { return errors.New("down") }))
+
+	w := httptest.NewRecorder()
+	a.Handler(Readiness)(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+	var report Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil || report.Checks["db"].Error != "down" {
+		t.Errorf("body = %s, %v", w.Body.String(), err)
+	}
+
+	w = httptest.NewRecorder()
+	a.Handler(Liveness)(w, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("liveness status = %d, want 200", w.Code)
+	}
+}
+
+func TestDisk(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		minFree uint64
+		wantErr bool
+	}{
+		{name: "enough space", path: t.TempDir(), minFree: 1},
+		{name: "not enough space", path: t.TempDir(), minFree: 1 << 62, wantErr: true},
+		{name: "missing path", path: "/does/not/exist", minFree: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Disk(tt.path, tt.minFree).Check(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
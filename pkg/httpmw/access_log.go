@@ -0,0 +1,172 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"mora/pkg/logger"
+)
+
+// AccessLogConfig holds the configuration for the access log middleware
+type AccessLogConfig struct {
+	// Logger writes the entries, logger.NewDefault() when nil
+	Logger *logger.Logger
+	// SlowThreshold, when set, logs slower requests at warn level
+	SlowThreshold time.Duration
+	// SkipPaths contains paths that are not logged, in the NewSkipper syntax
+	SkipPaths []string
+	// Skipper, when set, skips requests SkipPaths cannot express
+	Skipper Skipper
+	// Message is the log message, "http request" when empty
+	Message string
+}
+
+// AccessLogger writes one structured entry per request with its method,
+// path, status, latency, user ID and trace ID. Server errors and slow
+// requests are logged at warn level.
+type AccessLogger struct {
+	log     *logger.Logger
+	message string
+	slow    time.Duration
+	skip    Skipper
+}
+
+// NewAccessLogger creates an access logger; frameworks that track the
+// response status themselves call Log, others use AccessLog
+func NewAccessLogger(config AccessLogConfig) *AccessLogger {
+	l := &AccessLogger{
+		log:     config.Logger,
+		message: config.Message,
+		slow:    config.SlowThreshold,
+		skip:    NewSkipper(config.SkipPaths, config.Skipper),
+	}
+	if l.log == nil {
+		l.log = logger.NewDefault()
+	}
+	if l.message == "" {
+		l.message = "http request"
+	}
+	return l
+}
+
+// Skip reports whether r is not logged
+func (l *AccessLogger) Skip(r *http.Request) bool {
+	return l.skip(r)
+}
+
+// Log writes the entry of r; ctx supplies the user and trace IDs
+func (l *AccessLogger) Log(ctx context.Context, r *http.Request, status int, latency time.Duration) {
+	fields := []logger.Field{
+		logger.String("method", r.Method),
+		logger.String("path", r.URL.Path),
+		logger.Int("status", status),
+		logger.Int64("latency_ms", latency.Milliseconds()),
+	}
+	slow := l.slow > 0 && latency >= l.slow
+	if slow {
+		fields = append(fields, logger.Bool("slow", true))
+	}
+	entry := l.log.WithContext(ctx).WithFields(fields...)
+	if slow || status >= http.StatusInternalServerError {
+		entry.Warn(l.message)
+		return
+	}
+	entry.Info(l.message)
+}
+
+// accessLogKey keys the accessInfo of a request
+type accessLogKey struct{}
+
+// accessInfo collects what inner middleware learns about a request, since
+// the contexts they derive never reach AccessLog
+type accessInfo struct {
+	userID  string
+	traceID string
+}
+
+// ReportUserID tells an enclosing AccessLog the request's user
+func ReportUserID(ctx context.Context, userID string) {
+	if info, ok := ctx.Value(accessLogKey{}).(*accessInfo); ok {
+		info.userID = userID
+	}
+}
+
+// ReportTraceID tells an enclosing AccessLog the request's trace
+func ReportTraceID(ctx context.Context, traceID string) {
+	if info, ok := ctx.Value(accessLogKey{}).(*accessInfo); ok {
+		info.traceID = traceID
+	}
+}
+
+// AccessLog creates a middleware logging every request with an
+// AccessLogger. Inner middleware report the user and trace IDs with
+// ReportUserID and ReportTraceID.
+func AccessLog(config AccessLogConfig) func(http.Handler) http.Handler {
+	l := NewAccessLogger(config)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if l.Skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			info := &accessInfo{}
+			rec := NewStatusRecorder(w)
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), accessLogKey{}, info)))
+			latency := time.Since(start)
+
+			ctx := r.Context()
+			if info.traceID != "" {
+				ctx = logger.WithTraceID(ctx, info.traceID)
+			}
+			if info.userID != "" {
+				ctx = logger.WithUserID(ctx, info.userID)
+			}
+			l.Log(ctx, r, rec.Status(), latency)
+		})
+	}
+}
+
+// StatusRecorder is an http.ResponseWriter that records the status code
+// and whether the response has started
+type StatusRecorder struct {
+	http.ResponseWriter
+	status  int
+	written bool
+}
+
+// NewStatusRecorder wraps w; the status is 200 until the handler sets one
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// Status returns the response status
+func (r *StatusRecorder) Status() int {
+	return r.status
+}
+
+// Written reports whether the response has started
+func (r *StatusRecorder) Written() bool {
+	return r.written
+}
+
+// WriteHeader implements http.ResponseWriter
+func (r *StatusRecorder) WriteHeader(status int) {
+	if !r.written {
+		r.status, r.written = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (r *StatusRecorder) Write(p []byte) (int, error) {
+	r.written = true
+	return r.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *StatusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
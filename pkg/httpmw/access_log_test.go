@@ -0,0 +1,79 @@
+package httpmw
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"mora/pkg/logger"
+)
+
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		status    int
+		wantLevel string
+	}{
+		{name: "success", path: "/orders", status: http.StatusCreated, wantLevel: "info"},
+		{name: "server error", path: "/orders", status: http.StatusBadGateway, wantLevel: "warn"},
+		{name: "skipped", path: "/health", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log, err := logger.NewWithBackend(logger.Config{Level: "info", Format: "json"}, logger.NewZerologBackend(zerolog.New(&buf)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := AccessLog(AccessLogConfig{Logger: log, SkipPaths: []string{"/health"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ReportUserID(r.Context(), "user-1")
+				ReportTraceID(r.Context(), "trace-1")
+				w.WriteHeader(tt.status)
+			}))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+			if tt.wantLevel == "" {
+				if buf.Len() > 0 {
+					t.Errorf("logged %q, want nothing", buf.String())
+				}
+				return
+			}
+			var line map[string]interface{}
+			if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &line); err != nil {
+				t.Fatalf("output is not a single JSON line: %q", buf.String())
+			}
+			want := map[string]interface{}{
+				"level":    tt.wantLevel,
+				"message":  "http request",
+				"method":   http.MethodPost,
+				"path":     tt.path,
+				"status":   float64(tt.status),
+				"user_id":  "user-1",
+				"trace_id": "trace-1",
+			}
+			for k, v := range want {
+				if line[k] != v {
+					t.Errorf("%s = %v, want %v", k, line[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusRecorder(t *testing.T) {
+	rec := NewStatusRecorder(httptest.NewRecorder())
+	if rec.Status() != http.StatusOK || rec.Written() {
+		t.Fatalf("new recorder: status = %d, written = %v", rec.Status(), rec.Written())
+	}
+	rec.WriteHeader(http.StatusNotFound)
+	rec.WriteHeader(http.StatusInternalServerError)
+	if rec.Status() != http.StatusNotFound || !rec.Written() {
+		t.Errorf("status = %d, written = %v, want the first status", rec.Status(), rec.Written())
+	}
+}
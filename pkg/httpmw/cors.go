@@ -0,0 +1,168 @@
+// Package httpmw implements framework-neutral HTTP middleware on net/http.
+// The adapters bind it to gin, go-zero and plain handlers, so behaviour is
+// the same whichever framework serves the request.
+package httpmw
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures cross-origin resource sharing
+type CORSConfig struct {
+	// AllowOrigins lists allowed origins; "*" allows any, and a "*" label
+	// allows subdomains, e.g. "https://*.example.com"
+	AllowOrigins []string `json:"allow_origins" yaml:"allow_origins" env:"ALLOW_ORIGINS"`
+	// AllowMethods lists methods allowed in preflighted requests
+	AllowMethods []string `json:"allow_methods" yaml:"allow_methods" env:"ALLOW_METHODS"`
+	// AllowHeaders lists request headers allowed in preflighted requests;
+	// "*" allows whatever the browser asks for
+	AllowHeaders []string `json:"allow_headers" yaml:"allow_headers" env:"ALLOW_HEADERS"`
+	// ExposeHeaders lists response headers scripts may read
+	ExposeHeaders []string `json:"expose_headers" yaml:"expose_headers" env:"EXPOSE_HEADERS"`
+	// AllowCredentials lets requests carry cookies and Authorization; the
+	// origin is then echoed instead of sending "*"
+	AllowCredentials bool `json:"allow_credentials" yaml:"allow_credentials" env:"ALLOW_CREDENTIALS"`
+	// MaxAge is how long browsers may cache preflight results
+	MaxAge time.Duration `json:"max_age" yaml:"max_age" env:"MAX_AGE"`
+}
+
+// DefaultCORSConfig returns a configuration allowing any origin without
+// credentials
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowOrigins: []string{"*"},
+		AllowMethods: []string{
+			http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+			http.MethodDelete, http.MethodHead, http.MethodOptions,
+		},
+		AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
+		MaxAge:       12 * time.Hour,
+	}
+}
+
+// CORS answers preflight requests and adds CORS headers to responses
+type CORS struct {
+	cfg           CORSConfig
+	anyOrigin     bool
+	origins       map[string]bool
+	wildcards     [][2]string
+	anyHeader     bool
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+// NewCORS creates a CORS handler
+func NewCORS(cfg CORSConfig) *CORS {
+	c := &CORS{
+		cfg:           cfg,
+		origins:       make(map[string]bool),
+		allowMethods:  strings.Join(cfg.AllowMethods, ", "),
+		allowHeaders:  strings.Join(cfg.AllowHeaders, ", "),
+		exposeHeaders: strings.Join(cfg.ExposeHeaders, ", "),
+	}
+	for _, o := range cfg.AllowOrigins {
+		o = strings.ToLower(strings.TrimSpace(o))
+		switch {
+		case o == "*":
+			c.anyOrigin = true
+		case strings.Contains(o, "*"):
+			prefix, suffix, _ := strings.Cut(o, "*")
+			c.wildcards = append(c.wildcards, [2]string{prefix, suffix})
+		default:
+			c.origins[o] = true
+		}
+	}
+	for _, h := range cfg.AllowHeaders {
+		if h == "*" {
+			c.anyHeader = true
+		}
+	}
+	if cfg.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+	return c
+}
+
+// allowed reports whether origin may access the resource
+func (c *CORS) allowed(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if c.origins[origin] {
+		return true
+	}
+	for _, w := range c.wildcards {
+		if len(origin) > len(w[0])+len(w[1]) && strings.HasPrefix(origin, w[0]) && strings.HasSuffix(origin, w[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle adds the CORS headers for r to w. It reports true when r was a
+// preflight request, which it has answered; the caller must not pass it on.
+func (c *CORS) Handle(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+	h := w.Header()
+	if preflight {
+		h.Add("Vary", "Origin")
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+	} else if !c.anyOrigin || c.cfg.AllowCredentials {
+		h.Add("Vary", "Origin")
+	}
+
+	if origin != "" && c.allowed(origin) {
+		if c.anyOrigin && !c.cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if c.cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if c.exposeHeaders != "" {
+				h.Set("Access-Control-Expose-Headers", c.exposeHeaders)
+			}
+			return false
+		}
+		if c.allowMethods != "" {
+			h.Set("Access-Control-Allow-Methods", c.allowMethods)
+		}
+		if c.anyHeader {
+			if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				h.Set("Access-Control-Allow-Headers", requested)
+			}
+		} else if c.allowHeaders != "" {
+			h.Set("Access-Control-Allow-Headers", c.allowHeaders)
+		}
+		if c.maxAge != "" {
+			h.Set("Access-Control-Max-Age", c.maxAge)
+		}
+	}
+
+	if preflight {
+		// Disallowed origins get no CORS headers, which the browser rejects
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return preflight
+}
+
+// Middleware returns a net/http middleware applying CORS
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.Handle(w, r) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
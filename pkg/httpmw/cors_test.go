@@ -0,0 +1,142 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	restricted := CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com", "https://*.example.org"},
+		AllowMethods:     []string{http.MethodGet, http.MethodPost},
+		AllowHeaders:     []string{"Content-Type", "Authorization"},
+		ExposeHeaders:    []string{"X-Total-Count"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+
+	tests := []struct {
+		name       string
+		cfg        CORSConfig
+		method     string
+		origin     string
+		reqHeaders string
+		wantStatus int
+		wantNext   bool
+		want       map[string]string
+	}{
+		{
+			name:       "any origin",
+			cfg:        DefaultCORSConfig(),
+			method:     http.MethodGet,
+			origin:     "https://foo.test",
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+			want:       map[string]string{"Access-Control-Allow-Origin": "*", "Access-Control-Allow-Credentials": ""},
+		},
+		{
+			name:       "exact origin with credentials",
+			cfg:        restricted,
+			method:     http.MethodGet,
+			origin:     "https://app.example.com",
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "X-Total-Count",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name:       "wildcard subdomain",
+			cfg:        restricted,
+			method:     http.MethodGet,
+			origin:     "https://api.example.org",
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+			want:       map[string]string{"Access-Control-Allow-Origin": "https://api.example.org"},
+		},
+		{
+			name:       "wildcard does not match the bare domain",
+			cfg:        restricted,
+			method:     http.MethodGet,
+			origin:     "https://.example.org",
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+			want:       map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:       "disallowed origin",
+			cfg:        restricted,
+			method:     http.MethodGet,
+			origin:     "https://evil.test",
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+			want:       map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Credentials": ""},
+		},
+		{
+			name:       "preflight",
+			cfg:        restricted,
+			method:     http.MethodOptions,
+			origin:     "https://app.example.com",
+			reqHeaders: "content-type",
+			wantStatus: http.StatusNoContent,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Content-Type, Authorization",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:       "preflight reflects headers for *",
+			cfg:        CORSConfig{AllowOrigins: []string{"*"}, AllowHeaders: []string{"*"}},
+			method:     http.MethodOptions,
+			origin:     "https://foo.test",
+			reqHeaders: "X-Custom",
+			wantStatus: http.StatusNoContent,
+			want:       map[string]string{"Access-Control-Allow-Headers": "X-Custom", "Access-Control-Max-Age": ""},
+		},
+		{
+			name:       "preflight from disallowed origin",
+			cfg:        restricted,
+			method:     http.MethodOptions,
+			origin:     "https://evil.test",
+			wantStatus: http.StatusNoContent,
+			want:       map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			h := NewCORS(tt.cfg).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			}))
+
+			r := httptest.NewRequest(tt.method, "/orders", nil)
+			r.Header.Set("Origin", tt.origin)
+			if tt.method == http.MethodOptions {
+				r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+				r.Header.Set("Access-Control-Request-Headers", tt.reqHeaders)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if called != tt.wantNext {
+				t.Errorf("next called = %v, want %v", called, tt.wantNext)
+			}
+			for k, v := range tt.want {
+				if got := w.Header().Get(k); got != v {
+					t.Errorf("%s = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package httpmw
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mora/pkg/errors"
+)
+
+// Idempotency errors, rendered by the adapters
+var (
+	// ErrIdempotencyInProgress is returned while the first request with a
+	// key is still being handled
+	ErrIdempotencyInProgress = errors.ErrConflict.WithMessage("a request with this idempotency key is in progress")
+	// ErrIdempotencyKeyReused is returned when a key is sent again with
+	// another method or path
+	ErrIdempotencyKeyReused = errors.ErrBadRequest.WithMessage("idempotency key already used for another request")
+	// ErrIdempotencyKeyTooLong is returned for keys over 255 bytes
+	ErrIdempotencyKeyTooLong = errors.ErrBadRequest.WithMessage("idempotency key is too long")
+)
+
+// maxIdempotencyKey is the longest accepted Idempotency-Key
+const maxIdempotencyKey = 255
+
+// IdempotencyStore keeps idempotency records; *cache.Client implements it
+type IdempotencyStore interface {
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	GetBytes(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// IdempotencyConfig configures idempotent request handling
+type IdempotencyConfig struct {
+	// Header carries the client-chosen key, Idempotency-Key by default
+	Header string `json:"header" yaml:"header" env:"HEADER"`
+	// TTL is how long the first response is replayed
+	TTL time.Duration `json:"ttl" yaml:"ttl" env:"TTL"`
+	// LockTTL bounds how long a request in progress holds its key, so that
+	// a crashed instance does not block retries for TTL
+	LockTTL time.Duration `json:"lock_ttl" yaml:"lock_ttl" env:"LOCK_TTL"`
+	// Methods lists the methods keys apply to; others pass through
+	Methods []string `json:"methods" yaml:"methods" env:"METHODS"`
+	// KeyPrefix namespaces the records in the store
+	KeyPrefix string `json:"key_prefix" yaml:"key_prefix" env:"KEY_PREFIX"`
+	// OnError is called with store errors once the handler has run
+	OnError func(error) `json:"-" yaml:"-"`
+}
+
+// DefaultIdempotencyConfig returns default idempotency configuration
+func DefaultIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		Header:    "Idempotency-Key",
+		TTL:       24 * time.Hour,
+		LockTTL:   time.Minute,
+		Methods:   []string{http.MethodPost, http.MethodPatch},
+		KeyPrefix: "idempotency:",
+	}
+}
+
+// Idempotency makes retried requests safe: the first response to a key is
+// stored and replayed for later requests with the same key, and the handler
+// runs once. Server errors are not stored, so the client may retry them.
+type Idempotency struct {
+	cfg     IdempotencyConfig
+	store   IdempotencyStore
+	methods map[string]bool
+}
+
+// NewIdempotency creates an idempotency handler keeping records in store
+func NewIdempotency(store IdempotencyStore, cfg IdempotencyConfig) *Idempotency {
+	defaults := DefaultIdempotencyConfig()
+	if cfg.Header == "" {
+		cfg.Header = defaults.Header
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaults.TTL
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = defaults.LockTTL
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = defaults.Methods
+	}
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = defaults.KeyPrefix
+	}
+	i := &Idempotency{cfg: cfg, store: store, methods: make(map[string]bool)}
+	for _, m := range cfg.Methods {
+		i.methods[strings.ToUpper(m)] = true
+	}
+	return i
+}
+
+// idempotencyRecord is the stored state of a key; Status 0 means the first
+// request is in progress
+type idempotencyRecord struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// Serve runs next for r unless r repeats an earlier key, whose response is
+// replayed instead. Keys are namespaced by scope, e.g. the user ID, so that
+// clients cannot see each other's responses. The returned error, for a key
+// in use or a store failure before next ran, is for the caller to render.
+func (i *Idempotency) Serve(w http.ResponseWriter, r *http.Request, scope string, next http.Handler) error {
+	key := r.Header.Get(i.cfg.Header)
+	if key == "" || !i.methods[r.Method] {
+		next.ServeHTTP(w, r)
+		return nil
+	}
+	if len(key) > maxIdempotencyKey {
+		return ErrIdempotencyKeyTooLong
+	}
+
+	ctx := r.Context()
+	storeKey := i.cfg.KeyPrefix + scope + ":" + key
+	fingerprint := r.Method + " " + r.URL.Path
+	pending, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+	if err != nil {
+		return err
+	}
+	claimed, err := i.store.SetNX(ctx, storeKey, pending, i.cfg.LockTTL)
+	if err != nil {
+		// Running the handler without the guard could duplicate the request
+		return errors.ErrUnavailable.Wrap(err)
+	}
+	if !claimed {
+		return i.replay(w, r, storeKey, fingerprint)
+	}
+
+	rec := &captureWriter{ResponseWriter: w, status: http.StatusOK}
+	completed := false
+	defer func() {
+		// A panicking handler releases its key, like a server error
+		if !completed {
+			i.report(i.store.Delete(context.WithoutCancel(ctx), storeKey))
+		}
+	}()
+	next.ServeHTTP(rec, r)
+	completed = true
+
+	storeCtx := context.WithoutCancel(ctx)
+	if rec.status >= http.StatusInternalServerError {
+		i.report(i.store.Delete(storeCtx, storeKey))
+		return nil
+	}
+	data, err := json.Marshal(idempotencyRecord{
+		Fingerprint: fingerprint,
+		Status:      rec.status,
+		Header:      replayHeader(w.Header()),
+		Body:        rec.body.Bytes(),
+	})
+	if err != nil {
+		i.report(err)
+		return nil
+	}
+	i.report(i.store.Set(storeCtx, storeKey, data, i.cfg.TTL))
+	return nil
+}
+
+// replay writes the stored response of an earlier request with the key
+func (i *Idempotency) replay(w http.ResponseWriter, r *http.Request, storeKey, fingerprint string) error {
+	data, err := i.store.GetBytes(r.Context(), storeKey)
+	if err != nil {
+		// The record may have just expired; the client can retry
+		return errors.ErrUnavailable.Wrap(err)
+	}
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return errors.ErrInternal.Wrap(fmt.Errorf("decode idempotency record: %w", err))
+	}
+	switch {
+	case record.Fingerprint != fingerprint:
+		return ErrIdempotencyKeyReused
+	case record.Status == 0:
+		return ErrIdempotencyInProgress
+	}
+
+	h := w.Header()
+	for k, v := range record.Header {
+		h[k] = v
+	}
+	h.Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.Status)
+	_, err = w.Write(record.Body)
+	return err
+}
+
+// report passes err, if any, to the error handler
+func (i *Idempotency) report(err error) {
+	if err != nil && i.cfg.OnError != nil {
+		i.cfg.OnError(err)
+	}
+}
+
+// replayHeader returns the response headers worth replaying; cookies and
+// per-response headers are left out
+func replayHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, k := range []string{"Set-Cookie", "Date", "Content-Length", "X-Request-Id", "Traceparent"} {
+		out.Del(k)
+	}
+	return out
+}
+
+// captureWriter is an http.ResponseWriter that keeps a copy of the status
+// and body
+type captureWriter struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *captureWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (w *captureWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,153 @@
+package httpmw
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"mora/pkg/errors"
+)
+
+// memoryIdempotencyStore is an IdempotencyStore on a map
+type memoryIdempotencyStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{data: make(map[string][]byte)}
+}
+
+func (s *memoryIdempotencyStore) SetNX(_ context.Context, key string, value interface{}, _ time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data[key]; ok {
+		return false, nil
+	}
+	s.data[key] = value.([]byte)
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) GetBytes(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	if !ok {
+		return nil, stderrors.New("not found")
+	}
+	return v, nil
+}
+
+func (s *memoryIdempotencyStore) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value.([]byte)
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.data, key)
+	}
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	idem := NewIdempotency(store, IdempotencyConfig{})
+
+	calls := 0
+	status := http.StatusCreated
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=1")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"id":42}`))
+	})
+	serve := func(method, path, key, scope string) (*httptest.ResponseRecorder, error) {
+		r := httptest.NewRequest(method, path, nil)
+		if key != "" {
+			r.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		return w, idem.Serve(w, r, scope, handler)
+	}
+
+	w, err := serve(http.MethodPost, "/orders", "k1", "user:1")
+	if err != nil || w.Code != http.StatusCreated || calls != 1 {
+		t.Fatalf("first request: status %d, calls %d, err %v", w.Code, calls, err)
+	}
+
+	t.Run("replayed", func(t *testing.T) {
+		w, err := serve(http.MethodPost, "/orders", "k1", "user:1")
+		if err != nil || calls != 1 {
+			t.Fatalf("calls = %d, err = %v", calls, err)
+		}
+		if w.Code != http.StatusCreated || w.Body.String() != `{"id":42}` {
+			t.Errorf("replay = %d %q", w.Code, w.Body.String())
+		}
+		if w.Header().Get("Idempotent-Replayed") != "true" || w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("replay headers = %v", w.Header())
+		}
+		if w.Header().Get("Set-Cookie") != "" {
+			t.Error("cookies should not be replayed")
+		}
+	})
+
+	t.Run("other scope", func(t *testing.T) {
+		before := calls
+		if _, err := serve(http.MethodPost, "/orders", "k1", "user:2"); err != nil || calls != before+1 {
+			t.Errorf("calls = %d, err = %v", calls-before, err)
+		}
+	})
+
+	t.Run("reused for another path", func(t *testing.T) {
+		if _, err := serve(http.MethodPost, "/payments", "k1", "user:1"); !stderrors.Is(err, ErrIdempotencyKeyReused) {
+			t.Errorf("err = %v, want ErrIdempotencyKeyReused", err)
+		}
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		store.SetNX(context.Background(), "idempotency:user:1:k2", []byte(`{"fingerprint":"POST /orders"}`), time.Minute)
+		if _, err := serve(http.MethodPost, "/orders", "k2", "user:1"); !stderrors.Is(err, errors.ErrConflict) {
+			t.Errorf("err = %v, want ErrConflict", err)
+		}
+	})
+
+	t.Run("server errors are not stored", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		defer func() { status = http.StatusCreated }()
+		before := calls
+		serve(http.MethodPost, "/orders", "k3", "user:1")
+		serve(http.MethodPost, "/orders", "k3", "user:1")
+		if calls != before+2 {
+			t.Errorf("calls = %d, want 2", calls-before)
+		}
+	})
+
+	t.Run("passed through", func(t *testing.T) {
+		before := calls
+		serve(http.MethodPost, "/orders", "", "user:1")
+		serve(http.MethodGet, "/orders", "k1", "user:1")
+		if calls != before+2 {
+			t.Errorf("calls = %d, want 2", calls-before)
+		}
+	})
+
+	t.Run("key too long", func(t *testing.T) {
+		long := make([]byte, maxIdempotencyKey+1)
+		for i := range long {
+			long[i] = 'a'
+		}
+		if _, err := serve(http.MethodPost, "/orders", string(long), "user:1"); !stderrors.Is(err, errors.ErrBadRequest) {
+			t.Errorf("err = %v, want ErrBadRequest", err)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package httpmw
+
+import (
+	"net/http"
+
+	"mora/pkg/errors"
+	"mora/pkg/logger"
+	"mora/pkg/ratelimit"
+	"mora/pkg/response"
+	"mora/pkg/utils"
+)
+
+// RateLimitConfig holds the configuration for the rate limit middleware
+type RateLimitConfig struct {
+	Limiter ratelimit.Limiter
+	// KeyFunc identifies whose budget a request takes from, RateLimitKey
+	// when nil
+	KeyFunc func(r *http.Request) string
+	// SkipPaths contains paths that are not limited, in the NewSkipper syntax
+	SkipPaths []string
+	// Skipper, when set, skips requests SkipPaths cannot express
+	Skipper Skipper
+	// ErrorHandler writes the ErrTooManyRequests response, response.Err
+	// when nil
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// RateLimitKey keys authenticated requests by the user ID pkg/logger reads
+// from their context and others by the peer address; behind a proxy, key
+// by utils.RealIP with the proxy's prefixes
+func RateLimitKey(r *http.Request) string {
+	if userID := logger.GetUserIDFromContext(r.Context()); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + utils.RealIP(r, nil)
+}
+
+// RateLimiter applies a ratelimit.Limiter to requests
+type RateLimiter struct {
+	limiter ratelimit.Limiter
+	skip    Skipper
+}
+
+// NewRateLimiter creates a rate limiter; frameworks with their own request
+// keys call Take, others use RateLimit
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		limiter: config.Limiter,
+		skip:    NewSkipper(config.SkipPaths, config.Skipper),
+	}
+}
+
+// Skip reports whether r is not limited
+func (l *RateLimiter) Skip(r *http.Request) bool {
+	return l.skip(r)
+}
+
+// Take takes a request from key's budget and sets the X-RateLimit-* headers
+// on w. It returns ErrTooManyRequests when the budget is spent, and nil
+// when the limiter fails so an unreachable Redis does not take the service
+// down.
+func (l *RateLimiter) Take(w http.ResponseWriter, r *http.Request, key string) error {
+	res, err := l.limiter.Allow(r.Context(), key)
+	if err != nil {
+		return nil
+	}
+	res.SetHeaders(w.Header())
+	if !res.Allowed {
+		return errors.ErrTooManyRequests
+	}
+	return nil
+}
+
+// RateLimit creates a middleware that limits requests per key with a
+// RateLimiter; apply it inside the auth middleware to limit by user
+func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
+	l := NewRateLimiter(config)
+	key := config.KeyFunc
+	if key == nil {
+		key = RateLimitKey
+	}
+	writeErr := config.ErrorHandler
+	if writeErr == nil {
+		writeErr = response.Err
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if l.Skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err := l.Take(w, r, key(r)); err != nil {
+				writeErr(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
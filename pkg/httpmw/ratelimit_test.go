@@ -0,0 +1,93 @@
+package httpmw
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mora/pkg/logger"
+	"mora/pkg/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		result     ratelimit.Result
+		err        error
+		wantStatus int
+		wantHeader map[string]string
+	}{
+		{
+			name:       "allowed",
+			path:       "/orders",
+			result:     ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: time.Second},
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"X-RateLimit-Limit": "10", "X-RateLimit-Remaining": "9", "Retry-After": ""},
+		},
+		{
+			name:       "over budget",
+			path:       "/orders",
+			result:     ratelimit.Result{Limit: 10, ResetAfter: time.Minute, RetryAfter: time.Second},
+			wantStatus: http.StatusTooManyRequests,
+			wantHeader: map[string]string{"X-RateLimit-Remaining": "0", "Retry-After": "1"},
+		},
+		{
+			name:       "limiter down",
+			path:       "/orders",
+			err:        errors.New("connection refused"),
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"X-RateLimit-Limit": ""},
+		},
+		{
+			name:       "skipped",
+			path:       "/health",
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"X-RateLimit-Limit": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var key string
+			limiter := ratelimit.LimiterFunc(func(ctx context.Context, k string) (ratelimit.Result, error) {
+				key = k
+				return tt.result, tt.err
+			})
+			h := RateLimit(RateLimitConfig{Limiter: limiter, SkipPaths: []string{"/health"}})(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r = r.WithContext(logger.WithUserID(r.Context(), "user-1"))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			for k, v := range tt.wantHeader {
+				if got := w.Header().Get(k); got != v {
+					t.Errorf("%s = %q, want %q", k, got, v)
+				}
+			}
+			if tt.path != "/health" && key != "user:user-1" {
+				t.Errorf("key = %q, want user:user-1", key)
+			}
+		})
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "203.0.113.7:4321"
+	if got := RateLimitKey(r); got != "ip:203.0.113.7" {
+		t.Errorf("anonymous key = %q, want ip:203.0.113.7", got)
+	}
+	r = r.WithContext(logger.WithUserID(r.Context(), "user-1"))
+	if got := RateLimitKey(r); got != "user:user-1" {
+		t.Errorf("authenticated key = %q, want user:user-1", got)
+	}
+}
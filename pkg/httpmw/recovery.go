@@ -0,0 +1,86 @@
+package httpmw
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"mora/pkg/errors"
+	"mora/pkg/logger"
+	"mora/pkg/response"
+)
+
+// PanicNotifier reports a recovered panic, e.g. to Sentry
+type PanicNotifier func(r *http.Request, recovered any, stack []byte)
+
+// RecoveryConfig holds the configuration for the recovery middleware
+type RecoveryConfig struct {
+	// Logger writes the panic and its stack, logger.NewDefault() when nil
+	Logger *logger.Logger
+	// Notifier, when set, is called with every recovered panic
+	Notifier PanicNotifier
+	// ErrorHandler writes the ErrInternal response, response.Err when nil
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Recoverer handles panics recovered from handlers
+type Recoverer struct {
+	config RecoveryConfig
+}
+
+// NewRecoverer creates a recoverer; frameworks that recover panics
+// themselves call Handle, others use Recovery
+func NewRecoverer(config RecoveryConfig) *Recoverer {
+	if config.Logger == nil {
+		config.Logger = logger.NewDefault()
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = response.Err
+	}
+	return &Recoverer{config: config}
+}
+
+// Handle logs a value recovered while serving r with its stack and trace
+// ID, passes it to the Notifier and, unless the response has been written,
+// responds with the pkg/errors ErrInternal envelope. Call it from the
+// deferred function that recovered. http.ErrAbortHandler is re-panicked so
+// net/http still aborts the response.
+func (p *Recoverer) Handle(w http.ResponseWriter, r *http.Request, recovered any, written bool) {
+	if recovered == http.ErrAbortHandler {
+		panic(recovered)
+	}
+
+	stack := debug.Stack()
+	p.config.Logger.WithContext(r.Context()).WithFields(
+		logger.String("method", r.Method),
+		logger.String("path", r.URL.Path),
+		logger.String("panic", fmt.Sprint(recovered)),
+		logger.String("stack", string(stack)),
+	).Error("panic recovered")
+	if p.config.Notifier != nil {
+		p.config.Notifier(r, recovered, stack)
+	}
+
+	// A response already under way cannot be replaced
+	if written {
+		return
+	}
+	p.config.ErrorHandler(w, r, errors.ErrInternal.WithDetail("panic: %v", recovered))
+}
+
+// Recovery creates a middleware that recovers from panics in later handlers
+// with a Recoverer
+func Recovery(config RecoveryConfig) func(http.Handler) http.Handler {
+	p := NewRecoverer(config)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := NewStatusRecorder(w)
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					p.Handle(w, r, recovered, rec.Written())
+				}
+			}()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
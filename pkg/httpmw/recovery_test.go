@@ -0,0 +1,89 @@
+package httpmw
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"mora/pkg/logger"
+)
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantPanic  bool
+	}{
+		{
+			name:       "no panic",
+			handler:    func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) },
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "panic",
+			handler:    func(w http.ResponseWriter, r *http.Request) { panic("boom") },
+			wantStatus: http.StatusInternalServerError,
+			wantPanic:  true,
+		},
+		{
+			name: "panic after the response started",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panic("boom")
+			},
+			wantStatus: http.StatusAccepted,
+			wantPanic:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log, err := logger.NewWithBackend(logger.Config{Level: "info", Format: "json"}, logger.NewZerologBackend(zerolog.New(&buf)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var notified any
+			h := Recovery(RecoveryConfig{
+				Logger:   log,
+				Notifier: func(r *http.Request, recovered any, stack []byte) { notified = recovered },
+			})(tt.handler)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if !tt.wantPanic {
+				if notified != nil || buf.Len() > 0 {
+					t.Errorf("notified %v, logged %q, want nothing", notified, buf.String())
+				}
+				return
+			}
+			if notified != "boom" {
+				t.Errorf("notified %v, want boom", notified)
+			}
+			if out := buf.String(); !strings.Contains(out, `"message":"panic recovered"`) || !strings.Contains(out, `"stack":`) {
+				t.Errorf("logged %q, want the panic with its stack", out)
+			}
+		})
+	}
+}
+
+func TestRecoveryRepanicsAbortHandler(t *testing.T) {
+	h := Recovery(RecoveryConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", recovered)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
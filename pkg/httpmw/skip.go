@@ -0,0 +1,81 @@
+package httpmw
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Skipper reports whether a request should bypass a middleware
+type Skipper func(r *http.Request) bool
+
+// skipRule is a compiled SkipPaths pattern
+type skipRule struct {
+	method string
+	path   string
+	prefix bool
+	glob   bool
+	re     *regexp.Regexp
+}
+
+// NewSkipper compiles SkipPaths patterns into a Skipper that also consults
+// custom. A pattern is an exact path, a path/* prefix, a glob such as
+// "/users/*/avatar" matched with path.Match, or a ~regexp matched against the
+// whole path, optionally preceded by a method as in "GET /public/*". It
+// panics on an invalid pattern, like regexp.MustCompile, as that is a
+// configuration error.
+func NewSkipper(patterns []string, custom Skipper) Skipper {
+	rules := make([]skipRule, 0, len(patterns))
+	for _, p := range patterns {
+		var rule skipRule
+		if method, rest, ok := strings.Cut(p, " "); ok && !strings.HasPrefix(p, "/") && !strings.HasPrefix(p, "~") {
+			rule.method, p = strings.ToUpper(method), strings.TrimSpace(rest)
+		}
+		switch {
+		case strings.HasPrefix(p, "~"):
+			re, err := regexp.Compile("^(?:" + p[1:] + ")$")
+			if err != nil {
+				panic(fmt.Sprintf("httpmw: invalid skip pattern %q: %v", p, err))
+			}
+			rule.re = re
+		case strings.HasSuffix(p, "/*") && !strings.ContainsAny(p[:len(p)-1], "*?["):
+			rule.path, rule.prefix = strings.TrimSuffix(p, "/*"), true
+		case strings.ContainsAny(p, "*?["):
+			if _, err := path.Match(p, ""); err != nil {
+				panic(fmt.Sprintf("httpmw: invalid skip pattern %q: %v", p, err))
+			}
+			rule.path, rule.glob = p, true
+		default:
+			rule.path = p
+		}
+		rules = append(rules, rule)
+	}
+
+	return func(r *http.Request) bool {
+		for _, rule := range rules {
+			if rule.match(r) {
+				return true
+			}
+		}
+		return custom != nil && custom(r)
+	}
+}
+
+// match reports whether the rule covers the request
+func (rule skipRule) match(r *http.Request) bool {
+	if rule.method != "" && rule.method != r.Method {
+		return false
+	}
+	switch {
+	case rule.re != nil:
+		return rule.re.MatchString(r.URL.Path)
+	case rule.glob:
+		ok, _ := path.Match(rule.path, r.URL.Path)
+		return ok
+	case rule.prefix:
+		return strings.HasPrefix(r.URL.Path, rule.path)
+	}
+	return r.URL.Path == rule.path
+}
@@ -0,0 +1,67 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewSkipper(t *testing.T) {
+	patterns := []string{
+		"/health",
+		"/static/*",
+		"/users/*/avatar",
+		"/files/v?/*.png",
+		"~/api/v[0-9]+/ping",
+		"GET /public/*",
+	}
+	custom := func(r *http.Request) bool { return r.Header.Get("X-Skip") != "" }
+	skip := NewSkipper(patterns, custom)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		want   bool
+	}{
+		{"exact", http.MethodGet, "/health", "", true},
+		{"exact only", http.MethodGet, "/health/live", "", false},
+		{"prefix", http.MethodGet, "/static/js/app.js", "", true},
+		{"prefix root", http.MethodGet, "/static", "", true},
+		{"glob segment", http.MethodGet, "/users/42/avatar", "", true},
+		{"glob stays in segment", http.MethodGet, "/users/42/7/avatar", "", false},
+		{"glob suffix", http.MethodGet, "/files/v2/logo.png", "", true},
+		{"glob mismatch", http.MethodGet, "/files/v2/logo.jpg", "", false},
+		{"regexp", http.MethodGet, "/api/v12/ping", "", true},
+		{"regexp whole path", http.MethodGet, "/api/v12/ping/x", "", false},
+		{"method", http.MethodGet, "/public/index.html", "", true},
+		{"other method", http.MethodPost, "/public/index.html", "", false},
+		{"custom", http.MethodPost, "/orders", "1", true},
+		{"no match", http.MethodPost, "/orders", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				r.Header.Set("X-Skip", tt.header)
+			}
+			if got := skip(r); got != tt.want {
+				t.Errorf("skip(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSkipperInvalid(t *testing.T) {
+	for _, p := range []string{"~(", "/files/[a"} {
+		t.Run(p, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewSkipper(%q) did not panic", p)
+				}
+			}()
+			NewSkipper([]string{p}, nil)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package httpmw
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultTokenLookup reads a Bearer token from the Authorization header
+const DefaultTokenLookup = "header:Authorization:Bearer"
+
+// tokenSource is one compiled TokenLookup entry
+type tokenSource struct {
+	kind   string
+	name   string
+	scheme string
+}
+
+// TokenExtractor reads a token from the first source that carries one
+type TokenExtractor struct {
+	sources []tokenSource
+}
+
+// ParseTokenLookup compiles a TokenLookup such as
+// "header:Authorization:Bearer,cookie:access_token,query:token"; an empty
+// lookup is DefaultTokenLookup. Header entries may name the scheme the value
+// must start with, compared case-insensitively.
+func ParseTokenLookup(lookup string) (*TokenExtractor, error) {
+	if lookup == "" {
+		lookup = DefaultTokenLookup
+	}
+	e := &TokenExtractor{}
+	for _, entry := range strings.Split(lookup, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) < 2 || parts[0] == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("httpmw: invalid token lookup %q", entry)
+		}
+		source := tokenSource{kind: parts[0], name: strings.TrimSpace(parts[1])}
+		switch source.kind {
+		case "header":
+			if len(parts) == 3 {
+				source.scheme = strings.TrimSpace(parts[2])
+			}
+		case "cookie", "query":
+			if len(parts) == 3 {
+				return nil, fmt.Errorf("httpmw: token lookup %q: only headers take a scheme", entry)
+			}
+		default:
+			return nil, fmt.Errorf("httpmw: unknown token source %q", source.kind)
+		}
+		e.sources = append(e.sources, source)
+	}
+	return e, nil
+}
+
+// NewTokenExtractor is like ParseTokenLookup but panics on an invalid
+// lookup, as that is a configuration error
+func NewTokenExtractor(lookup string) *TokenExtractor {
+	e, err := ParseTokenLookup(lookup)
+	if err != nil {
+		panic(err.Error())
+	}
+	return e
+}
+
+// Extract returns the request's token, or the message to reject it with
+func (e *TokenExtractor) Extract(r *http.Request) (string, string) {
+	for _, source := range e.sources {
+		var value string
+		switch source.kind {
+		case "header":
+			value = r.Header.Get(source.name)
+		case "cookie":
+			if cookie, err := r.Cookie(source.name); err == nil {
+				value = cookie.Value
+			}
+		case "query":
+			value = r.URL.Query().Get(source.name)
+		}
+		if value == "" {
+			continue
+		}
+
+		if source.scheme != "" {
+			n := len(source.scheme)
+			if len(value) <= n || value[n] != ' ' || !strings.EqualFold(value[:n], source.scheme) {
+				return "", fmt.Sprintf("invalid %s header format", strings.ToLower(source.name))
+			}
+			value = strings.TrimSpace(value[n+1:])
+		}
+		if value == "" {
+			return "", "missing token"
+		}
+		return value, ""
+	}
+
+	if len(e.sources) == 1 && e.sources[0].kind == "header" {
+		return "", fmt.Sprintf("missing %s header", strings.ToLower(e.sources[0].name))
+	}
+	return "", "missing token"
+}
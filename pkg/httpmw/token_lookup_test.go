@@ -0,0 +1,94 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseTokenLookupInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		lookup  string
+		wantErr string
+	}{
+		{"missing source", ":Authorization", "invalid token lookup"},
+		{"missing name", "header:", "invalid token lookup"},
+		{"no separator", "header", "invalid token lookup"},
+		{"empty entry", "header:Authorization,", "invalid token lookup"},
+		{"unknown source", "form:token", "unknown token source"},
+		{"scheme on cookie", "cookie:session:Bearer", "only headers take a scheme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTokenLookup(tt.lookup)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseTokenLookup(%q) error = %v, want %q", tt.lookup, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewTokenExtractorPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewTokenExtractor did not panic on an unknown source")
+		}
+	}()
+	NewTokenExtractor("body:token")
+}
+
+func TestTokenExtractor(t *testing.T) {
+	tests := []struct {
+		name        string
+		lookup      string
+		header      string
+		cookie      string
+		query       string
+		wantToken   string
+		wantMessage string
+	}{
+		{name: "default bearer", header: "Bearer abc", wantToken: "abc"},
+		{name: "scheme case-insensitive", header: "bearer abc", wantToken: "abc"},
+		{name: "extra spaces", header: "Bearer   abc ", wantToken: "abc"},
+		{name: "wrong scheme", header: "Basic abc", wantMessage: "invalid authorization header format"},
+		{name: "scheme without space", header: "Bearerabc", wantMessage: "invalid authorization header format"},
+		{name: "scheme only", header: "Bearer", wantMessage: "invalid authorization header format"},
+		{name: "empty token", header: "Bearer  ", wantMessage: "missing token"},
+		{name: "missing header", wantMessage: "missing authorization header"},
+		{name: "raw header", lookup: "header:X-Token", header: "abc", wantToken: "abc"},
+		{name: "cookie", lookup: "header:X-Token,cookie:session", cookie: "abc", wantToken: "abc"},
+		{name: "query fallback", lookup: "header:X-Token,cookie:session,query:token", query: "abc", wantToken: "abc"},
+		{name: "first source wins", lookup: "header:X-Token,query:token", header: "h", query: "q", wantToken: "h"},
+		{name: "missing any", lookup: "cookie:session,query:token", wantMessage: "missing token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := ParseTokenLookup(tt.lookup)
+			if err != nil {
+				t.Fatalf("ParseTokenLookup() error = %v", err)
+			}
+			target := "/"
+			if tt.query != "" {
+				target += "?token=" + tt.query
+			}
+			r := httptest.NewRequest(http.MethodGet, target, nil)
+			if tt.header != "" {
+				name := "Authorization"
+				if tt.lookup != "" {
+					name = "X-Token"
+				}
+				r.Header.Set(name, tt.header)
+			}
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "session", Value: tt.cookie})
+			}
+
+			token, message := e.Extract(r)
+			if token != tt.wantToken || message != tt.wantMessage {
+				t.Errorf("Extract() = %q, %q, want %q, %q", token, message, tt.wantToken, tt.wantMessage)
+			}
+		})
+	}
+}
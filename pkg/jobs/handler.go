@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	moraerrors "mora/pkg/errors"
+	"mora/pkg/response"
+)
+
+// NewHandler returns an admin API over store, answering with pkg/response
+// envelopes:
+//
+//	GET    /jobs?queue=mail&status=dead&limit=50&offset=0
+//	GET    /jobs/{id}
+//	POST   /jobs/{id}/requeue
+//	DELETE /jobs/{id}
+//
+// Mount it behind authentication, e.g.
+// mux.Handle("/admin/", http.StripPrefix("/admin", jobs.NewHandler(store)))
+func NewHandler(store Store) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /jobs", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		opts := ListOptions{Queue: q.Get("queue"), Status: Status(q.Get("status"))}
+		var err error
+		if opts.Limit, err = intParam(q.Get("limit")); err != nil {
+			response.Err(w, r, moraerrors.ErrBadRequest.WithMessage("invalid limit"))
+			return
+		}
+		if opts.Offset, err = intParam(q.Get("offset")); err != nil {
+			response.Err(w, r, moraerrors.ErrBadRequest.WithMessage("invalid offset"))
+			return
+		}
+		list, err := store.List(r.Context(), opts)
+		if err != nil {
+			response.Err(w, r, err)
+			return
+		}
+		if list == nil {
+			list = []*Job{}
+		}
+		response.OK(w, r, list)
+	})
+	mux.HandleFunc("GET /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		job, err := store.Get(r.Context(), r.PathValue("id"))
+		if err != nil {
+			response.Err(w, r, apiError(err))
+			return
+		}
+		response.OK(w, r, job)
+	})
+	mux.HandleFunc("POST /jobs/{id}/requeue", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if err := store.Requeue(r.Context(), id, time.Now()); err != nil {
+			response.Err(w, r, apiError(err))
+			return
+		}
+		job, err := store.Get(r.Context(), id)
+		if err != nil {
+			response.Err(w, r, apiError(err))
+			return
+		}
+		response.OK(w, r, job)
+	})
+	mux.HandleFunc("DELETE /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if err := store.Delete(r.Context(), r.PathValue("id")); err != nil {
+			response.Err(w, r, apiError(err))
+			return
+		}
+		response.OK(w, r, nil)
+	})
+	return mux
+}
+
+// apiError maps store errors to coded errors
+func apiError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return moraerrors.ErrNotFound.WithMessage("job not found")
+	case errors.Is(err, ErrNotDead):
+		return moraerrors.ErrConflict.WithMessage("only dead jobs can be requeued")
+	}
+	return err
+}
+
+// intParam parses an optional non-negative integer query parameter
+func intParam(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid integer")
+	}
+	return n, nil
+}
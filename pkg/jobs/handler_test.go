@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	job, _ := NewClient(store).Enqueue(ctx, "t", map[string]int{"n": 1})
+	claimed, _ := store.Claim(ctx, DefaultQueue, time.Now(), time.Minute)
+	claimed.LastError = "boom"
+	claimed.UpdatedAt = time.Now()
+	store.Kill(ctx, claimed)
+	pending, _ := NewClient(store).Enqueue(ctx, "t", nil, Delay(time.Hour))
+
+	h := NewHandler(store)
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantCode   int
+		wantIDs    []string
+	}{
+		{name: "list dead", method: http.MethodGet, path: "/jobs?status=dead", wantStatus: 200, wantIDs: []string{job.ID}},
+		{name: "invalid limit", method: http.MethodGet, path: "/jobs?limit=x", wantStatus: 400, wantCode: 40000},
+		{name: "get", method: http.MethodGet, path: "/jobs/" + job.ID, wantStatus: 200},
+		{name: "get missing", method: http.MethodGet, path: "/jobs/missing", wantStatus: 404, wantCode: 40400},
+		{name: "requeue pending", method: http.MethodPost, path: "/jobs/" + pending.ID + "/requeue", wantStatus: 409, wantCode: 40900},
+		{name: "requeue dead", method: http.MethodPost, path: "/jobs/" + job.ID + "/requeue", wantStatus: 200},
+		{name: "list after requeue", method: http.MethodGet, path: "/jobs?status=dead", wantStatus: 200, wantIDs: []string{}},
+		{name: "delete", method: http.MethodDelete, path: "/jobs/" + pending.ID, wantStatus: 200},
+		{name: "list all", method: http.MethodGet, path: "/jobs", wantStatus: 200, wantIDs: []string{job.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var resp struct {
+				Code int             `json:"code"`
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid body %s: %v", w.Body, err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", resp.Code, tt.wantCode)
+			}
+			if tt.wantIDs != nil {
+				var list []Job
+				json.Unmarshal(resp.Data, &list)
+				if len(list) != len(tt.wantIDs) {
+					t.Fatalf("listed %d jobs, want %d: %s", len(list), len(tt.wantIDs), resp.Data)
+				}
+				for i, id := range tt.wantIDs {
+					if list[i].ID != id {
+						t.Errorf("job %d = %s, want %s", i, list[i].ID, id)
+					}
+				}
+			}
+		})
+	}
+}
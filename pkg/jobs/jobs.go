@@ -0,0 +1,202 @@
+// Package jobs runs background jobs from a persistent queue. Jobs are
+// enqueued to run now, after a delay or at a set time, stored in Redis or a
+// SQL database, and executed by a worker pool with per-queue concurrency.
+// Failed jobs are retried with exponential backoff and, once out of
+// attempts, kept as dead jobs that can be listed and requeued.
+//
+// Jobs run at least once: a worker that dies mid-job loses its lease and
+// the job runs again, so handlers should be idempotent.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"mora/pkg/utils"
+)
+
+// DefaultQueue is the queue of jobs enqueued without the Queue option
+const DefaultQueue = "default"
+
+// Status is the state of a job
+type Status string
+
+const (
+	// StatusPending jobs wait for RunAt
+	StatusPending Status = "pending"
+	// StatusRunning jobs are leased by a worker until RunAt
+	StatusRunning Status = "running"
+	// StatusDead jobs ran out of attempts or failed permanently
+	StatusDead Status = "dead"
+)
+
+var (
+	// ErrNotFound is returned for an unknown job ID
+	ErrNotFound = errors.New("jobs: job not found")
+	// ErrLeaseLost is returned when finishing a job whose lease expired and
+	// that another worker may have claimed
+	ErrLeaseLost = errors.New("jobs: lease lost")
+	// ErrNotDead is returned when requeueing a job that is not dead
+	ErrNotDead = errors.New("jobs: job is not dead")
+)
+
+// Job is a unit of background work
+type Job struct {
+	ID      string          `json:"id"`
+	Queue   string          `json:"queue"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+	Status  Status          `json:"status"`
+	// Attempt counts the runs so far, including the current one
+	Attempt     int `json:"attempt"`
+	MaxAttempts int `json:"max_attempts"`
+	// RunAt is when a pending job is due, when a running job's lease
+	// expires, or when a dead job died
+	RunAt     time.Time `json:"run_at"`
+	LastError string    `json:"last_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Decode unmarshals the payload into v
+func (j *Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return fmt.Errorf("jobs: failed to decode %s payload: %w", j.Type, err)
+	}
+	return nil
+}
+
+// permanentError marks an error that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err so the job goes straight to the dead jobs
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// enqueueOptions collects the EnqueueOption values
+type enqueueOptions struct {
+	queue       string
+	runAt       time.Time
+	delay       time.Duration
+	maxAttempts int
+}
+
+// EnqueueOption customizes an enqueued job
+type EnqueueOption func(*enqueueOptions)
+
+// Queue puts the job on a named queue instead of DefaultQueue
+func Queue(name string) EnqueueOption {
+	return func(o *enqueueOptions) { o.queue = name }
+}
+
+// Delay runs the job after d
+func Delay(d time.Duration) EnqueueOption {
+	return func(o *enqueueOptions) { o.delay = d }
+}
+
+// At runs the job at t
+func At(t time.Time) EnqueueOption {
+	return func(o *enqueueOptions) { o.runAt = t }
+}
+
+// MaxAttempts bounds the runs of the job, 5 by default
+func MaxAttempts(n int) EnqueueOption {
+	return func(o *enqueueOptions) { o.maxAttempts = n }
+}
+
+// Client enqueues jobs
+type Client struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewClient creates a client enqueueing into store
+func NewClient(store Store) *Client {
+	return &Client{store: store, now: time.Now}
+}
+
+// Enqueue stores a job of jobType whose payload is the JSON encoding of payload
+func (c *Client) Enqueue(ctx context.Context, jobType string, payload any, opts ...EnqueueOption) (*Job, error) {
+	o := enqueueOptions{queue: DefaultQueue, maxAttempts: 5}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("jobs: failed to encode %s payload: %w", jobType, err)
+	}
+	id, err := utils.GenerateULID()
+	if err != nil {
+		return nil, fmt.Errorf("jobs: failed to generate id: %w", err)
+	}
+
+	now := c.now()
+	runAt := o.runAt
+	if runAt.IsZero() {
+		runAt = now.Add(o.delay)
+	}
+	job := &Job{
+		ID:          id,
+		Queue:       o.queue,
+		Type:        jobType,
+		Payload:     data,
+		Status:      StatusPending,
+		MaxAttempts: max(1, o.maxAttempts),
+		RunAt:       runAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := c.store.Enqueue(ctx, job); err != nil {
+		return nil, fmt.Errorf("jobs: failed to enqueue %s: %w", jobType, err)
+	}
+	return job, nil
+}
+
+// Task is a job type with a typed payload, e.g.
+//
+//	var SendWelcome = jobs.NewTask[WelcomeEmail]("send-welcome", jobs.Queue("mail"))
+//
+//	SendWelcome.Enqueue(ctx, client, WelcomeEmail{UserID: id}, jobs.Delay(time.Minute))
+//	worker.Register(SendWelcome.Name, SendWelcome.Handler(sendWelcome))
+type Task[T any] struct {
+	Name string
+	// Options apply to every enqueued job, before the per-call options
+	Options []EnqueueOption
+}
+
+// NewTask defines a task
+func NewTask[T any](name string, opts ...EnqueueOption) Task[T] {
+	return Task[T]{Name: name, Options: opts}
+}
+
+// Enqueue enqueues a job of the task
+func (t Task[T]) Enqueue(ctx context.Context, c *Client, payload T, opts ...EnqueueOption) (*Job, error) {
+	return c.Enqueue(ctx, t.Name, payload, append(append([]EnqueueOption{}, t.Options...), opts...)...)
+}
+
+// Handler adapts fn to a Handler that decodes the payload; payloads that
+// cannot be decoded fail permanently
+func (t Task[T]) Handler(fn func(ctx context.Context, payload T) error) Handler {
+	return func(ctx context.Context, job *Job) error {
+		var payload T
+		if err := job.Decode(&payload); err != nil {
+			return Permanent(err)
+		}
+		return fn(ctx, payload)
+	}
+}
@@ -0,0 +1,292 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mora/pkg/cache"
+)
+
+// RedisStore is a Store in Redis through pkg/cache. Each job is a hash;
+// pending and running jobs of a queue share a sorted set scored by RunAt,
+// and dead jobs are kept in a sorted set per queue. Scripts derive job keys
+// from the prefix, so on Redis Cluster use a hash-tagged prefix such as "{jobs}:".
+type RedisStore struct {
+	client *cache.Client
+	prefix string
+}
+
+// NewRedisStore creates a store whose keys start with prefix, "jobs:" when empty
+func NewRedisStore(client *cache.Client, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "jobs:"
+	}
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) jobKey(id string) string      { return s.prefix + "job:" + id }
+func (s *RedisStore) queueKey(queue string) string { return s.prefix + "queue:" + queue }
+func (s *RedisStore) deadKey(queue string) string  { return s.prefix + "dead:" + queue }
+func (s *RedisStore) queuesKey() string            { return s.prefix + "queues" }
+
+// Enqueue implements Store
+func (s *RedisStore) Enqueue(ctx context.Context, job *Job) error {
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, s.jobKey(job.ID), jobFields(job))
+	pipe.ZAdd(ctx, s.queueKey(job.Queue), redis.Z{Score: float64(job.RunAt.UnixMilli()), Member: job.ID})
+	pipe.SAdd(ctx, s.queuesKey(), job.Queue)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("jobs redis: failed to enqueue: %w", err)
+	}
+	return nil
+}
+
+// jobFields encodes a job as hash fields; times are Unix milliseconds
+func jobFields(j *Job) map[string]any {
+	return map[string]any{
+		"queue":        j.Queue,
+		"type":         j.Type,
+		"payload":      string(j.Payload),
+		"status":       string(j.Status),
+		"attempt":      j.Attempt,
+		"max_attempts": j.MaxAttempts,
+		"run_at":       j.RunAt.UnixMilli(),
+		"last_error":   j.LastError,
+		"created_at":   j.CreatedAt.UnixMilli(),
+		"updated_at":   j.UpdatedAt.UnixMilli(),
+	}
+}
+
+// parseJob decodes the hash fields of a job
+func parseJob(id string, h map[string]string) *Job {
+	ms := func(field string) time.Time {
+		n, _ := strconv.ParseInt(h[field], 10, 64)
+		return time.UnixMilli(n)
+	}
+	attempt, _ := strconv.Atoi(h["attempt"])
+	maxAttempts, _ := strconv.Atoi(h["max_attempts"])
+	return &Job{
+		ID:          id,
+		Queue:       h["queue"],
+		Type:        h["type"],
+		Payload:     []byte(h["payload"]),
+		Status:      Status(h["status"]),
+		Attempt:     attempt,
+		MaxAttempts: maxAttempts,
+		RunAt:       ms("run_at"),
+		LastError:   h["last_error"],
+		CreatedAt:   ms("created_at"),
+		UpdatedAt:   ms("updated_at"),
+	}
+}
+
+// claimScript leases the earliest due job of a queue and returns its ID.
+// KEYS[1] is the queue set; ARGV is now, the lease expiry and the job key prefix.
+var claimScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 1)
+if #ids == 0 then
+	return false
+end
+local id = ids[1]
+local key = ARGV[3] .. id
+redis.call('ZADD', KEYS[1], ARGV[2], id)
+redis.call('HSET', key, 'status', 'running', 'run_at', ARGV[2], 'updated_at', ARGV[1])
+redis.call('HINCRBY', key, 'attempt', 1)
+return id
+`)
+
+// finishScript completes, retries or kills a job while its lease holds.
+// KEYS are the job hash, the queue set and the dead set; ARGV is the ID,
+// the attempt, the action, the time to score it at, the error and the
+// update time. It returns 0 when the lease was lost.
+var finishScript = redis.NewScript(`
+local cur = redis.call('HMGET', KEYS[1], 'status', 'attempt')
+if cur[1] ~= 'running' or cur[2] ~= ARGV[2] then
+	return 0
+end
+if ARGV[3] == 'complete' then
+	redis.call('ZREM', KEYS[2], ARGV[1])
+	redis.call('DEL', KEYS[1])
+elseif ARGV[3] == 'retry' then
+	redis.call('ZADD', KEYS[2], ARGV[4], ARGV[1])
+	redis.call('HSET', KEYS[1], 'status', 'pending', 'run_at', ARGV[4], 'last_error', ARGV[5], 'updated_at', ARGV[6])
+else
+	redis.call('ZREM', KEYS[2], ARGV[1])
+	redis.call('ZADD', KEYS[3], ARGV[4], ARGV[1])
+	redis.call('HSET', KEYS[1], 'status', 'dead', 'run_at', ARGV[4], 'last_error', ARGV[5], 'updated_at', ARGV[6])
+end
+return 1
+`)
+
+// requeueScript makes a dead job pending again. KEYS are the job hash, the
+// dead set and the queue set; ARGV is the ID and now. It returns -1 for a
+// missing job and 0 for one that is not dead.
+var requeueScript = redis.NewScript(`
+local status = redis.call('HGET', KEYS[1], 'status')
+if not status then
+	return -1
+end
+if status ~= 'dead' then
+	return 0
+end
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('ZADD', KEYS[3], ARGV[2], ARGV[1])
+redis.call('HSET', KEYS[1], 'status', 'pending', 'attempt', 0, 'run_at', ARGV[2], 'updated_at', ARGV[2])
+return 1
+`)
+
+// Claim implements Store
+func (s *RedisStore) Claim(ctx context.Context, queue string, now time.Time, lease time.Duration) (*Job, error) {
+	id, err := claimScript.Run(ctx, s.client.GetClient(), []string{s.queueKey(queue)},
+		now.UnixMilli(), now.Add(lease).UnixMilli(), s.prefix+"job:").Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jobs redis: failed to claim: %w", err)
+	}
+	return s.Get(ctx, id)
+}
+
+// finish runs finishScript for job
+func (s *RedisStore) finish(ctx context.Context, job *Job, action string, at time.Time) error {
+	n, err := finishScript.Run(ctx, s.client.GetClient(),
+		[]string{s.jobKey(job.ID), s.queueKey(job.Queue), s.deadKey(job.Queue)},
+		job.ID, job.Attempt, action, at.UnixMilli(), job.LastError, job.UpdatedAt.UnixMilli()).Int()
+	if err != nil {
+		return fmt.Errorf("jobs redis: failed to %s job: %w", action, err)
+	}
+	if n == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Complete implements Store
+func (s *RedisStore) Complete(ctx context.Context, job *Job) error {
+	return s.finish(ctx, job, "complete", job.UpdatedAt)
+}
+
+// Retry implements Store
+func (s *RedisStore) Retry(ctx context.Context, job *Job, runAt time.Time) error {
+	return s.finish(ctx, job, "retry", runAt)
+}
+
+// Kill implements Store
+func (s *RedisStore) Kill(ctx context.Context, job *Job) error {
+	return s.finish(ctx, job, "kill", job.UpdatedAt)
+}
+
+// Get implements Store
+func (s *RedisStore) Get(ctx context.Context, id string) (*Job, error) {
+	h, err := s.client.HGetAll(ctx, s.jobKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("jobs redis: failed to load job: %w", err)
+	}
+	if len(h) == 0 {
+		return nil, ErrNotFound
+	}
+	return parseJob(id, h), nil
+}
+
+// List implements Store. Pending and running jobs share a set, so with
+// either status the page is filtered after loading and may come up short.
+func (s *RedisStore) List(ctx context.Context, opts ListOptions) ([]*Job, error) {
+	queues := []string{opts.Queue}
+	if opts.Queue == "" {
+		var err error
+		if queues, err = s.client.SMembers(ctx, s.queuesKey()); err != nil {
+			return nil, fmt.Errorf("jobs redis: failed to list queues: %w", err)
+		}
+	}
+	var keys []string
+	for _, q := range queues {
+		if opts.Status == "" || opts.Status == StatusDead {
+			keys = append(keys, s.deadKey(q))
+		}
+		if opts.Status != StatusDead {
+			keys = append(keys, s.queueKey(q))
+		}
+	}
+
+	// Every set holds at most offset+limit entries of the merged page
+	stop := int64(max(0, opts.Offset) + opts.limit() - 1)
+	var list []*Job
+	for _, key := range keys {
+		ids, err := s.client.GetClient().ZRange(ctx, key, 0, stop).Result()
+		if err != nil {
+			return nil, fmt.Errorf("jobs redis: failed to list jobs: %w", err)
+		}
+		for _, id := range ids {
+			job, err := s.Get(ctx, id)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if opts.Status == "" || job.Status == opts.Status {
+				list = append(list, job)
+			}
+		}
+	}
+	sort.Slice(list, func(i, k int) bool {
+		if !list[i].RunAt.Equal(list[k].RunAt) {
+			return list[i].RunAt.Before(list[k].RunAt)
+		}
+		return list[i].ID < list[k].ID
+	})
+	if opts.Offset >= len(list) {
+		return nil, nil
+	}
+	list = list[max(0, opts.Offset):]
+	return list[:min(len(list), opts.limit())], nil
+}
+
+// Requeue implements Store
+func (s *RedisStore) Requeue(ctx context.Context, id string, now time.Time) error {
+	queue, err := s.client.HGet(ctx, s.jobKey(id), "queue")
+	if errors.Is(err, redis.Nil) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("jobs redis: failed to load job: %w", err)
+	}
+	n, err := requeueScript.Run(ctx, s.client.GetClient(),
+		[]string{s.jobKey(id), s.deadKey(queue), s.queueKey(queue)}, id, now.UnixMilli()).Int()
+	if err != nil {
+		return fmt.Errorf("jobs redis: failed to requeue job: %w", err)
+	}
+	switch n {
+	case -1:
+		return ErrNotFound
+	case 0:
+		return ErrNotDead
+	}
+	return nil
+}
+
+// Delete implements Store
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	queue, err := s.client.HGet(ctx, s.jobKey(id), "queue")
+	if errors.Is(err, redis.Nil) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("jobs redis: failed to load job: %w", err)
+	}
+	pipe := s.client.TxPipeline()
+	pipe.ZRem(ctx, s.queueKey(queue), id)
+	pipe.ZRem(ctx, s.deadKey(queue), id)
+	pipe.Del(ctx, s.jobKey(id))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("jobs redis: failed to delete job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mora/pkg/db"
+)
+
+// SQLStore is a Store in a MySQL, PostgreSQL or SQLite table accessed
+// through pkg/db
+type SQLStore struct {
+	client *db.SQLXClient
+	table  string
+}
+
+// NewSQLStore creates a store in table, "jobs" when empty; call CreateTable
+// or create it with an equivalent migration
+func NewSQLStore(client *db.SQLXClient, table string) *SQLStore {
+	if table == "" {
+		table = "jobs"
+	}
+	return &SQLStore{client: client, table: table}
+}
+
+// CreateTable creates the table and its queue index if they do not exist
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	columns := `id VARCHAR(64) PRIMARY KEY,
+		queue VARCHAR(128) NOT NULL,
+		type VARCHAR(128) NOT NULL,
+		payload TEXT NOT NULL,
+		status VARCHAR(16) NOT NULL,
+		attempt INTEGER NOT NULL,
+		max_attempts INTEGER NOT NULL,
+		run_at BIGINT NOT NULL,
+		last_error TEXT NOT NULL,
+		created_at BIGINT NOT NULL,
+		updated_at BIGINT NOT NULL`
+	index := fmt.Sprintf("idx_%s_queue_run_at", s.table)
+	stmts := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", s.table, columns),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (queue, status, run_at)", index, s.table),
+	}
+	// MySQL has no CREATE INDEX IF NOT EXISTS, so the index is declared inline
+	if s.client.DB().DriverName() == "mysql" {
+		stmts = []string{fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s, INDEX %s (queue, status, run_at))", s.table, columns, index)}
+	}
+	for _, stmt := range stmts {
+		if _, err := s.client.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("jobs: failed to create table: %w", err)
+		}
+	}
+	return nil
+}
+
+// row is the table representation of a Job; times are Unix milliseconds
+type row struct {
+	ID          string `db:"id"`
+	Queue       string `db:"queue"`
+	Type        string `db:"type"`
+	Payload     string `db:"payload"`
+	Status      string `db:"status"`
+	Attempt     int    `db:"attempt"`
+	MaxAttempts int    `db:"max_attempts"`
+	RunAt       int64  `db:"run_at"`
+	LastError   string `db:"last_error"`
+	CreatedAt   int64  `db:"created_at"`
+	UpdatedAt   int64  `db:"updated_at"`
+}
+
+// toRow encodes a job
+func toRow(j *Job) row {
+	return row{
+		ID:          j.ID,
+		Queue:       j.Queue,
+		Type:        j.Type,
+		Payload:     string(j.Payload),
+		Status:      string(j.Status),
+		Attempt:     j.Attempt,
+		MaxAttempts: j.MaxAttempts,
+		RunAt:       j.RunAt.UnixMilli(),
+		LastError:   j.LastError,
+		CreatedAt:   j.CreatedAt.UnixMilli(),
+		UpdatedAt:   j.UpdatedAt.UnixMilli(),
+	}
+}
+
+// job decodes a row
+func (r row) job() *Job {
+	return &Job{
+		ID:          r.ID,
+		Queue:       r.Queue,
+		Type:        r.Type,
+		Payload:     []byte(r.Payload),
+		Status:      Status(r.Status),
+		Attempt:     r.Attempt,
+		MaxAttempts: r.MaxAttempts,
+		RunAt:       time.UnixMilli(r.RunAt),
+		LastError:   r.LastError,
+		CreatedAt:   time.UnixMilli(r.CreatedAt),
+		UpdatedAt:   time.UnixMilli(r.UpdatedAt),
+	}
+}
+
+// exec runs a query rebound to the driver's placeholders and returns the
+// number of affected rows
+func (s *SQLStore) exec(ctx context.Context, query string, args ...any) (int64, error) {
+	res, err := s.client.Exec(ctx, s.client.DB().Rebind(fmt.Sprintf(query, s.table)), args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Enqueue implements Store
+func (s *SQLStore) Enqueue(ctx context.Context, job *Job) error {
+	_, err := s.client.NamedExec(ctx, fmt.Sprintf(`INSERT INTO %s
+		(id, queue, type, payload, status, attempt, max_attempts, run_at, last_error, created_at, updated_at)
+		VALUES (:id, :queue, :type, :payload, :status, :attempt, :max_attempts, :run_at, :last_error, :created_at, :updated_at)`, s.table), toRow(job))
+	if err != nil {
+		return fmt.Errorf("jobs: failed to insert job: %w", err)
+	}
+	return nil
+}
+
+// claimCandidates is how many due jobs Claim tries before giving up to
+// workers racing for the same rows
+const claimCandidates = 5
+
+// Claim implements Store. Candidates are claimed with a conditional update,
+// so concurrent workers never lease the same job.
+func (s *SQLStore) Claim(ctx context.Context, queue string, now time.Time, lease time.Duration) (*Job, error) {
+	var candidates []row
+	query := s.client.DB().Rebind(fmt.Sprintf(`SELECT * FROM %s
+		WHERE queue = ? AND status IN (?, ?) AND run_at <= ?
+		ORDER BY run_at, id LIMIT ?`, s.table))
+	err := s.client.Select(ctx, &candidates, query, queue, StatusPending, StatusRunning, now.UnixMilli(), claimCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("jobs: failed to load due jobs: %w", err)
+	}
+	for _, r := range candidates {
+		n, err := s.exec(ctx, `UPDATE %s SET status = ?, attempt = attempt + 1, run_at = ?, updated_at = ?
+			WHERE id = ? AND status = ? AND attempt = ? AND run_at = ?`,
+			StatusRunning, now.Add(lease).UnixMilli(), now.UnixMilli(), r.ID, r.Status, r.Attempt, r.RunAt)
+		if err != nil {
+			return nil, fmt.Errorf("jobs: failed to claim job: %w", err)
+		}
+		if n == 1 {
+			r.Status = string(StatusRunning)
+			r.Attempt++
+			r.RunAt = now.Add(lease).UnixMilli()
+			r.UpdatedAt = now.UnixMilli()
+			return r.job(), nil
+		}
+	}
+	return nil, nil
+}
+
+// Complete implements Store
+func (s *SQLStore) Complete(ctx context.Context, job *Job) error {
+	n, err := s.exec(ctx, `DELETE FROM %s WHERE id = ? AND status = ? AND attempt = ?`, job.ID, StatusRunning, job.Attempt)
+	return leaseResult(n, err, "complete")
+}
+
+// Retry implements Store
+func (s *SQLStore) Retry(ctx context.Context, job *Job, runAt time.Time) error {
+	n, err := s.exec(ctx, `UPDATE %s SET status = ?, run_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ? AND status = ? AND attempt = ?`,
+		StatusPending, runAt.UnixMilli(), job.LastError, job.UpdatedAt.UnixMilli(), job.ID, StatusRunning, job.Attempt)
+	return leaseResult(n, err, "retry")
+}
+
+// Kill implements Store
+func (s *SQLStore) Kill(ctx context.Context, job *Job) error {
+	n, err := s.exec(ctx, `UPDATE %s SET status = ?, run_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ? AND status = ? AND attempt = ?`,
+		StatusDead, job.UpdatedAt.UnixMilli(), job.LastError, job.UpdatedAt.UnixMilli(), job.ID, StatusRunning, job.Attempt)
+	return leaseResult(n, err, "kill")
+}
+
+// leaseResult converts the outcome of a lease-guarded statement
+func leaseResult(n int64, err error, op string) error {
+	if err != nil {
+		return fmt.Errorf("jobs: failed to %s job: %w", op, err)
+	}
+	if n == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Get implements Store
+func (s *SQLStore) Get(ctx context.Context, id string) (*Job, error) {
+	var r row
+	query := s.client.DB().Rebind(fmt.Sprintf("SELECT * FROM %s WHERE id = ?", s.table))
+	if err := s.client.Get(ctx, &r, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("jobs: failed to load job: %w", err)
+	}
+	return r.job(), nil
+}
+
+// List implements Store
+func (s *SQLStore) List(ctx context.Context, opts ListOptions) ([]*Job, error) {
+	var where []string
+	var args []any
+	if opts.Queue != "" {
+		where = append(where, "queue = ?")
+		args = append(args, opts.Queue)
+	}
+	if opts.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, opts.Status)
+	}
+	query := "SELECT * FROM " + s.table
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY run_at, id LIMIT ? OFFSET ?"
+	args = append(args, opts.limit(), max(0, opts.Offset))
+
+	var rows []row
+	if err := s.client.Select(ctx, &rows, s.client.DB().Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("jobs: failed to list jobs: %w", err)
+	}
+	list := make([]*Job, len(rows))
+	for i, r := range rows {
+		list[i] = r.job()
+	}
+	return list, nil
+}
+
+// Requeue implements Store
+func (s *SQLStore) Requeue(ctx context.Context, id string, now time.Time) error {
+	n, err := s.exec(ctx, `UPDATE %s SET status = ?, attempt = 0, run_at = ?, updated_at = ? WHERE id = ? AND status = ?`,
+		StatusPending, now.UnixMilli(), now.UnixMilli(), id, StatusDead)
+	if err != nil {
+		return fmt.Errorf("jobs: failed to requeue job: %w", err)
+	}
+	if n == 0 {
+		if _, err := s.Get(ctx, id); err != nil {
+			return err
+		}
+		return ErrNotDead
+	}
+	return nil
+}
+
+// Delete implements Store
+func (s *SQLStore) Delete(ctx context.Context, id string) error {
+	n, err := s.exec(ctx, `DELETE FROM %s WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("jobs: failed to delete job: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store persists jobs. Claiming a job leases it to one worker; Complete,
+// Retry and Kill only succeed while that lease holds, identified by the
+// job's Attempt, and return ErrLeaseLost otherwise.
+type Store interface {
+	// Enqueue inserts a pending job
+	Enqueue(ctx context.Context, job *Job) error
+	// Claim leases the earliest due job of queue until now+lease, marking
+	// it running and counting an attempt; it returns nil when none is due.
+	// Running jobs whose lease expired are due again.
+	Claim(ctx context.Context, queue string, now time.Time, lease time.Duration) (*Job, error)
+	// Complete removes a finished job
+	Complete(ctx context.Context, job *Job) error
+	// Retry makes a running job pending again at runAt, saving
+	// job.LastError and job.UpdatedAt
+	Retry(ctx context.Context, job *Job, runAt time.Time) error
+	// Kill moves a running job to the dead jobs, saving job.LastError; the
+	// job died at job.UpdatedAt
+	Kill(ctx context.Context, job *Job) error
+
+	// Get returns a job, or ErrNotFound
+	Get(ctx context.Context, id string) (*Job, error)
+	// List returns jobs ordered by RunAt
+	List(ctx context.Context, opts ListOptions) ([]*Job, error)
+	// Requeue makes a dead job pending at now with its attempts reset
+	Requeue(ctx context.Context, id string, now time.Time) error
+	// Delete removes a job in any state
+	Delete(ctx context.Context, id string) error
+}
+
+// ListOptions filters List
+type ListOptions struct {
+	// Queue and Status filter the jobs when set
+	Queue  string
+	Status Status
+	Limit  int
+	Offset int
+}
+
+// limit returns the page size, 100 by default
+func (o ListOptions) limit() int {
+	if o.Limit <= 0 {
+		return 100
+	}
+	return o.Limit
+}
+
+// MemoryStore is an in-process Store for tests and single-instance tools
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]*Job)}
+}
+
+// copyJob returns a copy that shares no data with j
+func copyJob(j *Job) *Job {
+	c := *j
+	c.Payload = append([]byte(nil), j.Payload...)
+	return &c
+}
+
+// Enqueue implements Store
+func (s *MemoryStore) Enqueue(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.ID]; ok {
+		return fmt.Errorf("jobs: job %s already exists", job.ID)
+	}
+	s.jobs[job.ID] = copyJob(job)
+	return nil
+}
+
+// Claim implements Store
+func (s *MemoryStore) Claim(ctx context.Context, queue string, now time.Time, lease time.Duration) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next *Job
+	for _, j := range s.jobs {
+		if j.Queue != queue || j.Status == StatusDead || j.RunAt.After(now) {
+			continue
+		}
+		if next == nil || j.RunAt.Before(next.RunAt) || (j.RunAt.Equal(next.RunAt) && j.ID < next.ID) {
+			next = j
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	next.Status = StatusRunning
+	next.Attempt++
+	next.RunAt = now.Add(lease)
+	next.UpdatedAt = now
+	return copyJob(next), nil
+}
+
+// leased returns the stored job if job still holds its lease
+func (s *MemoryStore) leased(job *Job) (*Job, error) {
+	cur, ok := s.jobs[job.ID]
+	if !ok || cur.Status != StatusRunning || cur.Attempt != job.Attempt {
+		return nil, ErrLeaseLost
+	}
+	return cur, nil
+}
+
+// Complete implements Store
+func (s *MemoryStore) Complete(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.leased(job); err != nil {
+		return err
+	}
+	delete(s.jobs, job.ID)
+	return nil
+}
+
+// Retry implements Store
+func (s *MemoryStore) Retry(ctx context.Context, job *Job, runAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur, err := s.leased(job)
+	if err != nil {
+		return err
+	}
+	cur.Status = StatusPending
+	cur.RunAt = runAt
+	cur.LastError = job.LastError
+	cur.UpdatedAt = job.UpdatedAt
+	return nil
+}
+
+// Kill implements Store
+func (s *MemoryStore) Kill(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur, err := s.leased(job)
+	if err != nil {
+		return err
+	}
+	cur.Status = StatusDead
+	cur.RunAt = job.UpdatedAt
+	cur.LastError = job.LastError
+	cur.UpdatedAt = job.UpdatedAt
+	return nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyJob(j), nil
+}
+
+// List implements Store
+func (s *MemoryStore) List(ctx context.Context, opts ListOptions) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []*Job
+	for _, j := range s.jobs {
+		if (opts.Queue == "" || j.Queue == opts.Queue) && (opts.Status == "" || j.Status == opts.Status) {
+			list = append(list, copyJob(j))
+		}
+	}
+	sort.Slice(list, func(i, k int) bool {
+		if !list[i].RunAt.Equal(list[k].RunAt) {
+			return list[i].RunAt.Before(list[k].RunAt)
+		}
+		return list[i].ID < list[k].ID
+	})
+	if opts.Offset >= len(list) {
+		return nil, nil
+	}
+	list = list[opts.Offset:]
+	return list[:min(len(list), opts.limit())], nil
+}
+
+// Requeue implements Store
+func (s *MemoryStore) Requeue(ctx context.Context, id string, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if j.Status != StatusDead {
+		return ErrNotDead
+	}
+	j.Status = StatusPending
+	j.Attempt = 0
+	j.RunAt = now
+	j.UpdatedAt = now
+	return nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.jobs, id)
+	return nil
+}
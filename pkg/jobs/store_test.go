@@ -0,0 +1,145 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mora/pkg/db"
+)
+
+// testStore runs the behaviour every Store must have
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+	base := time.UnixMilli(1700000000000)
+	enqueue := func(id, queue string, runAt time.Time) {
+		t.Helper()
+		err := s.Enqueue(ctx, &Job{
+			ID: id, Queue: queue, Type: "t", Payload: []byte(`{"n":1}`), Status: StatusPending,
+			MaxAttempts: 3, RunAt: runAt, CreatedAt: base, UpdatedAt: base,
+		})
+		if err != nil {
+			t.Fatalf("Enqueue(%s) error = %v", id, err)
+		}
+	}
+	enqueue("a", "default", base.Add(2*time.Second))
+	enqueue("b", "default", base.Add(time.Second))
+	enqueue("c", "default", base.Add(time.Hour))
+	enqueue("m", "mail", base)
+
+	t.Run("claim in run order", func(t *testing.T) {
+		now := base.Add(time.Minute)
+		first, err := s.Claim(ctx, "default", now, time.Minute)
+		if err != nil || first == nil || first.ID != "b" {
+			t.Fatalf("Claim() = %+v, %v, want b", first, err)
+		}
+		if first.Status != StatusRunning || first.Attempt != 1 || !first.RunAt.Equal(now.Add(time.Minute)) || string(first.Payload) != `{"n":1}` {
+			t.Errorf("claimed job = %+v", first)
+		}
+		second, _ := s.Claim(ctx, "default", now, time.Minute)
+		if second == nil || second.ID != "a" {
+			t.Fatalf("second Claim() = %+v, want a", second)
+		}
+		if none, err := s.Claim(ctx, "default", now, time.Minute); none != nil || err != nil {
+			t.Fatalf("third Claim() = %+v, %v, want nothing due", none, err)
+		}
+
+		// b completes; a's lease expires and it is claimed again
+		if err := s.Complete(ctx, first); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+		if _, err := s.Get(ctx, "b"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("completed job still stored: %v", err)
+		}
+		again, _ := s.Claim(ctx, "default", now.Add(2*time.Minute), time.Minute)
+		if again == nil || again.ID != "a" || again.Attempt != 2 {
+			t.Fatalf("Claim() after lease expiry = %+v, want a on attempt 2", again)
+		}
+		if err := s.Retry(ctx, second, now); !errors.Is(err, ErrLeaseLost) {
+			t.Errorf("Retry() with a lost lease error = %v, want ErrLeaseLost", err)
+		}
+
+		again.LastError = "boom"
+		again.UpdatedAt = now.Add(2 * time.Minute)
+		if err := s.Retry(ctx, again, now.Add(10*time.Minute)); err != nil {
+			t.Fatalf("Retry() error = %v", err)
+		}
+		got, _ := s.Get(ctx, "a")
+		if got.Status != StatusPending || got.LastError != "boom" || !got.RunAt.Equal(now.Add(10*time.Minute)) || got.Attempt != 2 {
+			t.Errorf("after Retry = %+v", got)
+		}
+	})
+
+	t.Run("kill and requeue", func(t *testing.T) {
+		job, _ := s.Claim(ctx, "mail", base, time.Minute)
+		if job == nil || job.ID != "m" {
+			t.Fatalf("Claim(mail) = %+v", job)
+		}
+		job.LastError = "smtp down"
+		job.UpdatedAt = base.Add(time.Second)
+		if err := s.Kill(ctx, job); err != nil {
+			t.Fatalf("Kill() error = %v", err)
+		}
+		if next, _ := s.Claim(ctx, "mail", base.Add(time.Hour), time.Minute); next != nil {
+			t.Fatalf("dead job claimed: %+v", next)
+		}
+		dead, err := s.List(ctx, ListOptions{Status: StatusDead})
+		if err != nil || len(dead) != 1 || dead[0].ID != "m" || dead[0].LastError != "smtp down" || !dead[0].RunAt.Equal(job.UpdatedAt) {
+			t.Fatalf("List(dead) = %+v, %v", dead, err)
+		}
+
+		if err := s.Requeue(ctx, "a", base); !errors.Is(err, ErrNotDead) {
+			t.Errorf("Requeue(pending) error = %v, want ErrNotDead", err)
+		}
+		if err := s.Requeue(ctx, "missing", base); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Requeue(missing) error = %v, want ErrNotFound", err)
+		}
+		if err := s.Requeue(ctx, "m", base.Add(time.Hour)); err != nil {
+			t.Fatalf("Requeue() error = %v", err)
+		}
+		again, _ := s.Claim(ctx, "mail", base.Add(time.Hour), time.Minute)
+		if again == nil || again.ID != "m" || again.Attempt != 1 {
+			t.Errorf("Claim() after Requeue = %+v, want m on attempt 1", again)
+		}
+	})
+
+	t.Run("list and delete", func(t *testing.T) {
+		all, err := s.List(ctx, ListOptions{Queue: "default"})
+		if err != nil || len(all) != 2 || all[0].ID != "a" || all[1].ID != "c" {
+			t.Fatalf("List(default) = %+v, %v, want a then c", all, err)
+		}
+		page, _ := s.List(ctx, ListOptions{Queue: "default", Limit: 1, Offset: 1})
+		if len(page) != 1 || page[0].ID != "c" {
+			t.Errorf("List() page = %+v, want c", page)
+		}
+		if err := s.Delete(ctx, "c"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if err := s.Delete(ctx, "c"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestSQLStore(t *testing.T) {
+	cfg := db.DefaultConfig()
+	cfg.Driver = "sqlite3"
+	cfg.DSN = filepath.Join(t.TempDir(), "jobs.db")
+	client, err := db.NewSQLX(cfg)
+	if err != nil {
+		t.Fatalf("NewSQLX() error = %v", err)
+	}
+	defer client.Close()
+
+	s := NewSQLStore(client, "")
+	if err := s.CreateTable(context.Background()); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	testStore(t, s)
+}
@@ -0,0 +1,244 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Handler runs a job; return a Permanent error to skip the remaining attempts
+type Handler func(ctx context.Context, job *Job) error
+
+// Config configures a Worker
+type Config struct {
+	// Queues maps the queues to work on to the number of jobs run
+	// concurrently from each, e.g. {"default": 10, "mail": 2}
+	Queues map[string]int `json:"queues" yaml:"queues"`
+	// PollInterval is how long an idle queue waits before looking for jobs again
+	PollInterval time.Duration `json:"poll_interval" yaml:"poll_interval" env:"POLL_INTERVAL"`
+	// Timeout bounds one run of a job; the lease lasts a little longer so
+	// no other worker picks the job up while it runs
+	Timeout time.Duration `json:"timeout" yaml:"timeout" env:"TIMEOUT"`
+	// Backoff is the delay before the first retry, doubled for each further one
+	Backoff    time.Duration `json:"backoff" yaml:"backoff" env:"BACKOFF"`
+	MaxBackoff time.Duration `json:"max_backoff" yaml:"max_backoff" env:"MAX_BACKOFF"`
+
+	// OnDead is called when a job is moved to the dead jobs
+	OnDead func(job *Job, err error) `json:"-" yaml:"-"`
+	// OnError is called with store errors
+	OnError func(err error) `json:"-" yaml:"-"`
+}
+
+// DefaultConfig returns default worker configuration
+func DefaultConfig() Config {
+	return Config{
+		Queues:       map[string]int{DefaultQueue: 10},
+		PollInterval: time.Second,
+		Timeout:      5 * time.Minute,
+		Backoff:      time.Second,
+		MaxBackoff:   time.Hour,
+	}
+}
+
+// leaseMargin is the time a lease outlasts the job timeout, covering the
+// store round trip that finishes the job
+const leaseMargin = 30 * time.Second
+
+// Worker runs jobs from a store
+type Worker struct {
+	store Store
+	cfg   Config
+	now   func() time.Time
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	lifecycle sync.Mutex
+	cancel    context.CancelFunc
+	cancelRun context.CancelFunc
+	loops     sync.WaitGroup
+	runs      sync.WaitGroup
+}
+
+// NewWorker creates a worker
+func NewWorker(store Store, cfg Config) *Worker {
+	defaults := DefaultConfig()
+	if len(cfg.Queues) == 0 {
+		cfg.Queues = defaults.Queues
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaults.PollInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaults.Backoff
+	}
+	if cfg.MaxBackoff < cfg.Backoff {
+		cfg.MaxBackoff = max(defaults.MaxBackoff, cfg.Backoff)
+	}
+	return &Worker{store: store, cfg: cfg, now: time.Now, handlers: make(map[string]Handler)}
+}
+
+// Register sets the handler of a job type
+func (w *Worker) Register(jobType string, h Handler) error {
+	if jobType == "" || h == nil {
+		return errors.New("jobs: job type and handler are required")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.handlers[jobType]; ok {
+		return fmt.Errorf("jobs: handler for %s already registered", jobType)
+	}
+	w.handlers[jobType] = h
+	return nil
+}
+
+// Start begins working on the queues until Stop is called or ctx is
+// cancelled; use it with app.Func("jobs", w.Start, w.Stop)
+func (w *Worker) Start(ctx context.Context) error {
+	w.lifecycle.Lock()
+	defer w.lifecycle.Unlock()
+	if w.cancel != nil {
+		return nil
+	}
+	// Running jobs keep their context when fetching stops, until Stop gives up on them
+	runCtx, cancelRun := context.WithCancel(context.WithoutCancel(ctx))
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.cancelRun = cancelRun
+	for queue, concurrency := range w.cfg.Queues {
+		w.loops.Add(1)
+		go w.fetch(ctx, runCtx, queue, max(1, concurrency))
+	}
+	return nil
+}
+
+// Stop stops claiming jobs and waits for running ones to finish; when ctx
+// expires first, their contexts are cancelled and they are retried later
+func (w *Worker) Stop(ctx context.Context) error {
+	w.lifecycle.Lock()
+	cancel, cancelRun := w.cancel, w.cancelRun
+	w.lifecycle.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	w.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		w.runs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		cancelRun()
+		return nil
+	case <-ctx.Done():
+		cancelRun()
+		<-done
+		return fmt.Errorf("jobs: waiting for running jobs: %w", ctx.Err())
+	}
+}
+
+// fetch claims jobs of one queue while a slot is free
+func (w *Worker) fetch(ctx, runCtx context.Context, queue string, concurrency int) {
+	defer w.loops.Done()
+	slots := make(chan struct{}, concurrency)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case slots <- struct{}{}:
+		}
+
+		job, err := w.store.Claim(ctx, queue, w.now(), w.cfg.Timeout+leaseMargin)
+		if err != nil || job == nil {
+			<-slots
+			if err != nil && ctx.Err() == nil {
+				w.report(fmt.Errorf("jobs: failed to claim from %s: %w", queue, err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.cfg.PollInterval):
+			}
+			continue
+		}
+
+		w.runs.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				w.runs.Done()
+			}()
+			w.process(runCtx, job)
+		}()
+	}
+}
+
+// process runs a claimed job and records its outcome
+func (w *Worker) process(ctx context.Context, job *Job) {
+	w.mu.RLock()
+	h := w.handlers[job.Type]
+	w.mu.RUnlock()
+
+	var err error
+	if h == nil {
+		err = Permanent(fmt.Errorf("no handler registered for %s", job.Type))
+	} else {
+		err = w.run(ctx, h, job)
+	}
+
+	// Finish the job even when shutdown cancelled its context
+	ctx = context.WithoutCancel(ctx)
+	job.UpdatedAt = w.now()
+	switch {
+	case err == nil:
+		err = w.store.Complete(ctx, job)
+	case !IsPermanent(err) && job.Attempt < job.MaxAttempts:
+		job.LastError = err.Error()
+		err = w.store.Retry(ctx, job, job.UpdatedAt.Add(w.backoff(job.Attempt)))
+	default:
+		job.LastError = err.Error()
+		runErr := err
+		if err = w.store.Kill(ctx, job); err == nil && w.cfg.OnDead != nil {
+			w.cfg.OnDead(job, runErr)
+		}
+	}
+	if err != nil {
+		w.report(fmt.Errorf("jobs: failed to finish %s %s: %w", job.Type, job.ID, err))
+	}
+}
+
+// run calls h with the job timeout, turning panics into errors
+func (w *Worker) run(ctx context.Context, h Handler, job *Job) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("jobs: handler panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return h(ctx, job)
+}
+
+// backoff returns the delay before the retry following attempt
+func (w *Worker) backoff(attempt int) time.Duration {
+	d := w.cfg.Backoff
+	for i := 1; i < attempt && d < w.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, w.cfg.MaxBackoff)
+}
+
+// report passes err to the OnError hook
+func (w *Worker) report(err error) {
+	if w.cfg.OnError != nil {
+		w.cfg.OnError(err)
+	}
+}
@@ -0,0 +1,216 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type welcome struct {
+	UserID string `json:"user_id"`
+}
+
+var sendWelcome = NewTask[welcome]("send-welcome", Queue("mail"))
+
+// startWorker starts a worker polling every few milliseconds
+func startWorker(t *testing.T, store Store, cfg Config, register func(w *Worker)) *Worker {
+	t.Helper()
+	cfg.PollInterval = 5 * time.Millisecond
+	cfg.Backoff = time.Millisecond
+	w := NewWorker(store, cfg)
+	register(w)
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { w.Stop(context.Background()) })
+	return w
+}
+
+// waitFor polls cond until it holds or a second passes
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTaskRuns(t *testing.T) {
+	store := NewMemoryStore()
+	client := NewClient(store)
+	got := make(chan string, 1)
+	startWorker(t, store, Config{Queues: map[string]int{"mail": 1}}, func(w *Worker) {
+		w.Register(sendWelcome.Name, sendWelcome.Handler(func(ctx context.Context, p welcome) error {
+			got <- p.UserID
+			return nil
+		}))
+	})
+
+	job, err := sendWelcome.Enqueue(context.Background(), client, welcome{UserID: "u-1"})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if job.Queue != "mail" || job.Type != "send-welcome" || job.MaxAttempts != 5 {
+		t.Errorf("job = %+v", job)
+	}
+	select {
+	case id := <-got:
+		if id != "u-1" {
+			t.Errorf("payload user = %q", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("job did not run")
+	}
+	waitFor(t, "completion", func() bool {
+		_, err := store.Get(context.Background(), job.ID)
+		return errors.Is(err, ErrNotFound)
+	})
+}
+
+func TestEnqueueSchedule(t *testing.T) {
+	store := NewMemoryStore()
+	client := NewClient(store)
+	now := time.Unix(1700000000, 0)
+	client.now = func() time.Time { return now }
+
+	delayed, _ := client.Enqueue(context.Background(), "t", nil, Delay(time.Minute), MaxAttempts(2))
+	at, _ := client.Enqueue(context.Background(), "t", nil, At(now.Add(time.Hour)))
+	if !delayed.RunAt.Equal(now.Add(time.Minute)) || delayed.MaxAttempts != 2 || delayed.Queue != DefaultQueue {
+		t.Errorf("delayed job = %+v", delayed)
+	}
+	if !at.RunAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("scheduled job = %+v", at)
+	}
+	if job, _ := store.Claim(context.Background(), DefaultQueue, now, time.Minute); job != nil {
+		t.Errorf("claimed %s before it was due", job.ID)
+	}
+}
+
+func TestRetriesThenDeadLetter(t *testing.T) {
+	store := NewMemoryStore()
+	var runs atomic.Int32
+	dead := make(chan *Job, 1)
+	startWorker(t, store, Config{OnDead: func(job *Job, err error) { dead <- job }}, func(w *Worker) {
+		w.Register("flaky", func(ctx context.Context, job *Job) error {
+			runs.Add(1)
+			return errors.New("upstream unavailable")
+		})
+	})
+
+	job, _ := NewClient(store).Enqueue(context.Background(), "flaky", nil, MaxAttempts(3))
+	select {
+	case d := <-dead:
+		if d.ID != job.ID || d.Attempt != 3 || d.LastError != "upstream unavailable" {
+			t.Errorf("dead job = %+v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("job was not dead-lettered")
+	}
+	if n := runs.Load(); n != 3 {
+		t.Errorf("runs = %d, want 3", n)
+	}
+	got, _ := store.Get(context.Background(), job.ID)
+	if got.Status != StatusDead {
+		t.Errorf("Status = %s, want dead", got.Status)
+	}
+}
+
+func TestPermanentErrorsAndPanics(t *testing.T) {
+	store := NewMemoryStore()
+	var mu sync.Mutex
+	errs := map[string]string{}
+	startWorker(t, store, Config{OnDead: func(job *Job, err error) {
+		mu.Lock()
+		errs[job.Type] = job.LastError
+		mu.Unlock()
+	}}, func(w *Worker) {
+		w.Register("reject", func(ctx context.Context, job *Job) error { return Permanent(errors.New("invalid address")) })
+		w.Register("panic", func(ctx context.Context, job *Job) error { panic("boom") })
+		w.Register("typed", sendWelcome.Handler(func(ctx context.Context, p welcome) error { return nil }))
+	})
+
+	client := NewClient(store)
+	client.Enqueue(context.Background(), "reject", nil)
+	client.Enqueue(context.Background(), "panic", nil, MaxAttempts(1))
+	client.Enqueue(context.Background(), "typed", "not an object")
+	client.Enqueue(context.Background(), "unknown", nil)
+
+	waitFor(t, "dead jobs", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) == 4
+	})
+	if errs["reject"] != "invalid address" {
+		t.Errorf("reject error = %q", errs["reject"])
+	}
+	for _, jobType := range []string{"panic", "typed", "unknown"} {
+		if errs[jobType] == "" {
+			t.Errorf("%s has no error", jobType)
+		}
+	}
+}
+
+func TestConcurrencyPerQueue(t *testing.T) {
+	store := NewMemoryStore()
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	startWorker(t, store, Config{Queues: map[string]int{DefaultQueue: 2}}, func(w *Worker) {
+		w.Register("slow", func(ctx context.Context, job *Job) error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			<-release
+			running.Add(-1)
+			return nil
+		})
+	})
+
+	client := NewClient(store)
+	for i := 0; i < 5; i++ {
+		client.Enqueue(context.Background(), "slow", nil)
+	}
+	waitFor(t, "two running jobs", func() bool { return running.Load() == 2 })
+	time.Sleep(20 * time.Millisecond)
+	if p := peak.Load(); p != 2 {
+		t.Errorf("peak concurrency = %d, want 2", p)
+	}
+	close(release)
+	waitFor(t, "all jobs", func() bool {
+		list, _ := store.List(context.Background(), ListOptions{})
+		return len(list) == 0
+	})
+}
+
+func TestStopRetriesInterruptedJobs(t *testing.T) {
+	store := NewMemoryStore()
+	started := make(chan struct{})
+	w := NewWorker(store, Config{PollInterval: 5 * time.Millisecond})
+	w.Register("long", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	w.Start(context.Background())
+	job, _ := NewClient(store).Enqueue(context.Background(), "long", nil)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := w.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop() error = %v, want deadline exceeded", err)
+	}
+	got, _ := store.Get(context.Background(), job.ID)
+	if got.Status != StatusPending || got.LastError == "" {
+		t.Errorf("interrupted job = %+v, want pending retry", got)
+	}
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultAsyncBufferSize is the default number of entries buffered in async mode
+const DefaultAsyncBufferSize = 4096
+
+// AsyncConfig configures asynchronous buffered output
+type AsyncConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// BufferSize bounds the number of encoded entries waiting to be written;
+	// entries are dropped (and counted) once it is full
+	BufferSize int `json:"buffer_size" yaml:"buffer_size"`
+}
+
+// asyncWriter hands encoded entries to a background goroutine through a
+// bounded channel so that slow output never blocks the caller
+type asyncWriter struct {
+	out     zapcore.WriteSyncer
+	entries chan []byte
+	flushes chan chan struct{}
+	done    chan struct{}
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	dropped   atomic.Int64
+}
+
+// newAsyncWriter starts an async writer on top of out
+func newAsyncWriter(out zapcore.WriteSyncer, cfg AsyncConfig) *asyncWriter {
+	size := cfg.BufferSize
+	if size <= 0 {
+		size = DefaultAsyncBufferSize
+	}
+
+	w := &asyncWriter{
+		out:     out,
+		entries: make(chan []byte, size),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+// run writes entries until the writer is closed
+func (w *asyncWriter) run() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case entry := <-w.entries:
+			_, _ = w.out.Write(entry)
+		case ack := <-w.flushes:
+			w.drain()
+			close(ack)
+		case <-w.done:
+			w.drain()
+			return
+		}
+	}
+}
+
+// drain writes every entry currently buffered
+func (w *asyncWriter) drain() {
+	for {
+		select {
+		case entry := <-w.entries:
+			_, _ = w.out.Write(entry)
+		default:
+			return
+		}
+	}
+}
+
+// Write implements zapcore.WriteSyncer. The encoder reuses its buffer, so the
+// entry is copied before being queued.
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	entry := make([]byte, len(p))
+	copy(entry, p)
+
+	select {
+	case w.entries <- entry:
+	default:
+		w.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Sync implements zapcore.WriteSyncer by waiting until the buffer is drained
+func (w *asyncWriter) Sync() error {
+	ack := make(chan struct{})
+	select {
+	case w.flushes <- ack:
+		<-ack
+	case <-w.done:
+	}
+	return w.out.Sync()
+}
+
+// Close drains the buffer and stops the background goroutine
+func (w *asyncWriter) Close() {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.wg.Wait()
+	})
+}
+
+// Dropped returns the number of entries dropped because the buffer was full
+func (w *asyncWriter) Dropped() int64 {
+	return w.dropped.Load()
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+// blockingBuffer is a WriteSyncer whose writes wait until released
+type blockingBuffer struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+}
+
+func (b *blockingBuffer) Write(p []byte) (int, error) {
+	if b.release != nil {
+		<-b.release
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *blockingBuffer) Sync() error { return nil }
+
+func (b *blockingBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAsyncWriterSync(t *testing.T) {
+	out := &blockingBuffer{}
+	w := newAsyncWriter(out, AsyncConfig{Enabled: true, BufferSize: 16})
+	defer w.Close()
+
+	for _, line := range []string{"a\n", "b\n", "c\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if got := out.String(); got != "a\nb\nc\n" {
+		t.Errorf("output = %q, want %q", got, "a\nb\nc\n")
+	}
+}
+
+func TestAsyncWriterDropsWhenFull(t *testing.T) {
+	out := &blockingBuffer{release: make(chan struct{})}
+	w := newAsyncWriter(out, AsyncConfig{Enabled: true, BufferSize: 2})
+
+	// One entry is held by the blocked writer goroutine, two fill the
+	// buffer, and the rest must be dropped.
+	for i := 0; i < 10; i++ {
+		w.Write([]byte("x"))
+	}
+
+	if w.Dropped() == 0 {
+		t.Error("Dropped() = 0, want entries to be dropped")
+	}
+
+	close(out.release)
+	w.Close()
+
+	if written := int64(len(out.String())); written+w.Dropped() != 10 {
+		t.Errorf("written %d + dropped %d != 10", written, w.Dropped())
+	}
+}
+
+func TestAsyncWriterCopiesInput(t *testing.T) {
+	out := &blockingBuffer{}
+	w := newAsyncWriter(out, AsyncConfig{Enabled: true})
+	defer w.Close()
+
+	buf := []byte("first")
+	w.Write(buf)
+	copy(buf, "xxxxx")
+	w.Sync()
+
+	if got := out.String(); got != "first" {
+		t.Errorf("output = %q, want first", got)
+	}
+}
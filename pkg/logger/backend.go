@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"io"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/zap/zapcore"
+)
+
+// Backend writes finished entries to the final output. The Logger facade,
+// level handling, hooks, sinks and metrics stay the same whichever backend
+// is used; only the encoding and writing of each line is delegated.
+type Backend interface {
+	Write(entry Entry) error
+	Sync() error
+}
+
+// backendCore adapts a Backend to zapcore.Core
+type backendCore struct {
+	backend Backend
+	fields  []zapcore.Field
+}
+
+// Enabled implements zapcore.Core; level filtering happens in levelCore
+func (c *backendCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With implements zapcore.Core
+func (c *backendCore) With(fields []zapcore.Field) zapcore.Core {
+	combined := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	combined = append(combined, c.fields...)
+	combined = append(combined, fields...)
+	return &backendCore{backend: c.backend, fields: combined}
+}
+
+// Check implements zapcore.Core
+func (c *backendCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+
+// Write implements zapcore.Core
+func (c *backendCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.backend.Write(newEntry(entry, c.fields, fields))
+}
+
+// Sync implements zapcore.Core
+func (c *backendCore) Sync() error {
+	return c.backend.Sync()
+}
+
+// zerologBackend writes entries through a zerolog.Logger
+type zerologBackend struct {
+	logger zerolog.Logger
+	out    io.Writer
+}
+
+// NewZerologBackend creates a Backend on top of an existing zerolog.Logger,
+// so services keep their zerolog writers, hooks and field names
+func NewZerologBackend(logger zerolog.Logger) Backend {
+	return &zerologBackend{logger: logger}
+}
+
+// newZerologOutput creates the zerolog backend used by Config.Backend
+func newZerologOutput(out zapcore.WriteSyncer, format string) Backend {
+	var w io.Writer = out
+	if format == "console" || format == "dev" {
+		w = zerolog.ConsoleWriter{Out: out, TimeFormat: DefaultDevTimeLayout}
+	}
+	return &zerologBackend{logger: zerolog.New(w), out: out}
+}
+
+// Write implements Backend. WithLevel never exits or panics, fatal and panic
+// behaviour stays with the Logger facade.
+func (b *zerologBackend) Write(entry Entry) error {
+	event := b.logger.WithLevel(zerologLevel(entry.Level))
+	if event == nil {
+		return nil
+	}
+
+	event = event.Time(zerolog.TimestampFieldName, entry.Time)
+	if entry.Logger != "" {
+		event = event.Str("logger", entry.Logger)
+	}
+	if entry.Caller != "" {
+		event = event.Str(zerolog.CallerFieldName, entry.Caller)
+	}
+	if entry.Stack != "" {
+		event = event.Str(zerolog.ErrorStackFieldName, entry.Stack)
+	}
+	event.Fields(entry.Fields).Msg(entry.Message)
+	return nil
+}
+
+// Sync implements Backend
+func (b *zerologBackend) Sync() error {
+	if s, ok := b.out.(zapcore.WriteSyncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// zerologLevel maps a level name to its zerolog equivalent
+func zerologLevel(level string) zerolog.Level {
+	switch level {
+	case "debug":
+		return zerolog.DebugLevel
+	case "info":
+		return zerolog.InfoLevel
+	case "warn":
+		return zerolog.WarnLevel
+	case "error":
+		return zerolog.ErrorLevel
+	case "dpanic", "panic":
+		return zerolog.PanicLevel
+	case "fatal":
+		return zerolog.FatalLevel
+	default:
+		return zerolog.NoLevel
+	}
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestZerologBackend(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithBackend(Config{Level: "info", Format: "json"}, NewZerologBackend(zerolog.New(&buf)))
+	if err != nil {
+		t.Fatalf("NewWithBackend() error = %v", err)
+	}
+
+	log.Debug("filtered")
+	log.Named("cache").WithTraceID("trace-1").Infow("hit", "key", "user:1", "size", 3)
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &line); err != nil {
+		t.Fatalf("output is not a single JSON line: %q", buf.String())
+	}
+
+	want := map[string]interface{}{
+		"level":    "info",
+		"message":  "hit",
+		"logger":   "cache",
+		"trace_id": "trace-1",
+		"key":      "user:1",
+		"size":     float64(3),
+	}
+	for k, v := range want {
+		if line[k] != v {
+			t.Errorf("%s = %v, want %v", k, line[k], v)
+		}
+	}
+	if _, ok := line[zerolog.CallerFieldName]; !ok {
+		t.Errorf("caller missing: %v", line)
+	}
+}
+
+func TestBackendSelection(t *testing.T) {
+	if _, err := NewWithBackend(Config{Level: "info"}, nil); err == nil {
+		t.Error("NewWithBackend() should reject a nil backend")
+	}
+	if _, err := New(Config{Level: "info", Backend: "logrus"}); err == nil {
+		t.Error("New() should reject an unknown backend")
+	}
+
+	log, err := New(Config{Level: "info", Backend: "zerolog"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	log.Close()
+}
@@ -7,21 +7,81 @@ import (
 const (
 	// TraceIDKey is the key used to store trace ID in context
 	TraceIDKey = "trace_id"
+	// UserIDKey is the key used to store the authenticated user ID in context
+	UserIDKey = "user_id"
+	// RequestIDKey is the key used to store the request ID in context
+	RequestIDKey = "request_id"
 )
 
 // GetTraceIDFromContext extracts trace ID from context
 func GetTraceIDFromContext(ctx context.Context) string {
+	return stringFromContext(ctx, TraceIDKey)
+}
+
+// WithTraceID adds trace ID to context
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, TraceIDKey, traceID)
+}
+
+// GetUserIDFromContext extracts the user ID from context
+func GetUserIDFromContext(ctx context.Context) string {
+	return stringFromContext(ctx, UserIDKey)
+}
+
+// WithUserID adds the user ID to context
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, UserIDKey, userID)
+}
+
+// GetRequestIDFromContext extracts the request ID from context
+func GetRequestIDFromContext(ctx context.Context) string {
+	return stringFromContext(ctx, RequestIDKey)
+}
+
+// WithRequestID adds the request ID to context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, requestID)
+}
+
+// stringFromContext returns the string stored under key, if any
+func stringFromContext(ctx context.Context, key string) string {
 	if ctx == nil {
 		return ""
 	}
-
-	if traceID, ok := ctx.Value(TraceIDKey).(string); ok {
-		return traceID
+	if v, ok := ctx.Value(key).(string); ok {
+		return v
 	}
 	return ""
 }
 
-// WithTraceID adds trace ID to context
-func WithTraceID(ctx context.Context, traceID string) context.Context {
-	return context.WithValue(ctx, TraceIDKey, traceID)
+// fieldsKey is the context key for accumulated log fields
+type fieldsKey struct{}
+
+// ContextWithFields returns a context carrying the given log fields merged
+// with any fields already stored in ctx. Later values win on key conflicts.
+func ContextWithFields(ctx context.Context, fields map[string]interface{}) context.Context {
+	existing := GetFieldsFromContext(ctx)
+
+	merged := make(map[string]interface{}, len(existing)+len(fields))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// GetFieldsFromContext extracts the accumulated log fields from context.
+// The returned map must not be modified.
+func GetFieldsFromContext(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+
+	if fields, ok := ctx.Value(fieldsKey{}).(map[string]interface{}); ok {
+		return fields
+	}
+	return nil
 }
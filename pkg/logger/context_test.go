@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextWithFields(t *testing.T) {
+	ctx := ContextWithFields(context.Background(), map[string]interface{}{"user_id": "u1", "tenant": "t1"})
+	ctx = ContextWithFields(ctx, map[string]interface{}{"path": "/orders", "tenant": "t2"})
+
+	fields := GetFieldsFromContext(ctx)
+	want := map[string]interface{}{"user_id": "u1", "tenant": "t2", "path": "/orders"}
+	if len(fields) != len(want) {
+		t.Fatalf("fields = %v, want %v", fields, want)
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("fields[%s] = %v, want %v", k, fields[k], v)
+		}
+	}
+
+	if GetFieldsFromContext(context.Background()) != nil {
+		t.Error("GetFieldsFromContext() should return nil for empty context")
+	}
+}
+
+func TestWithContextFields(t *testing.T) {
+	root, logs := newObservedLogger(t, "info", nil)
+
+	ctx := WithTraceID(context.Background(), "trace-1")
+	ctx = ContextWithFields(ctx, map[string]interface{}{"user_id": "u1"})
+
+	root.WithContext(ctx).Info("handled")
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["trace_id"] != "trace-1" || fields["user_id"] != "u1" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+}
+
+func TestWithContextIDs(t *testing.T) {
+	root, logs := newObservedLogger(t, "info", nil)
+
+	ctx := WithTraceID(context.Background(), "trace-1")
+	ctx = WithUserID(ctx, "u1")
+	ctx = WithRequestID(ctx, "req-1")
+	ctx = ContextWithFields(ctx, map[string]interface{}{"user_id": "stale", "tenant": "t1"})
+
+	root.WithContext(ctx).Info("handled")
+	root.WithContext(context.Background()).Info("bare")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entries))
+	}
+	want := map[string]interface{}{"trace_id": "trace-1", "user_id": "u1", "request_id": "req-1", "tenant": "t1"}
+	fields := entries[0].ContextMap()
+	if len(fields) != len(want) {
+		t.Fatalf("fields = %v, want %v", fields, want)
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("fields[%s] = %v, want %v", k, fields[k], v)
+		}
+	}
+	if len(entries[1].ContextMap()) != 0 {
+		t.Errorf("bare context added fields: %v", entries[1].ContextMap())
+	}
+}
+
+func TestCtx(t *testing.T) {
+	root, logs := newObservedLogger(t, "info", nil)
+	previous := defaultLogger.Load()
+	SetDefault(root)
+	defer func() {
+		if previous != nil {
+			SetDefault(previous)
+		} else {
+			defaultLogger.Store(nil)
+		}
+	}()
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	InfoCtx(ctx, "info")
+	ErrorCtx(ctx, "error")
+	DebugCtx(ctx, "filtered")
+	Ctx(ctx).Warnw("warn", "attempt", 2)
+
+	entries := logs.All()
+	if len(entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.ContextMap()["request_id"] != "req-1" {
+			t.Errorf("%s: request_id missing: %v", entry.Message, entry.ContextMap())
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultDevTimeLayout is the default time layout of the dev encoder
+const DefaultDevTimeLayout = "15:04:05.000"
+
+// DevEncoderConfig configures the human-friendly "dev" format
+type DevEncoderConfig struct {
+	// Color enables ANSI colors for levels and field keys
+	Color bool `json:"color" yaml:"color"`
+	// FieldOrder lists fields printed first, in this order; remaining fields
+	// follow alphabetically, e.g. ["trace_id", "user_id"]
+	FieldOrder []string `json:"field_order" yaml:"field_order"`
+	// TraceIDLength shortens trace_id to its last N characters, 0 keeps it whole
+	TraceIDLength int `json:"trace_id_length" yaml:"trace_id_length"`
+	// TimeLayout is a time.Format layout, defaults to DefaultDevTimeLayout
+	TimeLayout string `json:"time_layout" yaml:"time_layout"`
+}
+
+const (
+	colorReset   = "\x1b[0m"
+	colorDim     = "\x1b[2m"
+	colorRed     = "\x1b[31m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+)
+
+var devBufferPool = buffer.NewPool()
+
+// devEncoder renders entries as `time LEVEL logger caller message key=value...`
+type devEncoder struct {
+	*zapcore.MapObjectEncoder
+	cfg DevEncoderConfig
+}
+
+// newDevEncoder creates the dev encoder
+func newDevEncoder(cfg DevEncoderConfig) *devEncoder {
+	if cfg.TimeLayout == "" {
+		cfg.TimeLayout = DefaultDevTimeLayout
+	}
+	return &devEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), cfg: cfg}
+}
+
+// Clone implements zapcore.Encoder
+func (e *devEncoder) Clone() zapcore.Encoder {
+	clone := newDevEncoder(e.cfg)
+	for k, v := range e.Fields {
+		clone.Fields[k] = v
+	}
+	return clone
+}
+
+// EncodeEntry implements zapcore.Encoder
+func (e *devEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	enc := e.Clone().(*devEncoder)
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	buf := devBufferPool.Get()
+
+	e.writeColored(buf, colorDim, entry.Time.Format(e.cfg.TimeLayout))
+	buf.AppendByte(' ')
+	e.writeColored(buf, levelColor(entry.Level), fmt.Sprintf("%-5s", entry.Level.CapitalString()))
+
+	if entry.LoggerName != "" {
+		buf.AppendByte(' ')
+		e.writeColored(buf, colorCyan, "["+entry.LoggerName+"]")
+	}
+	if entry.Caller.Defined {
+		buf.AppendByte(' ')
+		e.writeColored(buf, colorDim, entry.Caller.TrimmedPath())
+	}
+
+	buf.AppendByte(' ')
+	buf.AppendString(entry.Message)
+
+	for _, key := range e.orderedKeys(enc.Fields) {
+		buf.AppendByte(' ')
+		e.writeColored(buf, colorDim, key+"=")
+		buf.AppendString(e.formatValue(key, enc.Fields[key]))
+	}
+
+	if entry.Stack != "" {
+		buf.AppendByte('\n')
+		buf.AppendString(entry.Stack)
+	}
+	buf.AppendByte('\n')
+	return buf, nil
+}
+
+// orderedKeys returns FieldOrder keys first, then the rest alphabetically
+func (e *devEncoder) orderedKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(e.cfg.FieldOrder))
+	for _, key := range e.cfg.FieldOrder {
+		if _, ok := fields[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+
+	rest := make([]string, 0, len(fields)-len(keys))
+	for key := range fields {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// formatValue renders a field value, quoting strings with spaces
+func (e *devEncoder) formatValue(key string, value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		if key == TraceIDKey && e.cfg.TraceIDLength > 0 && len(v) > e.cfg.TraceIDLength {
+			v = "…" + v[len(v)-e.cfg.TraceIDLength:]
+		}
+		if strings.ContainsAny(v, " \t\n\"=") {
+			return fmt.Sprintf("%q", v)
+		}
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeColored appends s, wrapped in an ANSI color when enabled
+func (e *devEncoder) writeColored(buf *buffer.Buffer, color, s string) {
+	if !e.cfg.Color {
+		buf.AppendString(s)
+		return
+	}
+	buf.AppendString(color)
+	buf.AppendString(s)
+	buf.AppendString(colorReset)
+}
+
+// levelColor returns the color of a level
+func levelColor(level zapcore.Level) string {
+	switch level {
+	case zapcore.DebugLevel:
+		return colorMagenta
+	case zapcore.InfoLevel:
+		return colorBlue
+	case zapcore.WarnLevel:
+		return colorYellow
+	default:
+		return colorRed
+	}
+}
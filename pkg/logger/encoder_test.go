@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDevEncoder(t *testing.T) {
+	entry := zapcore.Entry{
+		Level:      zapcore.InfoLevel,
+		Time:       time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+		LoggerName: "cache",
+		Message:    "hit",
+	}
+	fields := []zapcore.Field{
+		zap.String("zeta", "last"),
+		zap.String("user_id", "u1"),
+		zap.String(TraceIDKey, "trace-1700000000-abcdef12"),
+		zap.String("note", "has spaces"),
+	}
+
+	tests := []struct {
+		name string
+		cfg  DevEncoderConfig
+		want string
+	}{
+		{
+			name: "default ordering",
+			cfg:  DevEncoderConfig{},
+			want: `15:04:05.000 INFO  [cache] hit note="has spaces" trace_id=trace-1700000000-abcdef12 user_id=u1 zeta=last` + "\n",
+		},
+		{
+			name: "custom ordering and short trace id",
+			cfg:  DevEncoderConfig{FieldOrder: []string{TraceIDKey, "user_id"}, TraceIDLength: 8},
+			want: `15:04:05.000 INFO  [cache] hit trace_id=…abcdef12 user_id=u1 note="has spaces" zeta=last` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := newDevEncoder(tt.cfg).EncodeEntry(entry, fields)
+			if err != nil {
+				t.Fatalf("EncodeEntry() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("EncodeEntry() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDevEncoderColorAndClone(t *testing.T) {
+	enc := newDevEncoder(DevEncoderConfig{Color: true})
+	enc.AddString("service", "api")
+
+	clone := enc.Clone()
+	clone.AddString("extra", "x")
+
+	buf, err := enc.EncodeEntry(zapcore.Entry{Level: zapcore.ErrorLevel, Message: "boom"}, nil)
+	if err != nil {
+		t.Fatalf("EncodeEntry() error = %v", err)
+	}
+	got := buf.String()
+
+	if !strings.Contains(got, colorRed+"ERROR"+colorReset) {
+		t.Errorf("error level should be red: %q", got)
+	}
+	if !strings.Contains(got, "service=") || strings.Contains(got, "extra=") {
+		t.Errorf("clone should not leak fields into parent: %q", got)
+	}
+}
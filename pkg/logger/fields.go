@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Field is a strongly-typed log field, encoded without reflection
+type Field = zap.Field
+
+// String constructs a field with a string value
+func String(key, val string) Field {
+	return zap.String(key, val)
+}
+
+// Strings constructs a field with a string slice value
+func Strings(key string, vals []string) Field {
+	return zap.Strings(key, vals)
+}
+
+// Int constructs a field with an int value
+func Int(key string, val int) Field {
+	return zap.Int(key, val)
+}
+
+// Int64 constructs a field with an int64 value
+func Int64(key string, val int64) Field {
+	return zap.Int64(key, val)
+}
+
+// Uint64 constructs a field with a uint64 value
+func Uint64(key string, val uint64) Field {
+	return zap.Uint64(key, val)
+}
+
+// Float64 constructs a field with a float64 value
+func Float64(key string, val float64) Field {
+	return zap.Float64(key, val)
+}
+
+// Bool constructs a field with a bool value
+func Bool(key string, val bool) Field {
+	return zap.Bool(key, val)
+}
+
+// Duration constructs a field with a time.Duration value
+func Duration(key string, val time.Duration) Field {
+	return zap.Duration(key, val)
+}
+
+// Time constructs a field with a time.Time value
+func Time(key string, val time.Time) Field {
+	return zap.Time(key, val)
+}
+
+// Err constructs an "error" field; a nil error adds nothing
+func Err(err error) Field {
+	return zap.Error(err)
+}
+
+// Any constructs a field with an arbitrary value, choosing the typed
+// encoding when possible and falling back to reflection
+func Any(key string, val interface{}) Field {
+	return zap.Any(key, val)
+}
+
+// FromMap converts a map to fields sorted by key, for callers migrating from
+// map based fields
+func FromMap(fields map[string]interface{}) []Field {
+	out := make([]Field, 0, len(fields))
+	for _, k := range sortedKeys(fields) {
+		out = append(out, zap.Any(k, fields[k]))
+	}
+	return out
+}
+
+// Typed returns the strongly-typed logger sharing the levels, hooks, sinks
+// and fields of l. Prefer it over the sugared methods in hot paths:
+//
+//	log.Typed().Info("order created", logger.String("order_id", id), logger.Int("items", n))
+func (l *Logger) Typed() *zap.Logger {
+	return l.SugaredLogger.Desugar()
+}
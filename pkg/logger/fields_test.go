@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTypedFields(t *testing.T) {
+	l, logs := newObservedLogger(t, "info", nil)
+
+	l.WithFields(String("order_id", "o-1"), Int("items", 3)).Typed().Info("created",
+		Err(errors.New("boom")), Duration("took", time.Second), Bool("paid", true))
+	l.WithFields(FromMap(map[string]interface{}{"b": 2, "a": "x"})...).Info("mapped")
+
+	tests := []struct {
+		message string
+		want    map[string]interface{}
+	}{
+		{message: "created", want: map[string]interface{}{
+			"order_id": "o-1", "items": int64(3), "error": "boom", "took": time.Second, "paid": true,
+		}},
+		{message: "mapped", want: map[string]interface{}{"a": "x", "b": int64(2)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			entries := logs.FilterMessage(tt.message).All()
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			got := entries[0].ContextMap()
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("field %s = %#v, want %#v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestWithFieldsEmpty(t *testing.T) {
+	l, _ := newObservedLogger(t, "info", nil)
+	if l.WithFields() != l {
+		t.Error("WithFields() without fields should return the logger itself")
+	}
+}
@@ -0,0 +1,335 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultFileMaxSize is the default size in megabytes at which a log file
+	// is rotated
+	DefaultFileMaxSize = 100
+
+	// backupTimeLayout is the timestamp inserted into rotated file names,
+	// e.g. app-2024-05-01T10-00-00.000.log
+	backupTimeLayout = "2006-01-02T15-04-05.000"
+
+	// compressSuffix is appended to compressed backups
+	compressSuffix = ".gz"
+)
+
+// FileConfig configures rotation of the file outputs
+type FileConfig struct {
+	// MaxSize is the size in megabytes at which a file is rotated,
+	// defaults to DefaultFileMaxSize
+	MaxSize int `json:"max_size" yaml:"max_size"`
+	// MaxAge removes backups older than this, zero keeps them regardless of age
+	MaxAge time.Duration `json:"max_age" yaml:"max_age"`
+	// MaxBackups is the number of rotated files kept, zero keeps them all
+	MaxBackups int `json:"max_backups" yaml:"max_backups"`
+	// Compress gzips rotated files
+	Compress bool `json:"compress" yaml:"compress"`
+	// LocalTime uses local time instead of UTC in backup names
+	LocalTime bool `json:"local_time" yaml:"local_time"`
+}
+
+// newOutput opens every configured output and combines them; an empty list
+// writes to stderr. The returned files must be closed with the logger.
+func newOutput(cfg Config) (zapcore.WriteSyncer, []*fileWriter, error) {
+	if len(cfg.Outputs) == 0 {
+		return zapcore.Lock(os.Stderr), nil, nil
+	}
+
+	syncers := make([]zapcore.WriteSyncer, 0, len(cfg.Outputs))
+	var files []*fileWriter
+	for _, path := range cfg.Outputs {
+		switch path {
+		case "stdout":
+			syncers = append(syncers, zapcore.Lock(os.Stdout))
+		case "stderr":
+			syncers = append(syncers, zapcore.Lock(os.Stderr))
+		default:
+			file, err := newFileWriter(path, cfg.File)
+			if err != nil {
+				closeFiles(files)
+				return nil, nil, err
+			}
+			files = append(files, file)
+			syncers = append(syncers, file)
+		}
+	}
+
+	if len(syncers) == 1 {
+		return syncers[0], files, nil
+	}
+	return zapcore.NewMultiWriteSyncer(syncers...), files, nil
+}
+
+// closeFiles closes every file output and joins their errors
+func closeFiles(files []*fileWriter) error {
+	var errs []error
+	for _, file := range files {
+		if err := file.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fileWriter is a zapcore.WriteSyncer appending to a file that is rotated
+// once it reaches the maximum size. Backups are compressed and pruned by a
+// background goroutine so that rotation never blocks on old files.
+type fileWriter struct {
+	path string
+	cfg  FileConfig
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	mill      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// newFileWriter opens path for appending, creating it and its directory
+func newFileWriter(path string, cfg FileConfig) (*fileWriter, error) {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultFileMaxSize
+	}
+
+	w := &fileWriter{path: path, cfg: cfg, mill: make(chan struct{}, 1)}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go w.runMill()
+	return w, nil
+}
+
+// open opens or creates the current file
+func (w *fileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	w.file, w.size = file, info.Size()
+	return nil
+}
+
+// Write implements zapcore.WriteSyncer, rotating first when p would push the
+// file over the maximum size
+func (w *fileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, errors.New("log file is closed")
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxBytes() {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Sync implements zapcore.WriteSyncer
+func (w *fileWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Rotate closes the current file, renames it to a timestamped backup and
+// starts a new one, e.g. from a SIGHUP handler
+func (w *fileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rotate()
+}
+
+// rotate must be called with mu held
+func (w *fileWriter) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("failed to close log file: %w", err)
+		}
+		w.file = nil
+	}
+	if err := os.Rename(w.path, w.backupName(w.now())); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	select {
+	case w.mill <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Close flushes and closes the file and waits for pending compression
+func (w *fileWriter) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		if w.file != nil {
+			err = w.file.Close()
+			w.file = nil
+		}
+		w.mu.Unlock()
+
+		close(w.mill)
+		w.wg.Wait()
+	})
+	return err
+}
+
+// maxBytes returns the rotation threshold in bytes
+func (w *fileWriter) maxBytes() int64 {
+	return int64(w.cfg.MaxSize) * 1024 * 1024
+}
+
+// now returns the time used in backup names
+func (w *fileWriter) now() time.Time {
+	if w.cfg.LocalTime {
+		return time.Now()
+	}
+	return time.Now().UTC()
+}
+
+// backupName inserts the timestamp between the file name and its extension
+func (w *fileWriter) backupName(t time.Time) string {
+	prefix, ext := w.nameParts()
+	return filepath.Join(filepath.Dir(w.path), prefix+t.Format(backupTimeLayout)+ext)
+}
+
+// nameParts returns the "name-" prefix and extension shared by backups
+func (w *fileWriter) nameParts() (string, string) {
+	base := filepath.Base(w.path)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// runMill compresses and prunes backups after each rotation
+func (w *fileWriter) runMill() {
+	defer w.wg.Done()
+	for range w.mill {
+		_ = w.millBackups()
+	}
+}
+
+// backup is a rotated file found next to the current one
+type backup struct {
+	path string
+	time time.Time
+}
+
+// millBackups removes the backups beyond MaxBackups or older than MaxAge
+// and compresses the remaining ones
+func (w *fileWriter) millBackups() error {
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	cutoff := w.now().Add(-w.cfg.MaxAge)
+	for i, b := range backups {
+		if (w.cfg.MaxBackups > 0 && i >= w.cfg.MaxBackups) || (w.cfg.MaxAge > 0 && b.time.Before(cutoff)) {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if w.cfg.Compress && !strings.HasSuffix(b.path, compressSuffix) {
+			if err := compressFile(b.path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// backups lists the rotated files, newest first
+func (w *fileWriter) backups() ([]backup, error) {
+	entries, err := os.ReadDir(filepath.Dir(w.path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log directory: %w", err)
+	}
+
+	prefix, ext := w.nameParts()
+	var backups []backup
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), compressSuffix)
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		t, err := time.ParseInLocation(backupTimeLayout, ts, w.now().Location())
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(filepath.Dir(w.path), entry.Name()), time: t})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].time.After(backups[j].time) })
+	return backups, nil
+}
+
+// compressFile gzips path into path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open log backup: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create compressed log backup: %w", err)
+	}
+
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + compressSuffix)
+		return fmt.Errorf("failed to compress log backup: %w", err)
+	}
+	return os.Remove(path)
+}
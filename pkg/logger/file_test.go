@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileWriterRotate(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         FileConfig
+		rotations   int
+		wantBackups int
+		wantSuffix  string
+	}{
+		{name: "keep all", cfg: FileConfig{}, rotations: 3, wantBackups: 3, wantSuffix: ".log"},
+		{name: "max backups", cfg: FileConfig{MaxBackups: 2}, rotations: 3, wantBackups: 2, wantSuffix: ".log"},
+		{name: "compress", cfg: FileConfig{Compress: true}, rotations: 2, wantBackups: 2, wantSuffix: ".log.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			w, err := newFileWriter(filepath.Join(dir, "app.log"), tt.cfg)
+			if err != nil {
+				t.Fatalf("newFileWriter() error = %v", err)
+			}
+
+			for i := 0; i < tt.rotations; i++ {
+				if _, err := w.Write([]byte("entry\n")); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+				if err := w.Rotate(); err != nil {
+					t.Fatalf("Rotate() error = %v", err)
+				}
+				// backup names carry millisecond timestamps
+				time.Sleep(2 * time.Millisecond)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			backups, err := w.backups()
+			if err != nil {
+				t.Fatalf("backups() error = %v", err)
+			}
+			if len(backups) != tt.wantBackups {
+				t.Fatalf("backups = %d, want %d", len(backups), tt.wantBackups)
+			}
+			for _, b := range backups {
+				if !strings.HasSuffix(b.path, tt.wantSuffix) {
+					t.Errorf("backup %s, want suffix %s", b.path, tt.wantSuffix)
+				}
+			}
+		})
+	}
+}
+
+func TestFileWriterRotatesAtMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := newFileWriter(path, FileConfig{MaxSize: 1})
+	if err != nil {
+		t.Fatalf("newFileWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	chunk := bytes.Repeat([]byte("x"), 600*1024)
+	for i := 0; i < 2; i++ {
+		if _, err := w.Write(chunk); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Size() != int64(len(chunk)) {
+		t.Errorf("current file size = %d, want %d", info.Size(), len(chunk))
+	}
+	if backups, _ := w.backups(); len(backups) != 1 {
+		t.Errorf("backups = %d, want 1", len(backups))
+	}
+}
+
+func TestCompressFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app-backup.log")
+	if err := os.WriteFile(path, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := compressFile(path); err != nil {
+		t.Fatalf("compressFile() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("original backup should be removed")
+	}
+
+	f, err := os.Open(path + compressSuffix)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	got, _ := io.ReadAll(gz)
+	if string(got) != "hello\n" {
+		t.Errorf("decompressed = %q, want %q", got, "hello\n")
+	}
+}
+
+func TestLoggerFileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	l, err := New(Config{Level: "info", Format: "json", Outputs: []string{"stdout", path}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	l.Infow("order created", "order_id", 42)
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"order_id":42`) {
+		t.Errorf("log file = %q, want the entry", data)
+	}
+}
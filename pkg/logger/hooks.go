@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// HookLevel is the minimum level at which hooks are invoked
+const HookLevel = zapcore.WarnLevel
+
+// Entry is the log entry passed to hooks and backends
+type Entry struct {
+	Level   string
+	Time    time.Time
+	Logger  string
+	Message string
+	Caller  string
+	Stack   string
+	Fields  map[string]interface{}
+}
+
+// Hook receives warn, error and fatal entries, e.g. to forward them to
+// Sentry or PagerDuty. Hooks run synchronously on the logging goroutine, so
+// slow reporters should hand entries off to their own queue.
+type Hook func(Entry) error
+
+// hookRegistry holds the hooks shared by a logger and all of its children
+type hookRegistry struct {
+	mu    sync.RWMutex
+	hooks []Hook
+}
+
+// add registers a hook
+func (r *hookRegistry) add(hook Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, hook)
+}
+
+// snapshot returns the currently registered hooks
+func (r *hookRegistry) snapshot() []Hook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.hooks
+}
+
+// fire invokes every hook and joins their errors
+func (r *hookRegistry) fire(entry Entry) error {
+	var errs []error
+	for _, hook := range r.snapshot() {
+		if err := hook(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hookCore is a zapcore.Core that hands warn+ entries to the hook registry
+type hookCore struct {
+	hooks  *hookRegistry
+	fields []zapcore.Field
+}
+
+// Enabled implements zapcore.Core
+func (c *hookCore) Enabled(level zapcore.Level) bool {
+	return level >= HookLevel && len(c.hooks.snapshot()) > 0
+}
+
+// With implements zapcore.Core
+func (c *hookCore) With(fields []zapcore.Field) zapcore.Core {
+	combined := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	combined = append(combined, c.fields...)
+	combined = append(combined, fields...)
+	return &hookCore{hooks: c.hooks, fields: combined}
+}
+
+// Check implements zapcore.Core
+func (c *hookCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write implements zapcore.Core
+func (c *hookCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.hooks.fire(newEntry(entry, c.fields, fields))
+}
+
+// newEntry flattens a zap entry and its accumulated and call-site fields
+func newEntry(entry zapcore.Entry, base, fields []zapcore.Field) Entry {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range base {
+		field.AddTo(enc)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	var caller string
+	if entry.Caller.Defined {
+		caller = entry.Caller.TrimmedPath()
+	}
+
+	return Entry{
+		Level:   entry.Level.String(),
+		Time:    entry.Time,
+		Logger:  entry.LoggerName,
+		Message: entry.Message,
+		Caller:  caller,
+		Stack:   entry.Stack,
+		Fields:  enc.Fields,
+	}
+}
+
+// Sync implements zapcore.Core
+func (c *hookCore) Sync() error {
+	return nil
+}
+
+// AddHook registers a hook invoked for warn, error and fatal entries of this
+// logger and every logger derived from the same root
+func (l *Logger) AddHook(hook Hook) {
+	if l.hooks == nil || hook == nil {
+		return
+	}
+	l.hooks.add(hook)
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddHook(t *testing.T) {
+	root, logs := newObservedLogger(t, "debug", nil)
+
+	var entries []Entry
+	root.AddHook(func(e Entry) error {
+		entries = append(entries, e)
+		return nil
+	})
+
+	log := root.Named("payment").WithFields(String("order_id", "o-1"))
+	log.Info("ignored")
+	log.Warnw("slow response", "latency_ms", 1200)
+	log.Error("failed")
+
+	if logs.Len() != 3 {
+		t.Errorf("observed entries = %d, want 3", logs.Len())
+	}
+	if len(entries) != 2 {
+		t.Fatalf("hook entries = %d, want 2", len(entries))
+	}
+
+	warn := entries[0]
+	if warn.Level != "warn" || warn.Message != "slow response" {
+		t.Errorf("unexpected entry: %+v", warn)
+	}
+	if warn.Logger != "payment" {
+		t.Errorf("Logger = %v, want payment", warn.Logger)
+	}
+	if warn.Fields["order_id"] != "o-1" {
+		t.Errorf("order_id field = %v, want o-1", warn.Fields["order_id"])
+	}
+	if warn.Fields["latency_ms"] != int64(1200) {
+		t.Errorf("latency_ms field = %v, want 1200", warn.Fields["latency_ms"])
+	}
+}
+
+func TestHookRegistryFire(t *testing.T) {
+	errA := errors.New("a")
+	errB := errors.New("b")
+
+	r := &hookRegistry{}
+	r.add(func(Entry) error { return errA })
+	r.add(func(Entry) error { return nil })
+	r.add(func(Entry) error { return errB })
+
+	err := r.fire(Entry{})
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("fire() error = %v, want both hook errors", err)
+	}
+}
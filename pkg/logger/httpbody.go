@@ -0,0 +1,369 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxBodySize is the default number of body bytes captured
+	DefaultMaxBodySize = 4096
+	// RedactedValue replaces redacted field and header values
+	RedactedValue = "[REDACTED]"
+)
+
+var (
+	// DefaultBodyContentTypes are the content types captured by default
+	DefaultBodyContentTypes = []string{
+		"application/json",
+		"application/xml",
+		"application/x-www-form-urlencoded",
+		"text/",
+	}
+	// DefaultRedactHeaders are the headers redacted by default
+	DefaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+)
+
+// BodyLogConfig configures request/response body capture
+type BodyLogConfig struct {
+	// MaxBodySize caps the bytes captured per body, defaults to DefaultMaxBodySize
+	MaxBodySize int
+	// ContentTypes lists captured media types; entries ending in "/" match a
+	// whole family such as "text/". Defaults to DefaultBodyContentTypes.
+	ContentTypes []string
+	// RedactFields lists JSON/form keys whose values are replaced, matched
+	// case-insensitively at any depth. Defaults to DefaultRedactFields.
+	RedactFields []string
+	// RedactHeaders lists headers whose values are replaced. Defaults to
+	// DefaultRedactHeaders.
+	RedactHeaders []string
+	// Message is the log message, defaults to "http exchange"
+	Message string
+}
+
+// BodyLogger captures HTTP bodies and writes them as one structured entry
+type BodyLogger struct {
+	log           *Logger
+	cfg           BodyLogConfig
+	redactFields  map[string]bool
+	redactHeaders map[string]bool
+}
+
+// NewBodyLogger creates a body logger, filling unset options with defaults
+func NewBodyLogger(log *Logger, cfg BodyLogConfig) *BodyLogger {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = DefaultMaxBodySize
+	}
+	if cfg.ContentTypes == nil {
+		cfg.ContentTypes = DefaultBodyContentTypes
+	}
+	if cfg.RedactFields == nil {
+		cfg.RedactFields = DefaultRedactFields
+	}
+	if cfg.RedactHeaders == nil {
+		cfg.RedactHeaders = DefaultRedactHeaders
+	}
+	if cfg.Message == "" {
+		cfg.Message = "http exchange"
+	}
+
+	b := &BodyLogger{
+		log:           log,
+		cfg:           cfg,
+		redactFields:  make(map[string]bool, len(cfg.RedactFields)),
+		redactHeaders: make(map[string]bool, len(cfg.RedactHeaders)),
+	}
+	for _, f := range cfg.RedactFields {
+		b.redactFields[strings.ToLower(f)] = true
+	}
+	for _, h := range cfg.RedactHeaders {
+		b.redactHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+	return b
+}
+
+// CaptureRequest reads up to MaxBodySize bytes of the request body and
+// restores the body so downstream handlers still see all of it
+func (b *BodyLogger) CaptureRequest(r *http.Request) []byte {
+	if r.Body == nil || r.Body == http.NoBody || !b.capturable(r.Header.Get("Content-Type")) {
+		return nil
+	}
+
+	captured, err := io.ReadAll(io.LimitReader(r.Body, int64(b.cfg.MaxBodySize)))
+	if err != nil {
+		return nil
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(captured), r.Body), r.Body}
+	return captured
+}
+
+// BodyRecorder is an http.ResponseWriter that records the status code and
+// the first bytes of the response body
+type BodyRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+	limit  int
+	body   bytes.Buffer
+}
+
+// NewRecorder wraps w so the response can be logged after the handler runs
+func (b *BodyLogger) NewRecorder(w http.ResponseWriter) *BodyRecorder {
+	return &BodyRecorder{ResponseWriter: w, status: http.StatusOK, limit: b.cfg.MaxBodySize}
+}
+
+// WriteHeader implements http.ResponseWriter
+func (r *BodyRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (r *BodyRecorder) Write(p []byte) (int, error) {
+	if remaining := r.limit - r.body.Len(); remaining > 0 {
+		if len(p) < remaining {
+			remaining = len(p)
+		}
+		r.body.Write(p[:remaining])
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.size += n
+	return n, err
+}
+
+// Status returns the recorded status code
+func (r *BodyRecorder) Status() int {
+	return r.status
+}
+
+// Size returns the number of body bytes written to the client
+func (r *BodyRecorder) Size() int {
+	return r.size
+}
+
+// Body returns the captured prefix of the response body
+func (r *BodyRecorder) Body() []byte {
+	return r.body.Bytes()
+}
+
+// Exchange describes one captured request/response pair
+type Exchange struct {
+	Request        *http.Request
+	RequestBody    []byte
+	Status         int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	ResponseSize   int
+	Latency        time.Duration
+	ExtraFields    map[string]interface{}
+}
+
+// Fields builds the structured fields of an exchange, applying content-type
+// filters and redaction
+func (b *BodyLogger) Fields(ex Exchange) map[string]interface{} {
+	r := ex.Request
+	fields := map[string]interface{}{
+		"method":      r.Method,
+		"path":        r.URL.Path,
+		"status":      ex.Status,
+		"latency_ms":  ex.Latency.Milliseconds(),
+		"req_headers": b.headers(r.Header),
+	}
+	if r.URL.RawQuery != "" {
+		fields["query"] = r.URL.RawQuery
+	}
+	if len(ex.RequestBody) > 0 {
+		fields["req_body"] = b.redactBody(r.Header.Get("Content-Type"), ex.RequestBody)
+		fields["req_body_truncated"] = len(ex.RequestBody) >= b.cfg.MaxBodySize
+	}
+
+	if ex.ResponseHeader != nil {
+		fields["resp_headers"] = b.headers(ex.ResponseHeader)
+		contentType := ex.ResponseHeader.Get("Content-Type")
+		if len(ex.ResponseBody) > 0 && b.capturable(contentType) {
+			fields["resp_body"] = b.redactBody(contentType, ex.ResponseBody)
+			fields["resp_body_truncated"] = ex.ResponseSize > len(ex.ResponseBody)
+		}
+	}
+
+	for k, v := range ex.ExtraFields {
+		fields[k] = v
+	}
+	return fields
+}
+
+// Log writes an exchange as a single entry, at warn level for 5xx responses
+func (b *BodyLogger) Log(ex Exchange) {
+	log := b.log.WithContext(ex.Request.Context()).WithFields(FromMap(b.Fields(ex))...)
+	if ex.Status >= http.StatusInternalServerError {
+		log.Warn(b.cfg.Message)
+		return
+	}
+	log.Info(b.cfg.Message)
+}
+
+// Middleware returns a net/http middleware that logs every exchange
+func (b *BodyLogger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		reqBody := b.CaptureRequest(r)
+		rec := b.NewRecorder(w)
+
+		next.ServeHTTP(rec, r)
+
+		b.Log(Exchange{
+			Request:        r,
+			RequestBody:    reqBody,
+			Status:         rec.Status(),
+			ResponseHeader: rec.Header(),
+			ResponseBody:   rec.Body(),
+			ResponseSize:   rec.Size(),
+			Latency:        time.Since(start),
+		})
+	})
+}
+
+// capturable reports whether a body of the given content type is logged
+func (b *BodyLogger) capturable(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range b.cfg.ContentTypes {
+		if strings.HasSuffix(allowed, "/") && strings.HasPrefix(mediaType, allowed) {
+			return true
+		}
+		if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// headers flattens headers and redacts sensitive ones
+func (b *BodyLogger) headers(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if b.redactHeaders[http.CanonicalHeaderKey(k)] {
+			out[k] = RedactedValue
+			continue
+		}
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+// redactBody masks sensitive values in JSON and form bodies
+func (b *BodyLogger) redactBody(contentType string, body []byte) interface{} {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case "application/json":
+		var payload interface{}
+		if err := json.Unmarshal(body, &payload); err == nil {
+			return b.redactValue(payload)
+		}
+	case "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			for k := range values {
+				if b.redactFields[strings.ToLower(k)] {
+					values[k] = []string{RedactedValue}
+				}
+			}
+			return values.Encode()
+		}
+	}
+
+	// Bodies that cannot be parsed (e.g. truncated JSON) are logged as text
+	// with any `"field": "value"` pairs of sensitive fields masked.
+	return b.redactText(string(body))
+}
+
+// redactValue walks decoded JSON and masks sensitive keys at any depth
+func (b *BodyLogger) redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, inner := range val {
+			if b.redactFields[strings.ToLower(k)] {
+				val[k] = RedactedValue
+				continue
+			}
+			val[k] = b.redactValue(inner)
+		}
+		return val
+	case []interface{}:
+		for i, inner := range val {
+			val[i] = b.redactValue(inner)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// redactText masks `"field":"value"` pairs in unparsable text
+func (b *BodyLogger) redactText(s string) string {
+	lower := strings.ToLower(s)
+	for field := range b.redactFields {
+		needle := `"` + field + `"`
+		offset := 0
+		for {
+			idx := strings.Index(lower[offset:], needle)
+			if idx < 0 {
+				break
+			}
+			start := offset + idx + len(needle)
+			valueStart, valueEnd := quotedValueBounds(s, start)
+			if valueStart < 0 {
+				offset = start
+				continue
+			}
+			s = s[:valueStart] + RedactedValue + s[valueEnd:]
+			lower = lower[:valueStart] + RedactedValue + lower[valueEnd:]
+			offset = valueStart + len(RedactedValue)
+		}
+	}
+	return s
+}
+
+// quotedValueBounds finds the contents of the string value after a JSON key
+// starting at pos, i.e. the `abc` in `: "abc"`. The value may be unterminated.
+func quotedValueBounds(s string, pos int) (int, int) {
+	i := pos
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	if i >= len(s) || s[i] != ':' {
+		return -1, -1
+	}
+	i++
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	if i >= len(s) || s[i] != '"' {
+		return -1, -1
+	}
+	start := i + 1
+	for j := start; j < len(s); j++ {
+		if s[j] == '\\' {
+			j++
+			continue
+		}
+		if s[j] == '"' {
+			return start, j
+		}
+	}
+	return start, len(s)
+}
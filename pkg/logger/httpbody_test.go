@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLoggerMiddleware(t *testing.T) {
+	root, logs := newObservedLogger(t, "info", nil)
+	bl := NewBodyLogger(root, BodyLogConfig{MaxBodySize: 64})
+
+	var seen string
+	handler := bl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		seen = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"o-1","token":"abc"}`))
+	}))
+
+	reqBody := `{"user":"admin","password":"secret","nested":{"Token":"t"}}`
+	req := httptest.NewRequest(http.MethodPost, "/login?x=1", strings.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer abc")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if seen != reqBody {
+		t.Errorf("handler body = %q, want %q", seen, reqBody)
+	}
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+
+	if fields["status"] != int64(http.StatusCreated) {
+		t.Errorf("status = %v, want 201", fields["status"])
+	}
+	reqFields := fields["req_body"].(map[string]interface{})
+	if reqFields["password"] != RedactedValue || reqFields["user"] != "admin" {
+		t.Errorf("req_body not redacted: %v", reqFields)
+	}
+	if reqFields["nested"].(map[string]interface{})["Token"] != RedactedValue {
+		t.Errorf("nested token not redacted: %v", reqFields)
+	}
+	if fields["req_headers"].(map[string]string)["Authorization"] != RedactedValue {
+		t.Errorf("authorization header not redacted: %v", fields["req_headers"])
+	}
+	respFields := fields["resp_body"].(map[string]interface{})
+	if respFields["token"] != RedactedValue {
+		t.Errorf("resp_body not redacted: %v", respFields)
+	}
+}
+
+func TestBodyLoggerFilters(t *testing.T) {
+	root, _ := newObservedLogger(t, "info", nil)
+	bl := NewBodyLogger(root, BodyLogConfig{MaxBodySize: 24})
+
+	t.Run("skips binary content", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("binary"))
+		req.Header.Set("Content-Type", "application/octet-stream")
+		if got := bl.CaptureRequest(req); got != nil {
+			t.Errorf("CaptureRequest() = %q, want nil", got)
+		}
+	})
+
+	t.Run("caps size and keeps full body", func(t *testing.T) {
+		body := `{"password":"supersecretvalue","a":1}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		captured := bl.CaptureRequest(req)
+		if len(captured) != 24 {
+			t.Errorf("captured %d bytes, want 24", len(captured))
+		}
+		rest, _ := io.ReadAll(req.Body)
+		if string(rest) != body {
+			t.Errorf("restored body = %q, want %q", rest, body)
+		}
+
+		redacted := bl.redactBody("application/json", captured)
+		if s, ok := redacted.(string); !ok || strings.Contains(s, "supersecret") {
+			t.Errorf("truncated body not redacted: %v", redacted)
+		}
+	})
+
+	t.Run("redacts form bodies", func(t *testing.T) {
+		got := bl.redactBody("application/x-www-form-urlencoded", []byte("user=a&password=b"))
+		if got != "password=%5BREDACTED%5D&user=a" {
+			t.Errorf("redactBody() = %v", got)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// SetLevel changes the root level at runtime. It is shared by the logger,
+// its children and named loggers without a module override.
+func (l *Logger) SetLevel(level string) error {
+	if l.levels == nil {
+		return nil
+	}
+
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	l.levels.root.SetLevel(lvl)
+	return nil
+}
+
+// Level returns the root level
+func (l *Logger) Level() string {
+	if l.levels == nil {
+		return ""
+	}
+	return l.levels.root.Level().String()
+}
+
+// SetLevel changes the root level of the default logger
+func SetLevel(level string) error {
+	return NewDefault().SetLevel(level)
+}
+
+// levelPayload is the body served and accepted by LevelHandler
+type levelPayload struct {
+	Level   string            `json:"level"`
+	Module  string            `json:"module,omitempty"`
+	Modules map[string]string `json:"modules,omitempty"`
+}
+
+// LevelHandler serves the levels of l, e.g. mounted on /debug/loglevel.
+// GET returns the root level and module overrides; PUT or POST with
+// {"level": "debug"} changes the root level, or the level of one module
+// with {"module": "cache", "level": "debug"}. Form values are accepted too:
+//
+//	curl -X PUT 'localhost:8080/debug/loglevel?level=debug&module=cache'
+//
+// Mount it on an internal port or behind authentication.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var req levelPayload
+			if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					writeLevelError(w, http.StatusBadRequest, "invalid request body")
+					return
+				}
+			} else {
+				req.Level, req.Module = r.FormValue("level"), r.FormValue("module")
+			}
+
+			var err error
+			if req.Module != "" {
+				err = l.SetModuleLevel(req.Module, req.Level)
+			} else {
+				err = l.SetLevel(req.Level)
+			}
+			if err != nil {
+				writeLevelError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			writeLevelError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(levelPayload{Level: l.Level(), Modules: l.levels.snapshot()})
+	})
+}
+
+// writeLevelError writes a JSON error for LevelHandler
+func writeLevelError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
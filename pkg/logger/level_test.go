@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetLevel(t *testing.T) {
+	l, logs := newObservedLogger(t, "info", nil)
+	child := l.Named("cache")
+
+	child.Debug("hidden")
+	if err := l.SetLevel("debug"); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	child.Debug("shown")
+
+	if got := logs.FilterMessage("hidden").Len(); got != 0 {
+		t.Errorf("debug entry before SetLevel logged %d times", got)
+	}
+	if got := logs.FilterMessage("shown").Len(); got != 1 {
+		t.Errorf("debug entry after SetLevel logged %d times, want 1", got)
+	}
+	if err := l.SetLevel("verbose"); err == nil {
+		t.Error("SetLevel() with an unknown level should fail")
+	}
+	if got := l.Level(); got != "debug" {
+		t.Errorf("Level() = %q, want debug", got)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantLevel   string
+		wantModules map[string]string
+	}{
+		{name: "get", method: http.MethodGet, target: "/", wantStatus: http.StatusOK, wantLevel: "info"},
+		{name: "put json", method: http.MethodPut, target: "/", contentType: "application/json",
+			body: `{"level":"debug"}`, wantStatus: http.StatusOK, wantLevel: "debug"},
+		{name: "put module", method: http.MethodPut, target: "/?level=warn&module=db",
+			wantStatus: http.StatusOK, wantLevel: "info", wantModules: map[string]string{"db": "warn"}},
+		{name: "invalid level", method: http.MethodPost, target: "/?level=loud", wantStatus: http.StatusBadRequest},
+		{name: "invalid body", method: http.MethodPut, target: "/", contentType: "application/json",
+			body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "method", method: http.MethodDelete, target: "/", wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newObservedLogger(t, "info", nil)
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			l.LevelHandler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got levelPayload
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("invalid body %q: %v", rec.Body.String(), err)
+			}
+			if got.Level != tt.wantLevel {
+				t.Errorf("level = %q, want %q", got.Level, tt.wantLevel)
+			}
+			for module, level := range tt.wantModules {
+				if got.Modules[module] != level {
+					t.Errorf("modules[%s] = %q, want %q", module, got.Modules[module], level)
+				}
+			}
+		})
+	}
+}
@@ -2,8 +2,12 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -12,47 +16,187 @@ import (
 // Logger represents a logger instance
 type Logger struct {
 	*zap.SugaredLogger
+	levels   *levelRegistry
+	hooks    *hookRegistry
+	counters *counterRegistry
+	sinks    []*sinkCore
+	async    *asyncWriter
+	files    []*fileWriter
+	redact   *redactRegistry
+	name     string
 }
 
 // Config holds the logger configuration
 type Config struct {
 	Level  string `json:"level" yaml:"level"`   // debug, info, warn, error
-	Format string `json:"format" yaml:"format"` // json, console
+	Format string `json:"format" yaml:"format"` // json, console, dev
+	// Modules overrides the level of named loggers, e.g. {"cache": "debug", "db": "warn"}
+	Modules map[string]string `json:"modules" yaml:"modules"`
+	// Sinks ship entries to remote backends (OTLP, Loki) in addition to stderr
+	Sinks []SinkConfig `json:"sinks" yaml:"sinks"`
+	// Outputs lists where entries are written: "stdout", "stderr" or file
+	// paths, e.g. ["stdout", "/var/log/app/app.log"]; defaults to stderr
+	Outputs []string `json:"outputs" yaml:"outputs"`
+	// File configures rotation of the file outputs
+	File FileConfig `json:"file" yaml:"file"`
+	// Async moves writes to the outputs off the calling goroutine
+	Async AsyncConfig `json:"async" yaml:"async"`
+	// Dev configures the "dev" format
+	Dev DevEncoderConfig `json:"dev" yaml:"dev"`
+	// Redact masks sensitive field values, defaults to DefaultRedactFields
+	Redact RedactConfig `json:"redact" yaml:"redact"`
+	// Backend selects the library that writes the output: zap (default) or zerolog
+	Backend string `json:"backend" yaml:"backend"`
 }
 
-var defaultLogger *Logger
+var (
+	// defaultLogger backs the package-level functions; defaultMu serializes
+	// its lazy creation
+	defaultLogger atomic.Pointer[Logger]
+	defaultMu     sync.Mutex
+)
 
 // New creates a new logger instance
 func New(cfg Config) (*Logger, error) {
-	var level zapcore.Level
-	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
-		return nil, fmt.Errorf("invalid log level: %s", cfg.Level)
+	return newLogger(cfg, nil)
+}
+
+// NewWithBackend creates a logger whose output is written by the given
+// backend, e.g. NewZerologBackend(existingZerolog). Format and Async are
+// ignored since the backend owns encoding and writing.
+func NewWithBackend(cfg Config, backend Backend) (*Logger, error) {
+	if backend == nil {
+		return nil, errors.New("backend is required")
 	}
+	return newLogger(cfg, backend)
+}
 
-	var config zap.Config
-	if cfg.Format == "console" {
-		config = zap.NewDevelopmentConfig()
-		config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	} else {
-		config = zap.NewProductionConfig()
+// newLogger builds a logger; a nil backend selects the one named by
+// cfg.Backend
+func newLogger(cfg Config, backend Backend) (*Logger, error) {
+	level, err := parseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
 	}
 
-	config.Level = zap.NewAtomicLevelAt(level)
+	levels, err := newLevelRegistry(level, cfg.Modules)
+	if err != nil {
+		return nil, err
+	}
 
-	zapLogger, err := config.Build()
+	redact, err := newRedactRegistry(cfg.Redact)
 	if err != nil {
 		return nil, err
 	}
 
+	hooks := &hookRegistry{}
+	counters := &counterRegistry{}
+
+	sinks := make([]*sinkCore, 0, len(cfg.Sinks))
+	for _, sinkCfg := range cfg.Sinks {
+		sink, err := newSinkCore(sinkCfg)
+		if err != nil {
+			closeSinks(sinks)
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	output, files, err := newOutput(cfg)
+	if err != nil {
+		closeSinks(sinks)
+		return nil, err
+	}
+	var async *asyncWriter
+	if cfg.Async.Enabled && backend == nil {
+		async = newAsyncWriter(output, cfg.Async)
+		output = async
+	}
+
+	// The output core accepts every level; filtering is done by levelCore so
+	// that named loggers can apply their own level on top of the same output.
+	encoder, opts := newEncoder(cfg)
+	var core zapcore.Core
+	switch {
+	case backend != nil:
+		core = &backendCore{backend: backend}
+	case cfg.Backend == "zerolog":
+		core = &backendCore{backend: newZerologOutput(output, cfg.Format)}
+	case cfg.Backend == "" || cfg.Backend == "zap":
+		core = zapcore.NewCore(encoder, output, zapcore.DebugLevel)
+	default:
+		closeSinks(sinks)
+		if async != nil {
+			async.Close()
+		}
+		closeFiles(files)
+		return nil, fmt.Errorf("unsupported log backend: %s", cfg.Backend)
+	}
+	core = &redactCore{Core: core, redact: redact}
+	if cfg.Format != "console" && cfg.Format != "dev" {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
+	}
+
+	cores := []zapcore.Core{core, &redactCore{Core: &hookCore{hooks: hooks}, redact: redact}, &metricsCore{counters: counters}}
+	for _, sink := range sinks {
+		cores = append(cores, &redactCore{Core: sink, redact: redact})
+	}
+
+	zapLogger := zap.New(&levelCore{Core: zapcore.NewTee(cores...), enabler: levels.root}, opts...)
+
 	return &Logger{
 		SugaredLogger: zapLogger.Sugar(),
+		levels:        levels,
+		hooks:         hooks,
+		counters:      counters,
+		sinks:         sinks,
+		async:         async,
+		files:         files,
+		redact:        redact,
 	}, nil
 }
 
-// NewDefault creates a logger with default configuration
+// newEncoder returns the encoder and logger options for a format, mirroring
+// zap's production (json) and development (console, dev) presets
+func newEncoder(cfg Config) (zapcore.Encoder, []zap.Option) {
+	opts := []zap.Option{zap.AddCaller(), zap.ErrorOutput(zapcore.Lock(os.Stderr))}
+
+	switch cfg.Format {
+	case "console":
+		encCfg := zap.NewDevelopmentEncoderConfig()
+		encCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		opts = append(opts, zap.Development(), zap.AddStacktrace(zapcore.WarnLevel))
+		return zapcore.NewConsoleEncoder(encCfg), opts
+	case "dev":
+		opts = append(opts, zap.Development(), zap.AddStacktrace(zapcore.WarnLevel))
+		return newDevEncoder(cfg.Dev), opts
+	}
+
+	opts = append(opts, zap.AddStacktrace(zapcore.ErrorLevel))
+	return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), opts
+}
+
+// parseLevel converts a level name into a zapcore.Level
+func parseLevel(text string) (zapcore.Level, error) {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(text)); err != nil {
+		return level, fmt.Errorf("invalid log level: %s", text)
+	}
+	return level, nil
+}
+
+// NewDefault returns the default logger, creating it from the default
+// configuration on first use unless one was installed with SetDefault
 func NewDefault() *Logger {
-	if defaultLogger != nil {
-		return defaultLogger
+	if logger := defaultLogger.Load(); logger != nil {
+		return logger
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	if logger := defaultLogger.Load(); logger != nil {
+		return logger
 	}
 
 	cfg := Config{
@@ -61,8 +205,9 @@ func NewDefault() *Logger {
 	}
 
 	if os.Getenv("ENV") == "development" {
-		cfg.Format = "console"
+		cfg.Format = "dev"
 		cfg.Level = "debug"
+		cfg.Dev = DevEncoderConfig{Color: true, FieldOrder: []string{TraceIDKey}, TraceIDLength: 8}
 	}
 
 	logger, err := New(cfg)
@@ -70,38 +215,164 @@ func NewDefault() *Logger {
 		panic(fmt.Sprintf("failed to create default logger: %v", err))
 	}
 
-	defaultLogger = logger
-	return defaultLogger
+	defaultLogger.Store(logger)
+	return logger
+}
+
+// SetDefault installs the logger used by NewDefault and the package-level
+// functions. It is safe to call concurrently with logging; nil is ignored.
+func SetDefault(logger *Logger) {
+	if logger == nil {
+		return
+	}
+	defaultLogger.Store(logger)
 }
 
 // WithTraceID adds a trace ID to the logger context
 func (l *Logger) WithTraceID(traceID string) *Logger {
-	return &Logger{
-		SugaredLogger: l.SugaredLogger.With("trace_id", traceID),
-	}
+	return l.derive(l.SugaredLogger.With("trace_id", traceID))
 }
 
-// WithContext extracts trace ID from context and adds it to logger
+// WithContext adds the trace, user and request IDs and every field stored
+// with ContextWithFields
 func (l *Logger) WithContext(ctx context.Context) *Logger {
-	if traceID := GetTraceIDFromContext(ctx); traceID != "" {
-		return l.WithTraceID(traceID)
+	ids := [...][2]string{
+		{TraceIDKey, GetTraceIDFromContext(ctx)},
+		{UserIDKey, GetUserIDFromContext(ctx)},
+		{RequestIDKey, GetRequestIDFromContext(ctx)},
+	}
+	fields := GetFieldsFromContext(ctx)
+
+	args := make([]interface{}, 0, len(fields)*2+len(ids)*2)
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id[1] != "" {
+			args = append(args, id[0], id[1])
+			set[id[0]] = true
+		}
 	}
-	return l
+	for _, k := range sortedKeys(fields) {
+		if set[k] {
+			continue
+		}
+		args = append(args, k, fields[k])
+	}
+
+	if len(args) == 0 {
+		return l
+	}
+	return l.derive(l.SugaredLogger.With(args...))
 }
 
-// WithFields adds structured fields to the logger
-func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
-	args := make([]interface{}, 0, len(fields)*2)
-	for k, v := range fields {
-		args = append(args, k, v)
+// WithFields adds typed fields to the logger, e.g.
+// WithFields(logger.String("order_id", id), logger.Err(err))
+func (l *Logger) WithFields(fields ...Field) *Logger {
+	if len(fields) == 0 {
+		return l
 	}
+	return l.derive(l.SugaredLogger.Desugar().With(fields...).Sugar())
+}
+
+// derive wraps a child SugaredLogger while keeping the module level state
+func (l *Logger) derive(s *zap.SugaredLogger) *Logger {
 	return &Logger{
-		SugaredLogger: l.SugaredLogger.With(args...),
+		SugaredLogger: s,
+		levels:        l.levels,
+		hooks:         l.hooks,
+		counters:      l.counters,
+		sinks:         l.sinks,
+		async:         l.async,
+		files:         l.files,
+		redact:        l.redact,
+		name:          l.name,
 	}
 }
 
+// Close flushes buffered entries, stops the async writer and the
+// background sink exporters and closes the file outputs. It should be called
+// once, on the root logger, during graceful shutdown.
+func (l *Logger) Close() error {
+	_ = l.Sync()
+	err := closeSinks(l.sinks)
+	if l.async != nil {
+		l.async.Close()
+	}
+	return errors.Join(err, closeFiles(l.files))
+}
+
+// Rotate rotates every file output immediately, e.g. on SIGHUP
+func (l *Logger) Rotate() error {
+	var errs []error
+	for _, file := range l.files {
+		if err := file.Rotate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Dropped returns the number of entries dropped by the async writer and the
+// network sinks because their buffers were full or exports failed
+func (l *Logger) Dropped() int64 {
+	var dropped int64
+	if l.async != nil {
+		dropped += l.async.Dropped()
+	}
+	for _, sink := range l.sinks {
+		dropped += sink.batcher.Dropped()
+	}
+	return dropped
+}
+
+// closeSinks stops every sink and joins their errors
+func closeSinks(sinks []*sinkCore) error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.batcher.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // Global logger functions using default logger
 
+// Ctx returns the default logger with the trace, user and request IDs and
+// the fields of ctx, e.g. logger.Ctx(ctx).Infow("order created", "order_id", id)
+func Ctx(ctx context.Context) *Logger {
+	return NewDefault().WithContext(ctx)
+}
+
+// DebugCtx logs a debug message with the context fields
+func DebugCtx(ctx context.Context, args ...interface{}) {
+	Ctx(ctx).Debug(args...)
+}
+
+// InfoCtx logs an info message with the context fields
+func InfoCtx(ctx context.Context, args ...interface{}) {
+	Ctx(ctx).Info(args...)
+}
+
+// WarnCtx logs a warning message with the context fields
+func WarnCtx(ctx context.Context, args ...interface{}) {
+	Ctx(ctx).Warn(args...)
+}
+
+// ErrorCtx logs an error message with the context fields
+func ErrorCtx(ctx context.Context, args ...interface{}) {
+	Ctx(ctx).Error(args...)
+}
+
+// AddHook registers a hook on the default logger
+func AddHook(hook Hook) {
+	NewDefault().AddHook(hook)
+}
+
+// Named returns a child of the default logger for the given module
+func Named(name string) *Logger {
+	return NewDefault().Named(name)
+}
+
 // Debug logs a debug message
 func Debug(args ...interface{}) {
 	NewDefault().Debug(args...)
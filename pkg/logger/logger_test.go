@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"json", Config{Level: "info", Format: "json"}, false},
+		{"console", Config{Level: "debug", Format: "console"}, false},
+		{"dev", Config{Level: "warn", Format: "dev"}, false},
+		{"invalid level", Config{Level: "verbose"}, true},
+		{"invalid module level", Config{Level: "info", Modules: map[string]string{"db": "loud"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if log != nil {
+				log.Close()
+			}
+		})
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	previous := NewDefault()
+	defer SetDefault(previous)
+
+	custom, logs := newObservedLogger(t, "debug", nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			SetDefault(custom)
+			Debug("concurrent")
+		}()
+	}
+	wg.Wait()
+
+	if NewDefault() != custom {
+		t.Fatal("NewDefault() should return the installed logger")
+	}
+	if logs.Len() != 8 {
+		t.Errorf("entries = %d, want 8", logs.Len())
+	}
+
+	SetDefault(nil)
+	if NewDefault() != custom {
+		t.Error("SetDefault(nil) should be ignored")
+	}
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// EntryCounter receives one call per emitted log line, labelled by level and
+// logger name, e.g. to increment a Prometheus counter so alerts can fire on
+// error-rate spikes without parsing log text
+type EntryCounter interface {
+	IncLogEntry(level, logger string)
+}
+
+// EntryCounterFunc adapts a plain function to EntryCounter
+type EntryCounterFunc func(level, logger string)
+
+// IncLogEntry implements EntryCounter
+func (f EntryCounterFunc) IncLogEntry(level, logger string) {
+	f(level, logger)
+}
+
+// counterRegistry keeps per-level totals and the optional external counter
+type counterRegistry struct {
+	levels  [zapcore.FatalLevel - zapcore.DebugLevel + 1]atomic.Int64
+	counter atomic.Pointer[EntryCounter]
+}
+
+// inc records one entry
+func (r *counterRegistry) inc(entry zapcore.Entry) {
+	if entry.Level >= zapcore.DebugLevel && entry.Level <= zapcore.FatalLevel {
+		r.levels[entry.Level-zapcore.DebugLevel].Add(1)
+	}
+	if c := r.counter.Load(); c != nil {
+		(*c).IncLogEntry(entry.Level.String(), entry.LoggerName)
+	}
+}
+
+// counts returns the per-level totals
+func (r *counterRegistry) counts() map[string]int64 {
+	counts := make(map[string]int64, len(r.levels))
+	for i := range r.levels {
+		level := zapcore.DebugLevel + zapcore.Level(i)
+		counts[level.String()] = r.levels[i].Load()
+	}
+	return counts
+}
+
+// metricsCore counts every entry that passes level filtering
+type metricsCore struct {
+	counters *counterRegistry
+}
+
+// Enabled implements zapcore.Core
+func (c *metricsCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With implements zapcore.Core
+func (c *metricsCore) With([]zapcore.Field) zapcore.Core {
+	return c
+}
+
+// Check implements zapcore.Core
+func (c *metricsCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+
+// Write implements zapcore.Core
+func (c *metricsCore) Write(entry zapcore.Entry, _ []zapcore.Field) error {
+	c.counters.inc(entry)
+	return nil
+}
+
+// Sync implements zapcore.Core
+func (c *metricsCore) Sync() error {
+	return nil
+}
+
+// SetEntryCounter installs the counter notified for every log line of this
+// logger and every logger derived from the same root. Passing nil removes it.
+func (l *Logger) SetEntryCounter(counter EntryCounter) {
+	if l.counters == nil {
+		return
+	}
+	if counter == nil {
+		l.counters.counter.Store(nil)
+		return
+	}
+	l.counters.counter.Store(&counter)
+}
+
+// LevelCounts returns the number of lines emitted per level since creation
+func (l *Logger) LevelCounts() map[string]int64 {
+	if l.counters == nil {
+		return map[string]int64{}
+	}
+	return l.counters.counts()
+}
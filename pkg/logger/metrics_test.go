@@ -0,0 +1,37 @@
+package logger
+
+import "testing"
+
+func TestLevelCounts(t *testing.T) {
+	root, _ := newObservedLogger(t, "info", map[string]string{"db": "debug"})
+
+	type key struct{ level, logger string }
+	seen := make(map[key]int)
+	root.SetEntryCounter(EntryCounterFunc(func(level, logger string) {
+		seen[key{level, logger}]++
+	}))
+
+	root.Debug("filtered")
+	root.Info("one")
+	root.Error("two")
+	root.Named("db").Debug("three")
+	root.Named("db").Error("four")
+
+	counts := root.LevelCounts()
+	want := map[string]int64{"debug": 1, "info": 1, "warn": 0, "error": 2}
+	for level, n := range want {
+		if counts[level] != n {
+			t.Errorf("LevelCounts()[%s] = %d, want %d", level, counts[level], n)
+		}
+	}
+
+	if seen[key{"error", "db"}] != 1 || seen[key{"error", ""}] != 1 {
+		t.Errorf("unexpected counter calls: %v", seen)
+	}
+
+	root.SetEntryCounter(nil)
+	root.Info("after removal")
+	if len(seen) != 4 {
+		t.Errorf("counter should not be called after removal: %v", seen)
+	}
+}
@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelRegistry holds the root level and the per-module overrides shared by
+// a logger and all of its children
+type levelRegistry struct {
+	root    zap.AtomicLevel
+	mu      sync.RWMutex
+	modules map[string]zap.AtomicLevel
+}
+
+// newLevelRegistry creates a registry from the root level and module overrides
+func newLevelRegistry(root zapcore.Level, modules map[string]string) (*levelRegistry, error) {
+	r := &levelRegistry{
+		root:    zap.NewAtomicLevelAt(root),
+		modules: make(map[string]zap.AtomicLevel, len(modules)),
+	}
+
+	for name, text := range modules {
+		level, err := parseLevel(text)
+		if err != nil {
+			return nil, err
+		}
+		r.modules[name] = zap.NewAtomicLevelAt(level)
+	}
+
+	return r, nil
+}
+
+// lookup returns the override for a module, walking up dotted parent names
+// so that "cache.redis" falls back to "cache"
+func (r *levelRegistry) lookup(name string) (zap.AtomicLevel, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for name != "" {
+		if level, ok := r.modules[name]; ok {
+			return level, true
+		}
+		idx := strings.LastIndex(name, ".")
+		if idx < 0 {
+			break
+		}
+		name = name[:idx]
+	}
+	return zap.AtomicLevel{}, false
+}
+
+// set creates or updates the override for a module
+func (r *levelRegistry) set(name string, level zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.modules[name]; ok {
+		existing.SetLevel(level)
+		return
+	}
+	r.modules[name] = zap.NewAtomicLevelAt(level)
+}
+
+// snapshot returns the level name of every module override
+func (r *levelRegistry) snapshot() map[string]string {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	levels := make(map[string]string, len(r.modules))
+	for name, level := range r.modules {
+		levels[name] = level.Level().String()
+	}
+	return levels
+}
+
+// moduleEnabler enables levels according to a module override, falling back
+// to the root level when the module has none
+type moduleEnabler struct {
+	registry *levelRegistry
+	name     string
+}
+
+// Enabled implements zapcore.LevelEnabler
+func (m moduleEnabler) Enabled(level zapcore.Level) bool {
+	if override, ok := m.registry.lookup(m.name); ok {
+		return override.Enabled(level)
+	}
+	return m.registry.root.Enabled(level)
+}
+
+// levelCore filters entries with a swappable LevelEnabler
+type levelCore struct {
+	zapcore.Core
+	enabler zapcore.LevelEnabler
+}
+
+// Enabled implements zapcore.Core
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.enabler.Enabled(level)
+}
+
+// With implements zapcore.Core
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), enabler: c.enabler}
+}
+
+// Check implements zapcore.Core
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// Named returns a child logger for a module. Its level follows the module
+// override from Config.Modules or SetModuleLevel, and the root level otherwise.
+func (l *Logger) Named(name string) *Logger {
+	fullName := name
+	if l.name != "" {
+		fullName = l.name + "." + name
+	}
+
+	named := l.SugaredLogger.Named(name)
+	if l.levels != nil {
+		enabler := moduleEnabler{registry: l.levels, name: fullName}
+		named = named.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			if lc, ok := core.(*levelCore); ok {
+				return &levelCore{Core: lc.Core, enabler: enabler}
+			}
+			return core
+		}))
+	}
+
+	child := l.derive(named)
+	child.name = fullName
+	return child
+}
+
+// SetModuleLevel changes the level of a named logger at runtime
+func (l *Logger) SetModuleLevel(name, level string) error {
+	if l.levels == nil {
+		return nil
+	}
+
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	l.levels.set(name, lvl)
+	return nil
+}
+
+// ModuleLevel returns the effective level of a named logger
+func (l *Logger) ModuleLevel(name string) string {
+	if l.levels == nil {
+		return ""
+	}
+	if override, ok := l.levels.lookup(name); ok {
+		return override.Level().String()
+	}
+	return l.levels.root.Level().String()
+}
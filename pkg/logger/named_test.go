@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newObservedLogger builds a Logger writing to an in-memory core
+func newObservedLogger(t *testing.T, level string, modules map[string]string) (*Logger, *observer.ObservedLogs) {
+	t.Helper()
+
+	lvl, err := parseLevel(level)
+	if err != nil {
+		t.Fatalf("parseLevel() error = %v", err)
+	}
+	levels, err := newLevelRegistry(lvl, modules)
+	if err != nil {
+		t.Fatalf("newLevelRegistry() error = %v", err)
+	}
+
+	hooks := &hookRegistry{}
+	counters := &counterRegistry{}
+	core, logs := observer.New(zapcore.DebugLevel)
+	zapLogger := zap.New(&levelCore{
+		Core:    zapcore.NewTee(core, &hookCore{hooks: hooks}, &metricsCore{counters: counters}),
+		enabler: levels.root,
+	})
+	return &Logger{SugaredLogger: zapLogger.Sugar(), levels: levels, hooks: hooks, counters: counters}, logs
+}
+
+func TestNamedLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules map[string]string
+		module  string
+		level   zapcore.Level
+		want    bool
+	}{
+		{"inherits root level", nil, "cache", zapcore.DebugLevel, false},
+		{"module override lower", map[string]string{"cache": "debug"}, "cache", zapcore.DebugLevel, true},
+		{"module override higher", map[string]string{"db": "warn"}, "db", zapcore.InfoLevel, false},
+		{"other module unaffected", map[string]string{"db": "warn"}, "cache", zapcore.InfoLevel, true},
+		{"nested name falls back to parent", map[string]string{"cache": "debug"}, "cache.redis", zapcore.DebugLevel, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, logs := newObservedLogger(t, "info", tt.modules)
+
+			named := root
+			for _, part := range strings.Split(tt.module, ".") {
+				named = named.Named(part)
+			}
+
+			if ce := named.Desugar().Check(tt.level, "msg"); ce != nil {
+				ce.Write()
+			}
+
+			if got := logs.Len() == 1; got != tt.want {
+				t.Errorf("logged = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetModuleLevel(t *testing.T) {
+	root, logs := newObservedLogger(t, "info", nil)
+	cache := root.Named("cache")
+
+	cache.Debug("before")
+	if logs.Len() != 0 {
+		t.Fatalf("debug entry logged before override")
+	}
+
+	if err := root.SetModuleLevel("cache", "debug"); err != nil {
+		t.Fatalf("SetModuleLevel() error = %v", err)
+	}
+	cache.Debug("after")
+	if logs.Len() != 1 {
+		t.Errorf("debug entry not logged after override")
+	}
+
+	root.Debug("root")
+	if logs.Len() != 1 {
+		t.Errorf("root logger should keep info level")
+	}
+
+	if got := root.ModuleLevel("cache"); got != "debug" {
+		t.Errorf("ModuleLevel() = %v, want debug", got)
+	}
+
+	if err := root.SetModuleLevel("cache", "invalid"); err == nil {
+		t.Error("SetModuleLevel() should reject invalid level")
+	}
+}
+
+func TestNamedKeepsFields(t *testing.T) {
+	root, logs := newObservedLogger(t, "info", nil)
+
+	root.WithTraceID("trace-1").Named("db").Info("query")
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	if entries[0].LoggerName != "db" {
+		t.Errorf("LoggerName = %v, want db", entries[0].LoggerName)
+	}
+	if entries[0].ContextMap()["trace_id"] != "trace-1" {
+		t.Errorf("trace_id field missing: %v", entries[0].ContextMap())
+	}
+}
@@ -0,0 +1,271 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"mora/pkg/utils"
+)
+
+// DefaultRedactFields are the field names masked by default, in log entries
+// and in the bodies captured by BodyLogger
+var DefaultRedactFields = []string{"password", "token", "access_token", "refresh_token", "secret", "authorization"}
+
+// RedactConfig configures the masking of sensitive field values
+type RedactConfig struct {
+	// Fields are field names masked case-insensitively at any depth, e.g. in
+	// nested maps. Defaults to DefaultRedactFields; an empty list masks
+	// nothing.
+	Fields []string `json:"fields" yaml:"fields"`
+	// Patterns are regular expressions matched against field names, e.g.
+	// "(?i)_secret$"
+	Patterns []string `json:"patterns" yaml:"patterns"`
+}
+
+// redactRegistry holds the sensitive field names and patterns shared by a
+// logger and all of its children
+type redactRegistry struct {
+	mu       sync.RWMutex
+	fields   map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// newRedactRegistry creates a registry from the configuration
+func newRedactRegistry(cfg RedactConfig) (*redactRegistry, error) {
+	fields := cfg.Fields
+	if fields == nil {
+		fields = DefaultRedactFields
+	}
+
+	r := &redactRegistry{fields: make(map[string]bool, len(fields))}
+	r.addFields(fields...)
+	for _, pattern := range cfg.Patterns {
+		if err := r.addPattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// addFields registers sensitive field names
+func (r *redactRegistry) addFields(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		r.fields[strings.ToLower(name)] = true
+	}
+}
+
+// addPattern registers a sensitive field name pattern
+func (r *redactRegistry) addPattern(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.patterns = append(r.patterns, re)
+	return nil
+}
+
+// empty reports whether nothing is masked
+func (r *redactRegistry) empty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.fields) == 0 && len(r.patterns) == 0
+}
+
+// sensitive reports whether the values of a field name must be masked
+func (r *redactRegistry) sensitive(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.fields[strings.ToLower(name)] {
+		return true
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactFields returns fields with sensitive values masked; the input is
+// returned unchanged when there is nothing to mask
+func (r *redactRegistry) redactFields(fields []zapcore.Field) []zapcore.Field {
+	if len(fields) == 0 || r.empty() {
+		return fields
+	}
+
+	var out []zapcore.Field
+	for i, field := range fields {
+		redacted, changed := r.redactField(field)
+		if !changed {
+			if out != nil {
+				out = append(out, field)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, redacted)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// redactField masks a sensitive field or the sensitive keys of a nested map
+func (r *redactRegistry) redactField(field zapcore.Field) (zapcore.Field, bool) {
+	if r.sensitive(field.Key) {
+		return zap.String(field.Key, mask(fieldString(field))), true
+	}
+	if field.Type != zapcore.ReflectType {
+		return field, false
+	}
+	if value, changed := r.redactValue(field.Interface); changed {
+		return zap.Any(field.Key, value), true
+	}
+	return field, false
+}
+
+// redactValue walks maps and slices, returning a masked copy when a nested
+// key is sensitive so that the caller's values are never modified
+func (r *redactRegistry) redactValue(v interface{}) (interface{}, bool) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		var out map[string]interface{}
+		for k, inner := range val {
+			var masked interface{}
+			var changed bool
+			if r.sensitive(k) {
+				masked, changed = mask(fmt.Sprint(inner)), true
+			} else {
+				masked, changed = r.redactValue(inner)
+			}
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = make(map[string]interface{}, len(val))
+				for k2, v2 := range val {
+					out[k2] = v2
+				}
+			}
+			out[k] = masked
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	case map[string]string:
+		var out map[string]string
+		for k, inner := range val {
+			if !r.sensitive(k) {
+				continue
+			}
+			if out == nil {
+				out = make(map[string]string, len(val))
+				for k2, v2 := range val {
+					out[k2] = v2
+				}
+			}
+			out[k] = mask(inner)
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	case []interface{}:
+		var out []interface{}
+		for i, inner := range val {
+			masked, changed := r.redactValue(inner)
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = make([]interface{}, len(val))
+				copy(out, val)
+			}
+			out[i] = masked
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	default:
+		return v, false
+	}
+}
+
+// mask hides a value with utils.MaskSensitive, keeping values already
+// replaced by the body logger
+func mask(s string) string {
+	if s == RedactedValue {
+		return s
+	}
+	return utils.MaskSensitive(s)
+}
+
+// fieldString renders the value of a field as text
+func fieldString(field zapcore.Field) string {
+	if field.Type == zapcore.StringType {
+		return field.String
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	field.AddTo(enc)
+	return fmt.Sprint(enc.Fields[field.Key])
+}
+
+// redactCore masks sensitive fields, both those added with WithFields and
+// those passed at the call site, before they reach the wrapped core. It
+// wraps each output, hook and sink core, whose Check only tests Enabled.
+type redactCore struct {
+	zapcore.Core
+	redact *redactRegistry
+}
+
+// With implements zapcore.Core
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redact.redactFields(fields)), redact: c.redact}
+}
+
+// Check implements zapcore.Core
+func (c *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write implements zapcore.Core
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, c.redact.redactFields(fields))
+}
+
+// AddRedactFields masks the given field names in entries of this logger and
+// every logger derived from the same root
+func (l *Logger) AddRedactFields(names ...string) {
+	if l.redact == nil {
+		return
+	}
+	l.redact.addFields(names...)
+}
+
+// AddRedactPattern masks the fields whose names match a regular expression
+func (l *Logger) AddRedactPattern(pattern string) error {
+	if l.redact == nil {
+		return nil
+	}
+	return l.redact.addPattern(pattern)
+}
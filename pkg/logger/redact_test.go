@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newRedactedLogger builds a zap logger masking fields before an in-memory core
+func newRedactedLogger(t *testing.T, cfg RedactConfig) (*zap.Logger, *observer.ObservedLogs) {
+	t.Helper()
+
+	redact, err := newRedactRegistry(cfg)
+	if err != nil {
+		t.Fatalf("newRedactRegistry() error = %v", err)
+	}
+	core, logs := observer.New(zapcore.DebugLevel)
+	return zap.New(&redactCore{Core: core, redact: redact}), logs
+}
+
+func TestRedactFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    RedactConfig
+		fields []zapcore.Field
+		want   map[string]interface{}
+	}{
+		{
+			name:   "default names",
+			fields: []zapcore.Field{zap.String("password", "hunter2"), zap.String("Authorization", "Bearer abcdefgh1234")},
+			want:   map[string]interface{}{"password": "*******", "Authorization": "Bear***********1234"},
+		},
+		{
+			name:   "non string value",
+			cfg:    RedactConfig{Fields: []string{"pin"}},
+			fields: []zapcore.Field{zap.Int("pin", 1234), zap.Int("count", 2)},
+			want:   map[string]interface{}{"pin": "****", "count": int64(2)},
+		},
+		{
+			name:   "pattern",
+			cfg:    RedactConfig{Fields: []string{}, Patterns: []string{`(?i)_key$`}},
+			fields: []zapcore.Field{zap.String("api_key", "k-0123456789"), zap.String("token", "kept")},
+			want:   map[string]interface{}{"api_key": "k-01****6789", "token": "kept"},
+		},
+		{
+			name: "nested maps",
+			fields: []zapcore.Field{zap.Any("user", map[string]interface{}{
+				"name": "ann",
+				"auth": map[string]interface{}{"token": "0123456789abcdef"},
+				"devices": []interface{}{
+					map[string]interface{}{"secret": "s"},
+				},
+			})},
+			want: map[string]interface{}{"user": map[string]interface{}{
+				"name": "ann",
+				"auth": map[string]interface{}{"token": "0123********cdef"},
+				"devices": []interface{}{
+					map[string]interface{}{"secret": "*"},
+				},
+			}},
+		},
+		{
+			name:   "string map",
+			fields: []zapcore.Field{zap.Any("headers", map[string]string{"Authorization": "short", "Accept": "*/*"})},
+			want:   map[string]interface{}{"headers": map[string]string{"Authorization": "*****", "Accept": "*/*"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, logs := newRedactedLogger(t, tt.cfg)
+			log.Info("call site", tt.fields...)
+			log.With(tt.fields...).Info("with")
+
+			for _, entry := range logs.All() {
+				got, _ := json.Marshal(entry.ContextMap())
+				want, _ := json.Marshal(tt.want)
+				if string(got) != string(want) {
+					t.Errorf("%s: fields = %s, want %s", entry.Message, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactKeepsCallerValues(t *testing.T) {
+	log, _ := newRedactedLogger(t, RedactConfig{})
+	nested := map[string]interface{}{"token": "0123456789abcdef"}
+	user := map[string]interface{}{"auth": nested}
+
+	log.Info("login", zap.Any("user", user))
+
+	if nested["token"] != "0123456789abcdef" {
+		t.Errorf("caller map was modified: %v", nested)
+	}
+}
+
+func TestLoggerRedactsOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{Level: "info", Format: "json", Outputs: []string{path}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	l.AddRedactFields("card_number")
+	if err := l.AddRedactPattern("("); err == nil {
+		t.Error("AddRedactPattern() with an invalid pattern should fail")
+	}
+
+	l.WithFields(String("card_number", "4111111111111111")).Infow("paid", "password", "hunter22")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, secret := range []string{"4111111111111111", "hunter22"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("log output contains %q: %s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), `"card_number":"4111********1111"`) {
+		t.Errorf("log output = %s, want masked card number", data)
+	}
+}
+
+func TestInvalidRedactConfig(t *testing.T) {
+	if _, err := New(Config{Level: "info", Redact: RedactConfig{Patterns: []string{"["}}}); err == nil {
+		t.Error("New() with an invalid redact pattern should fail")
+	}
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"net/http"
+
+	"mora/pkg/utils"
+)
+
+const (
+	// RequestIDHeader carries the request ID between services
+	RequestIDHeader = "X-Request-ID"
+
+	// maxRequestIDLength bounds the caller's request ID so that it cannot
+	// flood the logs
+	maxRequestIDLength = 128
+)
+
+// RequestID returns the request ID sent by the caller in RequestIDHeader,
+// or a new ULID when it is missing or not printable ASCII. The adapters'
+// RequestID middleware stores it with WithRequestID and echoes it back.
+func RequestID(r *http.Request) string {
+	if id := r.Header.Get(RequestIDHeader); validRequestID(id) {
+		return id
+	}
+	id, err := utils.GenerateULID()
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+// validRequestID reports whether a caller supplied ID can be logged as is
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
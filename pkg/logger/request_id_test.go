@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mora/pkg/utils"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantKeep bool
+	}{
+		{name: "caller id", header: "req-123", wantKeep: true},
+		{name: "missing", header: ""},
+		{name: "not printable", header: "req 123"},
+		{name: "too long", header: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				r.Header.Set(RequestIDHeader, tt.header)
+			}
+			got := RequestID(r)
+			if tt.wantKeep {
+				if got != tt.header {
+					t.Errorf("RequestID() = %q, want %q", got, tt.header)
+				}
+				return
+			}
+			if _, err := utils.ULIDTime(got); err != nil {
+				t.Errorf("RequestID() = %q, want a generated ULID: %v", got, err)
+			}
+		})
+	}
+}
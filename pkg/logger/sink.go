@@ -0,0 +1,301 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// Default sink settings
+	DefaultSinkBatchSize     = 100
+	DefaultSinkFlushInterval = time.Second
+	DefaultSinkMaxRetries    = 3
+	DefaultSinkTimeout       = 5 * time.Second
+	DefaultSinkRetryDelay    = 200 * time.Millisecond
+)
+
+// SinkConfig configures a network sink that ships log entries in batches
+type SinkConfig struct {
+	Type     string            `json:"type" yaml:"type"`         // otlp, loki, kafka
+	Endpoint string            `json:"endpoint" yaml:"endpoint"` // e.g. http://collector:4318/v1/logs, http://loki:3100/loki/api/v1/push
+	Headers  map[string]string `json:"headers" yaml:"headers"`   // extra request headers, e.g. auth tokens
+	Level    string            `json:"level" yaml:"level"`       // minimum level shipped, defaults to every level the logger emits
+
+	// Labels maps entry fields to Loki labels (field name -> label name)
+	Labels map[string]string `json:"labels" yaml:"labels"`
+	// StaticLabels are attached to every Loki stream, e.g. {"app": "order-api"}
+	StaticLabels map[string]string `json:"static_labels" yaml:"static_labels"`
+	// Resource holds OTLP resource attributes, e.g. {"service.name": "order-api"}
+	Resource map[string]string `json:"resource" yaml:"resource"`
+
+	// Brokers and Topic select the Kafka destination
+	Brokers []string `json:"brokers" yaml:"brokers"`
+	Topic   string   `json:"topic" yaml:"topic"`
+	// KeyField is the entry field used as Kafka message key, defaults to trace_id
+	KeyField string `json:"key_field" yaml:"key_field"`
+	// Service is added to Kafka messages and used as key when KeyField is absent
+	Service string `json:"service" yaml:"service"`
+
+	BatchSize     int           `json:"batch_size" yaml:"batch_size"`
+	FlushInterval time.Duration `json:"flush_interval" yaml:"flush_interval"`
+	MaxRetries    int           `json:"max_retries" yaml:"max_retries"`
+	Timeout       time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+// record is a buffered log entry waiting to be exported
+type record struct {
+	entry  zapcore.Entry
+	fields map[string]interface{}
+}
+
+// exporter ships a batch of records to a remote backend
+type exporter interface {
+	export(ctx context.Context, records []record) error
+}
+
+// newExporter creates the exporter for a sink type
+func newExporter(cfg SinkConfig, client *http.Client) (exporter, error) {
+	if cfg.Type == "kafka" {
+		return newKafkaExporter(cfg)
+	}
+
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("sink %s: endpoint is required", cfg.Type)
+	}
+
+	switch cfg.Type {
+	case "otlp":
+		return &otlpExporter{cfg: cfg, client: client}, nil
+	case "loki":
+		return &lokiExporter{cfg: cfg, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported sink type: %s", cfg.Type)
+	}
+}
+
+// batcher buffers records and exports them in the background
+type batcher struct {
+	exporter exporter
+	cfg      SinkConfig
+
+	mu      sync.Mutex
+	buf     []record
+	flushMu sync.Mutex
+
+	flushCh   chan struct{}
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	dropped   atomic.Int64
+}
+
+// newBatcher starts a batcher for the given exporter
+func newBatcher(exp exporter, cfg SinkConfig) *batcher {
+	b := &batcher{
+		exporter: exp,
+		cfg:      cfg,
+		flushCh:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	b.wg.Add(1)
+	go b.run()
+	return b
+}
+
+// run flushes on every interval tick and whenever a batch fills up
+func (b *batcher) run() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+		case <-b.flushCh:
+		}
+		b.flush()
+	}
+}
+
+// add buffers a record, dropping the oldest entries when the backend falls
+// too far behind
+func (b *batcher) add(r record) {
+	b.mu.Lock()
+	if limit := b.cfg.BatchSize * 10; len(b.buf) >= limit {
+		drop := len(b.buf) - limit + 1
+		b.buf = b.buf[drop:]
+		b.dropped.Add(int64(drop))
+	}
+	b.buf = append(b.buf, r)
+	full := len(b.buf) >= b.cfg.BatchSize
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// flush exports everything buffered so far
+func (b *batcher) flush() error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	for {
+		b.mu.Lock()
+		n := len(b.buf)
+		if n > b.cfg.BatchSize {
+			n = b.cfg.BatchSize
+		}
+		batch := b.buf[:n:n]
+		b.buf = b.buf[n:]
+		b.mu.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := b.exportWithRetry(batch); err != nil {
+			b.dropped.Add(int64(len(batch)))
+			fmt.Fprintf(os.Stderr, "logger: %s sink dropped %d entries: %v\n", b.cfg.Type, len(batch), err)
+			return err
+		}
+	}
+}
+
+// exportWithRetry exports a batch with exponential backoff
+func (b *batcher) exportWithRetry(batch []record) error {
+	delay := DefaultSinkRetryDelay
+
+	var err error
+	for attempt := 0; attempt <= b.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), b.cfg.Timeout)
+		err = b.exporter.export(ctx, batch)
+		cancel()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// Close stops the background goroutine and flushes the remaining records
+func (b *batcher) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.done)
+		b.wg.Wait()
+		err = b.flush()
+		if closer, ok := b.exporter.(io.Closer); ok {
+			err = errors.Join(err, closer.Close())
+		}
+	})
+	return err
+}
+
+// Dropped returns the number of entries dropped because of export failures
+// or a full buffer
+func (b *batcher) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// sinkCore is a zapcore.Core that feeds a batcher
+type sinkCore struct {
+	batcher *batcher
+	level   zapcore.LevelEnabler
+	fields  []zapcore.Field
+}
+
+// newSinkCore creates a sink core and its batcher from configuration
+func newSinkCore(cfg SinkConfig) (*sinkCore, error) {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultSinkBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultSinkFlushInterval
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultSinkMaxRetries
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultSinkTimeout
+	}
+
+	var level zapcore.LevelEnabler = zapcore.DebugLevel
+	if cfg.Level != "" {
+		lvl, err := parseLevel(cfg.Level)
+		if err != nil {
+			return nil, err
+		}
+		level = lvl
+	}
+
+	exp, err := newExporter(cfg, &http.Client{Timeout: cfg.Timeout})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sinkCore{batcher: newBatcher(exp, cfg), level: level}, nil
+}
+
+// Enabled implements zapcore.Core
+func (c *sinkCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+// With implements zapcore.Core
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	combined := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	combined = append(combined, c.fields...)
+	combined = append(combined, fields...)
+	return &sinkCore{batcher: c.batcher, level: c.level, fields: combined}
+}
+
+// Check implements zapcore.Core
+func (c *sinkCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write implements zapcore.Core
+func (c *sinkCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(enc)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	c.batcher.add(record{entry: entry, fields: enc.Fields})
+	return nil
+}
+
+// Sync implements zapcore.Core by flushing buffered entries
+func (c *sinkCore) Sync() error {
+	return c.batcher.flush()
+}
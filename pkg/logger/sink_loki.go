@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// lokiExporter ships records to the Loki push API, turning mapped fields
+// into stream labels
+type lokiExporter struct {
+	cfg    SinkConfig
+	client *http.Client
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiRequest struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+// export implements exporter
+func (e *lokiExporter) export(ctx context.Context, records []record) error {
+	streams := make(map[string]*lokiStream)
+	order := make([]string, 0)
+
+	for _, r := range records {
+		labels := e.labels(r)
+		key := labelKey(labels)
+
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			streams[key] = stream
+			order = append(order, key)
+		}
+
+		line, err := lokiLine(r)
+		if err != nil {
+			return err
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(r.entry.Time.UnixNano(), 10), line})
+	}
+
+	req := lokiRequest{Streams: make([]*lokiStream, 0, len(order))}
+	for _, key := range order {
+		req.Streams = append(req.Streams, streams[key])
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode loki streams: %w", err)
+	}
+
+	return postJSON(ctx, e.client, e.cfg.Endpoint, e.cfg.Headers, body)
+}
+
+// labels builds the stream labels of a record
+func (e *lokiExporter) labels(r record) map[string]string {
+	labels := make(map[string]string, len(e.cfg.StaticLabels)+len(e.cfg.Labels)+1)
+	for k, v := range e.cfg.StaticLabels {
+		labels[k] = v
+	}
+	labels["level"] = r.entry.Level.String()
+
+	for field, label := range e.cfg.Labels {
+		if v, ok := r.fields[field]; ok {
+			labels[label] = fmt.Sprint(v)
+		}
+	}
+	return labels
+}
+
+// lokiLine renders a record as a JSON log line
+func lokiLine(r record) (string, error) {
+	line := make(map[string]interface{}, len(r.fields)+4)
+	for k, v := range r.fields {
+		line[k] = v
+	}
+	line["level"] = r.entry.Level.String()
+	line["msg"] = r.entry.Message
+	if r.entry.LoggerName != "" {
+		line["logger"] = r.entry.LoggerName
+	}
+	if r.entry.Caller.Defined {
+		line["caller"] = r.entry.Caller.TrimmedPath()
+	}
+
+	data, err := json.Marshal(line)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode loki line: %w", err)
+	}
+	return string(data), nil
+}
+
+// labelKey builds a stable identity for a label set
+func labelKey(labels map[string]string) string {
+	var b strings.Builder
+	for _, k := range sortedKeys(labels) {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(labels[k])
+		b.WriteByte(',')
+	}
+	return b.String()
+}
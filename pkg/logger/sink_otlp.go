@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// otlpExporter ships records using the OTLP/HTTP logs protocol with JSON encoding
+type otlpExporter struct {
+	cfg    SinkConfig
+	client *http.Client
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string         `json:"timeUnixNano"`
+	SeverityNumber int            `json:"severityNumber"`
+	SeverityText   string         `json:"severityText"`
+	Body           otlpValue      `json:"body"`
+	Attributes     []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeLogs struct {
+	Scope      map[string]string `json:"scope"`
+	LogRecords []otlpLogRecord   `json:"logRecords"`
+}
+
+type otlpResourceLogs struct {
+	Resource  map[string][]otlpKeyValue `json:"resource"`
+	ScopeLogs []otlpScopeLogs           `json:"scopeLogs"`
+}
+
+type otlpRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+// export implements exporter
+func (e *otlpExporter) export(ctx context.Context, records []record) error {
+	logs := make([]otlpLogRecord, 0, len(records))
+	for _, r := range records {
+		attrs := make([]otlpKeyValue, 0, len(r.fields)+2)
+		if r.entry.LoggerName != "" {
+			attrs = append(attrs, otlpKeyValue{Key: "logger", Value: toOTLPValue(r.entry.LoggerName)})
+		}
+		if r.entry.Caller.Defined {
+			attrs = append(attrs, otlpKeyValue{Key: "caller", Value: toOTLPValue(r.entry.Caller.TrimmedPath())})
+		}
+		for _, key := range sortedKeys(r.fields) {
+			attrs = append(attrs, otlpKeyValue{Key: key, Value: toOTLPValue(r.fields[key])})
+		}
+
+		logs = append(logs, otlpLogRecord{
+			TimeUnixNano:   strconv.FormatInt(r.entry.Time.UnixNano(), 10),
+			SeverityNumber: otlpSeverity(r.entry.Level),
+			SeverityText:   r.entry.Level.CapitalString(),
+			Body:           toOTLPValue(r.entry.Message),
+			Attributes:     attrs,
+		})
+	}
+
+	resource := make([]otlpKeyValue, 0, len(e.cfg.Resource))
+	for _, key := range sortedKeys(e.cfg.Resource) {
+		resource = append(resource, otlpKeyValue{Key: key, Value: toOTLPValue(e.cfg.Resource[key])})
+	}
+
+	body, err := json.Marshal(otlpRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: map[string][]otlpKeyValue{"attributes": resource},
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      map[string]string{"name": "mora/pkg/logger"},
+				LogRecords: logs,
+			}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode otlp logs: %w", err)
+	}
+
+	return postJSON(ctx, e.client, e.cfg.Endpoint, e.cfg.Headers, body)
+}
+
+// otlpSeverity maps zap levels to OTLP severity numbers
+func otlpSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 5
+	case zapcore.InfoLevel:
+		return 9
+	case zapcore.WarnLevel:
+		return 13
+	case zapcore.ErrorLevel:
+		return 17
+	default:
+		return 21
+	}
+}
+
+// toOTLPValue converts a field value into an OTLP AnyValue
+func toOTLPValue(v interface{}) otlpValue {
+	switch val := v.(type) {
+	case string:
+		return otlpValue{StringValue: &val}
+	case bool:
+		return otlpValue{BoolValue: &val}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		s := fmt.Sprint(val)
+		return otlpValue{IntValue: &s}
+	case float32:
+		f := float64(val)
+		return otlpValue{DoubleValue: &f}
+	case float64:
+		return otlpValue{DoubleValue: &val}
+	default:
+		data, err := json.Marshal(val)
+		s := string(data)
+		if err != nil {
+			s = fmt.Sprint(val)
+		}
+		return otlpValue{StringValue: &s}
+	}
+}
+
+// postJSON sends a JSON body and treats any non-2xx response as an error
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// sortedKeys returns map keys in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
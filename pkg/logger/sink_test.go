@@ -0,0 +1,215 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap/zapcore"
+)
+
+// captureServer records request bodies and fails the first n requests
+func captureServer(t *testing.T, failures int32) (*httptest.Server, func() [][]byte) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var bodies [][]byte
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() [][]byte {
+		mu.Lock()
+		defer mu.Unlock()
+		return bodies
+	}
+}
+
+func TestLokiSink(t *testing.T) {
+	srv, bodies := captureServer(t, 1)
+
+	log, err := New(Config{
+		Level:  "info",
+		Format: "json",
+		Sinks: []SinkConfig{{
+			Type:          "loki",
+			Endpoint:      srv.URL,
+			Labels:        map[string]string{"tenant_id": "tenant"},
+			StaticLabels:  map[string]string{"app": "order-api"},
+			FlushInterval: time.Hour,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	log.WithFields(String("tenant_id", "t1")).Info("created")
+	log.Warn("slow")
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got := bodies()
+	if len(got) != 1 {
+		t.Fatalf("requests = %d, want 1", len(got))
+	}
+
+	var req lokiRequest
+	if err := json.Unmarshal(got[0], &req); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if len(req.Streams) != 2 {
+		t.Fatalf("streams = %d, want 2", len(req.Streams))
+	}
+
+	first := req.Streams[0]
+	if first.Stream["tenant"] != "t1" || first.Stream["app"] != "order-api" || first.Stream["level"] != "info" {
+		t.Errorf("unexpected labels: %v", first.Stream)
+	}
+	if len(first.Values) != 1 {
+		t.Fatalf("values = %d, want 1", len(first.Values))
+	}
+
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(first.Values[0][1]), &line); err != nil {
+		t.Fatalf("invalid line: %v", err)
+	}
+	if line["msg"] != "created" {
+		t.Errorf("msg = %v, want created", line["msg"])
+	}
+}
+
+func TestOTLPSink(t *testing.T) {
+	srv, bodies := captureServer(t, 0)
+
+	log, err := New(Config{
+		Level:  "debug",
+		Format: "json",
+		Sinks: []SinkConfig{{
+			Type:          "otlp",
+			Endpoint:      srv.URL,
+			Level:         "warn",
+			Resource:      map[string]string{"service.name": "order-api"},
+			FlushInterval: time.Hour,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	log.Info("not shipped")
+	log.Errorw("payment failed", "order_id", "o-1", "attempt", 2)
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got := bodies()
+	if len(got) != 1 {
+		t.Fatalf("requests = %d, want 1", len(got))
+	}
+
+	var req otlpRequest
+	if err := json.Unmarshal(got[0], &req); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+
+	records := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+	if len(records) != 1 {
+		t.Fatalf("records = %d, want 1", len(records))
+	}
+	if records[0].SeverityText != "ERROR" || records[0].SeverityNumber != 17 {
+		t.Errorf("unexpected severity: %s/%d", records[0].SeverityText, records[0].SeverityNumber)
+	}
+	if *records[0].Body.StringValue != "payment failed" {
+		t.Errorf("body = %v, want payment failed", *records[0].Body.StringValue)
+	}
+
+	attrs := make(map[string]otlpValue)
+	for _, kv := range records[0].Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if v := attrs["order_id"].StringValue; v == nil || *v != "o-1" {
+		t.Errorf("order_id attribute missing: %+v", records[0].Attributes)
+	}
+	if v := attrs["attempt"].IntValue; v == nil || *v != "2" {
+		t.Errorf("attempt attribute missing: %+v", records[0].Attributes)
+	}
+}
+
+func TestNewSinkCoreErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  SinkConfig
+	}{
+		{"missing endpoint", SinkConfig{Type: "loki"}},
+		{"unknown type", SinkConfig{Type: "syslog", Endpoint: "http://localhost"}},
+		{"invalid level", SinkConfig{Type: "otlp", Endpoint: "http://localhost", Level: "loud"}},
+		{"kafka without topic", SinkConfig{Type: "kafka", Brokers: []string{"localhost:9092"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newSinkCore(tt.cfg); err == nil {
+				t.Error("newSinkCore() should return an error")
+			}
+		})
+	}
+}
+
+// fakeKafkaWriter records published messages
+type fakeKafkaWriter struct {
+	msgs []kafka.Message
+}
+
+func (w *fakeKafkaWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *fakeKafkaWriter) Close() error { return nil }
+
+func TestKafkaExporter(t *testing.T) {
+	writer := &fakeKafkaWriter{}
+	exp := &kafkaExporter{cfg: SinkConfig{Service: "order-api"}, writer: writer}
+
+	records := []record{
+		{entry: zapcore.Entry{Level: zapcore.InfoLevel, Message: "a"}, fields: map[string]interface{}{"trace_id": "t-1"}},
+		{entry: zapcore.Entry{Level: zapcore.ErrorLevel, Message: "b"}, fields: map[string]interface{}{}},
+	}
+	if err := exp.export(context.Background(), records); err != nil {
+		t.Fatalf("export() error = %v", err)
+	}
+
+	if len(writer.msgs) != 2 {
+		t.Fatalf("messages = %d, want 2", len(writer.msgs))
+	}
+	if string(writer.msgs[0].Key) != "t-1" || string(writer.msgs[1].Key) != "order-api" {
+		t.Errorf("unexpected keys: %q, %q", writer.msgs[0].Key, writer.msgs[1].Key)
+	}
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(writer.msgs[1].Value, &line); err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	if line["msg"] != "b" || line["level"] != "error" || line["service"] != "order-api" {
+		t.Errorf("unexpected message: %v", line)
+	}
+}
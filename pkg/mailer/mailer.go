@@ -0,0 +1,296 @@
+// Package mailer sends email: messages with HTML/text bodies and
+// attachments, an SMTP sender with connection pooling, templates with
+// layouts, and an async queue with retries for flows such as email
+// verification and password reset.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidMessage is returned for messages that can never be sent, such
+// as ones without recipients; the queue does not retry them
+var ErrInvalidMessage = errors.New("mailer: invalid message")
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// SenderFunc adapts a function to Sender
+type SenderFunc func(ctx context.Context, msg *Message) error
+
+// Send calls f
+func (f SenderFunc) Send(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// Message is an email
+type Message struct {
+	// From defaults to the sender's configured address
+	From    string
+	To      []string
+	Cc      []string
+	Bcc     []string
+	ReplyTo string
+	Subject string
+	// Text and HTML are the bodies; with both, clients pick the one they render
+	Text string
+	HTML string
+	// Attachments are sent as files, or referenced from HTML as cid:<ContentID> when inline
+	Attachments []Attachment
+	// Headers are extra headers, e.g. {"List-Unsubscribe": "<https://...>"}
+	Headers map[string]string
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename string
+	// ContentType defaults to the type of the filename's extension
+	ContentType string
+	Data        []byte
+	// Inline attachments are shown in the HTML body instead of listed as files
+	Inline    bool
+	ContentID string
+}
+
+// Attach adds a file attachment
+func (m *Message) Attach(filename string, data []byte) *Message {
+	m.Attachments = append(m.Attachments, Attachment{Filename: filename, Data: data})
+	return m
+}
+
+// Embed adds an inline attachment, referenced from HTML as cid:<contentID>
+func (m *Message) Embed(filename, contentID string, data []byte) *Message {
+	m.Attachments = append(m.Attachments, Attachment{Filename: filename, Data: data, Inline: true, ContentID: contentID})
+	return m
+}
+
+// Recipients returns every envelope recipient address, Bcc included
+func (m *Message) Recipients() ([]string, error) {
+	var rcpts []string
+	for _, list := range [][]string{m.To, m.Cc, m.Bcc} {
+		for _, a := range list {
+			addr, err := mail.ParseAddress(a)
+			if err != nil {
+				return nil, fmt.Errorf("%w: recipient %q: %v", ErrInvalidMessage, a, err)
+			}
+			rcpts = append(rcpts, addr.Address)
+		}
+	}
+	if len(rcpts) == 0 {
+		return nil, fmt.Errorf("%w: no recipients", ErrInvalidMessage)
+	}
+	return rcpts, nil
+}
+
+// mimePart is a MIME entity
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// Bytes encodes the message in RFC 5322 form; Bcc is left out of the headers
+func (m *Message) Bytes() ([]byte, error) {
+	if m.From == "" {
+		return nil, fmt.Errorf("%w: no sender", ErrInvalidMessage)
+	}
+	if m.Text == "" && m.HTML == "" {
+		return nil, fmt.Errorf("%w: no body", ErrInvalidMessage)
+	}
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sender %q: %v", ErrInvalidMessage, m.From, err)
+	}
+
+	root := m.rootPart()
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", from.String())
+	if len(m.To) > 0 {
+		writeHeader(&buf, "To", formatAddressList(m.To))
+	}
+	if len(m.Cc) > 0 {
+		writeHeader(&buf, "Cc", formatAddressList(m.Cc))
+	}
+	if m.ReplyTo != "" {
+		writeHeader(&buf, "Reply-To", formatAddressList([]string{m.ReplyTo}))
+	}
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID(from.Address))
+	writeHeader(&buf, "MIME-Version", "1.0")
+	keys := make([]string, 0, len(m.Headers))
+	for k := range m.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeHeader(&buf, k, m.Headers[k])
+	}
+	writeMIMEHeader(&buf, root.header)
+	buf.WriteString("\r\n")
+	buf.Write(root.body)
+	return buf.Bytes(), nil
+}
+
+// rootPart nests the bodies and attachments: mixed(related(alternative))
+func (m *Message) rootPart() mimePart {
+	var bodies []mimePart
+	if m.Text != "" {
+		bodies = append(bodies, textPart("text/plain", m.Text))
+	}
+	if m.HTML != "" {
+		bodies = append(bodies, textPart("text/html", m.HTML))
+	}
+	body := bodies[0]
+	if len(bodies) > 1 {
+		body = multipartOf("alternative", bodies)
+	}
+
+	var inline, attached []mimePart
+	for _, a := range m.Attachments {
+		if a.Inline {
+			inline = append(inline, attachmentPart(a))
+		} else {
+			attached = append(attached, attachmentPart(a))
+		}
+	}
+	if len(inline) > 0 {
+		body = multipartOf("related", append([]mimePart{body}, inline...))
+	}
+	if len(attached) > 0 {
+		body = multipartOf("mixed", append([]mimePart{body}, attached...))
+	}
+	return body
+}
+
+// textPart encodes a body as quoted-printable UTF-8
+func textPart(contentType, s string) mimePart {
+	var buf bytes.Buffer
+	w := quotedprintable.NewWriter(&buf)
+	w.Write([]byte(s))
+	w.Close()
+	return mimePart{
+		header: textproto.MIMEHeader{
+			"Content-Type":              {contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		},
+		body: buf.Bytes(),
+	}
+}
+
+// attachmentPart encodes an attachment as base64
+func attachmentPart(a Attachment) mimePart {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(a.Filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	disposition := "attachment"
+	if a.Inline {
+		disposition = "inline"
+	}
+
+	header := textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": a.Filename})},
+		"Content-Disposition":       {mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename})},
+		"Content-Transfer-Encoding": {"base64"},
+	}
+	if a.ContentID != "" {
+		header.Set("Content-ID", "<"+a.ContentID+">")
+	}
+	return mimePart{header: header, body: wrapBase64(a.Data)}
+}
+
+// multipartOf combines parts into a multipart entity of subtype
+func multipartOf(subtype string, parts []mimePart) mimePart {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, p := range parts {
+		pw, _ := w.CreatePart(p.header)
+		pw.Write(p.body)
+	}
+	w.Close()
+	return mimePart{
+		header: textproto.MIMEHeader{"Content-Type": {"multipart/" + subtype + "; boundary=" + w.Boundary()}},
+		body:   buf.Bytes(),
+	}
+}
+
+// wrapBase64 encodes data as base64 in lines of 76 characters
+func wrapBase64(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var buf bytes.Buffer
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76])
+		buf.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded)
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
+
+// writeHeader writes one header line
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	// Strip line breaks so values cannot inject headers
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	buf.WriteString(key + ": " + value + "\r\n")
+}
+
+// writeMIMEHeader writes the headers of a MIME entity in a stable order
+func writeMIMEHeader(buf *bytes.Buffer, h textproto.MIMEHeader) {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			writeHeader(buf, k, v)
+		}
+	}
+}
+
+// formatAddressList formats addresses, encoding non-ASCII display names;
+// invalid addresses are kept as given and rejected by Recipients
+func formatAddressList(list []string) string {
+	formatted := make([]string, len(list))
+	for i, a := range list {
+		if addr, err := mail.ParseAddress(a); err == nil {
+			formatted[i] = addr.String()
+		} else {
+			formatted[i] = a
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// messageID generates a unique Message-ID in the sender's domain
+func messageID(from string) string {
+	domain := "localhost"
+	if _, d, ok := strings.Cut(from, "@"); ok {
+		domain = d
+	}
+	b := make([]byte, 12)
+	rand.Read(b)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), domain)
+}
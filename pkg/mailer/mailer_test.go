@@ -0,0 +1,136 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestMessageBytes(t *testing.T) {
+	msg := &Message{
+		From:    "Mora <noreply@example.com>",
+		To:      []string{"Alice <alice@example.com>"},
+		Cc:      []string{"bob@example.com"},
+		Bcc:     []string{"audit@example.com"},
+		Subject: "验证你的邮箱",
+		Text:    "Your code is 123456",
+		HTML:    `<p>Your code is <b>123456</b></p><img src="cid:logo">`,
+		Headers: map[string]string{"X-Campaign": "verify\r\nBcc: evil@example.com"},
+	}
+	msg.Embed("logo.png", "logo", []byte("png-bytes"))
+	msg.Attach("terms.pdf", bytes.Repeat([]byte("pdf"), 100))
+
+	data, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != msg.Subject {
+		t.Errorf("Subject = %q, want %q", subject, msg.Subject)
+	}
+	if parsed.Header.Get("Bcc") != "" {
+		t.Error("Bcc must not be written to the headers")
+	}
+	if got := parsed.Header.Get("X-Campaign"); got != "verifyBcc: evil@example.com" {
+		t.Errorf("X-Campaign = %q, want line breaks stripped", got)
+	}
+	if parsed.Header.Get("Message-Id") == "" || parsed.Header.Get("Date") == "" {
+		t.Error("Message-ID and Date should be set")
+	}
+
+	// mixed(related(alternative(text, html), logo), terms)
+	mixed := readParts(t, parsed.Header.Get("Content-Type"), parsed.Body)
+	if len(mixed) != 2 || mixed[1].FileName() != "terms.pdf" {
+		t.Fatalf("mixed parts = %v", mixed)
+	}
+	related := readParts(t, mixed[0].Header.Get("Content-Type"), bytes.NewReader(mixed[0].body))
+	if len(related) != 2 || related[1].Header.Get("Content-Id") != "<logo>" {
+		t.Fatalf("related parts = %v", related)
+	}
+	alternative := readParts(t, related[0].Header.Get("Content-Type"), bytes.NewReader(related[0].body))
+	if len(alternative) != 2 {
+		t.Fatalf("alternative parts = %v", alternative)
+	}
+	if string(alternative[0].body) != msg.Text || string(alternative[1].body) != msg.HTML {
+		t.Errorf("bodies = %q, %q", alternative[0].body, alternative[1].body)
+	}
+	if !strings.HasPrefix(alternative[1].Header.Get("Content-Type"), "text/html") {
+		t.Errorf("second alternative = %s, want text/html", alternative[1].Header.Get("Content-Type"))
+	}
+
+	rcpts, err := msg.Recipients()
+	if err != nil {
+		t.Fatalf("Recipients() error = %v", err)
+	}
+	want := []string{"alice@example.com", "bob@example.com", "audit@example.com"}
+	if strings.Join(rcpts, ",") != strings.Join(want, ",") {
+		t.Errorf("Recipients() = %v, want %v", rcpts, want)
+	}
+}
+
+func TestMessageInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+	}{
+		{"no sender", Message{To: []string{"a@example.com"}, Text: "hi"}},
+		{"no body", Message{From: "b@example.com", To: []string{"a@example.com"}}},
+		{"bad sender", Message{From: "not an address", To: []string{"a@example.com"}, Text: "hi"}},
+		{"no recipients", Message{From: "b@example.com", Text: "hi"}},
+		{"bad recipient", Message{From: "b@example.com", To: []string{"nope"}, Text: "hi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewMemorySender().Send(context.Background(), &tt.msg)
+			if !errors.Is(err, ErrInvalidMessage) {
+				t.Errorf("Send() error = %v, want ErrInvalidMessage", err)
+			}
+			if !IsPermanent(err) {
+				t.Error("invalid messages should be permanent failures")
+			}
+		})
+	}
+}
+
+// decodedPart is a MIME part with its body decoded
+type decodedPart struct {
+	*multipart.Part
+	body []byte
+}
+
+// readParts reads the parts of a multipart body
+func readParts(t *testing.T, contentType string, body io.Reader) []decodedPart {
+	t.Helper()
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("ParseMediaType(%q) error = %v", contentType, err)
+	}
+	r := multipart.NewReader(body, params["boundary"])
+	var parts []decodedPart
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		data, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		parts = append(parts, decodedPart{Part: p, body: data})
+	}
+}
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// MemorySender records messages instead of sending them, for tests and
+// local development
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []*Message
+	// Err, when set, is returned by Send and nothing is recorded
+	Err error
+}
+
+// NewMemorySender creates an empty in-memory sender
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+// Send records msg after checking it can be encoded
+func (s *MemorySender) Send(ctx context.Context, msg *Message) error {
+	if _, err := msg.Bytes(); err != nil {
+		return err
+	}
+	if _, err := msg.Recipients(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far
+func (s *MemorySender) Messages() []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Message(nil), s.messages...)
+}
+
+// Reset forgets the recorded messages
+func (s *MemorySender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = nil
+}
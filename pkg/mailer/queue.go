@@ -0,0 +1,163 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"mora/pkg/logger"
+	"mora/pkg/retry"
+)
+
+var (
+	// ErrQueueFull is returned when the queue buffer is full
+	ErrQueueFull = errors.New("mailer: queue is full")
+	// ErrQueueClosed is returned after Close
+	ErrQueueClosed = errors.New("mailer: queue is closed")
+)
+
+// QueueConfig configures an async send queue
+type QueueConfig struct {
+	Workers int `json:"workers" yaml:"workers" env:"WORKERS"`
+	// Size is the number of messages buffered before Send fails
+	Size int `json:"size" yaml:"size" env:"SIZE"`
+	// Retry retries temporary failures; permanent ones, see IsPermanent,
+	// fail at once
+	Retry retry.Config `json:"retry" yaml:"retry"`
+	// OnFailure is called for messages that could not be delivered;
+	// failures are logged by default
+	OnFailure func(msg *Message, err error) `json:"-" yaml:"-"`
+}
+
+// DefaultQueueConfig returns default queue configuration
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		Workers: 2,
+		Size:    1000,
+		Retry: retry.Config{
+			MaxAttempts: 5,
+			Backoff:     time.Second,
+			MaxBackoff:  time.Minute,
+			Multiplier:  2,
+			Jitter:      0.2,
+		},
+	}
+}
+
+// Queue sends messages in the background with retries. It implements
+// Sender, so request handlers return as soon as the message is queued.
+type Queue struct {
+	sender Sender
+	cfg    QueueConfig
+	jobs   chan *Message
+	// ctx is cancelled when Close gives up waiting, aborting retries
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewQueue creates a queue in front of sender and starts its workers
+func NewQueue(sender Sender, cfg QueueConfig) *Queue {
+	defaults := DefaultQueueConfig()
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaults.Workers
+	}
+	if cfg.Size <= 0 {
+		cfg.Size = defaults.Size
+	}
+	if cfg.Retry.MaxAttempts <= 0 {
+		cfg.Retry.MaxAttempts = defaults.Retry.MaxAttempts
+	}
+	if cfg.Retry.Backoff <= 0 {
+		cfg.Retry.Backoff = defaults.Retry.Backoff
+	}
+	if cfg.Retry.Multiplier == 0 {
+		cfg.Retry.Multiplier = defaults.Retry.Multiplier
+	}
+	retryable := cfg.Retry.Retryable
+	cfg.Retry.Retryable = func(err error) bool {
+		return !IsPermanent(err) && (retryable == nil || retryable(err))
+	}
+	if cfg.OnFailure == nil {
+		log := logger.NewDefault()
+		cfg.OnFailure = func(msg *Message, err error) {
+			log.Errorf("failed to send email %q to %v: %v", msg.Subject, msg.To, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		sender: sender,
+		cfg:    cfg,
+		jobs:   make(chan *Message, cfg.Size),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// Send queues msg without blocking; delivery errors go to OnFailure
+func (q *Queue) Send(ctx context.Context, msg *Message) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	select {
+	case q.jobs <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting messages and waits for queued ones to be sent. When
+// ctx ends first, pending retries are abandoned and ctx's error is returned.
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// work delivers queued messages until the queue is closed
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for msg := range q.jobs {
+		if err := q.deliver(msg); err != nil {
+			q.cfg.OnFailure(msg, err)
+		}
+	}
+}
+
+// deliver sends msg, retrying temporary failures with the Retry policy
+func (q *Queue) deliver(msg *Message) error {
+	return retry.Do(q.ctx, q.cfg.Retry, func(ctx context.Context) error {
+		return q.sender.Send(ctx, msg)
+	})
+}
@@ -0,0 +1,112 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mora/pkg/retry"
+)
+
+// flakySender fails the first failures sends of each message with err
+type flakySender struct {
+	failures int32
+	err      error
+	calls    atomic.Int32
+	sent     *MemorySender
+}
+
+func (s *flakySender) Send(ctx context.Context, msg *Message) error {
+	if s.calls.Add(1) <= s.failures {
+		return s.err
+	}
+	return s.sent.Send(ctx, msg)
+}
+
+func TestQueueRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int32
+		err       error
+		wantCalls int32
+		wantSent  int
+		wantErr   bool
+	}{
+		{"first try", 0, nil, 1, 1, false},
+		{"temporary failures", 2, &textproto.Error{Code: 421, Msg: "try later"}, 3, 1, false},
+		{"attempts exhausted", 5, errors.New("connection reset"), 3, 0, true},
+		{"permanent failure", 5, &textproto.Error{Code: 550, Msg: "no such user"}, 1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &flakySender{failures: tt.failures, err: tt.err, sent: NewMemorySender()}
+
+			var mu sync.Mutex
+			var failed []error
+			q := NewQueue(sender, QueueConfig{
+				Workers: 1,
+				Retry:   retry.Config{MaxAttempts: 3, Backoff: time.Millisecond},
+				OnFailure: func(msg *Message, err error) {
+					mu.Lock()
+					failed = append(failed, err)
+					mu.Unlock()
+				},
+			})
+
+			msg := &Message{From: "noreply@example.com", To: []string{"a@example.com"}, Subject: "hi", Text: "hi"}
+			if err := q.Send(context.Background(), msg); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if err := q.Close(context.Background()); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			if got := sender.calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if got := len(sender.sent.Messages()); got != tt.wantSent {
+				t.Errorf("sent = %d, want %d", got, tt.wantSent)
+			}
+			if (len(failed) > 0) != tt.wantErr {
+				t.Errorf("failures = %v, want error %v", failed, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	block := make(chan struct{})
+	sender := SenderFunc(func(ctx context.Context, msg *Message) error {
+		<-block
+		return nil
+	})
+	q := NewQueue(sender, QueueConfig{Workers: 1, Size: 1, OnFailure: func(*Message, error) {}})
+	msg := &Message{Subject: "hi"}
+
+	// One message in flight, one buffered, then the buffer is full
+	q.Send(context.Background(), msg)
+	time.Sleep(10 * time.Millisecond)
+	if err := q.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := q.Send(context.Background(), msg); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Send() error = %v, want ErrQueueFull", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(block)
+	}()
+	if err := q.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() error = %v, want DeadlineExceeded", err)
+	}
+	if err := q.Send(context.Background(), msg); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Send() after Close error = %v, want ErrQueueClosed", err)
+	}
+}
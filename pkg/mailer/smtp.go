@@ -0,0 +1,299 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TLS modes of SMTPConfig.TLS
+const (
+	// TLSStartTLS upgrades a plain connection with STARTTLS, usually on port 587
+	TLSStartTLS = "starttls"
+	// TLSImplicit connects over TLS from the start, usually on port 465
+	TLSImplicit = "tls"
+	// TLSNone sends in plain text; only use it for local relays
+	TLSNone = "none"
+)
+
+// SMTPConfig configures an SMTP sender
+type SMTPConfig struct {
+	Host     string `json:"host" yaml:"host" env:"HOST"`
+	Port     int    `json:"port" yaml:"port" env:"PORT"`
+	Username string `json:"username" yaml:"username" env:"USERNAME"`
+	Password string `json:"password" yaml:"password" env:"PASSWORD"`
+	// From is used for messages without a From address
+	From string `json:"from" yaml:"from" env:"FROM"`
+	// TLS is starttls, tls or none
+	TLS                string `json:"tls" yaml:"tls" env:"TLS"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify" yaml:"insecure_skip_verify" env:"INSECURE_SKIP_VERIFY"`
+	// LocalName is sent in EHLO, defaulting to localhost
+	LocalName string `json:"local_name" yaml:"local_name" env:"LOCAL_NAME"`
+	// PoolSize caps the open connections, which are reused between messages
+	PoolSize int `json:"pool_size" yaml:"pool_size" env:"POOL_SIZE"`
+	// IdleTimeout closes pooled connections unused for this long
+	IdleTimeout time.Duration `json:"idle_timeout" yaml:"idle_timeout" env:"IDLE_TIMEOUT"`
+	// Timeout bounds connecting and each send
+	Timeout time.Duration `json:"timeout" yaml:"timeout" env:"TIMEOUT"`
+}
+
+// DefaultSMTPConfig returns default SMTP configuration
+func DefaultSMTPConfig() SMTPConfig {
+	return SMTPConfig{
+		Host:        "localhost",
+		Port:        587,
+		TLS:         TLSStartTLS,
+		PoolSize:    4,
+		IdleTimeout: 30 * time.Second,
+		Timeout:     30 * time.Second,
+	}
+}
+
+// smtpConn is a pooled connection
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// SMTPSender sends messages over SMTP, reusing connections
+type SMTPSender struct {
+	cfg   SMTPConfig
+	slots chan struct{}
+
+	mu     sync.Mutex
+	idle   []*smtpConn
+	closed bool
+}
+
+// NewSMTPSender creates an SMTP sender; connections are opened on demand
+func NewSMTPSender(cfg SMTPConfig) (*SMTPSender, error) {
+	defaults := DefaultSMTPConfig()
+	if cfg.Host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = defaults.Port
+	}
+	if cfg.TLS == "" {
+		cfg.TLS = defaults.TLS
+	}
+	switch cfg.TLS {
+	case TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return nil, fmt.Errorf("unsupported smtp tls mode %q", cfg.TLS)
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = defaults.PoolSize
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaults.IdleTimeout
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	return &SMTPSender{cfg: cfg, slots: make(chan struct{}, cfg.PoolSize)}, nil
+}
+
+// Send delivers msg, waiting for a free connection when all are busy
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	if msg.From == "" {
+		copied := *msg
+		copied.From = s.cfg.From
+		msg = &copied
+	}
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	rcpts, err := msg.Recipients()
+	if err != nil {
+		return err
+	}
+	from, err := envelopeAddress(msg.From)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-s.slots }()
+
+	c, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.deliver(ctx, c, from, rcpts, data); err != nil {
+		c.client.Close()
+		return err
+	}
+	s.release(c)
+	return nil
+}
+
+// deliver runs one mail transaction on c
+func (s *SMTPSender) deliver(ctx context.Context, c *smtpConn, from string, rcpts []string, data []byte) error {
+	deadline := time.Now().Add(s.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetDeadline(deadline)
+
+	if err := c.client.Mail(from); err != nil {
+		return fmt.Errorf("smtp MAIL FROM: %w", err)
+	}
+	for _, rcpt := range rcpts {
+		if err := c.client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s: %w", rcpt, err)
+		}
+	}
+	w, err := c.client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	return nil
+}
+
+// conn returns a live pooled connection or dials a new one
+func (s *SMTPSender) conn(ctx context.Context) (*smtpConn, error) {
+	for {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return nil, errors.New("smtp sender is closed")
+		}
+		if len(s.idle) == 0 {
+			s.mu.Unlock()
+			return s.dial(ctx)
+		}
+		c := s.idle[len(s.idle)-1]
+		s.idle = s.idle[:len(s.idle)-1]
+		s.mu.Unlock()
+
+		// RSET both checks the connection and clears any leftover state
+		c.conn.SetDeadline(time.Now().Add(s.cfg.Timeout))
+		if time.Since(c.lastUsed) < s.cfg.IdleTimeout && c.client.Reset() == nil {
+			return c, nil
+		}
+		c.client.Close()
+	}
+}
+
+// release returns c to the pool
+func (s *SMTPSender) release(c *smtpConn) {
+	c.lastUsed = time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		c.client.Quit()
+		return
+	}
+	s.idle = append(s.idle, c)
+}
+
+// dial opens and authenticates a connection
+func (s *SMTPSender) dial(ctx context.Context) (*smtpConn, error) {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: s.cfg.Host, InsecureSkipVerify: s.cfg.InsecureSkipVerify}
+
+	dialer := &net.Dialer{Timeout: s.cfg.Timeout}
+	var conn net.Conn
+	var err error
+	if s.cfg.TLS == TLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to smtp server %s: %w", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(s.cfg.Timeout))
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	if err := s.handshake(client, tlsConfig); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &smtpConn{conn: conn, client: client, lastUsed: time.Now()}, nil
+}
+
+// handshake greets the server, upgrades to TLS and authenticates
+func (s *SMTPSender) handshake(client *smtp.Client, tlsConfig *tls.Config) error {
+	localName := s.cfg.LocalName
+	if localName == "" {
+		localName = "localhost"
+	}
+	if err := client.Hello(localName); err != nil {
+		return fmt.Errorf("smtp EHLO: %w", err)
+	}
+	if s.cfg.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("smtp STARTTLS: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		auth := smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp AUTH: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the idle connections; connections in use close once their
+// send completes
+func (s *SMTPSender) Close() error {
+	s.mu.Lock()
+	idle := s.idle
+	s.idle = nil
+	s.closed = true
+	s.mu.Unlock()
+
+	for _, c := range idle {
+		c.client.Quit()
+	}
+	return nil
+}
+
+// IsPermanent reports whether err will fail again on retry: an invalid
+// message or a 5xx SMTP reply
+func IsPermanent(err error) bool {
+	if errors.Is(err, ErrInvalidMessage) {
+		return true
+	}
+	var tpErr *textproto.Error
+	return errors.As(err, &tpErr) && tpErr.Code >= 500
+}
+
+// envelopeAddress extracts the bare address for MAIL FROM
+func envelopeAddress(from string) (string, error) {
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return "", fmt.Errorf("%w: sender %q: %v", ErrInvalidMessage, from, err)
+	}
+	return addr.Address, nil
+}
@@ -0,0 +1,228 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSMTP is a minimal SMTP server recording the mail it receives
+type fakeSMTP struct {
+	ln net.Listener
+
+	mu    sync.Mutex
+	conns int
+	auth  []string
+	mails []fakeMail
+}
+
+type fakeMail struct {
+	from  string
+	rcpts []string
+	data  string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	s := &fakeSMTP{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTP) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 fake ESMTP")
+	var cur fakeMail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO":
+			reply("250-fake")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			creds, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(arg, "PLAIN "))
+			s.mu.Lock()
+			s.auth = append(s.auth, string(creds))
+			s.mu.Unlock()
+			if strings.HasSuffix(string(creds), "\x00secret") {
+				reply("235 ok")
+			} else {
+				reply("535 bad credentials")
+			}
+		case "MAIL":
+			cur = fakeMail{from: strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")}
+			reply("250 ok")
+		case "RCPT":
+			rcpt := strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+			if strings.HasPrefix(rcpt, "unknown@") {
+				reply("550 no such user")
+				continue
+			}
+			cur.rcpts = append(cur.rcpts, rcpt)
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			cur.data = data.String()
+			s.mu.Lock()
+			s.mails = append(s.mails, cur)
+			s.mu.Unlock()
+			reply("250 queued")
+		case "RSET", "NOOP":
+			reply("250 ok")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 unknown command")
+		}
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	server := newFakeSMTP(t)
+	cfg := DefaultSMTPConfig()
+	cfg.Host = "127.0.0.1"
+	cfg.Port = server.port()
+	cfg.TLS = TLSNone
+	cfg.Username = "mora"
+	cfg.Password = "secret"
+	cfg.From = "Mora <noreply@example.com>"
+	cfg.PoolSize = 1
+	sender, err := NewSMTPSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSMTPSender() error = %v", err)
+	}
+	defer sender.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		msg := &Message{
+			To:      []string{"alice@example.com"},
+			Bcc:     []string{"audit@example.com"},
+			Subject: "code " + strconv.Itoa(i),
+			Text:    "hello\n.leading dot",
+		}
+		if err := sender.Send(ctx, msg); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	err = sender.Send(ctx, &Message{To: []string{"unknown@example.com"}, Subject: "x", Text: "x"})
+	if err == nil || !IsPermanent(err) {
+		t.Errorf("Send() to unknown recipient error = %v, want a permanent error", err)
+	}
+	if err := sender.Send(ctx, &Message{To: []string{"alice@example.com"}, Subject: "again", Text: "x"}); err != nil {
+		t.Fatalf("Send() after a failure error = %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.mails) != 4 {
+		t.Fatalf("server received %d mails, want 4", len(server.mails))
+	}
+	// The first three reuse one connection; the failed send discards it
+	if server.conns != 2 {
+		t.Errorf("connections = %d, want 2", server.conns)
+	}
+	if server.auth[0] != "\x00mora\x00secret" {
+		t.Errorf("AUTH = %q", server.auth[0])
+	}
+	first := server.mails[0]
+	if first.from != "noreply@example.com" || strings.Join(first.rcpts, ",") != "alice@example.com,audit@example.com" {
+		t.Errorf("envelope = %s -> %v", first.from, first.rcpts)
+	}
+	if !strings.Contains(first.data, "Subject: code 0") || strings.Contains(first.data, "audit@example.com") {
+		t.Errorf("data = %q", first.data)
+	}
+}
+
+func TestSMTPSenderErrors(t *testing.T) {
+	server := newFakeSMTP(t)
+
+	tests := []struct {
+		name    string
+		cfg     func(*SMTPConfig)
+		wantErr string
+	}{
+		{"bad credentials", func(c *SMTPConfig) { c.Password = "wrong" }, "smtp AUTH"},
+		{"starttls required", func(c *SMTPConfig) { c.TLS = TLSStartTLS }, "STARTTLS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultSMTPConfig()
+			cfg.Host = "127.0.0.1"
+			cfg.Port = server.port()
+			cfg.TLS = TLSNone
+			cfg.Username = "mora"
+			cfg.Password = "secret"
+			tt.cfg(&cfg)
+			sender, err := NewSMTPSender(cfg)
+			if err != nil {
+				t.Fatalf("NewSMTPSender() error = %v", err)
+			}
+			defer sender.Close()
+
+			msg := &Message{From: "noreply@example.com", To: []string{"a@example.com"}, Subject: "x", Text: "x"}
+			err = sender.Send(context.Background(), msg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Send() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := NewSMTPSender(SMTPConfig{Host: "localhost", TLS: "ssl"}); err == nil {
+		t.Error("NewSMTPSender() should reject unknown TLS modes")
+	}
+	sender, _ := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: server.port(), TLS: TLSNone})
+	sender.Close()
+	err := sender.Send(context.Background(), &Message{From: "a@example.com", To: []string{"b@example.com"}, Text: "x"})
+	if err == nil || errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("Send() after Close error = %v", err)
+	}
+}
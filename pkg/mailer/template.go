@@ -0,0 +1,158 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// TemplateConfig configures email templates
+type TemplateConfig struct {
+	// Layout is the HTML layout file; each page is rendered inside it through
+	// {{template "content" .}}. It is optional.
+	Layout string `json:"layout" yaml:"layout" env:"LAYOUT"`
+	// TextLayout is the plain text counterpart of Layout
+	TextLayout string `json:"text_layout" yaml:"text_layout" env:"TEXT_LAYOUT"`
+	// Funcs are added to both HTML and text templates
+	Funcs map[string]any `json:"-" yaml:"-"`
+}
+
+// DefaultTemplateConfig returns default template configuration
+func DefaultTemplateConfig() TemplateConfig {
+	return TemplateConfig{
+		Layout:     "layout.html",
+		TextLayout: "layout.txt",
+	}
+}
+
+// Templates renders named emails from <name>.html and <name>.txt files. A
+// page sets its subject with {{define "subject"}}...{{end}}; with a layout the
+// body goes in {{define "content"}}...{{end}}.
+type Templates struct {
+	html map[string]*htmltemplate.Template
+	text map[string]*texttemplate.Template
+	// root is the template executed for the body: the layout or the page
+	htmlRoot string
+	textRoot string
+}
+
+// NewTemplates parses every template in fsys
+func NewTemplates(fsys fs.FS, cfg TemplateConfig) (*Templates, error) {
+	t := &Templates{
+		html: make(map[string]*htmltemplate.Template),
+		text: make(map[string]*texttemplate.Template),
+	}
+
+	htmlBase := htmltemplate.New("").Funcs(cfg.Funcs)
+	if exists(fsys, cfg.Layout) {
+		if _, err := htmlBase.ParseFS(fsys, cfg.Layout); err != nil {
+			return nil, fmt.Errorf("failed to parse layout %s: %w", cfg.Layout, err)
+		}
+		t.htmlRoot = path.Base(cfg.Layout)
+	}
+	textBase := texttemplate.New("").Funcs(cfg.Funcs)
+	if exists(fsys, cfg.TextLayout) {
+		if _, err := textBase.ParseFS(fsys, cfg.TextLayout); err != nil {
+			return nil, fmt.Errorf("failed to parse layout %s: %w", cfg.TextLayout, err)
+		}
+		t.textRoot = path.Base(cfg.TextLayout)
+	}
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || p == cfg.Layout || p == cfg.TextLayout {
+			return err
+		}
+		name := strings.TrimSuffix(p, path.Ext(p))
+		switch path.Ext(p) {
+		case ".html":
+			page, err := htmlBase.Clone()
+			if err == nil {
+				_, err = page.ParseFS(fsys, p)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to parse template %s: %w", p, err)
+			}
+			t.html[name] = page
+		case ".txt":
+			page, err := textBase.Clone()
+			if err == nil {
+				_, err = page.ParseFS(fsys, p)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to parse template %s: %w", p, err)
+			}
+			t.text[name] = page
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render executes the named email with data and returns a message with the
+// subject and bodies set
+func (t *Templates) Render(name string, data any) (*Message, error) {
+	htmlPage, hasHTML := t.html[name]
+	textPage, hasText := t.text[name]
+	if !hasHTML && !hasText {
+		return nil, fmt.Errorf("email template %q not found", name)
+	}
+
+	msg := &Message{}
+	var buf bytes.Buffer
+	if hasText {
+		if err := textPage.ExecuteTemplate(&buf, rootName(t.textRoot, name, ".txt"), data); err != nil {
+			return nil, fmt.Errorf("failed to render %s.txt: %w", name, err)
+		}
+		msg.Text = buf.String()
+		if textPage.Lookup("subject") != nil {
+			buf.Reset()
+			if err := textPage.ExecuteTemplate(&buf, "subject", data); err != nil {
+				return nil, fmt.Errorf("failed to render %s.txt subject: %w", name, err)
+			}
+			msg.Subject = strings.TrimSpace(buf.String())
+		}
+	}
+	if hasHTML {
+		buf.Reset()
+		if err := htmlPage.ExecuteTemplate(&buf, rootName(t.htmlRoot, name, ".html"), data); err != nil {
+			return nil, fmt.Errorf("failed to render %s.html: %w", name, err)
+		}
+		msg.HTML = buf.String()
+		if msg.Subject == "" && htmlPage.Lookup("subject") != nil {
+			buf.Reset()
+			if err := htmlPage.ExecuteTemplate(&buf, "subject", data); err != nil {
+				return nil, fmt.Errorf("failed to render %s.html subject: %w", name, err)
+			}
+			msg.Subject = strings.TrimSpace(html.UnescapeString(buf.String()))
+		}
+	}
+	if msg.Subject == "" {
+		return nil, fmt.Errorf("email template %q has no subject", name)
+	}
+	return msg, nil
+}
+
+// rootName is the template to execute for a page: the layout when there is one
+func rootName(layout, name, ext string) string {
+	if layout != "" {
+		return layout
+	}
+	return path.Base(name + ext)
+}
+
+// exists reports whether name is a file in fsys
+func exists(fsys fs.FS, name string) bool {
+	if name == "" {
+		return false
+	}
+	info, err := fs.Stat(fsys, name)
+	return err == nil && !info.IsDir()
+}
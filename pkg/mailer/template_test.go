@@ -0,0 +1,75 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTemplatesRender(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layout.html": {Data: []byte(`<html><body>{{template "content" .}}<footer>{{brand}}</footer></body></html>`)},
+		"layout.txt":  {Data: []byte("{{template \"content\" .}}\n-- {{brand}}")},
+		"verify.html": {Data: []byte(`{{define "subject"}}Verify {{.Name}}{{end}}{{define "content"}}<a href="{{.Link}}">Verify</a>{{end}}`)},
+		"verify.txt":  {Data: []byte(`{{define "subject"}}Verify {{.Name}} & confirm{{end}}{{define "content"}}Open {{.Link}}{{end}}`)},
+		"reset.html":  {Data: []byte(`{{define "subject"}}Reset for {{.Name}}{{end}}{{define "content"}}<p>{{.Name}}</p>{{end}}`)},
+	}
+	cfg := DefaultTemplateConfig()
+	cfg.Funcs = map[string]any{"brand": func() string { return "Mora" }}
+	tmpl, err := NewTemplates(fsys, cfg)
+	if err != nil {
+		t.Fatalf("NewTemplates() error = %v", err)
+	}
+
+	data := map[string]string{"Name": "<Alice>", "Link": "https://example.com/v?t=1&u=2"}
+	msg, err := tmpl.Render("verify", data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if msg.Subject != "Verify <Alice> & confirm" {
+		t.Errorf("Subject = %q, want the text subject", msg.Subject)
+	}
+	if msg.HTML != `<html><body><a href="https://example.com/v?t=1&amp;u=2">Verify</a><footer>Mora</footer></body></html>` {
+		t.Errorf("HTML = %q", msg.HTML)
+	}
+	if msg.Text != "Open https://example.com/v?t=1&u=2\n-- Mora" {
+		t.Errorf("Text = %q", msg.Text)
+	}
+
+	msg, err = tmpl.Render("reset", data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if msg.Subject != "Reset for <Alice>" || msg.Text != "" {
+		t.Errorf("Render(reset) = %+v, want an unescaped HTML subject and no text", msg)
+	}
+	if !strings.Contains(msg.HTML, "<p>&lt;Alice&gt;</p>") {
+		t.Errorf("HTML = %q, want escaped data", msg.HTML)
+	}
+
+	if _, err := tmpl.Render("missing", data); err == nil {
+		t.Error("Render() of an unknown template should fail")
+	}
+}
+
+func TestTemplatesWithoutLayout(t *testing.T) {
+	fsys := fstest.MapFS{
+		"emails/welcome.txt":   {Data: []byte(`{{define "subject"}}Welcome{{end}}Hi {{.}}`)},
+		"emails/nosubject.txt": {Data: []byte(`Hi`)},
+	}
+	tmpl, err := NewTemplates(fsys, DefaultTemplateConfig())
+	if err != nil {
+		t.Fatalf("NewTemplates() error = %v", err)
+	}
+
+	msg, err := tmpl.Render("emails/welcome", "Bob")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if msg.Subject != "Welcome" || msg.Text != "Hi Bob" || msg.HTML != "" {
+		t.Errorf("Render() = %+v", msg)
+	}
+	if _, err := tmpl.Render("emails/nosubject", nil); err == nil {
+		t.Error("Render() without a subject should fail")
+	}
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RouteUnmatched labels requests that matched no route, such as 404s, so
+// unknown paths cannot inflate the label cardinality
+const RouteUnmatched = "unmatched"
+
+// HTTPMetrics records request counts, latencies and in-flight requests by
+// route pattern, e.g. "/orders/:id", never by raw path
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+}
+
+// HTTP registers the http_requests_total, http_request_duration_seconds and
+// http_requests_in_flight collectors; collectors already registered in the
+// process are shared
+func (r *Registry) HTTP() (*HTTPMetrics, error) {
+	requests, err := r.Counter("http_requests_total",
+		"Total number of HTTP requests, by method, route and status.", "method", "route", "status")
+	if err != nil {
+		return nil, err
+	}
+	duration, err := r.Histogram("http_request_duration_seconds",
+		"Latency of HTTP requests, by method and route.", nil, "method", "route")
+	if err != nil {
+		return nil, err
+	}
+	inFlight := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: r.cfg.Namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests currently being served.",
+	})
+	if err := register(r.reg, &inFlight); err != nil {
+		return nil, err
+	}
+	return &HTTPMetrics{requests: requests, duration: duration, inFlight: inFlight}, nil
+}
+
+// Start marks a request in flight; call the returned function when it ends
+func (m *HTTPMetrics) Start() func() {
+	m.inFlight.Inc()
+	return m.inFlight.Dec
+}
+
+// Observe records one finished request; an empty route is RouteUnmatched
+func (m *HTTPMetrics) Observe(method, route string, status int, d time.Duration) {
+	if route == "" {
+		route = RouteUnmatched
+	}
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.duration.WithLabelValues(method, route).Observe(d.Seconds())
+}
+
+// Instrument wraps next, labelling each request with route(r) evaluated
+// after next returns, so routers that set the pattern while serving, such
+// as http.ServeMux with r.Pattern, are reported by pattern
+func (m *HTTPMetrics) Instrument(next http.Handler, route func(r *http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer m.Start()()
+		start := time.Now()
+		rec := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		m.Observe(r.Method, route(r), rec.status, time.Since(start))
+	})
+}
+
+// statusWriter records the status code of a response
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the first status code
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write implies a 200 status when no header was written
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
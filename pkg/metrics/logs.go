@@ -0,0 +1,26 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// LogMetrics counts emitted log lines by level and logger name, so alerts
+// can fire on error-rate spikes; it implements logger.EntryCounter
+type LogMetrics struct {
+	entries *prometheus.CounterVec
+}
+
+// Logs registers the log_entries_total collector; install the result with
+// logger.SetEntryCounter
+func (r *Registry) Logs() (*LogMetrics, error) {
+	entries, err := r.Counter("log_entries_total",
+		"Total number of log lines emitted, by level and logger.", "level", "logger")
+	if err != nil {
+		return nil, err
+	}
+	return &LogMetrics{entries: entries}, nil
+}
+
+// IncLogEntry implements logger.EntryCounter; the root logger has an empty
+// name
+func (m *LogMetrics) IncLogEntry(level, logger string) {
+	m.entries.WithLabelValues(level, logger).Inc()
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"mora/pkg/logger"
+)
+
+var _ logger.EntryCounter = (*LogMetrics)(nil)
+
+func TestLogs(t *testing.T) {
+	r := New(Config{Namespace: "app"}, prometheus.NewRegistry())
+	m, err := r.Logs()
+	if err != nil {
+		t.Fatalf("Logs() error = %v", err)
+	}
+
+	log, err := logger.New(logger.Config{
+		Level:   "info",
+		Format:  "json",
+		Outputs: []string{filepath.Join(t.TempDir(), "app.log")},
+	})
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+	defer log.Close()
+	log.SetEntryCounter(m)
+
+	log.Debug("filtered")
+	log.Info("one")
+	log.Named("db").Info("two")
+	log.Error("three")
+
+	tests := []struct {
+		level, logger string
+		want          float64
+	}{
+		{"debug", "", 0},
+		{"info", "", 1},
+		{"info", "db", 1},
+		{"error", "", 1},
+		{"error", "db", 0},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(m.entries.WithLabelValues(tt.level, tt.logger)); got != tt.want {
+			t.Errorf("log_entries_total{level=%q,logger=%q} = %v, want %v", tt.level, tt.logger, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Package metrics is a small facade over prometheus/client_golang: a
+// Registry creating namespaced counters, gauges and histograms, HTTP
+// request metrics for the adapters, database and Redis pool gauges, log
+// line counts, and the /metrics handler.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+)
+
+// Config configures a Registry
+type Config struct {
+	// Namespace prefixes the names of the metrics created by the registry
+	Namespace string `json:"namespace" yaml:"namespace" env:"METRICS_NAMESPACE"`
+	// Path is where the metrics are served
+	Path string `json:"path" yaml:"path" env:"METRICS_PATH"`
+}
+
+// DefaultConfig returns default metrics configuration
+func DefaultConfig() Config {
+	return Config{
+		Path: "/metrics",
+	}
+}
+
+// Registry creates and registers collectors
+type Registry struct {
+	cfg      Config
+	reg      prometheus.Registerer
+	gatherer prometheus.Gatherer
+}
+
+// New creates a registry registering with reg, or with
+// prometheus.DefaultRegisterer when nil. Handler serves reg when it is also
+// a Gatherer, such as a *prometheus.Registry, and the default gatherer otherwise.
+func New(cfg Config, reg prometheus.Registerer) *Registry {
+	if cfg.Path == "" {
+		cfg.Path = DefaultConfig().Path
+	}
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	gatherer := prometheus.DefaultGatherer
+	if g, ok := reg.(prometheus.Gatherer); ok {
+		gatherer = g
+	}
+	return &Registry{cfg: cfg, reg: reg, gatherer: gatherer}
+}
+
+// Path returns the configured metrics path
+func (r *Registry) Path() string {
+	return r.cfg.Path
+}
+
+// Handler serves the gathered metrics in the Prometheus exposition format
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.gatherer, promhttp.HandlerOpts{})
+}
+
+// Register registers a collector
+func (r *Registry) Register(c prometheus.Collector) error {
+	return r.reg.Register(c)
+}
+
+// Counter registers a counter vector, e.g.
+// Counter("orders_created_total", "Orders created.", "channel").
+// A counter already registered with the same name and labels is shared.
+func (r *Registry) Counter(name, help string, labels ...string) (*prometheus.CounterVec, error) {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: r.cfg.Namespace,
+		Name:      name,
+		Help:      help,
+	}, labels)
+	if err := register(r.reg, &c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Gauge registers a gauge vector
+func (r *Registry) Gauge(name, help string, labels ...string) (*prometheus.GaugeVec, error) {
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: r.cfg.Namespace,
+		Name:      name,
+		Help:      help,
+	}, labels)
+	if err := register(r.reg, &g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Histogram registers a histogram vector with buckets, prometheus.DefBuckets
+// when nil
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) (*prometheus.HistogramVec, error) {
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: r.cfg.Namespace,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}, labels)
+	if err := register(r.reg, &h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// DB exports the connection pool statistics of db as the go_sql_* gauges
+// labelled db_name=name, e.g. DB("orders", client.DB().DB())
+func (r *Registry) DB(name string, db *sql.DB) error {
+	return r.reg.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// PoolStatser is implemented by go-redis clients, e.g. cache.Client.GetClient()
+type PoolStatser interface {
+	PoolStats() *redis.PoolStats
+}
+
+// Redis exports the connection pool statistics of a Redis client as the
+// redis_pool_* metrics labelled pool=name
+func (r *Registry) Redis(name string, client PoolStatser) error {
+	return r.reg.Register(newRedisCollector(r.cfg.Namespace, name, client))
+}
+
+// register registers *c, replacing it with the existing collector when an
+// identical one is already registered
+func register[C prometheus.Collector](reg prometheus.Registerer, c *C) error {
+	err := reg.Register(*c)
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			*c = existing
+			return nil
+		}
+	}
+	return err
+}
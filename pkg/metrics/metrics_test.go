@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRegistryShare(t *testing.T) {
+	r := New(Config{Namespace: "app"}, prometheus.NewRegistry())
+
+	c1, err := r.Counter("orders_total", "Orders.", "channel")
+	if err != nil {
+		t.Fatalf("Counter() error = %v", err)
+	}
+	c2, err := r.Counter("orders_total", "Orders.", "channel")
+	if err != nil || c1 != c2 {
+		t.Fatalf("second Counter() = %p, %v; want shared %p", c2, err, c1)
+	}
+	if _, err := r.Gauge("orders_total", "Orders.", "other"); err == nil {
+		t.Error("Gauge() with a clashing name should fail")
+	}
+
+	c1.WithLabelValues("web").Add(2)
+	if got := testutil.ToFloat64(c2.WithLabelValues("web")); got != 2 {
+		t.Errorf("counter = %v, want 2", got)
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	r := New(DefaultConfig(), reg)
+	m, err := r.HTTP()
+	if err != nil {
+		t.Fatalf("HTTP() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	h := m.Instrument(mux, func(r *http.Request) string { return r.Pattern })
+
+	tests := []struct {
+		path   string
+		route  string
+		status string
+	}{
+		{path: "/orders/1", route: "GET /orders/{id}", status: "200"},
+		{path: "/orders/2", route: "GET /orders/{id}", status: "200"},
+		{path: "/missing", route: RouteUnmatched, status: "404"},
+	}
+	for _, tt := range tests {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+	}
+
+	requests := m.requests
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := testutil.ToFloat64(requests.WithLabelValues("GET", tt.route, tt.status)); got < 1 {
+				t.Errorf("http_requests_total{route=%q,status=%q} = %v", tt.route, tt.status, got)
+			}
+		})
+	}
+	if got := testutil.ToFloat64(requests.WithLabelValues("GET", "GET /orders/{id}", "200")); got != 2 {
+		t.Errorf("requests by pattern = %v, want 2", got)
+	}
+}
+
+// fakePool reports fixed pool statistics
+type fakePool struct{}
+
+func (fakePool) PoolStats() *redis.PoolStats {
+	return &redis.PoolStats{Hits: 5, TotalConns: 3, IdleConns: 2}
+}
+
+func TestHandlerServesPoolMetrics(t *testing.T) {
+	r := New(DefaultConfig(), prometheus.NewRegistry())
+	if err := r.Redis("cache", fakePool{}); err != nil {
+		t.Fatalf("Redis() error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, r.Path(), nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		`redis_pool_hits_total{pool="cache"} 5`,
+		`redis_pool_connections{pool="cache"} 3`,
+		`redis_pool_idle_connections{pool="cache"} 2`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaConfig configures the Kafka producer and consumer
+type KafkaConfig struct {
+	Brokers  []string            `json:"brokers" yaml:"brokers"`
+	ClientID string              `json:"client_id" yaml:"client_id" env:"CLIENT_ID"`
+	Producer KafkaProducerConfig `json:"producer" yaml:"producer"`
+	Consumer KafkaConsumerConfig `json:"consumer" yaml:"consumer"`
+}
+
+// KafkaProducerConfig configures publishing
+type KafkaProducerConfig struct {
+	// Topic is used for messages that do not set one
+	Topic        string        `json:"topic" yaml:"topic" env:"TOPIC"`
+	BatchSize    int           `json:"batch_size" yaml:"batch_size" env:"BATCH_SIZE"`
+	BatchTimeout time.Duration `json:"batch_timeout" yaml:"batch_timeout" env:"BATCH_TIMEOUT"`
+	WriteTimeout time.Duration `json:"write_timeout" yaml:"write_timeout" env:"WRITE_TIMEOUT"`
+	// RequiredAcks is -1 (all in-sync replicas), 0 (none) or 1 (leader only)
+	RequiredAcks int `json:"required_acks" yaml:"required_acks" env:"REQUIRED_ACKS"`
+}
+
+// KafkaConsumerConfig configures a consumer group
+type KafkaConsumerConfig struct {
+	GroupID string   `json:"group_id" yaml:"group_id" env:"GROUP_ID"`
+	Topics  []string `json:"topics" yaml:"topics"`
+	// StartOffset is where a new group starts reading: earliest or latest
+	StartOffset string        `json:"start_offset" yaml:"start_offset" env:"START_OFFSET"`
+	MinBytes    int           `json:"min_bytes" yaml:"min_bytes" env:"MIN_BYTES"`
+	MaxBytes    int           `json:"max_bytes" yaml:"max_bytes" env:"MAX_BYTES"`
+	MaxWait     time.Duration `json:"max_wait" yaml:"max_wait" env:"MAX_WAIT"`
+	// MaxAttempts bounds deliveries of a failing message, 0 retries forever.
+	// Once exhausted the message goes to DeadLetterTopic, or Consume stops
+	// without committing it when no dead-letter topic is set.
+	MaxAttempts     int           `json:"max_attempts" yaml:"max_attempts" env:"MAX_ATTEMPTS"`
+	RetryBackoff    time.Duration `json:"retry_backoff" yaml:"retry_backoff" env:"RETRY_BACKOFF"`
+	DeadLetterTopic string        `json:"dead_letter_topic" yaml:"dead_letter_topic" env:"DEAD_LETTER_TOPIC"`
+}
+
+// DefaultKafkaConfig returns default Kafka configuration
+func DefaultKafkaConfig() KafkaConfig {
+	return KafkaConfig{
+		Brokers:  []string{"localhost:9092"},
+		ClientID: "mora",
+		Producer: KafkaProducerConfig{
+			BatchSize:    100,
+			BatchTimeout: 10 * time.Millisecond,
+			WriteTimeout: 10 * time.Second,
+			RequiredAcks: -1,
+		},
+		Consumer: KafkaConsumerConfig{
+			StartOffset:  "earliest",
+			MinBytes:     1,
+			MaxBytes:     10 << 20,
+			MaxWait:      500 * time.Millisecond,
+			MaxAttempts:  5,
+			RetryBackoff: 200 * time.Millisecond,
+		},
+	}
+}
+
+// maxRetryBackoff caps the doubling delay between redeliveries
+const maxRetryBackoff = 30 * time.Second
+
+// kafkaWriter is the subset of kafka.Writer used by the producer
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// kafkaReader is the subset of kafka.Reader used by the consumer
+type kafkaReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// newKafkaWriter creates a writer publishing to per-message topics
+func newKafkaWriter(cfg KafkaConfig) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Balancer:     &kafka.Hash{},
+		BatchSize:    cfg.Producer.BatchSize,
+		BatchTimeout: cfg.Producer.BatchTimeout,
+		WriteTimeout: cfg.Producer.WriteTimeout,
+		RequiredAcks: kafka.RequiredAcks(cfg.Producer.RequiredAcks),
+		Transport:    &kafka.Transport{ClientID: cfg.ClientID},
+	}
+}
+
+// KafkaProducer publishes messages to Kafka. Messages with the same key go
+// to the same partition and therefore keep their order.
+type KafkaProducer struct {
+	topic  string
+	writer kafkaWriter
+}
+
+// NewKafkaProducer creates a Kafka producer
+func NewKafkaProducer(cfg KafkaConfig) (*KafkaProducer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("mq kafka: brokers are required")
+	}
+	return &KafkaProducer{topic: cfg.Producer.Topic, writer: newKafkaWriter(cfg)}, nil
+}
+
+// Publish implements Producer
+func (p *KafkaProducer) Publish(ctx context.Context, msgs ...*Message) error {
+	injectTraceID(ctx, msgs)
+
+	kmsgs := make([]kafka.Message, len(msgs))
+	for i, m := range msgs {
+		topic := m.Topic
+		if topic == "" {
+			topic = p.topic
+		}
+		if topic == "" {
+			return errors.New("mq kafka: message topic is required")
+		}
+		kmsgs[i] = toKafkaMessage(m, topic)
+	}
+
+	if err := p.writer.WriteMessages(ctx, kmsgs...); err != nil {
+		return fmt.Errorf("mq kafka: failed to publish: %w", err)
+	}
+	return nil
+}
+
+// Close flushes pending messages and closes the producer
+func (p *KafkaProducer) Close() error {
+	return p.writer.Close()
+}
+
+// KafkaConsumer consumes a Kafka consumer group. Offsets are committed only
+// after the handler succeeds, so messages are delivered at least once.
+type KafkaConsumer struct {
+	cfg    KafkaConsumerConfig
+	reader kafkaReader
+	// deadLetter publishes exhausted messages, nil without DeadLetterTopic
+	deadLetter kafkaWriter
+
+	closeOnce sync.Once
+}
+
+// NewKafkaConsumer creates a consumer for cfg.Consumer.Topics in group
+// cfg.Consumer.GroupID
+func NewKafkaConsumer(cfg KafkaConfig) (*KafkaConsumer, error) {
+	cc := cfg.Consumer
+	if len(cfg.Brokers) == 0 || cc.GroupID == "" || len(cc.Topics) == 0 {
+		return nil, errors.New("mq kafka: brokers, group id and topics are required")
+	}
+
+	startOffset := kafka.FirstOffset
+	switch cc.StartOffset {
+	case "", "earliest":
+	case "latest":
+		startOffset = kafka.LastOffset
+	default:
+		return nil, fmt.Errorf("mq kafka: unsupported start offset %q", cc.StartOffset)
+	}
+
+	c := &KafkaConsumer{
+		cfg: cc,
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:     cfg.Brokers,
+			GroupID:     cc.GroupID,
+			GroupTopics: cc.Topics,
+			MinBytes:    cc.MinBytes,
+			MaxBytes:    cc.MaxBytes,
+			MaxWait:     cc.MaxWait,
+			StartOffset: startOffset,
+			Dialer:      &kafka.Dialer{ClientID: cfg.ClientID, Timeout: 10 * time.Second, DualStack: true},
+		}),
+	}
+	if cc.DeadLetterTopic != "" {
+		c.deadLetter = newKafkaWriter(cfg)
+	}
+	return c, nil
+}
+
+// Consume implements Consumer. It returns nil when ctx is cancelled; a
+// message being handled at that moment stays uncommitted and is delivered
+// again after a restart or rebalance.
+func (c *KafkaConsumer) Consume(ctx context.Context, h Handler) error {
+	for {
+		km, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, io.EOF) {
+				// kafka-go reports a closed reader as io.EOF
+				return ErrClosed
+			}
+			return fmt.Errorf("mq kafka: failed to fetch: %w", err)
+		}
+
+		done, err := c.handle(ctx, km, h)
+		if err != nil || !done {
+			return err
+		}
+		if err := c.reader.CommitMessages(ctx, km); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("mq kafka: failed to commit %s: %w", messageID(km), err)
+		}
+	}
+}
+
+// handle delivers one message, retrying with backoff until it succeeds or
+// runs out of attempts. It reports whether the message may be committed.
+func (c *KafkaConsumer) handle(ctx context.Context, km kafka.Message, h Handler) (bool, error) {
+	delay := c.cfg.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := h(ctx, fromKafkaMessage(km))
+		if err == nil {
+			return true, nil
+		}
+		if ctx.Err() != nil {
+			return false, nil
+		}
+
+		if c.cfg.MaxAttempts > 0 && attempt >= c.cfg.MaxAttempts {
+			if c.deadLetter == nil {
+				return false, fmt.Errorf("mq kafka: message %s failed after %d attempts: %w", messageID(km), attempt, err)
+			}
+			if err := c.sendToDeadLetter(ctx, km, attempt, err); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetryBackoff)
+	}
+}
+
+// sendToDeadLetter publishes an exhausted message with its failure details
+func (c *KafkaConsumer) sendToDeadLetter(ctx context.Context, km kafka.Message, attempts int, cause error) error {
+	msg := fromKafkaMessage(km)
+	msg.SetHeader(HeaderOriginalTopic, km.Topic)
+	msg.SetHeader(HeaderAttempt, strconv.Itoa(attempts))
+	msg.SetHeader(HeaderError, cause.Error())
+
+	if err := c.deadLetter.WriteMessages(ctx, toKafkaMessage(msg, c.cfg.DeadLetterTopic)); err != nil {
+		return fmt.Errorf("mq kafka: failed to dead-letter %s: %w", messageID(km), err)
+	}
+	return nil
+}
+
+// Close stops the consumer and leaves the group
+func (c *KafkaConsumer) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		err = c.reader.Close()
+		if c.deadLetter != nil {
+			err = errors.Join(err, c.deadLetter.Close())
+		}
+	})
+	return err
+}
+
+// toKafkaMessage converts a message for publishing to topic
+func toKafkaMessage(m *Message, topic string) kafka.Message {
+	km := kafka.Message{Topic: topic, Value: m.Value}
+	if m.Key != "" {
+		km.Key = []byte(m.Key)
+	}
+	for k, v := range m.Headers {
+		km.Headers = append(km.Headers, kafka.Header{Key: k, Value: []byte(v)})
+	}
+	return km
+}
+
+// fromKafkaMessage converts a fetched message
+func fromKafkaMessage(km kafka.Message) *Message {
+	m := &Message{
+		Topic: km.Topic,
+		Key:   string(km.Key),
+		Value: km.Value,
+		Time:  km.Time,
+		ID:    messageID(km),
+	}
+	for _, h := range km.Headers {
+		m.SetHeader(h.Key, string(h.Value))
+	}
+	return m
+}
+
+// messageID formats a message position as partition/offset
+func messageID(km kafka.Message) string {
+	return strconv.Itoa(km.Partition) + "/" + strconv.FormatInt(km.Offset, 10)
+}
@@ -0,0 +1,162 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+
+	"mora/pkg/logger"
+)
+
+type fakeWriter struct {
+	mu   sync.Mutex
+	msgs []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error { return nil }
+
+type fakeReader struct {
+	msgs      []kafka.Message
+	committed []int64
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if len(r.msgs) == 0 {
+		return kafka.Message{}, io.EOF
+	}
+	m := r.msgs[0]
+	r.msgs = r.msgs[1:]
+	return m, nil
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, m := range msgs {
+		r.committed = append(r.committed, m.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Close() error { return nil }
+
+func TestKafkaProducerPublish(t *testing.T) {
+	w := &fakeWriter{}
+	p := &KafkaProducer{topic: "events", writer: w}
+
+	ctx := logger.WithTraceID(context.Background(), "trace-1")
+	err := p.Publish(ctx,
+		&Message{Key: "order-1", Value: []byte("created")},
+		&Message{Topic: "audit", Value: []byte("x"), Headers: map[string]string{HeaderTraceID: "own"}},
+	)
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if len(w.msgs) != 2 {
+		t.Fatalf("published %d messages, want 2", len(w.msgs))
+	}
+	first, second := fromKafkaMessage(w.msgs[0]), fromKafkaMessage(w.msgs[1])
+	if first.Topic != "events" || first.Key != "order-1" || first.Header(HeaderTraceID) != "trace-1" {
+		t.Errorf("first message = %+v", first)
+	}
+	if second.Topic != "audit" || second.Header(HeaderTraceID) != "own" {
+		t.Errorf("second message = %+v", second)
+	}
+
+	if err := (&KafkaProducer{writer: w}).Publish(ctx, &Message{}); err == nil {
+		t.Error("Publish() without topic should fail")
+	}
+}
+
+func TestKafkaConsumer(t *testing.T) {
+	boom := errors.New("boom")
+	newMessages := func() []kafka.Message {
+		return []kafka.Message{
+			{Topic: "orders", Offset: 1, Value: []byte("ok")},
+			{Topic: "orders", Offset: 2, Value: []byte("bad")},
+			{Topic: "orders", Offset: 3, Value: []byte("ok")},
+		}
+	}
+	handler := func(calls *int) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			*calls++
+			if string(msg.Value) == "bad" {
+				return boom
+			}
+			return nil
+		}
+	}
+
+	t.Run("dead letter", func(t *testing.T) {
+		r := &fakeReader{msgs: newMessages()}
+		dlq := &fakeWriter{}
+		c := &KafkaConsumer{
+			cfg:        KafkaConsumerConfig{MaxAttempts: 3, DeadLetterTopic: "orders.dlq"},
+			reader:     r,
+			deadLetter: dlq,
+		}
+
+		calls := 0
+		if err := c.Consume(context.Background(), handler(&calls)); !errors.Is(err, ErrClosed) {
+			t.Fatalf("Consume() error = %v, want %v", err, ErrClosed)
+		}
+		if calls != 5 {
+			t.Errorf("handler calls = %d, want 5", calls)
+		}
+		if len(r.committed) != 3 {
+			t.Errorf("committed = %v, want all three offsets", r.committed)
+		}
+		if len(dlq.msgs) != 1 {
+			t.Fatalf("dead-lettered %d messages, want 1", len(dlq.msgs))
+		}
+		dead := fromKafkaMessage(dlq.msgs[0])
+		if dead.Topic != "orders.dlq" || dead.Header(HeaderOriginalTopic) != "orders" ||
+			dead.Header(HeaderAttempt) != "3" || dead.Header(HeaderError) != "boom" {
+			t.Errorf("dead letter = %+v", dead)
+		}
+	})
+
+	t.Run("stop without dead letter", func(t *testing.T) {
+		r := &fakeReader{msgs: newMessages()}
+		c := &KafkaConsumer{cfg: KafkaConsumerConfig{MaxAttempts: 2}, reader: r}
+
+		calls := 0
+		if err := c.Consume(context.Background(), handler(&calls)); !errors.Is(err, boom) {
+			t.Fatalf("Consume() error = %v, want %v", err, boom)
+		}
+		if len(r.committed) != 1 || r.committed[0] != 1 {
+			t.Errorf("committed = %v, want only offset 1", r.committed)
+		}
+	})
+}
+
+func TestNewKafkaConsumerValidation(t *testing.T) {
+	cfg := DefaultKafkaConfig()
+	if _, err := NewKafkaConsumer(cfg); err == nil {
+		t.Error("NewKafkaConsumer() without group and topics should fail")
+	}
+
+	cfg.Consumer.GroupID = "billing"
+	cfg.Consumer.Topics = []string{"orders"}
+	cfg.Consumer.StartOffset = "middle"
+	if _, err := NewKafkaConsumer(cfg); err == nil {
+		t.Error("NewKafkaConsumer() with unknown start offset should fail")
+	}
+
+	cfg.Consumer.StartOffset = "latest"
+	c, err := NewKafkaConsumer(cfg)
+	if err != nil {
+		t.Fatalf("NewKafkaConsumer() error = %v", err)
+	}
+	c.Close()
+}
@@ -0,0 +1,100 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"mora/pkg/logger"
+)
+
+// Recover turns handler panics into errors so one bad message cannot crash
+// the consumer
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("mq: handler panic: %v\n%s", r, debug.Stack())
+				}
+			}()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// TraceID restores the producer's trace ID from the message headers into
+// the handler context, for logger.WithContext and downstream calls
+func TraceID() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			if traceID := msg.Header(HeaderTraceID); traceID != "" {
+				ctx = logger.WithTraceID(ctx, traceID)
+			}
+			return next(ctx, msg)
+		}
+	}
+}
+
+// Logging logs every handled message with its duration and error
+func Logging(l *logger.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+
+			log := l.WithContext(ctx)
+			keysAndValues := []interface{}{
+				"topic", msg.Topic,
+				"key", msg.Key,
+				"id", msg.ID,
+				"duration", time.Since(start),
+			}
+			if err != nil {
+				log.Errorw("message handling failed", append(keysAndValues, "error", err)...)
+			} else {
+				log.Debugw("message handled", keysAndValues...)
+			}
+			return err
+		}
+	}
+}
+
+// Timeout bounds the time a handler may spend on one message
+func Timeout(d time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// Retry re-runs a failing handler in place up to attempts times in total,
+// doubling backoff after each failure; attempts below 1 run it once
+func Retry(attempts int, backoff time.Duration) Middleware {
+	attempts = max(attempts, 1)
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			var err error
+			delay := backoff
+			for i := 0; i < attempts; i++ {
+				if err = next(ctx, msg); err == nil {
+					return nil
+				}
+				if i == attempts-1 {
+					break
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(delay):
+				}
+				delay *= 2
+			}
+			return err
+		}
+	}
+}
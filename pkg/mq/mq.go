@@ -0,0 +1,97 @@
+// Package mq defines backend-neutral message queue interfaces. Producers
+// publish messages with headers; consumers deliver them to a Handler with
+// at-least-once semantics, acknowledging a message only after the handler
+// returns nil. Cross-cutting behaviour such as logging, tracing and retries
+// is added with Middleware.
+package mq
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"mora/pkg/logger"
+)
+
+const (
+	// HeaderTraceID carries the producer's trace ID to consumers
+	HeaderTraceID = "trace_id"
+	// HeaderAttempt records how many times a dead-lettered message was tried
+	HeaderAttempt = "x-attempt"
+	// HeaderError records the last handler error of a dead-lettered message
+	HeaderError = "x-error"
+	// HeaderOriginalTopic records the topic a dead-lettered message came from
+	HeaderOriginalTopic = "x-original-topic"
+)
+
+// ErrClosed is returned when using a closed producer or consumer
+var ErrClosed = errors.New("mq: closed")
+
+// Message is a queue message
+type Message struct {
+	Topic   string
+	Key     string
+	Value   []byte
+	Headers map[string]string
+	// Time is the publish time, set by the backend on delivery
+	Time time.Time
+	// ID identifies the message within its backend, e.g. "2/1045" (partition/offset)
+	ID string
+}
+
+// Header returns a header value, or "" when absent
+func (m *Message) Header(key string) string {
+	return m.Headers[key]
+}
+
+// SetHeader sets a header value
+func (m *Message) SetHeader(key, value string) {
+	if m.Headers == nil {
+		m.Headers = make(map[string]string)
+	}
+	m.Headers[key] = value
+}
+
+// Producer publishes messages
+type Producer interface {
+	// Publish sends messages, returning once the backend has accepted them
+	Publish(ctx context.Context, msgs ...*Message) error
+	Close() error
+}
+
+// Handler processes one delivered message; returning an error leaves the
+// message unacknowledged so it is delivered again
+type Handler func(ctx context.Context, msg *Message) error
+
+// Consumer delivers messages to a handler
+type Consumer interface {
+	// Consume blocks, delivering messages to h until ctx is cancelled or a
+	// message cannot be handled
+	Consume(ctx context.Context, h Handler) error
+	Close() error
+}
+
+// Middleware wraps a Handler with extra behaviour
+type Middleware func(Handler) Handler
+
+// Chain wraps h with middlewares; the first middleware is the outermost
+func Chain(h Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// injectTraceID copies the trace ID from ctx into messages that have none,
+// so consumers can continue the producer's trace
+func injectTraceID(ctx context.Context, msgs []*Message) {
+	traceID := logger.GetTraceIDFromContext(ctx)
+	if traceID == "" {
+		return
+	}
+	for _, m := range msgs {
+		if m.Header(HeaderTraceID) == "" {
+			m.SetHeader(HeaderTraceID, traceID)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"mora/pkg/logger"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, msg *Message) error {
+				order = append(order, name)
+				return next(ctx, msg)
+			}
+		}
+	}
+
+	h := Chain(func(ctx context.Context, msg *Message) error {
+		order = append(order, "handler")
+		return nil
+	}, mw("a"), mw("b"))
+
+	if err := h(context.Background(), &Message{}); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	if got := strings.Join(order, ","); got != "a,b,handler" {
+		t.Errorf("order = %s, want a,b,handler", got)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	boom := errors.New("boom")
+
+	tests := []struct {
+		name    string
+		mw      Middleware
+		handler Handler
+		check   func(t *testing.T, err error)
+	}{
+		{
+			name:    "recover",
+			mw:      Recover(),
+			handler: func(ctx context.Context, msg *Message) error { panic("bad message") },
+			check: func(t *testing.T, err error) {
+				if err == nil || !strings.Contains(err.Error(), "bad message") {
+					t.Errorf("err = %v, want panic error", err)
+				}
+			},
+		},
+		{
+			name: "trace id",
+			mw:   TraceID(),
+			handler: func(ctx context.Context, msg *Message) error {
+				if got := logger.GetTraceIDFromContext(ctx); got != "trace-1" {
+					return errors.New("trace id = " + got)
+				}
+				return nil
+			},
+			check: func(t *testing.T, err error) {
+				if err != nil {
+					t.Error(err)
+				}
+			},
+		},
+		{
+			name: "timeout",
+			mw:   Timeout(10 * time.Millisecond),
+			handler: func(ctx context.Context, msg *Message) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("err = %v, want deadline exceeded", err)
+				}
+			},
+		},
+		{
+			name:    "retry exhausted",
+			mw:      Retry(3, time.Millisecond),
+			handler: func(ctx context.Context, msg *Message) error { return boom },
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, boom) {
+					t.Errorf("err = %v, want %v", err, boom)
+				}
+			},
+		},
+		{
+			name: "logging",
+			mw:   Logging(logger.NewDefault()),
+			handler: func(ctx context.Context, msg *Message) error {
+				return boom
+			},
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, boom) {
+					t.Errorf("err = %v, want %v", err, boom)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &Message{Topic: "orders", Headers: map[string]string{HeaderTraceID: "trace-1"}}
+			tt.check(t, tt.mw(tt.handler)(context.Background(), msg))
+		})
+	}
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	calls := 0
+	h := Retry(5, time.Millisecond)(func(ctx context.Context, msg *Message) error {
+		calls++
+		if calls < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err := h(context.Background(), &Message{}); err != nil || calls != 3 {
+		t.Errorf("err = %v, calls = %d, want nil after 3 calls", err, calls)
+	}
+}
+
+func TestRetryRunsAtLeastOnce(t *testing.T) {
+	boom := errors.New("boom")
+	for _, attempts := range []int{0, -1} {
+		calls := 0
+		h := Retry(attempts, time.Millisecond)(func(ctx context.Context, msg *Message) error {
+			calls++
+			return boom
+		})
+		if err := h(context.Background(), &Message{}); !errors.Is(err, boom) || calls != 1 {
+			t.Errorf("Retry(%d): err = %v, calls = %d, want %v after 1 call", attempts, err, calls, boom)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mora/pkg/cache"
+	"mora/pkg/retry"
+)
+
+// Stream entry fields used to store a message
+const (
+	streamFieldKey    = "key"
+	streamFieldValue  = "value"
+	streamFieldHeader = "h:"
+)
+
+// RedisStreamConfig configures the Redis Streams producer and consumer.
+// Each topic is stored in the stream StreamPrefix+topic.
+type RedisStreamConfig struct {
+	StreamPrefix string `json:"stream_prefix" yaml:"stream_prefix" env:"STREAM_PREFIX"`
+	// Topic is used for messages that do not set one
+	Topic string `json:"topic" yaml:"topic" env:"TOPIC"`
+	// MaxLen approximately caps each stream's length, 0 keeps every entry
+	MaxLen int64 `json:"max_len" yaml:"max_len" env:"MAX_LEN"`
+
+	Group  string   `json:"group" yaml:"group" env:"GROUP"`
+	Topics []string `json:"topics" yaml:"topics"`
+	// Consumer names this instance within the group, defaults to host-pid
+	Consumer string `json:"consumer" yaml:"consumer" env:"CONSUMER"`
+	// StartID is where a new group starts reading: 0 (oldest) or $ (new only)
+	StartID   string        `json:"start_id" yaml:"start_id" env:"START_ID"`
+	BatchSize int64         `json:"batch_size" yaml:"batch_size" env:"BATCH_SIZE"`
+	Block     time.Duration `json:"block" yaml:"block" env:"BLOCK"`
+
+	// Retry retries a failed handler in process before the message is left
+	// pending; a zero MaxAttempts calls the handler once
+	Retry retry.Config `json:"retry" yaml:"retry"`
+	// Messages left pending longer than MinIdle, because a handler failed or
+	// a consumer died, are reclaimed every ClaimInterval and delivered again
+	ClaimInterval time.Duration `json:"claim_interval" yaml:"claim_interval" env:"CLAIM_INTERVAL"`
+	MinIdle       time.Duration `json:"min_idle" yaml:"min_idle" env:"MIN_IDLE"`
+	// MaxDeliveries moves a message to its topic's dead-letter stream, the
+	// stream name plus cache.DeadLetterSuffix, once its handler has failed on
+	// this many deliveries; 0 retries forever
+	MaxDeliveries int64 `json:"max_deliveries" yaml:"max_deliveries" env:"MAX_DELIVERIES"`
+
+	// OnError is called with handler and Redis errors; the consumer goes on
+	OnError func(error) `json:"-" yaml:"-"`
+}
+
+// DefaultRedisStreamConfig returns default Redis Streams configuration
+func DefaultRedisStreamConfig() RedisStreamConfig {
+	return RedisStreamConfig{
+		StreamPrefix:  "mq:",
+		MaxLen:        100000,
+		StartID:       "0",
+		BatchSize:     10,
+		Block:         2 * time.Second,
+		Retry:         retry.DefaultConfig(),
+		ClaimInterval: 30 * time.Second,
+		MinIdle:       time.Minute,
+		MaxDeliveries: 5,
+	}
+}
+
+// RedisProducer publishes messages to Redis Streams
+type RedisProducer struct {
+	cfg RedisStreamConfig
+	rdb redis.UniversalClient
+}
+
+// NewRedisProducer creates a Redis Streams producer on a cache client
+func NewRedisProducer(client *cache.Client, cfg RedisStreamConfig) *RedisProducer {
+	return &RedisProducer{cfg: cfg, rdb: client.GetClient()}
+}
+
+// Publish implements Producer; messages are added in one pipeline
+func (p *RedisProducer) Publish(ctx context.Context, msgs ...*Message) error {
+	injectTraceID(ctx, msgs)
+
+	pipe := p.rdb.Pipeline()
+	for _, m := range msgs {
+		topic := m.Topic
+		if topic == "" {
+			topic = p.cfg.Topic
+		}
+		if topic == "" {
+			return errors.New("mq redis: message topic is required")
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: p.cfg.StreamPrefix + topic,
+			MaxLen: p.cfg.MaxLen,
+			Approx: p.cfg.MaxLen > 0,
+			Values: encodeStreamValues(m),
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("mq redis: failed to publish: %w", err)
+	}
+	return nil
+}
+
+// Close implements Producer; the cache client is owned by the caller
+func (p *RedisProducer) Close() error {
+	return nil
+}
+
+// RedisConsumer consumes Redis Streams as a consumer group, running a
+// cache.StreamConsumer per topic. Entries are acknowledged only after the
+// handler succeeds; failures are retried in process, then left pending and
+// reclaimed after MinIdle, and dead-lettered with their error after
+// MaxDeliveries.
+type RedisConsumer struct {
+	cfg    RedisStreamConfig
+	client *cache.Client
+
+	mu        sync.Mutex
+	consumers []*cache.StreamConsumer
+	closed    bool
+}
+
+// NewRedisConsumer creates a Redis Streams consumer on a cache client
+func NewRedisConsumer(client *cache.Client, cfg RedisStreamConfig) (*RedisConsumer, error) {
+	if cfg.Group == "" || len(cfg.Topics) == 0 {
+		return nil, errors.New("mq redis: group and topics are required")
+	}
+	if cfg.Consumer == "" {
+		host, _ := os.Hostname()
+		cfg.Consumer = host + "-" + strconv.Itoa(os.Getpid())
+	}
+	return &RedisConsumer{cfg: cfg, client: client}, nil
+}
+
+// Consume implements Consumer. It returns nil when ctx is cancelled, once
+// the messages being handled are done; read errors go to OnError.
+func (c *RedisConsumer) Consume(ctx context.Context, h Handler) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	consumers := make([]*cache.StreamConsumer, 0, len(c.cfg.Topics))
+	for _, topic := range c.cfg.Topics {
+		sc, err := cache.NewStreamConsumer(c.client, c.streamConfig(topic), func(ctx context.Context, entry redis.XMessage) error {
+			return h(ctx, decodeStreamMessage(c.cfg.StreamPrefix, topic, entry))
+		})
+		if err != nil {
+			c.mu.Unlock()
+			return fmt.Errorf("mq redis: %w", err)
+		}
+		consumers = append(consumers, sc)
+	}
+	c.consumers = consumers
+	c.mu.Unlock()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make([]error, len(consumers))
+	var wg sync.WaitGroup
+	for i, sc := range consumers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errs[i] = sc.Run(runCtx); errs[i] != nil {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("mq redis: %w", err)
+	}
+	return nil
+}
+
+// streamConfig returns the stream consumer configuration of a topic
+func (c *RedisConsumer) streamConfig(topic string) cache.StreamConsumerConfig {
+	return cache.StreamConsumerConfig{
+		Stream:        c.cfg.StreamPrefix + topic,
+		Group:         c.cfg.Group,
+		Consumer:      c.cfg.Consumer,
+		StartID:       c.cfg.StartID,
+		BatchSize:     c.cfg.BatchSize,
+		Block:         c.cfg.Block,
+		Retry:         c.cfg.Retry,
+		ClaimInterval: c.cfg.ClaimInterval,
+		MinIdle:       c.cfg.MinIdle,
+		MaxDeliveries: c.cfg.MaxDeliveries,
+		OnError:       c.cfg.OnError,
+	}
+}
+
+// Close stops Consume once the messages being handled are done; the cache
+// client is owned by the caller
+func (c *RedisConsumer) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	consumers := c.consumers
+	c.mu.Unlock()
+
+	for _, sc := range consumers {
+		sc.Close()
+	}
+	return nil
+}
+
+// encodeStreamValues flattens a message into stream entry fields
+func encodeStreamValues(m *Message) map[string]interface{} {
+	values := make(map[string]interface{}, len(m.Headers)+2)
+	values[streamFieldKey] = m.Key
+	values[streamFieldValue] = m.Value
+	for k, v := range m.Headers {
+		values[streamFieldHeader+k] = v
+	}
+	return values
+}
+
+// decodeStreamMessage rebuilds a message from a stream entry; the fields of
+// a dead-lettered entry become the dead-letter headers
+func decodeStreamMessage(prefix, topic string, entry redis.XMessage) *Message {
+	m := &Message{Topic: topic, ID: entry.ID}
+	for k, v := range entry.Values {
+		s := fmt.Sprint(v)
+		switch {
+		case k == streamFieldKey:
+			m.Key = s
+		case k == streamFieldValue:
+			m.Value = []byte(s)
+		case strings.HasPrefix(k, streamFieldHeader):
+			m.SetHeader(strings.TrimPrefix(k, streamFieldHeader), s)
+		case k == cache.DeadLetterFieldStream:
+			m.SetHeader(HeaderOriginalTopic, strings.TrimPrefix(s, prefix))
+		case k == cache.DeadLetterFieldAttempts:
+			m.SetHeader(HeaderAttempt, s)
+		case k == cache.DeadLetterFieldError:
+			m.SetHeader(HeaderError, s)
+		}
+	}
+	// Stream IDs start with the millisecond timestamp of the entry
+	if ms, _, ok := strings.Cut(entry.ID, "-"); ok {
+		if v, err := strconv.ParseInt(ms, 10, 64); err == nil {
+			m.Time = time.UnixMilli(v)
+		}
+	}
+	return m
+}
@@ -0,0 +1,185 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"mora/pkg/cache"
+	"mora/pkg/retry"
+)
+
+func TestStreamMessageRoundTrip(t *testing.T) {
+	in := &Message{Key: "order-1", Value: []byte(`{"id":1}`), Headers: map[string]string{HeaderTraceID: "trace-1"}}
+
+	values := encodeStreamValues(in)
+	if values["h:trace_id"] != "trace-1" {
+		t.Errorf("header field = %v", values["h:trace_id"])
+	}
+
+	// Redis returns every field as a string
+	entry := redis.XMessage{ID: "1700000000000-0", Values: map[string]interface{}{}}
+	for k, v := range values {
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		entry.Values[k] = v
+	}
+
+	out := decodeStreamMessage("mq:", "orders", entry)
+	if out.Topic != "orders" || out.Key != in.Key || string(out.Value) != string(in.Value) || out.ID != entry.ID {
+		t.Errorf("decoded = %+v", out)
+	}
+	if out.Header(HeaderTraceID) != "trace-1" {
+		t.Errorf("headers = %v", out.Headers)
+	}
+	if !out.Time.Equal(time.UnixMilli(1700000000000)) {
+		t.Errorf("Time = %v", out.Time)
+	}
+}
+
+func TestDecodeDeadLetter(t *testing.T) {
+	entry := redis.XMessage{ID: "1700000000000-0", Values: map[string]interface{}{
+		streamFieldValue:              "bad",
+		cache.DeadLetterFieldStream:   "mq:orders",
+		cache.DeadLetterFieldID:       "1690000000000-0",
+		cache.DeadLetterFieldAttempts: "5",
+	}}
+
+	m := decodeStreamMessage("mq:", "orders"+cache.DeadLetterSuffix, entry)
+	if m.Header(HeaderOriginalTopic) != "orders" || m.Header(HeaderAttempt) != "5" {
+		t.Errorf("headers = %v", m.Headers)
+	}
+}
+
+func TestNewRedisConsumerValidation(t *testing.T) {
+	client := cache.New(cache.DefaultConfig())
+	defer client.Close()
+
+	cfg := DefaultRedisStreamConfig()
+	if _, err := NewRedisConsumer(client, cfg); err == nil {
+		t.Error("NewRedisConsumer() without group and topics should fail")
+	}
+
+	cfg.Group, cfg.Topics = "billing", []string{"orders"}
+	c, err := NewRedisConsumer(client, cfg)
+	if err != nil {
+		t.Fatalf("NewRedisConsumer() error = %v", err)
+	}
+	if c.cfg.Consumer == "" {
+		t.Error("consumer name should default to host-pid")
+	}
+}
+
+func TestRedisStreams(t *testing.T) {
+	tests := []struct {
+		name  string
+		retry retry.Config
+		// minIdle must outlast in-process retries, or reclaim takes over
+		// the entry while it is being retried
+		minIdle       time.Duration
+		maxDeliveries int64
+		wantBad       int32
+	}{
+		{name: "retried in process", retry: retry.Config{MaxAttempts: 3, Backoff: time.Millisecond}, minIdle: time.Minute, maxDeliveries: 1, wantBad: 3},
+		{name: "redelivered", minIdle: 10 * time.Millisecond, maxDeliveries: 2, wantBad: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := miniredis.RunT(t)
+			cfgCache := cache.DefaultConfig()
+			cfgCache.Addr = srv.Addr()
+			client := cache.New(cfgCache)
+			defer client.Close()
+
+			cfg := DefaultRedisStreamConfig()
+			cfg.Group, cfg.Topics = "test", []string{"orders"}
+			cfg.Block = 10 * time.Millisecond
+			cfg.Retry = tt.retry
+			cfg.ClaimInterval = 10 * time.Millisecond
+			cfg.MinIdle = tt.minIdle
+			cfg.MaxDeliveries = tt.maxDeliveries
+			ctx := context.Background()
+			dlq := cfg.StreamPrefix + "orders" + cache.DeadLetterSuffix
+
+			p := NewRedisProducer(client, cfg)
+			if err := p.Publish(ctx, &Message{Topic: "orders", Value: []byte("ok")}, &Message{Topic: "orders", Value: []byte("bad")}); err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+
+			c, err := NewRedisConsumer(client, cfg)
+			if err != nil {
+				t.Fatalf("NewRedisConsumer() error = %v", err)
+			}
+			var handled, bad atomic.Int32
+			runCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			go func() {
+				for runCtx.Err() == nil {
+					if n, _ := client.XLen(ctx, dlq); n > 0 {
+						cancel()
+					}
+					time.Sleep(5 * time.Millisecond)
+				}
+			}()
+			err = c.Consume(runCtx, func(ctx context.Context, msg *Message) error {
+				if string(msg.Value) == "bad" {
+					bad.Add(1)
+					return errors.New("boom")
+				}
+				handled.Add(1)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Consume() error = %v", err)
+			}
+
+			if handled.Load() != 1 || bad.Load() != tt.wantBad {
+				t.Errorf("handled = %d, bad = %d, want 1 and %d", handled.Load(), bad.Load(), tt.wantBad)
+			}
+			res, err := client.GetClient().XRange(ctx, dlq, "-", "+").Result()
+			if err != nil || len(res) != 1 {
+				t.Fatalf("dead-letter entries = %v, %v, want 1", res, err)
+			}
+			dead := decodeStreamMessage(cfg.StreamPrefix, "orders"+cache.DeadLetterSuffix, res[0])
+			if string(dead.Value) != "bad" || dead.Header(HeaderOriginalTopic) != "orders" || dead.Header(HeaderError) != "boom" {
+				t.Errorf("dead letter = %+v", dead)
+			}
+		})
+	}
+}
+
+func TestRedisConsumerClose(t *testing.T) {
+	srv := miniredis.RunT(t)
+	cfgCache := cache.DefaultConfig()
+	cfgCache.Addr = srv.Addr()
+	client := cache.New(cfgCache)
+	defer client.Close()
+
+	cfg := DefaultRedisStreamConfig()
+	cfg.Group, cfg.Topics = "test", []string{"orders", "payments"}
+	cfg.Block = 10 * time.Millisecond
+	c, err := NewRedisConsumer(client, cfg)
+	if err != nil {
+		t.Fatalf("NewRedisConsumer() error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- c.Consume(context.Background(), func(context.Context, *Message) error { return nil }) }()
+	time.Sleep(50 * time.Millisecond)
+	c.Close()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("Consume() error = %v, want %v", err, ErrClosed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Consume() did not return after Close")
+	}
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many calls pass between sweeps of idle keys
+const sweepEvery = 1024
+
+// memoryState is the per-key state of a memory limiter
+type memoryState struct {
+	// tokens and last track a token bucket
+	tokens float64
+	last   time.Time
+	// window, prev and cur track a sliding window
+	window int64
+	prev   int
+	cur    int
+}
+
+// MemoryLimiter is an in-process Limiter; budgets are not shared between
+// instances
+type MemoryLimiter struct {
+	mu     sync.Mutex
+	cfg    Config
+	bucket bool
+	keys   map[string]*memoryState
+	calls  int
+	now    func() time.Time
+}
+
+// NewMemoryTokenBucket creates an in-process token bucket limiter
+func NewMemoryTokenBucket(cfg Config) *MemoryLimiter {
+	return &MemoryLimiter{cfg: cfg.normalize(), bucket: true, keys: make(map[string]*memoryState), now: time.Now}
+}
+
+// NewMemorySlidingWindow creates an in-process sliding window limiter
+func NewMemorySlidingWindow(cfg Config) *MemoryLimiter {
+	return &MemoryLimiter{cfg: cfg.normalize(), keys: make(map[string]*memoryState), now: time.Now}
+}
+
+// Allow implements Limiter
+func (l *MemoryLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if l.calls++; l.calls%sweepEvery == 0 {
+		l.sweep(now)
+	}
+
+	s, ok := l.keys[key]
+	if !ok {
+		s = &memoryState{tokens: float64(l.cfg.Burst), last: now, window: now.UnixNano() / int64(l.cfg.Window)}
+		l.keys[key] = s
+	}
+	if l.bucket {
+		return l.takeToken(s, now), nil
+	}
+	return l.countRequest(s, now), nil
+}
+
+// takeToken refills the bucket and takes a token when one is available
+func (l *MemoryLimiter) takeToken(s *memoryState, now time.Time) Result {
+	rate := float64(l.cfg.Limit) / l.cfg.Window.Seconds()
+	s.tokens = min(float64(l.cfg.Burst), s.tokens+now.Sub(s.last).Seconds()*rate)
+	s.last = now
+	allowed := s.tokens >= 1
+	if allowed {
+		s.tokens--
+	}
+	return bucketResult(l.cfg, allowed, s.tokens, rate)
+}
+
+// countRequest rolls the windows forward and counts the request when the
+// weighted count allows it
+func (l *MemoryLimiter) countRequest(s *memoryState, now time.Time) Result {
+	window := now.UnixNano() / int64(l.cfg.Window)
+	switch window - s.window {
+	case 0:
+	case 1:
+		s.prev, s.cur = s.cur, 0
+	default:
+		s.prev, s.cur = 0, 0
+	}
+	s.window = window
+	elapsed := time.Duration(now.UnixNano() % int64(l.cfg.Window))
+
+	weight := 1 - float64(elapsed)/float64(l.cfg.Window)
+	allowed := float64(s.prev)*weight+float64(s.cur) < float64(l.cfg.Limit)
+	if allowed {
+		s.cur++
+	}
+	return windowResult(l.cfg, allowed, s.prev, s.cur, elapsed)
+}
+
+// sweep drops keys whose budget has been fully restored
+func (l *MemoryLimiter) sweep(now time.Time) {
+	window := now.UnixNano() / int64(l.cfg.Window)
+	for key, s := range l.keys {
+		idle := now.Sub(s.last) >= l.cfg.Window*time.Duration(l.cfg.Burst)/time.Duration(l.cfg.Limit)
+		if (l.bucket && idle) || (!l.bucket && window-s.window > 1) {
+			delete(l.keys, key)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package ratelimit limits how often a key, such as a user ID or client IP,
+// may act. Token-bucket and sliding-window limiters are provided in memory
+// for single instances and in Redis, through atomic scripts, for fleets.
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mora/pkg/cache"
+)
+
+const (
+	// TokenBucket refills Limit tokens per Window and allows bursts of Burst
+	TokenBucket = "token_bucket"
+	// SlidingWindow allows Limit requests in any Window, weighting the
+	// previous fixed window by how much of it still overlaps
+	SlidingWindow = "sliding_window"
+)
+
+// Result is the outcome of a rate limit check
+type Result struct {
+	// Allowed reports whether the request may proceed
+	Allowed bool
+	// Limit is the number of requests allowed per window, or the burst
+	Limit int
+	// Remaining is the number of further requests currently allowed
+	Remaining int
+	// ResetAfter is the time until the budget is fully restored
+	ResetAfter time.Duration
+	// RetryAfter is the time until a denied request may be retried
+	RetryAfter time.Duration
+}
+
+// Limiter takes one request from a key's budget
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// LimiterFunc adapts a function to Limiter
+type LimiterFunc func(ctx context.Context, key string) (Result, error)
+
+// Allow calls f
+func (f LimiterFunc) Allow(ctx context.Context, key string) (Result, error) {
+	return f(ctx, key)
+}
+
+// Config configures a limiter
+type Config struct {
+	// Algorithm is TokenBucket or SlidingWindow
+	Algorithm string `json:"algorithm" yaml:"algorithm" env:"ALGORITHM"`
+	// Limit is the number of requests allowed per Window
+	Limit  int           `json:"limit" yaml:"limit" env:"LIMIT"`
+	Window time.Duration `json:"window" yaml:"window" env:"WINDOW"`
+	// Burst is the token bucket capacity, Limit when unset
+	Burst int `json:"burst" yaml:"burst" env:"BURST"`
+	// Prefix starts every Redis key
+	Prefix string `json:"prefix" yaml:"prefix" env:"PREFIX"`
+}
+
+// DefaultConfig returns default rate limit configuration
+func DefaultConfig() Config {
+	return Config{
+		Algorithm: SlidingWindow,
+		Limit:     100,
+		Window:    time.Minute,
+		Prefix:    "ratelimit:",
+	}
+}
+
+// normalize fills unset fields with defaults
+func (c Config) normalize() Config {
+	defaults := DefaultConfig()
+	if c.Algorithm == "" {
+		c.Algorithm = defaults.Algorithm
+	}
+	if c.Limit <= 0 {
+		c.Limit = defaults.Limit
+	}
+	if c.Window <= 0 {
+		c.Window = defaults.Window
+	}
+	if c.Burst <= 0 {
+		c.Burst = c.Limit
+	}
+	if c.Prefix == "" {
+		c.Prefix = defaults.Prefix
+	}
+	return c
+}
+
+// New creates the configured limiter in Redis, or in memory when client is
+// nil, e.g. in development or tests
+func New(cfg Config, client *cache.Client) Limiter {
+	cfg = cfg.normalize()
+	switch {
+	case client == nil && cfg.Algorithm == TokenBucket:
+		return NewMemoryTokenBucket(cfg)
+	case client == nil:
+		return NewMemorySlidingWindow(cfg)
+	case cfg.Algorithm == TokenBucket:
+		return NewRedisTokenBucket(client, cfg)
+	}
+	return NewRedisSlidingWindow(client, cfg)
+}
+
+// bucketResult computes the result of a token bucket holding tokens after
+// the request at refill tokens per second
+func bucketResult(cfg Config, allowed bool, tokens, rate float64) Result {
+	res := Result{
+		Allowed:    allowed,
+		Limit:      cfg.Burst,
+		Remaining:  int(tokens),
+		ResetAfter: seconds((float64(cfg.Burst) - tokens) / rate),
+	}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return res
+}
+
+// windowResult computes the result of a sliding window whose previous and
+// current windows hold prev and cur requests, elapsed into the current one
+func windowResult(cfg Config, allowed bool, prev, cur int, elapsed time.Duration) Result {
+	weight := 1 - float64(elapsed)/float64(cfg.Window)
+	count := float64(prev)*weight + float64(cur)
+	res := Result{
+		Allowed:    allowed,
+		Limit:      cfg.Limit,
+		Remaining:  max(0, cfg.Limit-int(count+0.999)),
+		ResetAfter: 2*cfg.Window - elapsed,
+	}
+	if cur == 0 {
+		res.ResetAfter = cfg.Window - elapsed
+	}
+	if !allowed {
+		// The previous window's share shrinks linearly, so the wait is the
+		// time it takes to shed the excess, after the rollover when the
+		// current window alone is full
+		if cur < cfg.Limit {
+			share := (count - float64(cfg.Limit)) / float64(prev)
+			res.RetryAfter = time.Duration(share*float64(cfg.Window)) + time.Millisecond
+		} else {
+			share := 1 - float64(cfg.Limit)/float64(cur)
+			res.RetryAfter = cfg.Window - elapsed + time.Duration(share*float64(cfg.Window)) + time.Millisecond
+		}
+	}
+	return res
+}
+
+// seconds converts fractional seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// SetHeaders writes the X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset headers, plus Retry-After for denied requests; times
+// are whole seconds, rounded up
+func (r Result) SetHeaders(h http.Header) {
+	h.Set("X-RateLimit-Limit", strconv.Itoa(r.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(r.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(r.ResetAfter)))
+	if !r.Allowed {
+		h.Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(r.RetryAfter))))
+	}
+}
+
+// ceilSeconds rounds d up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable time source
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time      { return c.t }
+func (c *fakeClock) add(d time.Duration) { c.t = c.t.Add(d) }
+func newFakeClock() *fakeClock           { return &fakeClock{t: time.Unix(1_700_000_040, 0)} }
+func allow(t *testing.T, l Limiter, key string) Result {
+	t.Helper()
+	res, err := l.Allow(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	return res
+}
+
+func TestMemoryTokenBucket(t *testing.T) {
+	clock := newFakeClock()
+	l := NewMemoryTokenBucket(Config{Limit: 2, Window: time.Second})
+	l.now = clock.now
+
+	for i, want := range []bool{true, true, false} {
+		if res := allow(t, l, "u1"); res.Allowed != want {
+			t.Fatalf("request %d allowed = %v, want %v", i, res.Allowed, want)
+		}
+	}
+	res := allow(t, l, "u1")
+	if res.Limit != 2 || res.Remaining != 0 || res.RetryAfter != 500*time.Millisecond {
+		t.Errorf("denied result = %+v", res)
+	}
+	if res := allow(t, l, "u2"); !res.Allowed || res.Remaining != 1 {
+		t.Errorf("other key result = %+v", res)
+	}
+
+	clock.add(500 * time.Millisecond)
+	if res := allow(t, l, "u1"); !res.Allowed || res.ResetAfter != time.Second {
+		t.Errorf("after refill result = %+v", res)
+	}
+}
+
+func TestMemoryTokenBucketBurst(t *testing.T) {
+	clock := newFakeClock()
+	l := NewMemoryTokenBucket(Config{Limit: 1, Window: time.Second, Burst: 5})
+	l.now = clock.now
+
+	allowed := 0
+	for range 10 {
+		if allow(t, l, "k").Allowed {
+			allowed++
+		}
+	}
+	if allowed != 5 {
+		t.Errorf("allowed = %d, want burst of 5", allowed)
+	}
+}
+
+func TestMemorySlidingWindow(t *testing.T) {
+	clock := newFakeClock() // at the start of a minute
+	l := NewMemorySlidingWindow(Config{Limit: 3, Window: time.Minute})
+	l.now = clock.now
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		allowed []bool
+	}{
+		{name: "fills the window", allowed: []bool{true, true, true, false}},
+		{name: "full window blocks the start of the next", advance: time.Minute, allowed: []bool{false}},
+		{name: "previous window half counts", advance: 30 * time.Second, allowed: []bool{true, true, false}},
+		{name: "idle windows reset", advance: 3 * time.Minute, allowed: []bool{true, true, true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.add(tt.advance)
+			for i, want := range tt.allowed {
+				res := allow(t, l, "ip:10.0.0.1")
+				if res.Allowed != want {
+					t.Fatalf("request %d allowed = %v, want %v (%+v)", i, res.Allowed, want, res)
+				}
+				if !res.Allowed && res.RetryAfter <= 0 {
+					t.Errorf("denied without RetryAfter: %+v", res)
+				}
+			}
+		})
+	}
+}
+
+func TestSlidingWindowRetryAfter(t *testing.T) {
+	clock := newFakeClock()
+	l := NewMemorySlidingWindow(Config{Limit: 2, Window: time.Minute})
+	l.now = clock.now
+
+	allow(t, l, "k")
+	allow(t, l, "k")
+	clock.add(time.Minute + 30*time.Second)
+	allow(t, l, "k") // prev 2 at half weight plus 1
+	res := allow(t, l, "k")
+	if res.Allowed {
+		t.Fatalf("expected denial, got %+v", res)
+	}
+
+	// Retrying after RetryAfter must succeed
+	clock.add(res.RetryAfter)
+	if res := allow(t, l, "k"); !res.Allowed {
+		t.Errorf("retry after %v denied: %+v", res.RetryAfter, res)
+	}
+}
+
+func TestNewWithoutRedis(t *testing.T) {
+	if _, ok := New(Config{Algorithm: TokenBucket}, nil).(*MemoryLimiter); !ok {
+		t.Error("New() without client should use memory")
+	}
+	l := New(Config{}, nil).(*MemoryLimiter)
+	if l.bucket || l.cfg.Limit != 100 || l.cfg.Burst != 100 {
+		t.Errorf("defaults = %+v, bucket %v", l.cfg, l.bucket)
+	}
+}
+
+func TestSetHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		res  Result
+		want map[string]string
+	}{
+		{
+			name: "allowed",
+			res:  Result{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: 1500 * time.Millisecond},
+			want: map[string]string{"X-RateLimit-Limit": "10", "X-RateLimit-Remaining": "9", "X-RateLimit-Reset": "2", "Retry-After": ""},
+		},
+		{
+			name: "denied",
+			res:  Result{Limit: 10, ResetAfter: time.Minute, RetryAfter: time.Millisecond},
+			want: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "60", "Retry-After": "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			tt.res.SetHeaders(h)
+			for k, v := range tt.want {
+				if got := h.Get(k); got != v {
+					t.Errorf("%s = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mora/pkg/cache"
+)
+
+// tokenBucketScript refills and takes from a bucket hash. KEYS[1] is the
+// bucket; ARGV is the refill rate per millisecond, the burst and the TTL in
+// milliseconds. Redis' clock is used so instances need not agree on time.
+var tokenBucketScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {allowed, tostring(tokens)}
+`)
+
+// slidingWindowScript counts a request in a hash of per-window counters.
+// KEYS[1] is the hash; ARGV is the window in milliseconds and the limit.
+// It returns whether the request was allowed, the previous and current
+// counts and the milliseconds elapsed in the current window.
+var slidingWindowScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local size = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local window = math.floor(now / size)
+local elapsed = now % size
+local prev = tonumber(redis.call('HGET', KEYS[1], window - 1) or '0')
+local cur = tonumber(redis.call('HGET', KEYS[1], window) or '0')
+local allowed = 0
+if prev * (1 - elapsed / size) + cur < limit then
+	cur = redis.call('HINCRBY', KEYS[1], window, 1)
+	allowed = 1
+end
+redis.call('HDEL', KEYS[1], window - 2)
+redis.call('PEXPIRE', KEYS[1], size * 2)
+return {allowed, prev, cur, elapsed}
+`)
+
+// RedisLimiter is a Limiter in Redis through pkg/cache, sharing budgets
+// between every instance using the same prefix
+type RedisLimiter struct {
+	client *cache.Client
+	cfg    Config
+	bucket bool
+}
+
+// NewRedisTokenBucket creates a token bucket limiter in Redis
+func NewRedisTokenBucket(client *cache.Client, cfg Config) *RedisLimiter {
+	return &RedisLimiter{client: client, cfg: cfg.normalize(), bucket: true}
+}
+
+// NewRedisSlidingWindow creates a sliding window limiter in Redis
+func NewRedisSlidingWindow(client *cache.Client, cfg Config) *RedisLimiter {
+	return &RedisLimiter{client: client, cfg: cfg.normalize()}
+}
+
+// Allow implements Limiter
+func (l *RedisLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	if l.bucket {
+		return l.takeToken(ctx, l.cfg.Prefix+"tb:"+key)
+	}
+	return l.countRequest(ctx, l.cfg.Prefix+"sw:"+key)
+}
+
+// takeToken runs tokenBucketScript
+func (l *RedisLimiter) takeToken(ctx context.Context, key string) (Result, error) {
+	rate := float64(l.cfg.Limit) / float64(l.cfg.Window.Milliseconds())
+	ttl := time.Duration(float64(l.cfg.Burst)/rate) * time.Millisecond
+	reply, err := tokenBucketScript.Run(ctx, l.client.GetClient(), []string{key},
+		rate, l.cfg.Burst, max(1, ttl.Milliseconds())).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit: failed to take token: %w", err)
+	}
+	allowed, _ := reply[0].(int64)
+	text, _ := reply[1].(string)
+	tokens, _ := strconv.ParseFloat(text, 64)
+	return bucketResult(l.cfg, allowed == 1, tokens, rate*1000), nil
+}
+
+// countRequest runs slidingWindowScript
+func (l *RedisLimiter) countRequest(ctx context.Context, key string) (Result, error) {
+	reply, err := slidingWindowScript.Run(ctx, l.client.GetClient(), []string{key},
+		l.cfg.Window.Milliseconds(), l.cfg.Limit).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit: failed to count request: %w", err)
+	}
+	elapsed := time.Duration(reply[3]) * time.Millisecond
+	return windowResult(l.cfg, reply[0] == 1, int(reply[1]), int(reply[2]), elapsed), nil
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"mora/pkg/cache"
+)
+
+func TestRedisLimiter(t *testing.T) {
+	type step struct {
+		advance    time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}
+	tests := []struct {
+		name    string
+		limiter func(*cache.Client, Config) *RedisLimiter
+		key     string
+		steps   []step
+	}{
+		{
+			name:    "token bucket",
+			limiter: NewRedisTokenBucket,
+			key:     "ratelimit:tb:u1",
+			steps: []step{
+				{allowed: true, remaining: 1},
+				{allowed: true, remaining: 0},
+				{allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond},
+				{advance: 250 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 250 * time.Millisecond},
+				{advance: 250 * time.Millisecond, allowed: true, remaining: 0},
+				{advance: time.Minute, allowed: true, remaining: 1},
+			},
+		},
+		{
+			name:    "sliding window",
+			limiter: NewRedisSlidingWindow,
+			key:     "ratelimit:sw:u1",
+			steps: []step{
+				{allowed: true, remaining: 1},
+				{allowed: true, remaining: 0},
+				{allowed: false, remaining: 0, retryAfter: time.Second + time.Millisecond},
+				// Half the previous window's two requests still count
+				{advance: 1500 * time.Millisecond, allowed: true, remaining: 0},
+				{allowed: false, remaining: 0, retryAfter: time.Millisecond},
+				{advance: 250 * time.Millisecond, allowed: true, remaining: 0},
+				{advance: time.Minute, allowed: true, remaining: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := miniredis.RunT(t)
+			cfg := cache.DefaultConfig()
+			cfg.Addr = srv.Addr()
+			client := cache.New(cfg)
+			t.Cleanup(func() { client.Close() })
+
+			now := time.Unix(1_700_000_040, 0)
+			srv.SetTime(now)
+			l := tt.limiter(client, Config{Limit: 2, Window: time.Second})
+			for i, s := range tt.steps {
+				now = now.Add(s.advance)
+				srv.SetTime(now)
+				res := allow(t, l, "u1")
+				if res.Allowed != s.allowed || res.Remaining != s.remaining || res.RetryAfter != s.retryAfter {
+					t.Fatalf("step %d: result = %+v, want allowed %v, remaining %d, retry after %v",
+						i, res, s.allowed, s.remaining, s.retryAfter)
+				}
+				if ttl := srv.TTL(tt.key); ttl <= 0 {
+					t.Fatalf("step %d: %s TTL = %v, want it to expire", i, tt.key, ttl)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package response
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"mora/pkg/errors"
+)
+
+// registry holds the business error codes a service can return, so codes
+// stay unique and can be listed in API documentation
+var registry = struct {
+	sync.RWMutex
+	codes map[int]*errors.Error
+}{codes: make(map[int]*errors.Error)}
+
+func init() {
+	for _, e := range []*errors.Error{
+		errors.ErrBadRequest,
+		errors.ErrUnauthorized,
+		errors.ErrForbidden,
+		errors.ErrNotFound,
+		errors.ErrConflict,
+		errors.ErrPayloadTooLarge,
+		errors.ErrUnsupportedType,
+		errors.ErrTooManyRequests,
+		errors.ErrInternal,
+		errors.ErrUnavailable,
+	} {
+		MustRegister(e)
+	}
+}
+
+// Register creates a coded error and records its code, e.g.
+// ErrOrderNotFound = response.Register(40401, http.StatusNotFound, "order not found").
+// It panics when the code is already registered, so clashes surface at startup.
+func Register(code, httpStatus int, message string) *errors.Error {
+	e := errors.New(code, httpStatus, message)
+	MustRegister(e)
+	return e
+}
+
+// MustRegister records the code of an existing coded error; it panics on a
+// success code or one that is already registered
+func MustRegister(e *errors.Error) {
+	if e.Code == CodeOK {
+		panic("response: error code 0 is reserved for success")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if prev, ok := registry.codes[e.Code]; ok {
+		panic(fmt.Sprintf("response: error code %d already registered as %q", e.Code, prev.Message))
+	}
+	registry.codes[e.Code] = e
+}
+
+// Lookup returns the registered error for code
+func Lookup(code int) (*errors.Error, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	e, ok := registry.codes[code]
+	return e, ok
+}
+
+// Codes returns every registered error ordered by code
+func Codes() []*errors.Error {
+	registry.RLock()
+	list := make([]*errors.Error, 0, len(registry.codes))
+	for _, e := range registry.codes {
+		list = append(list, e)
+	}
+	registry.RUnlock()
+	sort.Slice(list, func(i, k int) bool { return list[i].Code < list[k].Code })
+	return list
+}
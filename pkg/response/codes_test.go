@@ -0,0 +1,46 @@
+package response
+
+import (
+	"net/http"
+	"testing"
+
+	"mora/pkg/errors"
+)
+
+func TestRegister(t *testing.T) {
+	errOrderNotFound := Register(40461, http.StatusNotFound, "order not found")
+
+	got, ok := Lookup(40461)
+	if !ok || got != errOrderNotFound {
+		t.Fatalf("Lookup(40461) = %v, %v", got, ok)
+	}
+	if _, ok := Lookup(errors.ErrNotFound.Code); !ok {
+		t.Error("predefined errors should be registered")
+	}
+
+	codes := Codes()
+	for i := 1; i < len(codes); i++ {
+		if codes[i-1].Code >= codes[i].Code {
+			t.Fatalf("Codes() not ordered: %d before %d", codes[i-1].Code, codes[i].Code)
+		}
+	}
+
+	tests := []struct {
+		name string
+		code int
+	}{
+		{name: "duplicate", code: 40461},
+		{name: "predefined", code: errors.ErrInternal.Code},
+		{name: "success", code: CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%d) should panic", tt.code)
+				}
+			}()
+			Register(tt.code, http.StatusBadRequest, "clash")
+		})
+	}
+}
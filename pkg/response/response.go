@@ -0,0 +1,128 @@
+// Package response defines the standard JSON envelope returned by Mora
+// services: {"code": 0, "message": "ok", "data": ..., "trace_id": "..."}.
+// Code 0 means success; any other code is a business error code.
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"mora/pkg/errors"
+	"mora/pkg/logger"
+)
+
+const (
+	// CodeOK is the business code of a successful response
+	CodeOK = 0
+	// MessageOK is the message of a successful response
+	MessageOK = "ok"
+)
+
+// Response is the standard response envelope
+type Response struct {
+	Code    int    `json:"code" example:"0"`
+	Message string `json:"message" example:"ok"`
+	Data    any    `json:"data,omitempty"`
+	TraceID string `json:"trace_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+}
+
+// Page is the data of a paginated response
+type Page struct {
+	Items    any   `json:"items"`
+	Total    int64 `json:"total" example:"42"`
+	Page     int   `json:"page" example:"1"`
+	PageSize int   `json:"page_size" example:"20"`
+}
+
+// Success builds a successful envelope carrying data
+func Success(data any) Response {
+	return Response{Code: CodeOK, Message: MessageOK, Data: data}
+}
+
+// PageOf builds a successful envelope carrying one page of items
+func PageOf(items any, total int64, page, pageSize int) Response {
+	return Success(Page{Items: items, Total: total, Page: page, PageSize: pageSize})
+}
+
+// Error builds an error envelope; a zero code is replaced with -1 so
+// clients never mistake an error for success
+func Error(code int, message string) Response {
+	if code == CodeOK {
+		code = -1
+	}
+	return Response{Code: code, Message: message}
+}
+
+// WithTraceID returns a copy of the envelope carrying traceID
+func (r Response) WithTraceID(traceID string) Response {
+	r.TraceID = traceID
+	return r
+}
+
+// WithContext fills in the trace ID stored in ctx, unless one is already set
+func (r Response) WithContext(ctx context.Context) Response {
+	if r.TraceID == "" {
+		r.TraceID = logger.GetTraceIDFromContext(ctx)
+	}
+	return r
+}
+
+// Write encodes resp as JSON with the given HTTP status, taking the trace
+// ID from the request context
+func Write(w http.ResponseWriter, r *http.Request, status int, resp Response) {
+	if r != nil {
+		resp = resp.WithContext(r.Context())
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// OK writes a 200 response carrying data
+func OK(w http.ResponseWriter, r *http.Request, data any) {
+	Write(w, r, http.StatusOK, Success(data))
+}
+
+// Created writes a 201 response carrying the created resource
+func Created(w http.ResponseWriter, r *http.Request, data any) {
+	Write(w, r, http.StatusCreated, Success(data))
+}
+
+// Paged writes a 200 response carrying one page of items
+func Paged(w http.ResponseWriter, r *http.Request, items any, total int64, page, pageSize int) {
+	Write(w, r, http.StatusOK, PageOf(items, total, page, pageSize))
+}
+
+// Fail writes an error response with the HTTP status and business code
+func Fail(w http.ResponseWriter, r *http.Request, status, code int, message string) {
+	Write(w, r, status, Error(code, message))
+}
+
+// ErrorData is implemented by errors that carry client-safe details, such
+// as the field errors of a failed validation, sent as the envelope's data
+type ErrorData interface {
+	ErrorData() any
+}
+
+// FromError builds the error envelope and HTTP status for err. Coded errors
+// from pkg/errors keep their code and user-safe message; any other error is
+// reported as an internal error without exposing its text.
+func FromError(err error) (int, Response) {
+	e := errors.FromError(err)
+	if e == nil {
+		return http.StatusOK, Success(nil)
+	}
+	resp := Error(e.Code, e.Message)
+	var data ErrorData
+	if errors.As(err, &data) {
+		resp.Data = data.ErrorData()
+	}
+	return e.HTTPStatus, resp
+}
+
+// Err writes the error envelope for err
+func Err(w http.ResponseWriter, r *http.Request, err error) {
+	status, resp := FromError(err)
+	Write(w, r, status, resp)
+}
@@ -0,0 +1,123 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mora/pkg/errors"
+	"mora/pkg/logger"
+)
+
+// fieldsError is a bad request carrying client-safe data
+type fieldsError []string
+
+func (e fieldsError) Error() string  { return "invalid fields" }
+func (e fieldsError) Unwrap() error  { return errors.ErrBadRequest }
+func (e fieldsError) ErrorData() any { return map[string]any{"fields": []string(e)} }
+
+func TestHelpers(t *testing.T) {
+	tests := []struct {
+		name       string
+		write      func(w http.ResponseWriter, r *http.Request)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "ok",
+			write:      func(w http.ResponseWriter, r *http.Request) { OK(w, r, map[string]string{"id": "1"}) },
+			wantStatus: http.StatusOK,
+			wantBody:   `{"code":0,"message":"ok","data":{"id":"1"},"trace_id":"trace-1"}`,
+		},
+		{
+			name:       "created",
+			write:      func(w http.ResponseWriter, r *http.Request) { Created(w, r, []int{1}) },
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"code":0,"message":"ok","data":[1],"trace_id":"trace-1"}`,
+		},
+		{
+			name:       "paged",
+			write:      func(w http.ResponseWriter, r *http.Request) { Paged(w, r, []string{"a", "b"}, 12, 2, 2) },
+			wantStatus: http.StatusOK,
+			wantBody:   `{"code":0,"message":"ok","data":{"items":["a","b"],"total":12,"page":2,"page_size":2},"trace_id":"trace-1"}`,
+		},
+		{
+			name: "fail",
+			write: func(w http.ResponseWriter, r *http.Request) {
+				Fail(w, r, http.StatusNotFound, 40401, "order not found")
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":40401,"message":"order not found","trace_id":"trace-1"}`,
+		},
+		{
+			name: "coded error",
+			write: func(w http.ResponseWriter, r *http.Request) {
+				Err(w, r, fmt.Errorf("load: %w", errors.ErrNotFound.WithDetail("order o-1")))
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":40400,"message":"resource not found","trace_id":"trace-1"}`,
+		},
+		{
+			name: "error with data",
+			write: func(w http.ResponseWriter, r *http.Request) {
+				Err(w, r, fieldsError{"email"})
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":40000,"message":"bad request","data":{"fields":["email"]},"trace_id":"trace-1"}`,
+		},
+		{
+			name:       "plain error",
+			write:      func(w http.ResponseWriter, r *http.Request) { Err(w, r, io.ErrUnexpectedEOF) },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"code":50000,"message":"internal server error","trace_id":"trace-1"}`,
+		},
+		{
+			name:       "fail with zero code",
+			write:      func(w http.ResponseWriter, r *http.Request) { Fail(w, r, http.StatusInternalServerError, 0, "boom") },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"code":-1,"message":"boom","trace_id":"trace-1"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r = r.WithContext(logger.WithTraceID(r.Context(), "trace-1"))
+			w := httptest.NewRecorder()
+
+			tt.write(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			var got, want any
+			json.Unmarshal(w.Body.Bytes(), &got)
+			json.Unmarshal([]byte(tt.wantBody), &want)
+			if !jsonEqual(got, want) {
+				t.Errorf("body = %s, want %s", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestWithContextKeepsExplicitTraceID(t *testing.T) {
+	ctx := logger.WithTraceID(t.Context(), "from-ctx")
+	if got := Success(nil).WithTraceID("explicit").WithContext(ctx); got.TraceID != "explicit" {
+		t.Errorf("TraceID = %q, want explicit", got.TraceID)
+	}
+	if got := Success(nil).WithContext(ctx); got.TraceID != "from-ctx" {
+		t.Errorf("TraceID = %q, want from-ctx", got.TraceID)
+	}
+}
+
+func jsonEqual(a, b any) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
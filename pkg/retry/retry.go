@@ -0,0 +1,151 @@
+// Package retry runs operations again after transient failures, waiting
+// with exponential backoff and jitter between attempts. Every attempt can
+// run under its own timeout, and the caller's context cancels the lot.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Config configures retries
+type Config struct {
+	// MaxAttempts includes the first try
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts" env:"MAX_ATTEMPTS"`
+	// Backoff is the first retry delay, multiplied by Multiplier on each
+	// further attempt up to MaxBackoff
+	Backoff    time.Duration `json:"backoff" yaml:"backoff" env:"BACKOFF"`
+	MaxBackoff time.Duration `json:"max_backoff" yaml:"max_backoff" env:"MAX_BACKOFF"`
+	Multiplier float64       `json:"multiplier" yaml:"multiplier" env:"MULTIPLIER"`
+	// Jitter randomizes each delay by up to this share of it, 0-1, so
+	// clients that failed together do not retry together
+	Jitter float64 `json:"jitter" yaml:"jitter" env:"JITTER"`
+	// AttemptTimeout, when set, bounds every attempt
+	AttemptTimeout time.Duration `json:"attempt_timeout" yaml:"attempt_timeout" env:"ATTEMPT_TIMEOUT"`
+
+	// Retryable decides whether an error is worth retrying; by default
+	// every error is except those marked Permanent
+	Retryable func(err error) bool `json:"-" yaml:"-"`
+	// OnRetry is called before waiting to retry, e.g. to log the failure
+	OnRetry func(attempt int, err error, delay time.Duration) `json:"-" yaml:"-"`
+}
+
+// DefaultConfig returns default retry configuration
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts: 3,
+		Backoff:     100 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.2,
+	}
+}
+
+// normalize fills unset fields with defaults
+func (c Config) normalize() Config {
+	defaults := DefaultConfig()
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = defaults.MaxAttempts
+	}
+	if c.Backoff < 0 {
+		c.Backoff = 0
+	}
+	if c.MaxBackoff < c.Backoff {
+		c.MaxBackoff = c.Backoff
+	}
+	if c.Multiplier < 1 {
+		c.Multiplier = 1
+	}
+	c.Jitter = min(max(c.Jitter, 0), 1)
+	return c
+}
+
+// Delay returns the wait before retry number n, counting from 1, without
+// jitter
+func (c Config) Delay(n int) time.Duration {
+	c = c.normalize()
+	d := float64(c.Backoff)
+	for i := 1; i < n && d < float64(c.MaxBackoff); i++ {
+		d *= c.Multiplier
+	}
+	return min(time.Duration(d), c.MaxBackoff)
+}
+
+// jittered spreads d by up to Jitter of it in either direction
+func (c Config) jittered(d time.Duration) time.Duration {
+	if c.Jitter == 0 || d == 0 {
+		return d
+	}
+	spread := float64(d) * c.Jitter
+	return time.Duration(float64(d) - spread + rand.Float64()*2*spread)
+}
+
+// permanentError marks an error that must not be retried
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not retryable; Do returns err itself
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// Do runs fn until it succeeds, fails with an error that is not retryable
+// or runs out of attempts, and returns fn's last error. When ctx ends while
+// waiting, the error wraps both ctx.Err() and fn's last error.
+func Do(ctx context.Context, cfg Config, fn func(ctx context.Context) error) error {
+	_, err := DoValue(ctx, cfg, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// DoValue is Do for operations returning a value
+func DoValue[T any](ctx context.Context, cfg Config, fn func(ctx context.Context) (T, error)) (T, error) {
+	cfg = cfg.normalize()
+	for attempt := 1; ; attempt++ {
+		v, err := runAttempt(ctx, cfg, fn)
+		if err == nil {
+			return v, nil
+		}
+		var permanent permanentError
+		if errors.As(err, &permanent) {
+			return v, permanent.err
+		}
+		if attempt >= cfg.MaxAttempts || (cfg.Retryable != nil && !cfg.Retryable(err)) {
+			return v, err
+		}
+		if ctx.Err() != nil {
+			return v, fmt.Errorf("retry: %w: %w", ctx.Err(), err)
+		}
+
+		delay := cfg.jittered(cfg.Delay(attempt))
+		if cfg.OnRetry != nil {
+			cfg.OnRetry(attempt, err, delay)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return v, fmt.Errorf("retry: %w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// runAttempt runs fn under the per-attempt timeout
+func runAttempt[T any](ctx context.Context, cfg Config, fn func(ctx context.Context) (T, error)) (T, error) {
+	if cfg.AttemptTimeout <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, cfg.AttemptTimeout)
+	defer cancel()
+	return fn(ctx)
+}
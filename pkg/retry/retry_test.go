@@ -0,0 +1,134 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("transient")
+
+// fast retries immediately
+func fast(attempts int) Config {
+	return Config{MaxAttempts: attempts, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+}
+
+func TestDo(t *testing.T) {
+	errFatal := errors.New("fatal")
+	tests := []struct {
+		name      string
+		cfg       Config
+		results   []error
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "first try", cfg: fast(3), results: []error{nil}, wantCalls: 1},
+		{name: "succeeds after retries", cfg: fast(3), results: []error{errTransient, errTransient, nil}, wantCalls: 3},
+		{name: "runs out of attempts", cfg: fast(2), results: []error{errTransient, errTransient, nil}, wantErr: errTransient, wantCalls: 2},
+		{name: "permanent error", cfg: fast(3), results: []error{Permanent(errFatal)}, wantErr: errFatal, wantCalls: 1},
+		{
+			name:      "not retryable",
+			cfg:       Config{MaxAttempts: 3, Retryable: func(err error) bool { return errors.Is(err, errTransient) }},
+			results:   []error{errTransient, errFatal, nil},
+			wantErr:   errFatal,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Do(context.Background(), tt.cfg, func(ctx context.Context) error {
+				calls++
+				return tt.results[calls-1]
+			})
+			if err != tt.wantErr {
+				t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDoValue(t *testing.T) {
+	var retries []int
+	cfg := fast(3)
+	cfg.OnRetry = func(attempt int, err error, delay time.Duration) { retries = append(retries, attempt) }
+
+	calls := 0
+	v, err := DoValue(context.Background(), cfg, func(ctx context.Context) (string, error) {
+		if calls++; calls < 3 {
+			return "", errTransient
+		}
+		return "ok", nil
+	})
+	if v != "ok" || err != nil {
+		t.Fatalf("DoValue() = %q, %v", v, err)
+	}
+	if len(retries) != 2 || retries[0] != 1 || retries[1] != 2 {
+		t.Errorf("OnRetry attempts = %v, want [1 2]", retries)
+	}
+}
+
+func TestDoContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := Do(ctx, Config{MaxAttempts: 10, Backoff: time.Hour}, func(ctx context.Context) error {
+		return errTransient
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errTransient) {
+		t.Errorf("Do() error = %v, want deadline and last error", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Do() kept waiting after the context ended")
+	}
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	cfg := fast(2)
+	cfg.AttemptTimeout = 10 * time.Millisecond
+
+	calls := 0
+	err := Do(context.Background(), cfg, func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("Do() = %v after %d calls, want retry after the timed-out attempt", err, calls)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	cfg := Config{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	tests := []struct {
+		n    int
+		want time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{50, time.Second},
+	}
+	for _, tt := range tests {
+		if got := cfg.Delay(tt.n); got != tt.want {
+			t.Errorf("Delay(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+
+	cfg.Jitter = 0.5
+	cfg = cfg.normalize()
+	for range 100 {
+		if d := cfg.jittered(time.Second); d < 500*time.Millisecond || d > 1500*time.Millisecond {
+			t.Fatalf("jittered delay %v outside ±50%%", d)
+		}
+	}
+}
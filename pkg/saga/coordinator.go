@@ -0,0 +1,412 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"mora/pkg/mq"
+	"mora/pkg/utils"
+)
+
+// Config configures a Coordinator
+type Config struct {
+	// ReplyTopic is where participants send replies; consume it with HandleReply
+	ReplyTopic string `json:"reply_topic" yaml:"reply_topic" env:"REPLY_TOPIC"`
+	// PollInterval is how often Run looks for timed-out steps and due retries
+	PollInterval time.Duration `json:"poll_interval" yaml:"poll_interval" env:"POLL_INTERVAL"`
+	// BatchSize bounds the instances handled per poll
+	BatchSize int `json:"batch_size" yaml:"batch_size" env:"BATCH_SIZE"`
+	// StepTimeout is the default time a step attempt may take
+	StepTimeout time.Duration `json:"step_timeout" yaml:"step_timeout" env:"STEP_TIMEOUT"`
+	// MaxAttempts is the default number of executions of a step
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts" env:"MAX_ATTEMPTS"`
+	// CompensateAttempts is the number of executions of a compensation
+	// before the saga is marked failed
+	CompensateAttempts int `json:"compensate_attempts" yaml:"compensate_attempts" env:"COMPENSATE_ATTEMPTS"`
+	// Backoff is the delay before the first retry, doubled for each further one
+	Backoff    time.Duration `json:"backoff" yaml:"backoff" env:"BACKOFF"`
+	MaxBackoff time.Duration `json:"max_backoff" yaml:"max_backoff" env:"MAX_BACKOFF"`
+
+	// OnTransition is called with every saved state of an instance
+	OnTransition func(Instance) `json:"-" yaml:"-"`
+	// OnError is called with errors of background polls
+	OnError func(error) `json:"-" yaml:"-"`
+}
+
+// DefaultConfig returns default coordinator configuration
+func DefaultConfig() Config {
+	return Config{
+		ReplyTopic:         "saga.replies",
+		PollInterval:       time.Second,
+		BatchSize:          100,
+		StepTimeout:        30 * time.Second,
+		MaxAttempts:        3,
+		CompensateAttempts: 10,
+		Backoff:            time.Second,
+		MaxBackoff:         time.Minute,
+	}
+}
+
+// Coordinator runs sagas
+type Coordinator struct {
+	store    Store
+	producer mq.Producer
+	cfg      Config
+	now      func() time.Time
+
+	mu    sync.RWMutex
+	sagas map[string]Definition
+}
+
+// NewCoordinator creates a coordinator that persists instances in store and
+// sends commands with producer; producer may be nil when every step is local
+func NewCoordinator(store Store, producer mq.Producer, cfg Config) *Coordinator {
+	defaults := DefaultConfig()
+	if cfg.ReplyTopic == "" {
+		cfg.ReplyTopic = defaults.ReplyTopic
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaults.PollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaults.BatchSize
+	}
+	if cfg.StepTimeout <= 0 {
+		cfg.StepTimeout = defaults.StepTimeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaults.MaxAttempts
+	}
+	if cfg.CompensateAttempts <= 0 {
+		cfg.CompensateAttempts = defaults.CompensateAttempts
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaults.Backoff
+	}
+	if cfg.MaxBackoff < cfg.Backoff {
+		cfg.MaxBackoff = max(defaults.MaxBackoff, cfg.Backoff)
+	}
+	return &Coordinator{
+		store:    store,
+		producer: producer,
+		cfg:      cfg,
+		now:      time.Now,
+		sagas:    make(map[string]Definition),
+	}
+}
+
+// Register adds a saga definition
+func (c *Coordinator) Register(def Definition) error {
+	if err := def.validate(); err != nil {
+		return err
+	}
+	for _, s := range def.Steps {
+		if (s.Topic != "" || s.CompensateTopic != "") && c.producer == nil {
+			return fmt.Errorf("saga: %s step %s is remote but the coordinator has no producer", def.Name, s.Name)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.sagas[def.Name]; ok {
+		return fmt.Errorf("saga: %s is already registered", def.Name)
+	}
+	c.sagas[def.Name] = def
+	return nil
+}
+
+// definition returns a registered definition
+func (c *Coordinator) definition(name string) (Definition, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	def, ok := c.sagas[name]
+	return def, ok
+}
+
+// Start persists a new instance of the named saga and dispatches its first
+// step. Local steps run before Start returns; the returned instance shows
+// how far the saga got.
+func (c *Coordinator) Start(ctx context.Context, name string, data Data) (*Instance, error) {
+	def, ok := c.definition(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSaga, name)
+	}
+	id, err := utils.GenerateULID()
+	if err != nil {
+		return nil, fmt.Errorf("saga: failed to generate id: %w", err)
+	}
+	now := c.now()
+	inst := &Instance{
+		ID:        id,
+		Saga:      name,
+		Status:    StatusRunning,
+		Data:      data.clone(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := c.store.Create(ctx, inst); err != nil {
+		return nil, fmt.Errorf("saga: failed to create instance: %w", err)
+	}
+	if err := c.dispatch(ctx, def, inst); err != nil {
+		return inst, err
+	}
+	return inst, nil
+}
+
+// Get returns the current state of an instance
+func (c *Coordinator) Get(ctx context.Context, id string) (*Instance, error) {
+	return c.store.Get(ctx, id)
+}
+
+// HandleReply is the mq.Handler for the reply topic. Duplicate and late
+// replies are ignored; an error means the instance changed concurrently and
+// the reply should be delivered again.
+func (c *Coordinator) HandleReply(ctx context.Context, msg *mq.Message) error {
+	inst, err := c.store.Get(ctx, msg.Header(HeaderSagaID))
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("saga: failed to load instance: %w", err)
+	}
+	def, ok := c.definition(inst.Saga)
+	if !ok {
+		return nil
+	}
+	step, _ := strconv.Atoi(msg.Header(HeaderStep))
+	attempt, _ := strconv.Atoi(msg.Header(HeaderAttempt))
+	if inst.Status.Done() || !inst.Awaiting || inst.Step != step || inst.Attempt != attempt ||
+		Action(msg.Header(HeaderAction)) != actionOf(inst) {
+		return nil
+	}
+
+	r := result{outcome: msg.Header(HeaderOutcome), err: msg.Header(HeaderError)}
+	if r.outcome == outcomeOK && len(msg.Value) > 0 {
+		if err := json.Unmarshal(msg.Value, &r.data); err != nil {
+			r = result{outcome: outcomeError, err: "invalid reply data: " + err.Error()}
+		}
+	}
+	return c.advance(ctx, def, inst, r)
+}
+
+// Run polls for timed-out steps and due retries until ctx is cancelled;
+// run it on every coordinator instance, e.g. with app.Background
+func (c *Coordinator) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.poll(ctx); err != nil && c.cfg.OnError != nil {
+				c.cfg.OnError(err)
+			}
+		}
+	}
+}
+
+// poll times out in-flight attempts past their deadline and dispatches due retries
+func (c *Coordinator) poll(ctx context.Context) error {
+	due, err := c.store.Due(ctx, c.now(), c.cfg.BatchSize)
+	if err != nil {
+		return fmt.Errorf("saga: failed to load due instances: %w", err)
+	}
+	var errs []error
+	for _, inst := range due {
+		def, ok := c.definition(inst.Saga)
+		if !ok {
+			continue
+		}
+		if inst.Awaiting {
+			err = c.advance(ctx, def, inst, result{outcome: outcomeError, err: "step timed out", timeout: true})
+		} else {
+			err = c.dispatch(ctx, def, inst)
+		}
+		// A conflict means a reply or another coordinator got there first
+		if err != nil && !errors.Is(err, ErrConflict) {
+			errs = append(errs, fmt.Errorf("saga %s: %w", inst.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// result is the outcome of one attempt
+type result struct {
+	outcome string
+	err     string
+	data    Data
+	// timeout is set when the effect of the attempt is unknown
+	timeout bool
+}
+
+// resultOf converts the return values of a local function
+func resultOf(data Data, err error) result {
+	switch {
+	case err == nil:
+		return result{outcome: outcomeOK, data: data}
+	case IsAborted(err):
+		return result{outcome: outcomeAborted, err: err.Error()}
+	}
+	return result{outcome: outcomeError, err: err.Error()}
+}
+
+// actionOf returns the action the instance's current step is awaiting
+func actionOf(inst *Instance) Action {
+	if inst.Status == StatusCompensating {
+		return ActionCompensate
+	}
+	return ActionExecute
+}
+
+// dispatch starts the next attempt of the current step or compensation
+func (c *Coordinator) dispatch(ctx context.Context, def Definition, inst *Instance) error {
+	step := def.Steps[inst.Step]
+	timeout := step.Timeout
+	if timeout <= 0 {
+		timeout = c.cfg.StepTimeout
+	}
+	inst.Attempt++
+	inst.Awaiting = true
+	inst.Deadline = c.now().Add(timeout)
+	// Save before sending so a fast reply finds the attempt it answers
+	if err := c.save(ctx, inst); err != nil {
+		return err
+	}
+
+	action := actionOf(inst)
+	fn, topic := step.Execute, step.Topic
+	if action == ActionCompensate {
+		fn, topic = step.Compensate, step.CompensateTopic
+	}
+	if fn != nil {
+		stepCtx, cancel := context.WithTimeout(ctx, timeout)
+		data, err := fn(stepCtx, inst.Data.clone())
+		cancel()
+		return c.advance(ctx, def, inst, resultOf(data, err))
+	}
+
+	msg, err := c.command(inst, step, topic, action)
+	if err == nil {
+		err = c.producer.Publish(ctx, msg)
+	}
+	if err != nil {
+		return c.advance(ctx, def, inst, result{outcome: outcomeError, err: "failed to send command: " + err.Error()})
+	}
+	return nil
+}
+
+// command builds the message asking a participant to run an action
+func (c *Coordinator) command(inst *Instance, step Step, topic string, action Action) (*mq.Message, error) {
+	value, err := json.Marshal(inst.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &mq.Message{
+		Topic: topic,
+		Key:   inst.ID,
+		Value: value,
+		Headers: map[string]string{
+			HeaderSagaID:   inst.ID,
+			HeaderSagaName: inst.Saga,
+			HeaderStep:     strconv.Itoa(inst.Step),
+			HeaderStepName: step.Name,
+			HeaderAction:   string(action),
+			HeaderAttempt:  strconv.Itoa(inst.Attempt),
+			HeaderReplyTo:  c.cfg.ReplyTopic,
+		},
+	}, nil
+}
+
+// advance applies the result of the in-flight attempt
+func (c *Coordinator) advance(ctx context.Context, def Definition, inst *Instance, r result) error {
+	inst.Awaiting = false
+	step := def.Steps[inst.Step]
+
+	if inst.Status == StatusCompensating {
+		switch {
+		case r.outcome == outcomeOK:
+			inst.Step--
+			return c.compensate(ctx, def, inst)
+		case inst.Attempt < c.cfg.CompensateAttempts:
+			return c.retry(ctx, inst)
+		}
+		inst.Status = StatusFailed
+		inst.Deadline = time.Time{}
+		inst.Error = fmt.Sprintf("%s; compensating %s: %s", inst.Error, step.Name, r.err)
+		return c.save(ctx, inst)
+	}
+
+	maxAttempts := step.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = c.cfg.MaxAttempts
+	}
+	switch {
+	case r.outcome == outcomeOK:
+		for k, v := range r.data {
+			inst.Data[k] = v
+		}
+		inst.Step++
+		inst.Attempt = 0
+		if inst.Step == len(def.Steps) {
+			inst.Status = StatusCompleted
+			inst.Deadline = time.Time{}
+			return c.save(ctx, inst)
+		}
+		return c.dispatch(ctx, def, inst)
+	case r.outcome == outcomeError && inst.Attempt < maxAttempts:
+		return c.retry(ctx, inst)
+	}
+
+	inst.Status = StatusCompensating
+	inst.Error = fmt.Sprintf("%s: %s", step.Name, r.err)
+	// A step that timed out may have taken effect, so it is undone too
+	if !r.timeout {
+		inst.Step--
+	}
+	return c.compensate(ctx, def, inst)
+}
+
+// compensate dispatches the compensation of the current step, skipping steps
+// without one, or marks the saga compensated when none are left
+func (c *Coordinator) compensate(ctx context.Context, def Definition, inst *Instance) error {
+	for inst.Step >= 0 && !def.Steps[inst.Step].compensable() {
+		inst.Step--
+	}
+	inst.Attempt = 0
+	if inst.Step < 0 {
+		inst.Status = StatusCompensated
+		inst.Deadline = time.Time{}
+		return c.save(ctx, inst)
+	}
+	return c.dispatch(ctx, def, inst)
+}
+
+// retry schedules the next attempt after an exponential backoff
+func (c *Coordinator) retry(ctx context.Context, inst *Instance) error {
+	backoff := c.cfg.Backoff
+	for i := 1; i < inst.Attempt && backoff < c.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	inst.Deadline = c.now().Add(min(backoff, c.cfg.MaxBackoff))
+	return c.save(ctx, inst)
+}
+
+// save persists inst and reports the transition
+func (c *Coordinator) save(ctx context.Context, inst *Instance) error {
+	inst.UpdatedAt = c.now()
+	if err := c.store.Update(ctx, inst); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return err
+		}
+		return fmt.Errorf("saga: failed to save instance: %w", err)
+	}
+	if c.cfg.OnTransition != nil {
+		c.cfg.OnTransition(*inst.clone())
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"mora/pkg/mq"
+)
+
+// Command is a step command delivered to a participant
+type Command struct {
+	SagaID string
+	Saga   string
+	Step   string
+	Action Action
+	// Attempt counts deliveries of this step's action, starting at 1
+	Attempt int
+	Data    Data
+}
+
+// Key identifies the command across retries, for idempotent participants
+func (c *Command) Key() string {
+	return c.SagaID + ":" + c.Step + ":" + string(c.Action)
+}
+
+// ParticipantFunc runs a command, returning data to merge into the saga.
+// Return an Abort error to reject the step permanently; other errors are
+// retried by the coordinator.
+type ParticipantFunc func(ctx context.Context, cmd *Command) (Data, error)
+
+// Participant returns the mq.Handler of a participant service's command
+// topic: it runs execute or compensate and publishes the reply with producer.
+// A nil compensate acknowledges compensations without doing anything.
+// Messages without saga headers are ignored.
+func Participant(producer mq.Producer, execute, compensate ParticipantFunc) mq.Handler {
+	return func(ctx context.Context, msg *mq.Message) error {
+		replyTo := msg.Header(HeaderReplyTo)
+		if msg.Header(HeaderSagaID) == "" || replyTo == "" {
+			return nil
+		}
+		attempt, _ := strconv.Atoi(msg.Header(HeaderAttempt))
+		cmd := &Command{
+			SagaID:  msg.Header(HeaderSagaID),
+			Saga:    msg.Header(HeaderSagaName),
+			Step:    msg.Header(HeaderStepName),
+			Action:  Action(msg.Header(HeaderAction)),
+			Attempt: attempt,
+		}
+
+		var r result
+		if err := json.Unmarshal(msg.Value, &cmd.Data); err != nil {
+			r = result{outcome: outcomeError, err: "invalid command data: " + err.Error()}
+		} else {
+			if cmd.Data == nil {
+				cmd.Data = Data{}
+			}
+			fn := execute
+			if cmd.Action == ActionCompensate {
+				fn = compensate
+			}
+			if fn == nil {
+				r = result{outcome: outcomeOK}
+			} else {
+				r = resultOf(fn(ctx, cmd))
+			}
+		}
+
+		reply := &mq.Message{
+			Topic: replyTo,
+			Key:   cmd.SagaID,
+			Headers: map[string]string{
+				HeaderSagaID:   cmd.SagaID,
+				HeaderSagaName: cmd.Saga,
+				HeaderStep:     msg.Header(HeaderStep),
+				HeaderStepName: cmd.Step,
+				HeaderAction:   string(cmd.Action),
+				HeaderAttempt:  msg.Header(HeaderAttempt),
+				HeaderOutcome:  r.outcome,
+			},
+		}
+		if r.err != "" {
+			reply.SetHeader(HeaderError, r.err)
+		}
+		if len(r.data) > 0 {
+			value, err := json.Marshal(r.data)
+			if err != nil {
+				return fmt.Errorf("saga: failed to encode reply data: %w", err)
+			}
+			reply.Value = value
+		}
+		if err := producer.Publish(ctx, reply); err != nil {
+			return fmt.Errorf("saga: failed to send reply: %w", err)
+		}
+		return nil
+	}
+}
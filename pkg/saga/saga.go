@@ -0,0 +1,210 @@
+// Package saga coordinates distributed transactions as sagas: ordered
+// steps, each optionally paired with a compensation that undoes it. The
+// coordinator persists every saga's state in a Store, sends step commands
+// to participant services through pkg/mq and advances on their replies.
+// Failed and timed-out steps are retried with backoff; a step that keeps
+// failing triggers the compensations of completed steps in reverse order.
+//
+// Commands are delivered at least once, so participants must be idempotent,
+// for example by recording Command.Key alongside their local change.
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// Status is the state of a saga instance
+type Status string
+
+const (
+	// StatusRunning means steps are being executed
+	StatusRunning Status = "running"
+	// StatusCompensating means a step failed and completed steps are being undone
+	StatusCompensating Status = "compensating"
+	// StatusCompleted means every step succeeded
+	StatusCompleted Status = "completed"
+	// StatusCompensated means a step failed and every completed step was undone
+	StatusCompensated Status = "compensated"
+	// StatusFailed means a compensation kept failing; the saga needs manual repair
+	StatusFailed Status = "failed"
+)
+
+// Done reports whether the status is final
+func (s Status) Done() bool {
+	return s == StatusCompleted || s == StatusCompensated || s == StatusFailed
+}
+
+// Action is what a command asks a participant to do
+type Action string
+
+const (
+	ActionExecute    Action = "execute"
+	ActionCompensate Action = "compensate"
+)
+
+// Message headers of commands and replies; replies are matched to their
+// command by step index, the step name is informational
+const (
+	HeaderSagaID   = "x-saga-id"
+	HeaderSagaName = "x-saga-name"
+	HeaderStep     = "x-saga-step"
+	HeaderStepName = "x-saga-step-name"
+	HeaderAction   = "x-saga-action"
+	HeaderAttempt  = "x-saga-attempt"
+	HeaderReplyTo  = "x-saga-reply-to"
+	HeaderOutcome  = "x-saga-outcome"
+	HeaderError    = "x-saga-error"
+)
+
+// Reply outcomes
+const (
+	outcomeOK = "ok"
+	// outcomeError is a transient failure, the step is retried
+	outcomeError = "error"
+	// outcomeAborted is a permanent rejection, the saga compensates at once
+	outcomeAborted = "aborted"
+)
+
+var (
+	// ErrNotFound is returned for an unknown saga instance
+	ErrNotFound = errors.New("saga: instance not found")
+	// ErrConflict is returned when an instance was changed concurrently
+	ErrConflict = errors.New("saga: instance changed concurrently")
+	// ErrUnknownSaga is returned when starting a saga that is not registered
+	ErrUnknownSaga = errors.New("saga: unknown saga")
+)
+
+// abortError marks a permanent step failure
+type abortError struct {
+	err error
+}
+
+func (e *abortError) Error() string { return e.err.Error() }
+func (e *abortError) Unwrap() error { return e.err }
+
+// Abort marks err as a permanent failure, such as insufficient funds or stock;
+// the step is not retried and the saga starts compensating
+func Abort(err error) error {
+	return &abortError{err: err}
+}
+
+// IsAborted reports whether err was marked with Abort
+func IsAborted(err error) bool {
+	var a *abortError
+	return errors.As(err, &a)
+}
+
+// Data is the JSON object a saga carries from step to step. Values returned
+// by a step, such as a payment ID its compensation needs, are merged into it.
+type Data map[string]any
+
+// Decode unmarshals the data into v, typically a struct
+func (d Data) Decode(v any) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("saga: failed to encode data: %w", err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("saga: failed to decode data: %w", err)
+	}
+	return nil
+}
+
+// clone returns a shallow copy that is never nil
+func (d Data) clone() Data {
+	c := make(Data, len(d))
+	maps.Copy(c, d)
+	return c
+}
+
+// LocalFunc runs a step or compensation in-process
+type LocalFunc func(ctx context.Context, data Data) (Data, error)
+
+// Step is one step of a saga. A step runs either in-process with Execute or
+// in a participant service listening on Topic.
+type Step struct {
+	Name string
+	// Execute runs the step in-process
+	Execute LocalFunc
+	// Topic receives the step's commands for a remote participant
+	Topic string
+
+	// Compensate undoes the step in-process
+	Compensate LocalFunc
+	// CompensateTopic receives compensation commands, usually the same topic
+	// as Topic; steps without Compensate or CompensateTopic are not undone
+	CompensateTopic string
+
+	// Timeout bounds one attempt, defaulting to Config.StepTimeout
+	Timeout time.Duration
+	// MaxAttempts bounds executions of the step, defaulting to Config.MaxAttempts
+	MaxAttempts int
+}
+
+// compensable reports whether the step has a compensation
+func (s Step) compensable() bool {
+	return s.Compensate != nil || s.CompensateTopic != ""
+}
+
+// Definition describes a saga
+type Definition struct {
+	Name  string
+	Steps []Step
+}
+
+// validate checks that the definition can be run
+func (d Definition) validate() error {
+	if d.Name == "" {
+		return errors.New("saga: definition name is required")
+	}
+	if len(d.Steps) == 0 {
+		return fmt.Errorf("saga: %s has no steps", d.Name)
+	}
+	for i, s := range d.Steps {
+		if s.Name == "" {
+			return fmt.Errorf("saga: %s step %d has no name", d.Name, i)
+		}
+		if (s.Execute == nil) == (s.Topic == "") {
+			return fmt.Errorf("saga: %s step %s needs exactly one of Execute and Topic", d.Name, s.Name)
+		}
+		if s.Compensate != nil && s.CompensateTopic != "" {
+			return fmt.Errorf("saga: %s step %s has both Compensate and CompensateTopic", d.Name, s.Name)
+		}
+	}
+	return nil
+}
+
+// Instance is the persisted state of one saga run
+type Instance struct {
+	ID     string
+	Saga   string
+	Status Status
+	// Step is the index of the step being executed or compensated
+	Step int
+	// Attempt counts executions of the current step or compensation
+	Attempt int
+	// Awaiting is set while the command of Attempt is in flight
+	Awaiting bool
+	// Deadline is when the in-flight attempt times out or, when not
+	// awaiting, when the next attempt is due; zero once the saga is done
+	Deadline time.Time
+	Data     Data
+	// Error is the failure that made the saga compensate or fail
+	Error string
+	// Version increases with every update, for optimistic locking
+	Version   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// clone returns a copy that shares no data with inst
+func (inst *Instance) clone() *Instance {
+	c := *inst
+	c.Data = inst.Data.clone()
+	return &c
+}
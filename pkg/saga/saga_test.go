@@ -0,0 +1,422 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"mora/pkg/mq"
+)
+
+// bus is an in-memory mq.Producer that queues messages until drained
+type bus struct {
+	mu       sync.Mutex
+	queue    []*mq.Message
+	handlers map[string]mq.Handler
+}
+
+func newBus() *bus {
+	return &bus{handlers: make(map[string]mq.Handler)}
+}
+
+func (b *bus) Publish(ctx context.Context, msgs ...*mq.Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.queue = append(b.queue, msgs...)
+	return nil
+}
+
+func (b *bus) Close() error { return nil }
+
+// drain delivers queued messages, including ones published while draining
+func (b *bus) drain(t *testing.T) {
+	t.Helper()
+	for {
+		b.mu.Lock()
+		if len(b.queue) == 0 {
+			b.mu.Unlock()
+			return
+		}
+		msg := b.queue[0]
+		b.queue = b.queue[1:]
+		h := b.handlers[msg.Topic]
+		b.mu.Unlock()
+		if h == nil {
+			continue
+		}
+		if err := h(context.Background(), msg); err != nil {
+			t.Fatalf("handling %s: %v", msg.Topic, err)
+		}
+	}
+}
+
+// drop discards queued messages, as if they were lost
+func (b *bus) drop() {
+	b.mu.Lock()
+	b.queue = nil
+	b.mu.Unlock()
+}
+
+// clock is a manually advanced time source
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// recorder records the calls of steps and compensations
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) add(call string) {
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	r.mu.Unlock()
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.calls)
+}
+
+// local returns a step function that records name and returns out or err
+func (r *recorder) local(name string, out Data, err error) LocalFunc {
+	return func(ctx context.Context, data Data) (Data, error) {
+		r.add(name)
+		return out, err
+	}
+}
+
+// participant returns a participant function that records name
+func (r *recorder) participant(name string, fn func(cmd *Command) (Data, error)) ParticipantFunc {
+	return func(ctx context.Context, cmd *Command) (Data, error) {
+		r.add(name)
+		if fn == nil {
+			return nil, nil
+		}
+		return fn(cmd)
+	}
+}
+
+func newTestCoordinator(t *testing.T, b *bus, clk *clock) *Coordinator {
+	t.Helper()
+	var producer mq.Producer
+	if b != nil {
+		producer = b
+	}
+	cfg := DefaultConfig()
+	cfg.Backoff = time.Second
+	c := NewCoordinator(NewMemoryStore(), producer, cfg)
+	if clk != nil {
+		c.now = clk.Now
+	}
+	if b != nil {
+		b.handlers[cfg.ReplyTopic] = c.HandleReply
+	}
+	return c
+}
+
+func TestLocalSagaCompletes(t *testing.T) {
+	rec := &recorder{}
+	c := newTestCoordinator(t, nil, nil)
+	err := c.Register(Definition{Name: "signup", Steps: []Step{
+		{Name: "user", Execute: rec.local("user", Data{"user_id": "u-1"}, nil)},
+		{Name: "welcome", Execute: func(ctx context.Context, data Data) (Data, error) {
+			rec.add("welcome:" + data["user_id"].(string))
+			return nil, nil
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	inst, err := c.Start(context.Background(), "signup", Data{"email": "a@example.com"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if inst.Status != StatusCompleted {
+		t.Fatalf("Status = %s, want completed", inst.Status)
+	}
+	if got := rec.get(); !slices.Equal(got, []string{"user", "welcome:u-1"}) {
+		t.Errorf("calls = %v", got)
+	}
+	stored, err := c.Get(context.Background(), inst.ID)
+	if err != nil || stored.Data["email"] != "a@example.com" || stored.Data["user_id"] != "u-1" || !stored.Deadline.IsZero() {
+		t.Errorf("Get() = %+v, %v", stored, err)
+	}
+}
+
+// orderSaga registers the order demo: a local order step followed by
+// payment and inventory participants
+func orderSaga(t *testing.T, c *Coordinator, b *bus, rec *recorder, inventory func(cmd *Command) (Data, error)) {
+	t.Helper()
+	err := c.Register(Definition{Name: "create-order", Steps: []Step{
+		{
+			Name:       "order",
+			Execute:    rec.local("order", Data{"order_status": "pending"}, nil),
+			Compensate: rec.local("cancel-order", nil, nil),
+		},
+		{Name: "payment", Topic: "payment", CompensateTopic: "payment"},
+		{Name: "inventory", Topic: "inventory", CompensateTopic: "inventory"},
+		{Name: "confirm", Execute: rec.local("confirm", nil, nil)},
+	}})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	b.handlers["payment"] = Participant(b,
+		rec.participant("charge", func(cmd *Command) (Data, error) {
+			return Data{"payment_id": "pay-" + cmd.SagaID}, nil
+		}),
+		rec.participant("refund", func(cmd *Command) (Data, error) {
+			if cmd.Data["payment_id"] != "pay-"+cmd.SagaID {
+				return nil, fmt.Errorf("refund without payment id: %v", cmd.Data)
+			}
+			return nil, nil
+		}))
+	b.handlers["inventory"] = Participant(b, rec.participant("reserve", inventory), rec.participant("release", nil))
+}
+
+func TestRemoteSagaCompletes(t *testing.T) {
+	b, rec := newBus(), &recorder{}
+	c := newTestCoordinator(t, b, nil)
+	orderSaga(t, c, b, rec, nil)
+
+	inst, err := c.Start(context.Background(), "create-order", Data{"amount": 100})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if inst.Status != StatusRunning || inst.Step != 1 || !inst.Awaiting {
+		t.Fatalf("after Start = %+v, want awaiting payment", inst)
+	}
+	b.drain(t)
+
+	got, _ := c.Get(context.Background(), inst.ID)
+	if got.Status != StatusCompleted {
+		t.Fatalf("Status = %s (%s), want completed", got.Status, got.Error)
+	}
+	if calls := rec.get(); !slices.Equal(calls, []string{"order", "charge", "reserve", "confirm"}) {
+		t.Errorf("calls = %v", calls)
+	}
+	if got.Data["payment_id"] != "pay-"+inst.ID {
+		t.Errorf("Data = %v, want payment id", got.Data)
+	}
+}
+
+func TestAbortCompensatesCompletedSteps(t *testing.T) {
+	b, rec := newBus(), &recorder{}
+	c := newTestCoordinator(t, b, nil)
+	orderSaga(t, c, b, rec, func(*Command) (Data, error) {
+		return nil, Abort(errors.New("out of stock"))
+	})
+
+	inst, _ := c.Start(context.Background(), "create-order", nil)
+	b.drain(t)
+
+	got, _ := c.Get(context.Background(), inst.ID)
+	if got.Status != StatusCompensated {
+		t.Fatalf("Status = %s (%s), want compensated", got.Status, got.Error)
+	}
+	if got.Error != "inventory: out of stock" {
+		t.Errorf("Error = %q", got.Error)
+	}
+	// The rejected reservation is not released; earlier steps are undone in reverse
+	if calls := rec.get(); !slices.Equal(calls, []string{"order", "charge", "reserve", "refund", "cancel-order"}) {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestTransientErrorsAreRetried(t *testing.T) {
+	clk := &clock{now: time.Unix(1700000000, 0)}
+	c := newTestCoordinator(t, nil, clk)
+	failures := 2
+	c.Register(Definition{Name: "flaky", Steps: []Step{{
+		Name: "step",
+		Execute: func(ctx context.Context, data Data) (Data, error) {
+			if failures > 0 {
+				failures--
+				return nil, errors.New("connection reset")
+			}
+			return nil, nil
+		},
+	}}})
+
+	inst, err := c.Start(context.Background(), "flaky", nil)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if inst.Status != StatusRunning || inst.Attempt != 1 || inst.Awaiting || !inst.Deadline.Equal(clk.Now().Add(time.Second)) {
+		t.Fatalf("after first failure = %+v", inst)
+	}
+
+	// Not yet due
+	c.poll(context.Background())
+	if got, _ := c.Get(context.Background(), inst.ID); got.Attempt != 1 {
+		t.Fatalf("retried before backoff: %+v", got)
+	}
+
+	clk.Add(time.Second)
+	c.poll(context.Background())
+	got, _ := c.Get(context.Background(), inst.ID)
+	if got.Attempt != 2 || !got.Deadline.Equal(clk.Now().Add(2*time.Second)) {
+		t.Fatalf("after second failure = %+v, want doubled backoff", got)
+	}
+
+	clk.Add(2 * time.Second)
+	c.poll(context.Background())
+	if got, _ := c.Get(context.Background(), inst.ID); got.Status != StatusCompleted {
+		t.Fatalf("Status = %s, want completed", got.Status)
+	}
+}
+
+func TestTimeoutRetriesThenCompensatesTimedOutStep(t *testing.T) {
+	clk := &clock{now: time.Unix(1700000000, 0)}
+	b, rec := newBus(), &recorder{}
+	c := newTestCoordinator(t, b, clk)
+	c.Register(Definition{Name: "pay", Steps: []Step{
+		{Name: "payment", Topic: "payment", CompensateTopic: "payment", Timeout: 5 * time.Second, MaxAttempts: 2},
+	}})
+	b.handlers["payment"] = Participant(b, rec.participant("charge", nil), rec.participant("refund", nil))
+
+	inst, _ := c.Start(context.Background(), "pay", nil)
+	b.drop()
+
+	clk.Add(5 * time.Second)
+	c.poll(context.Background())
+	got, _ := c.Get(context.Background(), inst.ID)
+	if got.Status != StatusRunning || got.Awaiting || got.Error != "" {
+		t.Fatalf("after first timeout = %+v, want retry scheduled", got)
+	}
+
+	clk.Add(time.Second)
+	c.poll(context.Background())
+	b.drop()
+	if got, _ := c.Get(context.Background(), inst.ID); got.Attempt != 2 || !got.Awaiting {
+		t.Fatalf("after retry = %+v, want second attempt in flight", got)
+	}
+
+	clk.Add(5 * time.Second)
+	c.poll(context.Background())
+	b.drain(t)
+	got, _ = c.Get(context.Background(), inst.ID)
+	if got.Status != StatusCompensated || got.Error != "payment: step timed out" {
+		t.Fatalf("after attempts exhausted = %+v", got)
+	}
+	// The payment may have gone through, so it is refunded
+	if calls := rec.get(); !slices.Equal(calls, []string{"refund"}) {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestCompensationFailureFailsSaga(t *testing.T) {
+	clk := &clock{now: time.Unix(1700000000, 0)}
+	cfg := DefaultConfig()
+	cfg.CompensateAttempts = 2
+	c := NewCoordinator(NewMemoryStore(), nil, cfg)
+	c.now = clk.Now
+	rec := &recorder{}
+	c.Register(Definition{Name: "broken", Steps: []Step{
+		{Name: "a", Execute: rec.local("a", nil, nil), Compensate: rec.local("undo-a", nil, errors.New("db down"))},
+		{Name: "b", Execute: rec.local("b", nil, Abort(errors.New("rejected")))},
+	}})
+
+	inst, _ := c.Start(context.Background(), "broken", nil)
+	if inst.Status != StatusCompensating {
+		t.Fatalf("Status = %s, want compensating", inst.Status)
+	}
+	clk.Add(time.Minute)
+	c.poll(context.Background())
+
+	got, _ := c.Get(context.Background(), inst.ID)
+	if got.Status != StatusFailed || got.Error != "b: rejected; compensating a: db down" {
+		t.Fatalf("got %+v", got)
+	}
+	if calls := rec.get(); !slices.Equal(calls, []string{"a", "b", "undo-a", "undo-a"}) {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestStaleRepliesAreIgnored(t *testing.T) {
+	b, rec := newBus(), &recorder{}
+	c := newTestCoordinator(t, b, nil)
+	orderSaga(t, c, b, rec, nil)
+
+	inst, _ := c.Start(context.Background(), "create-order", nil)
+	// Deliver the payment command twice
+	b.mu.Lock()
+	b.queue = append(b.queue, b.queue[0])
+	b.mu.Unlock()
+	b.drain(t)
+
+	got, _ := c.Get(context.Background(), inst.ID)
+	if got.Status != StatusCompleted {
+		t.Fatalf("Status = %s, want completed", got.Status)
+	}
+	if calls := rec.get(); !slices.Equal(calls, []string{"order", "charge", "charge", "reserve", "confirm"}) {
+		t.Errorf("calls = %v, want the duplicate reply ignored", calls)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	noop := func(context.Context, Data) (Data, error) { return nil, nil }
+	tests := []struct {
+		name    string
+		def     Definition
+		wantErr bool
+	}{
+		{name: "valid", def: Definition{Name: "ok", Steps: []Step{{Name: "a", Execute: noop}}}},
+		{name: "no name", def: Definition{Steps: []Step{{Name: "a", Execute: noop}}}, wantErr: true},
+		{name: "no steps", def: Definition{Name: "empty"}, wantErr: true},
+		{name: "unnamed step", def: Definition{Name: "x", Steps: []Step{{Execute: noop}}}, wantErr: true},
+		{name: "execute and topic", def: Definition{Name: "x", Steps: []Step{{Name: "a", Execute: noop, Topic: "t"}}}, wantErr: true},
+		{name: "remote without producer", def: Definition{Name: "x", Steps: []Step{{Name: "a", Topic: "t"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCoordinator(NewMemoryStore(), nil, DefaultConfig())
+			if err := c.Register(tt.def); (err != nil) != tt.wantErr {
+				t.Errorf("Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	c := NewCoordinator(NewMemoryStore(), nil, DefaultConfig())
+	def := Definition{Name: "dup", Steps: []Step{{Name: "a", Execute: noop}}}
+	c.Register(def)
+	if err := c.Register(def); err == nil {
+		t.Error("Register() of a duplicate succeeded")
+	}
+	if _, err := c.Start(context.Background(), "missing", nil); !errors.Is(err, ErrUnknownSaga) {
+		t.Errorf("Start() error = %v, want ErrUnknownSaga", err)
+	}
+}
+
+func TestDataDecode(t *testing.T) {
+	var order struct {
+		ID     string  `json:"id"`
+		Amount float64 `json:"amount"`
+	}
+	if err := (Data{"id": "o-1", "amount": 12.5}).Decode(&order); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if order.ID != "o-1" || order.Amount != 12.5 {
+		t.Errorf("Decode() = %+v", order)
+	}
+}
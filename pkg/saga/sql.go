@@ -0,0 +1,203 @@
+package saga
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"mora/pkg/db"
+)
+
+// SQLStore is a Store in a MySQL, PostgreSQL or SQLite table accessed
+// through pkg/db
+type SQLStore struct {
+	client *db.SQLXClient
+	table  string
+}
+
+// NewSQLStore creates a store in table, "saga_instances" when empty; call
+// CreateTable or create it with an equivalent migration
+func NewSQLStore(client *db.SQLXClient, table string) *SQLStore {
+	if table == "" {
+		table = "saga_instances"
+	}
+	return &SQLStore{client: client, table: table}
+}
+
+// CreateTable creates the table and its deadline index if they do not exist
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	columns := `id VARCHAR(64) PRIMARY KEY,
+		saga VARCHAR(128) NOT NULL,
+		status VARCHAR(16) NOT NULL,
+		step INTEGER NOT NULL,
+		attempt INTEGER NOT NULL,
+		awaiting INTEGER NOT NULL,
+		deadline BIGINT NOT NULL,
+		data TEXT NOT NULL,
+		error TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		created_at BIGINT NOT NULL,
+		updated_at BIGINT NOT NULL`
+	index := fmt.Sprintf("idx_%s_status_deadline", s.table)
+	stmts := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", s.table, columns),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (status, deadline)", index, s.table),
+	}
+	// MySQL has no CREATE INDEX IF NOT EXISTS, so the index is declared inline
+	if s.client.DB().DriverName() == "mysql" {
+		stmts = []string{fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s, INDEX %s (status, deadline))", s.table, columns, index)}
+	}
+	for _, stmt := range stmts {
+		if _, err := s.client.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("saga: failed to create table: %w", err)
+		}
+	}
+	return nil
+}
+
+// row is the table representation of an Instance
+type row struct {
+	ID        string `db:"id"`
+	Saga      string `db:"saga"`
+	Status    string `db:"status"`
+	Step      int    `db:"step"`
+	Attempt   int    `db:"attempt"`
+	Awaiting  int    `db:"awaiting"`
+	Deadline  int64  `db:"deadline"`
+	Data      string `db:"data"`
+	Error     string `db:"error"`
+	Version   int    `db:"version"`
+	CreatedAt int64  `db:"created_at"`
+	UpdatedAt int64  `db:"updated_at"`
+}
+
+// toRow encodes inst; times are stored as Unix milliseconds, 0 for zero
+func toRow(inst *Instance) (row, error) {
+	data, err := json.Marshal(inst.Data)
+	if err != nil {
+		return row{}, fmt.Errorf("saga: failed to encode data: %w", err)
+	}
+	r := row{
+		ID:        inst.ID,
+		Saga:      inst.Saga,
+		Status:    string(inst.Status),
+		Step:      inst.Step,
+		Attempt:   inst.Attempt,
+		Data:      string(data),
+		Error:     inst.Error,
+		Version:   inst.Version,
+		CreatedAt: inst.CreatedAt.UnixMilli(),
+		UpdatedAt: inst.UpdatedAt.UnixMilli(),
+	}
+	if inst.Awaiting {
+		r.Awaiting = 1
+	}
+	if !inst.Deadline.IsZero() {
+		r.Deadline = inst.Deadline.UnixMilli()
+	}
+	return r, nil
+}
+
+// instance decodes a row
+func (r row) instance() (*Instance, error) {
+	inst := &Instance{
+		ID:        r.ID,
+		Saga:      r.Saga,
+		Status:    Status(r.Status),
+		Step:      r.Step,
+		Attempt:   r.Attempt,
+		Awaiting:  r.Awaiting != 0,
+		Error:     r.Error,
+		Version:   r.Version,
+		CreatedAt: time.UnixMilli(r.CreatedAt),
+		UpdatedAt: time.UnixMilli(r.UpdatedAt),
+	}
+	if r.Deadline != 0 {
+		inst.Deadline = time.UnixMilli(r.Deadline)
+	}
+	if err := json.Unmarshal([]byte(r.Data), &inst.Data); err != nil {
+		return nil, fmt.Errorf("saga: failed to decode data of %s: %w", r.ID, err)
+	}
+	if inst.Data == nil {
+		inst.Data = Data{}
+	}
+	return inst, nil
+}
+
+// Create implements Store
+func (s *SQLStore) Create(ctx context.Context, inst *Instance) error {
+	r, err := toRow(inst)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.NamedExec(ctx, fmt.Sprintf(`INSERT INTO %s
+		(id, saga, status, step, attempt, awaiting, deadline, data, error, version, created_at, updated_at)
+		VALUES (:id, :saga, :status, :step, :attempt, :awaiting, :deadline, :data, :error, :version, :created_at, :updated_at)`, s.table), r)
+	if err != nil {
+		return fmt.Errorf("saga: failed to insert instance: %w", err)
+	}
+	return nil
+}
+
+// Get implements Store
+func (s *SQLStore) Get(ctx context.Context, id string) (*Instance, error) {
+	var r row
+	query := s.client.DB().Rebind(fmt.Sprintf("SELECT * FROM %s WHERE id = ?", s.table))
+	if err := s.client.Get(ctx, &r, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("saga: failed to load instance: %w", err)
+	}
+	return r.instance()
+}
+
+// Update implements Store
+func (s *SQLStore) Update(ctx context.Context, inst *Instance) error {
+	r, err := toRow(inst)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.NamedExec(ctx, fmt.Sprintf(`UPDATE %s SET
+		status = :status, step = :step, attempt = :attempt, awaiting = :awaiting, deadline = :deadline,
+		data = :data, error = :error, version = version + 1, updated_at = :updated_at
+		WHERE id = :id AND version = :version`, s.table), r)
+	if err != nil {
+		return fmt.Errorf("saga: failed to update instance: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("saga: failed to update instance: %w", err)
+	}
+	if n == 0 {
+		return ErrConflict
+	}
+	inst.Version++
+	return nil
+}
+
+// Due implements Store
+func (s *SQLStore) Due(ctx context.Context, now time.Time, limit int) ([]*Instance, error) {
+	if limit <= 0 {
+		limit = DefaultConfig().BatchSize
+	}
+	query := s.client.DB().Rebind(fmt.Sprintf(`SELECT * FROM %s
+		WHERE status IN (?, ?) AND deadline > 0 AND deadline <= ?
+		ORDER BY deadline LIMIT ?`, s.table))
+	var rows []row
+	if err := s.client.Select(ctx, &rows, query, StatusRunning, StatusCompensating, now.UnixMilli(), limit); err != nil {
+		return nil, fmt.Errorf("saga: failed to load due instances: %w", err)
+	}
+	due := make([]*Instance, 0, len(rows))
+	for _, r := range rows {
+		inst, err := r.instance()
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, inst)
+	}
+	return due, nil
+}
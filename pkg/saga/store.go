@@ -0,0 +1,86 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store persists saga instances
+type Store interface {
+	// Create inserts a new instance
+	Create(ctx context.Context, inst *Instance) error
+	// Get returns an instance, or ErrNotFound
+	Get(ctx context.Context, id string) (*Instance, error)
+	// Update saves inst if its Version is still current, then increments
+	// Version; it returns ErrConflict when another update came first
+	Update(ctx context.Context, inst *Instance) error
+	// Due returns up to limit unfinished instances whose deadline is at or
+	// before now, earliest first
+	Due(ctx context.Context, now time.Time, limit int) ([]*Instance, error)
+}
+
+// MemoryStore is an in-process Store for tests and single-instance tools
+type MemoryStore struct {
+	mu        sync.Mutex
+	instances map[string]*Instance
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{instances: make(map[string]*Instance)}
+}
+
+// Create implements Store
+func (s *MemoryStore) Create(ctx context.Context, inst *Instance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.instances[inst.ID]; ok {
+		return fmt.Errorf("saga: instance %s already exists", inst.ID)
+	}
+	s.instances[inst.ID] = inst.clone()
+	return nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Instance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inst, ok := s.instances[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return inst.clone(), nil
+}
+
+// Update implements Store
+func (s *MemoryStore) Update(ctx context.Context, inst *Instance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur, ok := s.instances[inst.ID]
+	if !ok || cur.Version != inst.Version {
+		return ErrConflict
+	}
+	inst.Version++
+	s.instances[inst.ID] = inst.clone()
+	return nil
+}
+
+// Due implements Store
+func (s *MemoryStore) Due(ctx context.Context, now time.Time, limit int) ([]*Instance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []*Instance
+	for _, inst := range s.instances {
+		if !inst.Status.Done() && !inst.Deadline.IsZero() && !inst.Deadline.After(now) {
+			due = append(due, inst.clone())
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].Deadline.Before(due[j].Deadline) })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
@@ -0,0 +1,102 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mora/pkg/db"
+)
+
+// testStore runs the behaviour every Store must have
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+	base := time.UnixMilli(1700000000000)
+	insts := []*Instance{
+		{ID: "a", Saga: "s", Status: StatusRunning, Deadline: base.Add(2 * time.Second), Data: Data{"n": 1.0}},
+		{ID: "b", Saga: "s", Status: StatusCompensating, Deadline: base.Add(time.Second), Data: Data{}},
+		{ID: "c", Saga: "s", Status: StatusRunning, Deadline: base.Add(time.Hour), Data: Data{}},
+		{ID: "d", Saga: "s", Status: StatusCompleted, Data: Data{}},
+	}
+	for _, inst := range insts {
+		inst.CreatedAt, inst.UpdatedAt = base, base
+		if err := s.Create(ctx, inst); err != nil {
+			t.Fatalf("Create(%s) error = %v", inst.ID, err)
+		}
+	}
+
+	t.Run("get", func(t *testing.T) {
+		got, err := s.Get(ctx, "a")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if got.Status != StatusRunning || !got.Deadline.Equal(base.Add(2*time.Second)) || got.Data["n"] != 1.0 {
+			t.Errorf("Get() = %+v", got)
+		}
+		if _, err := s.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("due", func(t *testing.T) {
+		due, err := s.Due(ctx, base.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("Due() error = %v", err)
+		}
+		if len(due) != 2 || due[0].ID != "b" || due[1].ID != "a" {
+			t.Errorf("Due() = %+v, want b then a", due)
+		}
+		if due, _ := s.Due(ctx, base.Add(time.Minute), 1); len(due) != 1 {
+			t.Errorf("Due() with limit 1 returned %d", len(due))
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		inst, _ := s.Get(ctx, "a")
+		stale, _ := s.Get(ctx, "a")
+		inst.Status = StatusCompleted
+		inst.Awaiting = true
+		inst.Deadline = time.Time{}
+		inst.Data["payment_id"] = "p-1"
+		if err := s.Update(ctx, inst); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if inst.Version != 1 {
+			t.Errorf("Version = %d, want 1", inst.Version)
+		}
+		if err := s.Update(ctx, stale); !errors.Is(err, ErrConflict) {
+			t.Errorf("stale Update() error = %v, want ErrConflict", err)
+		}
+
+		got, _ := s.Get(ctx, "a")
+		if got.Status != StatusCompleted || !got.Awaiting || !got.Deadline.IsZero() || got.Data["payment_id"] != "p-1" || got.Version != 1 {
+			t.Errorf("after Update = %+v", got)
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestSQLStore(t *testing.T) {
+	cfg := db.DefaultConfig()
+	cfg.Driver = "sqlite3"
+	cfg.DSN = filepath.Join(t.TempDir(), "saga.db")
+	client, err := db.NewSQLX(cfg)
+	if err != nil {
+		t.Fatalf("NewSQLX() error = %v", err)
+	}
+	defer client.Close()
+
+	s := NewSQLStore(client, "")
+	if err := s.CreateTable(context.Background()); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if err := s.CreateTable(context.Background()); err != nil {
+		t.Fatalf("second CreateTable() error = %v", err)
+	}
+	testStore(t, s)
+}
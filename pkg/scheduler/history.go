@@ -0,0 +1,37 @@
+package scheduler
+
+import "sync"
+
+// History keeps the most recent runs of each job in memory; pass its
+// Record method as Config.OnFinish
+type History struct {
+	mu    sync.Mutex
+	limit int
+	runs  map[string][]Run
+}
+
+// NewHistory creates a history keeping up to limit runs per job
+func NewHistory(limit int) *History {
+	if limit <= 0 {
+		limit = 100
+	}
+	return &History{limit: limit, runs: make(map[string][]Run)}
+}
+
+// Record stores a run, dropping the oldest one past the limit
+func (h *History) Record(run Run) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	runs := append(h.runs[run.Job], run)
+	if len(runs) > h.limit {
+		runs = runs[len(runs)-h.limit:]
+	}
+	h.runs[run.Job] = runs
+}
+
+// Runs returns a job's recorded runs, oldest first
+func (h *History) Runs(job string) []Run {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Run(nil), h.runs[job]...)
+}
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronNext(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC) // Monday
+
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", base, time.Date(2024, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", base, time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * *", base, time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)},
+		{"30 10 * * *", base, time.Date(2024, 1, 16, 10, 30, 0, 0, time.UTC)},
+		{"0 9-18/3 * * MON-FRI", base, time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * SAT,SUN", base, time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", base, time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", base, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", base, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)},
+		// day of month and day of week restricted: either matches
+		{"0 0 1 * MON", base, time.Date(2024, 1, 22, 0, 0, 0, 0, time.UTC)},
+		{"0 0 0 1 JAN *", base, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"5/20 * * * * *", base, time.Date(2024, 1, 15, 10, 30, 5, 0, time.UTC)},
+		{"@hourly", base, time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", base, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", base, time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", base, base.Add(90 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseCron(tt.expr, time.UTC)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronLocation(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	s, err := ParseCron("0 9 * * *", shanghai)
+	if err != nil {
+		t.Fatalf("ParseCron() error = %v", err)
+	}
+	got := s.Next(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2024, 1, 15, 1, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"", "* * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"* * * * 8", "*/0 * * * *", "5-1 * * * *", "@fortnightly", "@every -1s", "@every soon",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseCron(expr, time.UTC); err == nil {
+				t.Errorf("ParseCron(%q) should fail", expr)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+// Package scheduler runs jobs on cron expressions or fixed intervals. With a
+// Locker, each occurrence of a job runs on only one instance of a service.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"mora/pkg/cache"
+)
+
+// OverlapPolicy decides what happens when a job is due while its previous
+// run on this instance is still going
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the new occurrence
+	OverlapSkip OverlapPolicy = iota
+	// OverlapAllow runs occurrences concurrently
+	OverlapAllow
+	// OverlapQueue runs the new occurrence once the current run finishes,
+	// keeping at most one waiting
+	OverlapQueue
+)
+
+// Skip reasons reported in Run.SkipReason
+const (
+	SkipOverlap = "overlap"
+	SkipLocked  = "locked"
+)
+
+var (
+	// ErrJobExists is returned when adding a job whose name is taken
+	ErrJobExists = errors.New("scheduler: job already exists")
+	// ErrJobNotFound is returned for an unknown job name
+	ErrJobNotFound = errors.New("scheduler: job not found")
+)
+
+// Job is a unit of scheduled work
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+	// Timeout cancels the run's context after this long, 0 means no limit
+	Timeout time.Duration
+	Overlap OverlapPolicy
+	// LockTTL is how long the occurrence lock is held, defaulting to
+	// Config.LockTTL; it should exceed clock skew between instances
+	LockTTL time.Duration
+}
+
+// Run describes one occurrence of a job, passed to the history hooks
+type Run struct {
+	Job         string
+	ScheduledAt time.Time
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	Err         error
+	// Skipped is set when the occurrence did not run, see SkipReason
+	Skipped    bool
+	SkipReason string
+}
+
+// Duration returns how long the run took
+func (r Run) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// Locker grants an occurrence of a job to a single instance
+type Locker interface {
+	// TryLock acquires key for ttl, reporting false when another instance holds it
+	TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// cacheLocker implements Locker with the pkg/cache distributed lock
+type cacheLocker struct {
+	client *cache.Client
+}
+
+// NewCacheLocker returns a Locker backed by Redis through pkg/cache
+func NewCacheLocker(client *cache.Client) Locker {
+	return cacheLocker{client: client}
+}
+
+// TryLock implements Locker. Occurrence locks are never released; they
+// expire, so an instance whose clock lags cannot run the occurrence again.
+func (l cacheLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	_, err := l.client.TryLock(ctx, key, ttl)
+	if errors.Is(err, cache.ErrLockNotAcquired) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Config configures a Scheduler
+type Config struct {
+	// Location evaluates cron expressions, defaulting to time.Local
+	Location *time.Location
+	// Locker makes jobs run once per occurrence across instances; nil runs
+	// every job on every instance
+	Locker Locker
+	// LockPrefix namespaces lock keys, e.g. "order-api:scheduler:"
+	LockPrefix string
+	LockTTL    time.Duration
+
+	// OnStart is called before a job runs
+	OnStart func(Run)
+	// OnFinish is called after every occurrence, including skipped ones
+	OnFinish func(Run)
+}
+
+// DefaultConfig returns default scheduler configuration
+func DefaultConfig() Config {
+	return Config{
+		LockPrefix: "scheduler:",
+		LockTTL:    time.Minute,
+	}
+}
+
+// entry is a registered job and its run state
+type entry struct {
+	job Job
+
+	mu      sync.Mutex
+	running int
+	queued  *time.Time
+	next    time.Time
+	last    *Run
+}
+
+// Scheduler runs registered jobs
+type Scheduler struct {
+	cfg Config
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	ctx     context.Context
+	cancel  context.CancelFunc
+	loops   sync.WaitGroup
+	runs    sync.WaitGroup
+}
+
+// New creates a scheduler
+func New(cfg Config) *Scheduler {
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = DefaultConfig().LockTTL
+	}
+	return &Scheduler{cfg: cfg, entries: make(map[string]*entry)}
+}
+
+// Add registers a job; jobs added after Start begin immediately
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return errors.New("scheduler: job name, schedule and run are required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[job.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrJobExists, job.Name)
+	}
+	e := &entry{job: job}
+	s.entries[job.Name] = e
+	if s.ctx != nil {
+		s.startLoop(e)
+	}
+	return nil
+}
+
+// AddFunc registers fn under a cron expression or "@every" spec
+func (s *Scheduler) AddFunc(name, spec string, fn func(ctx context.Context) error) error {
+	schedule, err := ParseCron(spec, s.cfg.Location)
+	if err != nil {
+		return err
+	}
+	return s.Add(Job{Name: name, Schedule: schedule, Run: fn})
+}
+
+// Start begins running jobs until Stop is called or ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx != nil {
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	for _, e := range s.entries {
+		s.startLoop(e)
+	}
+}
+
+// Stop stops scheduling and waits for running jobs to finish or ctx to
+// expire; running jobs see their context cancelled
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		s.runs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("scheduler: waiting for running jobs: %w", ctx.Err())
+	}
+}
+
+// RunNow triggers a job immediately, outside its schedule
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	ctx := s.ctx
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	if ctx == nil {
+		return errors.New("scheduler: not started")
+	}
+	s.dispatch(ctx, e, time.Now())
+	return nil
+}
+
+// JobInfo reports a job's schedule state
+type JobInfo struct {
+	Name    string
+	Next    time.Time
+	Running int
+	// Last is the most recent occurrence, nil before the first
+	Last *Run
+}
+
+// Jobs returns the state of every registered job
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	infos := make([]JobInfo, 0, len(s.entries))
+	for name, e := range s.entries {
+		e.mu.Lock()
+		infos = append(infos, JobInfo{Name: name, Next: e.next, Running: e.running, Last: e.last})
+		e.mu.Unlock()
+	}
+	return infos
+}
+
+// startLoop starts the timer loop of an entry; s.mu must be held
+func (s *Scheduler) startLoop(e *entry) {
+	ctx := s.ctx
+	s.loops.Add(1)
+	go func() {
+		defer s.loops.Done()
+		for {
+			next := e.job.Schedule.Next(time.Now())
+			if next.IsZero() {
+				return
+			}
+			e.mu.Lock()
+			e.next = next
+			e.mu.Unlock()
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.dispatch(ctx, e, next)
+			}
+		}
+	}()
+}
+
+// dispatch applies the overlap policy and starts the occurrence
+func (s *Scheduler) dispatch(ctx context.Context, e *entry, scheduled time.Time) {
+	e.mu.Lock()
+	if e.running > 0 {
+		switch e.job.Overlap {
+		case OverlapSkip:
+			e.mu.Unlock()
+			s.finish(e, Run{Job: e.job.Name, ScheduledAt: scheduled, Skipped: true, SkipReason: SkipOverlap})
+			return
+		case OverlapQueue:
+			if e.queued != nil {
+				e.mu.Unlock()
+				s.finish(e, Run{Job: e.job.Name, ScheduledAt: scheduled, Skipped: true, SkipReason: SkipOverlap})
+				return
+			}
+			e.queued = &scheduled
+			e.mu.Unlock()
+			return
+		}
+	}
+	e.running++
+	e.mu.Unlock()
+
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		for {
+			s.execute(ctx, e, scheduled)
+
+			e.mu.Lock()
+			if e.queued == nil || ctx.Err() != nil {
+				e.running--
+				e.queued = nil
+				e.mu.Unlock()
+				return
+			}
+			scheduled = *e.queued
+			e.queued = nil
+			e.mu.Unlock()
+		}
+	}()
+}
+
+// execute runs one occurrence with locking, timeout and panic recovery
+func (s *Scheduler) execute(ctx context.Context, e *entry, scheduled time.Time) {
+	run := Run{Job: e.job.Name, ScheduledAt: scheduled}
+
+	if s.cfg.Locker != nil {
+		ttl := e.job.LockTTL
+		if ttl <= 0 {
+			ttl = s.cfg.LockTTL
+		}
+		key := fmt.Sprintf("%s%s:%d", s.cfg.LockPrefix, e.job.Name, scheduled.Unix())
+		ok, err := s.cfg.Locker.TryLock(ctx, key, ttl)
+		if err != nil || !ok {
+			run.Skipped, run.SkipReason, run.Err = true, SkipLocked, err
+			s.finish(e, run)
+			return
+		}
+	}
+
+	runCtx := ctx
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer cancel()
+	}
+
+	run.StartedAt = time.Now()
+	if s.cfg.OnStart != nil {
+		s.cfg.OnStart(run)
+	}
+	run.Err = safeRun(runCtx, e.job.Run)
+	run.FinishedAt = time.Now()
+	s.finish(e, run)
+}
+
+// finish records the occurrence and calls the hook
+func (s *Scheduler) finish(e *entry, run Run) {
+	e.mu.Lock()
+	e.last = &run
+	e.mu.Unlock()
+	if s.cfg.OnFinish != nil {
+		s.cfg.OnFinish(run)
+	}
+}
+
+// safeRun calls fn, converting a panic into an error
+func safeRun(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("scheduler: job panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return fn(ctx)
+}
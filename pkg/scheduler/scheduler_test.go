@@ -0,0 +1,187 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeLocker grants each key once, like a lock that never expires
+type fakeLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[key] {
+		return false, nil
+	}
+	l.held[key] = true
+	return true, nil
+}
+
+func TestSchedulerRunsJobs(t *testing.T) {
+	history := NewHistory(10)
+	s := New(Config{OnFinish: history.Record})
+
+	var calls atomic.Int32
+	if err := s.AddFunc("tick", "@every 20ms", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}); err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+	if err := s.AddFunc("tick", "@every 1s", func(ctx context.Context) error { return nil }); !errors.Is(err, ErrJobExists) {
+		t.Errorf("AddFunc() duplicate error = %v, want %v", err, ErrJobExists)
+	}
+
+	s.Start(context.Background())
+	time.Sleep(110 * time.Millisecond)
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if n := calls.Load(); n < 3 || n > 6 {
+		t.Errorf("calls = %d, want about 5", n)
+	}
+	runs := history.Runs("tick")
+	if len(runs) != int(calls.Load()) || runs[0].Err != nil || runs[0].Duration() < 0 {
+		t.Errorf("history = %+v", runs)
+	}
+	if jobs := s.Jobs(); len(jobs) != 1 || jobs[0].Last == nil {
+		t.Errorf("Jobs() = %+v", jobs)
+	}
+}
+
+func TestSchedulerOverlap(t *testing.T) {
+	tests := []struct {
+		policy    OverlapPolicy
+		wantRuns  int32
+		wantSkips bool
+	}{
+		{OverlapSkip, 1, true},
+		{OverlapAllow, 3, false},
+		{OverlapQueue, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(map[OverlapPolicy]string{OverlapSkip: "skip", OverlapAllow: "allow", OverlapQueue: "queue"}[tt.policy], func(t *testing.T) {
+			var skips atomic.Int32
+			s := New(Config{OnFinish: func(r Run) {
+				if r.Skipped && r.SkipReason == SkipOverlap {
+					skips.Add(1)
+				}
+			}})
+
+			var runs atomic.Int32
+			release := make(chan struct{})
+			s.Add(Job{Name: "slow", Schedule: Every(time.Hour), Overlap: tt.policy, Run: func(ctx context.Context) error {
+				runs.Add(1)
+				<-release
+				return nil
+			}})
+			s.Start(context.Background())
+
+			for i := 0; i < 3; i++ {
+				s.RunNow("slow")
+			}
+			time.Sleep(20 * time.Millisecond)
+			close(release)
+			time.Sleep(20 * time.Millisecond)
+			if err := s.Stop(context.Background()); err != nil {
+				t.Fatalf("Stop() error = %v", err)
+			}
+
+			if runs.Load() != tt.wantRuns {
+				t.Errorf("runs = %d, want %d", runs.Load(), tt.wantRuns)
+			}
+			if (skips.Load() > 0) != tt.wantSkips {
+				t.Errorf("skips = %d", skips.Load())
+			}
+		})
+	}
+}
+
+func TestSchedulerRecoverAndTimeout(t *testing.T) {
+	history := NewHistory(0)
+	s := New(Config{OnFinish: history.Record})
+	s.Add(Job{Name: "panics", Schedule: Every(time.Hour), Run: func(ctx context.Context) error {
+		panic("boom")
+	}})
+	s.Add(Job{Name: "slow", Schedule: Every(time.Hour), Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	s.Start(context.Background())
+	s.RunNow("panics")
+	s.RunNow("slow")
+	if err := s.RunNow("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("RunNow() error = %v, want %v", err, ErrJobNotFound)
+	}
+	time.Sleep(50 * time.Millisecond)
+	s.Stop(context.Background())
+
+	if runs := history.Runs("panics"); len(runs) != 1 || runs[0].Err == nil || !strings.Contains(runs[0].Err.Error(), "boom") {
+		t.Errorf("panic runs = %+v", runs)
+	}
+	if runs := history.Runs("slow"); len(runs) != 1 || !errors.Is(runs[0].Err, context.DeadlineExceeded) {
+		t.Errorf("timeout runs = %+v", runs)
+	}
+}
+
+func TestSchedulerLocker(t *testing.T) {
+	locker := &fakeLocker{held: make(map[string]bool)}
+	var runs, locked atomic.Int32
+	hooks := func(r Run) {
+		if r.SkipReason == SkipLocked {
+			locked.Add(1)
+		}
+	}
+
+	// Two instances share a locker and fire the same occurrence
+	instances := []*Scheduler{
+		New(Config{Locker: locker, LockPrefix: "svc:", OnFinish: hooks}),
+		New(Config{Locker: locker, LockPrefix: "svc:", OnFinish: hooks}),
+	}
+	occurrence := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, s := range instances {
+		s.Add(Job{Name: "report", Schedule: Every(time.Hour), Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		}})
+		s.Start(context.Background())
+		s.dispatch(s.ctx, s.entries["report"], occurrence)
+	}
+	for _, s := range instances {
+		s.Stop(context.Background())
+	}
+
+	if runs.Load() != 1 || locked.Load() != 1 {
+		t.Errorf("runs = %d, locked = %d, want 1 and 1", runs.Load(), locked.Load())
+	}
+	if !locker.held["svc:report:1704067200"] {
+		t.Errorf("lock keys = %v", locker.held)
+	}
+}
+
+func TestStopTimeout(t *testing.T) {
+	s := New(DefaultConfig())
+	s.Add(Job{Name: "stuck", Schedule: Every(time.Hour), Run: func(ctx context.Context) error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	}})
+	s.Start(context.Background())
+	s.RunNow("stuck")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() error = %v, want deadline exceeded", err)
+	}
+}
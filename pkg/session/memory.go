@@ -0,0 +1,119 @@
+package session
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process Store for tests and single-instance services
+type MemoryStore struct {
+	cfg Config
+	now func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewMemoryStore creates an empty memory store
+func NewMemoryStore(cfg Config) *MemoryStore {
+	return &MemoryStore{cfg: cfg.normalize(), now: time.Now, sessions: make(map[string]*Session)}
+}
+
+// get returns a live session; callers hold the lock
+func (s *MemoryStore) get(id string) (*Session, bool) {
+	sess, ok := s.sessions[id]
+	if ok && !s.now().Before(sess.ExpiresAt) {
+		delete(s.sessions, id)
+		return nil, false
+	}
+	return sess, ok
+}
+
+// copySession returns a copy callers may modify
+func copySession(sess *Session) *Session {
+	c := *sess
+	c.Data = maps.Clone(sess.Data)
+	return &c
+}
+
+// Create implements Store
+func (s *MemoryStore) Create(ctx context.Context, userID string, data map[string]string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, err := s.cfg.newSession(userID, maps.Clone(data), s.now())
+	if err != nil {
+		return nil, err
+	}
+	s.sessions[sess.ID] = sess
+	return copySession(sess), nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.get(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copySession(sess), nil
+}
+
+// Touch implements Store
+func (s *MemoryStore) Touch(ctx context.Context, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.get(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	now := s.now()
+	sess.LastSeen = now
+	sess.ExpiresAt = s.cfg.expiry(sess, now)
+	return copySession(sess), nil
+}
+
+// Destroy implements Store
+func (s *MemoryStore) Destroy(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// List implements Store
+func (s *MemoryStore) List(ctx context.Context, userID string) ([]*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []*Session
+	for id, sess := range s.sessions {
+		if sess.UserID != userID {
+			continue
+		}
+		if sess, ok := s.get(id); ok {
+			list = append(list, copySession(sess))
+		}
+	}
+	sort.Slice(list, func(i, k int) bool { return list[i].CreatedAt.Before(list[k].CreatedAt) })
+	return list, nil
+}
+
+// DestroyAll implements Store
+func (s *MemoryStore) DestroyAll(ctx context.Context, userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, sess := range s.sessions {
+		if sess.UserID != userID {
+			continue
+		}
+		if _, ok := s.get(id); ok {
+			n++
+		}
+		delete(s.sessions, id)
+	}
+	return n, nil
+}
@@ -0,0 +1,159 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mora/pkg/cache"
+)
+
+// RedisStore is a Store backed by pkg/cache. Each session is a JSON value
+// that expires with the session, and each user has a set of session IDs
+// that is pruned as sessions are found gone.
+type RedisStore struct {
+	client *cache.Client
+	cfg    Config
+	now    func() time.Time
+}
+
+// NewRedisStore creates a Redis store
+func NewRedisStore(client *cache.Client, cfg Config) *RedisStore {
+	return &RedisStore{client: client, cfg: cfg.normalize(), now: time.Now}
+}
+
+func (s *RedisStore) sessionKey(id string) string  { return s.cfg.Prefix + id }
+func (s *RedisStore) userKey(userID string) string { return s.cfg.Prefix + "user:" + userID }
+
+// save writes a session and keeps the user's set alive at least as long
+func (s *RedisStore) save(ctx context.Context, sess *Session) error {
+	data, err := json.Marshal(sess)
+	if err != nil {
+		return fmt.Errorf("session: failed to encode session: %w", err)
+	}
+	ttl := sess.ExpiresAt.Sub(s.now())
+	if ttl <= 0 {
+		return ErrNotFound
+	}
+	userKey := s.userKey(sess.UserID)
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, s.sessionKey(sess.ID), data, ttl)
+	pipe.SAdd(ctx, userKey, sess.ID)
+	// No session outlives its last save by more than TTL, so the set
+	// outlives every member it holds
+	pipe.Expire(ctx, userKey, s.cfg.TTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("session: failed to save session: %w", err)
+	}
+	return nil
+}
+
+// Create implements Store
+func (s *RedisStore) Create(ctx context.Context, userID string, data map[string]string) (*Session, error) {
+	sess, err := s.cfg.newSession(userID, data, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("session: failed to create session: %w", err)
+	}
+	if err := s.save(ctx, sess); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// Get implements Store
+func (s *RedisStore) Get(ctx context.Context, id string) (*Session, error) {
+	data, err := s.client.GetBytes(ctx, s.sessionKey(id))
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("session: failed to load session: %w", err)
+	}
+	var sess Session
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, fmt.Errorf("session: failed to decode session: %w", err)
+	}
+	return &sess, nil
+}
+
+// Touch implements Store
+func (s *RedisStore) Touch(ctx context.Context, id string) (*Session, error) {
+	sess, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	sess.LastSeen = now
+	sess.ExpiresAt = s.cfg.expiry(sess, now)
+	if err := s.save(ctx, sess); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// Destroy implements Store
+func (s *RedisStore) Destroy(ctx context.Context, id string) error {
+	sess, err := s.Get(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, s.sessionKey(id))
+	pipe.SRem(ctx, s.userKey(sess.UserID), id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("session: failed to destroy session: %w", err)
+	}
+	return nil
+}
+
+// List implements Store
+func (s *RedisStore) List(ctx context.Context, userID string) ([]*Session, error) {
+	ids, err := s.client.SMembers(ctx, s.userKey(userID))
+	if err != nil {
+		return nil, fmt.Errorf("session: failed to list sessions: %w", err)
+	}
+	var list []*Session
+	var gone []any
+	for _, id := range ids {
+		sess, err := s.Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			gone = append(gone, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, sess)
+	}
+	if len(gone) > 0 {
+		if err := s.client.SRem(ctx, s.userKey(userID), gone...); err != nil {
+			return nil, fmt.Errorf("session: failed to prune sessions: %w", err)
+		}
+	}
+	sort.Slice(list, func(i, k int) bool { return list[i].CreatedAt.Before(list[k].CreatedAt) })
+	return list, nil
+}
+
+// DestroyAll implements Store
+func (s *RedisStore) DestroyAll(ctx context.Context, userID string) (int, error) {
+	list, err := s.List(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	keys := []string{s.userKey(userID)}
+	for _, sess := range list {
+		keys = append(keys, s.sessionKey(sess.ID))
+	}
+	if err := s.client.Delete(ctx, keys...); err != nil {
+		return 0, fmt.Errorf("session: failed to destroy sessions: %w", err)
+	}
+	return len(list), nil
+}
@@ -0,0 +1,102 @@
+// Package session keeps server-side sessions with sliding expiration and
+// per-user enumeration, so apps can log users out for real, list their
+// devices or kick every session of a user on top of stateless JWTs.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned for missing, expired or destroyed sessions
+var ErrNotFound = errors.New("session: not found")
+
+// Session is a server-side session
+type Session struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Data      map[string]string `json:"data,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	LastSeen  time.Time         `json:"last_seen"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// Store keeps sessions
+type Store interface {
+	// Create starts a session for the user with optional data such as the
+	// user agent or IP
+	Create(ctx context.Context, userID string, data map[string]string) (*Session, error)
+	// Get returns ErrNotFound for missing or expired sessions; it does not
+	// extend the session
+	Get(ctx context.Context, id string) (*Session, error)
+	// Touch records activity and slides the expiry forward
+	Touch(ctx context.Context, id string) (*Session, error)
+	// Destroy ends a session; destroying a missing session is not an error
+	Destroy(ctx context.Context, id string) error
+	// List returns the user's live sessions, oldest first
+	List(ctx context.Context, userID string) ([]*Session, error)
+	// DestroyAll ends every session of the user and returns how many there were
+	DestroyAll(ctx context.Context, userID string) (int, error)
+}
+
+// Config holds the expiry settings shared by the stores
+type Config struct {
+	// TTL is the idle timeout; every Touch extends the session by TTL
+	TTL time.Duration `json:"ttl" yaml:"ttl" env:"SESSION_TTL"`
+	// MaxLifetime caps how long a session lives however active it is; 0 means no cap
+	MaxLifetime time.Duration `json:"max_lifetime" yaml:"max_lifetime" env:"SESSION_MAX_LIFETIME"`
+	// Prefix starts every Redis key
+	Prefix string `json:"prefix" yaml:"prefix" env:"SESSION_PREFIX"`
+}
+
+// DefaultConfig returns a day of idle time and at most a month per session
+func DefaultConfig() Config {
+	return Config{
+		TTL:         24 * time.Hour,
+		MaxLifetime: 30 * 24 * time.Hour,
+		Prefix:      "session:",
+	}
+}
+
+// normalize fills unset fields with defaults
+func (c Config) normalize() Config {
+	d := DefaultConfig()
+	if c.TTL <= 0 {
+		c.TTL = d.TTL
+	}
+	if c.Prefix == "" {
+		c.Prefix = d.Prefix
+	}
+	return c
+}
+
+// expiry returns when a session last seen at now expires
+func (c Config) expiry(s *Session, now time.Time) time.Time {
+	expires := now.Add(c.TTL)
+	if c.MaxLifetime > 0 {
+		if limit := s.CreatedAt.Add(c.MaxLifetime); limit.Before(expires) {
+			return limit
+		}
+	}
+	return expires
+}
+
+// newSession creates a session with a random ID
+func (c Config) newSession(userID string, data map[string]string, now time.Time) (*Session, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	s := &Session{
+		ID:        base64.RawURLEncoding.EncodeToString(b),
+		UserID:    userID,
+		Data:      data,
+		CreatedAt: now,
+		LastSeen:  now,
+	}
+	s.ExpiresAt = c.expiry(s, now)
+	return s, nil
+}
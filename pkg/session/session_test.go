@@ -0,0 +1,105 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	s := NewMemoryStore(Config{TTL: time.Hour, MaxLifetime: 3 * time.Hour})
+	s.now = func() time.Time { return now }
+
+	a, err := s.Create(ctx, "u1", map[string]string{"ua": "firefox"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if len(a.ID) < 40 || a.UserID != "u1" || !a.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("session = %+v", a)
+	}
+	now = now.Add(time.Minute)
+	b, _ := s.Create(ctx, "u1", nil)
+	s.Create(ctx, "u2", nil)
+
+	t.Run("get returns a copy", func(t *testing.T) {
+		got, err := s.Get(ctx, a.ID)
+		if err != nil || got.Data["ua"] != "firefox" {
+			t.Fatalf("Get() = %+v, %v", got, err)
+		}
+		got.Data["ua"] = "changed"
+		if again, _ := s.Get(ctx, a.ID); again.Data["ua"] != "firefox" {
+			t.Error("Get() exposed stored data")
+		}
+		if _, err := s.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("sliding expiration", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			now = now.Add(40 * time.Minute)
+			got, err := s.Touch(ctx, a.ID)
+			if err != nil {
+				t.Fatalf("Touch() after %v error = %v", now.Sub(a.CreatedAt), err)
+			}
+			if i == 3 && !got.ExpiresAt.Equal(a.CreatedAt.Add(3*time.Hour)) {
+				t.Errorf("ExpiresAt = %v, want capped by MaxLifetime", got.ExpiresAt)
+			}
+			if i == 0 && !got.LastSeen.Equal(now) {
+				t.Errorf("LastSeen = %v, want %v", got.LastSeen, now)
+			}
+		}
+		// b was never touched and expired an hour after creation
+		if _, err := s.Get(ctx, b.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("idle session error = %v, want ErrNotFound", err)
+		}
+		now = a.CreatedAt.Add(3 * time.Hour)
+		if _, err := s.Touch(ctx, a.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Touch() past MaxLifetime error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestMemoryStoreUsers(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(DefaultConfig())
+	base := time.Unix(1700000000, 0)
+	var ids []string
+	for i := 0; i < 3; i++ {
+		now := base.Add(time.Duration(i) * time.Second)
+		s.now = func() time.Time { return now }
+		sess, _ := s.Create(ctx, "u1", nil)
+		ids = append(ids, sess.ID)
+	}
+	s.Create(ctx, "u2", nil)
+
+	list, err := s.List(ctx, "u1")
+	if err != nil || len(list) != 3 {
+		t.Fatalf("List() = %d sessions, %v", len(list), err)
+	}
+	for i, sess := range list {
+		if sess.ID != ids[i] {
+			t.Errorf("session %d = %s, want oldest first", i, sess.ID)
+		}
+	}
+
+	if err := s.Destroy(ctx, ids[0]); err != nil {
+		t.Fatalf("Destroy() error = %v", err)
+	}
+	if err := s.Destroy(ctx, ids[0]); err != nil {
+		t.Errorf("second Destroy() error = %v", err)
+	}
+	n, err := s.DestroyAll(ctx, "u1")
+	if err != nil || n != 2 {
+		t.Fatalf("DestroyAll() = %d, %v, want 2", n, err)
+	}
+	if list, _ := s.List(ctx, "u1"); len(list) != 0 {
+		t.Errorf("List() after DestroyAll = %d sessions", len(list))
+	}
+	if list, _ := s.List(ctx, "u2"); len(list) != 1 {
+		t.Errorf("other user's sessions = %d, want 1", len(list))
+	}
+}
@@ -0,0 +1,60 @@
+package sms
+
+import (
+	"context"
+	"sync"
+)
+
+// MockSender records messages instead of sending them, for tests and local
+// development
+type MockSender struct {
+	mu       sync.Mutex
+	messages []*Message
+	// Err, when set, is returned by Send and nothing is recorded
+	Err error
+}
+
+// NewMockSender creates an empty mock sender
+func NewMockSender() *MockSender {
+	return &MockSender{}
+}
+
+// Send records msg
+func (s *MockSender) Send(ctx context.Context, msg *Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far
+func (s *MockSender) Messages() []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Message(nil), s.messages...)
+}
+
+// Last returns the last message sent to phone, or nil
+func (s *MockSender) Last(phone string) *Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].Phone == phone {
+			return s.messages[i]
+		}
+	}
+	return nil
+}
+
+// Reset forgets the recorded messages
+func (s *MockSender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = nil
+}
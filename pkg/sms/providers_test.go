@@ -0,0 +1,177 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAliyunSign(t *testing.T) {
+	// Example from the Aliyun signature documentation
+	params := url.Values{}
+	for k, v := range map[string]string{
+		"AccessKeyId": "testId", "Action": "SendSms", "Format": "XML", "OutId": "123",
+		"PhoneNumbers": "15300000001", "RegionId": "cn-hangzhou", "SignName": "阿里云短信测试专用",
+		"SignatureMethod": "HMAC-SHA1", "SignatureNonce": "45e25e9b-0a6f-4070-8c85-2956eda1b466",
+		"SignatureVersion": "1.0", "TemplateCode": "SMS_71390007", "TemplateParam": `{"customer":"test"}`,
+		"Timestamp": "2017-07-12T02:42:19Z", "Version": "2017-05-25",
+	} {
+		params.Set(k, v)
+	}
+	if got := aliyunSign("testSecret", http.MethodGet, aliyunQuery(params)); got != "zJDF+Lrzhj/ThnlvIToysFRq6t4=" {
+		t.Errorf("aliyunSign() = %s", got)
+	}
+}
+
+func TestAliyunSender(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if query.Get("PhoneNumbers") == "+8613800000000" {
+			w.Write([]byte(`{"Code":"OK","Message":"OK","RequestId":"r1"}`))
+			return
+		}
+		w.Write([]byte(`{"Code":"isv.BUSINESS_LIMIT_CONTROL","Message":"limited","RequestId":"r2"}`))
+	}))
+	defer server.Close()
+
+	cfg := DefaultAliyunConfig()
+	cfg.AccessKeyID = "id"
+	cfg.AccessKeySecret = "secret"
+	cfg.SignName = "Mora"
+	cfg.Endpoint = server.URL
+	sender, err := NewAliyunSender(cfg)
+	if err != nil {
+		t.Fatalf("NewAliyunSender() error = %v", err)
+	}
+
+	msg := &Message{Phone: "+8613800000000", Template: "SMS_1", Params: map[string]string{"code": "123456"}}
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if query.Get("SignName") != "Mora" || query.Get("TemplateParam") != `{"code":"123456"}` {
+		t.Errorf("query = %v", query)
+	}
+	signature := query.Get("Signature")
+	query.Del("Signature")
+	if want := aliyunSign("secret", http.MethodGet, aliyunQuery(query)); signature != want {
+		t.Errorf("Signature = %s, want %s", signature, want)
+	}
+
+	err = sender.Send(context.Background(), &Message{Phone: "+8613800000001", Template: "SMS_1"})
+	var perr *ProviderError
+	if !errors.As(err, &perr) || perr.Code != "isv.BUSINESS_LIMIT_CONTROL" || perr.RequestID != "r2" {
+		t.Errorf("Send() error = %v, want a provider error", err)
+	}
+}
+
+func TestTencentSender(t *testing.T) {
+	var body map[string]any
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		if body["TemplateId"] == "bad" {
+			w.Write([]byte(`{"Response":{"Error":{"Code":"AuthFailure.SignatureFailure","Message":"bad signature"},"RequestId":"r1"}}`))
+			return
+		}
+		w.Write([]byte(`{"Response":{"SendStatusSet":[{"Code":"Ok","Message":"send success"}],"RequestId":"r2"}}`))
+	}))
+	defer server.Close()
+
+	cfg := DefaultTencentConfig()
+	cfg.SecretID = "AKID"
+	cfg.SecretKey = "key"
+	cfg.SDKAppID = "1400000000"
+	cfg.SignName = "Mora"
+	cfg.Endpoint = server.URL
+	sender, err := NewTencentSender(cfg)
+	if err != nil {
+		t.Fatalf("NewTencentSender() error = %v", err)
+	}
+
+	msg := &Message{Phone: "+8613800000000", Template: "1234", Params: map[string]string{"1": "123456", "2": "5"}}
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if params, _ := json.Marshal(body["TemplateParamSet"]); string(params) != `["123456","5"]` {
+		t.Errorf("TemplateParamSet = %s", params)
+	}
+	if header.Get("X-TC-Action") != "SendSms" || header.Get("X-TC-Region") != "ap-guangzhou" {
+		t.Errorf("headers = %v", header)
+	}
+	auth := header.Get("Authorization")
+	if !strings.HasPrefix(auth, "TC3-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/sms/tc3_request, SignedHeaders=content-type;host, Signature=") {
+		t.Errorf("Authorization = %s", auth)
+	}
+
+	err = sender.Send(context.Background(), &Message{Phone: "+8613800000000", Template: "bad"})
+	var perr *ProviderError
+	if !errors.As(err, &perr) || perr.Code != "AuthFailure.SignatureFailure" {
+		t.Errorf("Send() error = %v, want a provider error", err)
+	}
+}
+
+func TestTwilioSender(t *testing.T) {
+	var form url.Values
+	var user, pass string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2010-04-01/Accounts/AC1/Messages.json" {
+			http.NotFound(w, r)
+			return
+		}
+		user, pass, _ = r.BasicAuth()
+		r.ParseForm()
+		form = r.PostForm
+		if form.Get("To") == "+1invalid" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":21211,"message":"Invalid 'To' Phone Number","status":400}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM1","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	cfg := DefaultTwilioConfig()
+	cfg.AccountSID = "AC1"
+	cfg.AuthToken = "token"
+	cfg.From = "+15550000000"
+	cfg.Templates = map[string]string{"verify": "Your code is {{.code}}"}
+	cfg.Endpoint = server.URL
+	sender, err := NewTwilioSender(cfg)
+	if err != nil {
+		t.Fatalf("NewTwilioSender() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		msg      Message
+		wantErr  bool
+		wantBody string
+	}{
+		{"sent", Message{Phone: "+15551234567", Template: "verify", Params: map[string]string{"code": "123456"}}, false, "Your code is 123456"},
+		{"missing param", Message{Phone: "+15551234567", Template: "verify"}, true, ""},
+		{"unknown template", Message{Phone: "+15551234567", Template: "reset"}, true, ""},
+		{"provider error", Message{Phone: "+1invalid", Template: "verify", Params: map[string]string{"code": "1"}}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form = nil
+			err := sender.Send(context.Background(), &tt.msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantBody != "" && (form.Get("Body") != tt.wantBody || form.Get("From") != cfg.From || user != "AC1" || pass != "token") {
+				t.Errorf("request = %v (%s:%s)", form, user, pass)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mora/pkg/cache"
+)
+
+// RateLimitConfig limits how often one number can be sent messages
+type RateLimitConfig struct {
+	// Interval is the minimum gap between two messages to a number
+	Interval time.Duration `json:"interval" yaml:"interval" env:"INTERVAL"`
+	// Limit messages are allowed per Window
+	Limit  int           `json:"limit" yaml:"limit" env:"LIMIT"`
+	Window time.Duration `json:"window" yaml:"window" env:"WINDOW"`
+	// Prefix is prepended to the cache keys
+	Prefix string `json:"prefix" yaml:"prefix" env:"PREFIX"`
+}
+
+// DefaultRateLimitConfig returns one message a minute and ten a day per number
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Interval: time.Minute,
+		Limit:    10,
+		Window:   24 * time.Hour,
+		Prefix:   "sms:ratelimit:",
+	}
+}
+
+// rateLimitScript checks the interval and window counters atomically.
+// It returns 0 when allowed, otherwise milliseconds until the next send.
+var rateLimitScript = redis.NewScript(`
+local wait = redis.call('PTTL', KEYS[1])
+if wait > 0 then
+	return wait
+end
+local n = redis.call('INCR', KEYS[2])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[2], ARGV[2])
+end
+if n > tonumber(ARGV[3]) then
+	redis.call('DECR', KEYS[2])
+	return redis.call('PTTL', KEYS[2])
+end
+if tonumber(ARGV[1]) > 0 then
+	redis.call('SET', KEYS[1], '1', 'PX', ARGV[1])
+end
+return 0
+`)
+
+// RateLimiter is a Limiter storing per-number counters in Redis
+type RateLimiter struct {
+	client *cache.Client
+	cfg    RateLimitConfig
+}
+
+// NewRateLimiter creates a rate limiter backed by client
+func NewRateLimiter(client *cache.Client, cfg RateLimitConfig) *RateLimiter {
+	defaults := DefaultRateLimitConfig()
+	if cfg.Limit <= 0 {
+		cfg.Limit = defaults.Limit
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaults.Window
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaults.Prefix
+	}
+	return &RateLimiter{client: client, cfg: cfg}
+}
+
+// Allow counts a message to phone, returning an error wrapping
+// ErrRateLimited when the number is over its limit
+func (l *RateLimiter) Allow(ctx context.Context, phone string) error {
+	keys := []string{l.cfg.Prefix + "interval:" + phone, l.cfg.Prefix + "window:" + phone}
+	wait, err := rateLimitScript.Run(ctx, l.client.GetClient(), keys,
+		l.cfg.Interval.Milliseconds(), l.cfg.Window.Milliseconds(), l.cfg.Limit).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to check sms rate limit: %w", err)
+	}
+	if wait > 0 {
+		return fmt.Errorf("%w: retry in %s", ErrRateLimited, (time.Duration(wait) * time.Millisecond).Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Package sms sends template text messages through Aliyun, Tencent Cloud or
+// Twilio, with per-number rate limiting backed by pkg/cache.
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	// ErrInvalidMessage is returned for messages missing a phone number or template
+	ErrInvalidMessage = errors.New("sms: invalid message")
+	// ErrRateLimited is returned when a number has been sent too many messages
+	ErrRateLimited = errors.New("sms: rate limited")
+)
+
+// Sender delivers text messages
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// SenderFunc adapts a function to Sender
+type SenderFunc func(ctx context.Context, msg *Message) error
+
+// Send calls f
+func (f SenderFunc) Send(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// Message is a text message rendered from a provider template
+type Message struct {
+	// Phone is the recipient in E.164 form, e.g. +8613800000000
+	Phone string
+	// Template is the Aliyun template code, the Tencent template ID or the
+	// name of a configured Twilio template
+	Template string
+	// Params fill the template; Tencent templates use positional keys "1", "2", ...
+	Params map[string]string
+	// SignName overrides the configured signature (Aliyun and Tencent)
+	SignName string
+}
+
+// validate checks the fields every provider needs
+func (m *Message) validate() error {
+	if m.Phone == "" {
+		return fmt.Errorf("%w: no phone number", ErrInvalidMessage)
+	}
+	if m.Template == "" {
+		return fmt.Errorf("%w: no template", ErrInvalidMessage)
+	}
+	return nil
+}
+
+// ProviderError is an error reported by an SMS provider
+type ProviderError struct {
+	Provider  string
+	Code      string
+	Message   string
+	RequestID string
+}
+
+// Error implements error
+func (e *ProviderError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s sms error %s: %s (request %s)", e.Provider, e.Code, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("%s sms error %s: %s", e.Provider, e.Code, e.Message)
+}
+
+// Limiter decides whether a number may be sent another message
+type Limiter interface {
+	// Allow returns an error wrapping ErrRateLimited when phone is over its limit
+	Allow(ctx context.Context, phone string) error
+}
+
+// WithRateLimit wraps sender so each message first passes limiter
+func WithRateLimit(sender Sender, limiter Limiter) Sender {
+	return SenderFunc(func(ctx context.Context, msg *Message) error {
+		if err := msg.validate(); err != nil {
+			return err
+		}
+		if err := limiter.Allow(ctx, msg.Phone); err != nil {
+			return err
+		}
+		return sender.Send(ctx, msg)
+	})
+}
+
+// doJSON sends req and decodes the JSON response into out; providers
+// report errors in the body, so the status is only checked when it is not JSON
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sms request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read sms response: %w", err)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("unexpected sms response %s: %.200s", resp.Status, body)
+	}
+	return nil
+}
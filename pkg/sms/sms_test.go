@@ -0,0 +1,83 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// fakeLimiter allows a fixed number of messages per phone
+type fakeLimiter struct {
+	limit int
+	sent  map[string]int
+}
+
+func (l *fakeLimiter) Allow(ctx context.Context, phone string) error {
+	if l.sent[phone] >= l.limit {
+		return fmt.Errorf("%w: retry in 1m0s", ErrRateLimited)
+	}
+	l.sent[phone]++
+	return nil
+}
+
+func TestWithRateLimit(t *testing.T) {
+	mock := NewMockSender()
+	sender := WithRateLimit(mock, &fakeLimiter{limit: 2, sent: map[string]int{}})
+	ctx := context.Background()
+
+	tests := []struct {
+		phone   string
+		wantErr error
+	}{
+		{"+8613800000001", nil},
+		{"+8613800000001", nil},
+		{"+8613800000001", ErrRateLimited},
+		{"+8613800000002", nil},
+		{"", ErrInvalidMessage},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d_%s", i, tt.phone), func(t *testing.T) {
+			err := sender.Send(ctx, &Message{Phone: tt.phone, Template: "SMS_1", Params: map[string]string{"code": "123456"}})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Send() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := len(mock.Messages()); got != 3 {
+		t.Errorf("sent %d messages, want 3", got)
+	}
+	if last := mock.Last("+8613800000002"); last == nil || last.Params["code"] != "123456" {
+		t.Errorf("Last() = %+v", last)
+	}
+	mock.Reset()
+	if mock.Last("+8613800000002") != nil {
+		t.Error("Reset() should forget messages")
+	}
+}
+
+func TestPositionalParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    []string
+		wantErr bool
+	}{
+		{"none", nil, []string{}, false},
+		{"ordered", map[string]string{"2": "5", "1": "123456"}, []string{"123456", "5"}, false},
+		{"named", map[string]string{"code": "123456"}, nil, true},
+		{"gap", map[string]string{"1": "a", "3": "c"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := positionalParams(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("positionalParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) && !tt.wantErr {
+				t.Errorf("positionalParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"mora/pkg/utils"
+)
+
+// TencentConfig configures the Tencent Cloud SMS sender
+type TencentConfig struct {
+	SecretID  string `json:"secret_id" yaml:"secret_id" env:"SECRET_ID"`
+	SecretKey string `json:"secret_key" yaml:"secret_key" env:"SECRET_KEY"`
+	// SDKAppID is the SMS application ID (SmsSdkAppId)
+	SDKAppID string        `json:"sdk_app_id" yaml:"sdk_app_id" env:"SDK_APP_ID"`
+	SignName string        `json:"sign_name" yaml:"sign_name" env:"SIGN_NAME"`
+	Region   string        `json:"region" yaml:"region" env:"REGION"`
+	Endpoint string        `json:"endpoint" yaml:"endpoint" env:"ENDPOINT"`
+	Timeout  time.Duration `json:"timeout" yaml:"timeout" env:"TIMEOUT"`
+}
+
+// DefaultTencentConfig returns default Tencent Cloud configuration
+func DefaultTencentConfig() TencentConfig {
+	return TencentConfig{
+		Region:   "ap-guangzhou",
+		Endpoint: "https://sms.tencentcloudapi.com",
+		Timeout:  10 * time.Second,
+	}
+}
+
+// TencentSender sends messages with the SMS API 3.0 SendSms action
+type TencentSender struct {
+	cfg    TencentConfig
+	host   string
+	client *http.Client
+}
+
+// NewTencentSender creates a Tencent Cloud sender
+func NewTencentSender(cfg TencentConfig) (*TencentSender, error) {
+	if cfg.SecretID == "" || cfg.SecretKey == "" {
+		return nil, errors.New("tencent secret is required")
+	}
+	if cfg.SDKAppID == "" {
+		return nil, errors.New("tencent sdk app id is required")
+	}
+	defaults := DefaultTencentConfig()
+	if cfg.Region == "" {
+		cfg.Region = defaults.Region
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = defaults.Endpoint
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tencent endpoint: %w", err)
+	}
+	return &TencentSender{cfg: cfg, host: u.Host, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+// Send implements Sender
+func (s *TencentSender) Send(ctx context.Context, msg *Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	params, err := positionalParams(msg.Params)
+	if err != nil {
+		return err
+	}
+	signName := msg.SignName
+	if signName == "" {
+		signName = s.cfg.SignName
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"PhoneNumberSet":   []string{msg.Phone},
+		"SmsSdkAppId":      s.cfg.SDKAppID,
+		"SignName":         signName,
+		"TemplateId":       msg.Template,
+		"TemplateParamSet": params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode tencent request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create tencent request: %w", err)
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-TC-Action", "SendSms")
+	req.Header.Set("X-TC-Version", "2021-01-11")
+	req.Header.Set("X-TC-Region", s.cfg.Region)
+	req.Header.Set("X-TC-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set("Authorization", tencentAuthorization(s.cfg.SecretID, s.cfg.SecretKey, s.host, payload, now))
+
+	var resp struct {
+		Response struct {
+			Error *struct {
+				Code    string `json:"Code"`
+				Message string `json:"Message"`
+			} `json:"Error"`
+			SendStatusSet []struct {
+				Code    string `json:"Code"`
+				Message string `json:"Message"`
+			} `json:"SendStatusSet"`
+			RequestID string `json:"RequestId"`
+		} `json:"Response"`
+	}
+	if err := doJSON(s.client, req, &resp); err != nil {
+		return err
+	}
+	r := resp.Response
+	if r.Error != nil {
+		return &ProviderError{Provider: "tencent", Code: r.Error.Code, Message: r.Error.Message, RequestID: r.RequestID}
+	}
+	for _, status := range r.SendStatusSet {
+		if status.Code != "Ok" {
+			return &ProviderError{Provider: "tencent", Code: status.Code, Message: status.Message, RequestID: r.RequestID}
+		}
+	}
+	return nil
+}
+
+// positionalParams orders params keyed "1", "2", ... as Tencent templates expect
+func positionalParams(params map[string]string) ([]string, error) {
+	out := make([]string, len(params))
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 1 || i > len(params) {
+			return nil, fmt.Errorf("%w: tencent template params must be keyed 1 to %d, got %q", ErrInvalidMessage, len(params), k)
+		}
+		out[i-1] = params[k]
+	}
+	return out, nil
+}
+
+// tencentAuthorization signs a JSON POST with TC3-HMAC-SHA256
+func tencentAuthorization(secretID, secretKey, host string, payload []byte, now time.Time) string {
+	const service = "sms"
+	date := now.UTC().Format("2006-01-02")
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	canonicalRequest := "POST\n/\n\n" +
+		"content-type:application/json; charset=utf-8\nhost:" + host + "\n\n" +
+		"content-type;host\n" + utils.HashSHA256(string(payload))
+	scope := date + "/" + service + "/tc3_request"
+	stringToSign := "TC3-HMAC-SHA256\n" + timestamp + "\n" + scope + "\n" + utils.HashSHA256(canonicalRequest)
+
+	key := utils.HMAC(utils.HMACSHA256, []byte("TC3"+secretKey), []byte(date))
+	key = utils.HMAC(utils.HMACSHA256, key, []byte(service))
+	key = utils.HMAC(utils.HMACSHA256, key, []byte("tc3_request"))
+	signature := hex.EncodeToString(utils.HMAC(utils.HMACSHA256, key, []byte(stringToSign)))
+
+	return "TC3-HMAC-SHA256 Credential=" + secretID + "/" + scope +
+		", SignedHeaders=content-type;host, Signature=" + signature
+}
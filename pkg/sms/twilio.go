@@ -0,0 +1,115 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TwilioConfig configures the Twilio sender. Twilio has no server-side
+// templates, so message bodies are rendered from Templates.
+type TwilioConfig struct {
+	AccountSID string `json:"account_sid" yaml:"account_sid" env:"ACCOUNT_SID"`
+	AuthToken  string `json:"auth_token" yaml:"auth_token" env:"AUTH_TOKEN"`
+	// From is the sending number; MessagingServiceSID is used instead when set
+	From                string `json:"from" yaml:"from" env:"FROM"`
+	MessagingServiceSID string `json:"messaging_service_sid" yaml:"messaging_service_sid" env:"MESSAGING_SERVICE_SID"`
+	// Templates maps template names to text/template bodies, e.g.
+	// {"verify": "Your code is {{.code}}"}
+	Templates map[string]string `json:"templates" yaml:"templates"`
+	Endpoint  string            `json:"endpoint" yaml:"endpoint" env:"ENDPOINT"`
+	Timeout   time.Duration     `json:"timeout" yaml:"timeout" env:"TIMEOUT"`
+}
+
+// DefaultTwilioConfig returns default Twilio configuration
+func DefaultTwilioConfig() TwilioConfig {
+	return TwilioConfig{
+		Endpoint: "https://api.twilio.com",
+		Timeout:  10 * time.Second,
+	}
+}
+
+// TwilioSender sends messages with the Programmable Messaging API
+type TwilioSender struct {
+	cfg       TwilioConfig
+	templates map[string]*template.Template
+	client    *http.Client
+}
+
+// NewTwilioSender creates a Twilio sender and parses its templates
+func NewTwilioSender(cfg TwilioConfig) (*TwilioSender, error) {
+	if cfg.AccountSID == "" || cfg.AuthToken == "" {
+		return nil, errors.New("twilio account sid and auth token are required")
+	}
+	if cfg.From == "" && cfg.MessagingServiceSID == "" {
+		return nil, errors.New("twilio from number or messaging service sid is required")
+	}
+	defaults := DefaultTwilioConfig()
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = defaults.Endpoint
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+
+	templates := make(map[string]*template.Template, len(cfg.Templates))
+	for name, body := range cfg.Templates {
+		t, err := template.New(name).Option("missingkey=error").Parse(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse twilio template %s: %w", name, err)
+		}
+		templates[name] = t
+	}
+	return &TwilioSender{cfg: cfg, templates: templates, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+// Send implements Sender
+func (s *TwilioSender) Send(ctx context.Context, msg *Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	tmpl, ok := s.templates[msg.Template]
+	if !ok {
+		return fmt.Errorf("%w: unknown twilio template %q", ErrInvalidMessage, msg.Template)
+	}
+	var body strings.Builder
+	if err := tmpl.Execute(&body, msg.Params); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	form := url.Values{}
+	form.Set("To", msg.Phone)
+	form.Set("Body", body.String())
+	if s.cfg.MessagingServiceSID != "" {
+		form.Set("MessagingServiceSid", s.cfg.MessagingServiceSID)
+	} else {
+		form.Set("From", s.cfg.From)
+	}
+
+	endpoint := s.cfg.Endpoint + "/2010-04-01/Accounts/" + url.PathEscape(s.cfg.AccountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create twilio request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.cfg.AccountSID, s.cfg.AuthToken)
+
+	var resp struct {
+		SID     string `json:"sid"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := doJSON(s.client, req, &resp); err != nil {
+		return err
+	}
+	if resp.Code != 0 || resp.SID == "" {
+		return &ProviderError{Provider: "twilio", Code: strconv.Itoa(resp.Code), Message: resp.Message}
+	}
+	return nil
+}
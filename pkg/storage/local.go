@@ -0,0 +1,411 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mora/pkg/utils"
+)
+
+// uploadsDir holds in-progress multipart uploads under the root
+const uploadsDir = ".uploads"
+
+// LocalConfig configures the local filesystem backend
+type LocalConfig struct {
+	// Root is the directory objects are stored in
+	Root string `json:"root" yaml:"root" env:"ROOT"`
+	// BaseURL is where Handler is mounted, used for presigned URLs
+	BaseURL string `json:"base_url" yaml:"base_url" env:"BASE_URL"`
+	// Secret signs presigned URLs; presigning is disabled without it
+	Secret string `json:"secret" yaml:"secret" env:"SECRET"`
+}
+
+// DefaultLocalConfig returns default local storage configuration
+func DefaultLocalConfig() LocalConfig {
+	return LocalConfig{
+		Root:    "./data/storage",
+		BaseURL: "http://localhost:8080/files",
+	}
+}
+
+// Local stores objects as files under a root directory
+type Local struct {
+	cfg  LocalConfig
+	root string
+}
+
+// NewLocal creates a local backend, creating the root directory if needed
+func NewLocal(cfg LocalConfig) (*Local, error) {
+	if cfg.Root == "" {
+		return nil, errors.New("local storage root is required")
+	}
+	root, err := filepath.Abs(cfg.Root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local storage root: %w", err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create local storage root: %w", err)
+	}
+	return &Local{cfg: cfg, root: root}, nil
+}
+
+// path maps a key to its file, rejecting keys outside the root
+func (l *Local) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	clean := path.Clean(key)
+	if clean != key || clean == "." || strings.HasPrefix(clean, "../") || clean == ".." ||
+		clean == uploadsDir || strings.HasPrefix(clean, uploadsDir+"/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return filepath.Join(l.root, filepath.FromSlash(clean)), nil
+}
+
+// Put implements Storage
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, opts *PutOptions) (*Object, error) {
+	name, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(name, r); err != nil {
+		return nil, err
+	}
+	return l.Stat(ctx, key)
+}
+
+// writeFile writes r to name through a temporary file so readers never see
+// a partial object
+func writeFile(name string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// Get implements Storage
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	name, err := l.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, localError(key, err)
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		f.Close()
+		return nil, nil, localError(key, fs.ErrNotExist)
+	}
+	return f, localObject(key, info), nil
+}
+
+// Stat implements Storage
+func (l *Local) Stat(ctx context.Context, key string) (*Object, error) {
+	name, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(name)
+	if err == nil && info.IsDir() {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		return nil, localError(key, err)
+	}
+	return localObject(key, info), nil
+}
+
+// Delete implements Storage
+func (l *Local) Delete(ctx context.Context, key string) error {
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	// Prune directories left empty, up to the root
+	for dir := filepath.Dir(name); dir != l.root; dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// List implements Storage
+func (l *Local) List(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	var keys []string
+	err := filepath.WalkDir(l.root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(l.root, name)
+		key := filepath.ToSlash(rel)
+		if d.IsDir() {
+			if key == uploadsDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".tmp-") || !strings.HasPrefix(key, opts.Prefix) || key <= opts.Cursor {
+			return nil
+		}
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	// WalkDir orders by path segment, which differs from plain key order
+	sort.Strings(keys)
+
+	result := &ListResult{}
+	for _, key := range keys {
+		if len(result.Objects) == limit {
+			result.NextCursor = result.Objects[limit-1].Key
+			break
+		}
+		info, err := os.Stat(filepath.Join(l.root, filepath.FromSlash(key)))
+		if err != nil {
+			// Deleted while listing
+			continue
+		}
+		result.Objects = append(result.Objects, *localObject(key, info))
+	}
+	return result, nil
+}
+
+// PresignedURL implements Storage; the URL is served by Handler under BaseURL
+func (l *Local) PresignedURL(method, key string, ttl time.Duration) (string, error) {
+	if l.cfg.Secret == "" {
+		return "", errors.New("local storage secret is required for presigned urls")
+	}
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("signature", l.signature(method, key, expires))
+	return strings.TrimSuffix(l.cfg.BaseURL, "/") + "/" + escapeKey(key) + "?" + q.Encode(), nil
+}
+
+// signature signs a presigned request
+func (l *Local) signature(method, key, expires string) string {
+	return hex.EncodeToString(utils.HMAC(utils.HMACSHA256, []byte(l.cfg.Secret), []byte(method+"\n"+key+"\n"+expires)))
+}
+
+// Handler serves presigned GET and PUT requests; mount it at BaseURL with
+// the prefix stripped, e.g. http.StripPrefix("/files", local.Handler())
+func (l *Local) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		method := r.Method
+		if method == http.MethodHead {
+			method = http.MethodGet
+		}
+		expires := r.URL.Query().Get("expires")
+		unix, err := strconv.ParseInt(expires, 10, 64)
+		want := l.signature(method, key, expires)
+		if l.cfg.Secret == "" || err != nil || time.Now().Unix() > unix ||
+			!hmac.Equal([]byte(want), []byte(r.URL.Query().Get("signature"))) {
+			http.Error(w, "invalid or expired signature", http.StatusForbidden)
+			return
+		}
+
+		switch method {
+		case http.MethodGet:
+			rc, obj, err := l.Get(r.Context(), key)
+			if err != nil {
+				localHTTPError(w, err)
+				return
+			}
+			defer rc.Close()
+			if obj.ContentType != "" {
+				w.Header().Set("Content-Type", obj.ContentType)
+			}
+			w.Header().Set("ETag", obj.ETag)
+			http.ServeContent(w, r, path.Base(key), obj.LastModified, rc.(io.ReadSeeker))
+		case http.MethodPut:
+			obj, err := l.Put(r.Context(), key, r.Body, nil)
+			if err != nil {
+				localHTTPError(w, err)
+				return
+			}
+			w.Header().Set("ETag", obj.ETag)
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// CreateMultipartUpload implements Multipart
+func (l *Local) CreateMultipartUpload(ctx context.Context, key string, opts *PutOptions) (string, error) {
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	id := hex.EncodeToString(b)
+	dir := filepath.Join(l.root, uploadsDir, id)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create upload: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "key"), []byte(key), 0o644); err != nil {
+		return "", fmt.Errorf("failed to create upload: %w", err)
+	}
+	return id, nil
+}
+
+// uploadDir returns the directory of an upload after checking it belongs to key
+func (l *Local) uploadDir(key, uploadID string) (string, error) {
+	if uploadID == "" || strings.ContainsAny(uploadID, `/\.`) {
+		return "", fmt.Errorf("%w: upload %s", ErrNotFound, uploadID)
+	}
+	dir := filepath.Join(l.root, uploadsDir, uploadID)
+	owner, err := os.ReadFile(filepath.Join(dir, "key"))
+	if err != nil || string(owner) != key {
+		return "", fmt.Errorf("%w: upload %s", ErrNotFound, uploadID)
+	}
+	return dir, nil
+}
+
+// UploadPart implements Multipart
+func (l *Local) UploadPart(ctx context.Context, key, uploadID string, n int, r io.Reader, size int64) (Part, error) {
+	dir, err := l.uploadDir(key, uploadID)
+	if err != nil {
+		return Part{}, err
+	}
+	if n < 1 {
+		return Part{}, fmt.Errorf("invalid part number %d", n)
+	}
+	name := filepath.Join(dir, fmt.Sprintf("%05d", n))
+	if err := writeFile(name, r); err != nil {
+		return Part{}, err
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return Part{}, fmt.Errorf("failed to write part: %w", err)
+	}
+	return Part{Number: n, ETag: localETag(info)}, nil
+}
+
+// CompleteMultipartUpload implements Multipart
+func (l *Local) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []Part) (*Object, error) {
+	dir, err := l.uploadDir(key, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	name, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]io.Reader, 0, len(parts))
+	for _, p := range parts {
+		f, err := os.Open(filepath.Join(dir, fmt.Sprintf("%05d", p.Number)))
+		if err != nil {
+			return nil, fmt.Errorf("part %d of upload %s: %w", p.Number, uploadID, ErrNotFound)
+		}
+		defer f.Close()
+		if info, err := f.Stat(); err != nil || localETag(info) != p.ETag {
+			return nil, fmt.Errorf("part %d of upload %s has a different etag", p.Number, uploadID)
+		}
+		files = append(files, f)
+	}
+	if err := writeFile(name, io.MultiReader(files...)); err != nil {
+		return nil, err
+	}
+	os.RemoveAll(dir)
+	return l.Stat(ctx, key)
+}
+
+// AbortMultipartUpload implements Multipart
+func (l *Local) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	dir, err := l.uploadDir(key, uploadID)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to abort upload: %w", err)
+	}
+	return nil
+}
+
+// localObject describes a file
+func localObject(key string, info fs.FileInfo) *Object {
+	return &Object{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  mime.TypeByExtension(path.Ext(key)),
+		ETag:         localETag(info),
+		LastModified: info.ModTime(),
+	}
+}
+
+// localETag derives an ETag from the modification time and size, as web
+// servers do for static files
+func localETag(info fs.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
+
+// localError maps a missing file to ErrNotFound
+func localError(key string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return fmt.Errorf("failed to open %s: %w", key, err)
+}
+
+// localHTTPError writes the status for a storage error
+func localHTTPError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, ErrInvalidKey):
+		http.Error(w, "invalid key", http.StatusBadRequest)
+	default:
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
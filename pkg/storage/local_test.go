@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocal(t *testing.T) *Local {
+	t.Helper()
+
+	cfg := DefaultLocalConfig()
+	cfg.Root = t.TempDir()
+	cfg.Secret = "secret"
+	l, err := NewLocal(cfg)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	return l
+}
+
+func TestLocal(t *testing.T) {
+	l := newTestLocal(t)
+	testStorage(t, l)
+
+	// Deleting the last object of a directory prunes it
+	if _, err := os.Stat(filepath.Join(l.root, "list", "b")); !os.IsNotExist(err) {
+		t.Errorf("empty directory not pruned: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(l.root, uploadsDir)); len(entries) != 0 {
+		t.Errorf("uploads left behind: %v", entries)
+	}
+}
+
+func TestLocalInvalidKeys(t *testing.T) {
+	l := newTestLocal(t)
+	for _, key := range []string{"", "/abs", "../escape", "a/../../b", "a//b", ".uploads/x", "."} {
+		t.Run(key, func(t *testing.T) {
+			if _, err := l.Put(context.Background(), key, strings.NewReader("x"), nil); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+			}
+		})
+	}
+}
+
+func TestLocalPresignedURL(t *testing.T) {
+	l := newTestLocal(t)
+	server := httptest.NewServer(http.StripPrefix("/files", l.Handler()))
+	defer server.Close()
+	l.cfg.BaseURL = server.URL + "/files"
+
+	putURL, err := l.PresignedURL(http.MethodPut, "avatars/u 1.png", time.Minute)
+	if err != nil {
+		t.Fatalf("PresignedURL() error = %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, putURL, strings.NewReader("png"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("PUT presigned url = %v, %v", resp, err)
+	}
+	resp.Body.Close()
+
+	getURL, _ := l.PresignedURL(http.MethodGet, "avatars/u 1.png", time.Minute)
+	resp, err = http.Get(getURL)
+	if err != nil {
+		t.Fatalf("GET presigned url error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "png" || resp.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("GET presigned url = %d %q %s", resp.StatusCode, body, resp.Header.Get("Content-Type"))
+	}
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"put url used for get", putURL},
+		{"tampered key", strings.Replace(getURL, "u%201", "u%202", 1)},
+		{"no signature", server.URL + "/files/avatars/u%201.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(tt.url)
+			if err != nil {
+				t.Fatalf("GET error = %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusForbidden {
+				t.Errorf("status = %d, want 403", resp.StatusCode)
+			}
+		})
+	}
+
+	expired, _ := l.PresignedURL(http.MethodGet, "avatars/u 1.png", -time.Second)
+	resp, _ = http.Get(expired)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expired url status = %d, want 403", resp.StatusCode)
+	}
+}
@@ -0,0 +1,391 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mora/pkg/internal/sigv4"
+)
+
+// signer authenticates requests to an S3-style API
+type signer interface {
+	sign(req *http.Request)
+	presign(method string, u *url.URL, ttl time.Duration) error
+}
+
+// restClient speaks the object API shared by S3 and OSS
+type restClient struct {
+	client    *http.Client
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	// prefix starts the provider's headers: x-amz- or x-oss-
+	prefix   string
+	provider string
+	signer   signer
+}
+
+// objectURL returns the URL of key (the bucket when key is empty)
+func (c *restClient) objectURL(key string, query url.Values) *url.URL {
+	u := *c.endpoint
+	if c.pathStyle {
+		u.Path = "/" + c.bucket + "/" + key
+		u.RawPath = "/" + escapeKey(c.bucket) + "/" + escapeKey(key)
+	} else {
+		u.Host = c.bucket + "." + u.Host
+		u.Path = "/" + key
+		u.RawPath = "/" + escapeKey(key)
+	}
+	u.RawQuery = encodeQuery(query)
+	return &u
+}
+
+// do signs and sends a request, returning an error for non-2xx responses
+func (c *restClient) do(ctx context.Context, method, key string, query url.Values, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.objectURL(key, query).String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", c.provider, err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil && size >= 0 {
+		req.ContentLength = size
+	}
+	c.signer.sign(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", c.provider, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, c.parseError(key, resp)
+	}
+	return resp, nil
+}
+
+// APIError is an error returned by S3 or OSS
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+	RequestID  string `xml:"RequestId"`
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s error %d %s: %s (request %s)", e.Provider, e.StatusCode, e.Code, e.Message, e.RequestID)
+}
+
+// parseError decodes an error response; missing objects become ErrNotFound
+func (c *restClient) parseError(key string, resp *http.Response) error {
+	apiErr := &APIError{Provider: c.provider, StatusCode: resp.StatusCode}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(body, apiErr) != nil {
+		apiErr.Code = http.StatusText(resp.StatusCode)
+	}
+	if resp.StatusCode == http.StatusNotFound && (apiErr.Code == "NoSuchKey" || apiErr.Code == "NoSuchUpload" || len(body) == 0) {
+		return fmt.Errorf("%w: %s: %v", ErrNotFound, key, apiErr)
+	}
+	return apiErr
+}
+
+// put uploads an object or a part, buffering readers of unknown length since
+// both services need a Content-Length
+func (c *restClient) put(ctx context.Context, key string, query url.Values, header http.Header, r io.Reader, size int64) (*http.Response, error) {
+	if size <= 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read object: %w", err)
+		}
+		r, size = bytes.NewReader(data), int64(len(data))
+	}
+	return c.do(ctx, http.MethodPut, key, query, header, r, size)
+}
+
+// Put implements Storage
+func (c *restClient) Put(ctx context.Context, key string, r io.Reader, opts *PutOptions) (*Object, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	var size int64
+	if opts != nil {
+		size = opts.Size
+	}
+	resp, err := c.put(ctx, key, nil, c.putHeader(opts), r, size)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return c.Stat(ctx, key)
+}
+
+// putHeader converts PutOptions to request headers
+func (c *restClient) putHeader(opts *PutOptions) http.Header {
+	h := http.Header{}
+	if opts == nil {
+		return h
+	}
+	if opts.ContentType != "" {
+		h.Set("Content-Type", opts.ContentType)
+	}
+	if opts.CacheControl != "" {
+		h.Set("Cache-Control", opts.CacheControl)
+	}
+	if opts.ContentDisposition != "" {
+		h.Set("Content-Disposition", opts.ContentDisposition)
+	}
+	for k, v := range opts.Metadata {
+		h.Set(c.prefix+"meta-"+k, v)
+	}
+	return h
+}
+
+// Get implements Storage
+func (c *restClient) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	if err := checkKey(key); err != nil {
+		return nil, nil, err
+	}
+	resp, err := c.do(ctx, http.MethodGet, key, nil, nil, nil, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, c.object(key, resp), nil
+}
+
+// Stat implements Storage
+func (c *restClient) Stat(ctx context.Context, key string) (*Object, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, http.MethodHead, key, nil, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return c.object(key, resp), nil
+}
+
+// object reads object attributes from response headers
+func (c *restClient) object(key string, resp *http.Response) *Object {
+	obj := &Object{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        resp.Header.Get("ETag"),
+	}
+	obj.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	metaPrefix := http.CanonicalHeaderKey(c.prefix + "meta-")
+	for k, v := range resp.Header {
+		if strings.HasPrefix(k, metaPrefix) && len(v) > 0 {
+			if obj.Metadata == nil {
+				obj.Metadata = make(map[string]string)
+			}
+			obj.Metadata[strings.ToLower(strings.TrimPrefix(k, metaPrefix))] = v[0]
+		}
+	}
+	return obj
+}
+
+// Delete implements Storage
+func (c *restClient) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, http.MethodDelete, key, nil, nil, nil, 0)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// listBucketResult is a ListObjects (version 1) response
+type listBucketResult struct {
+	IsTruncated bool   `xml:"IsTruncated"`
+	NextMarker  string `xml:"NextMarker"`
+	Contents    []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		ETag         string    `xml:"ETag"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+// List implements Storage using ListObjects, which both services support
+func (c *restClient) List(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+	query := url.Values{}
+	query.Set("max-keys", strconv.Itoa(limit))
+	if opts.Prefix != "" {
+		query.Set("prefix", opts.Prefix)
+	}
+	if opts.Cursor != "" {
+		query.Set("marker", opts.Cursor)
+	}
+	resp, err := c.do(ctx, http.MethodGet, "", query, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out listBucketResult
+	if err := xml.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode %s list response: %w", c.provider, err)
+	}
+	result := &ListResult{Objects: make([]Object, 0, len(out.Contents))}
+	for _, o := range out.Contents {
+		result.Objects = append(result.Objects, Object{Key: o.Key, Size: o.Size, ETag: o.ETag, LastModified: o.LastModified})
+	}
+	if out.IsTruncated {
+		result.NextCursor = out.NextMarker
+		// NextMarker is only sent with a delimiter; the last key works as the marker
+		if result.NextCursor == "" && len(result.Objects) > 0 {
+			result.NextCursor = result.Objects[len(result.Objects)-1].Key
+		}
+	}
+	return result, nil
+}
+
+// PresignedURL implements Storage
+func (c *restClient) PresignedURL(method, key string, ttl time.Duration) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	u := c.objectURL(key, nil)
+	if err := c.signer.presign(method, u, ttl); err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// CreateMultipartUpload implements Multipart
+func (c *restClient) CreateMultipartUpload(ctx context.Context, key string, opts *PutOptions) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	resp, err := c.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, c.putHeader(opts), nil, 0)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&out); err != nil || out.UploadID == "" {
+		return "", fmt.Errorf("failed to decode %s multipart upload response: %v", c.provider, err)
+	}
+	return out.UploadID, nil
+}
+
+// UploadPart implements Multipart
+func (c *restClient) UploadPart(ctx context.Context, key, uploadID string, n int, r io.Reader, size int64) (Part, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(n)}, "uploadId": {uploadID}}
+	resp, err := c.put(ctx, key, query, nil, r, size)
+	if err != nil {
+		return Part{}, err
+	}
+	resp.Body.Close()
+	return Part{Number: n, ETag: resp.Header.Get("ETag")}, nil
+}
+
+// completeMultipartUpload is the CompleteMultipartUpload request body
+type completeMultipartUpload struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	} `xml:"Part"`
+}
+
+// CompleteMultipartUpload implements Multipart
+func (c *restClient) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []Part) (*Object, error) {
+	var body completeMultipartUpload
+	for _, p := range parts {
+		body.Parts = append(body.Parts, struct {
+			PartNumber int    `xml:"PartNumber"`
+			ETag       string `xml:"ETag"`
+		}{p.Number, p.ETag})
+	}
+	data, err := xml.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode parts: %w", err)
+	}
+	header := http.Header{"Content-Type": {"application/xml"}}
+	resp, err := c.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, header, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// S3 can report a failure with status 200 and an Error body
+	result, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if bytes.Contains(result, []byte("<Error>")) {
+		apiErr := &APIError{Provider: c.provider, StatusCode: resp.StatusCode}
+		xml.Unmarshal(result, apiErr)
+		return nil, apiErr
+	}
+	return c.Stat(ctx, key)
+}
+
+// AbortMultipartUpload implements Multipart
+func (c *restClient) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	resp, err := c.do(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// escapeKey percent-encodes each path segment of key as RFC 3986 requires
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = sigv4.URIEncode(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// encodeQuery encodes query sorted by key with RFC 3986 escaping; keys
+// without a value, such as "uploads", are written bare
+func encodeQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			if v == "" {
+				pairs = append(pairs, sigv4.URIEncode(k))
+			} else {
+				pairs = append(pairs, sigv4.URIEncode(k)+"="+sigv4.URIEncode(v))
+			}
+		}
+	}
+	return strings.Join(pairs, "&")
+}
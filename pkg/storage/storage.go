@@ -0,0 +1,204 @@
+// Package storage stores objects behind one interface, with backends for
+// S3 and S3-compatible services such as MinIO, Aliyun OSS and the local
+// filesystem.
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPartSize is the part size used by Upload
+	DefaultPartSize = 8 << 20
+	// MinPartSize is the smallest part S3 and OSS accept, except for the last
+	MinPartSize = 5 << 20
+)
+
+var (
+	// ErrNotFound is returned for missing objects
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrInvalidKey is returned for empty keys and keys escaping the root
+	ErrInvalidKey = errors.New("storage: invalid key")
+)
+
+// Storage stores objects by key
+type Storage interface {
+	// Put writes an object, replacing any existing one
+	Put(ctx context.Context, key string, r io.Reader, opts *PutOptions) (*Object, error)
+	// Get opens an object; the caller must close the reader
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	// Stat returns an object's attributes without its content
+	Stat(ctx context.Context, key string) (*Object, error)
+	// Delete removes an object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// List returns one page of objects in key order
+	List(ctx context.Context, opts ListOptions) (*ListResult, error)
+	// PresignedURL returns a URL allowing method (GET or PUT) on key until ttl passes
+	PresignedURL(method, key string, ttl time.Duration) (string, error)
+	Multipart
+}
+
+// Multipart uploads large objects in parts
+type Multipart interface {
+	CreateMultipartUpload(ctx context.Context, key string, opts *PutOptions) (uploadID string, err error)
+	// UploadPart uploads part number n (1-based) of size bytes
+	UploadPart(ctx context.Context, key, uploadID string, n int, r io.Reader, size int64) (Part, error)
+	// CompleteMultipartUpload joins the parts in order into the object
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []Part) (*Object, error)
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+}
+
+var (
+	_ Storage = (*Local)(nil)
+	_ Storage = (*S3)(nil)
+	_ Storage = (*OSS)(nil)
+)
+
+// Object describes a stored object
+type Object struct {
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	ContentType  string            `json:"content_type,omitempty"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified time.Time         `json:"last_modified"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+// PutOptions are optional object attributes
+type PutOptions struct {
+	ContentType        string
+	CacheControl       string
+	ContentDisposition string
+	// Metadata is stored as user metadata. The local backend ignores it and
+	// derives content types from the key's extension.
+	Metadata map[string]string
+	// Size is the content length when known, -1 or 0 otherwise
+	Size int64
+	// PartSize is the part size Upload uses, DefaultPartSize by default
+	PartSize int64
+}
+
+// ListOptions selects the objects returned by List
+type ListOptions struct {
+	Prefix string
+	// Cursor is the NextCursor of the previous page
+	Cursor string
+	// Limit caps the page size, 1000 by default
+	Limit int
+}
+
+// ListResult is one page of List
+type ListResult struct {
+	Objects []Object `json:"objects"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Part is an uploaded part of a multipart upload
+type Part struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+}
+
+// Config selects and configures a backend
+type Config struct {
+	Driver string      `json:"driver" yaml:"driver" env:"DRIVER"` // local, s3, oss
+	Local  LocalConfig `json:"local" yaml:"local"`
+	S3     S3Config    `json:"s3" yaml:"s3"`
+	OSS    OSSConfig   `json:"oss" yaml:"oss"`
+}
+
+// DefaultConfig returns default storage configuration
+func DefaultConfig() Config {
+	return Config{
+		Driver: "local",
+		Local:  DefaultLocalConfig(),
+		S3:     DefaultS3Config(),
+		OSS:    DefaultOSSConfig(),
+	}
+}
+
+// New creates the backend selected by cfg.Driver
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocal(cfg.Local)
+	case "s3", "minio":
+		return NewS3(cfg.S3)
+	case "oss":
+		return NewOSS(cfg.OSS)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s", cfg.Driver)
+	}
+}
+
+// Upload writes r to key, switching to a multipart upload when the content
+// is larger than one part; a failed multipart upload is aborted
+func Upload(ctx context.Context, s Storage, key string, r io.Reader, opts *PutOptions) (*Object, error) {
+	partSize := int64(DefaultPartSize)
+	if opts != nil && opts.PartSize > 0 {
+		partSize = opts.PartSize
+	}
+
+	buf := make([]byte, partSize)
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		putOpts := PutOptions{}
+		if opts != nil {
+			putOpts = *opts
+		}
+		putOpts.Size = int64(n)
+		return s.Put(ctx, key, bytes.NewReader(buf[:n]), &putOpts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	uploadID, err := s.CreateMultipartUpload(ctx, key, opts)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := uploadParts(ctx, s, key, uploadID, r, buf, n)
+	if err != nil {
+		// Use a fresh context so a cancelled upload is still cleaned up
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		if abortErr := s.AbortMultipartUpload(abortCtx, key, uploadID); abortErr != nil {
+			err = errors.Join(err, abortErr)
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+// uploadParts uploads buf[:n] and the rest of r as parts
+func uploadParts(ctx context.Context, s Storage, key, uploadID string, r io.Reader, buf []byte, n int) (*Object, error) {
+	var parts []Part
+	for number := 1; n > 0; number++ {
+		part, err := s.UploadPart(ctx, key, uploadID, number, bytes.NewReader(buf[:n]), int64(n))
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+
+		n, err = io.ReadFull(r, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("failed to read upload: %w", err)
+		}
+	}
+	return s.CompleteMultipartUpload(ctx, key, uploadID, parts)
+}
+
+// checkKey rejects keys that cannot be stored
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// testStorage runs the behaviour every backend shares
+func testStorage(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	t.Run("put get stat", func(t *testing.T) {
+		opts := &PutOptions{ContentType: "text/plain", Metadata: map[string]string{"owner": "alice"}}
+		obj, err := s.Put(ctx, "docs/a b.txt", strings.NewReader("hello"), opts)
+		if err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		if obj.Key != "docs/a b.txt" || obj.Size != 5 || obj.ETag == "" {
+			t.Errorf("Put() = %+v", obj)
+		}
+
+		rc, got, err := s.Get(ctx, "docs/a b.txt")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(data) != "hello" || got.ETag != obj.ETag || !strings.HasPrefix(got.ContentType, "text/plain") {
+			t.Errorf("Get() = %q, %+v", data, got)
+		}
+
+		stat, err := s.Stat(ctx, "docs/a b.txt")
+		if err != nil || stat.Size != 5 || stat.LastModified.IsZero() {
+			t.Errorf("Stat() = %+v, %v", stat, err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		if _, _, err := s.Get(ctx, "missing.txt"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get() error = %v, want ErrNotFound", err)
+		}
+		if _, err := s.Stat(ctx, "missing.txt"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat() error = %v, want ErrNotFound", err)
+		}
+		if err := s.Delete(ctx, "missing.txt"); err != nil {
+			t.Errorf("Delete() of a missing object error = %v", err)
+		}
+		if _, err := s.Put(ctx, "", strings.NewReader("x"), nil); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put() with an empty key error = %v, want ErrInvalidKey", err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		for _, key := range []string{"list/c", "list/a", "list/b/1", "other"} {
+			if _, err := s.Put(ctx, key, strings.NewReader(key), nil); err != nil {
+				t.Fatalf("Put(%s) error = %v", key, err)
+			}
+		}
+		var keys []string
+		opts := ListOptions{Prefix: "list/", Limit: 2}
+		for page := 0; page < 3; page++ {
+			result, err := s.List(ctx, opts)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			for _, o := range result.Objects {
+				keys = append(keys, o.Key)
+			}
+			if result.NextCursor == "" {
+				break
+			}
+			opts.Cursor = result.NextCursor
+		}
+		if strings.Join(keys, ",") != "list/a,list/b/1,list/c" {
+			t.Errorf("List() keys = %v", keys)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := s.Delete(ctx, "list/b/1"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := s.Stat(ctx, "list/b/1"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat() after Delete() error = %v", err)
+		}
+	})
+
+	t.Run("upload", func(t *testing.T) {
+		data := bytes.Repeat([]byte("0123456789"), 25)
+		obj, err := Upload(ctx, s, "big.bin", bytes.NewReader(data), &PutOptions{PartSize: 100})
+		if err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+		rc, _, err := s.Get(ctx, "big.bin")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		got, _ := io.ReadAll(rc)
+		rc.Close()
+		if !bytes.Equal(got, data) || obj.Size != int64(len(data)) {
+			t.Errorf("Upload() stored %d bytes, want %d", len(got), len(data))
+		}
+
+		small, err := Upload(ctx, s, "small.bin", strings.NewReader("tiny"), &PutOptions{PartSize: 100})
+		if err != nil || small.Size != 4 {
+			t.Errorf("Upload() of one part = %+v, %v", small, err)
+		}
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		id, err := s.CreateMultipartUpload(ctx, "aborted.bin", nil)
+		if err != nil {
+			t.Fatalf("CreateMultipartUpload() error = %v", err)
+		}
+		if _, err := s.UploadPart(ctx, "aborted.bin", id, 1, strings.NewReader("part"), 4); err != nil {
+			t.Fatalf("UploadPart() error = %v", err)
+		}
+		if err := s.AbortMultipartUpload(ctx, "aborted.bin", id); err != nil {
+			t.Fatalf("AbortMultipartUpload() error = %v", err)
+		}
+		if _, err := s.UploadPart(ctx, "aborted.bin", id, 2, strings.NewReader("part"), 4); !errors.Is(err, ErrNotFound) {
+			t.Errorf("UploadPart() after abort error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Local.Root = t.TempDir()
+	if s, err := New(cfg); err != nil {
+		t.Fatalf("New(local) error = %v", err)
+	} else if _, ok := s.(*Local); !ok {
+		t.Errorf("New(local) = %T", s)
+	}
+
+	cfg.Driver = "s3"
+	if _, err := New(cfg); err == nil {
+		t.Error("New(s3) without a bucket should fail")
+	}
+	cfg.Driver = "ftp"
+	if _, err := New(cfg); err == nil {
+		t.Error("New() with an unknown driver should fail")
+	}
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartHTTP continues the caller's trace from the request headers and
+// starts a server span named after the method; the returned request carries
+// the span and, for pkg/logger, its trace ID. Finish it with EndHTTP.
+func StartHTTP(r *http.Request) (*http.Request, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := Start(ctx, r.Method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.URLPath(r.URL.Path),
+			semconv.UserAgentOriginal(r.UserAgent()),
+		))
+	return r.WithContext(ctx), span
+}
+
+// EndHTTP names the span after the route pattern, e.g. "GET /orders/:id",
+// records the status, marking 5xx responses as errors, and ends it. A
+// pattern may lead with the method, as http.ServeMux patterns do; an empty
+// route keeps the method-only name so raw paths never become span names.
+func EndHTTP(span trace.Span, method, route string, status int) {
+	if route = strings.TrimPrefix(route, method+" "); route != "" {
+		span.SetName(method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	span.End()
+}
+
+// Middleware traces every request served by next, taking the route from
+// route(r) once next returns, e.g. r.Pattern for an http.ServeMux
+func Middleware(next http.Handler, route func(r *http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, span := StartHTTP(r)
+		rec := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		defer func() { EndHTTP(span, r.Method, route(r), rec.status) }()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// statusWriter records the status code of a response
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the first status code
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write implies a 200 status when no header was written
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
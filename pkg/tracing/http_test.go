@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+
+	"mora/pkg/logger"
+)
+
+func TestMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	p, err := newProvider(context.Background(), DefaultConfig(), exporter)
+	if err != nil {
+		t.Fatalf("newProvider() error = %v", err)
+	}
+	defer p.Shutdown(context.Background())
+	otel.SetTracerProvider(p.tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var logTraceID string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logTraceID = logger.GetTraceIDFromContext(r.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	h := Middleware(mux, func(r *http.Request) string { return r.Pattern })
+
+	const parent = "4bf92f3577b34da6a3ce929d0e0e4736"
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantName    string
+		wantStatus  int
+		wantError   bool
+	}{
+		{name: "continues caller trace", path: "/orders/1", traceparent: "00-" + parent + "-00f067aa0ba902b7-01",
+			wantName: "GET /orders/{id}", wantStatus: 500, wantError: true},
+		{name: "unmatched keeps method name", path: "/missing", wantName: "GET", wantStatus: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			p.tp.ForceFlush(context.Background())
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("exported %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name != tt.wantName {
+				t.Errorf("span name = %q, want %q", span.Name, tt.wantName)
+			}
+			if tt.traceparent != "" {
+				if got := span.SpanContext.TraceID().String(); got != parent || logTraceID != parent {
+					t.Errorf("trace ID = %q, logger trace ID = %q, want %q", got, logTraceID, parent)
+				}
+			}
+			if (span.Status.Code == codes.Error) != tt.wantError {
+				t.Errorf("status = %+v, want error %v", span.Status, tt.wantError)
+			}
+			var status int64
+			for _, kv := range span.Attributes {
+				if kv.Key == semconv.HTTPResponseStatusCodeKey {
+					status = kv.Value.AsInt64()
+				}
+			}
+			if status != int64(tt.wantStatus) {
+				t.Errorf("status code attribute = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+// Package tracing bootstraps OpenTelemetry: an OTLP or Jaeger exporter,
+// resource attributes, sampler and propagators set up with one call, plus
+// HTTP middleware and helpers to start spans and hand trace IDs to pkg/logger.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"mora/pkg/logger"
+)
+
+// instrumentationName names the tracer used by Start
+const instrumentationName = "mora"
+
+// Config configures tracing
+type Config struct {
+	ServiceName    string `json:"service_name" yaml:"service_name" env:"SERVICE_NAME"`
+	ServiceVersion string `json:"service_version" yaml:"service_version" env:"SERVICE_VERSION"`
+	Environment    string `json:"environment" yaml:"environment" env:"ENVIRONMENT"`
+	// Attributes are extra resource attributes, e.g. {"team": "payments"}
+	Attributes map[string]string `json:"attributes" yaml:"attributes"`
+
+	// Exporter is otlpgrpc, otlphttp, jaeger, stdout or none; Jaeger 1.35+
+	// also accepts OTLP, so prefer otlpgrpc for new deployments
+	Exporter string `json:"exporter" yaml:"exporter" env:"EXPORTER"`
+	// Endpoint is the collector address, e.g. otel-collector:4317, or for
+	// jaeger the collector URL, e.g. http://jaeger:14268/api/traces
+	Endpoint string            `json:"endpoint" yaml:"endpoint" env:"ENDPOINT"`
+	Insecure bool              `json:"insecure" yaml:"insecure" env:"INSECURE"`
+	Headers  map[string]string `json:"headers" yaml:"headers"`
+	Timeout  time.Duration     `json:"timeout" yaml:"timeout" env:"TIMEOUT"`
+
+	// Sampler is always, never or ratio
+	Sampler     string  `json:"sampler" yaml:"sampler" env:"SAMPLER"`
+	SampleRatio float64 `json:"sample_ratio" yaml:"sample_ratio" env:"SAMPLE_RATIO"`
+	// ParentBased follows the caller's sampling decision when there is one
+	ParentBased bool `json:"parent_based" yaml:"parent_based" env:"PARENT_BASED"`
+
+	// Propagators lists tracecontext and/or baggage
+	Propagators []string `json:"propagators" yaml:"propagators"`
+}
+
+// DefaultConfig returns default tracing configuration
+func DefaultConfig() Config {
+	return Config{
+		ServiceName: "mora-service",
+		Environment: "development",
+		Exporter:    "otlpgrpc",
+		Endpoint:    "localhost:4317",
+		Insecure:    true,
+		Timeout:     10 * time.Second,
+		Sampler:     "always",
+		SampleRatio: 1,
+		ParentBased: true,
+		Propagators: []string{"tracecontext", "baggage"},
+	}
+}
+
+// Provider owns the tracer provider created by Init
+type Provider struct {
+	tp *sdktrace.TracerProvider
+}
+
+// Init builds the tracer provider from cfg and installs it, along with the
+// propagators, as the OpenTelemetry globals. Call Shutdown before exiting
+// to flush buffered spans.
+func Init(ctx context.Context, cfg Config) (*Provider, error) {
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	p, err := newProvider(ctx, cfg, exporter)
+	if err != nil {
+		return nil, err
+	}
+
+	propagator, err := newPropagator(cfg.Propagators)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(p.tp)
+	otel.SetTextMapPropagator(propagator)
+	return p, nil
+}
+
+// newProvider creates a provider exporting to exporter, nil exports nothing
+func newProvider(ctx context.Context, cfg Config, exporter sdktrace.SpanExporter) (*Provider, error) {
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	sampler, err := newSampler(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res), sdktrace.WithSampler(sampler)}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	return &Provider{tp: sdktrace.NewTracerProvider(opts...)}, nil
+}
+
+// Tracer returns a named tracer from the provider
+func (p *Provider) Tracer(name string) trace.Tracer {
+	return p.tp.Tracer(name)
+}
+
+// Shutdown flushes pending spans and stops the exporter
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if err := p.tp.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shut down tracer provider: %w", err)
+	}
+	return nil
+}
+
+// newExporter creates the span exporter selected by cfg.Exporter
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(cfg.Exporter) {
+	case "", "otlpgrpc", "otlp":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint), otlptracegrpc.WithHeaders(cfg.Headers)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if cfg.Timeout > 0 {
+			opts = append(opts, otlptracegrpc.WithTimeout(cfg.Timeout))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case "otlphttp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint), otlptracehttp.WithHeaders(cfg.Headers)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if cfg.Timeout > 0 {
+			opts = append(opts, otlptracehttp.WithTimeout(cfg.Timeout))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case "jaeger":
+		opts := []jaeger.CollectorEndpointOption{jaeger.WithEndpoint(cfg.Endpoint)}
+		if cfg.Timeout > 0 {
+			opts = append(opts, jaeger.WithHTTPClient(&http.Client{Timeout: cfg.Timeout}))
+		}
+		return jaeger.New(jaeger.WithCollectorEndpoint(opts...))
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter %q", cfg.Exporter)
+	}
+}
+
+// newResource describes the service emitting spans
+func newResource(ctx context.Context, cfg Config) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{semconv.ServiceName(cfg.ServiceName)}
+	if cfg.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(cfg.ServiceVersion))
+	}
+	if cfg.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironment(cfg.Environment))
+	}
+	for k, v := range cfg.Attributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attrs...),
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithHost(),
+		resource.WithProcessPID(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+	return res, nil
+}
+
+// newSampler creates the sampler selected by cfg.Sampler
+func newSampler(cfg Config) (sdktrace.Sampler, error) {
+	var sampler sdktrace.Sampler
+	switch strings.ToLower(cfg.Sampler) {
+	case "", "always":
+		sampler = sdktrace.AlwaysSample()
+	case "never":
+		sampler = sdktrace.NeverSample()
+	case "ratio":
+		if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+			return nil, fmt.Errorf("sample ratio must be between 0 and 1, got %v", cfg.SampleRatio)
+		}
+		sampler = sdktrace.TraceIDRatioBased(cfg.SampleRatio)
+	default:
+		return nil, fmt.Errorf("unsupported trace sampler %q", cfg.Sampler)
+	}
+	if cfg.ParentBased {
+		sampler = sdktrace.ParentBased(sampler)
+	}
+	return sampler, nil
+}
+
+// newPropagator combines the named propagators
+func newPropagator(names []string) (propagation.TextMapPropagator, error) {
+	if len(names) == 0 {
+		names = DefaultConfig().Propagators
+	}
+	var props []propagation.TextMapPropagator
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case "tracecontext":
+			props = append(props, propagation.TraceContext{})
+		case "baggage":
+			props = append(props, propagation.Baggage{})
+		default:
+			return nil, fmt.Errorf("unsupported propagator %q", name)
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(props...), nil
+}
+
+// Start starts a span with the global tracer. The returned context also
+// carries the trace ID for pkg/logger, so logger.WithContext(ctx) tags log
+// lines with the span's trace.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+	return WithLogTraceID(ctx), span
+}
+
+// WithLogTraceID copies the active span's trace ID into ctx for pkg/logger;
+// use it for spans started by other instrumentation
+func WithLogTraceID(ctx context.Context) context.Context {
+	if traceID := TraceID(ctx); traceID != "" {
+		return logger.WithTraceID(ctx, traceID)
+	}
+	return ctx
+}
+
+// TraceID returns the trace ID of the active span, or "" without one
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// SpanID returns the ID of the active span, or "" without one
+func SpanID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasSpanID() {
+		return ""
+	}
+	return sc.SpanID().String()
+}
+
+// End records err on the span, if any, and ends it; use it as
+// defer func() { tracing.End(span, err) }()
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,149 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+
+	"mora/pkg/logger"
+)
+
+func TestInitAndStart(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Exporter = "none"
+	p, err := Init(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer p.Shutdown(context.Background())
+
+	ctx, span := Start(context.Background(), "op")
+	defer span.End()
+
+	traceID := TraceID(ctx)
+	if len(traceID) != 32 {
+		t.Fatalf("TraceID() = %q, want 32 hex chars", traceID)
+	}
+	if len(SpanID(ctx)) != 16 {
+		t.Errorf("SpanID() = %q, want 16 hex chars", SpanID(ctx))
+	}
+	if got := logger.GetTraceIDFromContext(ctx); got != traceID {
+		t.Errorf("logger trace ID = %q, want %q", got, traceID)
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if carrier.Get("traceparent") == "" {
+		t.Error("traceparent was not injected")
+	}
+}
+
+func TestIDsWithoutSpan(t *testing.T) {
+	ctx := context.Background()
+	if TraceID(ctx) != "" || SpanID(ctx) != "" {
+		t.Error("IDs should be empty without a span")
+	}
+	if WithLogTraceID(ctx) != ctx {
+		t.Error("WithLogTraceID() should return ctx unchanged without a span")
+	}
+}
+
+func TestProviderExportsResourceAndErrors(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	cfg := DefaultConfig()
+	cfg.ServiceName = "orders"
+	cfg.ServiceVersion = "1.2.3"
+	cfg.Environment = "test"
+	cfg.Attributes = map[string]string{"team": "payments"}
+	p, err := newProvider(context.Background(), cfg, exporter)
+	if err != nil {
+		t.Fatalf("newProvider() error = %v", err)
+	}
+
+	_, span := p.Tracer("test").Start(context.Background(), "op")
+	End(span, errors.New("boom"))
+	if err := p.tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+	defer p.Shutdown(context.Background())
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error || spans[0].Status.Description != "boom" {
+		t.Errorf("status = %+v, want error boom", spans[0].Status)
+	}
+
+	attrs := map[string]string{}
+	for _, kv := range spans[0].Resource.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	want := map[string]string{
+		string(semconv.ServiceNameKey):           "orders",
+		string(semconv.ServiceVersionKey):        "1.2.3",
+		string(semconv.DeploymentEnvironmentKey): "test",
+		"team":                                   "payments",
+	}
+	for k, v := range want {
+		if attrs[k] != v {
+			t.Errorf("resource %s = %q, want %q", k, attrs[k], v)
+		}
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"unknown exporter", func(c *Config) { c.Exporter = "zipkin" }},
+		{"unknown sampler", func(c *Config) { c.Exporter = "none"; c.Sampler = "sometimes" }},
+		{"ratio out of range", func(c *Config) { c.Exporter = "none"; c.Sampler = "ratio"; c.SampleRatio = 2 }},
+		{"unknown propagator", func(c *Config) { c.Exporter = "none"; c.Propagators = []string{"b3"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(&cfg)
+			if _, err := Init(context.Background(), cfg); err == nil {
+				t.Error("Init() should fail")
+			}
+		})
+	}
+}
+
+func TestSampler(t *testing.T) {
+	tests := []struct {
+		sampler string
+		ratio   float64
+		sampled bool
+	}{
+		{"always", 0, true},
+		{"never", 0, false},
+		{"ratio", 0, false},
+		{"ratio", 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sampler, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Sampler = tt.sampler
+			cfg.SampleRatio = tt.ratio
+			p, err := newProvider(context.Background(), cfg, nil)
+			if err != nil {
+				t.Fatalf("newProvider() error = %v", err)
+			}
+			_, span := p.Tracer("test").Start(context.Background(), "op")
+			defer span.End()
+			if got := span.SpanContext().IsSampled(); got != tt.sampled {
+				t.Errorf("IsSampled() = %v, want %v", got, tt.sampled)
+			}
+		})
+	}
+}
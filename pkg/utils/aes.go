@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// AESKeySize is the key size used by the AES-GCM helpers (AES-256)
+	AESKeySize = 32
+	// ciphertextVersion is the first byte of every ciphertext
+	ciphertextVersion byte = 1
+	// DefaultKeyID is the key ID used by Encrypt and Decrypt
+	DefaultKeyID = "default"
+)
+
+var (
+	// ErrInvalidCiphertext is returned for malformed or tampered ciphertexts
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+	// ErrUnknownKey is returned when a ciphertext was sealed with a key the
+	// keyring does not hold
+	ErrUnknownKey = errors.New("unknown encryption key")
+)
+
+// DeriveKey derives an AES-256 key from a passphrase with scrypt. The salt
+// should be random, at least 16 bytes, and stored alongside the data.
+func DeriveKey(passphrase string, salt []byte) ([]byte, error) {
+	if len(salt) < 8 {
+		return nil, errors.New("salt must be at least 8 bytes")
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, AESKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// DeriveKeyHKDF derives an AES-256 key from high-entropy secret material
+// (e.g. a master key) using HKDF-SHA256; info separates keys per purpose,
+// such as "pii" or "config"
+func DeriveKeyHKDF(secret, salt []byte, info string) ([]byte, error) {
+	key, err := hkdf.Key(sha256.New, secret, salt, info, AESKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// Keyring holds the AES-GCM keys used to encrypt and decrypt application
+// data. New data is sealed with the primary key; older keys stay available
+// for decryption so keys can be rotated without re-encrypting everything.
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring from key ID to 32-byte key, encrypting with
+// the primary key
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key %q not found", primary)
+	}
+
+	k := &Keyring{primary: primary, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || len(id) > 255 {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		k.aeads[id] = aead
+	}
+	return k, nil
+}
+
+// newGCM creates an AES-256-GCM cipher
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != AESKeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", AESKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Primary returns the ID of the key used for encryption
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// Encrypt seals plaintext with the primary key. The result is
+// version | key ID length | key ID | nonce | ciphertext; additionalData, if
+// any, must be passed again to Decrypt (e.g. a user ID binding the value to
+// its row).
+func (k *Keyring) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	aead := k.aeads[k.primary]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+
+	out := make([]byte, 0, 2+len(k.primary)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(out, ciphertextVersion, byte(len(k.primary)))
+	out = append(out, k.primary...)
+	out = append(out, nonce...)
+	// The header is authenticated too, so the key ID cannot be swapped
+	return aead.Seal(out, nonce, plaintext, append(out[:len(out):len(out)], additionalData...)), nil
+}
+
+// Decrypt opens a ciphertext produced by Encrypt with any key in the ring
+func (k *Keyring) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	keyID, err := CiphertextKeyID(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	aead, ok := k.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	headerLen := 2 + len(keyID)
+	if len(ciphertext) < headerLen+aead.NonceSize()+aead.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+	header := ciphertext[:headerLen+aead.NonceSize()]
+	nonce := header[headerLen:]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext[len(header):], append(header[:len(header):len(header)], additionalData...))
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+// NeedsRotation reports whether a ciphertext was sealed with a key other
+// than the primary one and should be re-encrypted
+func (k *Keyring) NeedsRotation(ciphertext []byte) bool {
+	keyID, err := CiphertextKeyID(ciphertext)
+	return err == nil && keyID != k.primary
+}
+
+// Rotate re-encrypts a ciphertext with the primary key
+func (k *Keyring) Rotate(ciphertext, additionalData []byte) ([]byte, error) {
+	plaintext, err := k.Decrypt(ciphertext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return k.Encrypt(plaintext, additionalData)
+}
+
+// EncryptString encrypts a string and encodes it as unpadded base64url, ready
+// to store in a text column or config file
+func (k *Keyring) EncryptString(plaintext string) (string, error) {
+	ciphertext, err := k.Encrypt([]byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptString decrypts a value produced by EncryptString
+func (k *Keyring) DecryptString(encoded string) (string, error) {
+	ciphertext, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	plaintext, err := k.Decrypt(ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// CiphertextKeyID returns the ID of the key a ciphertext was sealed with
+func CiphertextKeyID(ciphertext []byte) (string, error) {
+	if len(ciphertext) < 2 || ciphertext[0] != ciphertextVersion {
+		return "", ErrInvalidCiphertext
+	}
+	n := int(ciphertext[1])
+	if n == 0 || len(ciphertext) < 2+n {
+		return "", ErrInvalidCiphertext
+	}
+	return string(ciphertext[2 : 2+n]), nil
+}
+
+// Encrypt seals plaintext with a single 32-byte key
+func Encrypt(key, plaintext []byte) ([]byte, error) {
+	k, err := NewKeyring(DefaultKeyID, map[string][]byte{DefaultKeyID: key})
+	if err != nil {
+		return nil, err
+	}
+	return k.Encrypt(plaintext, nil)
+}
+
+// Decrypt opens a ciphertext produced by Encrypt
+func Decrypt(key, ciphertext []byte) ([]byte, error) {
+	k, err := NewKeyring(DefaultKeyID, map[string][]byte{DefaultKeyID: key})
+	if err != nil {
+		return nil, err
+	}
+	return k.Decrypt(ciphertext, nil)
+}
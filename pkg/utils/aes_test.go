@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, AESKeySize)
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	tests := []struct {
+		name      string
+		plaintext []byte
+	}{
+		{"text", []byte("13800138000")},
+		{"empty", []byte{}},
+		{"binary", []byte{0, 1, 2, 255}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, err := Encrypt(testKey(1), tt.plaintext)
+			if err != nil {
+				t.Fatalf("Encrypt() error = %v", err)
+			}
+
+			got, err := Decrypt(testKey(1), ciphertext)
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.plaintext) {
+				t.Errorf("Decrypt() = %v, want %v", got, tt.plaintext)
+			}
+
+			if _, err := Decrypt(testKey(2), ciphertext); err == nil {
+				t.Error("Decrypt() with the wrong key should fail")
+			}
+		})
+	}
+}
+
+func TestEncryptInvalidKey(t *testing.T) {
+	if _, err := Encrypt([]byte("short"), []byte("x")); err == nil {
+		t.Error("Encrypt() should reject a short key")
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old, err := NewKeyring("2024", map[string][]byte{"2024": testKey(1)})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	ciphertext, _ := old.Encrypt([]byte("secret"), []byte("user:1"))
+
+	ring, err := NewKeyring("2025", map[string][]byte{"2024": testKey(1), "2025": testKey(2)})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	if !ring.NeedsRotation(ciphertext) {
+		t.Error("NeedsRotation() = false for a ciphertext sealed with an old key")
+	}
+
+	rotated, err := ring.Rotate(ciphertext, []byte("user:1"))
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if id, _ := CiphertextKeyID(rotated); id != "2025" {
+		t.Errorf("CiphertextKeyID() = %v, want 2025", id)
+	}
+
+	got, err := ring.Decrypt(rotated, []byte("user:1"))
+	if err != nil || string(got) != "secret" {
+		t.Errorf("Decrypt() = %q, %v", got, err)
+	}
+
+	if _, err := ring.Decrypt(rotated, []byte("user:2")); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("Decrypt() with other additional data error = %v, want %v", err, ErrInvalidCiphertext)
+	}
+	if _, err := old.Decrypt(rotated, []byte("user:1")); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt() with a missing key error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestKeyringTampering(t *testing.T) {
+	ring, _ := NewKeyring("a", map[string][]byte{"a": testKey(1), "b": testKey(1)})
+	ciphertext, _ := ring.Encrypt([]byte("secret"), nil)
+
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := ring.Decrypt(tampered, nil); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("Decrypt() of tampered data error = %v, want %v", err, ErrInvalidCiphertext)
+	}
+
+	// Swapping the key ID in the header is detected even if the key matches
+	swapped := append([]byte(nil), ciphertext...)
+	swapped[2] = 'b'
+	if _, err := ring.Decrypt(swapped, nil); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("Decrypt() with swapped key id error = %v, want %v", err, ErrInvalidCiphertext)
+	}
+
+	for _, bad := range [][]byte{nil, {9, 1, 'a'}, {1, 0}, {1, 5, 'a'}} {
+		if _, err := ring.Decrypt(bad, nil); err == nil {
+			t.Errorf("Decrypt(%v) should fail", bad)
+		}
+	}
+}
+
+func TestKeyringStrings(t *testing.T) {
+	ring, _ := NewKeyring("k1", map[string][]byte{"k1": testKey(3)})
+
+	encoded, err := ring.EncryptString("alice@example.com")
+	if err != nil {
+		t.Fatalf("EncryptString() error = %v", err)
+	}
+	got, err := ring.DecryptString(encoded)
+	if err != nil || got != "alice@example.com" {
+		t.Errorf("DecryptString() = %q, %v", got, err)
+	}
+	if _, err := ring.DecryptString("not base64!"); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("DecryptString() error = %v, want %v", err, ErrInvalidCiphertext)
+	}
+}
+
+func TestNewKeyringErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		primary string
+		keys    map[string][]byte
+	}{
+		{"missing primary", "x", map[string][]byte{"a": testKey(1)}},
+		{"short key", "a", map[string][]byte{"a": []byte("short")}},
+		{"empty id", "a", map[string][]byte{"a": testKey(1), "": testKey(2)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.primary, tt.keys); err == nil {
+				t.Error("NewKeyring() should return an error")
+			}
+		})
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+
+	k1, err := DeriveKey("correct horse", salt)
+	if err != nil {
+		t.Fatalf("DeriveKey() error = %v", err)
+	}
+	k2, _ := DeriveKey("correct horse", salt)
+	k3, _ := DeriveKey("battery staple", salt)
+	if len(k1) != AESKeySize || !bytes.Equal(k1, k2) || bytes.Equal(k1, k3) {
+		t.Error("DeriveKey() should be deterministic per passphrase and salt")
+	}
+	if _, err := DeriveKey("x", []byte("salt")); err == nil {
+		t.Error("DeriveKey() should reject a short salt")
+	}
+
+	h1, err := DeriveKeyHKDF([]byte("master"), salt, "pii")
+	if err != nil {
+		t.Fatalf("DeriveKeyHKDF() error = %v", err)
+	}
+	h2, _ := DeriveKeyHKDF([]byte("master"), salt, "config")
+	if len(h1) != AESKeySize || bytes.Equal(h1, h2) {
+		t.Error("DeriveKeyHKDF() should derive distinct keys per info")
+	}
+}
@@ -0,0 +1,345 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+const (
+	// StateClosed lets calls through and records their outcome
+	StateClosed BreakerState = iota
+	// StateOpen rejects calls until OpenTimeout has passed
+	StateOpen
+	// StateHalfOpen lets a limited number of trial calls through
+	StateHalfOpen
+)
+
+// String returns the state name
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	// ErrCircuitOpen is returned while the breaker rejects calls
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	// ErrTooManyTrialCalls is returned when the half-open trial slots are taken
+	ErrTooManyTrialCalls = errors.New("circuit breaker: too many half-open calls")
+)
+
+// CircuitBreakerConfig configures a circuit breaker
+type CircuitBreakerConfig struct {
+	// Name identifies the breaker in callbacks, e.g. the downstream service
+	Name string `json:"name" yaml:"name"`
+	// WindowSize is the number of most recent calls the rates are computed over
+	WindowSize int `json:"window_size" yaml:"window_size"`
+	// MinCalls is the number of calls required before the breaker may trip
+	MinCalls int `json:"min_calls" yaml:"min_calls"`
+	// FailureRateThreshold trips the breaker when the share of failed calls
+	// reaches it, 0-1
+	FailureRateThreshold float64 `json:"failure_rate_threshold" yaml:"failure_rate_threshold"`
+	// SlowCallDuration marks calls taking at least this long as slow; zero
+	// disables slow-call tracking
+	SlowCallDuration time.Duration `json:"slow_call_duration" yaml:"slow_call_duration"`
+	// SlowCallRateThreshold trips the breaker when the share of slow calls
+	// reaches it, 0-1
+	SlowCallRateThreshold float64 `json:"slow_call_rate_threshold" yaml:"slow_call_rate_threshold"`
+	// OpenTimeout is how long the breaker stays open before trial calls
+	OpenTimeout time.Duration `json:"open_timeout" yaml:"open_timeout"`
+	// HalfOpenCalls is the number of trial calls; all must succeed to close
+	HalfOpenCalls int `json:"half_open_calls" yaml:"half_open_calls"`
+
+	// IsFailure decides whether an error counts as a failure, defaults to
+	// err != nil. Use it to ignore e.g. validation or not-found errors.
+	IsFailure func(err error) bool `json:"-" yaml:"-"`
+	// OnStateChange is called after every state transition
+	OnStateChange func(name string, from, to BreakerState) `json:"-" yaml:"-"`
+	// OnCall is called after every recorded call, e.g. to feed metrics
+	OnCall func(name string, result CallResult) `json:"-" yaml:"-"`
+	// OnReject is called whenever a call is rejected without running
+	OnReject func(name string, state BreakerState) `json:"-" yaml:"-"`
+}
+
+// DefaultCircuitBreakerConfig returns the default breaker configuration
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		WindowSize:            100,
+		MinCalls:              20,
+		FailureRateThreshold:  0.5,
+		SlowCallRateThreshold: 1,
+		OpenTimeout:           30 * time.Second,
+		HalfOpenCalls:         5,
+	}
+}
+
+// CallResult describes a call recorded by the breaker
+type CallResult struct {
+	Failure  bool
+	Slow     bool
+	Duration time.Duration
+	State    BreakerState
+}
+
+// BreakerCounts are the outcome counts of the current window
+type BreakerCounts struct {
+	Calls    int
+	Failures int
+	Slow     int
+}
+
+// CircuitBreaker stops calling a failing dependency. It tracks the outcome
+// of the last WindowSize calls and opens when the failure or slow-call rate
+// crosses its threshold; after OpenTimeout a few trial calls decide whether
+// it closes again.
+type CircuitBreaker struct {
+	cfg CircuitBreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	openedAt time.Time
+	window   []outcome
+	next     int
+	counts   BreakerCounts
+	trials   int
+	trialOK  int
+}
+
+// outcome is one slot of the rolling window
+type outcome struct {
+	failure bool
+	slow    bool
+}
+
+// NewCircuitBreaker creates a circuit breaker, filling unset options with
+// defaults
+func NewCircuitBreaker(cfg CircuitBreakerConfig) *CircuitBreaker {
+	def := DefaultCircuitBreakerConfig()
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = def.WindowSize
+	}
+	if cfg.MinCalls <= 0 {
+		cfg.MinCalls = def.MinCalls
+	}
+	if cfg.MinCalls > cfg.WindowSize {
+		cfg.MinCalls = cfg.WindowSize
+	}
+	if cfg.FailureRateThreshold <= 0 {
+		cfg.FailureRateThreshold = def.FailureRateThreshold
+	}
+	if cfg.SlowCallRateThreshold <= 0 {
+		cfg.SlowCallRateThreshold = def.SlowCallRateThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = def.OpenTimeout
+	}
+	if cfg.HalfOpenCalls <= 0 {
+		cfg.HalfOpenCalls = def.HalfOpenCalls
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(err error) bool { return err != nil }
+	}
+
+	return &CircuitBreaker{cfg: cfg, now: time.Now, window: make([]outcome, 0, cfg.WindowSize)}
+}
+
+// Name returns the breaker name
+func (cb *CircuitBreaker) Name() string {
+	return cb.cfg.Name
+}
+
+// State returns the current state
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	halfOpened := cb.refresh()
+	state := cb.state
+	cb.mu.Unlock()
+
+	if halfOpened {
+		cb.notify(StateOpen, StateHalfOpen)
+	}
+	return state
+}
+
+// Counts returns the outcome counts of the current window
+func (cb *CircuitBreaker) Counts() BreakerCounts {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.counts
+}
+
+// Execute runs fn if the breaker allows it and records the outcome
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	done, err := cb.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	done(err)
+	return err
+}
+
+// Allow reserves a call. On success the caller must invoke done with the
+// call's error once it finishes; this two-step form suits middleware and
+// round trippers where the call is not a single function.
+func (cb *CircuitBreaker) Allow() (done func(err error), err error) {
+	cb.mu.Lock()
+	halfOpened := cb.refresh()
+	state := cb.state
+	switch state {
+	case StateOpen:
+		err = ErrCircuitOpen
+	case StateHalfOpen:
+		if cb.trials >= cb.cfg.HalfOpenCalls {
+			err = ErrTooManyTrialCalls
+		} else {
+			cb.trials++
+		}
+	}
+	cb.mu.Unlock()
+
+	if halfOpened {
+		cb.notify(StateOpen, StateHalfOpen)
+	}
+	if err != nil {
+		if cb.cfg.OnReject != nil {
+			cb.cfg.OnReject(cb.cfg.Name, state)
+		}
+		return nil, err
+	}
+
+	start := cb.now()
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { cb.record(state, err, cb.now().Sub(start)) })
+	}, nil
+}
+
+// Reset closes the breaker and clears its window
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	from := cb.state
+	cb.setState(StateClosed)
+	cb.mu.Unlock()
+	cb.notify(from, StateClosed)
+}
+
+// record stores the outcome of a call started in the given state
+func (cb *CircuitBreaker) record(startState BreakerState, err error, d time.Duration) {
+	result := CallResult{
+		Failure:  cb.cfg.IsFailure(err),
+		Slow:     cb.cfg.SlowCallDuration > 0 && d >= cb.cfg.SlowCallDuration,
+		Duration: d,
+		State:    startState,
+	}
+
+	cb.mu.Lock()
+	from := cb.state
+	to := from
+	switch {
+	case from == StateHalfOpen && startState == StateHalfOpen:
+		if result.Failure || result.Slow {
+			to = StateOpen
+		} else if cb.trialOK++; cb.trialOK >= cb.cfg.HalfOpenCalls {
+			to = StateClosed
+		}
+	case from == StateClosed:
+		cb.push(outcome{failure: result.Failure, slow: result.Slow})
+		if cb.tripped() {
+			to = StateOpen
+		}
+	}
+	if to != from {
+		cb.setState(to)
+	}
+	cb.mu.Unlock()
+
+	if cb.cfg.OnCall != nil {
+		cb.cfg.OnCall(cb.cfg.Name, result)
+	}
+	if to != from {
+		cb.notify(from, to)
+	}
+}
+
+// push adds an outcome to the rolling window, evicting the oldest
+func (cb *CircuitBreaker) push(o outcome) {
+	if len(cb.window) < cb.cfg.WindowSize {
+		cb.window = append(cb.window, o)
+	} else {
+		old := cb.window[cb.next]
+		cb.counts.Calls--
+		if old.failure {
+			cb.counts.Failures--
+		}
+		if old.slow {
+			cb.counts.Slow--
+		}
+		cb.window[cb.next] = o
+		cb.next = (cb.next + 1) % cb.cfg.WindowSize
+	}
+
+	cb.counts.Calls++
+	if o.failure {
+		cb.counts.Failures++
+	}
+	if o.slow {
+		cb.counts.Slow++
+	}
+}
+
+// tripped reports whether the window crosses a threshold
+func (cb *CircuitBreaker) tripped() bool {
+	if cb.counts.Calls < cb.cfg.MinCalls {
+		return false
+	}
+	calls := float64(cb.counts.Calls)
+	if float64(cb.counts.Failures)/calls >= cb.cfg.FailureRateThreshold {
+		return true
+	}
+	return cb.cfg.SlowCallDuration > 0 && float64(cb.counts.Slow)/calls >= cb.cfg.SlowCallRateThreshold
+}
+
+// refresh moves an open breaker to half-open once OpenTimeout has passed and
+// reports whether it did; callers hold mu and notify after releasing it
+func (cb *CircuitBreaker) refresh() bool {
+	if cb.state == StateOpen && cb.now().Sub(cb.openedAt) >= cb.cfg.OpenTimeout {
+		cb.setState(StateHalfOpen)
+		return true
+	}
+	return false
+}
+
+// setState switches state and resets the per-state bookkeeping
+func (cb *CircuitBreaker) setState(to BreakerState) {
+	cb.state = to
+	cb.trials = 0
+	cb.trialOK = 0
+	switch to {
+	case StateOpen:
+		cb.openedAt = cb.now()
+	case StateClosed:
+		cb.window = cb.window[:0]
+		cb.next = 0
+		cb.counts = BreakerCounts{}
+	}
+}
+
+// notify reports a state transition outside the lock
+func (cb *CircuitBreaker) notify(from, to BreakerState) {
+	if cb.cfg.OnStateChange != nil && from != to {
+		cb.cfg.OnStateChange(cb.cfg.Name, from, to)
+	}
+}
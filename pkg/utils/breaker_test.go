@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for breaker tests
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestBreaker(cfg CircuitBreakerConfig) (*CircuitBreaker, *fakeClock) {
+	clock := &fakeClock{now: time.Now()}
+	cb := NewCircuitBreaker(cfg)
+	cb.now = clock.Now
+	return cb, clock
+}
+
+var errDownstream = errors.New("downstream failed")
+
+func TestCircuitBreakerLifecycle(t *testing.T) {
+	var transitions []string
+	cb, clock := newTestBreaker(CircuitBreakerConfig{
+		Name:                 "payments",
+		WindowSize:           10,
+		MinCalls:             4,
+		FailureRateThreshold: 0.5,
+		OpenTimeout:          time.Second,
+		HalfOpenCalls:        2,
+		OnStateChange: func(name string, from, to BreakerState) {
+			transitions = append(transitions, name+":"+from.String()+"->"+to.String())
+		},
+	})
+
+	// Below MinCalls the breaker never trips
+	for i := 0; i < 3; i++ {
+		_ = cb.Execute(func() error { return errDownstream })
+	}
+	if cb.State() != StateClosed {
+		t.Fatalf("State() = %v, want closed below MinCalls", cb.State())
+	}
+
+	_ = cb.Execute(func() error { return errDownstream })
+	if cb.State() != StateOpen {
+		t.Fatalf("State() = %v, want open", cb.State())
+	}
+
+	called := false
+	if err := cb.Execute(func() error { called = true; return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Execute() error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if called {
+		t.Error("Execute() should not run fn while open")
+	}
+
+	clock.Advance(time.Second)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("State() = %v, want half-open", cb.State())
+	}
+
+	// Only HalfOpenCalls trial calls are let through
+	done1, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	done2, _ := cb.Allow()
+	if _, err := cb.Allow(); !errors.Is(err, ErrTooManyTrialCalls) {
+		t.Errorf("Allow() error = %v, want %v", err, ErrTooManyTrialCalls)
+	}
+	done1(nil)
+	done2(nil)
+
+	if cb.State() != StateClosed {
+		t.Fatalf("State() = %v, want closed after successful trials", cb.State())
+	}
+
+	want := []string{"payments:closed->open", "payments:open->half-open", "payments:half-open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("transitions[%d] = %v, want %v", i, transitions[i], want[i])
+		}
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
+	cb, clock := newTestBreaker(CircuitBreakerConfig{WindowSize: 2, MinCalls: 2, OpenTimeout: time.Second})
+
+	_ = cb.Execute(func() error { return errDownstream })
+	_ = cb.Execute(func() error { return errDownstream })
+	clock.Advance(time.Second)
+
+	_ = cb.Execute(func() error { return errDownstream })
+	if cb.State() != StateOpen {
+		t.Errorf("State() = %v, want open after a failed trial", cb.State())
+	}
+}
+
+func TestCircuitBreakerSlowCalls(t *testing.T) {
+	cb, clock := newTestBreaker(CircuitBreakerConfig{
+		WindowSize:            4,
+		MinCalls:              4,
+		FailureRateThreshold:  1,
+		SlowCallDuration:      100 * time.Millisecond,
+		SlowCallRateThreshold: 0.5,
+	})
+
+	var results []CallResult
+	cb.cfg.OnCall = func(_ string, r CallResult) { results = append(results, r) }
+
+	for i := 0; i < 4; i++ {
+		slow := i%2 == 0
+		_ = cb.Execute(func() error {
+			if slow {
+				clock.Advance(200 * time.Millisecond)
+			}
+			return nil
+		})
+	}
+
+	if cb.State() != StateOpen {
+		t.Errorf("State() = %v, want open on slow-call rate", cb.State())
+	}
+	if len(results) != 4 || !results[0].Slow || results[1].Slow {
+		t.Errorf("unexpected call results: %+v", results)
+	}
+}
+
+func TestCircuitBreakerRollingWindow(t *testing.T) {
+	cb, _ := newTestBreaker(CircuitBreakerConfig{WindowSize: 4, MinCalls: 4, FailureRateThreshold: 0.75})
+
+	outcomes := []error{errDownstream, errDownstream, nil, nil, errDownstream, nil}
+	for _, outcome := range outcomes {
+		_ = cb.Execute(func() error { return outcome })
+	}
+
+	counts := cb.Counts()
+	if counts.Calls != 4 || counts.Failures != 1 {
+		t.Errorf("Counts() = %+v, want 4 calls with 1 failure", counts)
+	}
+	if cb.State() != StateClosed {
+		t.Errorf("State() = %v, want closed", cb.State())
+	}
+}
+
+func TestCircuitBreakerIsFailure(t *testing.T) {
+	errNotFound := errors.New("not found")
+	rejected := 0
+	cb, _ := newTestBreaker(CircuitBreakerConfig{
+		WindowSize:           2,
+		MinCalls:             2,
+		FailureRateThreshold: 1,
+		IsFailure:            func(err error) bool { return err != nil && !errors.Is(err, errNotFound) },
+		OnReject:             func(string, BreakerState) { rejected++ },
+	})
+
+	_ = cb.Execute(func() error { return errNotFound })
+	_ = cb.Execute(func() error { return errNotFound })
+	if cb.State() != StateClosed {
+		t.Fatalf("State() = %v, ignored errors should not trip the breaker", cb.State())
+	}
+
+	_ = cb.Execute(func() error { return errDownstream })
+	_ = cb.Execute(func() error { return errDownstream })
+	_ = cb.Execute(func() error { return nil })
+	if rejected != 1 {
+		t.Errorf("rejected = %d, want 1", rejected)
+	}
+
+	cb.Reset()
+	if cb.State() != StateClosed || cb.Counts().Calls != 0 {
+		t.Errorf("Reset() should close the breaker and clear counts")
+	}
+}
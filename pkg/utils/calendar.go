@@ -0,0 +1,171 @@
+package utils
+
+import "time"
+
+// BeginningOfDay returns midnight of t's date in loc; a nil loc uses t's
+// own location
+func BeginningOfDay(t time.Time, loc *time.Location) time.Time {
+	if loc != nil {
+		t = t.In(loc)
+	}
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfDay returns the last nanosecond of t's date in loc
+func EndOfDay(t time.Time, loc *time.Location) time.Time {
+	start := BeginningOfDay(t, loc)
+	// AddDate instead of 24h keeps DST days correct
+	return start.AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// StartOfWeek returns midnight of the first day of t's week in loc, with
+// weeks starting on weekStart (time.Monday for ISO weeks)
+func StartOfWeek(t time.Time, loc *time.Location, weekStart time.Weekday) time.Time {
+	day := BeginningOfDay(t, loc)
+	offset := (int(day.Weekday()) - int(weekStart) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// EndOfWeek returns the last nanosecond of t's week in loc
+func EndOfWeek(t time.Time, loc *time.Location, weekStart time.Weekday) time.Time {
+	return StartOfWeek(t, loc, weekStart).AddDate(0, 0, 7).Add(-time.Nanosecond)
+}
+
+// BeginningOfMonth returns midnight of the first day of t's month in loc
+func BeginningOfMonth(t time.Time, loc *time.Location) time.Time {
+	day := BeginningOfDay(t, loc)
+	return day.AddDate(0, 0, 1-day.Day())
+}
+
+// EndOfMonth returns the last nanosecond of t's month in loc
+func EndOfMonth(t time.Time, loc *time.Location) time.Time {
+	return BeginningOfMonth(t, loc).AddDate(0, 1, 0).Add(-time.Nanosecond)
+}
+
+// Calendar decides which days are business days. Besides weekends it knows
+// public holidays and extra working days, such as the weekend days worked in
+// exchange for a long national holiday.
+type Calendar struct {
+	weekend  map[time.Weekday]bool
+	holidays map[string]bool
+	workdays map[string]bool
+}
+
+// NewCalendar creates a calendar with Saturday and Sunday as weekend days
+// and the given holidays
+func NewCalendar(holidays ...time.Time) *Calendar {
+	c := &Calendar{
+		weekend:  map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+		holidays: make(map[string]bool),
+		workdays: make(map[string]bool),
+	}
+	c.AddHolidays(holidays...)
+	return c
+}
+
+// dateKey identifies a calendar date independent of time and location
+func dateKey(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+// SetWeekend replaces the weekend days, e.g. Friday and Saturday
+func (c *Calendar) SetWeekend(days ...time.Weekday) {
+	c.weekend = make(map[time.Weekday]bool, len(days))
+	for _, d := range days {
+		c.weekend[d] = true
+	}
+}
+
+// AddHolidays marks dates as non-business days
+func (c *Calendar) AddHolidays(days ...time.Time) {
+	for _, d := range days {
+		c.holidays[dateKey(d)] = true
+	}
+}
+
+// AddWorkdays marks dates as business days even if they fall on a weekend
+func (c *Calendar) AddWorkdays(days ...time.Time) {
+	for _, d := range days {
+		c.workdays[dateKey(d)] = true
+	}
+}
+
+// IsBusinessDay reports whether t's date is a business day. A nil calendar
+// only treats Saturday and Sunday as non-business days.
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	if c == nil {
+		return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+	}
+	key := dateKey(t)
+	if c.workdays[key] {
+		return true
+	}
+	return !c.weekend[t.Weekday()] && !c.holidays[key]
+}
+
+// AddBusinessDays moves t forward (or backward for negative n) by n business
+// days, keeping the time of day. Adding zero business days to a non-business
+// day returns t unchanged.
+func AddBusinessDays(t time.Time, n int, cal *Calendar) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		t = t.AddDate(0, 0, step)
+		if cal.IsBusinessDay(t) {
+			n--
+		}
+	}
+	return t
+}
+
+// BusinessDaysBetween counts business days in the half-open date range
+// [start, end)
+func BusinessDaysBetween(start, end time.Time, cal *Calendar) int {
+	count := 0
+	for d := BeginningOfDay(start, nil); d.Before(BeginningOfDay(end, start.Location())); d = d.AddDate(0, 0, 1) {
+		if cal.IsBusinessDay(d) {
+			count++
+		}
+	}
+	return count
+}
+
+// TimeRange is the half-open interval [Start, End)
+type TimeRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Contains reports whether t falls within the range
+func (r TimeRange) Contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// Overlaps reports whether two ranges share any instant; ranges that only
+// touch, such as 9:00-10:00 and 10:00-11:00, do not overlap
+func (r TimeRange) Overlaps(other TimeRange) bool {
+	return r.Start.Before(other.End) && other.Start.Before(r.End)
+}
+
+// Intersection returns the overlapping part of two ranges
+func (r TimeRange) Intersection(other TimeRange) (TimeRange, bool) {
+	if !r.Overlaps(other) {
+		return TimeRange{}, false
+	}
+	start, end := r.Start, r.End
+	if other.Start.After(start) {
+		start = other.Start
+	}
+	if other.End.Before(end) {
+		end = other.End
+	}
+	return TimeRange{Start: start, End: end}, true
+}
+
+// Duration returns the length of the range
+func (r TimeRange) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestDayBoundaries(t *testing.T) {
+	shanghai := mustLocation(t, "Asia/Shanghai")
+	// 2024-03-10 20:00 UTC is already 2024-03-11 in Shanghai
+	ts := time.Date(2024, 3, 10, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		got  time.Time
+		want time.Time
+	}{
+		{"beginning of day utc", BeginningOfDay(ts, time.UTC), time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"beginning of day shanghai", BeginningOfDay(ts, shanghai), time.Date(2024, 3, 11, 0, 0, 0, 0, shanghai)},
+		{"end of day shanghai", EndOfDay(ts, shanghai), time.Date(2024, 3, 11, 23, 59, 59, 999999999, shanghai)},
+		{"start of iso week", StartOfWeek(ts, shanghai, time.Monday), time.Date(2024, 3, 11, 0, 0, 0, 0, shanghai)},
+		{"start of sunday week", StartOfWeek(ts, time.UTC, time.Sunday), time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"end of week", EndOfWeek(ts, time.UTC, time.Monday), time.Date(2024, 3, 10, 23, 59, 59, 999999999, time.UTC)},
+		{"beginning of month", BeginningOfMonth(ts, shanghai), time.Date(2024, 3, 1, 0, 0, 0, 0, shanghai)},
+		{"end of february", EndOfMonth(time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), nil), time.Date(2024, 2, 29, 23, 59, 59, 999999999, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndOfDayDST(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+	// 2024-03-10 is 23 hours long in New York
+	day := time.Date(2024, 3, 10, 12, 0, 0, 0, ny)
+	if d := EndOfDay(day, ny).Sub(BeginningOfDay(day, ny)); d != 23*time.Hour-time.Nanosecond {
+		t.Errorf("DST day length = %v, want 23h", d)
+	}
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	friday := time.Date(2024, 9, 27, 10, 0, 0, 0, time.UTC)
+
+	// National Day holiday 2024-10-01..07 with 2024-09-29 and 10-12 worked
+	cal := NewCalendar()
+	for d := 1; d <= 7; d++ {
+		cal.AddHolidays(time.Date(2024, 10, d, 0, 0, 0, 0, time.UTC))
+	}
+	cal.AddWorkdays(time.Date(2024, 9, 29, 0, 0, 0, 0, time.UTC), time.Date(2024, 10, 12, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name string
+		n    int
+		cal  *Calendar
+		want time.Time
+	}{
+		{"weekend skipped", 1, nil, time.Date(2024, 9, 30, 10, 0, 0, 0, time.UTC)},
+		{"zero", 0, nil, friday},
+		{"backwards", -1, nil, time.Date(2024, 9, 26, 10, 0, 0, 0, time.UTC)},
+		{"makeup workday", 1, cal, time.Date(2024, 9, 29, 10, 0, 0, 0, time.UTC)},
+		{"across holiday", 3, cal, time.Date(2024, 10, 8, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AddBusinessDays(friday, tt.n, tt.cal); !got.Equal(tt.want) {
+				t.Errorf("AddBusinessDays() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := BusinessDaysBetween(friday, time.Date(2024, 10, 14, 0, 0, 0, 0, time.UTC), cal); got != 8 {
+		t.Errorf("BusinessDaysBetween() = %d, want 8", got)
+	}
+
+	cal.SetWeekend(time.Friday, time.Saturday)
+	if cal.IsBusinessDay(friday) {
+		t.Error("IsBusinessDay() should honour a custom weekend")
+	}
+}
+
+func TestTimeRange(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2024, 1, 1, h, 0, 0, 0, time.UTC) }
+
+	morning := TimeRange{Start: at(9), End: at(12)}
+	tests := []struct {
+		name    string
+		other   TimeRange
+		overlap bool
+	}{
+		{"inside", TimeRange{at(10), at(11)}, true},
+		{"partial", TimeRange{at(11), at(13)}, true},
+		{"touching", TimeRange{at(12), at(13)}, false},
+		{"before", TimeRange{at(7), at(8)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := morning.Overlaps(tt.other); got != tt.overlap {
+				t.Errorf("Overlaps() = %v, want %v", got, tt.overlap)
+			}
+			if got := tt.other.Overlaps(morning); got != tt.overlap {
+				t.Errorf("Overlaps() should be symmetric")
+			}
+		})
+	}
+
+	inter, ok := morning.Intersection(TimeRange{at(11), at(13)})
+	if !ok || !inter.Start.Equal(at(11)) || inter.Duration() != time.Hour {
+		t.Errorf("Intersection() = %v, %v", inter, ok)
+	}
+	if !morning.Contains(at(9)) || morning.Contains(at(12)) {
+		t.Error("Contains() should include Start and exclude End")
+	}
+}
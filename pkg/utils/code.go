@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// GenerateNumericCode generates a random decimal code of n digits, e.g. an
+// SMS verification code. Leading zeros are kept, so "004821" is possible.
+func GenerateNumericCode(n int) (string, error) {
+	if n <= 0 || n > 18 {
+		return "", errors.New("numeric code length must be between 1 and 18")
+	}
+
+	limit := big.NewInt(1)
+	for i := 0; i < n; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	v, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return fmt.Sprintf("%0*d", n, v.Int64()), nil
+}
+
+// GenerateShortCode generates a random code of n Crockford base32
+// characters, e.g. an invite code. The alphabet has no I, L, O or U, so codes
+// are easy to read aloud and type.
+func GenerateShortCode(n int) (string, error) {
+	if n <= 0 {
+		return "", errors.New("short code length must be positive")
+	}
+
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	// 256 is a multiple of 32, so masking keeps the distribution uniform
+	for i, b := range buf {
+		buf[i] = crockford[b&0x1f]
+	}
+	return string(buf), nil
+}
+
+// NormalizeShortCode upper-cases user input and maps look-alike characters
+// back to the Crockford alphabet (O to 0, I and L to 1), dropping dashes and
+// spaces, so "abc-def" and "ABCDEF" compare equal
+func NormalizeShortCode(code string) string {
+	var b strings.Builder
+	b.Grow(len(code))
+	for _, r := range strings.ToUpper(code) {
+		switch r {
+		case '-', ' ':
+			continue
+		case 'O':
+			r = '0'
+		case 'I', 'L':
+			r = '1'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
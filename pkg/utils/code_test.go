@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateNumericCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		wantErr bool
+	}{
+		{"length 4", 4, false},
+		{"length 6", 6, false},
+		{"length 18", 18, false},
+		{"length 0", 0, true},
+		{"length 19", 19, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := GenerateNumericCode(tt.length)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateNumericCode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !regexp.MustCompile(`^\d+$`).MatchString(code) || len(code) != tt.length {
+				t.Errorf("GenerateNumericCode() = %q, want %d digits", code, tt.length)
+			}
+		})
+	}
+}
+
+func TestGenerateShortCode(t *testing.T) {
+	re := regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{8}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := GenerateShortCode(8)
+		if err != nil {
+			t.Fatalf("GenerateShortCode() error = %v", err)
+		}
+		if !re.MatchString(code) {
+			t.Fatalf("GenerateShortCode() = %q, not Crockford base32", code)
+		}
+		if seen[code] {
+			t.Fatalf("GenerateShortCode() returned duplicate %q", code)
+		}
+		seen[code] = true
+	}
+
+	if _, err := GenerateShortCode(0); err == nil {
+		t.Error("GenerateShortCode() should reject zero length")
+	}
+}
+
+func TestNormalizeShortCode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"abc-def", "ABCDEF"},
+		{"O1L-IO", "01110"},
+		{"7K 3M", "7K3M"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := NormalizeShortCode(tt.input); got != tt.want {
+				t.Errorf("NormalizeShortCode(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer delays calls to a function until no new call has arrived for
+// the wait period; only the latest argument is delivered. It suits bursts
+// such as config reload storms where only the final state matters.
+type Debouncer[T any] struct {
+	wait time.Duration
+	fn   func(T)
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	pending bool
+	value   T
+	stopped bool
+}
+
+// NewDebouncer creates a debouncer calling fn once calls have been quiet for wait
+func NewDebouncer[T any](wait time.Duration, fn func(T)) *Debouncer[T] {
+	return &Debouncer[T]{wait: wait, fn: fn}
+}
+
+// Call schedules fn with v, replacing any pending argument and restarting the wait
+func (d *Debouncer[T]) Call(v T) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return
+	}
+	d.value, d.pending = v, true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(d.wait, d.fire)
+}
+
+// fire runs the pending call, if any
+func (d *Debouncer[T]) fire() {
+	d.mu.Lock()
+	if !d.pending || d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	v := d.value
+	d.pending = false
+	d.mu.Unlock()
+	d.fn(v)
+}
+
+// Flush runs a pending call immediately instead of waiting
+func (d *Debouncer[T]) Flush() {
+	d.mu.Lock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.mu.Unlock()
+	d.fire()
+}
+
+// Stop discards any pending call and ignores future calls
+func (d *Debouncer[T]) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped, d.pending = true, false
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
+
+// Debounce returns a function that debounces calls to fn
+func Debounce[T any](wait time.Duration, fn func(T)) func(T) {
+	return NewDebouncer(wait, fn).Call
+}
+
+// Throttler limits a function to at most one call per interval. The first
+// call runs immediately; calls arriving during the interval collapse into a
+// single trailing call with the latest argument, so the last update is
+// never lost.
+type Throttler[T any] struct {
+	interval time.Duration
+	fn       func(T)
+
+	mu       sync.Mutex
+	last     time.Time
+	timer    *time.Timer
+	pending  bool
+	value    T
+	stopped  bool
+	inflight sync.Mutex
+}
+
+// NewThrottler creates a throttler calling fn at most once per interval
+func NewThrottler[T any](interval time.Duration, fn func(T)) *Throttler[T] {
+	return &Throttler[T]{interval: interval, fn: fn}
+}
+
+// Call runs fn with v now if the interval has elapsed, otherwise schedules
+// it for the end of the interval
+func (t *Throttler[T]) Call(v T) {
+	t.mu.Lock()
+	if t.stopped {
+		t.mu.Unlock()
+		return
+	}
+	elapsed := time.Since(t.last)
+	if t.timer == nil && elapsed >= t.interval {
+		t.last = time.Now()
+		t.mu.Unlock()
+		t.run(v)
+		return
+	}
+	t.value, t.pending = v, true
+	if t.timer == nil {
+		t.timer = time.AfterFunc(t.interval-elapsed, t.trailing)
+	}
+	t.mu.Unlock()
+}
+
+// trailing runs the collapsed call at the end of an interval
+func (t *Throttler[T]) trailing() {
+	t.mu.Lock()
+	t.timer = nil
+	if !t.pending || t.stopped {
+		t.mu.Unlock()
+		return
+	}
+	v := t.value
+	t.pending = false
+	t.last = time.Now()
+	t.mu.Unlock()
+	t.run(v)
+}
+
+// run calls fn, never concurrently with itself
+func (t *Throttler[T]) run(v T) {
+	t.inflight.Lock()
+	defer t.inflight.Unlock()
+	t.fn(v)
+}
+
+// Stop discards any pending call and ignores future calls
+func (t *Throttler[T]) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped, t.pending = true, false
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// Throttle returns a function that throttles calls to fn
+func Throttle[T any](interval time.Duration, fn func(T)) func(T) {
+	return NewThrottler(interval, fn).Call
+}
+
+// OnceTTL computes a value once and caches it for a TTL, e.g. a token or a
+// remote config snapshot. Concurrent callers share a single computation and
+// errors are not cached, so the next call retries.
+type OnceTTL[T any] struct {
+	ttl time.Duration
+	fn  func() (T, error)
+
+	mu      sync.Mutex
+	value   T
+	expires time.Time
+	valid   bool
+	now     func() time.Time
+}
+
+// NewOnceTTL creates a OnceTTL around fn; a ttl of zero caches forever
+func NewOnceTTL[T any](ttl time.Duration, fn func() (T, error)) *OnceTTL[T] {
+	return &OnceTTL[T]{ttl: ttl, fn: fn, now: time.Now}
+}
+
+// Get returns the cached value, computing it when missing or expired
+func (o *OnceTTL[T]) Get() (T, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.valid && (o.ttl == 0 || o.now().Before(o.expires)) {
+		return o.value, nil
+	}
+
+	v, err := o.fn()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	o.value, o.valid = v, true
+	o.expires = o.now().Add(o.ttl)
+	return v, nil
+}
+
+// Reset drops the cached value so the next Get recomputes it
+func (o *OnceTTL[T]) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var zero T
+	o.value, o.valid = zero, false
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebouncer(t *testing.T) {
+	var mu sync.Mutex
+	var calls []int
+	d := NewDebouncer(30*time.Millisecond, func(v int) {
+		mu.Lock()
+		calls = append(calls, v)
+		mu.Unlock()
+	})
+
+	for i := 1; i <= 5; i++ {
+		d.Call(i)
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(80 * time.Millisecond)
+
+	mu.Lock()
+	if len(calls) != 1 || calls[0] != 5 {
+		t.Errorf("calls = %v, want [5]", calls)
+	}
+	mu.Unlock()
+
+	d.Call(6)
+	d.Flush()
+	mu.Lock()
+	if len(calls) != 2 || calls[1] != 6 {
+		t.Errorf("Flush() calls = %v, want [5 6]", calls)
+	}
+	mu.Unlock()
+
+	d.Call(7)
+	d.Stop()
+	d.Call(8)
+	time.Sleep(60 * time.Millisecond)
+	mu.Lock()
+	if len(calls) != 2 {
+		t.Errorf("calls after Stop() = %v", calls)
+	}
+	mu.Unlock()
+}
+
+func TestThrottler(t *testing.T) {
+	var mu sync.Mutex
+	var calls []int
+	th := NewThrottler(40*time.Millisecond, func(v int) {
+		mu.Lock()
+		calls = append(calls, v)
+		mu.Unlock()
+	})
+
+	for i := 1; i <= 5; i++ {
+		th.Call(i)
+	}
+	mu.Lock()
+	if len(calls) != 1 || calls[0] != 1 {
+		t.Errorf("leading calls = %v, want [1]", calls)
+	}
+	mu.Unlock()
+
+	time.Sleep(80 * time.Millisecond)
+	mu.Lock()
+	if len(calls) != 2 || calls[1] != 5 {
+		t.Errorf("trailing calls = %v, want [1 5]", calls)
+	}
+	mu.Unlock()
+
+	th.Stop()
+	th.Call(9)
+	mu.Lock()
+	if len(calls) != 2 {
+		t.Errorf("calls after Stop() = %v", calls)
+	}
+	mu.Unlock()
+}
+
+func TestOnceTTL(t *testing.T) {
+	var n atomic.Int32
+	fail := false
+	o := NewOnceTTL(time.Minute, func() (int32, error) {
+		if fail {
+			return 0, errors.New("boom")
+		}
+		return n.Add(1), nil
+	})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	o.now = func() time.Time { return now }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := o.Get(); err != nil || v != 1 {
+				t.Errorf("Get() = %v, %v, want 1", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	now = now.Add(2 * time.Minute)
+	fail = true
+	if _, err := o.Get(); err == nil {
+		t.Error("Get() after expiry should call fn and return its error")
+	}
+	fail = false
+	if v, _ := o.Get(); v != 2 {
+		t.Errorf("Get() after failed refresh = %v, want 2", v)
+	}
+
+	o.Reset()
+	if v, _ := o.Get(); v != 3 {
+		t.Errorf("Get() after Reset() = %v, want 3", v)
+	}
+}
@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// DeepCopy returns a copy of v that shares no pointers, slices or maps with
+// it. Unexported struct fields are copied shallowly, since reflection cannot
+// set them; channels and functions are shared.
+func DeepCopy[T any](v T) T {
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	copyValue(dst, src, make(map[uintptr]reflect.Value))
+	return dst.Interface().(T)
+}
+
+// copyValue deep copies src into dst; visited maps pointers already copied
+// so cyclic structures terminate and shared pointers stay shared
+func copyValue(dst, src reflect.Value, visited map[uintptr]reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		if p, ok := visited[src.Pointer()]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Elem().Type())
+		visited[src.Pointer()] = p
+		copyValue(p.Elem(), src.Elem(), visited)
+		dst.Set(p)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		inner := reflect.New(src.Elem().Type()).Elem()
+		copyValue(inner, src.Elem(), visited)
+		dst.Set(inner)
+	case reflect.Struct:
+		// Copy the whole struct first so unexported fields keep their values
+		dst.Set(src)
+		if src.Type() == timeType {
+			return
+		}
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				copyValue(dst.Field(i), src.Field(i), visited)
+			}
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			copyValue(s.Index(i), src.Index(i), visited)
+		}
+		dst.Set(s)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i), visited)
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(iter.Key().Type()).Elem()
+			copyValue(k, iter.Key(), visited)
+			val := reflect.New(iter.Value().Type()).Elem()
+			copyValue(val, iter.Value(), visited)
+			m.SetMapIndex(k, val)
+		}
+		dst.Set(m)
+	default:
+		dst.Set(src)
+	}
+}
+
+// Change is one difference reported by Diff
+type Change struct {
+	// Path locates the field using JSON names, e.g. "address.city" or "items[2].sku"
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// String renders the change as "path: old -> new"
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// Diff compares two values of the same type and returns the changed leaf
+// fields, named by their json tags. Fields tagged `json:"-"` and unexported
+// fields are ignored; time.Time values are compared with Equal.
+func Diff(old, new interface{}) []Change {
+	var changes []Change
+	diffValue("", reflect.ValueOf(old), reflect.ValueOf(new), &changes)
+	return changes
+}
+
+// diffValue appends the differences between a and b under path
+func diffValue(path string, a, b reflect.Value, changes *[]Change) {
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			*changes = append(*changes, Change{Path: path, Old: valueOf(a), New: valueOf(b)})
+		}
+		return
+	}
+	if a.Type() != b.Type() {
+		*changes = append(*changes, Change{Path: path, Old: valueOf(a), New: valueOf(b)})
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*changes = append(*changes, Change{Path: path, Old: valueOf(a), New: valueOf(b)})
+			}
+			return
+		}
+		diffValue(path, a.Elem(), b.Elem(), changes)
+	case reflect.Struct:
+		if a.Type() == timeType {
+			if !a.Interface().(time.Time).Equal(b.Interface().(time.Time)) {
+				*changes = append(*changes, Change{Path: path, Old: a.Interface(), New: b.Interface()})
+			}
+			return
+		}
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+				// Embedded structs are flattened like encoding/json does
+				diffValue(path, a.Field(i), b.Field(i), changes)
+				continue
+			}
+			name, ok := jsonFieldName(field)
+			if !ok {
+				continue
+			}
+			diffValue(joinPath(path, name), a.Field(i), b.Field(i), changes)
+		}
+	case reflect.Slice, reflect.Array:
+		n := a.Len()
+		if b.Len() > n {
+			n = b.Len()
+		}
+		for i := 0; i < n; i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= a.Len():
+				*changes = append(*changes, Change{Path: p, New: b.Index(i).Interface()})
+			case i >= b.Len():
+				*changes = append(*changes, Change{Path: p, Old: a.Index(i).Interface()})
+			default:
+				diffValue(p, a.Index(i), b.Index(i), changes)
+			}
+		}
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, k := range append(a.MapKeys(), b.MapKeys()...) {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			k := keys[name]
+			diffValue(joinPath(path, name), a.MapIndex(k), b.MapIndex(k), changes)
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changes = append(*changes, Change{Path: path, Old: a.Interface(), New: b.Interface()})
+		}
+	}
+}
+
+// valueOf returns the interface value of v, or nil when v is invalid or nil
+func valueOf(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	return v.Interface()
+}
+
+// jsonFieldName returns the JSON name of a struct field and whether it is
+// serialized at all
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
+
+// joinPath appends a segment to a dotted path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
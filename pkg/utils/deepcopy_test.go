@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type testItem struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty"`
+}
+
+type testAudit struct {
+	UpdatedBy string `json:"updated_by"`
+}
+
+type testOrder struct {
+	testAudit
+	ID        string            `json:"id"`
+	Status    string            `json:"status"`
+	Address   *testAddress      `json:"address"`
+	Items     []testItem        `json:"items"`
+	Meta      map[string]string `json:"meta"`
+	CreatedAt time.Time         `json:"created_at"`
+	Secret    string            `json:"-"`
+	NoTag     int
+	internal  int
+}
+
+func TestDeepCopy(t *testing.T) {
+	src := testOrder{
+		ID:        "o-1",
+		Address:   &testAddress{City: "Shanghai"},
+		Items:     []testItem{{SKU: "a", Qty: 1}},
+		Meta:      map[string]string{"channel": "web"},
+		CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		internal:  7,
+	}
+
+	dst := DeepCopy(src)
+	if !reflect.DeepEqual(src, dst) {
+		t.Fatalf("DeepCopy() = %+v, want %+v", dst, src)
+	}
+
+	dst.Address.City = "Beijing"
+	dst.Items[0].Qty = 5
+	dst.Meta["channel"] = "app"
+	if src.Address.City != "Shanghai" || src.Items[0].Qty != 1 || src.Meta["channel"] != "web" {
+		t.Errorf("mutating the copy changed the source: %+v", src)
+	}
+	if dst.internal != 7 {
+		t.Errorf("unexported field not copied")
+	}
+}
+
+func TestDeepCopyCycles(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+	a := &node{Name: "a"}
+	a.Next = &node{Name: "b", Next: a}
+
+	c := DeepCopy(a)
+	if c == a || c.Next.Next != c {
+		t.Errorf("DeepCopy() should copy cycles into a new cycle")
+	}
+
+	var nilMap map[string]int
+	if DeepCopy(nilMap) != nil {
+		t.Error("DeepCopy() of a nil map should stay nil")
+	}
+	iface := DeepCopy(interface{}([]int{1, 2}))
+	if !reflect.DeepEqual(iface, []int{1, 2}) {
+		t.Errorf("DeepCopy() of an interface = %v", iface)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	old := testOrder{
+		testAudit: testAudit{UpdatedBy: "alice"},
+		ID:        "o-1",
+		Status:    "created",
+		Address:   &testAddress{City: "Shanghai"},
+		Items:     []testItem{{SKU: "a", Qty: 1}},
+		Meta:      map[string]string{"channel": "web"},
+		CreatedAt: created,
+		Secret:    "x",
+	}
+	updated := DeepCopy(old)
+	updated.UpdatedBy = "bob"
+	updated.Status = "paid"
+	updated.Address.City = "Beijing"
+	updated.Items[0].Qty = 2
+	updated.Items = append(updated.Items, testItem{SKU: "b", Qty: 1})
+	updated.Meta["coupon"] = "SPRING"
+	updated.CreatedAt = created.In(time.FixedZone("CST", 8*3600))
+	updated.Secret = "y"
+	updated.NoTag = 3
+
+	got := Diff(old, updated)
+	want := []Change{
+		{Path: "updated_by", Old: "alice", New: "bob"},
+		{Path: "status", Old: "created", New: "paid"},
+		{Path: "address.city", Old: "Shanghai", New: "Beijing"},
+		{Path: "items[0].qty", Old: 1, New: 2},
+		{Path: "items[1]", New: testItem{SKU: "b", Qty: 1}},
+		{Path: "meta.coupon", Old: nil, New: "SPRING"},
+		{Path: "NoTag", Old: 0, New: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() =\n%v\nwant\n%v", got, want)
+	}
+
+	if changes := Diff(old, old); len(changes) != 0 {
+		t.Errorf("Diff() of equal values = %v", changes)
+	}
+
+	cleared := DeepCopy(old)
+	cleared.Address = nil
+	if changes := Diff(old, cleared); len(changes) != 1 || changes[0].Path != "address" || changes[0].New != nil {
+		t.Errorf("Diff() with nil pointer = %v", changes)
+	}
+}
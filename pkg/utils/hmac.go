@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+)
+
+// HMACAlgorithm selects the hash used for HMAC signatures
+type HMACAlgorithm string
+
+const (
+	// HMACSHA256 signs with HMAC-SHA256
+	HMACSHA256 HMACAlgorithm = "sha256"
+	// HMACSHA512 signs with HMAC-SHA512
+	HMACSHA512 HMACAlgorithm = "sha512"
+)
+
+// hashFunc returns the hash constructor of an algorithm, defaulting to SHA-256
+func (a HMACAlgorithm) hashFunc() func() hash.Hash {
+	if a == HMACSHA512 {
+		return sha512.New
+	}
+	return sha256.New
+}
+
+// HMAC computes the raw HMAC of message
+func HMAC(alg HMACAlgorithm, key, message []byte) []byte {
+	mac := hmac.New(alg.hashFunc(), key)
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// SignHMAC returns the HMAC of message encoded as unpadded base64url, safe
+// to use in URLs, headers and cookies
+func SignHMAC(alg HMACAlgorithm, key, message []byte) string {
+	return base64.RawURLEncoding.EncodeToString(HMAC(alg, key, message))
+}
+
+// VerifyHMAC checks a signature produced by SignHMAC in constant time
+func VerifyHMAC(alg HMACAlgorithm, key, message []byte, signature string) bool {
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(sig, HMAC(alg, key, message))
+}
+
+// SignSHA256 signs a string message with HMAC-SHA256
+func SignSHA256(secret, message string) string {
+	return SignHMAC(HMACSHA256, []byte(secret), []byte(message))
+}
+
+// VerifySHA256 verifies a SignSHA256 signature in constant time
+func VerifySHA256(secret, message, signature string) bool {
+	return VerifyHMAC(HMACSHA256, []byte(secret), []byte(message), signature)
+}
+
+// SignSHA512 signs a string message with HMAC-SHA512
+func SignSHA512(secret, message string) string {
+	return SignHMAC(HMACSHA512, []byte(secret), []byte(message))
+}
+
+// VerifySHA512 verifies a SignSHA512 signature in constant time
+func VerifySHA512(secret, message, signature string) bool {
+	return VerifyHMAC(HMACSHA512, []byte(secret), []byte(message), signature)
+}
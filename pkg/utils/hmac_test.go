@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestHMAC(t *testing.T) {
+	// RFC 4231 test case 2
+	key := []byte("Jefe")
+	message := []byte("what do ya want for nothing?")
+
+	tests := []struct {
+		alg  HMACAlgorithm
+		want string
+	}{
+		{HMACSHA256, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+		{HMACSHA512, "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.alg), func(t *testing.T) {
+			if got := hex.EncodeToString(HMAC(tt.alg, key, message)); got != tt.want {
+				t.Errorf("HMAC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		sign   func(secret, message string) string
+		verify func(secret, message, signature string) bool
+	}{
+		{"sha256", SignSHA256, VerifySHA256},
+		{"sha512", SignSHA512, VerifySHA512},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig := tt.sign("secret", "payload")
+			if !tt.verify("secret", "payload", sig) {
+				t.Error("verify() should accept a valid signature")
+			}
+			if tt.verify("other", "payload", sig) {
+				t.Error("verify() should reject a signature made with another secret")
+			}
+			if tt.verify("secret", "payload2", sig) {
+				t.Error("verify() should reject a signature over another message")
+			}
+			if tt.verify("secret", "payload", sig+"=") {
+				t.Error("verify() should reject malformed signatures")
+			}
+		})
+	}
+}
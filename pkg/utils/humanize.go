@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HumanDuration formats a duration by its two most significant units, e.g.
+// "2h 15m", "3d 4h" or "850ms"
+func HumanDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + HumanDuration(-d)
+	}
+	if d < time.Second {
+		if d < time.Millisecond {
+			return d.String()
+		}
+		return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+	}
+
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+
+	var parts []string
+	for _, u := range units {
+		if d < u.size && len(parts) == 0 {
+			continue
+		}
+		n := d / u.size
+		d -= n * u.size
+		if n > 0 {
+			parts = append(parts, strconv.FormatInt(int64(n), 10)+u.suffix)
+		}
+		if len(parts) == 2 || (len(parts) > 0 && n == 0) {
+			break
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// HumanBytes formats a byte count with SI units, e.g. "1.4 GB"
+func HumanBytes(n int64) string {
+	return humanBytes(n, 1000, []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"})
+}
+
+// HumanBytesIEC formats a byte count with binary units, e.g. "1.3 GiB"
+func HumanBytesIEC(n int64) string {
+	return humanBytes(n, 1024, []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"})
+}
+
+// humanBytes scales n by base until it fits the unit
+func humanBytes(n int64, base float64, units []string) string {
+	sign := ""
+	v := float64(n)
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	if v < base {
+		return fmt.Sprintf("%s%d %s", sign, int64(v), units[0])
+	}
+
+	i := 0
+	for v >= base && i < len(units)-1 {
+		v /= base
+		i++
+	}
+	s := strconv.FormatFloat(v, 'f', 1, 64)
+	s = strings.TrimSuffix(s, ".0")
+	return sign + s + " " + units[i]
+}
+
+// RelativeLocale holds the phrases used by RelativeTimeIn
+type RelativeLocale struct {
+	// JustNow is used for differences below one minute
+	JustNow string
+	// Past and Future wrap the amount, e.g. "%s ago" and "in %s"
+	Past   string
+	Future string
+	// Units maps second, minute, hour, day, week, month and year to their
+	// singular and plural formats, e.g. {"%d minute", "%d minutes"}
+	Units map[string][2]string
+}
+
+var (
+	// LocaleEN is the English relative time locale
+	LocaleEN = RelativeLocale{
+		JustNow: "just now",
+		Past:    "%s ago",
+		Future:  "in %s",
+		Units: map[string][2]string{
+			"minute": {"%d minute", "%d minutes"},
+			"hour":   {"%d hour", "%d hours"},
+			"day":    {"%d day", "%d days"},
+			"week":   {"%d week", "%d weeks"},
+			"month":  {"%d month", "%d months"},
+			"year":   {"%d year", "%d years"},
+		},
+	}
+	// LocaleZH is the Simplified Chinese relative time locale
+	LocaleZH = RelativeLocale{
+		JustNow: "刚刚",
+		Past:    "%s前",
+		Future:  "%s后",
+		Units: map[string][2]string{
+			"minute": {"%d分钟", "%d分钟"},
+			"hour":   {"%d小时", "%d小时"},
+			"day":    {"%d天", "%d天"},
+			"week":   {"%d周", "%d周"},
+			"month":  {"%d个月", "%d个月"},
+			"year":   {"%d年", "%d年"},
+		},
+	}
+)
+
+// RelativeTime describes t relative to now in English, e.g. "3 minutes ago"
+// or "in 2 hours"
+func RelativeTime(t, now time.Time) string {
+	return RelativeTimeIn(t, now, LocaleEN)
+}
+
+// RelativeTimeIn describes t relative to now using the given locale
+func RelativeTimeIn(t, now time.Time, locale RelativeLocale) string {
+	d := now.Sub(t)
+	wrap := locale.Past
+	if d < 0 {
+		d, wrap = -d, locale.Future
+	}
+	if d < time.Minute {
+		return locale.JustNow
+	}
+
+	var unit string
+	var n int64
+	switch {
+	case d < time.Hour:
+		unit, n = "minute", int64(d/time.Minute)
+	case d < 24*time.Hour:
+		unit, n = "hour", int64(d/time.Hour)
+	case d < 7*24*time.Hour:
+		unit, n = "day", int64(d/(24*time.Hour))
+	case d < 30*24*time.Hour:
+		unit, n = "week", int64(d/(7*24*time.Hour))
+	case d < 365*24*time.Hour:
+		unit, n = "month", int64(d/(30*24*time.Hour))
+	default:
+		unit, n = "year", int64(d/(365*24*time.Hour))
+	}
+
+	forms := locale.Units[unit]
+	format := forms[1]
+	if n == 1 {
+		format = forms[0]
+	}
+	return fmt.Sprintf(wrap, fmt.Sprintf(format, n))
+}
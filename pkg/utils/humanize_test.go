@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanDuration(t *testing.T) {
+	tests := []struct {
+		input time.Duration
+		want  string
+	}{
+		{2*time.Hour + 15*time.Minute + 30*time.Second, "2h 15m"},
+		{3*24*time.Hour + 4*time.Hour + 5*time.Minute, "3d 4h"},
+		{24*time.Hour + 30*time.Minute, "1d"},
+		{90 * time.Second, "1m 30s"},
+		{45 * time.Second, "45s"},
+		{850 * time.Millisecond, "850ms"},
+		{500 * time.Microsecond, "500µs"},
+		{0, "0s"},
+		{-5 * time.Minute, "-5m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := HumanDuration(tt.input); got != tt.want {
+				t.Errorf("HumanDuration(%v) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	tests := []struct {
+		input int64
+		si    string
+		iec   string
+	}{
+		{0, "0 B", "0 B"},
+		{999, "999 B", "999 B"},
+		{1000, "1 kB", "1000 B"},
+		{1536, "1.5 kB", "1.5 KiB"},
+		{1_400_000_000, "1.4 GB", "1.3 GiB"},
+		{-2048, "-2 kB", "-2 KiB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.si, func(t *testing.T) {
+			if got := HumanBytes(tt.input); got != tt.si {
+				t.Errorf("HumanBytes(%d) = %q, want %q", tt.input, got, tt.si)
+			}
+			if got := HumanBytesIEC(tt.input); got != tt.iec {
+				t.Errorf("HumanBytesIEC(%d) = %q, want %q", tt.input, got, tt.iec)
+			}
+		})
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		offset time.Duration
+		en     string
+		zh     string
+	}{
+		{-10 * time.Second, "just now", "刚刚"},
+		{-3 * time.Minute, "3 minutes ago", "3分钟前"},
+		{-time.Hour, "1 hour ago", "1小时前"},
+		{2 * time.Hour, "in 2 hours", "2小时后"},
+		{-3 * 24 * time.Hour, "3 days ago", "3天前"},
+		{-14 * 24 * time.Hour, "2 weeks ago", "2周前"},
+		{-60 * 24 * time.Hour, "2 months ago", "2个月前"},
+		{-400 * 24 * time.Hour, "1 year ago", "1年前"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.en, func(t *testing.T) {
+			ts := now.Add(tt.offset)
+			if got := RelativeTime(ts, now); got != tt.en {
+				t.Errorf("RelativeTime() = %q, want %q", got, tt.en)
+			}
+			if got := RelativeTimeIn(ts, now, LocaleZH); got != tt.zh {
+				t.Errorf("RelativeTimeIn(zh) = %q, want %q", got, tt.zh)
+			}
+		})
+	}
+}
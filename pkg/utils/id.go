@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// crockford is the ULID alphabet (Crockford's base32, no I, L, O, U)
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// GenerateUUIDv4 generates a random RFC 9562 version 4 UUID
+func GenerateUUIDv4() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return formatUUID(b), nil
+}
+
+// GenerateUUIDv7 generates a time-ordered RFC 9562 version 7 UUID, suitable
+// as a database primary key since new IDs sort after older ones
+func GenerateUUIDv7() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	ms := uint64(time.Now().UnixMilli())
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	b[6] = (b[6] & 0x0f) | 0x70
+	b[8] = (b[8] & 0x3f) | 0x80
+	return formatUUID(b), nil
+}
+
+// formatUUID renders 16 bytes in the canonical 8-4-4-4-12 form
+func formatUUID(b [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf[:])
+}
+
+// IsUUID checks if a string is a canonical UUID of any version
+func IsUUID(s string) bool {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return false
+	}
+	_, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	return err == nil
+}
+
+// GenerateULID generates a 26 character ULID: a 48-bit millisecond timestamp
+// followed by 80 random bits, lexicographically sortable by creation time
+func GenerateULID() (string, error) {
+	var entropy [10]byte
+	if _, err := rand.Read(entropy[:]); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return encodeULID(uint64(time.Now().UnixMilli()), entropy), nil
+}
+
+// ErrULIDOverflow is returned when more ULIDs are requested within one
+// millisecond than the monotonic entropy can hold
+var ErrULIDOverflow = errors.New("ulid entropy overflow")
+
+// MonotonicULID generates ULIDs that are strictly increasing even when
+// several are created within the same millisecond, by incrementing the
+// random part of the previous ID instead of drawing a new one
+type MonotonicULID struct {
+	mu      sync.Mutex
+	lastMS  uint64
+	entropy [10]byte
+}
+
+// NewMonotonicULID creates a monotonic ULID generator
+func NewMonotonicULID() *MonotonicULID {
+	return &MonotonicULID{}
+}
+
+// Generate returns the next ULID
+func (m *MonotonicULID) Generate() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ms := uint64(time.Now().UnixMilli())
+	// A clock that moves backwards keeps using the last timestamp so the
+	// sequence never goes down.
+	if ms <= m.lastMS {
+		ms = m.lastMS
+		if !incrementEntropy(&m.entropy) {
+			return "", ErrULIDOverflow
+		}
+	} else {
+		if _, err := rand.Read(m.entropy[:]); err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %w", err)
+		}
+		m.lastMS = ms
+	}
+	return encodeULID(ms, m.entropy), nil
+}
+
+// incrementEntropy adds one to the 80-bit entropy, reporting false on overflow
+func incrementEntropy(e *[10]byte) bool {
+	for i := len(e) - 1; i >= 0; i-- {
+		e[i]++
+		if e[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID renders a timestamp and entropy in Crockford base32
+func encodeULID(ms uint64, entropy [10]byte) string {
+	var buf [26]byte
+	// 48-bit timestamp as 10 characters, most significant first
+	for i := 9; i >= 0; i-- {
+		buf[i] = crockford[ms&0x1f]
+		ms >>= 5
+	}
+	// 80-bit entropy as 16 characters, 5 bits each
+	hi := uint64(binary.BigEndian.Uint16(entropy[0:2]))
+	lo := binary.BigEndian.Uint64(entropy[2:])
+	for i := 25; i >= 10; i-- {
+		buf[i] = crockford[lo&0x1f]
+		lo = lo>>5 | (hi&0x1f)<<59
+		hi >>= 5
+	}
+	return string(buf[:])
+}
+
+// ULIDTime extracts the creation time from a ULID
+func ULIDTime(id string) (time.Time, error) {
+	if len(id) != 26 {
+		return time.Time{}, fmt.Errorf("invalid ulid length: %d", len(id))
+	}
+	var ms uint64
+	for i := 0; i < 10; i++ {
+		idx := strings.IndexByte(crockford, upperASCII(id[i]))
+		if idx < 0 {
+			return time.Time{}, fmt.Errorf("invalid ulid character: %q", id[i])
+		}
+		ms = ms<<5 | uint64(idx)
+	}
+	if ms >= 1<<48 {
+		return time.Time{}, errors.New("ulid timestamp out of range")
+	}
+	return time.UnixMilli(int64(ms)), nil
+}
+
+// upperASCII upper-cases an ASCII letter so ULIDs parse case-insensitively
+func upperASCII(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestGenerateUUID(t *testing.T) {
+	tests := []struct {
+		name     string
+		generate func() (string, error)
+		pattern  string
+	}{
+		{"v4", GenerateUUIDv4, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{"v7", GenerateUUIDv7, `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re := regexp.MustCompile(tt.pattern)
+			seen := make(map[string]bool)
+			for i := 0; i < 100; i++ {
+				id, err := tt.generate()
+				if err != nil {
+					t.Fatalf("generate() error = %v", err)
+				}
+				if !re.MatchString(id) {
+					t.Fatalf("generate() = %v, does not match %s", id, tt.pattern)
+				}
+				if !IsUUID(id) {
+					t.Errorf("IsUUID(%v) = false", id)
+				}
+				if seen[id] {
+					t.Fatalf("generate() returned duplicate %v", id)
+				}
+				seen[id] = true
+			}
+		})
+	}
+}
+
+func TestGenerateUUIDv7Ordering(t *testing.T) {
+	first, _ := GenerateUUIDv7()
+	time.Sleep(2 * time.Millisecond)
+	second, _ := GenerateUUIDv7()
+	if first >= second {
+		t.Errorf("UUIDv7 %v should sort before %v", first, second)
+	}
+}
+
+func TestIsUUID(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", true},
+		{"6BA7B810-9DAD-11D1-80B4-00C04FD430C8", true},
+		{"6ba7b8109dad11d180b400c04fd430c8", false},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430cz", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := IsUUID(tt.input); got != tt.want {
+				t.Errorf("IsUUID(%v) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateULID(t *testing.T) {
+	re := regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+
+	before := time.Now().Truncate(time.Millisecond)
+	id, err := GenerateULID()
+	if err != nil {
+		t.Fatalf("GenerateULID() error = %v", err)
+	}
+	if !re.MatchString(id) {
+		t.Fatalf("GenerateULID() = %v, not a valid ULID", id)
+	}
+
+	ts, err := ULIDTime(id)
+	if err != nil {
+		t.Fatalf("ULIDTime() error = %v", err)
+	}
+	if ts.Before(before) || ts.After(time.Now()) {
+		t.Errorf("ULIDTime() = %v, want around %v", ts, before)
+	}
+}
+
+func TestMonotonicULID(t *testing.T) {
+	gen := NewMonotonicULID()
+
+	prev := ""
+	for i := 0; i < 1000; i++ {
+		id, err := gen.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if id <= prev {
+			t.Fatalf("Generate() = %v, not greater than %v", id, prev)
+		}
+		prev = id
+	}
+}
+
+func TestMonotonicULIDOverflow(t *testing.T) {
+	gen := NewMonotonicULID()
+	gen.lastMS = uint64(time.Now().Add(time.Hour).UnixMilli())
+	for i := range gen.entropy {
+		gen.entropy[i] = 0xff
+	}
+
+	if _, err := gen.Generate(); err != ErrULIDOverflow {
+		t.Errorf("Generate() error = %v, want %v", err, ErrULIDOverflow)
+	}
+}
+
+func TestULIDTimeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{"too short", "01ARZ3NDEK"},
+		{"invalid character", "01ARZ3NDEUTSV4RRFFQ69G5FAV"},
+		{"out of range", "ZZZZZZZZZZZZZZZZZZZZZZZZZZ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ULIDTime(tt.id); err == nil {
+				t.Errorf("ULIDTime(%v) should return an error", tt.id)
+			}
+		})
+	}
+}
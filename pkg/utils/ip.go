@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// cgnatPrefix is the RFC 6598 shared address space used by carrier-grade NAT
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// ParsePrefixes parses IP addresses and CIDR ranges, e.g. a trusted proxy
+// list such as ["10.0.0.0/8", "192.168.1.10"]. Single addresses become /32
+// or /128 prefixes.
+func ParsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			p, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cidr %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ip %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// prefixesContain reports whether any prefix contains addr
+func prefixesContain(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// RealIP returns the client IP of a request. Forwarding headers are only
+// honoured when the direct peer is a trusted proxy; X-Forwarded-For is then
+// read right to left, skipping trusted hops, so clients cannot spoof their
+// address by sending the header themselves.
+func RealIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	remote, ok := parseHostAddr(r.RemoteAddr)
+	if !ok {
+		return r.RemoteAddr
+	}
+	if !prefixesContain(trustedProxies, remote) {
+		return remote.String()
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		var leftmost netip.Addr
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, ok := parseHostAddr(strings.TrimSpace(hops[i]))
+			if !ok {
+				// A malformed hop breaks the chain of trust
+				break
+			}
+			if !prefixesContain(trustedProxies, addr) {
+				return addr.String()
+			}
+			leftmost = addr
+		}
+		if leftmost.IsValid() {
+			return leftmost.String()
+		}
+	}
+
+	if addr, ok := parseHostAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ok {
+		return addr.String()
+	}
+	return remote.String()
+}
+
+// parseHostAddr parses an IP that may carry a port, e.g. "1.2.3.4:5678" or
+// "[::1]:80"
+func parseHostAddr(s string) (netip.Addr, bool) {
+	if s == "" {
+		return netip.Addr{}, false
+	}
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return addr.Unmap(), true
+	}
+	host, _, err := net.SplitHostPort(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// CIDRContains reports whether ip falls within cidr
+func CIDRContains(cidr, ip string) (bool, error) {
+	p, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return false, fmt.Errorf("invalid cidr %q: %w", cidr, err)
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false, fmt.Errorf("invalid ip %q: %w", ip, err)
+	}
+	return p.Contains(addr.Unmap()), nil
+}
+
+// IsPrivateIP reports whether ip is not publicly routable: RFC 1918 and
+// unique local ranges, loopback, link-local and carrier-grade NAT
+func IsPrivateIP(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	return addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() ||
+		addr.IsUnspecified() || cgnatPrefix.Contains(addr)
+}
+
+// IsPublicIP reports whether ip is a valid, publicly routable unicast address
+func IsPublicIP(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	return !IsPrivateIP(ip) && !addr.IsMulticast()
+}
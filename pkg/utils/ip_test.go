@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted, err := ParsePrefixes([]string{"10.0.0.0/8", "192.168.1.10", "::1"})
+	if err != nil {
+		t.Fatalf("ParsePrefixes() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		remote  string
+		xff     []string
+		realIP  string
+		trusted bool
+		want    string
+	}{
+		{"direct client", "203.0.113.5:4321", nil, "", true, "203.0.113.5"},
+		{"untrusted peer ignores headers", "203.0.113.5:4321", []string{"1.1.1.1"}, "2.2.2.2", true, "203.0.113.5"},
+		{"trusted proxy", "10.0.0.2:80", []string{"198.51.100.7"}, "", true, "198.51.100.7"},
+		{"spoofed left entries skipped", "10.0.0.2:80", []string{"6.6.6.6, 198.51.100.7, 10.0.0.3"}, "", true, "198.51.100.7"},
+		{"multiple headers", "10.0.0.2:80", []string{"198.51.100.7", "10.0.0.3"}, "", true, "198.51.100.7"},
+		{"all hops trusted", "10.0.0.2:80", []string{"10.1.1.1, 10.0.0.3"}, "", true, "10.1.1.1"},
+		{"malformed hop", "10.0.0.2:80", []string{"198.51.100.7, garbage"}, "", true, "10.0.0.2"},
+		{"x-real-ip", "192.168.1.10:80", nil, "198.51.100.9", true, "198.51.100.9"},
+		{"ipv6 peer", "[::1]:8080", []string{"2001:db8::1"}, "", true, "2001:db8::1"},
+		{"no trusted proxies", "10.0.0.2:80", []string{"198.51.100.7"}, "", false, "10.0.0.2"},
+		{"xff with port", "10.0.0.2:80", []string{"198.51.100.7:5555"}, "", true, "198.51.100.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			proxies := trusted
+			if !tt.trusted {
+				proxies = nil
+			}
+			if got := RealIP(r, proxies); got != tt.want {
+				t.Errorf("RealIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePrefixesError(t *testing.T) {
+	for _, bad := range []string{"10.0.0.0/33", "not-an-ip", ""} {
+		if _, err := ParsePrefixes([]string{bad}); err == nil {
+			t.Errorf("ParsePrefixes(%q) should fail", bad)
+		}
+	}
+}
+
+func TestCIDRContains(t *testing.T) {
+	tests := []struct {
+		cidr    string
+		ip      string
+		want    bool
+		wantErr bool
+	}{
+		{"10.0.0.0/8", "10.20.30.40", true, false},
+		{"10.0.0.0/8", "11.0.0.1", false, false},
+		{"2001:db8::/32", "2001:db8::1", true, false},
+		{"192.168.0.0/16", "::ffff:192.168.1.1", true, false},
+		{"bad", "10.0.0.1", false, true},
+		{"10.0.0.0/8", "bad", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cidr+" "+tt.ip, func(t *testing.T) {
+			got, err := CIDRContains(tt.cidr, tt.ip)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CIDRContains() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CIDRContains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPrivateIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		private bool
+		public  bool
+	}{
+		{"10.1.2.3", true, false},
+		{"172.16.0.1", true, false},
+		{"192.168.1.1", true, false},
+		{"127.0.0.1", true, false},
+		{"169.254.1.1", true, false},
+		{"100.64.0.1", true, false},
+		{"fd00::1", true, false},
+		{"::1", true, false},
+		{"8.8.8.8", false, true},
+		{"2001:4860:4860::8888", false, true},
+		{"224.0.0.1", false, false},
+		{"invalid", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := IsPrivateIP(tt.ip); got != tt.private {
+				t.Errorf("IsPrivateIP(%v) = %v, want %v", tt.ip, got, tt.private)
+			}
+			if got := IsPublicIP(tt.ip); got != tt.public {
+				t.Errorf("IsPublicIP(%v) = %v, want %v", tt.ip, got, tt.public)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaskMiddle keeps the first keepStart and last keepEnd letters or digits of
+// s and masks the ones in between with '*'. Separators such as spaces and
+// dashes are kept so the masked value has the same shape as the original.
+func MaskMiddle(s string, keepStart, keepEnd int) string {
+	runes := []rune(s)
+	total := 0
+	for _, r := range runes {
+		if isMaskable(r) {
+			total++
+		}
+	}
+	// Too short to reveal anything safely
+	if total <= keepStart+keepEnd {
+		keepStart, keepEnd = 0, 0
+	}
+
+	seen := 0
+	for i, r := range runes {
+		if !isMaskable(r) {
+			continue
+		}
+		if seen >= keepStart && seen < total-keepEnd {
+			runes[i] = '*'
+		}
+		seen++
+	}
+	return string(runes)
+}
+
+// isMaskable reports whether a rune carries information worth masking
+func isMaskable(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// MaskEmail masks the local part of an email address while keeping its
+// domain, e.g. abcdef@example.com -> ab***@example.com
+func MaskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return MaskSensitive(email)
+	}
+
+	runes := []rune(local)
+	keep := 2
+	if len(runes) <= 2 {
+		keep = 1
+	}
+	if len(runes) == 0 {
+		keep = 0
+	}
+	return string(runes[:keep]) + "***@" + domain
+}
+
+// MaskPhone masks the middle digits of a phone number, e.g.
+// 13812345678 -> 138****5678 or +8613812345678 -> +86138****5678.
+// A leading country code and separators are kept.
+func MaskPhone(phone string) string {
+	prefix, number := "", phone
+	if strings.HasPrefix(number, "+86") {
+		prefix, number = "+86", number[3:]
+	} else if strings.HasPrefix(number, "+") {
+		prefix, number = "+", number[1:]
+	}
+
+	digits := 0
+	for _, r := range number {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	switch {
+	case digits >= 11:
+		return prefix + MaskMiddle(number, 3, 4)
+	case digits >= 7:
+		return prefix + MaskMiddle(number, 2, 4)
+	default:
+		return prefix + MaskMiddle(number, 0, 2)
+	}
+}
+
+// MaskBankCard masks a bank card number except its first 4 and last 4
+// digits, keeping grouping, e.g. 6222 0212 3456 7890 -> 6222 **** **** 7890
+func MaskBankCard(card string) string {
+	return MaskMiddle(card, 4, 4)
+}
+
+// MaskIDCard masks an identity card number except the region code and the
+// last 4 characters, e.g. 11010519491231002X -> 110105********002X
+func MaskIDCard(id string) string {
+	if len(id) == 18 || len(id) == 15 {
+		return MaskMiddle(id, 6, 4)
+	}
+	return MaskMiddle(id, 2, 2)
+}
+
+// MaskName masks a person's name except its first character, e.g.
+// 张三丰 -> 张**, Alice -> A****
+func MaskName(name string) string {
+	return MaskMiddle(name, 1, 0)
+}
@@ -0,0 +1,60 @@
+package utils
+
+import "testing"
+
+func TestMaskers(t *testing.T) {
+	tests := []struct {
+		name  string
+		fn    func(string) string
+		input string
+		want  string
+	}{
+		{"email", MaskEmail, "abcdef@example.com", "ab***@example.com"},
+		{"email short local", MaskEmail, "ab@example.com", "a***@example.com"},
+		{"email unicode local", MaskEmail, "张三丰@example.cn", "张三***@example.cn"},
+		{"email without at", MaskEmail, "not-an-email", "not-****mail"},
+		{"phone cn", MaskPhone, "13812345678", "138****5678"},
+		{"phone cn prefixed", MaskPhone, "+8613812345678", "+86138****5678"},
+		{"phone e164", MaskPhone, "+14155552671", "+141****2671"},
+		{"phone grouped", MaskPhone, "138-1234-5678", "138-****-5678"},
+		{"phone short", MaskPhone, "5552671", "55*2671"},
+		{"phone tiny", MaskPhone, "911", "*11"},
+		{"bank card", MaskBankCard, "6222021234567890", "6222********7890"},
+		{"bank card grouped", MaskBankCard, "6222 0212 3456 7890", "6222 **** **** 7890"},
+		{"id card", MaskIDCard, "11010519491231002X", "110105********002X"},
+		{"id card 15", MaskIDCard, "110105491231002", "110105*****1002"},
+		{"id card other", MaskIDCard, "G12345678", "G1*****78"},
+		{"name cjk", MaskName, "张三丰", "张**"},
+		{"name latin", MaskName, "Alice", "A****"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(tt.input); got != tt.want {
+				t.Errorf("mask(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaskMiddle(t *testing.T) {
+	tests := []struct {
+		input     string
+		keepStart int
+		keepEnd   int
+		want      string
+	}{
+		{"abcdefgh", 2, 2, "ab****gh"},
+		{"abcd", 2, 2, "****"},
+		{"a-b-c-d-e", 1, 1, "a-*-*-*-e"},
+		{"", 1, 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := MaskMiddle(tt.input, tt.keepStart, tt.keepEnd); got != tt.want {
+				t.Errorf("MaskMiddle(%q, %d, %d) = %q, want %q", tt.input, tt.keepStart, tt.keepEnd, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// RoundingMode selects how decimal results are rounded to minor units
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest value, ties to even (banker's rounding)
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds to the nearest value, ties away from zero
+	RoundHalfUp
+	// RoundDown truncates towards zero
+	RoundDown
+	// RoundUp rounds away from zero
+	RoundUp
+)
+
+var (
+	// ErrCurrencyMismatch is returned when combining amounts of different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrInvalidDecimal is returned for malformed decimal strings
+	ErrInvalidDecimal = errors.New("invalid decimal")
+	// ErrAmountOverflow is returned when a result does not fit in int64
+	ErrAmountOverflow = errors.New("amount overflow")
+)
+
+// currencyScales lists ISO 4217 currencies whose minor unit is not 1/100
+var currencyScales = map[string]int{
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0, "UGX": 0,
+	"BHD": 3, "KWD": 3, "OMR": 3, "JOD": 3, "TND": 3, "LYD": 3, "IQD": 3,
+}
+
+// CurrencyScale returns the number of decimal places of a currency's minor
+// unit, e.g. 2 for CNY and USD, 0 for JPY
+func CurrencyScale(currency string) int {
+	if scale, ok := currencyScales[strings.ToUpper(currency)]; ok {
+		return scale
+	}
+	return 2
+}
+
+// Money is an exact monetary amount stored as an integer number of minor
+// units (cents, fen) of a currency
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// NewMoney creates an amount from minor units, e.g. NewMoney(1999, "CNY") is 19.99 CNY
+func NewMoney(minor int64, currency string) Money {
+	return Money{Amount: minor, Currency: strings.ToUpper(currency)}
+}
+
+// ParseMoney parses a decimal string such as "19.99" in the given currency.
+// More decimal places than the currency allows are rejected rather than
+// silently rounded.
+func ParseMoney(s, currency string) (Money, error) {
+	minor, err := ParseDecimal(s, CurrencyScale(currency))
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(minor, currency), nil
+}
+
+// Scale returns the number of decimal places of the amount's currency
+func (m Money) Scale() int {
+	return CurrencyScale(m.Currency)
+}
+
+// Decimal returns the amount as a decimal string, e.g. "19.99"
+func (m Money) Decimal() string {
+	return FormatDecimal(m.Amount, m.Scale())
+}
+
+// String returns the amount and currency, e.g. "19.99 CNY"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	sum := m.Amount + other.Amount
+	if (sum > m.Amount) != (other.Amount > 0) {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Sub returns m - other
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrAmountOverflow
+	}
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// Cmp compares two amounts of the same currency, returning -1, 0 or 1
+func (m Money) Cmp(other Money) (int, error) {
+	if m.Currency != other.Currency {
+		return 0, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Mul multiplies the amount by an exact decimal factor such as "1.13" or
+// "0.085", rounding the result to minor units
+func (m Money) Mul(factor string, mode RoundingMode) (Money, error) {
+	f, ok := new(big.Rat).SetString(factor)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, factor)
+	}
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), f)
+	minor, err := roundRat(product, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: minor, Currency: m.Currency}, nil
+}
+
+// Allocate splits the amount by ratios without losing minor units; the
+// remainder is handed out one unit at a time starting with the first share.
+// Allocate(1, 1, 1) of 10.00 yields 3.34, 3.33 and 3.33.
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	var total int64
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, errors.New("ratios must not be negative")
+		}
+		total += int64(r)
+	}
+	if total == 0 {
+		return nil, errors.New("ratios must sum to more than zero")
+	}
+
+	shares := make([]Money, len(ratios))
+	remainder := m.Amount
+	for i, r := range ratios {
+		share := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(int64(r)))
+		share.Quo(share, big.NewInt(total))
+		shares[i] = Money{Amount: share.Int64(), Currency: m.Currency}
+		remainder -= shares[i].Amount
+	}
+
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(shares) {
+		if ratios[i] == 0 {
+			continue
+		}
+		shares[i].Amount += step
+		remainder -= step
+	}
+	return shares, nil
+}
+
+// Split divides the amount into n shares as equal as possible
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, errors.New("split count must be positive")
+	}
+	ratios := make([]int, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// moneyJSON is the wire form of Money; the amount is a decimal string so no
+// client ever parses it as a float
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as {"amount": "19.99", "currency": "CNY"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON decodes the form written by MarshalJSON
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Currency == "" {
+		return errors.New("money: currency is required")
+	}
+	parsed, err := ParseMoney(v.Amount, v.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// ParseDecimal parses a decimal string into an integer scaled by 10^scale,
+// e.g. ParseDecimal("12.5", 2) is 1250. It fails when s has more decimal
+// places than scale.
+func ParseDecimal(s string, scale int) (int64, error) {
+	s = strings.TrimSpace(s)
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if (whole == "" && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > scale {
+		return 0, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidDecimal, s, scale)
+	}
+
+	digits := whole + frac + strings.Repeat("0", scale-len(frac))
+	v, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		v = new(big.Int)
+	}
+	if neg {
+		v.Neg(v)
+	}
+	if !v.IsInt64() {
+		return 0, ErrAmountOverflow
+	}
+	return v.Int64(), nil
+}
+
+// isDigits reports whether s consists of ASCII digits only
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatDecimal formats an integer scaled by 10^scale as a decimal string,
+// e.g. FormatDecimal(-1250, 2) is "-12.50"
+func FormatDecimal(v int64, scale int) string {
+	digits := new(big.Int).Abs(big.NewInt(v)).String()
+	if scale > 0 {
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if v < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// RoundDecimal rounds a decimal string to scale places, e.g.
+// RoundDecimal("2.345", 2, RoundHalfUp) is "2.35"
+func RoundDecimal(s string, scale int, mode RoundingMode) (string, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	v, err := roundRat(r, mode)
+	if err != nil {
+		return "", err
+	}
+	return FormatDecimal(v, scale), nil
+}
+
+// roundRat rounds a rational number to an integer
+func roundRat(r *big.Rat, mode RoundingMode) (int64, error) {
+	num, den := r.Num(), r.Denom()
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	if rem.Sign() != 0 {
+		// twice the remainder against the denominator tells below/at/above half
+		half := new(big.Int).Abs(rem)
+		half.Lsh(half, 1)
+		cmp := half.Cmp(den)
+
+		away := false
+		switch mode {
+		case RoundUp:
+			away = true
+		case RoundHalfUp:
+			away = cmp >= 0
+		case RoundHalfEven:
+			away = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+		}
+		if away {
+			if num.Sign() < 0 {
+				q.Sub(q, big.NewInt(1))
+			} else {
+				q.Add(q, big.NewInt(1))
+			}
+		}
+	}
+
+	if !q.IsInt64() {
+		return 0, ErrAmountOverflow
+	}
+	return q.Int64(), nil
+}
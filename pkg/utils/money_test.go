@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input   string
+		scale   int
+		want    int64
+		wantErr bool
+	}{
+		{"12.5", 2, 1250, false},
+		{"-0.01", 2, -1, false},
+		{"+3", 2, 300, false},
+		{".5", 1, 5, false},
+		{"1.10", 1, 11, false},
+		{"100", 0, 100, false},
+		{"1.234", 2, 0, true},
+		{"abc", 2, 0, true},
+		{"1.2.3", 2, 0, true},
+		{"", 2, 0, true},
+		{"-", 2, 0, true},
+		{"99999999999999999999", 2, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDecimal(tt.input, tt.scale)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDecimal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDecimal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		v     int64
+		scale int
+		want  string
+	}{
+		{1250, 2, "12.50"},
+		{-1, 2, "-0.01"},
+		{5, 3, "0.005"},
+		{42, 0, "42"},
+		{math.MinInt64, 2, "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatDecimal(tt.v, tt.scale); got != tt.want {
+				t.Errorf("FormatDecimal(%d, %d) = %v, want %v", tt.v, tt.scale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundDecimal(t *testing.T) {
+	tests := []struct {
+		input string
+		mode  RoundingMode
+		want  string
+	}{
+		{"2.345", RoundHalfUp, "2.35"},
+		{"2.345", RoundHalfEven, "2.34"},
+		{"2.355", RoundHalfEven, "2.36"},
+		{"-2.345", RoundHalfUp, "-2.35"},
+		{"2.349", RoundDown, "2.34"},
+		{"2.341", RoundUp, "2.35"},
+		{"-2.341", RoundUp, "-2.35"},
+		{"2.3", RoundHalfUp, "2.30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := RoundDecimal(tt.input, 2, tt.mode)
+			if err != nil || got != tt.want {
+				t.Errorf("RoundDecimal(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	price, err := ParseMoney("19.99", "cny")
+	if err != nil {
+		t.Fatalf("ParseMoney() error = %v", err)
+	}
+	if price.Amount != 1999 || price.String() != "19.99 CNY" {
+		t.Errorf("ParseMoney() = %+v (%s)", price, price)
+	}
+
+	sum, err := price.Add(NewMoney(1, "CNY"))
+	if err != nil || sum.Decimal() != "20.00" {
+		t.Errorf("Add() = %v, %v", sum, err)
+	}
+	diff, _ := price.Sub(NewMoney(2000, "CNY"))
+	if !diff.IsNegative() || diff.Decimal() != "-0.01" {
+		t.Errorf("Sub() = %v", diff)
+	}
+
+	if _, err := price.Add(NewMoney(1, "USD")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add() error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if _, err := NewMoney(math.MaxInt64, "CNY").Add(NewMoney(1, "CNY")); !errors.Is(err, ErrAmountOverflow) {
+		t.Errorf("Add() error = %v, want %v", err, ErrAmountOverflow)
+	}
+
+	if c, _ := price.Cmp(sum); c != -1 {
+		t.Errorf("Cmp() = %d, want -1", c)
+	}
+
+	taxed, err := price.Mul("1.13", RoundHalfUp)
+	if err != nil || taxed.Decimal() != "22.59" {
+		t.Errorf("Mul() = %v, %v, want 22.59", taxed, err)
+	}
+	if _, err := price.Mul("x", RoundHalfUp); !errors.Is(err, ErrInvalidDecimal) {
+		t.Errorf("Mul() error = %v, want %v", err, ErrInvalidDecimal)
+	}
+
+	yen, _ := ParseMoney("500", "JPY")
+	if yen.Amount != 500 || yen.Decimal() != "500" {
+		t.Errorf("ParseMoney(JPY) = %+v", yen)
+	}
+	if _, err := ParseMoney("1.5", "JPY"); err == nil {
+		t.Error("ParseMoney() should reject fractional yen")
+	}
+}
+
+func TestMoneyAllocate(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		ratios []int
+		want   []int64
+	}{
+		{"even split", 1000, []int{1, 1, 1}, []int64{334, 333, 333}},
+		{"weighted", 100, []int{70, 30}, []int64{70, 30}},
+		{"zero ratio", 5, []int{1, 0, 1}, []int64{3, 0, 2}},
+		{"negative", -1000, []int{1, 1, 1}, []int64{-334, -333, -333}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := NewMoney(tt.amount, "CNY").Allocate(tt.ratios...)
+			if err != nil {
+				t.Fatalf("Allocate() error = %v", err)
+			}
+			for i, want := range tt.want {
+				if shares[i].Amount != want {
+					t.Errorf("share[%d] = %d, want %d", i, shares[i].Amount, want)
+				}
+			}
+		})
+	}
+
+	if _, err := NewMoney(1, "CNY").Allocate(0, 0); err == nil {
+		t.Error("Allocate() should reject ratios summing to zero")
+	}
+	if parts, _ := NewMoney(10, "CNY").Split(4); len(parts) != 4 || parts[0].Amount != 3 || parts[3].Amount != 2 {
+		t.Errorf("Split() = %v", parts)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	type order struct {
+		Total Money `json:"total"`
+	}
+
+	data, err := json.Marshal(order{Total: NewMoney(1999, "CNY")})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"total":{"amount":"19.99","currency":"CNY"}}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	var got order
+	if err := json.Unmarshal(data, &got); err != nil || got.Total != NewMoney(1999, "CNY") {
+		t.Errorf("Unmarshal() = %+v, %v", got, err)
+	}
+
+	for _, bad := range []string{`{"total":{"amount":"1.999","currency":"CNY"}}`, `{"total":{"amount":"1"}}`} {
+		if err := json.Unmarshal([]byte(bad), &got); err == nil {
+			t.Errorf("Unmarshal(%s) should fail", bad)
+		}
+	}
+}
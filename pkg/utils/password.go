@@ -0,0 +1,392 @@
+package utils
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!@#$%^&*()-_=+[]{}<>?,.:;"
+)
+
+// Password policy violations
+var (
+	ErrPasswordTooShort    = errors.New("password is too short")
+	ErrPasswordTooLong     = errors.New("password is too long")
+	ErrPasswordNoUpper     = errors.New("password must contain an upper-case letter")
+	ErrPasswordNoLower     = errors.New("password must contain a lower-case letter")
+	ErrPasswordNoDigit     = errors.New("password must contain a digit")
+	ErrPasswordNoSymbol    = errors.New("password must contain a symbol")
+	ErrPasswordCommon      = errors.New("password is too common")
+	ErrPasswordTooWeak     = errors.New("password is too weak")
+	ErrPasswordContainsKey = errors.New("password must not contain the user name or email")
+)
+
+// commonPasswords are frequently leaked passwords rejected regardless of
+// their character classes
+var commonPasswords = map[string]bool{
+	"123456": true, "123456789": true, "12345678": true, "password": true, "qwerty": true,
+	"qwerty123": true, "1q2w3e4r": true, "12345": true, "1234567890": true, "111111": true,
+	"000000": true, "123123": true, "abc123": true, "password1": true, "password123": true,
+	"iloveyou": true, "admin": true, "admin123": true, "welcome": true, "welcome1": true,
+	"letmein": true, "monkey": true, "dragon": true, "football": true, "baseball": true,
+	"sunshine": true, "princess": true, "qwertyuiop": true, "master": true, "shadow": true,
+	"superman": true, "trustno1": true, "passw0rd": true, "p@ssw0rd": true, "p@ssword": true,
+	"zaq12wsx": true, "1qaz2wsx": true, "asdfghjkl": true, "changeme": true, "root": true,
+	"toor": true, "woaini": true, "woaini1314": true, "5201314": true, "a123456": true,
+	"aa123456": true, "qq123456": true, "123qwe": true, "666666": true, "888888": true,
+}
+
+// keyboardRows are scanned for sequences such as "qwerty" or "asdf"
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm", lowerChars}
+
+// PasswordPolicy describes the requirements a password must meet
+type PasswordPolicy struct {
+	MinLength     int  `json:"min_length" yaml:"min_length"`
+	MaxLength     int  `json:"max_length" yaml:"max_length"`
+	RequireUpper  bool `json:"require_upper" yaml:"require_upper"`
+	RequireLower  bool `json:"require_lower" yaml:"require_lower"`
+	RequireDigit  bool `json:"require_digit" yaml:"require_digit"`
+	RequireSymbol bool `json:"require_symbol" yaml:"require_symbol"`
+	// RejectCommon rejects passwords found in the built-in leaked password list
+	RejectCommon bool `json:"reject_common" yaml:"reject_common"`
+	// MinScore is the minimum PasswordStrength score, 0-4
+	MinScore int `json:"min_score" yaml:"min_score"`
+}
+
+// DefaultPasswordPolicy returns a policy suitable for user accounts
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:    8,
+		MaxLength:    128,
+		RequireLower: true,
+		RequireDigit: true,
+		RejectCommon: true,
+		MinScore:     2,
+	}
+}
+
+// Validate checks a password against the policy and returns every violation
+// joined into one error. userInputs, such as the user name and email, must
+// not appear in the password.
+func (p PasswordPolicy) Validate(password string, userInputs ...string) error {
+	var errs []error
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		errs = append(errs, fmt.Errorf("%w: minimum %d characters", ErrPasswordTooShort, p.MinLength))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		errs = append(errs, fmt.Errorf("%w: maximum %d characters", ErrPasswordTooLong, p.MaxLength))
+	}
+
+	classes := passwordClasses(password)
+	if p.RequireUpper && !classes.upper {
+		errs = append(errs, ErrPasswordNoUpper)
+	}
+	if p.RequireLower && !classes.lower {
+		errs = append(errs, ErrPasswordNoLower)
+	}
+	if p.RequireDigit && !classes.digit {
+		errs = append(errs, ErrPasswordNoDigit)
+	}
+	if p.RequireSymbol && !classes.symbol {
+		errs = append(errs, ErrPasswordNoSymbol)
+	}
+
+	strength := EvaluatePassword(password, userInputs...)
+	if p.RejectCommon && strength.Common {
+		errs = append(errs, ErrPasswordCommon)
+	}
+	if strength.ContainsUserInput {
+		errs = append(errs, ErrPasswordContainsKey)
+	}
+	if strength.Score < p.MinScore {
+		errs = append(errs, ErrPasswordTooWeak)
+	}
+
+	return errors.Join(errs...)
+}
+
+// PasswordStrength is the result of EvaluatePassword
+type PasswordStrength struct {
+	// Score ranges from 0 (trivially guessable) to 4 (very strong)
+	Score int `json:"score"`
+	// Entropy is the estimated entropy in bits after penalties
+	Entropy float64 `json:"entropy"`
+	// Common reports whether the password is in the leaked password list
+	Common bool `json:"common"`
+	// ContainsUserInput reports whether a user input appears in the password
+	ContainsUserInput bool `json:"contains_user_input"`
+	// Suggestions explain how to make the password stronger
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// EvaluatePassword estimates password strength in the spirit of zxcvbn:
+// entropy from length and character classes, discounted for repeats,
+// keyboard/alphabet sequences, common passwords and user inputs
+func EvaluatePassword(password string, userInputs ...string) PasswordStrength {
+	var s PasswordStrength
+	lower := strings.ToLower(password)
+
+	if commonPasswords[lower] || commonPasswords[strings.TrimRight(lower, digitChars+symbolChars)] {
+		s.Common = true
+		s.Suggestions = append(s.Suggestions, "avoid common passwords")
+		return s
+	}
+
+	classes := passwordClasses(password)
+	pool := 0
+	if classes.lower {
+		pool += 26
+	}
+	if classes.upper {
+		pool += 26
+	}
+	if classes.digit {
+		pool += 10
+	}
+	if classes.symbol {
+		pool += 33
+	}
+	if classes.other {
+		pool += 100
+	}
+
+	// Characters belonging to repeats or sequences add little entropy, so
+	// only the remaining ones count at full weight
+	runes := []rune(lower)
+	weak := predictableRunes(runes)
+	effective := float64(len(runes)-weak) + float64(weak)*0.25
+	if pool > 0 {
+		s.Entropy = effective * math.Log2(float64(pool))
+	}
+	if weak > 0 {
+		s.Suggestions = append(s.Suggestions, "avoid repeated characters and sequences like abc or 123")
+	}
+
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if local, _, ok := strings.Cut(input, "@"); ok {
+			input = local
+		}
+		if len(input) >= 3 && strings.Contains(lower, input) {
+			s.ContainsUserInput = true
+			s.Entropy -= float64(utf8.RuneCountInString(input)) * math.Log2(float64(max(pool, 2)))
+			s.Suggestions = append(s.Suggestions, "do not include your name or email")
+		}
+	}
+	if s.Entropy < 0 {
+		s.Entropy = 0
+	}
+
+	switch {
+	case s.Entropy < 28:
+		s.Score = 0
+	case s.Entropy < 36:
+		s.Score = 1
+	case s.Entropy < 60:
+		s.Score = 2
+	case s.Entropy < 80:
+		s.Score = 3
+	default:
+		s.Score = 4
+	}
+
+	if s.Score < 3 {
+		if len(runes) < 12 {
+			s.Suggestions = append(s.Suggestions, "use a longer password or a passphrase")
+		}
+		if !classes.symbol && !classes.upper {
+			s.Suggestions = append(s.Suggestions, "mix in upper-case letters and symbols")
+		}
+	}
+	return s
+}
+
+// charClasses records which character classes a password uses
+type charClasses struct {
+	lower, upper, digit, symbol, other bool
+}
+
+// passwordClasses classifies the characters of a password
+func passwordClasses(password string) charClasses {
+	var c charClasses
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			c.lower = true
+		case r >= 'A' && r <= 'Z':
+			c.upper = true
+		case r >= '0' && r <= '9':
+			c.digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			c.symbol = true
+		default:
+			c.other = true
+		}
+	}
+	return c
+}
+
+// predictableRunes counts characters that repeat the previous one or
+// continue a keyboard or alphabet sequence of at least three characters
+func predictableRunes(runes []rune) int {
+	weak := make([]bool, len(runes))
+	for i := 1; i < len(runes); i++ {
+		if runes[i] == runes[i-1] {
+			weak[i] = true
+		}
+	}
+	for i := 2; i < len(runes); i++ {
+		seq := string(runes[i-2 : i+1])
+		for _, row := range keyboardRows {
+			if strings.Contains(row, seq) || strings.Contains(row, reverseASCII(seq)) {
+				weak[i-1], weak[i] = true, true
+				break
+			}
+		}
+	}
+
+	n := 0
+	for _, w := range weak {
+		if w {
+			n++
+		}
+	}
+	return n
+}
+
+// reverseASCII reverses a short ASCII string
+func reverseASCII(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+// PasswordOptions configures GeneratePassword
+type PasswordOptions struct {
+	Length int
+	Upper  bool
+	Digits bool
+	Symbol bool
+	// ExcludeAmbiguous drops look-alike characters such as 0/O and 1/l/I
+	ExcludeAmbiguous bool
+}
+
+// DefaultPasswordOptions returns options for a 16 character password using
+// every character class
+func DefaultPasswordOptions() PasswordOptions {
+	return PasswordOptions{Length: 16, Upper: true, Digits: true, Symbol: true}
+}
+
+// GeneratePassword generates a random password that contains at least one
+// character of every enabled class
+func GeneratePassword(opts PasswordOptions) (string, error) {
+	classes := []string{lowerChars}
+	if opts.Upper {
+		classes = append(classes, upperChars)
+	}
+	if opts.Digits {
+		classes = append(classes, digitChars)
+	}
+	if opts.Symbol {
+		classes = append(classes, symbolChars)
+	}
+	if opts.ExcludeAmbiguous {
+		for i, class := range classes {
+			classes[i] = strings.Map(func(r rune) rune {
+				if strings.ContainsRune("0O1lI", r) {
+					return -1
+				}
+				return r
+			}, class)
+		}
+	}
+	if opts.Length < len(classes) {
+		return "", fmt.Errorf("password length must be at least %d", len(classes))
+	}
+
+	all := strings.Join(classes, "")
+	out := make([]byte, opts.Length)
+	for i := range out {
+		set := all
+		if i < len(classes) {
+			set = classes[i]
+		}
+		c, err := randomChar(set)
+		if err != nil {
+			return "", err
+		}
+		out[i] = c
+	}
+
+	// Shuffle so the guaranteed characters are not always at the front
+	for i := len(out) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %w", err)
+		}
+		out[i], out[j.Int64()] = out[j.Int64()], out[i]
+	}
+	return string(out), nil
+}
+
+// randomChar picks a uniformly random byte of set
+func randomChar(set string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return set[n.Int64()], nil
+}
+
+// passphraseWords is a list of short, distinct words for passphrases; each
+// word adds 8 bits of entropy
+var passphraseWords = strings.Fields(`
+able acid aged also area army away baby back bake ball band bank base bath bean
+bear beat bell belt bend bird blow blue boat body bold bone book boot bowl brag
+bulk burn bush cafe cage cake calm camp card care cart case cash cast cave cell
+chef chin city clay clip club coal coat code coin cold cone cook cool copy cord
+core corn cost crab crew crop cube cure dark dash data dawn deal deck deer desk
+dial dice dish dock dome door dove drum duck dusk dust duty easy echo edge envy
+epic exit face fact fair fame farm fast fern film fire fish flag flat folk font
+fork form frog fuel gate gear gift glow glue goal gold golf gown grid grin gulf
+hail half hall harp hawk heat herb hero hike hill hint hive hold home hook hope
+horn idea inch iron isle jade jazz jeep join joke jump jury keen kelp kern kick
+kind king kite knee knot lake lamp lane lava lawn leaf lens lily lime link lion
+list loaf loft loop luck lung mail malt mask meal mild milk mint mist moon moss
+moth nail neck nest news note oath oboe odor onyx opal oval oven pace palm park
+path peak pear pine pink plum poem pond pony pool port quiz raft rain ramp reef
+rice ring road robe rock roof rope rose ruby sage sail salt sand seal ship silk
+sock soda soup star tide tile toad tree tuba twig vase vest wave wolf yarn zinc
+`)
+
+// GeneratePassphrase generates a passphrase of random words joined by sep,
+// e.g. "opal-harp-moth-bake-tide-rice"
+func GeneratePassphrase(words int, sep string) (string, error) {
+	if words <= 0 {
+		return "", errors.New("passphrase must have at least one word")
+	}
+
+	picked := make([]string, words)
+	for i := range picked {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passphraseWords))))
+		if err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %w", err)
+		}
+		picked[i] = passphraseWords[n.Int64()]
+	}
+	return strings.Join(picked, sep), nil
+}
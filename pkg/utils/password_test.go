@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEvaluatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		inputs   []string
+		minScore int
+		maxScore int
+		common   bool
+	}{
+		{"common", "password", nil, 0, 0, true},
+		{"common with suffix", "Password123!", nil, 0, 0, true},
+		{"short digits", "4821", nil, 0, 0, false},
+		{"sequence", "abcdefgh", nil, 0, 1, false},
+		{"repeats", "aaaaaaaaaaaa", nil, 0, 1, false},
+		{"mixed", "Tr0ub4dor&3", nil, 2, 4, false},
+		{"long random", "hT9#qLw2$zVb8!mX", nil, 4, 4, false},
+		{"passphrase", "opal-harp-moth-bake-tide", nil, 3, 4, false},
+		{"contains email", "alice2024!", []string{"alice@example.com"}, 0, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EvaluatePassword(tt.password, tt.inputs...)
+			if got.Score < tt.minScore || got.Score > tt.maxScore {
+				t.Errorf("EvaluatePassword(%q).Score = %d (entropy %.1f), want %d-%d", tt.password, got.Score, got.Entropy, tt.minScore, tt.maxScore)
+			}
+			if got.Common != tt.common {
+				t.Errorf("EvaluatePassword(%q).Common = %v, want %v", tt.password, got.Common, tt.common)
+			}
+			if got.Score < 3 && len(got.Suggestions) == 0 {
+				t.Errorf("EvaluatePassword(%q) should suggest improvements", tt.password)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	strict := PasswordPolicy{
+		MinLength:     10,
+		MaxLength:     20,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		RejectCommon:  true,
+		MinScore:      3,
+	}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		inputs   []string
+		wantErrs []error
+	}{
+		{"valid default", DefaultPasswordPolicy(), "correct7horse", nil, nil},
+		{"valid strict", strict, "hT9#qLw2$zVb8!", nil, nil},
+		{"too short", strict, "hT9#q", nil, []error{ErrPasswordTooShort}},
+		{"too long", strict, "hT9#qLw2$zVb8!mXhT9#qLw2", nil, []error{ErrPasswordTooLong}},
+		{"missing classes", strict, "qlwzvbmxkrtj", nil, []error{ErrPasswordNoUpper, ErrPasswordNoDigit, ErrPasswordNoSymbol}},
+		{"common", DefaultPasswordPolicy(), "password1", nil, []error{ErrPasswordCommon, ErrPasswordTooWeak}},
+		{"user input", DefaultPasswordPolicy(), "bob-smith-77x", []string{"bob-smith"}, []error{ErrPasswordContainsKey}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password, tt.inputs...)
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("Validate(%q) error = %v", tt.password, err)
+				}
+				return
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("Validate(%q) error = %v, want %v", tt.password, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	tests := []struct {
+		name string
+		opts PasswordOptions
+	}{
+		{"default", DefaultPasswordOptions()},
+		{"lower only", PasswordOptions{Length: 8}},
+		{"no ambiguous", PasswordOptions{Length: 32, Upper: true, Digits: true, ExcludeAmbiguous: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				pw, err := GeneratePassword(tt.opts)
+				if err != nil {
+					t.Fatalf("GeneratePassword() error = %v", err)
+				}
+				if len(pw) != tt.opts.Length {
+					t.Fatalf("len = %d, want %d", len(pw), tt.opts.Length)
+				}
+
+				classes := passwordClasses(pw)
+				if !classes.lower || classes.upper != tt.opts.Upper || classes.digit != tt.opts.Digits || classes.symbol != tt.opts.Symbol {
+					t.Fatalf("GeneratePassword() = %q, classes %+v do not match %+v", pw, classes, tt.opts)
+				}
+				if tt.opts.ExcludeAmbiguous && strings.ContainsAny(pw, "0O1lI") {
+					t.Fatalf("GeneratePassword() = %q, contains ambiguous characters", pw)
+				}
+			}
+		})
+	}
+
+	if _, err := GeneratePassword(PasswordOptions{Length: 2, Upper: true, Digits: true}); err == nil {
+		t.Error("GeneratePassword() should reject a length below the number of classes")
+	}
+}
+
+func TestGeneratePassphrase(t *testing.T) {
+	phrase, err := GeneratePassphrase(6, "-")
+	if err != nil {
+		t.Fatalf("GeneratePassphrase() error = %v", err)
+	}
+	words := strings.Split(phrase, "-")
+	if len(words) != 6 {
+		t.Fatalf("GeneratePassphrase() = %q, want 6 words", phrase)
+	}
+	for _, w := range words {
+		if !Contains(passphraseWords, w) {
+			t.Errorf("unexpected word %q", w)
+		}
+	}
+
+	if _, err := GeneratePassphrase(0, "-"); err == nil {
+		t.Error("GeneratePassphrase() should reject zero words")
+	}
+}
@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrPoolClosed is returned when submitting to a pool that is shutting down
+var ErrPoolClosed = errors.New("worker pool is closed")
+
+// Task is a unit of work executed by a WorkerPool
+type Task[T any] func(ctx context.Context) (T, error)
+
+// Result is the outcome of a submitted task
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// WorkerPool runs submitted tasks on a fixed number of goroutines and
+// publishes their outcomes on Results. Callers must drain Results, otherwise
+// workers block once the result buffer is full.
+type WorkerPool[T any] struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	tasks   chan Task[T]
+	results chan Result[T]
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewWorkerPool starts a pool with the given number of workers and queue
+// size; workers stop early when ctx is cancelled
+func NewWorkerPool[T any](ctx context.Context, workers, queueSize int) *WorkerPool[T] {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p := &WorkerPool[T]{
+		ctx:     ctx,
+		cancel:  cancel,
+		tasks:   make(chan Task[T], queueSize),
+		results: make(chan Result[T], queueSize),
+	}
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	go func() {
+		p.wg.Wait()
+		close(p.results)
+	}()
+	return p
+}
+
+// work executes tasks until the queue is closed or the context is cancelled
+func (p *WorkerPool[T]) work() {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		if err := p.ctx.Err(); err != nil {
+			// Drain the queue so Close does not wait for tasks nobody will run
+			p.results <- Result[T]{Err: err}
+			continue
+		}
+		value, err := task(p.ctx)
+		p.results <- Result[T]{Value: value, Err: err}
+	}
+}
+
+// Submit queues a task, blocking while the queue is full. It fails once the
+// pool is closed or when ctx is done before the task could be queued.
+func (p *WorkerPool[T]) Submit(ctx context.Context, task Task[T]) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.tasks <- task:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
+
+// Results returns the channel of task outcomes; it is closed after Close
+// once every queued task has finished
+func (p *WorkerPool[T]) Results() <-chan Result[T] {
+	return p.results
+}
+
+// Close stops accepting tasks and waits for queued ones to finish. Callers
+// must keep reading Results while Close runs.
+func (p *WorkerPool[T]) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.tasks)
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+	p.cancel()
+}
+
+// Stop cancels running tasks, fails the queued ones and waits for workers
+func (p *WorkerPool[T]) Stop() {
+	p.cancel()
+	p.Close()
+}
+
+// Parallel runs fns with at most limit running at once and returns the first
+// error. The context passed to fns is cancelled as soon as one fails.
+func Parallel(ctx context.Context, limit int, fns ...func(ctx context.Context) error) error {
+	return ForEachLimit(ctx, fns, limit, func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	})
+}
+
+// ForEachLimit calls fn for every item with at most limit calls in flight
+// and returns the first error; remaining items are skipped once ctx is
+// cancelled or a call fails. A limit <= 0 means no limit.
+func ForEachLimit[T any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) error) error {
+	if limit <= 0 || limit > len(items) {
+		limit = len(items)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	sem := make(chan struct{}, limit)
+loop:
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			fail(err)
+			break
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			fail(ctx.Err())
+			break loop
+		}
+
+		wg.Add(1)
+		go func(item T) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(ctx, item); err != nil {
+				fail(err)
+			}
+		}(item)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// MapLimit calls fn for every item with at most limit calls in flight and
+// returns the results in input order, or the first error
+func MapLimit[T, R any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	err := ForEachLimit(ctx, indexes, limit, func(ctx context.Context, i int) error {
+		r, err := fn(ctx, items[i])
+		if err != nil {
+			return err
+		}
+		results[i] = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	pool := NewWorkerPool[int](context.Background(), 3, 2)
+
+	go func() {
+		for i := 1; i <= 10; i++ {
+			n := i
+			if err := pool.Submit(context.Background(), func(context.Context) (int, error) {
+				return n * n, nil
+			}); err != nil {
+				t.Errorf("Submit() error = %v", err)
+			}
+		}
+		pool.Close()
+	}()
+
+	var got []int
+	for r := range pool.Results() {
+		if r.Err != nil {
+			t.Fatalf("task error = %v", r.Err)
+		}
+		got = append(got, r.Value)
+	}
+	sort.Ints(got)
+
+	if len(got) != 10 || got[0] != 1 || got[9] != 100 {
+		t.Errorf("results = %v, want squares of 1..10", got)
+	}
+	if err := pool.Submit(context.Background(), func(context.Context) (int, error) { return 0, nil }); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Submit() after Close error = %v, want %v", err, ErrPoolClosed)
+	}
+}
+
+func TestWorkerPoolStop(t *testing.T) {
+	pool := NewWorkerPool[int](context.Background(), 1, 4)
+
+	started := make(chan struct{})
+	_ = pool.Submit(context.Background(), func(ctx context.Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	_ = pool.Submit(context.Background(), func(context.Context) (int, error) { return 1, nil })
+	<-started
+
+	pool.Stop()
+
+	var errs int
+	for r := range pool.Results() {
+		if errors.Is(r.Err, context.Canceled) {
+			errs++
+		}
+	}
+	if errs != 2 {
+		t.Errorf("cancelled results = %d, want 2", errs)
+	}
+}
+
+func TestForEachLimit(t *testing.T) {
+	items := make([]int, 20)
+	var inFlight, peak, sum atomic.Int64
+
+	err := ForEachLimit(context.Background(), items, 4, func(ctx context.Context, _ int) error {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		inFlight.Add(-1)
+		sum.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachLimit() error = %v", err)
+	}
+	if sum.Load() != 20 {
+		t.Errorf("calls = %d, want 20", sum.Load())
+	}
+	if peak.Load() > 4 {
+		t.Errorf("peak concurrency = %d, want <= 4", peak.Load())
+	}
+}
+
+func TestForEachLimitError(t *testing.T) {
+	errBoom := errors.New("boom")
+	var calls atomic.Int64
+
+	err := ForEachLimit(context.Background(), []int{1, 2, 3, 4, 5, 6, 7, 8}, 1, func(ctx context.Context, item int) error {
+		calls.Add(1)
+		if item == 2 {
+			return errBoom
+		}
+		return nil
+	})
+	if !errors.Is(err, errBoom) {
+		t.Errorf("ForEachLimit() error = %v, want %v", err, errBoom)
+	}
+	if calls.Load() >= 8 {
+		t.Errorf("calls = %d, remaining items should be skipped", calls.Load())
+	}
+}
+
+func TestForEachLimitCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := ForEachLimit(ctx, []int{1, 2}, 1, func(context.Context, int) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ForEachLimit() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	var a, b atomic.Bool
+	err := Parallel(context.Background(), 2,
+		func(context.Context) error { a.Store(true); return nil },
+		func(context.Context) error { b.Store(true); return nil },
+	)
+	if err != nil || !a.Load() || !b.Load() {
+		t.Errorf("Parallel() error = %v, ran a=%v b=%v", err, a.Load(), b.Load())
+	}
+}
+
+func TestMapLimit(t *testing.T) {
+	got, err := MapLimit(context.Background(), []string{"a", "bb", "ccc"}, 2, func(_ context.Context, s string) (int, error) {
+		return len(s), nil
+	})
+	if err != nil {
+		t.Fatalf("MapLimit() error = %v", err)
+	}
+	want := []int{1, 2, 3}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("MapLimit()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
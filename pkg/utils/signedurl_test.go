@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignURL(t *testing.T) {
+	secret := []byte("s3cret")
+	signed, err := SignURL("https://cdn.example.com/files/report.pdf?user=42", secret, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("SignURL() error = %v", err)
+	}
+	if !strings.Contains(signed, "expires=") || !strings.Contains(signed, "signature=") {
+		t.Fatalf("SignURL() = %v, missing parameters", signed)
+	}
+	if err := VerifyURL(signed, secret); err != nil {
+		t.Errorf("VerifyURL() error = %v", err)
+	}
+
+	// Only the path and query are signed
+	u, _ := url.Parse(signed)
+	if err := VerifyURL(u.RequestURI(), secret); err != nil {
+		t.Errorf("VerifyURL() of the request URI error = %v", err)
+	}
+
+	tamper := func(fn func(q url.Values)) string {
+		u, _ := url.Parse(signed)
+		q := u.Query()
+		fn(q)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		secret  []byte
+		wantErr error
+	}{
+		{"wrong secret", signed, []byte("other"), ErrInvalidSignature},
+		{"changed parameter", tamper(func(q url.Values) { q.Set("user", "43") }), secret, ErrInvalidSignature},
+		{"extended expiry", tamper(func(q url.Values) { q.Set(URLExpiresParam, "99999999999") }), secret, ErrInvalidSignature},
+		{"missing signature", tamper(func(q url.Values) { q.Del(URLSignatureParam) }), secret, ErrInvalidSignature},
+		{"changed path", strings.Replace(signed, "report.pdf", "secret.pdf", 1), secret, ErrInvalidSignature},
+		{"unparsable", "http://%zz", secret, ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyURL(tt.url, tt.secret); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyURL() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignURLExpired(t *testing.T) {
+	secret := []byte("s3cret")
+	signed, _ := SignURL("/callback?order=1", secret, time.Now().Add(-time.Minute))
+
+	if err := VerifyURL(signed, secret); !errors.Is(err, ErrURLExpired) {
+		t.Errorf("VerifyURL() error = %v, want %v", err, ErrURLExpired)
+	}
+
+	u, _ := url.Parse(signed)
+	if err := verifySignedURL(u, secret, time.Now().Add(-time.Hour)); err != nil {
+		t.Errorf("verifySignedURL() before expiry error = %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Contains checks if a slice contains a specific item
+func Contains[T comparable](slice []T, item T) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// Map returns the result of applying fn to every item
+func Map[T, R any](items []T, fn func(T) R) []R {
+	result := make([]R, len(items))
+	for i, item := range items {
+		result[i] = fn(item)
+	}
+	return result
+}
+
+// Filter returns the items for which keep returns true
+func Filter[T any](items []T, keep func(T) bool) []T {
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		if keep(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Reduce folds items into a single value starting from initial
+func Reduce[T, R any](items []T, initial R, fn func(acc R, item T) R) R {
+	acc := initial
+	for _, item := range items {
+		acc = fn(acc, item)
+	}
+	return acc
+}
+
+// Find returns the first item matching match
+func Find[T any](items []T, match func(T) bool) (T, bool) {
+	for _, item := range items {
+		if match(item) {
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// Unique returns items without duplicates, keeping the first occurrence
+func Unique[T comparable](items []T) []T {
+	seen := make(map[T]struct{}, len(items))
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		if _, ok := seen[item]; ok {
+			continue
+		}
+		seen[item] = struct{}{}
+		result = append(result, item)
+	}
+	return result
+}
+
+// UniqueBy returns items without duplicate keys, keeping the first occurrence
+func UniqueBy[T any, K comparable](items []T, key func(T) K) []T {
+	seen := make(map[K]struct{}, len(items))
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		k := key(item)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		result = append(result, item)
+	}
+	return result
+}
+
+// Chunk splits items into slices of at most size elements; the chunks share
+// the backing array of items
+func Chunk[T any](items []T, size int) [][]T {
+	if size <= 0 {
+		return nil
+	}
+	chunks := make([][]T, 0, (len(items)+size-1)/size)
+	for size < len(items) {
+		items, chunks = items[size:], append(chunks, items[:size:size])
+	}
+	if len(items) > 0 {
+		chunks = append(chunks, items)
+	}
+	return chunks
+}
+
+// GroupBy groups items by the key returned by fn, preserving order within
+// each group
+func GroupBy[T any, K comparable](items []T, fn func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for _, item := range items {
+		k := fn(item)
+		groups[k] = append(groups[k], item)
+	}
+	return groups
+}
+
+// KeyBy indexes items by the key returned by fn; later items win on
+// duplicate keys
+func KeyBy[T any, K comparable](items []T, fn func(T) K) map[K]T {
+	result := make(map[K]T, len(items))
+	for _, item := range items {
+		result[fn(item)] = item
+	}
+	return result
+}
+
+// Keys returns the keys of a map in sorted order
+func Keys[K cmp.Ordered, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Values returns the values of a map ordered by key
+func Values[K cmp.Ordered, V any](m map[K]V) []V {
+	values := make([]V, 0, len(m))
+	for _, k := range Keys(m) {
+		values = append(values, m[k])
+	}
+	return values
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestMapFilterReduce(t *testing.T) {
+	nums := []int{1, 2, 3, 4, 5}
+
+	strs := Map(nums, strconv.Itoa)
+	if !reflect.DeepEqual(strs, []string{"1", "2", "3", "4", "5"}) {
+		t.Errorf("Map() = %v", strs)
+	}
+
+	even := Filter(nums, func(n int) bool { return n%2 == 0 })
+	if !reflect.DeepEqual(even, []int{2, 4}) {
+		t.Errorf("Filter() = %v, want [2 4]", even)
+	}
+
+	sum := Reduce(nums, 0, func(acc, n int) int { return acc + n })
+	if sum != 15 {
+		t.Errorf("Reduce() = %v, want 15", sum)
+	}
+
+	if v, ok := Find(nums, func(n int) bool { return n > 3 }); !ok || v != 4 {
+		t.Errorf("Find() = %v, %v, want 4, true", v, ok)
+	}
+	if _, ok := Find(nums, func(n int) bool { return n > 10 }); ok {
+		t.Error("Find() should report no match")
+	}
+}
+
+func TestUnique(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		want  []string
+	}{
+		{"duplicates", []string{"a", "b", "a", "c", "b"}, []string{"a", "b", "c"}},
+		{"no duplicates", []string{"x", "y"}, []string{"x", "y"}},
+		{"empty", []string{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unique(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unique() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	type user struct {
+		ID   int
+		Name string
+	}
+	users := []user{{1, "a"}, {2, "b"}, {1, "c"}}
+	if got := UniqueBy(users, func(u user) int { return u.ID }); len(got) != 2 || got[1].Name != "b" {
+		t.Errorf("UniqueBy() = %v", got)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []int
+		size  int
+		want  [][]int
+	}{
+		{"even", []int{1, 2, 3, 4}, 2, [][]int{{1, 2}, {3, 4}}},
+		{"remainder", []int{1, 2, 3, 4, 5}, 2, [][]int{{1, 2}, {3, 4}, {5}}},
+		{"larger than input", []int{1, 2}, 5, [][]int{{1, 2}}},
+		{"empty", []int{}, 3, [][]int{}},
+		{"invalid size", []int{1}, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Chunk(tt.input, tt.size); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Chunk() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Appending to a chunk must not overwrite the next one
+	chunks := Chunk([]int{1, 2, 3, 4}, 2)
+	_ = append(chunks[0], 99)
+	if chunks[1][0] != 3 {
+		t.Errorf("append to chunk overwrote the next chunk: %v", chunks)
+	}
+}
+
+func TestGroupByKeyBy(t *testing.T) {
+	words := []string{"apple", "avocado", "banana", "blueberry", "cherry"}
+
+	groups := GroupBy(words, func(s string) byte { return s[0] })
+	if !reflect.DeepEqual(groups['a'], []string{"apple", "avocado"}) || len(groups) != 3 {
+		t.Errorf("GroupBy() = %v", groups)
+	}
+
+	byLen := KeyBy(words, func(s string) int { return len(s) })
+	if byLen[6] != "cherry" {
+		t.Errorf("KeyBy()[6] = %v, want cherry", byLen[6])
+	}
+}
+
+func TestKeysValues(t *testing.T) {
+	m := map[string]int{"b": 2, "a": 1, "c": 3}
+
+	if got := Keys(m); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("Keys() = %v, want [a b c]", got)
+	}
+	if got := Values(m); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("Values() = %v, want [1 2 3]", got)
+	}
+}
+
+func TestContainsGeneric(t *testing.T) {
+	if !Contains([]int{1, 2, 3}, 2) {
+		t.Error("Contains() should find 2")
+	}
+	if Contains([]int{1, 2, 3}, 4) {
+		t.Error("Contains() should not find 4")
+	}
+}
@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	snowflakeWorkerBits   = 10
+	snowflakeSequenceBits = 12
+
+	// MaxSnowflakeWorkerID is the largest worker ID a generator accepts
+	MaxSnowflakeWorkerID = 1<<snowflakeWorkerBits - 1
+	maxSnowflakeSequence = 1<<snowflakeSequenceBits - 1
+
+	// DefaultSnowflakeWorkerEnv is the environment variable read for the worker ID
+	DefaultSnowflakeWorkerEnv = "WORKER_ID"
+	// DefaultSnowflakeMaxBackwardWait is how long a generator waits for a clock
+	// that moved backwards to catch up before failing
+	DefaultSnowflakeMaxBackwardWait = 5 * time.Millisecond
+)
+
+// DefaultSnowflakeEpoch is the default custom epoch, 2024-01-01 UTC, which
+// leaves the 41-bit timestamp room until 2093
+var DefaultSnowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ErrClockMovedBackwards is returned when the system clock moved back further
+// than MaxBackwardWait since the last generated ID
+var ErrClockMovedBackwards = errors.New("snowflake: clock moved backwards")
+
+// SnowflakeConfig configures a snowflake generator
+type SnowflakeConfig struct {
+	// Epoch is the custom epoch timestamps are counted from
+	Epoch time.Time `json:"epoch" yaml:"epoch"`
+	// WorkerID identifies this process, 0-1023. When negative it is read from
+	// WorkerEnv, falling back to the low bits of the first private IPv4 address.
+	WorkerID int64 `json:"worker_id" yaml:"worker_id"`
+	// WorkerEnv is the environment variable holding the worker ID
+	WorkerEnv string `json:"worker_env" yaml:"worker_env"`
+	// MaxBackwardWait bounds how long to wait out a clock rollback
+	MaxBackwardWait time.Duration `json:"max_backward_wait" yaml:"max_backward_wait"`
+}
+
+// DefaultSnowflakeConfig returns a configuration that resolves the worker ID
+// from the environment or the host IP
+func DefaultSnowflakeConfig() SnowflakeConfig {
+	return SnowflakeConfig{
+		Epoch:           DefaultSnowflakeEpoch,
+		WorkerID:        -1,
+		WorkerEnv:       DefaultSnowflakeWorkerEnv,
+		MaxBackwardWait: DefaultSnowflakeMaxBackwardWait,
+	}
+}
+
+// Snowflake generates sortable 63-bit IDs made of a 41-bit millisecond
+// timestamp, a 10-bit worker ID and a 12-bit sequence
+type Snowflake struct {
+	mu       sync.Mutex
+	epoch    int64
+	workerID int64
+	maxWait  time.Duration
+	lastMS   int64
+	sequence int64
+	now      func() time.Time
+}
+
+// NewSnowflake creates a snowflake generator
+func NewSnowflake(cfg SnowflakeConfig) (*Snowflake, error) {
+	if cfg.Epoch.IsZero() {
+		cfg.Epoch = DefaultSnowflakeEpoch
+	}
+	if cfg.Epoch.After(time.Now()) {
+		return nil, fmt.Errorf("snowflake epoch %s is in the future", cfg.Epoch)
+	}
+	if cfg.MaxBackwardWait <= 0 {
+		cfg.MaxBackwardWait = DefaultSnowflakeMaxBackwardWait
+	}
+
+	workerID := cfg.WorkerID
+	if workerID < 0 {
+		var err error
+		if workerID, err = resolveWorkerID(cfg.WorkerEnv); err != nil {
+			return nil, err
+		}
+	}
+	if workerID > MaxSnowflakeWorkerID {
+		return nil, fmt.Errorf("snowflake worker id %d out of range 0-%d", workerID, MaxSnowflakeWorkerID)
+	}
+
+	return &Snowflake{
+		epoch:    cfg.Epoch.UnixMilli(),
+		workerID: workerID,
+		maxWait:  cfg.MaxBackwardWait,
+		now:      time.Now,
+	}, nil
+}
+
+// resolveWorkerID reads the worker ID from the environment or derives it from
+// the host's private IPv4 address
+func resolveWorkerID(env string) (int64, error) {
+	if env == "" {
+		env = DefaultSnowflakeWorkerEnv
+	}
+	if v := os.Getenv(env); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 0 {
+			return 0, fmt.Errorf("invalid %s %q", env, v)
+		}
+		return id, nil
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list interface addresses: %w", err)
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ip := ipNet.IP.To4(); ip != nil && ip.IsPrivate() {
+			return (int64(ip[2])<<8 | int64(ip[3])) & MaxSnowflakeWorkerID, nil
+		}
+	}
+	return 0, fmt.Errorf("snowflake worker id not set: define %s or use a private IPv4 address", env)
+}
+
+// WorkerID returns the worker ID embedded in generated IDs
+func (s *Snowflake) WorkerID() int64 {
+	return s.workerID
+}
+
+// NextID returns the next ID
+func (s *Snowflake) NextID() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := s.now().UnixMilli()
+	if ms < s.lastMS {
+		// Small rollbacks (e.g. an NTP adjustment) are waited out; larger ones
+		// would risk duplicates, so they fail.
+		behind := time.Duration(s.lastMS-ms) * time.Millisecond
+		if behind > s.maxWait {
+			return 0, fmt.Errorf("%w by %s", ErrClockMovedBackwards, behind)
+		}
+		time.Sleep(behind)
+		ms = s.waitUntil(s.lastMS)
+	}
+
+	if ms == s.lastMS {
+		s.sequence = (s.sequence + 1) & maxSnowflakeSequence
+		if s.sequence == 0 {
+			ms = s.waitUntil(s.lastMS + 1)
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.lastMS = ms
+
+	elapsed := ms - s.epoch
+	return elapsed<<(snowflakeWorkerBits+snowflakeSequenceBits) | s.workerID<<snowflakeSequenceBits | s.sequence, nil
+}
+
+// waitUntil spins until the clock reaches the given millisecond
+func (s *Snowflake) waitUntil(target int64) int64 {
+	ms := s.now().UnixMilli()
+	for ms < target {
+		time.Sleep(100 * time.Microsecond)
+		ms = s.now().UnixMilli()
+	}
+	return ms
+}
+
+// NextString returns the next ID in decimal form, e.g. for JSON APIs where
+// int64 values lose precision in JavaScript clients
+func (s *Snowflake) NextString() (string, error) {
+	id, err := s.NextID()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// SnowflakeParts is a decoded snowflake ID
+type SnowflakeParts struct {
+	Time     time.Time
+	WorkerID int64
+	Sequence int64
+}
+
+// Parse decodes an ID produced by this generator
+func (s *Snowflake) Parse(id int64) SnowflakeParts {
+	return ParseSnowflake(id, time.UnixMilli(s.epoch))
+}
+
+// ParseSnowflake decodes an ID generated with the given epoch
+func ParseSnowflake(id int64, epoch time.Time) SnowflakeParts {
+	ms := id >> (snowflakeWorkerBits + snowflakeSequenceBits)
+	return SnowflakeParts{
+		Time:     time.UnixMilli(epoch.UnixMilli() + ms),
+		WorkerID: (id >> snowflakeSequenceBits) & MaxSnowflakeWorkerID,
+		Sequence: id & maxSnowflakeSequence,
+	}
+}
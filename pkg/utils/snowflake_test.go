@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewSnowflake(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     SnowflakeConfig
+		env     string
+		want    int64
+		wantErr bool
+	}{
+		{"explicit worker", SnowflakeConfig{WorkerID: 7}, "", 7, false},
+		{"worker from env", SnowflakeConfig{WorkerID: -1}, "42", 42, false},
+		{"worker out of range", SnowflakeConfig{WorkerID: 1024}, "", 0, true},
+		{"invalid env", SnowflakeConfig{WorkerID: -1}, "abc", 0, true},
+		{"future epoch", SnowflakeConfig{Epoch: time.Now().Add(time.Hour)}, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DefaultSnowflakeWorkerEnv, tt.env)
+
+			sf, err := NewSnowflake(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSnowflake() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && sf.WorkerID() != tt.want {
+				t.Errorf("WorkerID() = %v, want %v", sf.WorkerID(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSnowflakeNextID(t *testing.T) {
+	sf, err := NewSnowflake(SnowflakeConfig{WorkerID: 3})
+	if err != nil {
+		t.Fatalf("NewSnowflake() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	seen := make(map[int64]bool)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prev := int64(-1)
+			for i := 0; i < 2000; i++ {
+				id, err := sf.NextID()
+				if err != nil {
+					t.Errorf("NextID() error = %v", err)
+					return
+				}
+				if id <= prev {
+					t.Errorf("NextID() = %v, not greater than %v", id, prev)
+				}
+				prev = id
+
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("NextID() returned duplicate %v", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	id, _ := sf.NextID()
+	parts := sf.Parse(id)
+	if parts.WorkerID != 3 {
+		t.Errorf("Parse().WorkerID = %v, want 3", parts.WorkerID)
+	}
+	if d := time.Since(parts.Time); d < 0 || d > time.Second {
+		t.Errorf("Parse().Time = %v, want around now", parts.Time)
+	}
+}
+
+func TestSnowflakeClockRollback(t *testing.T) {
+	sf, err := NewSnowflake(SnowflakeConfig{WorkerID: 1, MaxBackwardWait: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewSnowflake() error = %v", err)
+	}
+
+	base := time.Now()
+	sf.now = func() time.Time { return base }
+	first, err := sf.NextID()
+	if err != nil {
+		t.Fatalf("NextID() error = %v", err)
+	}
+
+	sf.now = func() time.Time { return base.Add(-time.Second) }
+	if _, err := sf.NextID(); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Errorf("NextID() error = %v, want %v", err, ErrClockMovedBackwards)
+	}
+
+	// A rollback within MaxBackwardWait is waited out
+	calls := 0
+	sf.now = func() time.Time {
+		calls++
+		if calls == 1 {
+			return base.Add(-5 * time.Millisecond)
+		}
+		return base.Add(time.Millisecond)
+	}
+	next, err := sf.NextID()
+	if err != nil {
+		t.Fatalf("NextID() error = %v", err)
+	}
+	if next <= first {
+		t.Errorf("NextID() = %v, not greater than %v", next, first)
+	}
+}
+
+func TestSnowflakeNextString(t *testing.T) {
+	sf, _ := NewSnowflake(SnowflakeConfig{WorkerID: 0})
+	id, err := sf.NextString()
+	if err != nil {
+		t.Fatalf("NextString() error = %v", err)
+	}
+	if id == "" || id[0] == '-' {
+		t.Errorf("NextString() = %v, want a positive decimal", id)
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // IsEmpty checks if a string is empty or contains only whitespace
@@ -46,20 +47,116 @@ func ToSnakeCase(s string) string {
 	return result.String()
 }
 
-// Truncate truncates a string to a maximum length
+// Truncate truncates a string to at most maxLength characters, ending it
+// with "..." when shortened. Multibyte characters are never split; limits
+// below 3 leave no room for content and yield only dots.
 func Truncate(s string, maxLength int) string {
-	if len(s) <= maxLength {
+	if maxLength <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= maxLength {
 		return s
 	}
-	return s[:maxLength-3] + "..."
+	if maxLength <= 3 {
+		return strings.Repeat(".", maxLength)
+	}
+	runes := []rune(s)
+	return string(runes[:maxLength-3]) + "..."
 }
 
-// Contains checks if a slice contains a specific string
-func Contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
+// TruncateWidth truncates a string to at most width terminal columns, counting
+// CJK and other wide characters as two columns, and appends tail (e.g. "…")
+// when shortened. The tail counts towards the width.
+func TruncateWidth(s string, width int, tail string) string {
+	if StringWidth(s) <= width {
+		return s
+	}
+	budget := width - StringWidth(tail)
+	if budget < 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := RuneWidth(r)
+		if used+w > budget {
+			break
 		}
+		b.WriteRune(r)
+		used += w
+	}
+	return b.String() + tail
+}
+
+// StringWidth returns the number of terminal columns a string occupies
+func StringWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += RuneWidth(r)
+	}
+	return width
+}
+
+// wideRanges are the East Asian wide and fullwidth code point ranges
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F}, {0x2E80, 0x303E}, {0x3041, 0x33FF}, {0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF}, {0xA000, 0xA4CF}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF},
+	{0xFE30, 0xFE4F}, {0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x1F300, 0x1F64F},
+	{0x1F900, 0x1F9FF}, {0x20000, 0x3FFFD},
+}
+
+// RuneWidth returns the number of terminal columns of a rune: 0 for
+// combining marks and control characters, 2 for wide characters, 1 otherwise
+func RuneWidth(r rune) int {
+	if r == 0 || unicode.IsControl(r) || unicode.In(r, unicode.Mn, unicode.Me) || r == 0x200B {
+		return 0
+	}
+	for _, rng := range wideRanges {
+		if r >= rng[0] && r <= rng[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// PadLeft pads s on the left with pad until it is width columns wide
+func PadLeft(s string, width int, pad rune) string {
+	return padding(s, width, pad) + s
+}
+
+// PadRight pads s on the right with pad until it is width columns wide
+func PadRight(s string, width int, pad rune) string {
+	return s + padding(s, width, pad)
+}
+
+// padding returns the pad runes needed to bring s to width columns
+func padding(s string, width int, pad rune) string {
+	padWidth := RuneWidth(pad)
+	missing := width - StringWidth(s)
+	if padWidth == 0 || missing < padWidth {
+		return ""
+	}
+	return strings.Repeat(string(pad), missing/padWidth)
+}
+
+// Reverse reverses a string by character, keeping combining marks attached
+// to the character they modify
+func Reverse(s string) string {
+	runes := []rune(s)
+	// Group each base rune with the combining marks that follow it
+	var clusters [][]rune
+	for _, r := range runes {
+		if len(clusters) > 0 && unicode.In(r, unicode.Mn, unicode.Me) {
+			clusters[len(clusters)-1] = append(clusters[len(clusters)-1], r)
+			continue
+		}
+		clusters = append(clusters, []rune{r})
+	}
+
+	out := make([]rune, 0, len(runes))
+	for i := len(clusters) - 1; i >= 0; i-- {
+		out = append(out, clusters[i]...)
 	}
-	return false
+	return string(out)
 }
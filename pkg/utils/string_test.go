@@ -86,6 +86,12 @@ func TestTruncate(t *testing.T) {
 		{"needs truncation", "hello world", 8, "hello..."},
 		{"very short limit", "hello", 3, "..."},
 		{"empty string", "", 5, ""},
+		{"limit below ellipsis", "hello", 2, ".."},
+		{"zero limit", "hello", 0, ""},
+		{"negative limit", "hello", -1, ""},
+		{"multibyte fits", "你好世界", 4, "你好世界"},
+		{"multibyte truncated", "你好世界和平", 5, "你好..."},
+		{"emoji", "👍👍👍👍👍", 4, "👍..."},
 	}
 
 	for _, tt := range tests {
@@ -124,4 +130,96 @@ func TestContains(t *testing.T) {
 	if Contains(emptySlice, "test") {
 		t.Error("Contains() should return false for empty slice")
 	}
-}
\ No newline at end of file
+}
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		tail  string
+		want  string
+	}{
+		{"ascii fits", "hello", 5, "…", "hello"},
+		{"ascii truncated", "hello world", 6, "…", "hello…"},
+		{"cjk truncated", "你好世界", 5, "…", "你好…"},
+		{"cjk even width", "你好世界", 6, "", "你好世"},
+		{"mixed", "ab你好", 4, "", "ab你"},
+		{"tail too wide", "hello", 1, "...", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TruncateWidth(tt.input, tt.width, tt.tail); got != tt.want {
+				t.Errorf("TruncateWidth() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"hello", 5},
+		{"你好", 4},
+		{"ｈｉ", 4},
+		{"e\u0301", 1},
+		{"한국어", 6},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := StringWidth(tt.input); got != tt.want {
+				t.Errorf("StringWidth(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPad(t *testing.T) {
+	tests := []struct {
+		name  string
+		fn    func(string, int, rune) string
+		input string
+		width int
+		pad   rune
+		want  string
+	}{
+		{"left", PadLeft, "42", 5, '0', "00042"},
+		{"right", PadRight, "ab", 4, '.', "ab.."},
+		{"cjk right", PadRight, "名字", 6, ' ', "名字  "},
+		{"already wide", PadLeft, "hello", 3, ' ', "hello"},
+		{"wide pad", PadLeft, "a", 5, '中', "中中a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(tt.input, tt.width, tt.pad); got != tt.want {
+				t.Errorf("pad(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReverse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"hello", "olleh"},
+		{"你好", "好你"},
+		{"cafe\u0301!", "!e\u0301fac"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Reverse(tt.input); got != tt.want {
+				t.Errorf("Reverse(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	e164Pattern     = regexp.MustCompile(`^\+[1-9]\d{6,14}$`)
+	cnMobilePattern = regexp.MustCompile(`^(?:\+?86)?1[3-9]\d{9}$`)
+	emailDomain     = regexp.MustCompile(`^(?i)[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)+$`)
+)
+
+// IsEmail checks if a string is a plain email address such as
+// user@example.com; display names ("Bob <bob@example.com>") are rejected
+func IsEmail(s string) bool {
+	if len(s) > 254 {
+		return false
+	}
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || addr.Name != "" {
+		return false
+	}
+	local, domain, ok := strings.Cut(s, "@")
+	return ok && len(local) <= 64 && emailDomain.MatchString(domain)
+}
+
+// IsPhoneE164 checks if a string is an E.164 phone number, e.g. +14155552671
+func IsPhoneE164(s string) bool {
+	return e164Pattern.MatchString(s)
+}
+
+// IsCNMobile checks if a string is a mainland China mobile number, with an
+// optional 86 or +86 prefix
+func IsCNMobile(s string) bool {
+	return cnMobilePattern.MatchString(s)
+}
+
+// IsURL checks if a string is an absolute http or https URL with a host
+func IsURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.Hostname() != ""
+}
+
+// IsIDCard checks if a string is a valid 18-digit PRC resident identity card
+// number, including its birth date and ISO 7064 MOD 11-2 check digit
+func IsIDCard(s string) bool {
+	if len(s) != 18 {
+		return false
+	}
+
+	weights := [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i := 0; i < 17; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+		sum += int(s[i]-'0') * weights[i]
+	}
+	if s[0] == '0' {
+		return false
+	}
+
+	birth, err := time.Parse("20060102", s[6:14])
+	if err != nil || birth.After(time.Now()) || birth.Year() < 1900 {
+		return false
+	}
+
+	check := "10X98765432"[sum%11]
+	last := s[17]
+	if last == 'x' {
+		last = 'X'
+	}
+	return last == check
+}
+
+// IsIPv4 checks if a string is an IPv4 address in dotted decimal form
+func IsIPv4(s string) bool {
+	addr, err := netip.ParseAddr(s)
+	return err == nil && addr.Is4()
+}
+
+// IsIPv6 checks if a string is an IPv6 address, including IPv4-mapped forms
+func IsIPv6(s string) bool {
+	addr, err := netip.ParseAddr(s)
+	return err == nil && addr.Is6()
+}
+
+// IsIP checks if a string is an IPv4 or IPv6 address
+func IsIP(s string) bool {
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
@@ -0,0 +1,76 @@
+package utils
+
+import "testing"
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		name  string
+		fn    func(string) bool
+		valid []string
+		bad   []string
+	}{
+		{
+			name:  "IsEmail",
+			fn:    IsEmail,
+			valid: []string{"user@example.com", "first.last+tag@sub.example.co", "a_b@x-y.io"},
+			bad:   []string{"", "user", "user@", "@example.com", "user@localhost", "Bob <bob@example.com>", "user@-example.com", "a b@example.com"},
+		},
+		{
+			name:  "IsPhoneE164",
+			fn:    IsPhoneE164,
+			valid: []string{"+14155552671", "+8613800138000", "+442071838750"},
+			bad:   []string{"14155552671", "+0123456789", "+1", "+1415555267123456", "+1 415 555 2671"},
+		},
+		{
+			name:  "IsCNMobile",
+			fn:    IsCNMobile,
+			valid: []string{"13800138000", "19912345678", "+8613800138000", "8615012345678"},
+			bad:   []string{"12800138000", "1380013800", "138001380000", "+8512345678901"},
+		},
+		{
+			name:  "IsURL",
+			fn:    IsURL,
+			valid: []string{"https://example.com", "http://localhost:8080/path?q=1", "https://[::1]/"},
+			bad:   []string{"", "example.com", "ftp://example.com", "https://", "/relative/path", "http://:80"},
+		},
+		{
+			name:  "IsIDCard",
+			fn:    IsIDCard,
+			valid: []string{"11010519491231002X", "11010519491231002x", "440524198001010013"},
+			bad:   []string{"110105194912310021", "11010519491332002X", "01010519491231002X", "1101051949123100", "11010521991231002X"},
+		},
+		{
+			name:  "IsIPv4",
+			fn:    IsIPv4,
+			valid: []string{"192.168.1.1", "0.0.0.0", "255.255.255.255"},
+			bad:   []string{"256.1.1.1", "1.2.3", "::1", "01.2.3.4", ""},
+		},
+		{
+			name:  "IsIPv6",
+			fn:    IsIPv6,
+			valid: []string{"::1", "2001:db8::1", "fe80::1%eth0", "::ffff:192.168.1.1"},
+			bad:   []string{"192.168.1.1", "2001:db8::g", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, s := range tt.valid {
+				if !tt.fn(s) {
+					t.Errorf("%s(%q) = false, want true", tt.name, s)
+				}
+			}
+			for _, s := range tt.bad {
+				if tt.fn(s) {
+					t.Errorf("%s(%q) = true, want false", tt.name, s)
+				}
+			}
+		})
+	}
+}
+
+func TestIsIP(t *testing.T) {
+	if !IsIP("10.0.0.1") || !IsIP("::1") || IsIP("localhost") {
+		t.Error("IsIP() should accept IPv4 and IPv6 addresses only")
+	}
+}
@@ -0,0 +1,109 @@
+package validator
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+
+	"mora/pkg/utils"
+)
+
+// rule is a custom rule with its messages
+type rule struct {
+	tag      string
+	fn       validator.Func
+	messages map[string]string
+}
+
+// rules are registered on every Validator
+var rules = []rule{
+	{"phone", isPhone, map[string]string{
+		"en": "{0} must be a valid phone number",
+		"zh": "{0}必须是有效的手机号码",
+	}},
+	{"mobile", isMobile, map[string]string{
+		"en": "{0} must be a valid mobile number",
+		"zh": "{0}必须是有效的手机号码",
+	}},
+	{"idcard", isIDCard, map[string]string{
+		"en": "{0} must be a valid ID card number",
+		"zh": "{0}必须是有效的身份证号码",
+	}},
+	{"money", isMoney, map[string]string{
+		"en": "{0} must be a non-negative amount with at most 2 decimal places",
+		"zh": "{0}必须是非负金额且最多两位小数",
+	}},
+	{"enum", isEnum, map[string]string{
+		"en": "{0} must be one of the allowed values",
+		"zh": "{0}必须是允许的值之一",
+	}},
+}
+
+// Enum is implemented by enumerated types checked by the "enum" rule, e.g.
+// func (s Status) IsValid() bool { return s == StatusActive || s == StatusDisabled }
+type Enum interface {
+	IsValid() bool
+}
+
+// money matches non-negative amounts with at most two decimal places
+var money = regexp.MustCompile(`^\d+(\.\d{1,2})?$`)
+
+// isPhone accepts mainland China mobile numbers and E.164 numbers
+func isPhone(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	return utils.IsCNMobile(s) || utils.IsPhoneE164(s)
+}
+
+// isMobile accepts mainland China mobile numbers only
+func isMobile(fl validator.FieldLevel) bool {
+	return utils.IsCNMobile(fl.Field().String())
+}
+
+// isIDCard accepts 18-digit mainland China resident ID numbers with a valid
+// birth date and checksum
+func isIDCard(fl validator.FieldLevel) bool {
+	return utils.IsIDCard(fl.Field().String())
+}
+
+// isMoney accepts non-negative amounts with at most two decimal places, as
+// strings or numbers
+func isMoney(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	switch field.Kind() {
+	case reflect.String:
+		return money.MatchString(field.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int() >= 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Float32, reflect.Float64:
+		f := field.Float()
+		return f >= 0 && money.MatchString(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return false
+}
+
+// moneyValue validates a utils.Money as its minor units, so that "gt=0" and
+// "money" apply to it
+func moneyValue(field reflect.Value) interface{} {
+	return field.Interface().(utils.Money).Amount
+}
+
+// isEnum accepts values whose type implements Enum and reports them valid;
+// values of any other type fail
+func isEnum(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if field.CanInterface() {
+		if e, ok := field.Interface().(Enum); ok {
+			return e.IsValid()
+		}
+	}
+	if field.CanAddr() && field.Addr().CanInterface() {
+		if e, ok := field.Addr().Interface().(Enum); ok {
+			return e.IsValid()
+		}
+	}
+	return false
+}
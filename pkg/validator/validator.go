@@ -0,0 +1,247 @@
+// Package validator validates structs with go-playground/validator, adds
+// rules for phone numbers, Chinese ID cards, money amounts and enums, and reports
+// violations as translated field errors that render as a 400 envelope.
+package validator
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entrans "github.com/go-playground/validator/v10/translations/en"
+	zhtrans "github.com/go-playground/validator/v10/translations/zh"
+
+	"mora/pkg/errors"
+	"mora/pkg/utils"
+)
+
+// Config configures a Validator
+type Config struct {
+	// TagName is the struct tag holding rules
+	TagName string `json:"tag_name" yaml:"tag_name" env:"TAG_NAME"`
+	// DefaultLocale is used when the context carries no supported locale
+	DefaultLocale string `json:"default_locale" yaml:"default_locale" env:"DEFAULT_LOCALE"`
+}
+
+// DefaultConfig returns default validator configuration
+func DefaultConfig() Config {
+	return Config{
+		TagName:       "validate",
+		DefaultLocale: "en",
+	}
+}
+
+// FieldError is one violated rule
+type FieldError struct {
+	// Field is the JSON path of the field, e.g. "address.city" or "items[0].sku"
+	Field   string `json:"field" example:"email"`
+	Rule    string `json:"rule" example:"email"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message" example:"email must be a valid email address"`
+}
+
+// Error lists the violations of a validated value. It unwraps to a bad
+// request error, and the envelope carries the field errors as data.
+type Error struct {
+	Fields []FieldError
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap makes the error a 400 for pkg/errors and pkg/response
+func (e *Error) Unwrap() error {
+	return errors.ErrBadRequest.WithMessage("validation failed")
+}
+
+// ErrorData returns the field errors for the response envelope
+func (e *Error) ErrorData() any {
+	return map[string]any{"errors": e.Fields}
+}
+
+// Validator validates values and translates their violations
+type Validator struct {
+	validate      *validator.Validate
+	uni           *ut.UniversalTranslator
+	defaultLocale string
+}
+
+// New creates a validator with the built-in and custom rules and English
+// and Chinese messages
+func New(cfg Config) (*Validator, error) {
+	defaults := DefaultConfig()
+	if cfg.TagName == "" {
+		cfg.TagName = defaults.TagName
+	}
+	if cfg.DefaultLocale == "" {
+		cfg.DefaultLocale = defaults.DefaultLocale
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.SetTagName(cfg.TagName)
+	validate.RegisterTagNameFunc(jsonName)
+	validate.RegisterCustomTypeFunc(moneyValue, utils.Money{})
+
+	enLocale := en.New()
+	v := &Validator{
+		validate:      validate,
+		uni:           ut.New(enLocale, enLocale, zh.New()),
+		defaultLocale: cfg.DefaultLocale,
+	}
+	if _, ok := v.uni.GetTranslator(cfg.DefaultLocale); !ok {
+		return nil, fmt.Errorf("unsupported default locale %q", cfg.DefaultLocale)
+	}
+	enTrans, _ := v.uni.GetTranslator("en")
+	if err := entrans.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		return nil, fmt.Errorf("failed to register en translations: %w", err)
+	}
+	zhTrans, _ := v.uni.GetTranslator("zh")
+	if err := zhtrans.RegisterDefaultTranslations(validate, zhTrans); err != nil {
+		return nil, fmt.Errorf("failed to register zh translations: %w", err)
+	}
+	for _, r := range rules {
+		if err := v.RegisterRule(r.tag, r.fn, r.messages); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+var defaultValidator = sync.OnceValue(func() *Validator {
+	v, err := New(DefaultConfig())
+	if err != nil {
+		// Only reachable if the built-in translations are broken
+		panic(err)
+	}
+	return v
+})
+
+// Default returns the shared validator with the default configuration
+func Default() *Validator {
+	return defaultValidator()
+}
+
+// RegisterRule adds a rule; messages maps locales to a message where {0}
+// is the field and {1} the rule parameter, e.g.
+// {"en": "{0} must be a valid SKU", "zh": "{0}必须是有效的SKU"}
+func (v *Validator) RegisterRule(tag string, fn validator.Func, messages map[string]string) error {
+	if err := v.validate.RegisterValidation(tag, fn); err != nil {
+		return fmt.Errorf("failed to register rule %s: %w", tag, err)
+	}
+	for locale, message := range messages {
+		trans, ok := v.uni.GetTranslator(locale)
+		if !ok {
+			return fmt.Errorf("unsupported locale %q for rule %s", locale, tag)
+		}
+		err := v.validate.RegisterTranslation(tag, trans,
+			func(ut ut.Translator) error { return ut.Add(tag, message, true) },
+			func(ut ut.Translator, fe validator.FieldError) string {
+				msg, _ := ut.T(tag, fe.Field(), fe.Param())
+				return msg
+			})
+		if err != nil {
+			return fmt.Errorf("failed to register %s message for rule %s: %w", locale, tag, err)
+		}
+	}
+	return nil
+}
+
+// Struct validates s, translating violations to the locale stored in ctx
+func (v *Validator) Struct(ctx context.Context, s any) error {
+	return v.convert(ctx, v.validate.StructCtx(ctx, s))
+}
+
+// Var validates a single value against tag, e.g. Var(ctx, email, "required,email")
+func (v *Validator) Var(ctx context.Context, field any, tag string) error {
+	return v.convert(ctx, v.validate.VarCtx(ctx, field, tag))
+}
+
+// convert turns validator errors into an *Error
+func (v *Validator) convert(ctx context.Context, err error) error {
+	var verrs validator.ValidationErrors
+	if !stderrors.As(err, &verrs) {
+		return err
+	}
+	trans := v.translator(LocaleFromContext(ctx))
+	fields := make([]FieldError, len(verrs))
+	for i, fe := range verrs {
+		fields[i] = FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Translate(trans),
+		}
+	}
+	return &Error{Fields: fields}
+}
+
+// translator returns the translator of locale, or of the default locale
+func (v *Validator) translator(locale string) ut.Translator {
+	if locale != "" {
+		if trans, ok := v.uni.GetTranslator(locale); ok {
+			return trans
+		}
+	}
+	trans, _ := v.uni.GetTranslator(v.defaultLocale)
+	return trans
+}
+
+// fieldPath is the field's namespace without the root struct name
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, rest, ok := strings.Cut(ns, "."); ok {
+		return rest
+	}
+	return ns
+}
+
+// jsonName names fields by their json tag so errors match the request body
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+type localeKey struct{}
+
+// WithLocale returns a context whose validation messages use locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the locale stored by WithLocale
+func LocaleFromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// MatchLocale picks the supported locale ("en" or "zh") preferred by an
+// Accept-Language header, or "" when none matches
+func MatchLocale(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if base == "en" || base == "zh" {
+			return base
+		}
+	}
+	return ""
+}
@@ -0,0 +1,234 @@
+package validator
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+
+	"mora/pkg/errors"
+	"mora/pkg/response"
+	"mora/pkg/utils"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type signup struct {
+	Email   string  `json:"email" validate:"required,email"`
+	Phone   string  `json:"phone" validate:"omitempty,phone"`
+	IDCard  string  `json:"id_card" validate:"omitempty,idcard"`
+	Amount  string  `json:"amount" validate:"omitempty,money"`
+	Age     int     `json:"age" validate:"gte=18"`
+	Address address `json:"address"`
+}
+
+func TestStruct(t *testing.T) {
+	valid := signup{Email: "a@example.com", Age: 20, Address: address{City: "Hangzhou"}}
+
+	tests := []struct {
+		name       string
+		modify     func(s *signup)
+		locale     string
+		wantFields []FieldError
+	}{
+		{name: "valid", modify: func(s *signup) {}},
+		{
+			name: "custom rules pass",
+			modify: func(s *signup) {
+				s.Phone = "+8613812345678"
+				s.IDCard = "11010519491231002X"
+				s.Amount = "12.50"
+			},
+		},
+		{
+			name:   "english messages",
+			modify: func(s *signup) { s.Email = ""; s.Age = 16 },
+			wantFields: []FieldError{
+				{Field: "email", Rule: "required", Message: "email is a required field"},
+				{Field: "age", Rule: "gte", Param: "18", Message: "age must be 18 or greater"},
+			},
+		},
+		{
+			name:   "chinese messages",
+			modify: func(s *signup) { s.Phone = "12345" },
+			locale: "zh",
+			wantFields: []FieldError{
+				{Field: "phone", Rule: "phone", Message: "phone必须是有效的手机号码"},
+			},
+		},
+		{
+			name:   "nested field",
+			modify: func(s *signup) { s.Address.City = "" },
+			wantFields: []FieldError{
+				{Field: "address.city", Rule: "required", Message: "city is a required field"},
+			},
+		},
+		{
+			name:   "unsupported locale falls back",
+			modify: func(s *signup) { s.IDCard = "110105194912310021" },
+			locale: "fr",
+			wantFields: []FieldError{
+				{Field: "id_card", Rule: "idcard", Message: "id_card must be a valid ID card number"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid
+			tt.modify(&s)
+			ctx := context.Background()
+			if tt.locale != "" {
+				ctx = WithLocale(ctx, tt.locale)
+			}
+
+			err := Default().Struct(ctx, &s)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("Struct() error = %v", err)
+				}
+				return
+			}
+			var verr *Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Struct() error = %v, want *Error", err)
+			}
+			if len(verr.Fields) != len(tt.wantFields) {
+				t.Fatalf("Fields = %+v, want %+v", verr.Fields, tt.wantFields)
+			}
+			for i, want := range tt.wantFields {
+				if verr.Fields[i] != want {
+					t.Errorf("Fields[%d] = %+v, want %+v", i, verr.Fields[i], want)
+				}
+			}
+		})
+	}
+}
+
+// color is an enum for the "enum" rule
+type color string
+
+func (c color) IsValid() bool { return c == "red" || c == "green" }
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		tag   string
+		value any
+		want  bool
+	}{
+		{"phone", "13812345678", true},
+		{"phone", "8613812345678", true},
+		{"phone", "+14155552671", true},
+		{"phone", "12812345678", false},
+		{"phone", "+0123456789", false},
+		{"idcard", "11010519491231002X", true},
+		{"idcard", "11010519491231002x", true},
+		{"idcard", "110105194912310021", false},
+		{"idcard", "110105194913310025", false},
+		{"idcard", "1101051949123100", false},
+		{"idcard", "010105194912310026", false},
+		{"money", "0.99", true},
+		{"money", "100", true},
+		{"money", "1.999", false},
+		{"money", "-1", false},
+		{"money", 12.5, true},
+		{"money", 12.345, false},
+		{"money", -3, false},
+		{"money", uint(3), true},
+		{"money", utils.NewMoney(1999, "CNY"), true},
+		{"money", utils.NewMoney(-1, "CNY"), false},
+		{"gt=0,money", utils.NewMoney(0, "CNY"), false},
+		{"gt=0,money", utils.NewMoney(1, "CNY"), true},
+		{"mobile", "+8613812345678", true},
+		{"mobile", "+14155552671", false},
+		{"enum", color("red"), true},
+		{"enum", color("pink"), false},
+		{"enum", "red", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			err := Default().Var(context.Background(), tt.value, tt.tag)
+			if got := err == nil; got != tt.want {
+				t.Errorf("Var(%v, %q) error = %v, want valid %v", tt.value, tt.tag, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterRule(t *testing.T) {
+	v, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	err = v.RegisterRule("sku", func(fl validator.FieldLevel) bool {
+		return len(fl.Field().String()) == 8
+	}, map[string]string{
+		"en": "{0} must be a valid SKU",
+		"zh": "{0}必须是有效的SKU",
+	})
+	if err != nil {
+		t.Fatalf("RegisterRule() error = %v", err)
+	}
+
+	type item struct {
+		SKU string `json:"sku" validate:"sku"`
+	}
+	err = v.Struct(WithLocale(context.Background(), "zh"), item{SKU: "x"})
+	var verr *Error
+	if !errors.As(err, &verr) || verr.Fields[0].Message != "sku必须是有效的SKU" {
+		t.Fatalf("Struct() error = %v", err)
+	}
+
+	if err := v.RegisterRule("bad", func(validator.FieldLevel) bool { return true }, map[string]string{"fr": "x"}); err == nil {
+		t.Error("RegisterRule() with unsupported locale succeeded")
+	}
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	err := Default().Var(context.Background(), "", "required")
+	if !errors.Is(err, errors.ErrBadRequest) {
+		t.Fatalf("error %v does not match ErrBadRequest", err)
+	}
+
+	status, resp := response.FromError(err)
+	if status != http.StatusBadRequest || resp.Code != 40000 || resp.Message != "validation failed" {
+		t.Errorf("FromError() = %d, %+v", status, resp)
+	}
+	data, ok := resp.Data.(map[string]any)
+	if !ok {
+		t.Fatalf("Data = %#v, want field errors", resp.Data)
+	}
+	if fields, _ := data["errors"].([]FieldError); len(fields) != 1 || fields[0].Rule != "required" {
+		t.Errorf("Data = %+v", data)
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"zh-CN,zh;q=0.9,en;q=0.8", "zh"},
+		{"en-US", "en"},
+		{"fr-FR, en;q=0.5", "en"},
+		{"fr", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := MatchLocale(tt.header); got != tt.want {
+				t.Errorf("MatchLocale(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRejectsUnsupportedLocale(t *testing.T) {
+	if _, err := New(Config{DefaultLocale: "fr"}); err == nil {
+		t.Error("New() with unsupported default locale succeeded")
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"log"
 	"net/http"
 	"time"
 
@@ -9,7 +12,16 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	ginauth "mora/adapters/gin"
+	"mora/pkg/app"
 	"mora/pkg/auth"
+	"mora/pkg/errors"
+	"mora/pkg/health"
+	"mora/pkg/httpmw"
+	"mora/pkg/logger"
+	"mora/pkg/metrics"
+	"mora/pkg/saga"
+	"mora/pkg/tracing"
+	"mora/pkg/utils"
 	_ "mora/starter/gin-starter/docs"
 )
 
@@ -41,19 +53,56 @@ const (
 // @description Type "Bearer" followed by a space and JWT token.
 
 func main() {
+	// Tracing; set Exporter to otlpgrpc or jaeger to ship spans to a collector
+	tracingConfig := tracing.DefaultConfig()
+	tracingConfig.ServiceName = "gin-starter"
+	tracingConfig.Exporter = "none"
+	provider, err := tracing.Init(context.Background(), tracingConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	r := gin.Default()
+	r.Use(ginauth.RequestID(), ginauth.Tracing())
+
+	// Health checks; register components as they are added, e.g.
+	// checks.Register("db", health.SQL(sqlDB))
+	checks := health.New(health.DefaultConfig())
+	checks.Register("disk", health.Disk(".", 100<<20), health.NonCritical())
+
+	// Prometheus metrics by route, served on /metrics
+	registry := metrics.New(metrics.DefaultConfig(), nil)
+	httpMetrics, err := registry.HTTP()
+	if err != nil {
+		log.Fatal(err)
+	}
+	r.Use(ginauth.Metrics(httpMetrics))
+
+	// Count the lines of the default logger by level, e.g. to alert on
+	// error spikes
+	logMetrics, err := registry.Logs()
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger.NewDefault().SetEntryCounter(logMetrics)
+
+	// Answer CORS preflights before authentication rejects them
+	r.Use(ginauth.CORS(httpmw.DefaultCORSConfig()))
 
 	// Configure auth middleware
 	authConfig := ginauth.AuthMiddlewareConfig{
 		Secret:    JWTSecret,
-		SkipPaths: []string{"/health", "/login", "/swagger/*"},
+		SkipPaths: []string{"/health", "/health/*", "/metrics", "/login", "/swagger/*"},
 	}
 
 	// Apply auth middleware globally (except for skip paths)
 	r.Use(ginauth.AuthMiddleware(authConfig))
 
 	// Public routes (no authentication required)
-	r.GET("/health", healthHandler)
+	r.GET("/health", healthHandler(checks))
+	r.GET("/health/ready", healthHandler(checks))
+	r.GET("/health/live", ginauth.HealthHandler(checks, health.Liveness))
+	r.GET(registry.Path(), ginauth.MetricsHandler(registry))
 	r.POST("/login", loginHandler)
 
 	// Swagger documentation
@@ -63,35 +112,45 @@ func main() {
 	r.GET("/profile", profileHandler)
 	r.GET("/protected", protectedHandler)
 
+	// Orders are placed by a saga that reserves inventory, then charges the
+	// payment, releasing the reservation when the charge is declined
+	orderSagas, err := newOrderSagas()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Business API routes
 	api := r.Group("/api/v1")
 	{
 		api.GET("/orders", getOrdersHandler)
-		api.POST("/orders", createOrderHandler)
+		api.POST("/orders", createOrderHandler(orderSagas))
 		api.GET("/users", getUsersHandler)
 	}
 
-	r.Run(":8080")
-}
-
-// HealthResponse represents health check response
-type HealthResponse struct {
-	Status string `json:"status" example:"ok"`
-	Time   string `json:"time" example:"2023-12-25T15:30:45Z"`
+	// Run the server until SIGINT/SIGTERM, then shut down gracefully
+	application := app.New(app.DefaultConfig())
+	application.AfterStop(provider.Shutdown)
+	application.Add(app.Background("order-sagas", orderSagas.Run))
+	application.Add(app.HTTPServer("http", &http.Server{
+		Addr:              ":8080",
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}))
+	if err := application.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
 }
 
 // @Summary Health Check
-// @Description 系统健康检查接口
+// @Description 系统健康检查接口，聚合各组件的就绪检查
 // @Tags System
 // @Accept json
 // @Produce json
-// @Success 200 {object} HealthResponse
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
 // @Router /health [get]
-func healthHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, HealthResponse{
-		Status: "ok",
-		Time:   time.Now().Format(time.RFC3339),
-	})
+func healthHandler(checks *health.Aggregator) gin.HandlerFunc {
+	return ginauth.HealthHandler(checks, health.Readiness)
 }
 
 // LoginRequest represents login request
@@ -109,30 +168,20 @@ type LoginResponse struct {
 	Username    string `json:"username" example:"admin"`
 }
 
-// ErrorResponse represents error response
-type ErrorResponse struct {
-	Error   string `json:"error" example:"authentication failed"`
-	Message string `json:"message" example:"invalid username or password"`
-}
-
 // @Summary User Login
 // @Description 用户登录接口，返回Access Token
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "登录请求"
-// @Success 200 {object} LoginResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
+// @Success 200 {object} response.Response{data=LoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
 // @Router /login [post]
 func loginHandler(c *gin.Context) {
 	var req LoginRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid request",
-			Message: err.Error(),
-		})
+	if !ginauth.BindAndValidate(c, &req) {
 		return
 	}
 
@@ -141,14 +190,11 @@ func loginHandler(c *gin.Context) {
 		// Generate access token
 		token, err := auth.GenerateToken("user-123", req.Username, JWTSecret, TokenTTL)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error:   "token generation failed",
-				Message: err.Error(),
-			})
+			ginauth.Error(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, LoginResponse{
+		ginauth.OK(c, LoginResponse{
 			AccessToken: token,
 			TokenType:   "Bearer",
 			ExpiresIn:   int(TokenTTL.Seconds()),
@@ -158,10 +204,7 @@ func loginHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusUnauthorized, ErrorResponse{
-		Error:   "authentication failed",
-		Message: "invalid username or password",
-	})
+	ginauth.Error(c, errors.ErrUnauthorized.WithMessage("invalid username or password"))
 }
 
 // ProfileResponse represents profile response
@@ -179,22 +222,20 @@ type ProfileResponse struct {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} ProfileResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Success 200 {object} response.Response{data=ProfileResponse}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
 // @Router /profile [get]
 func profileHandler(c *gin.Context) {
 	userID := ginauth.GetUserID(c)
 	claims := ginauth.GetClaims(c)
 
 	if claims == nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error: "failed to get user claims",
-		})
+		ginauth.Error(c, errors.ErrInternal.WithDetail("missing user claims"))
 		return
 	}
 
-	c.JSON(http.StatusOK, ProfileResponse{
+	ginauth.OK(c, ProfileResponse{
 		UserID:   userID,
 		Username: claims.Username,
 		Subject:  claims.Subject,
@@ -216,12 +257,12 @@ type ProtectedResponse struct {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} ProtectedResponse
-// @Failure 401 {object} ErrorResponse
+// @Success 200 {object} response.Response{data=ProtectedResponse}
+// @Failure 401 {object} response.Response
 // @Router /protected [get]
 func protectedHandler(c *gin.Context) {
 	userID := ginauth.GetUserID(c)
-	c.JSON(http.StatusOK, ProtectedResponse{
+	ginauth.OK(c, ProtectedResponse{
 		Message: "This is a protected endpoint",
 		UserID:  userID,
 		Time:    time.Now().Format(time.RFC3339),
@@ -230,10 +271,10 @@ func protectedHandler(c *gin.Context) {
 
 // Order represents order information
 type Order struct {
-	ID     string  `json:"id" example:"order-1"`
-	UserID string  `json:"user_id" example:"user-123"`
-	Amount float64 `json:"amount" example:"100.00"`
-	Status string  `json:"status" example:"completed"`
+	ID     string      `json:"id" example:"order-1"`
+	UserID string      `json:"user_id" example:"user-123"`
+	Amount utils.Money `json:"amount" swaggertype:"object,string" example:"amount:100.00,currency:CNY"`
+	Status string      `json:"status" example:"completed"`
 }
 
 // OrdersResponse represents orders list response
@@ -248,19 +289,19 @@ type OrdersResponse struct {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} OrdersResponse
-// @Failure 401 {object} ErrorResponse
+// @Success 200 {object} response.Response{data=OrdersResponse}
+// @Failure 401 {object} response.Response
 // @Router /api/v1/orders [get]
 func getOrdersHandler(c *gin.Context) {
 	userID := ginauth.GetUserID(c)
 
 	// Mock orders data - in production, query from database
 	orders := []Order{
-		{ID: "order-1", UserID: userID, Amount: 100.00, Status: "completed"},
-		{ID: "order-2", UserID: userID, Amount: 250.50, Status: "pending"},
+		{ID: "order-1", UserID: userID, Amount: utils.NewMoney(10000, "CNY"), Status: "completed"},
+		{ID: "order-2", UserID: userID, Amount: utils.NewMoney(25050, "CNY"), Status: "pending"},
 	}
 
-	c.JSON(http.StatusOK, OrdersResponse{
+	ginauth.OK(c, OrdersResponse{
 		Orders: orders,
 		Total:  len(orders),
 	})
@@ -268,8 +309,8 @@ func getOrdersHandler(c *gin.Context) {
 
 // CreateOrderRequest represents create order request
 type CreateOrderRequest struct {
-	Amount      float64 `json:"amount" binding:"required" example:"100.00"`
-	Description string  `json:"description" example:"订单描述"`
+	Amount      utils.Money `json:"amount" validate:"gt=0,money" swaggertype:"object,string" example:"amount:100.00,currency:CNY"`
+	Description string      `json:"description" example:"订单描述"`
 }
 
 // CreateOrderResponse represents create order response
@@ -284,34 +325,131 @@ type CreateOrderResponse struct {
 // @Produce json
 // @Security BearerAuth
 // @Param request body CreateOrderRequest true "创建订单请求"
-// @Success 201 {object} CreateOrderResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
+// @Success 201 {object} response.Response{data=CreateOrderResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Router /api/v1/orders [post]
-func createOrderHandler(c *gin.Context) {
-	userID := ginauth.GetUserID(c)
+func createOrderHandler(orders *saga.Coordinator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := ginauth.GetUserID(c)
+
+		var req CreateOrderRequest
+
+		if !ginauth.BindAndValidate(c, &req) {
+			return
+		}
 
-	var req CreateOrderRequest
+		id, err := utils.GenerateULID()
+		if err != nil {
+			ginauth.Error(c, err)
+			return
+		}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid request",
-			Message: err.Error(),
+		order := Order{
+			ID:     "order-" + id,
+			UserID: userID,
+			Amount: req.Amount,
+		}
+		inst, err := orders.Start(c.Request.Context(), createOrderSaga, saga.Data{
+			"order_id": order.ID,
+			"amount":   order.Amount,
 		})
-		return
-	}
+		if err != nil {
+			ginauth.Error(c, err)
+			return
+		}
 
-	// Mock order creation
-	order := Order{
-		ID:     "order-" + time.Now().Format("20060102150405"),
-		UserID: userID,
-		Amount: req.Amount,
-		Status: "created",
+		switch inst.Status {
+		case saga.StatusCompleted:
+			order.Status = "created"
+		case saga.StatusCompensated, saga.StatusFailed:
+			ginauth.Error(c, errors.ErrConflict.WithMessage("order rolled back: "+inst.Error))
+			return
+		default:
+			// A step failed and is retried in the background
+			order.Status = "pending"
+		}
+
+		ginauth.Created(c, CreateOrderResponse{
+			Order: order,
+		})
 	}
+}
+
+// createOrderSaga names the saga placing an order
+const createOrderSaga = "create-order"
 
-	c.JSON(http.StatusCreated, CreateOrderResponse{
-		Order: order,
+// maxCharge is the largest amount the mock payment service accepts, in
+// minor units, so that larger orders show the compensation
+const maxCharge = 100000
+
+// orderSagaData is the data the create-order saga carries between steps
+type orderSagaData struct {
+	OrderID       string      `json:"order_id"`
+	Amount        utils.Money `json:"amount"`
+	ReservationID string      `json:"reservation_id"`
+	PaymentID     string      `json:"payment_id"`
+}
+
+// newOrderSagas registers the create-order saga on an in-memory coordinator;
+// in production, use saga.NewSQLStore and run the steps in their services
+func newOrderSagas() (*saga.Coordinator, error) {
+	orders := saga.NewCoordinator(saga.NewMemoryStore(), nil, saga.DefaultConfig())
+	err := orders.Register(saga.Definition{
+		Name: createOrderSaga,
+		Steps: []saga.Step{
+			{Name: "reserve-inventory", Execute: reserveInventory, Compensate: releaseInventory},
+			{Name: "charge-payment", Execute: chargePayment, Compensate: refundPayment},
+		},
 	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// reserveInventory is a mock inventory reservation
+func reserveInventory(ctx context.Context, data saga.Data) (saga.Data, error) {
+	var d orderSagaData
+	if err := data.Decode(&d); err != nil {
+		return nil, saga.Abort(err)
+	}
+	logger.NewDefault().WithContext(ctx).Infof("reserved inventory for %s", d.OrderID)
+	return saga.Data{"reservation_id": "rsv-" + d.OrderID}, nil
+}
+
+// releaseInventory undoes reserveInventory
+func releaseInventory(ctx context.Context, data saga.Data) (saga.Data, error) {
+	var d orderSagaData
+	if err := data.Decode(&d); err != nil {
+		return nil, err
+	}
+	logger.NewDefault().WithContext(ctx).Infof("released reservation %s", d.ReservationID)
+	return nil, nil
+}
+
+// chargePayment is a mock payment that declines amounts above maxCharge
+func chargePayment(ctx context.Context, data saga.Data) (saga.Data, error) {
+	var d orderSagaData
+	if err := data.Decode(&d); err != nil {
+		return nil, saga.Abort(err)
+	}
+	if d.Amount.Amount > maxCharge {
+		return nil, saga.Abort(fmt.Errorf("payment of %s declined", d.Amount))
+	}
+	logger.NewDefault().WithContext(ctx).Infof("charged %s for %s", d.Amount, d.OrderID)
+	return saga.Data{"payment_id": "pay-" + d.OrderID}, nil
+}
+
+// refundPayment undoes chargePayment
+func refundPayment(ctx context.Context, data saga.Data) (saga.Data, error) {
+	var d orderSagaData
+	if err := data.Decode(&d); err != nil {
+		return nil, err
+	}
+	logger.NewDefault().WithContext(ctx).Infof("refunded payment %s", d.PaymentID)
+	return nil, nil
 }
 
 // User represents user information
@@ -334,8 +472,8 @@ type UsersResponse struct {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} UsersResponse
-// @Failure 401 {object} ErrorResponse
+// @Success 200 {object} response.Response{data=UsersResponse}
+// @Failure 401 {object} response.Response
 // @Router /api/v1/users [get]
 func getUsersHandler(c *gin.Context) {
 	userID := ginauth.GetUserID(c)
@@ -346,9 +484,9 @@ func getUsersHandler(c *gin.Context) {
 		{ID: "user-456", Username: "user1", Role: "user"},
 	}
 
-	c.JSON(http.StatusOK, UsersResponse{
+	ginauth.OK(c, UsersResponse{
 		Users:     users,
 		Total:     len(users),
 		RequestBy: userID,
 	})
-}
\ No newline at end of file
+}
@@ -2,10 +2,9 @@ package handler
 
 import (
 	"net/http"
-	"time"
 
-	"github.com/zeromicro/go-zero/rest/httpx"
 	gozeroauth "mora/adapters/gozero"
+	"mora/pkg/utils"
 	"mora/starter/gozero-starter/internal/svc"
 	"mora/starter/gozero-starter/internal/types"
 )
@@ -13,16 +12,21 @@ import (
 func CreateOrderHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.CreateOrderRequest
-		if err := httpx.Parse(r, &req); err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
+		if !gozeroauth.BindAndValidate(w, r, &req) {
 			return
 		}
 
 		userID := gozeroauth.GetUserID(r.Context())
 
+		id, err := utils.GenerateULID()
+		if err != nil {
+			gozeroauth.Error(w, r, err)
+			return
+		}
+
 		// Mock order creation
 		order := types.Order{
-			ID:     "order-" + time.Now().Format("20060102150405"),
+			ID:     "order-" + id,
 			UserID: userID,
 			Amount: req.Amount,
 			Status: "created",
@@ -32,6 +36,6 @@ func CreateOrderHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			Order: order,
 		}
 
-		httpx.WriteJson(w, http.StatusCreated, resp)
+		gozeroauth.Created(w, r, resp)
 	}
-}
\ No newline at end of file
+}
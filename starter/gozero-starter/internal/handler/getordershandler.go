@@ -3,8 +3,8 @@ package handler
 import (
 	"net/http"
 
-	"github.com/zeromicro/go-zero/rest/httpx"
 	gozeroauth "mora/adapters/gozero"
+	"mora/pkg/utils"
 	"mora/starter/gozero-starter/internal/svc"
 	"mora/starter/gozero-starter/internal/types"
 )
@@ -15,8 +15,8 @@ func GetOrdersHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 
 		// Mock orders data - in production, query from database
 		orders := []types.Order{
-			{ID: "order-1", UserID: userID, Amount: 100.00, Status: "completed"},
-			{ID: "order-2", UserID: userID, Amount: 250.50, Status: "pending"},
+			{ID: "order-1", UserID: userID, Amount: utils.NewMoney(10000, "CNY"), Status: "completed"},
+			{ID: "order-2", UserID: userID, Amount: utils.NewMoney(25050, "CNY"), Status: "pending"},
 		}
 
 		resp := &types.OrdersResponse{
@@ -24,6 +24,6 @@ func GetOrdersHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			Total:  len(orders),
 		}
 
-		httpx.OkJson(w, resp)
+		gozeroauth.OK(w, r, resp)
 	}
-}
\ No newline at end of file
+}
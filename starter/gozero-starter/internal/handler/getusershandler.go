@@ -3,7 +3,6 @@ package handler
 import (
 	"net/http"
 
-	"github.com/zeromicro/go-zero/rest/httpx"
 	gozeroauth "mora/adapters/gozero"
 	"mora/starter/gozero-starter/internal/svc"
 	"mora/starter/gozero-starter/internal/types"
@@ -25,6 +24,6 @@ func GetUsersHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			RequestBy: userID,
 		}
 
-		httpx.OkJson(w, resp)
+		gozeroauth.OK(w, r, resp)
 	}
-}
\ No newline at end of file
+}
@@ -4,8 +4,9 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/zeromicro/go-zero/rest/httpx"
+	gozeroauth "mora/adapters/gozero"
 	"mora/pkg/auth"
+	"mora/pkg/errors"
 	"mora/starter/gozero-starter/internal/svc"
 	"mora/starter/gozero-starter/internal/types"
 )
@@ -13,8 +14,7 @@ import (
 func LoginHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.LoginRequest
-		if err := httpx.Parse(r, &req); err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
+		if !gozeroauth.BindAndValidate(w, r, &req) {
 			return
 		}
 
@@ -24,7 +24,7 @@ func LoginHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			tokenTTL := time.Duration(svcCtx.Config.JWT.TTL) * time.Second
 			token, err := auth.GenerateToken("user-123", req.Username, svcCtx.Config.JWT.Secret, tokenTTL)
 			if err != nil {
-				httpx.Error(w, err)
+				gozeroauth.Error(w, r, err)
 				return
 			}
 
@@ -36,14 +36,11 @@ func LoginHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 				Username:    req.Username,
 			}
 
-			httpx.OkJson(w, resp)
+			gozeroauth.OK(w, r, resp)
 			return
 		}
 
 		// Authentication failed
-		httpx.WriteJson(w, http.StatusUnauthorized, map[string]string{
-			"error":   "authentication failed",
-			"message": "invalid username or password",
-		})
+		gozeroauth.Error(w, r, errors.ErrUnauthorized.WithMessage("invalid username or password"))
 	}
-}
\ No newline at end of file
+}
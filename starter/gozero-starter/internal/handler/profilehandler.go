@@ -4,8 +4,8 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/zeromicro/go-zero/rest/httpx"
 	gozeroauth "mora/adapters/gozero"
+	"mora/pkg/errors"
 	"mora/starter/gozero-starter/internal/svc"
 	"mora/starter/gozero-starter/internal/types"
 )
@@ -16,9 +16,7 @@ func ProfileHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 		claims := gozeroauth.GetClaims(r.Context())
 
 		if claims == nil {
-			httpx.WriteJson(w, http.StatusInternalServerError, map[string]string{
-				"error": "failed to get user claims",
-			})
+			gozeroauth.Error(w, r, errors.ErrInternal.WithDetail("missing user claims"))
 			return
 		}
 
@@ -30,6 +28,6 @@ func ProfileHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			Iat:      claims.IssuedAt.Time.Format(time.RFC3339),
 		}
 
-		httpx.OkJson(w, resp)
+		gozeroauth.OK(w, r, resp)
 	}
-}
\ No newline at end of file
+}
@@ -4,7 +4,6 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/zeromicro/go-zero/rest/httpx"
 	gozeroauth "mora/adapters/gozero"
 	"mora/starter/gozero-starter/internal/svc"
 	"mora/starter/gozero-starter/internal/types"
@@ -20,6 +19,6 @@ func ProtectedHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			Time:    time.Now().Format(time.RFC3339),
 		}
 
-		httpx.OkJson(w, resp)
+		gozeroauth.OK(w, r, resp)
 	}
-}
\ No newline at end of file
+}
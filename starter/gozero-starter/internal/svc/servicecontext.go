@@ -1,15 +1,21 @@
 package svc
 
 import (
+	"mora/pkg/health"
 	"mora/starter/gozero-starter/internal/config"
 )
 
 type ServiceContext struct {
 	Config config.Config
+	Health *health.Aggregator
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
+	// Register component checks as they are added, e.g. checks.Register("db", health.SQL(sqlDB))
+	checks := health.New(health.DefaultConfig())
+	checks.Register("disk", health.Disk(".", 100<<20), health.NonCritical())
 	return &ServiceContext{
 		Config: c,
+		Health: checks,
 	}
-}
\ No newline at end of file
+}
@@ -1,21 +1,17 @@
 package types
 
+import "mora/pkg/utils"
+
 // 基础响应类型
 type BaseResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
 
-// 健康检查
-type HealthResponse struct {
-	Status string `json:"status"`
-	Time   string `json:"time"`
-}
-
 // 登录相关
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 type LoginResponse struct {
@@ -44,10 +40,10 @@ type ProtectedResponse struct {
 
 // 订单相关
 type Order struct {
-	ID     string  `json:"id"`
-	UserID string  `json:"user_id"`
-	Amount float64 `json:"amount"`
-	Status string  `json:"status"`
+	ID     string      `json:"id"`
+	UserID string      `json:"user_id"`
+	Amount utils.Money `json:"amount"`
+	Status string      `json:"status"`
 }
 
 type OrdersResponse struct {
@@ -56,8 +52,8 @@ type OrdersResponse struct {
 }
 
 type CreateOrderRequest struct {
-	Amount      float64 `json:"amount"`
-	Description string  `json:"description"`
+	Amount      utils.Money `json:"amount" validate:"gt=0,money"`
+	Description string      `json:"description"`
 }
 
 type CreateOrderResponse struct {
@@ -75,4 +71,4 @@ type UsersResponse struct {
 	Users     []User `json:"users"`
 	Total     int    `json:"total"`
 	RequestBy string `json:"request_by"`
-}
\ No newline at end of file
+}
@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"log"
 
 	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/proc"
 	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/httpx"
 	"mora/adapters/gozero"
+	"mora/pkg/app"
+	"mora/pkg/httpmw"
+	"mora/pkg/logger"
+	"mora/pkg/metrics"
 	"mora/starter/gozero-starter/internal/config"
 	"mora/starter/gozero-starter/internal/handler"
 	"mora/starter/gozero-starter/internal/svc"
@@ -20,65 +28,94 @@ func main() {
 	var c config.Config
 	conf.MustLoad(*configFile, &c)
 
-	server := rest.MustNewServer(c.RestConf)
-	defer server.Stop()
+	// Apply CORS ahead of routing so preflights for every route are answered
+	server := rest.MustNewServer(c.RestConf, gozero.WithCORS(httpmw.DefaultCORSConfig()))
 
 	ctx := svc.NewServiceContext(c)
 
+	// go-zero traces requests itself; hand its trace IDs and the request IDs
+	// to pkg/logger
+	server.Use(gozero.RequestID())
+	server.Use(gozero.Tracing())
+
+	// Render httpx errors with the Mora response envelope
+	httpx.SetErrorHandlerCtx(gozero.ErrorHandler)
+
 	// Configure auth middleware
 	authConfig := gozero.AuthMiddlewareConfig{
 		Secret:    c.JWT.Secret,
-		SkipPaths: []string{"/health", "/login"},
+		SkipPaths: []string{"/health", "/health/*", "/metrics", "/login"},
 	}
 
 	// Apply auth middleware to protected routes only
 	authMiddleware := gozero.AuthMiddleware(authConfig)
 
+	// Prometheus metrics by route, served on /metrics
+	registry := metrics.New(metrics.DefaultConfig(), nil)
+	httpMetrics, err := registry.HTTP()
+	if err != nil {
+		log.Fatal(err)
+	}
+	server.AddRoute(gozero.MetricsRoute(registry))
+
+	// Count the lines of the default logger by level, e.g. to alert on
+	// error spikes
+	logMetrics, err := registry.Logs()
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger.NewDefault().SetEntryCounter(logMetrics)
+
 	// Public routes (no authentication required)
-	server.AddRoute(rest.Route{
-		Method:  "GET",
-		Path:    "/health",
-		Handler: handler.HealthHandler(ctx),
-	})
-
-	server.AddRoute(rest.Route{
-		Method:  "POST",
-		Path:    "/login",
-		Handler: handler.LoginHandler(ctx),
-	})
-
-	// Protected routes (authentication required)
-	server.AddRoute(rest.Route{
-		Method:  "GET",
-		Path:    "/profile",
-		Handler: authMiddleware(handler.ProfileHandler(ctx)),
-	})
-
-	server.AddRoute(rest.Route{
-		Method:  "GET",
-		Path:    "/protected",
-		Handler: authMiddleware(handler.ProtectedHandler(ctx)),
-	})
-
-	// Business API routes
-	server.AddRoute(rest.Route{
-		Method:  "GET",
-		Path:    "/api/v1/orders",
-		Handler: authMiddleware(handler.GetOrdersHandler(ctx)),
-	})
-
-	server.AddRoute(rest.Route{
-		Method:  "POST",
-		Path:    "/api/v1/orders",
-		Handler: authMiddleware(handler.CreateOrderHandler(ctx)),
-	})
-
-	server.AddRoute(rest.Route{
-		Method:  "GET",
-		Path:    "/api/v1/users",
-		Handler: authMiddleware(handler.GetUsersHandler(ctx)),
-	})
+	server.AddRoutes(gozero.InstrumentRoutes(httpMetrics, gozero.HealthRoutes(ctx.Health)...))
+
+	server.AddRoutes(gozero.InstrumentRoutes(httpMetrics,
+		rest.Route{
+			Method:  "POST",
+			Path:    "/login",
+			Handler: handler.LoginHandler(ctx),
+		},
+
+		// Protected routes (authentication required)
+		rest.Route{
+			Method:  "GET",
+			Path:    "/profile",
+			Handler: authMiddleware(handler.ProfileHandler(ctx)),
+		},
+		rest.Route{
+			Method:  "GET",
+			Path:    "/protected",
+			Handler: authMiddleware(handler.ProtectedHandler(ctx)),
+		},
+
+		// Business API routes
+		rest.Route{
+			Method:  "GET",
+			Path:    "/api/v1/orders",
+			Handler: authMiddleware(handler.GetOrdersHandler(ctx)),
+		},
+		rest.Route{
+			Method:  "POST",
+			Path:    "/api/v1/orders",
+			Handler: authMiddleware(handler.CreateOrderHandler(ctx)),
+		},
+		rest.Route{
+			Method:  "GET",
+			Path:    "/api/v1/users",
+			Handler: authMiddleware(handler.GetUsersHandler(ctx)),
+		},
+	))
+
+	// Run the server until SIGINT/SIGTERM, then shut down gracefully
+	appConfig := app.DefaultConfig()
+	appConfig.Name = c.Name
+	proc.SetTimeToForceQuit(appConfig.ShutdownTimeout)
+
+	application := app.New(appConfig)
+	application.Add(gozero.Server("http", server))
 
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
-	server.Start()
-}
\ No newline at end of file
+	if err := application.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}